@@ -83,6 +83,16 @@ type HookSupport interface {
 	GetSupportedHooks() []HookType
 }
 
+// UserConfigHookInstaller is implemented by agents whose hooks are installed
+// in the user's configuration rather than the repository, so installing them
+// affects every repository. Entire only installs them when asked explicitly.
+type UserConfigHookInstaller interface {
+	HookSupport
+
+	// HookConfigPath returns the path of the configuration file the hooks are installed in
+	HookConfigPath() (string, error)
+}
+
 // HookHandler is implemented by agents that define their own hook vocabulary.
 // Each agent defines its own hook names (verbs) which become subcommands
 // under `entire hooks <agent>`. The actual handling is done by handlers
@@ -149,3 +159,41 @@ type TranscriptChunker interface {
 	// Handles format-specific reassembly (JSONL concatenation, JSON message merging).
 	ReassembleTranscript(chunks [][]byte) ([]byte, error)
 }
+
+// TranscriptParser is implemented by agents that can normalize transcript data,
+// as stored in checkpoints, into session entries.
+// This allows agent-agnostic explain, summaries, prompt extraction and token accounting.
+type TranscriptParser interface {
+	Agent
+
+	// ParseEntries converts transcript data in the agent's native format into
+	// entries, in transcript order. Entry positions match the agent's
+	// TranscriptAnalyzer positions, and ReadSession returns the same entries.
+	ParseEntries(data []byte) ([]SessionEntry, error)
+}
+
+// TranscriptBuilder is implemented by agents that can build a native transcript
+// from session entries recorded by any agent.
+// This allows continuing a session in another agent (see ConvertSession).
+type TranscriptBuilder interface {
+	Agent
+
+	// BuildTranscript renders session.Entries in the agent's native format,
+	// as a transcript of session.SessionID that WriteSession can write.
+	BuildTranscript(session *AgentSession) ([]byte, error)
+
+	// ResolveSessionFile returns the path of the transcript of an agent
+	// session ID in the agent's session directory (see GetSessionDir).
+	ResolveSessionFile(sessionDir, agentSessionID string) string
+}
+
+// TranscriptForker is implemented by agents whose transcripts record the
+// session they belong to, and can rewrite them for a forked session.
+// This allows forking a session from a checkpoint (see entire fork).
+type TranscriptForker interface {
+	Agent
+
+	// ForkTranscript returns a copy of a transcript in the agent's native
+	// format, as a transcript of the agent session ID agentSessionID.
+	ForkTranscript(data []byte, agentSessionID string) ([]byte, error)
+}
@@ -0,0 +1,357 @@
+// Package aider implements the Agent interface for Aider.
+//
+// Aider has no lifecycle hooks; sessions are read from the Markdown chat history
+// it keeps in the working directory, and detected by watching that file.
+package aider
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/sessionid"
+)
+
+//nolint:gochecknoinits // Agent self-registration is the intended pattern
+func init() {
+	agent.Register(agent.AgentNameAider, NewAiderAgent)
+}
+
+// AiderAgent implements the Agent interface for Aider.
+//
+//nolint:revive // AiderAgent is clearer than Agent in this context
+type AiderAgent struct{}
+
+func NewAiderAgent() agent.Agent {
+	return &AiderAgent{}
+}
+
+// Name returns the agent registry key.
+func (a *AiderAgent) Name() agent.AgentName {
+	return agent.AgentNameAider
+}
+
+// Type returns the agent type identifier.
+func (a *AiderAgent) Type() agent.AgentType {
+	return agent.AgentTypeAider
+}
+
+// Description returns a human-readable description.
+func (a *AiderAgent) Description() string {
+	return "Aider - AI pair programming in your terminal (file watching, no hooks)"
+}
+
+// DetectPresence checks if Aider is configured or has been used in the repository.
+func (a *AiderAgent) DetectPresence() (bool, error) {
+	repoRoot, err := paths.RepoRoot()
+	if err != nil {
+		// Not in a git repo, fall back to CWD-relative check
+		repoRoot = "."
+	}
+
+	for _, name := range []string{configFileName, ChatHistoryFileName, InputHistoryFileName} {
+		if _, err := os.Stat(filepath.Join(repoRoot, name)); err == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetHookConfigPath returns "" as Aider has no hook configuration.
+func (a *AiderAgent) GetHookConfigPath() string {
+	return ""
+}
+
+// SupportsHooks returns false; Aider sessions are detected by file watching.
+func (a *AiderAgent) SupportsHooks() bool {
+	return false
+}
+
+// ParseHookInput always fails as Aider does not invoke hooks.
+func (a *AiderAgent) ParseHookInput(_ agent.HookType, _ io.Reader) (*agent.HookInput, error) {
+	return nil, errors.New("aider does not support hooks; sessions are detected by file watching")
+}
+
+// GetSessionID extracts the session ID from hook input.
+func (a *AiderAgent) GetSessionID(input *agent.HookInput) string {
+	return input.SessionID
+}
+
+// TransformSessionID converts an Aider session ID to an Entire session ID.
+// This is an identity function - the agent session ID IS the Entire session ID.
+func (a *AiderAgent) TransformSessionID(agentSessionID string) string {
+	return agentSessionID
+}
+
+// ExtractAgentSessionID extracts the Aider session ID from an Entire session ID.
+// For backwards compatibility with legacy date-prefixed IDs, it strips the prefix if present.
+func (a *AiderAgent) ExtractAgentSessionID(entireSessionID string) string {
+	return sessionid.ModelSessionID(entireSessionID)
+}
+
+// GetSessionDir returns the repository path: Aider keeps its history files in
+// the directory it runs in.
+func (a *AiderAgent) GetSessionDir(repoPath string) (string, error) {
+	return repoPath, nil
+}
+
+// readChatSession reads the chat history at path and returns the session with
+// the given ID, or the most recent session if sessionID is empty or unknown.
+// Prompt timestamps come from the input history next to the chat history.
+// Returns nil if the history doesn't exist or is empty.
+func readChatSession(path, sessionID string) (*ChatSession, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // Reading from controlled transcript path
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read chat history: %w", err)
+	}
+
+	sessions := ParseChatHistory(data)
+	if len(sessions) == 0 {
+		return nil, nil
+	}
+	session := &sessions[len(sessions)-1]
+	for i := range sessions {
+		if sessions[i].ID == sessionID {
+			session = &sessions[i]
+			break
+		}
+	}
+
+	inputPath := filepath.Join(filepath.Dir(path), InputHistoryFileName)
+	if input, err := os.ReadFile(inputPath); err == nil { //nolint:gosec // Path next to the chat history
+		applyInputHistory(session, ParseInputHistory(input))
+	}
+	return session, nil
+}
+
+// ReadSession reads a session from Aider's chat history.
+// SessionRef is the path to .aider.chat.history.md (or a stored transcript);
+// NativeData holds the session's Markdown.
+func (a *AiderAgent) ReadSession(input *agent.HookInput) (*agent.AgentSession, error) {
+	if input.SessionRef == "" {
+		return nil, errors.New("session reference (chat history path) is required")
+	}
+
+	session, err := readChatSession(input.SessionRef, a.ExtractAgentSessionID(input.SessionID))
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, fmt.Errorf("no aider chat found in %s", input.SessionRef)
+	}
+
+	startTime := session.StartedAt
+	if startTime.IsZero() {
+		startTime = time.Now()
+	}
+
+	return &agent.AgentSession{
+		SessionID:     input.SessionID,
+		AgentName:     a.Name(),
+		SessionRef:    input.SessionRef,
+		StartTime:     startTime,
+		NativeData:    session.Raw,
+		ModifiedFiles: ExtractModifiedFiles(session.Messages),
+		Entries:       ToSessionEntries(*session),
+	}, nil
+}
+
+// WriteSession restores a session into the chat history in SessionRef's directory,
+// so `aider --restore-chat-history` picks it up. The session is appended unless
+// the history already contains it.
+func (a *AiderAgent) WriteSession(session *agent.AgentSession) error {
+	if session == nil {
+		return errors.New("session is nil")
+	}
+
+	// Verify this session belongs to Aider
+	if session.AgentName != "" && session.AgentName != a.Name() {
+		return fmt.Errorf("session belongs to agent %q, not %q", session.AgentName, a.Name())
+	}
+
+	if session.SessionRef == "" {
+		return errors.New("session reference (chat history path) is required")
+	}
+
+	if len(session.NativeData) == 0 {
+		return errors.New("session has no native data to write")
+	}
+
+	historyPath := session.SessionRef
+	if filepath.Base(historyPath) != ChatHistoryFileName {
+		historyPath = filepath.Join(filepath.Dir(historyPath), ChatHistoryFileName)
+	}
+
+	existing, err := os.ReadFile(historyPath) //nolint:gosec // Path built from session reference
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read chat history: %w", err)
+	}
+	if bytes.Contains(existing, session.NativeData) {
+		return nil
+	}
+
+	var content bytes.Buffer
+	content.Write(existing)
+	if len(existing) > 0 && !bytes.HasSuffix(existing, []byte("\n\n")) {
+		if !bytes.HasSuffix(existing, []byte("\n")) {
+			content.WriteByte('\n')
+		}
+		content.WriteByte('\n')
+	}
+	content.Write(session.NativeData)
+
+	if err := os.WriteFile(historyPath, content.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("failed to write chat history: %w", err)
+	}
+	return nil
+}
+
+// FormatResumeCommand returns the command to resume an Aider session.
+// Aider has no session IDs; it restores the chat history of the current directory.
+func (a *AiderAgent) FormatResumeCommand(_ string) string {
+	return "aider --restore-chat-history"
+}
+
+// FileWatcher interface implementation
+
+// GetWatchPaths returns the chat history file of the current repository.
+func (a *AiderAgent) GetWatchPaths() ([]string, error) {
+	repoRoot, err := paths.RepoRoot()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository root: %w", err)
+	}
+	return []string{filepath.Join(repoRoot, ChatHistoryFileName)}, nil
+}
+
+// OnFileChange maps a chat history change to the most recent session.
+// Aider appends the prompt when it's submitted and the response once it's
+// complete, so the last message tells where the turn is.
+// Returns nil for unrelated files.
+func (a *AiderAgent) OnFileChange(path string) (*agent.SessionChange, error) {
+	if filepath.Base(path) != ChatHistoryFileName {
+		return nil, nil
+	}
+
+	session, err := readChatSession(path, "")
+	if err != nil || session == nil {
+		return nil, err
+	}
+
+	eventType := agent.HookSessionStart
+	for i := len(session.Messages) - 1; i >= 0; i-- {
+		if session.Messages[i].Role == RoleUser {
+			if i == len(session.Messages)-1 {
+				eventType = agent.HookUserPromptSubmit
+			} else {
+				eventType = agent.HookStop
+			}
+			break
+		}
+	}
+
+	timestamp := time.Now()
+	if info, err := os.Stat(path); err == nil {
+		timestamp = info.ModTime()
+	}
+
+	return &agent.SessionChange{
+		SessionID:  session.ID,
+		SessionRef: path,
+		EventType:  eventType,
+		Timestamp:  timestamp,
+	}, nil
+}
+
+// TranscriptAnalyzer interface implementation
+
+// GetTranscriptPosition returns the number of messages in the most recent
+// session of a chat history. Returns 0 if the file doesn't exist or is empty.
+func (a *AiderAgent) GetTranscriptPosition(path string) (int, error) {
+	if path == "" {
+		return 0, nil
+	}
+	session, err := readChatSession(path, "")
+	if err != nil || session == nil {
+		return 0, err
+	}
+	return len(session.Messages), nil
+}
+
+// ExtractModifiedFilesFromOffset extracts files edited since a given message index
+// of the most recent session.
+// Returns:
+//   - files: list of file paths from SEARCH/REPLACE blocks and applied edits
+//   - currentPosition: total number of messages in the session
+//   - error: any error encountered during reading
+func (a *AiderAgent) ExtractModifiedFilesFromOffset(path string, startOffset int) (files []string, currentPosition int, err error) {
+	if path == "" {
+		return nil, 0, nil
+	}
+	session, err := readChatSession(path, "")
+	if err != nil || session == nil {
+		return nil, 0, err
+	}
+	startOffset = min(max(startOffset, 0), len(session.Messages))
+	return ExtractModifiedFiles(session.Messages[startOffset:]), len(session.Messages), nil
+}
+
+// TranscriptChunker interface implementation
+
+// ChunkTranscript splits a chat history at message boundaries. A message larger
+// than maxSize is split at line boundaries (or mid-line as a last resort);
+// Markdown has no structure that splitting could break.
+func (a *AiderAgent) ChunkTranscript(content []byte, maxSize int) ([][]byte, error) {
+	if len(content) <= maxSize {
+		return [][]byte{content}, nil
+	}
+
+	var chunks [][]byte
+	var current []byte
+	for _, block := range splitMessageBlocks(content) {
+		if len(current) > 0 && len(current)+len(block) > maxSize {
+			chunks = append(chunks, current)
+			current = nil
+		}
+		for len(block) > maxSize {
+			cut := bytes.LastIndexByte(block[:maxSize], '\n') + 1
+			if cut <= 0 {
+				cut = maxSize
+			}
+			chunks = append(chunks, block[:cut])
+			block = block[cut:]
+		}
+		current = append(current, block...)
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks, nil
+}
+
+// ReassembleTranscript concatenates chat history chunks.
+func (a *AiderAgent) ReassembleTranscript(chunks [][]byte) ([]byte, error) {
+	return bytes.Join(chunks, nil), nil
+}
+
+// TranscriptParser interface implementation
+
+// ParseEntries normalizes the most recent session of a chat history (such as a
+// stored transcript, which holds one session) into entries, one per message.
+//
+//nolint:unparam // error return is required by interface, kept for consistency
+func (a *AiderAgent) ParseEntries(data []byte) ([]agent.SessionEntry, error) {
+	sessions := ParseChatHistory(data)
+	if len(sessions) == 0 {
+		return nil, nil
+	}
+	return ToSessionEntries(sessions[len(sessions)-1]), nil
+}
@@ -0,0 +1,292 @@
+package aider
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+// initRepo creates a git repository, makes it the working directory, and returns its path.
+func initRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	cmd := exec.Command("git", "init", "-q", dir)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v\n%s", err, output)
+	}
+	t.Chdir(dir)
+	paths.ClearRepoRootCache()
+	return dir
+}
+
+func writeHistory(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, ChatHistoryFileName)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write chat history: %v", err)
+	}
+	return path
+}
+
+func TestName(t *testing.T) {
+	ag := &AiderAgent{}
+	if name := ag.Name(); name != agent.AgentNameAider {
+		t.Errorf("Name() = %q, want %q", name, agent.AgentNameAider)
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	ag, err := agent.Get(agent.AgentNameAider)
+	if err != nil {
+		t.Fatalf("Get(%q) error = %v", agent.AgentNameAider, err)
+	}
+	if _, ok := ag.(agent.FileWatcher); !ok {
+		t.Error("AiderAgent does not implement FileWatcher")
+	}
+	if _, ok := ag.(agent.TranscriptAnalyzer); !ok {
+		t.Error("AiderAgent does not implement TranscriptAnalyzer")
+	}
+	if _, ok := ag.(agent.TranscriptChunker); !ok {
+		t.Error("AiderAgent does not implement TranscriptChunker")
+	}
+	if ag.SupportsHooks() {
+		t.Error("SupportsHooks() = true, want false")
+	}
+}
+
+func TestDetectPresence(t *testing.T) {
+	dir := initRepo(t)
+	ag := &AiderAgent{}
+
+	present, err := ag.DetectPresence()
+	if err != nil {
+		t.Fatalf("DetectPresence() error = %v", err)
+	}
+	if present {
+		t.Error("DetectPresence() = true, want false")
+	}
+
+	writeHistory(t, dir, "")
+	present, err = ag.DetectPresence()
+	if err != nil {
+		t.Fatalf("DetectPresence() error = %v", err)
+	}
+	if !present {
+		t.Error("DetectPresence() = false, want true")
+	}
+}
+
+func TestReadSession(t *testing.T) {
+	dir := t.TempDir()
+	path := writeHistory(t, dir, sampleChatHistory)
+	ag := &AiderAgent{}
+
+	// Without a known session ID, the most recent session is read
+	session, err := ag.ReadSession(&agent.HookInput{SessionID: "unknown", SessionRef: path})
+	if err != nil {
+		t.Fatalf("ReadSession() error = %v", err)
+	}
+	if !bytes.HasPrefix(session.NativeData, []byte("# aider chat started at 2025-01-11")) {
+		t.Errorf("NativeData should hold the latest session, got %q", session.NativeData[:40])
+	}
+	if len(session.Entries) != 4 {
+		t.Errorf("got %d entries, want 4", len(session.Entries))
+	}
+	if len(session.ModifiedFiles) != 1 || session.ModifiedFiles[0] != "util/helpers.go" {
+		t.Errorf("ModifiedFiles = %v, want [util/helpers.go]", session.ModifiedFiles)
+	}
+
+	session, err = ag.ReadSession(&agent.HookInput{SessionID: "aider-20250110-090000", SessionRef: path})
+	if err != nil {
+		t.Fatalf("ReadSession() error = %v", err)
+	}
+	if len(session.ModifiedFiles) != 1 || session.ModifiedFiles[0] != "main.go" {
+		t.Errorf("ModifiedFiles = %v, want [main.go]", session.ModifiedFiles)
+	}
+}
+
+func TestReadSession_Missing(t *testing.T) {
+	ag := &AiderAgent{}
+	_, err := ag.ReadSession(&agent.HookInput{SessionRef: filepath.Join(t.TempDir(), ChatHistoryFileName)})
+	if err == nil {
+		t.Error("ReadSession() should error when the history doesn't exist")
+	}
+}
+
+func TestParseEntries(t *testing.T) {
+	t.Parallel()
+
+	// A stored transcript is the session's Markdown; positions are message
+	// indexes, not lines
+	entries, err := (&AiderAgent{}).ParseEntries([]byte(sampleChatHistory))
+	if err != nil {
+		t.Fatalf("ParseEntries() error = %v", err)
+	}
+	if got := agent.TranscriptLength(entries); got != 4 {
+		t.Errorf("TranscriptLength() = %d, want 4 messages", got)
+	}
+	if prompts := agent.UserPrompts(entries); len(prompts) != 2 {
+		t.Errorf("UserPrompts() = %q, want 2 prompts", prompts)
+	}
+}
+
+func TestWriteSession(t *testing.T) {
+	src := writeHistory(t, t.TempDir(), sampleChatHistory)
+	ag := &AiderAgent{}
+	session, err := ag.ReadSession(&agent.HookInput{SessionRef: src})
+	if err != nil {
+		t.Fatalf("ReadSession() error = %v", err)
+	}
+
+	dir := t.TempDir()
+	existing := "# aider chat started at 2025-01-01 08:00:00\n\n#### hi"
+	dest := writeHistory(t, dir, existing)
+
+	// A stored transcript path resolves to the chat history in its directory
+	session.SessionRef = filepath.Join(dir, "full.jsonl")
+	if err := ag.WriteSession(session); err != nil {
+		t.Fatalf("WriteSession() error = %v", err)
+	}
+	if err := ag.WriteSession(session); err != nil {
+		t.Fatalf("WriteSession() second call error = %v", err)
+	}
+
+	data, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatalf("failed to read chat history: %v", err)
+	}
+	if !strings.HasPrefix(string(data), existing+"\n\n") {
+		t.Errorf("existing history not preserved: %q", data)
+	}
+	if strings.Count(string(data), string(session.NativeData)) != 1 {
+		t.Error("session should be appended exactly once")
+	}
+	sessions := ParseChatHistory(data)
+	if len(sessions) != 2 || sessions[1].ID != "aider-20250111-143005" {
+		t.Errorf("restored history sessions = %d, want the written session last", len(sessions))
+	}
+}
+
+func TestWriteSession_WrongAgent(t *testing.T) {
+	ag := &AiderAgent{}
+	err := ag.WriteSession(&agent.AgentSession{
+		AgentName:  agent.AgentNameClaudeCode,
+		SessionRef: filepath.Join(t.TempDir(), ChatHistoryFileName),
+		NativeData: []byte("data"),
+	})
+	if err == nil {
+		t.Error("WriteSession() should reject sessions from other agents")
+	}
+}
+
+func TestOnFileChange(t *testing.T) {
+	dir := t.TempDir()
+	ag := &AiderAgent{}
+
+	tests := []struct {
+		name    string
+		history string
+		want    agent.HookType
+	}{
+		{"new session", "# aider chat started at 2025-01-11 14:30:05\n\n> Aider v0.70.0\n", agent.HookSessionStart},
+		{"pending prompt", "# aider chat started at 2025-01-11 14:30:05\n\n#### fix the bug\n", agent.HookUserPromptSubmit},
+		{"response", sampleChatHistory, agent.HookStop},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeHistory(t, dir, tt.history)
+			change, err := ag.OnFileChange(path)
+			if err != nil {
+				t.Fatalf("OnFileChange() error = %v", err)
+			}
+			if change == nil {
+				t.Fatal("OnFileChange() returned nil")
+			}
+			if change.EventType != tt.want {
+				t.Errorf("EventType = %v, want %v", change.EventType, tt.want)
+			}
+			if change.SessionID != "aider-20250111-143005" {
+				t.Errorf("SessionID = %q, want aider-20250111-143005", change.SessionID)
+			}
+			if change.SessionRef != path {
+				t.Errorf("SessionRef = %q, want %q", change.SessionRef, path)
+			}
+		})
+	}
+}
+
+func TestOnFileChange_Ignored(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, InputHistoryFileName)
+	if err := os.WriteFile(path, []byte("# 2025-01-11 14:30:10.0\n+hi\n"), 0o644); err != nil {
+		t.Fatalf("failed to write input history: %v", err)
+	}
+	change, err := (&AiderAgent{}).OnFileChange(path)
+	if err != nil || change != nil {
+		t.Errorf("OnFileChange() = (%v, %v), want (nil, nil)", change, err)
+	}
+}
+
+func TestExtractModifiedFilesFromOffset(t *testing.T) {
+	path := writeHistory(t, t.TempDir(), sampleChatHistory)
+	ag := &AiderAgent{}
+
+	pos, err := ag.GetTranscriptPosition(path)
+	if err != nil {
+		t.Fatalf("GetTranscriptPosition() error = %v", err)
+	}
+	if pos != 4 {
+		t.Errorf("GetTranscriptPosition() = %d, want 4", pos)
+	}
+
+	files, current, err := ag.ExtractModifiedFilesFromOffset(path, 2)
+	if err != nil {
+		t.Fatalf("ExtractModifiedFilesFromOffset() error = %v", err)
+	}
+	if current != 4 {
+		t.Errorf("currentPosition = %d, want 4", current)
+	}
+	if len(files) != 1 || files[0] != "util/helpers.go" {
+		t.Errorf("files = %v, want [util/helpers.go]", files)
+	}
+
+	files, _, err = ag.ExtractModifiedFilesFromOffset(path, 10)
+	if err != nil {
+		t.Fatalf("ExtractModifiedFilesFromOffset() error = %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("files past the end = %v, want none", files)
+	}
+}
+
+func TestChunkTranscript_RoundTrip(t *testing.T) {
+	ag := &AiderAgent{}
+	content := []byte(sampleChatHistory + "\n#### " + strings.Repeat("long prompt line\n", 20))
+
+	chunks, err := ag.ChunkTranscript(content, 120)
+	if err != nil {
+		t.Fatalf("ChunkTranscript() error = %v", err)
+	}
+	if len(chunks) < 2 {
+		t.Fatalf("ChunkTranscript() got %d chunks, want several", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if len(chunk) > 120 {
+			t.Errorf("chunk %d has %d bytes, exceeding the limit", i, len(chunk))
+		}
+	}
+
+	reassembled, err := ag.ReassembleTranscript(chunks)
+	if err != nil {
+		t.Fatalf("ReassembleTranscript() error = %v", err)
+	}
+	if !bytes.Equal(reassembled, content) {
+		t.Error("reassembled transcript differs from the original")
+	}
+}
@@ -0,0 +1,304 @@
+package aider
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+// searchMarkerRegex matches the line opening a SEARCH/REPLACE block
+var searchMarkerRegex = regexp.MustCompile(`^<{5,9} SEARCH\s*$`)
+
+// appliedEditRegex matches aider's confirmation that it wrote a file
+var appliedEditRegex = regexp.MustCompile(`^Applied edit to (.+)$`)
+
+// chatParser accumulates sessions and messages while scanning the chat history.
+type chatParser struct {
+	data     []byte
+	sessions []ChatSession
+	// boundaries are the byte offsets where sessions and messages start
+	boundaries []int
+
+	sessionStart int
+	role         Role
+	lines        []string
+	lastBlank    bool
+	inFence      bool
+}
+
+// ParseChatHistory parses .aider.chat.history.md into sessions, oldest first.
+// Content before the first session header (e.g. from a truncated file) forms a
+// session of its own.
+func ParseChatHistory(data []byte) []ChatSession {
+	p := parseChatHistory(data)
+	return p.sessions
+}
+
+func parseChatHistory(data []byte) *chatParser {
+	p := &chatParser{data: data}
+	offset := 0
+	for len(data[offset:]) > 0 {
+		end := bytes.IndexByte(data[offset:], '\n')
+		next := len(data)
+		if end >= 0 {
+			next = offset + end + 1
+		}
+		p.line(strings.TrimRight(string(data[offset:next]), "\r\n"), offset)
+		offset = next
+	}
+	p.finishSession(len(data))
+	return p
+}
+
+func (p *chatParser) line(line string, offset int) {
+	if !p.inFence && strings.HasPrefix(line, sessionHeaderPrefix) {
+		p.finishSession(offset)
+		p.startSession(strings.TrimPrefix(line, sessionHeaderPrefix), offset)
+		return
+	}
+	if strings.TrimSpace(line) == "" {
+		if p.role != "" {
+			p.lines = append(p.lines, "")
+		}
+		p.lastBlank = true
+		return
+	}
+
+	if len(p.sessions) == 0 {
+		p.startSession("", offset)
+	}
+
+	role, text := classifyLine(line, p.inFence)
+	if role == RoleAssistant && strings.HasPrefix(strings.TrimSpace(line), fencePrefix) {
+		p.inFence = !p.inFence
+	}
+
+	// A new message starts when the role changes, or for a prompt after a blank line
+	if role != p.role || (role == RoleUser && p.lastBlank) {
+		p.finishMessage()
+		p.role = role
+		p.boundaries = append(p.boundaries, offset)
+	}
+	p.lines = append(p.lines, text)
+	p.lastBlank = false
+}
+
+// classifyLine returns the role of a non-blank line and its text without the marker.
+func classifyLine(line string, inFence bool) (Role, string) {
+	switch {
+	case inFence:
+		return RoleAssistant, line
+	case line == userLinePrefix || strings.HasPrefix(line, userLinePrefix+" "):
+		return RoleUser, strings.TrimPrefix(strings.TrimPrefix(line, userLinePrefix), " ")
+	case line == toolLinePrefix || strings.HasPrefix(line, toolLinePrefix+" "):
+		return RoleTool, strings.TrimPrefix(strings.TrimPrefix(line, toolLinePrefix), " ")
+	default:
+		return RoleAssistant, line
+	}
+}
+
+func (p *chatParser) startSession(header string, offset int) {
+	session := ChatSession{}
+	if startedAt, err := time.ParseInLocation(headerTimeLayout, strings.TrimSpace(header), time.Local); err == nil {
+		session.StartedAt = startedAt
+		session.ID = sessionIDPrefix + startedAt.Format(sessionIDLayout)
+	} else {
+		session.ID = sessionIDPrefix + "session-" + strconv.Itoa(len(p.sessions)+1)
+	}
+	p.sessions = append(p.sessions, session)
+	p.sessionStart = offset
+	p.boundaries = append(p.boundaries, offset)
+	p.role = ""
+	p.lines = nil
+	p.lastBlank = false
+	p.inFence = false
+}
+
+func (p *chatParser) finishMessage() {
+	if p.role == "" {
+		return
+	}
+	content := strings.TrimSpace(strings.Join(p.lines, "\n"))
+	if content != "" {
+		session := &p.sessions[len(p.sessions)-1]
+		session.Messages = append(session.Messages, Message{Role: p.role, Content: content})
+	}
+	p.role = ""
+	p.lines = nil
+}
+
+func (p *chatParser) finishSession(offset int) {
+	if len(p.sessions) == 0 {
+		return
+	}
+	p.finishMessage()
+	p.sessions[len(p.sessions)-1].Raw = p.data[p.sessionStart:offset]
+}
+
+// ParseInputHistory parses .aider.input.history into prompts, oldest first.
+func ParseInputHistory(data []byte) []inputEntry {
+	var entries []inputEntry
+	var current *inputEntry
+	var lines []string
+	flush := func() {
+		if current != nil {
+			current.Text = strings.TrimSpace(strings.Join(lines, "\n"))
+			entries = append(entries, *current)
+		}
+		current = nil
+		lines = nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, inputHeaderPrefix):
+			flush()
+			ts, err := time.ParseInLocation(inputTimeLayout, strings.TrimPrefix(line, inputHeaderPrefix), time.Local)
+			if err != nil {
+				continue // Not a prompt header
+			}
+			current = &inputEntry{Timestamp: ts}
+		case strings.HasPrefix(line, inputLinePrefix) && current != nil:
+			lines = append(lines, strings.TrimPrefix(line, inputLinePrefix))
+		}
+	}
+	flush()
+	return entries
+}
+
+// applyInputHistory sets the timestamps of user messages from the input history.
+// Prompts are matched by text, in order, starting from the session's start time.
+func applyInputHistory(session *ChatSession, entries []inputEntry) {
+	next := 0
+	for i := range session.Messages {
+		msg := &session.Messages[i]
+		if msg.Role != RoleUser {
+			continue
+		}
+		for j := next; j < len(entries); j++ {
+			entry := entries[j]
+			if !session.StartedAt.IsZero() && entry.Timestamp.Before(session.StartedAt.Add(-time.Second)) {
+				continue
+			}
+			if entry.Text == msg.Content {
+				msg.Timestamp = entry.Timestamp
+				next = j + 1
+				break
+			}
+		}
+	}
+}
+
+// ExtractEditedFiles returns the files targeted by SEARCH/REPLACE blocks in a response.
+// Aider puts the file path on its own line just before the block's opening fence.
+func ExtractEditedFiles(content string) []string {
+	lines := strings.Split(content, "\n")
+	var files []string
+	for i, line := range lines {
+		if !searchMarkerRegex.MatchString(strings.TrimSpace(line)) {
+			continue
+		}
+		for j := i - 1; j >= 0 && j >= i-3; j-- {
+			candidate := strings.TrimSpace(lines[j])
+			if candidate == "" || strings.HasPrefix(candidate, fencePrefix) {
+				continue
+			}
+			if file := strings.Trim(candidate, "`*:"); file != "" {
+				files = append(files, file)
+			}
+			break
+		}
+	}
+	return files
+}
+
+// appliedEdits returns the files aider reports writing in its output.
+func appliedEdits(content string) []string {
+	var files []string
+	for _, line := range strings.Split(content, "\n") {
+		if m := appliedEditRegex.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			files = append(files, strings.TrimSpace(m[1]))
+		}
+	}
+	return files
+}
+
+// messageFiles returns the files a message shows being edited.
+func messageFiles(msg Message) []string {
+	switch msg.Role {
+	case RoleAssistant:
+		return ExtractEditedFiles(msg.Content)
+	case RoleTool:
+		return appliedEdits(msg.Content)
+	case RoleUser:
+	}
+	return nil
+}
+
+// ExtractModifiedFiles returns files edited in the given messages, in first-seen order.
+func ExtractModifiedFiles(messages []Message) []string {
+	fileSet := make(map[string]bool)
+	var files []string
+	for _, msg := range messages {
+		for _, file := range messageFiles(msg) {
+			if !fileSet[file] {
+				fileSet[file] = true
+				files = append(files, file)
+			}
+		}
+	}
+	return files
+}
+
+// ToSessionEntries normalizes a session's messages into agent session entries,
+// one per message, so entry indexes match transcript positions.
+// Entry UUIDs are "<session-id>-<message-index>".
+func ToSessionEntries(session ChatSession) []agent.SessionEntry {
+	entries := make([]agent.SessionEntry, 0, len(session.Messages))
+	for i, msg := range session.Messages {
+		entry := agent.SessionEntry{
+			UUID:          fmt.Sprintf("%s-%d", session.ID, i),
+			Position:      i,
+			Timestamp:     msg.Timestamp,
+			Content:       msg.Content,
+			FilesAffected: messageFiles(msg),
+		}
+		switch msg.Role {
+		case RoleUser:
+			entry.Type = agent.EntryUser
+		case RoleAssistant:
+			entry.Type = agent.EntryAssistant
+		case RoleTool:
+			entry.Type = agent.EntrySystem
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// splitMessageBlocks splits chat history Markdown into consecutive pieces that
+// each start at a session header or message. Concatenating them yields the input.
+func splitMessageBlocks(content []byte) [][]byte {
+	boundaries := parseChatHistory(content).boundaries
+	var blocks [][]byte
+	start := 0
+	for _, b := range boundaries {
+		if b > start {
+			blocks = append(blocks, content[start:b])
+			start = b
+		}
+	}
+	if start < len(content) {
+		blocks = append(blocks, content[start:])
+	}
+	return blocks
+}
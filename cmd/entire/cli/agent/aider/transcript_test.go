@@ -0,0 +1,211 @@
+package aider
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+const sampleChatHistory = `
+# aider chat started at 2025-01-10 09:00:00
+
+> Aider v0.70.0
+> Main model: claude-3-5-sonnet with diff edit format
+
+#### add a greeting
+
+main.go
+` + "```go" + `
+<<<<<<< SEARCH
+func main() {
+=======
+func main() {
+	fmt.Println("hello")
+>>>>>>> REPLACE
+` + "```" + `
+
+> Applied edit to main.go
+> Commit 1a2b3c4 feat: add greeting
+
+# aider chat started at 2025-01-11 14:30:05
+
+#### explain the layout
+
+The project has a single entrypoint.
+
+#### rename the helper
+#### across all files
+
+` + "```" + `
+# aider chat started at is just text inside a fence
+` + "```" + `
+
+util/helpers.go
+` + "```go" + `
+<<<<<<< SEARCH
+func helper() {}
+=======
+func renamedHelper() {}
+>>>>>>> REPLACE
+` + "```" + `
+`
+
+func TestParseChatHistory(t *testing.T) {
+	t.Parallel()
+
+	sessions := ParseChatHistory([]byte(sampleChatHistory))
+	if len(sessions) != 2 {
+		t.Fatalf("ParseChatHistory() got %d sessions, want 2", len(sessions))
+	}
+
+	first := sessions[0]
+	if first.ID != "aider-20250110-090000" {
+		t.Errorf("first session ID = %q, want aider-20250110-090000", first.ID)
+	}
+	wantRoles := []Role{RoleTool, RoleUser, RoleAssistant, RoleTool}
+	if len(first.Messages) != len(wantRoles) {
+		t.Fatalf("first session got %d messages, want %d: %+v", len(first.Messages), len(wantRoles), first.Messages)
+	}
+	for i, role := range wantRoles {
+		if first.Messages[i].Role != role {
+			t.Errorf("first session message %d role = %q, want %q", i, first.Messages[i].Role, role)
+		}
+	}
+	if first.Messages[1].Content != "add a greeting" {
+		t.Errorf("prompt content = %q, want %q", first.Messages[1].Content, "add a greeting")
+	}
+
+	second := sessions[1]
+	if second.ID != "aider-20250111-143005" {
+		t.Errorf("second session ID = %q, want aider-20250111-143005", second.ID)
+	}
+	// Two prompts, two responses; the header inside the fence is response content
+	if len(second.Messages) != 4 {
+		t.Fatalf("second session got %d messages, want 4: %+v", len(second.Messages), second.Messages)
+	}
+	if second.Messages[2].Content != "rename the helper\nacross all files" {
+		t.Errorf("multi-line prompt = %q", second.Messages[2].Content)
+	}
+
+	// Raw spans cover the file from the first header
+	joined := append(append([]byte{}, first.Raw...), second.Raw...)
+	if !bytes.HasSuffix([]byte(sampleChatHistory), joined) {
+		t.Error("session Raw data does not cover the history")
+	}
+	if !bytes.HasPrefix(second.Raw, []byte(sessionHeaderPrefix)) {
+		t.Errorf("second session Raw should start at its header, got %q", second.Raw[:20])
+	}
+}
+
+func TestParseChatHistory_NoHeader(t *testing.T) {
+	t.Parallel()
+
+	sessions := ParseChatHistory([]byte("#### hello\n\nhi there\n"))
+	if len(sessions) != 1 {
+		t.Fatalf("ParseChatHistory() got %d sessions, want 1", len(sessions))
+	}
+	if sessions[0].ID != "aider-session-1" {
+		t.Errorf("session ID = %q, want aider-session-1", sessions[0].ID)
+	}
+	if len(sessions[0].Messages) != 2 {
+		t.Errorf("got %d messages, want 2", len(sessions[0].Messages))
+	}
+}
+
+func TestParseChatHistory_Empty(t *testing.T) {
+	t.Parallel()
+
+	if sessions := ParseChatHistory([]byte("\n\n")); len(sessions) != 0 {
+		t.Errorf("ParseChatHistory() got %d sessions, want 0", len(sessions))
+	}
+}
+
+func TestExtractEditedFiles(t *testing.T) {
+	t.Parallel()
+
+	content := "I'll update both files.\n\n" +
+		"src/app.py\n```python\n<<<<<<< SEARCH\nold\n=======\nnew\n>>>>>>> REPLACE\n```\n\n" +
+		"`README.md`\n```\n<<<<<<< SEARCH\n=======\n# Title\n>>>>>>> REPLACE\n```\n"
+
+	files := ExtractEditedFiles(content)
+	if len(files) != 2 || files[0] != "src/app.py" || files[1] != "README.md" {
+		t.Errorf("ExtractEditedFiles() = %v, want [src/app.py README.md]", files)
+	}
+}
+
+func TestExtractModifiedFiles(t *testing.T) {
+	t.Parallel()
+
+	sessions := ParseChatHistory([]byte(sampleChatHistory))
+	files := ExtractModifiedFiles(sessions[0].Messages)
+	// main.go appears in the edit block and the "Applied edit" line; deduplicated
+	if len(files) != 1 || files[0] != "main.go" {
+		t.Errorf("ExtractModifiedFiles() = %v, want [main.go]", files)
+	}
+}
+
+func TestParseInputHistory(t *testing.T) {
+	t.Parallel()
+
+	data := []byte("\n# 2025-01-11 14:30:10.123456\n+explain the layout\n\n# 2025-01-11 14:31:00.5\n+rename the helper\n+across all files\n")
+	entries := ParseInputHistory(data)
+	if len(entries) != 2 {
+		t.Fatalf("ParseInputHistory() got %d entries, want 2", len(entries))
+	}
+	if entries[1].Text != "rename the helper\nacross all files" {
+		t.Errorf("entry text = %q", entries[1].Text)
+	}
+	want := time.Date(2025, 1, 11, 14, 30, 10, 123456000, time.Local)
+	if !entries[0].Timestamp.Equal(want) {
+		t.Errorf("entry timestamp = %v, want %v", entries[0].Timestamp, want)
+	}
+}
+
+func TestToSessionEntries(t *testing.T) {
+	t.Parallel()
+
+	sessions := ParseChatHistory([]byte(sampleChatHistory))
+	session := sessions[1]
+	applyInputHistory(&session, ParseInputHistory([]byte(
+		"# 2025-01-10 09:00:01.0\n+rename the helper\n+across all files\n"+
+			"# 2025-01-11 14:31:00.0\n+rename the helper\n+across all files\n")))
+
+	entries := ToSessionEntries(session)
+	if len(entries) != 4 {
+		t.Fatalf("ToSessionEntries() got %d entries, want 4", len(entries))
+	}
+	wantTypes := []agent.EntryType{agent.EntryUser, agent.EntryAssistant, agent.EntryUser, agent.EntryAssistant}
+	for i, want := range wantTypes {
+		if entries[i].Type != want {
+			t.Errorf("entry %d type = %q, want %q", i, entries[i].Type, want)
+		}
+		if entries[i].Position != i {
+			t.Errorf("entry %d position = %d, want %d", i, entries[i].Position, i)
+		}
+	}
+	if entries[0].UUID != "aider-20250111-143005-0" {
+		t.Errorf("entry UUID = %q", entries[0].UUID)
+	}
+	// The prompt from an earlier session is not matched
+	want := time.Date(2025, 1, 11, 14, 31, 0, 0, time.Local)
+	if !entries[2].Timestamp.Equal(want) {
+		t.Errorf("prompt timestamp = %v, want %v", entries[2].Timestamp, want)
+	}
+	if len(entries[3].FilesAffected) != 1 || entries[3].FilesAffected[0] != "util/helpers.go" {
+		t.Errorf("FilesAffected = %v, want [util/helpers.go]", entries[3].FilesAffected)
+	}
+}
+
+func TestSplitMessageBlocks(t *testing.T) {
+	t.Parallel()
+
+	blocks := splitMessageBlocks([]byte(sampleChatHistory))
+	if len(blocks) < 8 {
+		t.Errorf("splitMessageBlocks() got %d blocks, want at least 8", len(blocks))
+	}
+	if got := bytes.Join(blocks, nil); !bytes.Equal(got, []byte(sampleChatHistory)) {
+		t.Error("blocks do not reassemble to the input")
+	}
+}
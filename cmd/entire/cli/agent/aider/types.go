@@ -0,0 +1,62 @@
+package aider
+
+import "time"
+
+// Aider stores its history in the directory it runs in (normally the repo root):
+//   - .aider.chat.history.md is a Markdown log of every chat. Each run starts with
+//     a "# aider chat started at <time>" header; user prompts are prefixed with
+//     "#### ", aider's own output (tool results, applied edits, commits) with
+//     "> ", and everything else is the model's response.
+//   - .aider.input.history records each prompt under a "# <time>" header, with
+//     every line prefixed by "+". It's the only source of prompt timestamps.
+
+// History file names and line markers
+const (
+	ChatHistoryFileName  = ".aider.chat.history.md"
+	InputHistoryFileName = ".aider.input.history"
+	configFileName       = ".aider.conf.yml"
+
+	sessionHeaderPrefix = "# aider chat started at "
+	userLinePrefix      = "####"
+	toolLinePrefix      = ">"
+	inputHeaderPrefix   = "# "
+	inputLinePrefix     = "+"
+	fencePrefix         = "```"
+
+	sessionIDPrefix  = "aider-"
+	sessionIDLayout  = "20060102-150405"
+	headerTimeLayout = "2006-01-02 15:04:05"
+	inputTimeLayout  = "2006-01-02 15:04:05.999999"
+)
+
+// Role identifies who produced a message in the chat history
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleAssistant Role = "assistant"
+	RoleTool      Role = "tool"
+)
+
+// Message is a single message in an aider chat.
+// Timestamp is only known for user messages found in the input history.
+type Message struct {
+	Role      Role
+	Content   string
+	Timestamp time.Time
+}
+
+// ChatSession is one aider run in the chat history.
+// Raw holds the session's Markdown, from its header up to the next session.
+type ChatSession struct {
+	ID        string
+	StartedAt time.Time
+	Messages  []Message
+	Raw       []byte
+}
+
+// inputEntry is a prompt recorded in the input history
+type inputEntry struct {
+	Timestamp time.Time
+	Text      string
+}
@@ -180,7 +180,7 @@ func (c *ClaudeCodeAgent) GetSessionDir(repoPath string) (string, error) {
 
 // ReadSession reads a session from Claude's storage (JSONL transcript file).
 // The session data is stored in NativeData as raw JSONL bytes.
-// ModifiedFiles is computed by parsing the transcript.
+// ModifiedFiles and Entries are computed by parsing the transcript.
 func (c *ClaudeCodeAgent) ReadSession(input *agent.HookInput) (*agent.AgentSession, error) {
 	if input.SessionRef == "" {
 		return nil, errors.New("session reference (transcript path) is required")
@@ -197,6 +197,10 @@ func (c *ClaudeCodeAgent) ReadSession(input *agent.HookInput) (*agent.AgentSessi
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse transcript: %w", err)
 	}
+	entries, err := ToSessionEntries(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse transcript: %w", err)
+	}
 
 	return &agent.AgentSession{
 		SessionID:     input.SessionID,
@@ -205,6 +209,7 @@ func (c *ClaudeCodeAgent) ReadSession(input *agent.HookInput) (*agent.AgentSessi
 		StartTime:     time.Now(),
 		NativeData:    data,
 		ModifiedFiles: ExtractModifiedFiles(lines),
+		Entries:       entries,
 	}, nil
 }
 
@@ -445,3 +450,31 @@ func (c *ClaudeCodeAgent) ChunkTranscript(content []byte, maxSize int) ([][]byte
 func (c *ClaudeCodeAgent) ReassembleTranscript(chunks [][]byte) ([]byte, error) {
 	return agent.ReassembleJSONL(chunks), nil
 }
+
+// TranscriptParser interface implementation
+
+// ParseEntries normalizes a JSONL transcript into session entries positioned by line.
+func (c *ClaudeCodeAgent) ParseEntries(data []byte) ([]agent.SessionEntry, error) {
+	return ToSessionEntries(data)
+}
+
+// TranscriptBuilder interface implementation
+
+// BuildTranscript renders session entries recorded by another agent as a JSONL
+// transcript. Tool calls are down-converted to text, since Claude Code can't
+// replay another agent's tools.
+func (c *ClaudeCodeAgent) BuildTranscript(session *agent.AgentSession) ([]byte, error) {
+	return BuildTranscript(session.SessionID, session.RepoPath, agent.ConversationTurns(session.Entries))
+}
+
+// ResolveSessionFile returns the JSONL transcript path of a session.
+func (c *ClaudeCodeAgent) ResolveSessionFile(sessionDir, agentSessionID string) string {
+	return filepath.Join(sessionDir, agentSessionID+".jsonl")
+}
+
+// TranscriptForker interface implementation
+
+// ForkTranscript rewrites the sessionId of every line of a JSONL transcript.
+func (c *ClaudeCodeAgent) ForkTranscript(data []byte, agentSessionID string) ([]byte, error) {
+	return ForkTranscript(data, agentSessionID)
+}
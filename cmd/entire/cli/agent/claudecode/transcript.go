@@ -3,14 +3,20 @@ package claudecode
 import (
 	"bufio"
 	"bytes"
+	"cmp"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
 	"github.com/entireio/cli/cmd/entire/cli/transcript"
+
+	"github.com/google/uuid"
 )
 
 // TranscriptLine is an alias to the shared transcript.Line type.
@@ -427,3 +433,219 @@ func CalculateTotalTokenUsage(transcriptPath string, startLine int, subagentsDir
 
 	return mainUsage, nil
 }
+
+// ToSessionEntries normalizes a JSONL transcript into agent session entries.
+// Entry positions are line numbers, matching GetTranscriptPosition; every
+// non-empty line yields at least one entry (a system entry for lines that
+// aren't part of the conversation). An assistant line yields an entry per text
+// and tool_use block, and a user line an entry for its text and one per tool_result.
+// Streamed rows of one API response share a message ID, so its token usage is
+// set on the row with the highest output_tokens only, as in CalculateTokenUsage.
+func ToSessionEntries(data []byte) ([]agent.SessionEntry, error) {
+	type parsedLine struct {
+		position int
+		line     entryLine
+		msg      entryMessage
+	}
+
+	var lines []parsedLine
+	reader := bufio.NewReader(bytes.NewReader(data))
+	for position := 0; ; position++ {
+		raw, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to read transcript: %w", err)
+		}
+		if raw = bytes.TrimSpace(raw); len(raw) > 0 {
+			parsed := parsedLine{position: position}
+			if json.Unmarshal(raw, &parsed.line) == nil && len(parsed.line.Message) > 0 {
+				_ = json.Unmarshal(parsed.line.Message, &parsed.msg) //nolint:errcheck // Lines without a message object become system entries
+			}
+			lines = append(lines, parsed)
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+
+	usageLine := make(map[string]int)
+	for i, l := range lines {
+		if l.line.Type != transcript.TypeAssistant || l.msg.ID == "" || l.msg.Usage == nil {
+			continue
+		}
+		if j, ok := usageLine[l.msg.ID]; !ok || l.msg.Usage.OutputTokens > lines[j].msg.Usage.OutputTokens {
+			usageLine[l.msg.ID] = i
+		}
+	}
+
+	var entries []agent.SessionEntry
+	for i, l := range lines {
+		lineEntries := lineToEntries(l.line, l.msg)
+		if len(lineEntries) == 0 {
+			lineEntries = []agent.SessionEntry{{Type: agent.EntrySystem}}
+		}
+		if j, ok := usageLine[l.msg.ID]; ok && j == i && l.line.Type == transcript.TypeAssistant {
+			u := l.msg.Usage
+			lineEntries[0].TokenUsage = &agent.TokenUsage{
+				InputTokens:         u.InputTokens,
+				CacheCreationTokens: u.CacheCreationInputTokens,
+				CacheReadTokens:     u.CacheReadInputTokens,
+				OutputTokens:        u.OutputTokens,
+				APICallCount:        1,
+			}
+		}
+
+		timestamp, _ := time.Parse(time.RFC3339Nano, l.line.Timestamp) //nolint:errcheck // Zero time if missing
+		for _, entry := range lineEntries {
+			entry.UUID = l.line.UUID
+			entry.Position = l.position
+			entry.Timestamp = timestamp
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// lineToEntries converts the message of a user or assistant line into entries.
+func lineToEntries(line entryLine, msg entryMessage) []agent.SessionEntry {
+	var blocks []entryContentBlock
+	var text string
+	if err := json.Unmarshal(msg.Content, &blocks); err != nil {
+		_ = json.Unmarshal(msg.Content, &text) //nolint:errcheck // Content is either blocks or a string
+	}
+
+	var entries []agent.SessionEntry
+	switch line.Type {
+	case transcript.TypeUser, "human":
+		if content := transcript.ExtractUserContent(line.Message); content != "" {
+			entries = append(entries, agent.SessionEntry{Type: agent.EntryUser, Content: content})
+		}
+		for _, block := range blocks {
+			if block.Type == "tool_result" {
+				entries = append(entries, agent.SessionEntry{
+					Type:       agent.EntryToolResult,
+					ToolUseID:  block.ToolUseID,
+					ToolOutput: toolResultText(block.Content),
+				})
+			}
+		}
+
+	case transcript.TypeAssistant:
+		if text != "" {
+			entries = append(entries, agent.SessionEntry{Type: agent.EntryAssistant, Content: text, Model: msg.Model})
+		}
+		for _, block := range blocks {
+			switch block.Type {
+			case transcript.ContentTypeText:
+				if block.Text != "" {
+					entries = append(entries, agent.SessionEntry{Type: agent.EntryAssistant, Content: block.Text, Model: msg.Model})
+				}
+			case transcript.ContentTypeToolUse:
+				entry := agent.SessionEntry{
+					Type:      agent.EntryTool,
+					Model:     msg.Model,
+					ToolName:  block.Name,
+					ToolUseID: block.ID,
+					ToolInput: block.Input,
+				}
+				if slices.Contains(FileModificationTools, block.Name) {
+					var input toolInput
+					if err := json.Unmarshal(block.Input, &input); err == nil {
+						if file := cmp.Or(input.FilePath, input.NotebookPath); file != "" {
+							entry.FilesAffected = []string{file}
+						}
+					}
+				}
+				entries = append(entries, entry)
+			}
+		}
+	}
+	return entries
+}
+
+// toolResultText returns the text of a tool_result's content, which is a
+// string or an array of text blocks.
+func toolResultText(raw json.RawMessage) string {
+	var str string
+	if err := json.Unmarshal(raw, &str); err == nil {
+		return str
+	}
+	var blocks []entryContentBlock
+	if err := json.Unmarshal(raw, &blocks); err != nil {
+		return ""
+	}
+	var texts []string
+	for _, block := range blocks {
+		if block.Type == transcript.ContentTypeText {
+			texts = append(texts, block.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// BuildTranscript renders conversation turns, e.g. of another agent's session,
+// as a JSONL transcript of sessionID. Lines are chained by parentUuid like the
+// lines Claude Code writes.
+func BuildTranscript(sessionID, cwd string, turns []agent.ConversationTurn) ([]byte, error) {
+	var buf bytes.Buffer
+	var parentUUID *string
+	for _, turn := range turns {
+		timestamp := turn.Timestamp
+		if timestamp.IsZero() {
+			timestamp = time.Now()
+		}
+		line := resumeLine{
+			ParentUUID: parentUUID,
+			UserType:   "external",
+			Cwd:        cwd,
+			SessionID:  sessionID,
+			UUID:       uuid.NewString(),
+			Timestamp:  timestamp.UTC().Format(time.RFC3339Nano),
+		}
+		if turn.Type == agent.EntryUser {
+			line.Type = transcript.TypeUser
+			line.Message = resumeMessage{Role: "user", Content: turn.Content}
+		} else {
+			line.Type = transcript.TypeAssistant
+			line.Message = resumeMessage{
+				Role:    "assistant",
+				Model:   turn.Model,
+				Content: []resumeTextBlock{{Type: transcript.ContentTypeText, Text: turn.Content}},
+			}
+		}
+
+		data, err := json.Marshal(line)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal transcript line: %w", err)
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+		parentUUID = &line.UUID
+	}
+	return buf.Bytes(), nil
+}
+
+// ForkTranscript returns a copy of a JSONL transcript with the sessionId of
+// every line set to sessionID, so Claude Code resumes it as that session.
+// Lines that aren't JSON objects are kept as they are.
+func ForkTranscript(data []byte, sessionID string) ([]byte, error) {
+	sessionIDValue, err := json.Marshal(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal session ID: %w", err)
+	}
+
+	var buf bytes.Buffer
+	for _, line := range bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n")) {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(line, &fields); err == nil && fields != nil {
+			if _, ok := fields["sessionId"]; ok {
+				fields["sessionId"] = sessionIDValue
+				if line, err = json.Marshal(fields); err != nil {
+					return nil, fmt.Errorf("failed to marshal transcript line: %w", err)
+				}
+			}
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
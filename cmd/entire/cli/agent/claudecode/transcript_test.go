@@ -1,10 +1,12 @@
 package claudecode
 
 import (
+	"bytes"
 	"encoding/json"
 	"os"
 	"testing"
 
+	"github.com/entireio/cli/cmd/entire/cli/agent"
 	"github.com/entireio/cli/cmd/entire/cli/transcript"
 )
 
@@ -618,3 +620,158 @@ func TestCalculateTotalTokenUsage_PerCheckpoint(t *testing.T) {
 		t.Errorf("From line 4: got APICallCount=%d, want 1", usage3.APICallCount)
 	}
 }
+
+func TestToSessionEntries(t *testing.T) {
+	t.Parallel()
+
+	// Line 3 is blank and line 6 is a streamed row of the same response as line 4
+	data := []byte(`{"type":"summary","summary":"Earlier work"}
+{"type":"user","uuid":"u1","timestamp":"2025-10-16T09:00:00.000Z","message":{"content":"<ide_opened_file>main.go</ide_opened_file>Fix the bug"}}
+{"type":"assistant","uuid":"a1","message":{"id":"msg_1","model":"claude-sonnet-4","content":[{"type":"text","text":"Looking"},{"type":"tool_use","id":"toolu_1","name":"Edit","input":{"file_path":"main.go"}}],"usage":{"input_tokens":10,"output_tokens":5}}}
+
+{"type":"user","uuid":"u2","message":{"content":[{"type":"tool_result","tool_use_id":"toolu_1","content":[{"type":"text","text":"edited"}]}]}}
+{"type":"assistant","uuid":"a2","message":{"id":"msg_1","model":"claude-sonnet-4","content":[{"type":"text","text":"Done"}],"usage":{"input_tokens":10,"cache_read_input_tokens":3,"output_tokens":20}}}
+not valid json
+`)
+
+	entries, err := ToSessionEntries(data)
+	if err != nil {
+		t.Fatalf("ToSessionEntries() error = %v", err)
+	}
+
+	type want struct {
+		typ      agent.EntryType
+		position int
+	}
+	wants := []want{
+		{agent.EntrySystem, 0},
+		{agent.EntryUser, 1},
+		{agent.EntryAssistant, 2},
+		{agent.EntryTool, 2},
+		{agent.EntryToolResult, 4},
+		{agent.EntryAssistant, 5},
+		{agent.EntrySystem, 6},
+	}
+	if len(entries) != len(wants) {
+		t.Fatalf("got %d entries, want %d: %+v", len(entries), len(wants), entries)
+	}
+	for i, w := range wants {
+		if entries[i].Type != w.typ || entries[i].Position != w.position {
+			t.Errorf("entry %d = %s at %d, want %s at %d", i, entries[i].Type, entries[i].Position, w.typ, w.position)
+		}
+	}
+
+	if entries[1].Content != "Fix the bug" || entries[1].UUID != "u1" || entries[1].Timestamp.IsZero() {
+		t.Errorf("user entry = %+v", entries[1])
+	}
+	tool := entries[3]
+	if tool.ToolName != "Edit" || tool.ToolUseID != "toolu_1" || tool.Model != "claude-sonnet-4" ||
+		len(tool.FilesAffected) != 1 || tool.FilesAffected[0] != "main.go" {
+		t.Errorf("tool entry = %+v", tool)
+	}
+	if result := entries[4]; result.ToolUseID != "toolu_1" || result.ToolOutput != "edited" {
+		t.Errorf("tool result entry = %+v", result)
+	}
+
+	// Usage of the streamed response is counted once, from its final row
+	if entries[2].TokenUsage != nil {
+		t.Errorf("partial row usage = %+v, want nil", entries[2].TokenUsage)
+	}
+	usage := agent.SumTokenUsage(entries)
+	if usage.APICallCount != 1 || usage.OutputTokens != 20 || usage.CacheReadTokens != 3 {
+		t.Errorf("SumTokenUsage() = %+v", usage)
+	}
+	if got := agent.TranscriptLength(entries); got != 7 {
+		t.Errorf("TranscriptLength() = %d, want 7", got)
+	}
+}
+
+func TestToSessionEntries_StringContent(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`{"type":"human","message":{"content":"Hello"}}
+{"type":"assistant","message":{"content":"Hi"}}
+`)
+	entries, err := ToSessionEntries(data)
+	if err != nil {
+		t.Fatalf("ToSessionEntries() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].Type != agent.EntryUser || entries[1].Content != "Hi" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestBuildTranscript(t *testing.T) {
+	t.Parallel()
+
+	data, err := BuildTranscript("sess-1", "/repo", []agent.ConversationTurn{
+		{Type: agent.EntryUser, Content: "Hello"},
+		{Type: agent.EntryAssistant, Content: "Hi there", Model: "gemini-2.5-pro"},
+	})
+	if err != nil {
+		t.Fatalf("BuildTranscript() error = %v", err)
+	}
+
+	lines, err := ParseTranscript(data)
+	if err != nil {
+		t.Fatalf("ParseTranscript() error = %v", err)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	var second resumeLine
+	if err := json.Unmarshal(data[bytes.IndexByte(data, '\n')+1:], &second); err != nil {
+		t.Fatalf("failed to parse second line: %v", err)
+	}
+	if second.ParentUUID == nil || *second.ParentUUID != lines[0].UUID {
+		t.Errorf("second line parentUuid = %v, want %q", second.ParentUUID, lines[0].UUID)
+	}
+	if second.SessionID != "sess-1" || second.Cwd != "/repo" {
+		t.Errorf("second line session = %q, cwd = %q", second.SessionID, second.Cwd)
+	}
+
+	entries, err := ToSessionEntries(data)
+	if err != nil {
+		t.Fatalf("ToSessionEntries() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if entries[0].Type != agent.EntryUser || entries[0].Content != "Hello" {
+		t.Errorf("entry 0 = %+v", entries[0])
+	}
+	if entries[1].Type != agent.EntryAssistant || entries[1].Content != "Hi there" || entries[1].Model != "gemini-2.5-pro" {
+		t.Errorf("entry 1 = %+v", entries[1])
+	}
+}
+
+func TestForkTranscript(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`{"type":"user","uuid":"u1","sessionId":"old","message":{"content":"Hello"}}
+{"type":"summary","summary":"No session"}
+not json
+`)
+	forked, err := ForkTranscript(data, "new")
+	if err != nil {
+		t.Fatalf("ForkTranscript() error = %v", err)
+	}
+
+	lines := bytes.Split(bytes.TrimSuffix(forked, []byte("\n")), []byte("\n"))
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3", len(lines))
+	}
+	var first map[string]any
+	if err := json.Unmarshal(lines[0], &first); err != nil {
+		t.Fatalf("failed to parse first line: %v", err)
+	}
+	if first["sessionId"] != "new" || first["uuid"] != "u1" {
+		t.Errorf("first line = %v", first)
+	}
+	if string(lines[1]) != `{"type":"summary","summary":"No session"}` {
+		t.Errorf("line without sessionId changed: %s", lines[1])
+	}
+	if string(lines[2]) != "not json" {
+		t.Errorf("malformed line changed: %s", lines[2])
+	}
+}
@@ -95,3 +95,58 @@ type messageWithUsage struct {
 	ID    string       `json:"id"`
 	Usage messageUsage `json:"usage"`
 }
+
+// entryLine is a transcript line with the fields normalized into session entries.
+type entryLine struct {
+	Type      string          `json:"type"`
+	UUID      string          `json:"uuid"`
+	Timestamp string          `json:"timestamp"`
+	Message   json.RawMessage `json:"message"`
+}
+
+// entryMessage is the message of a user or assistant line.
+// Content is a string or an array of content blocks.
+type entryMessage struct {
+	ID      string          `json:"id"`
+	Model   string          `json:"model"`
+	Content json.RawMessage `json:"content"`
+	Usage   *messageUsage   `json:"usage"`
+}
+
+// entryContentBlock is a text, tool_use or tool_result content block.
+type entryContentBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+	ToolUseID string          `json:"tool_use_id"`
+	Content   json.RawMessage `json:"content"`
+}
+
+// resumeLine is a transcript line built from another agent's session,
+// with the fields Claude Code needs to resume it.
+type resumeLine struct {
+	ParentUUID  *string       `json:"parentUuid"`
+	IsSidechain bool          `json:"isSidechain"`
+	UserType    string        `json:"userType"`
+	Cwd         string        `json:"cwd,omitempty"`
+	SessionID   string        `json:"sessionId"`
+	Type        string        `json:"type"`
+	Message     resumeMessage `json:"message"`
+	UUID        string        `json:"uuid"`
+	Timestamp   string        `json:"timestamp"`
+}
+
+// resumeMessage is the message of a resumeLine. Content is a string for user
+// messages and text blocks for assistant messages.
+type resumeMessage struct {
+	Role    string      `json:"role"`
+	Model   string      `json:"model,omitempty"`
+	Content interface{} `json:"content"`
+}
+
+type resumeTextBlock struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
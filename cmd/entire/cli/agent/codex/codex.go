@@ -0,0 +1,434 @@
+// Package codex implements the Agent interface for OpenAI's Codex CLI.
+package codex
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/sessionid"
+)
+
+// ErrUnsupportedNotification is returned by ParseHookInput for notifications
+// other than the end of a turn, which Entire ignores.
+var ErrUnsupportedNotification = errors.New("unsupported codex notification")
+
+//nolint:gochecknoinits // Agent self-registration is the intended pattern
+func init() {
+	agent.Register(agent.AgentNameCodex, NewCodexAgent)
+}
+
+// CodexAgent implements the Agent interface for Codex CLI.
+//
+//nolint:revive // CodexAgent is clearer than Agent in this context
+type CodexAgent struct{}
+
+func NewCodexAgent() agent.Agent {
+	return &CodexAgent{}
+}
+
+// Name returns the agent registry key.
+func (c *CodexAgent) Name() agent.AgentName {
+	return agent.AgentNameCodex
+}
+
+// Type returns the agent type identifier.
+func (c *CodexAgent) Type() agent.AgentType {
+	return agent.AgentTypeCodex
+}
+
+// Description returns a human-readable description.
+func (c *CodexAgent) Description() string {
+	return "Codex - OpenAI's coding agent CLI"
+}
+
+// DetectPresence checks if Codex is configured in the repository.
+func (c *CodexAgent) DetectPresence() (bool, error) {
+	repoRoot, err := paths.RepoRoot()
+	if err != nil {
+		// Not in a git repo, fall back to CWD-relative check
+		repoRoot = "."
+	}
+
+	if info, err := os.Stat(filepath.Join(repoRoot, defaultCodexDir)); err == nil && info.IsDir() {
+		return true, nil
+	}
+	return false, nil
+}
+
+// GetHookConfigPath returns the path to Codex's config file.
+func (c *CodexAgent) GetHookConfigPath() string {
+	path, err := configPath()
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// SupportsHooks returns true as Codex runs a notify program after each turn.
+func (c *CodexAgent) SupportsHooks() bool {
+	return true
+}
+
+// ParseHookInput parses the notify payload. Codex passes it as the program's
+// last argument; the hook command hands it over as the reader.
+// Returns ErrUnsupportedNotification for notifications other than turn ends.
+func (c *CodexAgent) ParseHookInput(hookType agent.HookType, reader io.Reader) (*agent.HookInput, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, errors.New("empty input")
+	}
+
+	var raw notifyPayload
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse notification: %w", err)
+	}
+	if raw.Type != notifyTurnComplete {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedNotification, raw.Type)
+	}
+
+	input := &agent.HookInput{
+		HookType:  hookType,
+		SessionID: raw.ThreadID,
+		Timestamp: time.Now(),
+		RawData: map[string]interface{}{
+			"turn_id":                raw.TurnID,
+			"cwd":                    raw.Cwd,
+			"last_assistant_message": raw.LastAssistantMessage,
+		},
+	}
+	if len(raw.InputMessages) > 0 {
+		input.UserPrompt = raw.InputMessages[len(raw.InputMessages)-1]
+	}
+
+	// Older Codex versions don't send the session ID; use the latest session in cwd
+	sessionsDir, err := SessionsDir()
+	if err != nil {
+		return nil, err
+	}
+	if raw.ThreadID != "" {
+		input.SessionRef, err = FindRollout(sessionsDir, raw.ThreadID)
+	} else {
+		cwd := raw.Cwd
+		if cwd == "" {
+			cwd, _ = paths.RepoRoot() //nolint:errcheck // Empty cwd finds nothing
+		}
+		input.SessionRef, err = FindLatestRollout(sessionsDir, cwd)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if input.SessionRef == "" {
+		return nil, fmt.Errorf("no codex rollout found for session %q", raw.ThreadID)
+	}
+	if input.SessionID == "" {
+		meta, err := ReadSessionMeta(input.SessionRef)
+		if err != nil {
+			return nil, err
+		}
+		input.SessionID = meta.ID
+	}
+	return input, nil
+}
+
+// GetSessionID extracts the session ID from hook input.
+func (c *CodexAgent) GetSessionID(input *agent.HookInput) string {
+	return input.SessionID
+}
+
+// TransformSessionID converts a Codex session ID to an Entire session ID.
+// This is an identity function - the agent session ID IS the Entire session ID.
+func (c *CodexAgent) TransformSessionID(agentSessionID string) string {
+	return agentSessionID
+}
+
+// ExtractAgentSessionID extracts the Codex session ID from an Entire session ID.
+// For backwards compatibility with legacy date-prefixed IDs, it strips the prefix if present.
+func (c *CodexAgent) ExtractAgentSessionID(entireSessionID string) string {
+	return sessionid.ModelSessionID(entireSessionID)
+}
+
+// GetSessionDir returns the directory where Codex stores rollouts.
+// Codex files rollouts by date rather than by project.
+func (c *CodexAgent) GetSessionDir(_ string) (string, error) {
+	return SessionsDir()
+}
+
+// ReadSession reads a session from its rollout file.
+// SessionRef is the rollout path; if it's empty, the rollout is looked up by session ID.
+func (c *CodexAgent) ReadSession(input *agent.HookInput) (*agent.AgentSession, error) {
+	rolloutFile := input.SessionRef
+	if rolloutFile == "" {
+		sessionsDir, err := SessionsDir()
+		if err != nil {
+			return nil, err
+		}
+		rolloutFile, err = FindRollout(sessionsDir, c.ExtractAgentSessionID(input.SessionID))
+		if err != nil {
+			return nil, err
+		}
+		if rolloutFile == "" {
+			return nil, fmt.Errorf("no codex rollout found for session %q", input.SessionID)
+		}
+	}
+
+	data, err := os.ReadFile(rolloutFile) //nolint:gosec // Path comes from Codex's sessions directory
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rollout: %w", err)
+	}
+	rollout := ParseRollout(data)
+
+	startTime := time.Now()
+	if ts, err := time.Parse(time.RFC3339Nano, rollout.Meta.Timestamp); err == nil {
+		startTime = ts
+	}
+
+	return &agent.AgentSession{
+		SessionID:     input.SessionID,
+		AgentName:     c.Name(),
+		SessionRef:    rolloutFile,
+		StartTime:     startTime,
+		NativeData:    data,
+		ModifiedFiles: ExtractModifiedFiles(rollout, 0),
+		Entries:       ToSessionEntries(rollout),
+	}, nil
+}
+
+// WriteSession writes a session's rollout so `codex resume` can find it.
+// A SessionRef that isn't a rollout file name (e.g. <sessions-dir>/<id>.jsonl) is
+// replaced by the session's existing rollout, or a new one filed under its start date.
+func (c *CodexAgent) WriteSession(session *agent.AgentSession) error {
+	if session == nil {
+		return errors.New("session is nil")
+	}
+
+	// Verify this session belongs to Codex
+	if session.AgentName != "" && session.AgentName != c.Name() {
+		return fmt.Errorf("session belongs to agent %q, not %q", session.AgentName, c.Name())
+	}
+
+	if session.SessionRef == "" {
+		return errors.New("session reference (rollout path) is required")
+	}
+
+	if len(session.NativeData) == 0 {
+		return errors.New("session has no native data to write")
+	}
+
+	target := session.SessionRef
+	if !isRolloutFile(filepath.Base(target)) {
+		sessionsDir, err := SessionsDir()
+		if err != nil {
+			return err
+		}
+		meta := ParseRollout(session.NativeData).Meta
+		sessionID := meta.ID
+		if sessionID == "" {
+			sessionID = c.ExtractAgentSessionID(session.SessionID)
+		}
+		target, err = FindRollout(sessionsDir, sessionID)
+		if err != nil {
+			return err
+		}
+		if target == "" {
+			startedAt, err := time.Parse(time.RFC3339Nano, meta.Timestamp)
+			if err != nil {
+				startedAt = time.Now()
+			}
+			target = rolloutPath(sessionsDir, sessionID, startedAt)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+		return fmt.Errorf("failed to create sessions directory: %w", err)
+	}
+	if err := os.WriteFile(target, session.NativeData, 0o600); err != nil {
+		return fmt.Errorf("failed to write rollout: %w", err)
+	}
+	return nil
+}
+
+// FormatResumeCommand returns the command to resume a Codex session.
+func (c *CodexAgent) FormatResumeCommand(sessionID string) string {
+	return "codex resume " + sessionID
+}
+
+// FileWatcher interface implementation
+
+// GetWatchPaths returns the rollout directories of today and yesterday, where
+// sessions started recently are filed.
+func (c *CodexAgent) GetWatchPaths() ([]string, error) {
+	sessionsDir, err := SessionsDir()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return []string{
+		filepath.Join(sessionsDir, filepath.FromSlash(now.Format(sessionDirLayout))),
+		filepath.Join(sessionsDir, filepath.FromSlash(now.AddDate(0, 0, -1).Format(sessionDirLayout))),
+	}, nil
+}
+
+// OnFileChange maps a rollout change to its session, if the session runs in
+// the current repository. The last conversation item tells where the turn is:
+// a final assistant message (or an aborted turn) ends it, anything else means
+// it's in progress. Returns nil for other files and other repositories' sessions.
+func (c *CodexAgent) OnFileChange(path string) (*agent.SessionChange, error) {
+	if !isRolloutFile(filepath.Base(path)) {
+		return nil, nil
+	}
+	repoRoot, err := paths.RepoRoot()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository root: %w", err)
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // Path comes from Codex's sessions directory
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read rollout: %w", err)
+	}
+	rollout := ParseRollout(data)
+	if rollout.Meta.ID == "" || !isWithinDir(rollout.Meta.Cwd, repoRoot) {
+		return nil, nil
+	}
+
+	eventType := agent.HookSessionStart
+	for i := len(rollout.Lines) - 1; i >= 0 && eventType == agent.HookSessionStart; i-- {
+		line := rollout.Lines[i]
+		if event := line.eventMsg(); event != nil && event.Type == EventTypeTurnAborted {
+			eventType = agent.HookStop
+			continue
+		}
+		item := line.responseItem()
+		switch {
+		case item == nil:
+		case item.Type == ItemTypeMessage && item.Role == "assistant":
+			eventType = agent.HookStop
+		case item.isUserPrompt():
+			eventType = agent.HookUserPromptSubmit
+		case item.Type != ItemTypeMessage:
+			// Tool calls and reasoning: the turn is still running
+			eventType = agent.HookUserPromptSubmit
+		}
+	}
+
+	timestamp := time.Now()
+	if info, err := os.Stat(path); err == nil {
+		timestamp = info.ModTime()
+	}
+
+	return &agent.SessionChange{
+		SessionID:  rollout.Meta.ID,
+		SessionRef: path,
+		EventType:  eventType,
+		Timestamp:  timestamp,
+	}, nil
+}
+
+// isWithinDir reports whether path is dir or inside it, resolving symlinks.
+func isWithinDir(path, dir string) bool {
+	if path == "" {
+		return false
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// TranscriptAnalyzer interface implementation
+
+// GetTranscriptPosition returns the number of complete lines in a rollout.
+// Returns 0 if the file doesn't exist or is empty.
+func (c *CodexAgent) GetTranscriptPosition(path string) (int, error) {
+	if path == "" {
+		return 0, nil
+	}
+
+	file, err := os.Open(path) //nolint:gosec // Path comes from Codex's sessions directory
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to open rollout: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	lineCount := 0
+	for {
+		_, err := reader.ReadBytes('\n')
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return 0, fmt.Errorf("failed to read rollout: %w", err)
+		}
+		lineCount++
+	}
+	return lineCount, nil
+}
+
+// ExtractModifiedFilesFromOffset extracts files modified by apply_patch since a given line.
+// Returns:
+//   - files: list of file paths patched by apply_patch (directly or via shell)
+//   - currentPosition: total number of complete lines in the rollout
+//   - error: any error encountered during reading
+func (c *CodexAgent) ExtractModifiedFilesFromOffset(path string, startOffset int) (files []string, currentPosition int, err error) {
+	if path == "" {
+		return nil, 0, nil
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // Path comes from Codex's sessions directory
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, 0, nil
+		}
+		return nil, 0, fmt.Errorf("failed to read rollout: %w", err)
+	}
+	rollout := ParseRollout(data)
+	return ExtractModifiedFiles(rollout, startOffset), len(rollout.Lines), nil
+}
+
+// TranscriptChunker interface implementation
+
+// ChunkTranscript splits a rollout at line boundaries.
+func (c *CodexAgent) ChunkTranscript(content []byte, maxSize int) ([][]byte, error) {
+	chunks, err := agent.ChunkJSONL(content, maxSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to chunk rollout: %w", err)
+	}
+	return chunks, nil
+}
+
+// ReassembleTranscript concatenates rollout chunks with newlines.
+//
+//nolint:unparam // error return is required by interface, kept for consistency
+func (c *CodexAgent) ReassembleTranscript(chunks [][]byte) ([]byte, error) {
+	return agent.ReassembleJSONL(chunks), nil
+}
+
+// TranscriptParser interface implementation
+
+// ParseEntries normalizes rollout data into session entries, one per line.
+//
+//nolint:unparam // error return is required by interface, kept for consistency
+func (c *CodexAgent) ParseEntries(data []byte) ([]agent.SessionEntry, error) {
+	return ToSessionEntries(ParseRollout(data)), nil
+}
@@ -0,0 +1,283 @@
+package codex
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+// initRepo creates a git repository, makes it the working directory, and returns its path.
+func initRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	cmd := exec.Command("git", "init", "-q", dir)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v\n%s", err, output)
+	}
+	t.Chdir(dir)
+	paths.ClearRepoRootCache()
+	return dir
+}
+
+// setCodexHome points CODEX_HOME at a temporary directory and returns its sessions directory.
+func setCodexHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv(codexHomeEnv, home)
+	return filepath.Join(home, sessionsDirName)
+}
+
+// writeRollout writes a rollout for testSessionID started in cwd and returns its path.
+func writeRollout(t *testing.T, sessionsDir, cwd string) string {
+	t.Helper()
+	startedAt := time.Date(2025, 10, 16, 9, 0, 0, 0, time.UTC)
+	path := rolloutPath(sessionsDir, testSessionID, startedAt)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("failed to create sessions dir: %v", err)
+	}
+	content := strings.Replace(testRollout, `"cwd":"/work/repo"`, `"cwd":"`+cwd+`"`, 1)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write rollout: %v", err)
+	}
+	return path
+}
+
+func TestRegistered(t *testing.T) {
+	ag, err := agent.Get(agent.AgentNameCodex)
+	if err != nil {
+		t.Fatalf("Get(%q) error = %v", agent.AgentNameCodex, err)
+	}
+	if ag.Type() != agent.AgentTypeCodex {
+		t.Errorf("Type() = %q, want %q", ag.Type(), agent.AgentTypeCodex)
+	}
+	if _, ok := ag.(agent.HookSupport); !ok {
+		t.Error("CodexAgent does not implement HookSupport")
+	}
+	if _, ok := ag.(agent.FileWatcher); !ok {
+		t.Error("CodexAgent does not implement FileWatcher")
+	}
+	if _, ok := ag.(agent.TranscriptAnalyzer); !ok {
+		t.Error("CodexAgent does not implement TranscriptAnalyzer")
+	}
+	if _, ok := ag.(agent.TranscriptParser); !ok {
+		t.Error("CodexAgent does not implement TranscriptParser")
+	}
+}
+
+func TestFormatResumeCommand(t *testing.T) {
+	t.Parallel()
+
+	ag := &CodexAgent{}
+	if got := ag.FormatResumeCommand(testSessionID); got != "codex resume "+testSessionID {
+		t.Errorf("FormatResumeCommand() = %q", got)
+	}
+}
+
+func TestReadSession(t *testing.T) {
+	sessionsDir := setCodexHome(t)
+	path := writeRollout(t, sessionsDir, "/work/repo")
+
+	ag := &CodexAgent{}
+	session, err := ag.ReadSession(&agent.HookInput{SessionID: testSessionID})
+	if err != nil {
+		t.Fatalf("ReadSession() error = %v", err)
+	}
+	if session.SessionRef != path {
+		t.Errorf("SessionRef = %q, want %q", session.SessionRef, path)
+	}
+	if len(session.Entries) != 19 {
+		t.Errorf("got %d entries, want 19", len(session.Entries))
+	}
+	if len(session.ModifiedFiles) != 2 {
+		t.Errorf("ModifiedFiles = %v, want 2 files", session.ModifiedFiles)
+	}
+	if !session.StartTime.Equal(time.Date(2025, 10, 16, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("StartTime = %v", session.StartTime)
+	}
+}
+
+func TestReadSession_Missing(t *testing.T) {
+	setCodexHome(t)
+
+	ag := &CodexAgent{}
+	if _, err := ag.ReadSession(&agent.HookInput{SessionID: testSessionID}); err == nil {
+		t.Error("ReadSession() expected error for unknown session")
+	}
+}
+
+func TestWriteSession_CreatesRollout(t *testing.T) {
+	sessionsDir := setCodexHome(t)
+
+	// Restored sessions point at a plain <id>.jsonl path
+	ag := &CodexAgent{}
+	err := ag.WriteSession(&agent.AgentSession{
+		SessionID:  testSessionID,
+		AgentName:  agent.AgentNameCodex,
+		SessionRef: filepath.Join(sessionsDir, testSessionID+".jsonl"),
+		NativeData: []byte(testRollout),
+	})
+	if err != nil {
+		t.Fatalf("WriteSession() error = %v", err)
+	}
+
+	path, err := FindRollout(sessionsDir, testSessionID)
+	if err != nil {
+		t.Fatalf("FindRollout() error = %v", err)
+	}
+	if path == "" {
+		t.Fatal("WriteSession() did not create a rollout codex can resume")
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != testRollout {
+		t.Errorf("rollout content mismatch (err = %v)", err)
+	}
+}
+
+func TestWriteSession_WrongAgent(t *testing.T) {
+	t.Parallel()
+
+	ag := &CodexAgent{}
+	err := ag.WriteSession(&agent.AgentSession{
+		AgentName:  agent.AgentNameClaudeCode,
+		SessionRef: "/tmp/x.jsonl",
+		NativeData: []byte(testRollout),
+	})
+	if err == nil {
+		t.Error("WriteSession() expected error for another agent's session")
+	}
+}
+
+func TestParseHookInput(t *testing.T) {
+	sessionsDir := setCodexHome(t)
+	path := writeRollout(t, sessionsDir, "/work/repo")
+
+	ag := &CodexAgent{}
+	payload := `{"type":"agent-turn-complete","thread-id":"` + testSessionID + `","turn-id":"1","cwd":"/work/repo","input-messages":["List the files"],"last-assistant-message":"CHANGELOG.md and src."}`
+	input, err := ag.ParseHookInput(agent.HookStop, strings.NewReader(payload))
+	if err != nil {
+		t.Fatalf("ParseHookInput() error = %v", err)
+	}
+	if input.SessionID != testSessionID {
+		t.Errorf("SessionID = %q, want %q", input.SessionID, testSessionID)
+	}
+	if input.SessionRef != path {
+		t.Errorf("SessionRef = %q, want %q", input.SessionRef, path)
+	}
+	if input.UserPrompt != "List the files" {
+		t.Errorf("UserPrompt = %q", input.UserPrompt)
+	}
+}
+
+func TestParseHookInput_NoThreadID(t *testing.T) {
+	sessionsDir := setCodexHome(t)
+	path := writeRollout(t, sessionsDir, "/work/repo")
+
+	ag := &CodexAgent{}
+	payload := `{"type":"agent-turn-complete","turn-id":"1","cwd":"/work/repo","input-messages":["List the files"]}`
+	input, err := ag.ParseHookInput(agent.HookStop, strings.NewReader(payload))
+	if err != nil {
+		t.Fatalf("ParseHookInput() error = %v", err)
+	}
+	if input.SessionID != testSessionID || input.SessionRef != path {
+		t.Errorf("got session %q at %q, want %q at %q", input.SessionID, input.SessionRef, testSessionID, path)
+	}
+}
+
+func TestParseHookInput_Unsupported(t *testing.T) {
+	setCodexHome(t)
+
+	ag := &CodexAgent{}
+	_, err := ag.ParseHookInput(agent.HookStop, strings.NewReader(`{"type":"approval-requested"}`))
+	if !errors.Is(err, ErrUnsupportedNotification) {
+		t.Errorf("ParseHookInput() error = %v, want ErrUnsupportedNotification", err)
+	}
+}
+
+func TestOnFileChange(t *testing.T) {
+	dir := initRepo(t)
+	sessionsDir := setCodexHome(t)
+	path := writeRollout(t, sessionsDir, dir)
+
+	ag := &CodexAgent{}
+	change, err := ag.OnFileChange(path)
+	if err != nil {
+		t.Fatalf("OnFileChange() error = %v", err)
+	}
+	if change == nil {
+		t.Fatal("OnFileChange() = nil, want change")
+	}
+	if change.SessionID != testSessionID || change.EventType != agent.HookStop {
+		t.Errorf("change = %+v, want stop of %s", change, testSessionID)
+	}
+
+	// A new prompt starts a turn
+	prompt := `{"timestamp":"2025-10-16T09:02:00.000Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"Run the tests"}]}}` + "\n"
+	appendLine(t, path, prompt)
+	if change, err = ag.OnFileChange(path); err != nil || change == nil || change.EventType != agent.HookUserPromptSubmit {
+		t.Errorf("OnFileChange() after prompt = %+v, %v; want prompt submit", change, err)
+	}
+
+	// An aborted turn ends it
+	appendLine(t, path, `{"timestamp":"2025-10-16T09:02:01.000Z","type":"event_msg","payload":{"type":"turn_aborted","reason":"interrupted"}}`+"\n")
+	if change, err = ag.OnFileChange(path); err != nil || change == nil || change.EventType != agent.HookStop {
+		t.Errorf("OnFileChange() after abort = %+v, %v; want stop", change, err)
+	}
+}
+
+func appendLine(t *testing.T, path, line string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("failed to open rollout: %v", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(line); err != nil {
+		t.Fatalf("failed to append to rollout: %v", err)
+	}
+}
+
+func TestOnFileChange_Ignored(t *testing.T) {
+	initRepo(t)
+	sessionsDir := setCodexHome(t)
+
+	ag := &CodexAgent{}
+
+	// A session started in another repository
+	path := writeRollout(t, sessionsDir, t.TempDir())
+	if change, err := ag.OnFileChange(path); err != nil || change != nil {
+		t.Errorf("OnFileChange(other repo) = %+v, %v; want nil", change, err)
+	}
+
+	// Not a rollout
+	if change, err := ag.OnFileChange(filepath.Join(sessionsDir, "history.jsonl")); err != nil || change != nil {
+		t.Errorf("OnFileChange(other file) = %+v, %v; want nil", change, err)
+	}
+}
+
+func TestTranscriptPosition(t *testing.T) {
+	sessionsDir := setCodexHome(t)
+	path := writeRollout(t, sessionsDir, "/work/repo")
+
+	ag := &CodexAgent{}
+	pos, err := ag.GetTranscriptPosition(path)
+	if err != nil {
+		t.Fatalf("GetTranscriptPosition() error = %v", err)
+	}
+	if pos != 19 {
+		t.Errorf("GetTranscriptPosition() = %d, want 19", pos)
+	}
+
+	files, current, err := ag.ExtractModifiedFilesFromOffset(path, secondTurnLine)
+	if err != nil {
+		t.Fatalf("ExtractModifiedFilesFromOffset() error = %v", err)
+	}
+	if len(files) != 0 || current != 19 {
+		t.Errorf("ExtractModifiedFilesFromOffset() = %v, %d; want none, 19", files, current)
+	}
+}
@@ -0,0 +1,228 @@
+package codex
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+// Ensure CodexAgent implements HookSupport, UserConfigHookInstaller and HookHandler
+var (
+	_ agent.HookSupport             = (*CodexAgent)(nil)
+	_ agent.UserConfigHookInstaller = (*CodexAgent)(nil)
+	_ agent.HookHandler             = (*CodexAgent)(nil)
+)
+
+// Codex CLI hook names - these become subcommands under `entire hooks codex`
+const (
+	// HookNameNotify is run by Codex's notify program setting after each turn
+	HookNameNotify = "notify"
+)
+
+// notifyTurnComplete is the notify payload type sent when a turn ends
+const notifyTurnComplete = "agent-turn-complete"
+
+// entireNotifySuffix identifies Entire's notify command, in both production
+// and localDev form
+var entireNotifySuffix = []string{"hooks", "codex", HookNameNotify}
+
+var (
+	// notifyKeyRegex matches the start of the top-level notify setting
+	notifyKeyRegex = regexp.MustCompile(`^\s*notify\s*=`)
+	// tomlStringRegex matches a TOML basic string
+	tomlStringRegex = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"`)
+)
+
+// GetHookNames returns the hook verbs Codex supports.
+// These become subcommands: entire hooks codex <verb>
+func (c *CodexAgent) GetHookNames() []string {
+	return []string{HookNameNotify}
+}
+
+// configPath returns the path of Codex's config.toml.
+func configPath() (string, error) {
+	home, err := Home()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, configFileName), nil
+}
+
+// HookConfigPath returns the path of Codex's config.toml, which holds the hook
+// for every repository.
+func (c *CodexAgent) HookConfigPath() (string, error) {
+	return configPath()
+}
+
+// notifyCommand returns the notify program Entire installs.
+func notifyCommand(localDev bool) ([]string, error) {
+	if !localDev {
+		return append([]string{"entire"}, entireNotifySuffix...), nil
+	}
+	// notify runs without a shell, so the project path must be spelled out
+	repoRoot, err := paths.RepoRoot()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository root: %w", err)
+	}
+	return append([]string{"go", "run", filepath.Join(repoRoot, "cmd", "entire", "main.go")}, entireNotifySuffix...), nil
+}
+
+// isEntireNotify checks if a notify program is Entire's hook
+func isEntireNotify(command []string) bool {
+	n := len(entireNotifySuffix)
+	return len(command) > n && slices.Equal(command[len(command)-n:], entireNotifySuffix)
+}
+
+// notifySetting locates the top-level notify setting in config.toml lines.
+// Returns the first and last line of the setting and its command, or start -1
+// if it isn't set. Only keys before the first table header are top-level.
+func notifySetting(lines []string) (start, end int, command []string) {
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			break
+		}
+		if !notifyKeyRegex.MatchString(line) {
+			continue
+		}
+		// The array may span several lines
+		value := line[strings.Index(line, "=")+1:]
+		end = i
+		for strings.Count(value, "[") > strings.Count(value, "]") && end+1 < len(lines) {
+			end++
+			value += "\n" + lines[end]
+		}
+		for _, m := range tomlStringRegex.FindAllStringSubmatch(value, -1) {
+			arg, err := strconv.Unquote(`"` + m[1] + `"`)
+			if err != nil {
+				arg = m[1]
+			}
+			command = append(command, arg)
+		}
+		return i, end, command
+	}
+	return -1, -1, nil
+}
+
+// readConfigLines reads config.toml as lines. A missing file has no lines.
+func readConfigLines(path string) ([]string, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // Path is Codex's config file
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", configFileName, err)
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n"), nil
+}
+
+// writeConfigLines writes config.toml from lines.
+func writeConfigLines(path string, lines []string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create Codex directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", configFileName, err)
+	}
+	return nil
+}
+
+// formatNotify formats the notify setting for config.toml.
+func formatNotify(command []string) string {
+	quoted := make([]string, 0, len(command))
+	for _, arg := range command {
+		quoted = append(quoted, strconv.Quote(arg))
+	}
+	return "notify = [" + strings.Join(quoted, ", ") + "]"
+}
+
+// InstallHooks sets Entire as Codex's notify program in $CODEX_HOME/config.toml.
+// Codex reads notify only from the user configuration, so this applies to all
+// repositories (see agent.UserConfigHookInstaller); the hook does nothing in
+// repositories where Entire is not enabled.
+// A notify program that isn't Entire's is left alone and reported as an error.
+// Returns the number of hooks installed.
+func (c *CodexAgent) InstallHooks(localDev bool, force bool) (int, error) {
+	path, err := configPath()
+	if err != nil {
+		return 0, err
+	}
+	lines, err := readConfigLines(path)
+	if err != nil {
+		return 0, err
+	}
+	command, err := notifyCommand(localDev)
+	if err != nil {
+		return 0, err
+	}
+
+	start, end, existing := notifySetting(lines)
+	if start >= 0 {
+		if !isEntireNotify(existing) {
+			return 0, fmt.Errorf("codex already runs a notify program (%s) in %s; remove it to let Entire install its hook",
+				strings.Join(existing, " "), path)
+		}
+		// Idempotent unless forced: the same command is already installed
+		if !force && slices.Equal(existing, command) {
+			return 0, nil
+		}
+		lines = slices.Delete(lines, start, end+1)
+	} else {
+		start = 0
+	}
+
+	// Top-level keys must come before any table
+	lines = slices.Insert(lines, start, formatNotify(command))
+	if err := writeConfigLines(path, lines); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+// UninstallHooks removes Entire's notify program from Codex's config.
+func (c *CodexAgent) UninstallHooks() error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	lines, err := readConfigLines(path)
+	if err != nil {
+		return err
+	}
+	start, end, existing := notifySetting(lines)
+	if start < 0 || !isEntireNotify(existing) {
+		return nil
+	}
+	return writeConfigLines(path, slices.Delete(lines, start, end+1))
+}
+
+// AreHooksInstalled checks if Entire is Codex's notify program.
+func (c *CodexAgent) AreHooksInstalled() bool {
+	path, err := configPath()
+	if err != nil {
+		return false
+	}
+	lines, err := readConfigLines(path)
+	if err != nil {
+		return false
+	}
+	start, _, existing := notifySetting(lines)
+	return start >= 0 && isEntireNotify(existing)
+}
+
+// GetSupportedHooks returns the hook types Codex supports.
+func (c *CodexAgent) GetSupportedHooks() []agent.HookType {
+	return []agent.HookType{
+		agent.HookStop, // Maps to Codex's notify (agent-turn-complete)
+	}
+}
@@ -0,0 +1,142 @@
+package codex
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readConfig(t *testing.T) string {
+	t.Helper()
+	path, err := configPath()
+	if err != nil {
+		t.Fatalf("configPath() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	return string(data)
+}
+
+func writeConfig(t *testing.T, content string) {
+	t.Helper()
+	path, err := configPath()
+	if err != nil {
+		t.Fatalf("configPath() error = %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("failed to create config dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+}
+
+func TestInstallHooks(t *testing.T) {
+	setCodexHome(t)
+	ag := &CodexAgent{}
+
+	if ag.AreHooksInstalled() {
+		t.Fatal("AreHooksInstalled() = true before install")
+	}
+	count, err := ag.InstallHooks(false, false)
+	if err != nil {
+		t.Fatalf("InstallHooks() error = %v", err)
+	}
+	if count != 1 {
+		t.Errorf("InstallHooks() count = %d, want 1", count)
+	}
+	if got := readConfig(t); got != `notify = ["entire", "hooks", "codex", "notify"]`+"\n" {
+		t.Errorf("config = %q", got)
+	}
+	if !ag.AreHooksInstalled() {
+		t.Error("AreHooksInstalled() = false after install")
+	}
+
+	// Idempotent
+	count, err = ag.InstallHooks(false, false)
+	if err != nil {
+		t.Fatalf("second InstallHooks() error = %v", err)
+	}
+	if count != 0 {
+		t.Errorf("second InstallHooks() count = %d, want 0", count)
+	}
+}
+
+func TestInstallHooks_PreservesConfig(t *testing.T) {
+	setCodexHome(t)
+	writeConfig(t, "model = \"gpt-5-codex\"\n\n[mcp_servers.docs]\ncommand = \"docs\"\nnotify = \"not top-level\"\n")
+	ag := &CodexAgent{}
+
+	if _, err := ag.InstallHooks(false, false); err != nil {
+		t.Fatalf("InstallHooks() error = %v", err)
+	}
+	config := readConfig(t)
+	if !strings.HasPrefix(config, `notify = ["entire", "hooks", "codex", "notify"]`+"\n") {
+		t.Errorf("notify must be a top-level key before tables, got:\n%s", config)
+	}
+	if !strings.Contains(config, "[mcp_servers.docs]\ncommand = \"docs\"\nnotify = \"not top-level\"\n") {
+		t.Errorf("existing tables not preserved, got:\n%s", config)
+	}
+
+	if err := ag.UninstallHooks(); err != nil {
+		t.Fatalf("UninstallHooks() error = %v", err)
+	}
+	if got := readConfig(t); got != "model = \"gpt-5-codex\"\n\n[mcp_servers.docs]\ncommand = \"docs\"\nnotify = \"not top-level\"\n" {
+		t.Errorf("config after uninstall = %q", got)
+	}
+	if ag.AreHooksInstalled() {
+		t.Error("AreHooksInstalled() = true after uninstall")
+	}
+}
+
+func TestInstallHooks_LocalDev(t *testing.T) {
+	dir := initRepo(t)
+	setCodexHome(t)
+	ag := &CodexAgent{}
+
+	if _, err := ag.InstallHooks(false, false); err != nil {
+		t.Fatalf("InstallHooks() error = %v", err)
+	}
+	// Switching to localDev replaces the installed command
+	count, err := ag.InstallHooks(true, false)
+	if err != nil {
+		t.Fatalf("InstallHooks(localDev) error = %v", err)
+	}
+	if count != 1 {
+		t.Errorf("InstallHooks(localDev) count = %d, want 1", count)
+	}
+	_, _, command := notifySetting(strings.Split(readConfig(t), "\n"))
+	mainGo := filepath.Join(dir, "cmd", "entire", "main.go")
+	if len(command) != 6 || command[0] != "go" || !strings.HasSuffix(command[2], filepath.Join("cmd", "entire", "main.go")) {
+		t.Errorf("notify = %v, want go run %s hooks codex notify", command, mainGo)
+	}
+	if !ag.AreHooksInstalled() {
+		t.Error("AreHooksInstalled() = false for localDev command")
+	}
+}
+
+func TestInstallHooks_ForeignNotify(t *testing.T) {
+	setCodexHome(t)
+	original := "notify = [\n  \"python3\",\n  \"/home/me/notify.py\",\n]\n"
+	writeConfig(t, original)
+	ag := &CodexAgent{}
+
+	if _, err := ag.InstallHooks(false, true); err == nil {
+		t.Error("InstallHooks() expected error when another notify program is set")
+	}
+	if got := readConfig(t); got != original {
+		t.Errorf("config modified: %q", got)
+	}
+	if ag.AreHooksInstalled() {
+		t.Error("AreHooksInstalled() = true for another notify program")
+	}
+	if err := ag.UninstallHooks(); err != nil {
+		t.Fatalf("UninstallHooks() error = %v", err)
+	}
+	if got := readConfig(t); got != original {
+		t.Errorf("UninstallHooks() removed another notify program: %q", got)
+	}
+}
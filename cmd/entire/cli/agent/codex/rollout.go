@@ -0,0 +1,327 @@
+package codex
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+// patchBeginMarker starts the patch text passed to apply_patch
+const patchBeginMarker = "*** Begin Patch"
+
+// patchFilePrefixes introduce the file paths in apply_patch's patch format
+var patchFilePrefixes = []string{
+	"*** Add File: ",
+	"*** Update File: ",
+	"*** Delete File: ",
+	"*** Move to: ",
+}
+
+// contextMessagePrefixes identify user-role messages Codex injects itself
+// (environment and project instructions) rather than prompts typed by the user
+var contextMessagePrefixes = []string{
+	"<environment_context>",
+	"<user_instructions>",
+	"# AGENTS.md instructions",
+}
+
+// Rollout is a parsed rollout file.
+// Lines holds every complete line in order, so a line's index is its transcript
+// position; lines that fail to parse have an empty Type.
+type Rollout struct {
+	Meta  SessionMeta
+	Lines []RolloutLine
+}
+
+// ParseRollout parses rollout JSONL. A trailing line without a newline is
+// ignored, as Codex may still be writing it.
+func ParseRollout(data []byte) *Rollout {
+	rollout := &Rollout{}
+	for {
+		end := bytes.IndexByte(data, '\n')
+		if end < 0 {
+			break
+		}
+		var line RolloutLine
+		if err := json.Unmarshal(bytes.TrimSpace(data[:end]), &line); err != nil {
+			line = RolloutLine{}
+		}
+		if line.Type == LineTypeSessionMeta && rollout.Meta.ID == "" {
+			_ = json.Unmarshal(line.Payload, &rollout.Meta) //nolint:errcheck // Best-effort: an unreadable header leaves Meta empty
+		}
+		rollout.Lines = append(rollout.Lines, line)
+		data = data[end+1:]
+	}
+	return rollout
+}
+
+// responseItem decodes the response item of a line.
+// Returns nil for other line types and malformed items.
+func (l RolloutLine) responseItem() *ResponseItem {
+	if l.Type != LineTypeResponseItem {
+		return nil
+	}
+	var item ResponseItem
+	if err := json.Unmarshal(l.Payload, &item); err != nil {
+		return nil
+	}
+	return &item
+}
+
+// eventMsg decodes the event of a line.
+// Returns nil for other line types and malformed events.
+func (l RolloutLine) eventMsg() *EventMsg {
+	if l.Type != LineTypeEventMsg {
+		return nil
+	}
+	var event EventMsg
+	if err := json.Unmarshal(l.Payload, &event); err != nil {
+		return nil
+	}
+	return &event
+}
+
+// text joins the text of the item's content parts.
+func (i *ResponseItem) text() string {
+	parts := make([]string, 0, len(i.Content))
+	for _, c := range i.Content {
+		if c.Text != "" {
+			parts = append(parts, c.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// isUserPrompt reports whether the item is a prompt typed by the user.
+func (i *ResponseItem) isUserPrompt() bool {
+	if i.Type != ItemTypeMessage || i.Role != "user" {
+		return false
+	}
+	text := strings.TrimSpace(i.text())
+	if text == "" {
+		return false
+	}
+	for _, prefix := range contextMessagePrefixes {
+		if strings.HasPrefix(text, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParsePatchFiles returns the files an apply_patch patch adds, updates, deletes, or
+// moves to. Relative paths are resolved against workdir when it's set.
+func ParsePatchFiles(patch, workdir string) []string {
+	var files []string
+	for _, line := range strings.Split(patch, "\n") {
+		line = strings.TrimSpace(line)
+		for _, prefix := range patchFilePrefixes {
+			if !strings.HasPrefix(line, prefix) {
+				continue
+			}
+			file := strings.TrimSpace(strings.TrimPrefix(line, prefix))
+			if file == "" {
+				break
+			}
+			if workdir != "" && !filepath.IsAbs(file) {
+				file = filepath.Join(workdir, file)
+			}
+			files = append(files, file)
+			break
+		}
+	}
+	return files
+}
+
+// shellPatchFiles returns the files patched by a shell command that runs apply_patch,
+// either directly (["apply_patch", patch]) or from a script (heredoc).
+// Other shell commands return nil: their effects can't be read from the command.
+func shellPatchFiles(command []string, workdir string) []string {
+	script := strings.Join(command, "\n")
+	if !strings.Contains(script, ToolApplyPatch) {
+		return nil
+	}
+	idx := strings.Index(script, patchBeginMarker)
+	if idx < 0 {
+		return nil
+	}
+	return ParsePatchFiles(script[idx:], workdir)
+}
+
+// parseShellCommand decodes a shell tool's command, given as argv or a script.
+func parseShellCommand(raw json.RawMessage) []string {
+	var argv []string
+	if err := json.Unmarshal(raw, &argv); err == nil {
+		return argv
+	}
+	var script string
+	if err := json.Unmarshal(raw, &script); err == nil {
+		return []string{script}
+	}
+	return nil
+}
+
+// callFiles returns the files modified by a tool call item.
+func callFiles(item *ResponseItem) []string {
+	switch item.Type {
+	case ItemTypeCustomToolCall:
+		if item.Name == ToolApplyPatch {
+			return ParsePatchFiles(item.Input, "")
+		}
+	case ItemTypeFunctionCall:
+		switch item.Name {
+		case ToolApplyPatch:
+			var args applyPatchArgs
+			if err := json.Unmarshal([]byte(item.Arguments), &args); err == nil {
+				return ParsePatchFiles(args.Input, "")
+			}
+		case ToolShell, ToolShellCommand, ToolContainerExec:
+			var args shellArgs
+			if err := json.Unmarshal([]byte(item.Arguments), &args); err == nil {
+				return shellPatchFiles(parseShellCommand(args.Command), args.Workdir)
+			}
+		}
+	case ItemTypeLocalShellCall:
+		if item.Action != nil {
+			return shellPatchFiles(item.Action.Command, item.Action.WorkingDirectory)
+		}
+	}
+	return nil
+}
+
+// ExtractModifiedFiles returns files modified by tool calls at or after line
+// startLine, in first-seen order.
+func ExtractModifiedFiles(rollout *Rollout, startLine int) []string {
+	fileSet := make(map[string]bool)
+	var files []string
+	for i := max(startLine, 0); i < len(rollout.Lines); i++ {
+		item := rollout.Lines[i].responseItem()
+		if item == nil {
+			continue
+		}
+		for _, file := range callFiles(item) {
+			if !fileSet[file] {
+				fileSet[file] = true
+				files = append(files, file)
+			}
+		}
+	}
+	return files
+}
+
+// LastPromptLine returns the index of the line holding the most recent user prompt,
+// where the current (or last) turn begins. Returns 0 if there is no prompt.
+func LastPromptLine(rollout *Rollout) int {
+	for i := len(rollout.Lines) - 1; i >= 0; i-- {
+		if item := rollout.Lines[i].responseItem(); item != nil && item.isUserPrompt() {
+			return i
+		}
+	}
+	return 0
+}
+
+// lineTokenUsage returns the usage of the model call reported by a token_count
+// event, or nil. Events repeating the previous running total (*previousTotal)
+// don't represent a new call.
+func lineTokenUsage(event *EventMsg, previousTotal *TokenCounts) *agent.TokenUsage {
+	if event == nil || event.Type != EventTypeTokenCount || event.Info == nil {
+		return nil
+	}
+	if event.Info.TotalTokenUsage == *previousTotal {
+		return nil
+	}
+	*previousTotal = event.Info.TotalTokenUsage
+	last := event.Info.LastTokenUsage
+	return &agent.TokenUsage{
+		InputTokens:     last.InputTokens - last.CachedInputTokens,
+		CacheReadTokens: last.CachedInputTokens,
+		OutputTokens:    last.OutputTokens,
+		APICallCount:    1,
+	}
+}
+
+// outputText returns a tool output, which Codex records as a string or an object.
+func outputText(raw json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+	return string(raw)
+}
+
+// ToSessionEntries normalizes a rollout into agent session entries, one per line,
+// so entry indexes match transcript positions. Lines that aren't part of the
+// conversation (metadata, events, turn context) become system entries; token_count
+// events carry the usage of the model call they report.
+// Entry UUIDs are "<session-id>-<line>".
+func ToSessionEntries(rollout *Rollout) []agent.SessionEntry {
+	entries := make([]agent.SessionEntry, 0, len(rollout.Lines))
+	toolNames := make(map[string]string)
+	var previousTotal TokenCounts
+	var model string
+	for i, line := range rollout.Lines {
+		entry := agent.SessionEntry{
+			UUID:     fmt.Sprintf("%s-%d", rollout.Meta.ID, i),
+			Type:     agent.EntrySystem,
+			Position: i,
+		}
+		if ts, err := time.Parse(time.RFC3339Nano, line.Timestamp); err == nil {
+			entry.Timestamp = ts
+		}
+		if line.Type == LineTypeTurnContext {
+			var turn turnContext
+			if err := json.Unmarshal(line.Payload, &turn); err == nil && turn.Model != "" {
+				model = turn.Model
+			}
+		}
+		entry.TokenUsage = lineTokenUsage(line.eventMsg(), &previousTotal)
+
+		if item := line.responseItem(); item != nil {
+			switch item.Type {
+			case ItemTypeMessage:
+				entry.Content = item.text()
+				switch {
+				case item.isUserPrompt():
+					entry.Type = agent.EntryUser
+				case item.Role == "assistant":
+					entry.Type = agent.EntryAssistant
+					entry.Model = model
+				}
+			case ItemTypeReasoning:
+				texts := make([]string, 0, len(item.Summary))
+				for _, s := range item.Summary {
+					texts = append(texts, s.Text)
+				}
+				entry.Content = strings.Join(texts, "\n")
+			case ItemTypeFunctionCall, ItemTypeCustomToolCall, ItemTypeLocalShellCall:
+				entry.Type = agent.EntryTool
+				entry.Model = model
+				entry.ToolName = item.Name
+				entry.ToolUseID = item.CallID
+				switch {
+				case item.Type == ItemTypeLocalShellCall:
+					entry.ToolName = ToolShell
+					entry.ToolInput = item.Action
+				case item.Input != "":
+					entry.ToolInput = item.Input
+				default:
+					entry.ToolInput = item.Arguments
+				}
+				entry.FilesAffected = callFiles(item)
+				toolNames[item.CallID] = entry.ToolName
+			case ItemTypeFunctionOutput, ItemTypeCustomToolOutput:
+				entry.Type = agent.EntryToolResult
+				entry.ToolName = toolNames[item.CallID]
+				entry.ToolUseID = item.CallID
+				entry.ToolOutput = outputText(item.Output)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
@@ -0,0 +1,173 @@
+package codex
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+const testSessionID = "0199e1a2-7b3c-7d40-9e5f-123456789abc"
+
+// testRollout is a recorded rollout (trimmed) of a session with two turns:
+// the first edits files through apply_patch (as a custom tool and via shell),
+// the second only runs a read-only command.
+var testRollout = strings.Join([]string{
+	`{"timestamp":"2025-10-16T09:00:00.000Z","type":"session_meta","payload":{"id":"` + testSessionID + `","timestamp":"2025-10-16T09:00:00.000Z","cwd":"/work/repo","originator":"codex_cli_rs","cli_version":"0.46.0"}}`,
+	`{"timestamp":"2025-10-16T09:00:01.000Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"<environment_context>\n  <cwd>/work/repo</cwd>\n</environment_context>"}]}}`,
+	`{"timestamp":"2025-10-16T09:00:02.000Z","type":"turn_context","payload":{"cwd":"/work/repo","approval_policy":"on-request","model":"gpt-5-codex"}}`,
+	`{"timestamp":"2025-10-16T09:00:02.100Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"Add a greeting and a changelog"}]}}`,
+	`{"timestamp":"2025-10-16T09:00:02.200Z","type":"event_msg","payload":{"type":"user_message","message":"Add a greeting and a changelog","images":[]}}`,
+	`{"timestamp":"2025-10-16T09:00:05.000Z","type":"response_item","payload":{"type":"reasoning","summary":[{"type":"summary_text","text":"Planning the edit"}],"encrypted_content":"gAAA"}}`,
+	`{"timestamp":"2025-10-16T09:00:06.000Z","type":"response_item","payload":{"type":"custom_tool_call","status":"completed","call_id":"call_1","name":"apply_patch","input":"*** Begin Patch\n*** Update File: src/hello.go\n@@\n-func hello() {}\n+func hello() { println(\"hi\") }\n*** End Patch\n"}}`,
+	`{"timestamp":"2025-10-16T09:00:06.500Z","type":"response_item","payload":{"type":"custom_tool_call_output","call_id":"call_1","output":"{\"output\":\"Success. Updated the following files:\\nM src/hello.go\\n\"}"}}`,
+	`{"timestamp":"2025-10-16T09:00:07.000Z","type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":1200,"cached_input_tokens":200,"output_tokens":150,"reasoning_output_tokens":50,"total_tokens":1350},"last_token_usage":{"input_tokens":1200,"cached_input_tokens":200,"output_tokens":150,"reasoning_output_tokens":50,"total_tokens":1350}}}}`,
+	`{"timestamp":"2025-10-16T09:00:08.000Z","type":"response_item","payload":{"type":"function_call","name":"shell","arguments":"{\"command\":[\"bash\",\"-lc\",\"apply_patch <<'EOF'\\n*** Begin Patch\\n*** Add File: CHANGELOG.md\\n+# Changelog\\n*** End Patch\\nEOF\"],\"workdir\":\"/work/repo/docs\"}","call_id":"call_2"}}`,
+	`{"timestamp":"2025-10-16T09:00:08.500Z","type":"response_item","payload":{"type":"function_call_output","call_id":"call_2","output":"{\"output\":\"Success.\",\"metadata\":{\"exit_code\":0}}"}}`,
+	`{"timestamp":"2025-10-16T09:00:09.000Z","type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":2600,"cached_input_tokens":1000,"output_tokens":230,"reasoning_output_tokens":50,"total_tokens":2830},"last_token_usage":{"input_tokens":1400,"cached_input_tokens":800,"output_tokens":80,"reasoning_output_tokens":0,"total_tokens":1480}}}}`,
+	`{"timestamp":"2025-10-16T09:00:09.100Z","type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":2600,"cached_input_tokens":1000,"output_tokens":230,"reasoning_output_tokens":50,"total_tokens":2830},"last_token_usage":{"input_tokens":1400,"cached_input_tokens":800,"output_tokens":80,"reasoning_output_tokens":0,"total_tokens":1480}}}}`,
+	`{"timestamp":"2025-10-16T09:00:10.000Z","type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"Added a greeting and a changelog."}]}}`,
+	`{"timestamp":"2025-10-16T09:01:00.000Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"List the files"}]}}`,
+	`{"timestamp":"2025-10-16T09:01:01.000Z","type":"response_item","payload":{"type":"function_call","name":"shell","arguments":"{\"command\":[\"ls\"],\"workdir\":\"/work/repo\"}","call_id":"call_3"}}`,
+	`{"timestamp":"2025-10-16T09:01:01.500Z","type":"response_item","payload":{"type":"function_call_output","call_id":"call_3","output":"CHANGELOG.md\nsrc"}}`,
+	`{"timestamp":"2025-10-16T09:01:02.000Z","type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":3600,"cached_input_tokens":1900,"output_tokens":260,"reasoning_output_tokens":50,"total_tokens":3860},"last_token_usage":{"input_tokens":1000,"cached_input_tokens":900,"output_tokens":30,"reasoning_output_tokens":0,"total_tokens":1030}}}}`,
+	`{"timestamp":"2025-10-16T09:01:03.000Z","type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"CHANGELOG.md and src."}]}}`,
+}, "\n") + "\n"
+
+// secondTurnLine is the line of the second turn's prompt in testRollout
+const secondTurnLine = 14
+
+func TestParseRollout(t *testing.T) {
+	t.Parallel()
+
+	rollout := ParseRollout([]byte(testRollout))
+	if rollout.Meta.ID != testSessionID {
+		t.Errorf("Meta.ID = %q, want %q", rollout.Meta.ID, testSessionID)
+	}
+	if rollout.Meta.Cwd != "/work/repo" {
+		t.Errorf("Meta.Cwd = %q, want /work/repo", rollout.Meta.Cwd)
+	}
+	if len(rollout.Lines) != 19 {
+		t.Errorf("got %d lines, want 19", len(rollout.Lines))
+	}
+}
+
+func TestParseRollout_PartialLine(t *testing.T) {
+	t.Parallel()
+
+	data := testRollout + `{"timestamp":"2025-10-16T09:02:00.000Z","type":"response_item","payload":{"type":"mess`
+	rollout := ParseRollout([]byte(data))
+	if len(rollout.Lines) != 19 {
+		t.Errorf("got %d lines, want 19 (partial line ignored)", len(rollout.Lines))
+	}
+}
+
+func TestParsePatchFiles(t *testing.T) {
+	t.Parallel()
+
+	patch := "*** Begin Patch\n*** Add File: a.txt\n+a\n*** Update File: b.txt\n*** Move to: c.txt\n@@\n-x\n+y\n*** Delete File: /abs/d.txt\n*** End Patch"
+	files := ParsePatchFiles(patch, "/work")
+	want := []string{"/work/a.txt", "/work/b.txt", "/work/c.txt", "/abs/d.txt"}
+	if strings.Join(files, ",") != strings.Join(want, ",") {
+		t.Errorf("ParsePatchFiles() = %v, want %v", files, want)
+	}
+}
+
+func TestExtractModifiedFiles(t *testing.T) {
+	t.Parallel()
+
+	rollout := ParseRollout([]byte(testRollout))
+
+	files := ExtractModifiedFiles(rollout, 0)
+	want := []string{"src/hello.go", "/work/repo/docs/CHANGELOG.md"}
+	if strings.Join(files, ",") != strings.Join(want, ",") {
+		t.Errorf("ExtractModifiedFiles() = %v, want %v", files, want)
+	}
+
+	// The second turn only lists files
+	if files := ExtractModifiedFiles(rollout, secondTurnLine); len(files) != 0 {
+		t.Errorf("ExtractModifiedFiles(second turn) = %v, want none", files)
+	}
+}
+
+func TestLastPromptLine(t *testing.T) {
+	t.Parallel()
+
+	if got := LastPromptLine(ParseRollout([]byte(testRollout))); got != secondTurnLine {
+		t.Errorf("LastPromptLine() = %d, want %d", got, secondTurnLine)
+	}
+}
+
+func TestToSessionEntries_TokenUsage(t *testing.T) {
+	t.Parallel()
+
+	entries := ToSessionEntries(ParseRollout([]byte(testRollout)))
+
+	// The repeated token_count event is not a new call
+	usage := agent.SumTokenUsage(entries)
+	if usage.APICallCount != 3 {
+		t.Errorf("APICallCount = %d, want 3", usage.APICallCount)
+	}
+	if usage.InputTokens != 1000+600+100 {
+		t.Errorf("InputTokens = %d, want %d", usage.InputTokens, 1000+600+100)
+	}
+	if usage.CacheReadTokens != 200+800+900 {
+		t.Errorf("CacheReadTokens = %d, want %d", usage.CacheReadTokens, 200+800+900)
+	}
+	if usage.OutputTokens != 150+80+30 {
+		t.Errorf("OutputTokens = %d, want %d", usage.OutputTokens, 150+80+30)
+	}
+
+	second := agent.SumTokenUsage(agent.EntriesFrom(entries, secondTurnLine))
+	if second.APICallCount != 1 || second.OutputTokens != 30 {
+		t.Errorf("second turn usage = %+v, want 1 call with 30 output tokens", second)
+	}
+}
+
+func TestToSessionEntries(t *testing.T) {
+	t.Parallel()
+
+	rollout := ParseRollout([]byte(testRollout))
+	entries := ToSessionEntries(rollout)
+	if len(entries) != len(rollout.Lines) {
+		t.Fatalf("got %d entries, want one per line (%d)", len(entries), len(rollout.Lines))
+	}
+
+	var prompts, responses []string
+	for _, e := range entries {
+		switch e.Type {
+		case agent.EntryUser:
+			prompts = append(prompts, e.Content)
+		case agent.EntryAssistant:
+			responses = append(responses, e.Content)
+		case agent.EntryTool, agent.EntryToolResult, agent.EntrySystem:
+		}
+	}
+	// The injected environment context is not a prompt
+	if strings.Join(prompts, "|") != "Add a greeting and a changelog|List the files" {
+		t.Errorf("prompts = %q", prompts)
+	}
+	if len(responses) != 2 || responses[1] != "CHANGELOG.md and src." {
+		t.Errorf("responses = %q", responses)
+	}
+
+	patch := entries[6]
+	if patch.Type != agent.EntryTool || patch.ToolName != ToolApplyPatch {
+		t.Errorf("entry 6 = %+v, want apply_patch tool call", patch)
+	}
+	if len(patch.FilesAffected) != 1 || patch.FilesAffected[0] != "src/hello.go" {
+		t.Errorf("FilesAffected = %v, want [src/hello.go]", patch.FilesAffected)
+	}
+	if output := entries[7]; output.Type != agent.EntryToolResult || output.ToolName != ToolApplyPatch || output.ToolUseID != "call_1" || output.ToolOutput == nil {
+		t.Errorf("entry 7 = %+v, want apply_patch output", output)
+	}
+	if entries[13].Model != "gpt-5-codex" {
+		t.Errorf("Model = %q, want gpt-5-codex", entries[13].Model)
+	}
+	if entries[3].UUID != testSessionID+"-3" {
+		t.Errorf("UUID = %q, want %q", entries[3].UUID, testSessionID+"-3")
+	}
+	if entries[3].Timestamp.IsZero() {
+		t.Error("entry timestamp not parsed")
+	}
+}
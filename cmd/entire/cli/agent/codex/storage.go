@@ -0,0 +1,171 @@
+package codex
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// errStopWalk ends a directory walk early once a match is found
+var errStopWalk = errors.New("stop walk")
+
+// Home returns Codex's state directory: $CODEX_HOME, or ~/.codex.
+func Home() (string, error) {
+	if home := os.Getenv(codexHomeEnv); home != "" {
+		return home, nil
+	}
+	userHome, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(userHome, defaultCodexDir), nil
+}
+
+// SessionsDir returns the directory holding Codex's rollout files.
+func SessionsDir() (string, error) {
+	home, err := Home()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, sessionsDirName), nil
+}
+
+// isRolloutFile reports whether a file name is a rollout file.
+func isRolloutFile(name string) bool {
+	return strings.HasPrefix(name, rolloutFilePrefix) && strings.HasSuffix(name, rolloutFileSuffix)
+}
+
+// rolloutPath returns where Codex stores the rollout of a session started at startedAt.
+func rolloutPath(sessionsDir, sessionID string, startedAt time.Time) string {
+	startedAt = startedAt.Local()
+	name := rolloutFilePrefix + startedAt.Format(rolloutTimeLayout) + "-" + sessionID + rolloutFileSuffix
+	return filepath.Join(sessionsDir, filepath.FromSlash(startedAt.Format(sessionDirLayout)), name)
+}
+
+// FindRollout returns the rollout file of a session, searching the newest
+// day directories first. Returns "" if there is none.
+func FindRollout(sessionsDir, sessionID string) (string, error) {
+	if sessionID == "" {
+		return "", nil
+	}
+	suffix := "-" + sessionID + rolloutFileSuffix
+	var found string
+	err := walkRolloutsNewestFirst(sessionsDir, func(path string) error {
+		if strings.HasSuffix(filepath.Base(path), suffix) {
+			found = path
+			return errStopWalk
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStopWalk) {
+		return "", err
+	}
+	return found, nil
+}
+
+// FindLatestRollout returns the most recently modified rollout of a session
+// started in cwd. Returns "" if there is none.
+func FindLatestRollout(sessionsDir, cwd string) (string, error) {
+	type rolloutFile struct {
+		path    string
+		modTime time.Time
+	}
+	var files []rolloutFile
+	err := walkRolloutsNewestFirst(sessionsDir, func(path string) error {
+		if info, err := os.Stat(path); err == nil {
+			files = append(files, rolloutFile{path: path, modTime: info.ModTime()})
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// Only read the headers of the newest rollouts until one matches
+	sort.SliceStable(files, func(i, j int) bool { return files[i].modTime.After(files[j].modTime) })
+	for _, f := range files {
+		if meta, err := ReadSessionMeta(f.path); err == nil && sameDir(meta.Cwd, cwd) {
+			return f.path, nil
+		}
+	}
+	return "", nil
+}
+
+// walkRolloutsNewestFirst calls fn for each rollout file under sessionsDir,
+// walking the YYYY/MM/DD directories in reverse order.
+func walkRolloutsNewestFirst(sessionsDir string, fn func(path string) error) error {
+	var walk func(dir string, depth int) error
+	walk = func(dir string, depth int) error {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return fmt.Errorf("failed to read sessions directory: %w", err)
+		}
+		for i := len(entries) - 1; i >= 0; i-- {
+			entry := entries[i]
+			path := filepath.Join(dir, entry.Name())
+			switch {
+			case entry.IsDir() && depth < 3:
+				if err := walk(path, depth+1); err != nil {
+					return err
+				}
+			case !entry.IsDir() && isRolloutFile(entry.Name()):
+				if err := fn(path); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return walk(sessionsDir, 0)
+}
+
+// ReadSessionMeta reads the session metadata from the first line of a rollout.
+func ReadSessionMeta(path string) (*SessionMeta, error) {
+	file, err := os.Open(path) //nolint:gosec // Path comes from Codex's sessions directory
+	if err != nil {
+		return nil, fmt.Errorf("failed to open rollout: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	firstLine, err := reader.ReadBytes('\n')
+	if err != nil && len(firstLine) == 0 {
+		return nil, fmt.Errorf("failed to read rollout: %w", err)
+	}
+	var line RolloutLine
+	if err := json.Unmarshal(firstLine, &line); err != nil {
+		return nil, fmt.Errorf("failed to parse rollout header: %w", err)
+	}
+	if line.Type != LineTypeSessionMeta {
+		return nil, fmt.Errorf("rollout %s has no session metadata", path)
+	}
+	var meta SessionMeta
+	if err := json.Unmarshal(line.Payload, &meta); err != nil {
+		return nil, fmt.Errorf("failed to parse session metadata: %w", err)
+	}
+	return &meta, nil
+}
+
+// sameDir reports whether two paths name the same directory, resolving symlinks
+// (e.g. /var vs /private/var on macOS).
+func sameDir(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	if filepath.Clean(a) == filepath.Clean(b) {
+		return true
+	}
+	resolvedA, errA := filepath.EvalSymlinks(a)
+	resolvedB, errB := filepath.EvalSymlinks(b)
+	return errA == nil && errB == nil && resolvedA == resolvedB
+}
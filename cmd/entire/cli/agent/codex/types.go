@@ -0,0 +1,157 @@
+package codex
+
+import "encoding/json"
+
+// Codex CLI keeps its state under $CODEX_HOME (default ~/.codex):
+//   - config.toml is the user configuration. Its top-level "notify" key names a
+//     program Codex runs after each turn, passing a JSON payload as the last argument.
+//   - sessions/YYYY/MM/DD/rollout-<timestamp>-<session-id>.jsonl is the transcript
+//     ("rollout") of a session. The first line is the session metadata; each line
+//     after it is a response item (message, tool call, tool output), an event
+//     (e.g. token counts), or the context of a turn.
+
+// Storage file and directory names
+const (
+	codexHomeEnv      = "CODEX_HOME"
+	defaultCodexDir   = ".codex"
+	configFileName    = "config.toml"
+	sessionsDirName   = "sessions"
+	rolloutFilePrefix = "rollout-"
+	rolloutFileSuffix = ".jsonl"
+	rolloutTimeLayout = "2006-01-02T15-04-05"
+	sessionDirLayout  = "2006/01/02"
+)
+
+// Rollout line types
+const (
+	LineTypeSessionMeta  = "session_meta"
+	LineTypeResponseItem = "response_item"
+	LineTypeEventMsg     = "event_msg"
+	LineTypeTurnContext  = "turn_context"
+	LineTypeCompacted    = "compacted"
+)
+
+// Response item types
+const (
+	ItemTypeMessage          = "message"
+	ItemTypeReasoning        = "reasoning"
+	ItemTypeFunctionCall     = "function_call"
+	ItemTypeFunctionOutput   = "function_call_output"
+	ItemTypeCustomToolCall   = "custom_tool_call"
+	ItemTypeCustomToolOutput = "custom_tool_call_output"
+	ItemTypeLocalShellCall   = "local_shell_call"
+)
+
+// Event types
+const (
+	EventTypeTokenCount  = "token_count"
+	EventTypeTurnAborted = "turn_aborted"
+)
+
+// Tool names used by Codex that modify files
+const (
+	ToolApplyPatch    = "apply_patch"
+	ToolShell         = "shell"
+	ToolShellCommand  = "shell_command"
+	ToolContainerExec = "container.exec"
+)
+
+// RolloutLine is a single line of a rollout file
+type RolloutLine struct {
+	Timestamp string          `json:"timestamp"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// SessionMeta is the payload of the session_meta line
+type SessionMeta struct {
+	ID         string `json:"id"`
+	Timestamp  string `json:"timestamp"`
+	Cwd        string `json:"cwd"`
+	Originator string `json:"originator,omitempty"`
+	CLIVersion string `json:"cli_version,omitempty"`
+}
+
+// ResponseItem is the payload of a response_item line.
+// Only the fields of the item types Entire reads are included.
+type ResponseItem struct {
+	Type    string        `json:"type"`
+	Role    string        `json:"role,omitempty"`
+	Content []ContentItem `json:"content,omitempty"`
+	Summary []ContentItem `json:"summary,omitempty"`
+
+	// function_call and custom_tool_call
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments,omitempty"`
+	Input     string `json:"input,omitempty"`
+	CallID    string `json:"call_id,omitempty"`
+
+	// function_call_output and custom_tool_call_output
+	Output json.RawMessage `json:"output,omitempty"`
+
+	// local_shell_call
+	Action *LocalShellAction `json:"action,omitempty"`
+}
+
+// ContentItem is a piece of message content or reasoning summary
+type ContentItem struct {
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+}
+
+// LocalShellAction is the command run by a local_shell_call
+type LocalShellAction struct {
+	Type             string   `json:"type"`
+	Command          []string `json:"command"`
+	WorkingDirectory string   `json:"working_directory,omitempty"`
+}
+
+// shellArgs are the arguments of the shell tools.
+// "shell" passes the command as argv, "shell_command" as a script.
+type shellArgs struct {
+	Command json.RawMessage `json:"command"`
+	Workdir string          `json:"workdir,omitempty"`
+}
+
+// applyPatchArgs are the arguments of apply_patch as a function tool
+type applyPatchArgs struct {
+	Input string `json:"input"`
+}
+
+// EventMsg is the payload of an event_msg line
+type EventMsg struct {
+	Type    string          `json:"type"`
+	Message string          `json:"message,omitempty"`
+	Info    *TokenCountInfo `json:"info,omitempty"`
+}
+
+// TokenCountInfo is the usage reported by a token_count event
+type TokenCountInfo struct {
+	TotalTokenUsage TokenCounts `json:"total_token_usage"`
+	LastTokenUsage  TokenCounts `json:"last_token_usage"`
+}
+
+// TokenCounts are the token counts of one or more model calls.
+// InputTokens includes CachedInputTokens.
+type TokenCounts struct {
+	InputTokens           int `json:"input_tokens"`
+	CachedInputTokens     int `json:"cached_input_tokens"`
+	OutputTokens          int `json:"output_tokens"`
+	ReasoningOutputTokens int `json:"reasoning_output_tokens"`
+	TotalTokens           int `json:"total_tokens"`
+}
+
+// turnContext is the payload of a turn_context line
+type turnContext struct {
+	Model string `json:"model"`
+}
+
+// notifyPayload is the JSON Codex passes to the notify program
+type notifyPayload struct {
+	Type                 string   `json:"type"`
+	ThreadID             string   `json:"thread-id"`
+	TurnID               string   `json:"turn-id"`
+	Cwd                  string   `json:"cwd"`
+	InputMessages        []string `json:"input-messages"`
+	LastAssistantMessage string   `json:"last-assistant-message"`
+}
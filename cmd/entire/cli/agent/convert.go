@@ -0,0 +1,121 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxConvertedToolResultLength bounds tool output carried into another agent's
+// transcript; the target only needs enough of it to follow the conversation.
+const maxConvertedToolResultLength = 2000
+
+// ConvertSession converts transcript data written by an agent of type from into
+// a session of the target agent, with NativeData ready for WriteSession.
+// Transcripts of the target's own type are kept as they are.
+func ConvertSession(from AgentType, data []byte, target TranscriptBuilder, agentSessionID, repoPath string) (*AgentSession, error) {
+	entries, err := ParseTranscriptEntries(from, data)
+	if err != nil {
+		return nil, err
+	}
+	session := &AgentSession{
+		SessionID: agentSessionID,
+		AgentName: target.Name(),
+		RepoPath:  repoPath,
+		StartTime: time.Now(),
+		Entries:   entries,
+	}
+	if from == target.Type() {
+		session.NativeData = data
+		return session, nil
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no transcript entries to convert from %s", from)
+	}
+
+	native, err := target.BuildTranscript(session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build %s transcript: %w", target.Type(), err)
+	}
+	session.NativeData = native
+	return session, nil
+}
+
+// ConversationTurn is a user or assistant message of a transcript converted
+// between agents.
+type ConversationTurn struct {
+	// Type is EntryUser or EntryAssistant
+	Type      EntryType
+	Content   string
+	Model     string
+	Timestamp time.Time
+}
+
+// ConversationTurns down-converts entries into text turns for agents that
+// don't share tools: tool calls and their results are rendered as text in the
+// assistant's turn, system entries are dropped, and consecutive entries of the
+// same role are merged into one turn.
+func ConversationTurns(entries []SessionEntry) []ConversationTurn {
+	var turns []ConversationTurn
+	for _, entry := range entries {
+		var turnType EntryType
+		var text string
+		switch entry.Type {
+		case EntryUser:
+			turnType, text = EntryUser, entry.Content
+		case EntryAssistant:
+			turnType, text = EntryAssistant, entry.Content
+		case EntryTool:
+			turnType, text = EntryAssistant, fmt.Sprintf("[Tool call: %s] %s", entry.ToolName, toolValueText(entry.ToolInput))
+		case EntryToolResult:
+			turnType, text = EntryAssistant, "[Tool result] "+truncateToolResult(toolValueText(entry.ToolOutput))
+		case EntrySystem:
+			continue
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+
+		if n := len(turns); n > 0 && turns[n-1].Type == turnType {
+			turns[n-1].Content += "\n\n" + text
+			if turns[n-1].Model == "" {
+				turns[n-1].Model = entry.Model
+			}
+			continue
+		}
+		turns = append(turns, ConversationTurn{
+			Type:      turnType,
+			Content:   text,
+			Model:     entry.Model,
+			Timestamp: entry.Timestamp,
+		})
+	}
+	return turns
+}
+
+// toolValueText renders a tool input or output as text.
+func toolValueText(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case json.RawMessage:
+		return string(value)
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+		return string(data)
+	}
+}
+
+func truncateToolResult(s string) string {
+	if len(s) <= maxConvertedToolResultLength {
+		return s
+	}
+	return strings.ToValidUTF8(s[:maxConvertedToolResultLength], "") + "... (truncated)"
+}
@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConversationTurns(t *testing.T) {
+	t.Parallel()
+
+	turns := ConversationTurns([]SessionEntry{
+		{Type: EntryUser, Content: "Fix the bug"},
+		{Type: EntrySystem, Content: "ignored"},
+		{Type: EntryAssistant, Content: "Looking.", Model: "claude-sonnet-4"},
+		{Type: EntryTool, ToolName: "Read", ToolInput: map[string]string{"file_path": "main.go"}},
+		{Type: EntryToolResult, ToolOutput: "package main"},
+		{Type: EntryAssistant, Content: "Done."},
+		{Type: EntryUser, Content: "  "},
+		{Type: EntryUser, Content: "Thanks"},
+	})
+
+	if len(turns) != 3 {
+		t.Fatalf("got %d turns, want 3: %+v", len(turns), turns)
+	}
+	if turns[0].Type != EntryUser || turns[0].Content != "Fix the bug" {
+		t.Errorf("turn 0 = %+v", turns[0])
+	}
+	want := "Looking.\n\n[Tool call: Read] {\"file_path\":\"main.go\"}\n\n[Tool result] package main\n\nDone."
+	if turns[1].Type != EntryAssistant || turns[1].Content != want {
+		t.Errorf("turn 1 content = %q, want %q", turns[1].Content, want)
+	}
+	if turns[1].Model != "claude-sonnet-4" {
+		t.Errorf("turn 1 model = %q", turns[1].Model)
+	}
+	if turns[2].Type != EntryUser || turns[2].Content != "Thanks" {
+		t.Errorf("turn 2 = %+v", turns[2])
+	}
+}
+
+func TestConversationTurns_TruncatesToolResults(t *testing.T) {
+	t.Parallel()
+
+	turns := ConversationTurns([]SessionEntry{
+		{Type: EntryToolResult, ToolOutput: strings.Repeat("x", maxConvertedToolResultLength+100)},
+	})
+	if len(turns) != 1 {
+		t.Fatalf("got %d turns, want 1", len(turns))
+	}
+	if !strings.HasSuffix(turns[0].Content, "... (truncated)") {
+		t.Errorf("long tool result not truncated: %d bytes", len(turns[0].Content))
+	}
+}
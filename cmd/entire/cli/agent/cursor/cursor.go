@@ -0,0 +1,305 @@
+// Package cursor implements the Agent interface for Cursor.
+//
+// Cursor has no lifecycle hooks, so sessions are detected by watching its
+// state databases (see FileWatcher) and read directly from SQLite.
+package cursor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/sessionid"
+)
+
+//nolint:gochecknoinits // Agent self-registration is the intended pattern
+func init() {
+	agent.Register(agent.AgentNameCursor, NewCursorAgent)
+}
+
+// CursorAgent implements the Agent interface for Cursor.
+//
+//nolint:revive // CursorAgent is clearer than Agent in this context
+type CursorAgent struct{}
+
+func NewCursorAgent() agent.Agent {
+	return &CursorAgent{}
+}
+
+// Name returns the agent registry key.
+func (c *CursorAgent) Name() agent.AgentName {
+	return agent.AgentNameCursor
+}
+
+// Type returns the agent type identifier.
+func (c *CursorAgent) Type() agent.AgentType {
+	return agent.AgentTypeCursor
+}
+
+// Description returns a human-readable description.
+func (c *CursorAgent) Description() string {
+	return "Cursor - AI code editor (file watching, no hooks)"
+}
+
+// DetectPresence checks if Cursor is configured in the repository.
+func (c *CursorAgent) DetectPresence() (bool, error) {
+	repoRoot, err := paths.RepoRoot()
+	if err != nil {
+		// Not in a git repo, fall back to CWD-relative check
+		repoRoot = "."
+	}
+
+	// Check for .cursor directory (rules, MCP config)
+	if _, err := os.Stat(filepath.Join(repoRoot, ".cursor")); err == nil {
+		return true, nil
+	}
+	// Check for legacy .cursorrules file
+	if _, err := os.Stat(filepath.Join(repoRoot, ".cursorrules")); err == nil {
+		return true, nil
+	}
+	return false, nil
+}
+
+// GetHookConfigPath returns "" as Cursor has no hook configuration.
+func (c *CursorAgent) GetHookConfigPath() string {
+	return ""
+}
+
+// SupportsHooks returns false; Cursor sessions are detected by file watching.
+func (c *CursorAgent) SupportsHooks() bool {
+	return false
+}
+
+// ParseHookInput always fails as Cursor does not invoke hooks.
+func (c *CursorAgent) ParseHookInput(_ agent.HookType, _ io.Reader) (*agent.HookInput, error) {
+	return nil, errors.New("cursor does not support hooks; sessions are detected by file watching")
+}
+
+// GetSessionID extracts the session ID from hook input.
+func (c *CursorAgent) GetSessionID(input *agent.HookInput) string {
+	return input.SessionID
+}
+
+// TransformSessionID converts a Cursor composer ID to an Entire session ID.
+// This is an identity function - the composer ID IS the Entire session ID.
+func (c *CursorAgent) TransformSessionID(agentSessionID string) string {
+	return agentSessionID
+}
+
+// ExtractAgentSessionID extracts the Cursor composer ID from an Entire session ID.
+// For backwards compatibility with legacy date-prefixed IDs, it strips the prefix if present.
+func (c *CursorAgent) ExtractAgentSessionID(entireSessionID string) string {
+	return sessionid.ModelSessionID(entireSessionID)
+}
+
+// GetSessionDir returns the directory holding Cursor's global state database.
+// Cursor stores all chats in one database regardless of the repository.
+func (c *CursorAgent) GetSessionDir(_ string) (string, error) {
+	userDir, err := UserDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(userDir, globalStorageDir), nil
+}
+
+// ReadSession reads a composer from Cursor's state database, or from a stored
+// JSONL transcript if SessionRef is a file path.
+// NativeData holds the bubbles as JSONL.
+func (c *CursorAgent) ReadSession(input *agent.HookInput) (*agent.AgentSession, error) {
+	if input.SessionRef == "" {
+		return nil, errors.New("session reference is required")
+	}
+
+	bubbles, err := loadBubbles(input.SessionRef)
+	if err != nil {
+		return nil, err
+	}
+
+	return &agent.AgentSession{
+		SessionID:     input.SessionID,
+		AgentName:     c.Name(),
+		SessionRef:    input.SessionRef,
+		StartTime:     time.Now(),
+		NativeData:    FormatTranscript(bubbles),
+		ModifiedFiles: ExtractModifiedFiles(bubbles),
+		Entries:       ToSessionEntries(bubbles),
+	}, nil
+}
+
+// WriteSession writes a session's bubbles back into Cursor's global state database.
+// SessionRef may be a session reference or a path inside the global storage
+// directory (as built by resume from GetSessionDir). Cursor must be restarted
+// to pick up the restored chat.
+func (c *CursorAgent) WriteSession(session *agent.AgentSession) error {
+	if session == nil {
+		return errors.New("session is nil")
+	}
+
+	// Verify this session belongs to Cursor
+	if session.AgentName != "" && session.AgentName != c.Name() {
+		return fmt.Errorf("session belongs to agent %q, not %q", session.AgentName, c.Name())
+	}
+
+	if session.SessionRef == "" {
+		return errors.New("session reference is required")
+	}
+
+	if len(session.NativeData) == 0 {
+		return errors.New("session has no native data to write")
+	}
+
+	dbPath, composerID, ok := ParseSessionRef(session.SessionRef)
+	if !ok {
+		dbPath = filepath.Join(filepath.Dir(session.SessionRef), stateDBFileName)
+		composerID = c.ExtractAgentSessionID(session.SessionID)
+	}
+	if composerID == "" {
+		return errors.New("session ID is required")
+	}
+
+	bubbles, err := ParseTranscript(session.NativeData)
+	if err != nil {
+		return err
+	}
+	return writeComposer(dbPath, composerID, bubbles)
+}
+
+// FormatResumeCommand returns the command to reopen Cursor in the repository.
+// Cursor has no CLI flag to select a chat; the restored chat appears in the
+// composer history.
+func (c *CursorAgent) FormatResumeCommand(_ string) string {
+	return "cursor ."
+}
+
+// FileWatcher interface implementation
+
+// GetWatchPaths returns the global storage directory, where Cursor writes chat data.
+func (c *CursorAgent) GetWatchPaths() ([]string, error) {
+	dir, err := c.GetSessionDir("")
+	if err != nil {
+		return nil, err
+	}
+	return []string{dir}, nil
+}
+
+// OnFileChange maps a state database change to the most recently updated
+// composer of the current repository's workspace.
+// Returns nil if the change is unrelated (other files, other workspaces, no chats).
+func (c *CursorAgent) OnFileChange(path string) (*agent.SessionChange, error) {
+	// Writes land in state.vscdb or its -wal/-journal files
+	if !strings.HasPrefix(filepath.Base(path), stateDBFileName) {
+		return nil, nil
+	}
+
+	userDir, err := UserDir()
+	if err != nil {
+		return nil, err
+	}
+	repoRoot, err := paths.RepoRoot()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository root: %w", err)
+	}
+	workspaceDB, err := FindWorkspaceDB(userDir, repoRoot)
+	if err != nil || workspaceDB == "" {
+		return nil, err
+	}
+
+	list, err := readComposerList(workspaceDB)
+	if err != nil {
+		return nil, err
+	}
+	var latest *composerHead
+	for i := range list.AllComposers {
+		head := &list.AllComposers[i]
+		if latest == nil || head.LastUpdatedAt > latest.LastUpdatedAt {
+			latest = head
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+
+	globalDB := filepath.Join(userDir, globalStorageDir, stateDBFileName)
+	_, bubbles, err := readComposer(globalDB, latest.ComposerID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The last bubble tells where the turn is: a user bubble means a prompt was
+	// just submitted, an assistant bubble means the agent responded.
+	eventType := agent.HookSessionStart
+	if n := len(bubbles); n > 0 {
+		if bubbles[n-1].Type == BubbleTypeUser {
+			eventType = agent.HookUserPromptSubmit
+		} else {
+			eventType = agent.HookStop
+		}
+	}
+
+	timestamp := time.Now()
+	if latest.LastUpdatedAt > 0 {
+		timestamp = time.UnixMilli(latest.LastUpdatedAt)
+	}
+
+	return &agent.SessionChange{
+		SessionID:  latest.ComposerID,
+		SessionRef: SessionRef(globalDB, latest.ComposerID),
+		EventType:  eventType,
+		Timestamp:  timestamp,
+	}, nil
+}
+
+// TranscriptAnalyzer interface implementation
+
+// GetTranscriptPosition returns the number of bubbles in a composer.
+// path is a session reference or a stored JSONL transcript.
+// Returns 0 if the transcript doesn't exist or is empty.
+func (c *CursorAgent) GetTranscriptPosition(path string) (int, error) {
+	if path == "" {
+		return 0, nil
+	}
+	bubbles, err := loadBubbles(path)
+	if err != nil {
+		return 0, err
+	}
+	return len(bubbles), nil
+}
+
+// ExtractModifiedFilesFromOffset extracts files modified since a given bubble index.
+// Returns:
+//   - files: list of file paths modified by Cursor's agent (from edit tools)
+//   - currentPosition: total number of bubbles
+//   - error: any error encountered during reading
+func (c *CursorAgent) ExtractModifiedFilesFromOffset(path string, startOffset int) (files []string, currentPosition int, err error) {
+	if path == "" {
+		return nil, 0, nil
+	}
+	bubbles, err := loadBubbles(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	if startOffset < 0 {
+		startOffset = 0
+	}
+	if startOffset > len(bubbles) {
+		startOffset = len(bubbles)
+	}
+	return ExtractModifiedFiles(bubbles[startOffset:]), len(bubbles), nil
+}
+
+// TranscriptParser interface implementation
+
+// ParseEntries normalizes a stored JSONL transcript into session entries, one per bubble.
+func (c *CursorAgent) ParseEntries(data []byte) ([]agent.SessionEntry, error) {
+	bubbles, err := ParseTranscript(data)
+	if err != nil {
+		return nil, err
+	}
+	return ToSessionEntries(bubbles), nil
+}
@@ -0,0 +1,388 @@
+package cursor
+
+import (
+	"database/sql"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+const testComposerID = "c0ffee00-1111-2222-3333-444455556666"
+
+// Bubbles of a short chat: a prompt, an edit tool call, and a text response.
+var testBubbles = []string{
+	`{"bubbleId":"b1","type":1,"text":"Add a greeting","createdAt":"2026-01-02T10:00:00Z"}`,
+	`{"bubbleId":"b2","type":2,"text":"","toolFormerData":{"name":"edit_file","rawArgs":"{\"target_file\":\"hello.go\",\"instructions\":\"add greeting\"}","status":"completed","result":"ok"}}`,
+	`{"bubbleId":"b3","type":2,"text":"Added a greeting to hello.go."}`,
+}
+
+// createStateDB creates a state database with Cursor's schema.
+func createStateDB(t *testing.T, path string) *sql.DB {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("failed to create state dir: %v", err)
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("failed to open state db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	for _, table := range []string{itemTable, kvTable} {
+		if _, err := db.Exec("CREATE TABLE " + table + " (key TEXT UNIQUE ON CONFLICT REPLACE, value BLOB)"); err != nil {
+			t.Fatalf("failed to create %s: %v", table, err)
+		}
+	}
+	return db
+}
+
+func putValue(t *testing.T, db *sql.DB, table, key, value string) {
+	t.Helper()
+	if _, err := db.Exec("INSERT INTO "+table+" (key, value) VALUES (?, ?)", key, value); err != nil {
+		t.Fatalf("failed to insert %s: %v", key, err)
+	}
+}
+
+// setupCursorUserDir creates a Cursor user directory containing one composer
+// (stored as separate bubble rows) opened in a workspace for repoDir.
+// Returns the global database path.
+func setupCursorUserDir(t *testing.T, repoDir string, bubbles []string) string {
+	t.Helper()
+	userDir := t.TempDir()
+	t.Setenv(userDirOverrideEnv, userDir)
+
+	globalDB := filepath.Join(userDir, globalStorageDir, stateDBFileName)
+	global := createStateDB(t, globalDB)
+	headers := make([]bubbleHeader, 0, len(bubbles))
+	for _, raw := range bubbles {
+		var b Bubble
+		if err := json.Unmarshal([]byte(raw), &b); err != nil {
+			t.Fatalf("invalid test bubble: %v", err)
+		}
+		headers = append(headers, bubbleHeader{BubbleID: b.BubbleID, Type: b.Type})
+		putValue(t, global, kvTable, bubbleKey(testComposerID, b.BubbleID), raw)
+	}
+	composer, err := json.Marshal(composerData{
+		ComposerID:                  testComposerID,
+		Name:                        "Greeting",
+		FullConversationHeadersOnly: headers,
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal composer: %v", err)
+	}
+	putValue(t, global, kvTable, composerDataPrefix+testComposerID, string(composer))
+
+	wsDir := filepath.Join(userDir, workspaceStorageDir, "0123abcd")
+	workspace := createStateDB(t, filepath.Join(wsDir, stateDBFileName))
+	list, err := json.Marshal(composerList{AllComposers: []composerHead{
+		{ComposerID: "older-composer", LastUpdatedAt: 1000},
+		{ComposerID: testComposerID, LastUpdatedAt: 2000},
+	}})
+	if err != nil {
+		t.Fatalf("failed to marshal composer list: %v", err)
+	}
+	putValue(t, workspace, itemTable, composerListKey, string(list))
+
+	info := `{"folder":"file://` + filepath.ToSlash(repoDir) + `"}`
+	if err := os.WriteFile(filepath.Join(wsDir, workspaceFileName), []byte(info), 0o644); err != nil {
+		t.Fatalf("failed to write workspace.json: %v", err)
+	}
+	return globalDB
+}
+
+// initRepo creates a git repository and changes into it.
+func initRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	cmd := exec.Command("git", "init", "-q", dir)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v\n%s", err, output)
+	}
+	t.Chdir(dir)
+	return dir
+}
+
+func TestName(t *testing.T) {
+	ag := &CursorAgent{}
+	if name := ag.Name(); name != agent.AgentNameCursor {
+		t.Errorf("Name() = %q, want %q", name, agent.AgentNameCursor)
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	ag, err := agent.Get(agent.AgentNameCursor)
+	if err != nil {
+		t.Fatalf("Get(%q) error = %v", agent.AgentNameCursor, err)
+	}
+	if _, ok := ag.(agent.FileWatcher); !ok {
+		t.Error("CursorAgent does not implement FileWatcher")
+	}
+	if _, ok := ag.(agent.TranscriptAnalyzer); !ok {
+		t.Error("CursorAgent does not implement TranscriptAnalyzer")
+	}
+	if ag.SupportsHooks() {
+		t.Error("SupportsHooks() = true, want false")
+	}
+}
+
+func TestDetectPresence(t *testing.T) {
+	t.Run("no cursor config", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		present, err := (&CursorAgent{}).DetectPresence()
+		if err != nil {
+			t.Fatalf("DetectPresence() error = %v", err)
+		}
+		if present {
+			t.Error("DetectPresence() = true, want false")
+		}
+	})
+
+	t.Run("with .cursorrules", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		if err := os.WriteFile(".cursorrules", []byte("rules"), 0o644); err != nil {
+			t.Fatalf("failed to write .cursorrules: %v", err)
+		}
+		present, err := (&CursorAgent{}).DetectPresence()
+		if err != nil {
+			t.Fatalf("DetectPresence() error = %v", err)
+		}
+		if !present {
+			t.Error("DetectPresence() = false, want true")
+		}
+	})
+}
+
+func TestParseSessionRef(t *testing.T) {
+	dbPath := filepath.Join("home", "Cursor", "User", globalStorageDir, stateDBFileName)
+	ref := SessionRef(dbPath, testComposerID)
+
+	gotDB, gotID, ok := ParseSessionRef(ref)
+	if !ok || gotDB != dbPath || gotID != testComposerID {
+		t.Errorf("ParseSessionRef(%q) = (%q, %q, %v)", ref, gotDB, gotID, ok)
+	}
+
+	for _, notRef := range []string{"", "/tmp/transcript.jsonl", "/tmp/a#b.jsonl", dbPath + "#"} {
+		if _, _, ok := ParseSessionRef(notRef); ok {
+			t.Errorf("ParseSessionRef(%q) ok = true, want false", notRef)
+		}
+	}
+}
+
+func TestReadSession(t *testing.T) {
+	globalDB := setupCursorUserDir(t, t.TempDir(), testBubbles)
+
+	ag := &CursorAgent{}
+	session, err := ag.ReadSession(&agent.HookInput{
+		SessionID:  testComposerID,
+		SessionRef: SessionRef(globalDB, testComposerID),
+	})
+	if err != nil {
+		t.Fatalf("ReadSession() error = %v", err)
+	}
+
+	if session.AgentName != agent.AgentNameCursor {
+		t.Errorf("AgentName = %q, want %q", session.AgentName, agent.AgentNameCursor)
+	}
+	if len(session.ModifiedFiles) != 1 || session.ModifiedFiles[0] != "hello.go" {
+		t.Errorf("ModifiedFiles = %v, want [hello.go]", session.ModifiedFiles)
+	}
+	if got := strings.Count(string(session.NativeData), "\n"); got != len(testBubbles) {
+		t.Errorf("NativeData has %d lines, want %d", got, len(testBubbles))
+	}
+	if len(session.Entries) != 3 {
+		t.Fatalf("Entries = %d, want 3", len(session.Entries))
+	}
+	if got := session.GetLastUserPrompt(); got != "Add a greeting" {
+		t.Errorf("GetLastUserPrompt() = %q", got)
+	}
+	if got := session.GetLastAssistantResponse(); got != "Added a greeting to hello.go." {
+		t.Errorf("GetLastAssistantResponse() = %q", got)
+	}
+}
+
+func TestReadSession_InlineConversation(t *testing.T) {
+	userDir := t.TempDir()
+	t.Setenv(userDirOverrideEnv, userDir)
+	globalDB := filepath.Join(userDir, globalStorageDir, stateDBFileName)
+	db := createStateDB(t, globalDB)
+	putValue(t, db, kvTable, composerDataPrefix+testComposerID,
+		`{"composerId":"`+testComposerID+`","conversation":[`+strings.Join(testBubbles, ",")+`]}`)
+
+	session, err := (&CursorAgent{}).ReadSession(&agent.HookInput{
+		SessionID:  testComposerID,
+		SessionRef: SessionRef(globalDB, testComposerID),
+	})
+	if err != nil {
+		t.Fatalf("ReadSession() error = %v", err)
+	}
+	if len(session.Entries) != len(testBubbles) {
+		t.Errorf("Entries = %d, want %d", len(session.Entries), len(testBubbles))
+	}
+}
+
+func TestReadSession_UnknownComposer(t *testing.T) {
+	globalDB := setupCursorUserDir(t, t.TempDir(), testBubbles)
+	_, err := (&CursorAgent{}).ReadSession(&agent.HookInput{
+		SessionRef: SessionRef(globalDB, "missing"),
+	})
+	if err == nil {
+		t.Error("ReadSession() should error for an unknown composer")
+	}
+}
+
+func TestWriteSession_RoundTrip(t *testing.T) {
+	globalDB := setupCursorUserDir(t, t.TempDir(), testBubbles[:1])
+	transcript := []byte(strings.Join(testBubbles, "\n") + "\n")
+
+	ag := &CursorAgent{}
+	sessionDir, err := ag.GetSessionDir("")
+	if err != nil {
+		t.Fatalf("GetSessionDir() error = %v", err)
+	}
+	// Resume builds SessionRef as <session dir>/<agent session id>.jsonl
+	err = ag.WriteSession(&agent.AgentSession{
+		SessionID:  testComposerID,
+		AgentName:  agent.AgentNameCursor,
+		SessionRef: filepath.Join(sessionDir, testComposerID+".jsonl"),
+		NativeData: transcript,
+	})
+	if err != nil {
+		t.Fatalf("WriteSession() error = %v", err)
+	}
+
+	session, err := ag.ReadSession(&agent.HookInput{SessionRef: SessionRef(globalDB, testComposerID)})
+	if err != nil {
+		t.Fatalf("ReadSession() error = %v", err)
+	}
+	if string(session.NativeData) != string(transcript) {
+		t.Errorf("NativeData after round trip:\n%s\nwant:\n%s", session.NativeData, transcript)
+	}
+
+	// Fields Entire doesn't model must be preserved
+	db, err := openDB(globalDB, true)
+	if err != nil {
+		t.Fatalf("openDB() error = %v", err)
+	}
+	defer db.Close()
+	raw, err := readValue(db, kvTable, composerDataPrefix+testComposerID)
+	if err != nil {
+		t.Fatalf("readValue() error = %v", err)
+	}
+	if !strings.Contains(string(raw), `"name":"Greeting"`) {
+		t.Errorf("composer lost its name: %s", raw)
+	}
+}
+
+func TestWriteSession_WrongAgent(t *testing.T) {
+	err := (&CursorAgent{}).WriteSession(&agent.AgentSession{
+		AgentName:  agent.AgentNameClaudeCode,
+		SessionRef: "/tmp/state.vscdb#id",
+		NativeData: []byte("{}"),
+	})
+	if err == nil {
+		t.Error("WriteSession() should error for another agent's session")
+	}
+}
+
+func TestOnFileChange(t *testing.T) {
+	repoDir := initRepo(t)
+	globalDB := setupCursorUserDir(t, repoDir, testBubbles)
+
+	ag := &CursorAgent{}
+	change, err := ag.OnFileChange(globalDB + "-wal")
+	if err != nil {
+		t.Fatalf("OnFileChange() error = %v", err)
+	}
+	if change == nil {
+		t.Fatal("OnFileChange() = nil, want change")
+	}
+	if change.SessionID != testComposerID {
+		t.Errorf("SessionID = %q, want %q (most recently updated composer)", change.SessionID, testComposerID)
+	}
+	if change.SessionRef != SessionRef(globalDB, testComposerID) {
+		t.Errorf("SessionRef = %q", change.SessionRef)
+	}
+	if change.EventType != agent.HookStop {
+		t.Errorf("EventType = %q, want %q", change.EventType, agent.HookStop)
+	}
+}
+
+func TestOnFileChange_PendingPrompt(t *testing.T) {
+	repoDir := initRepo(t)
+	globalDB := setupCursorUserDir(t, repoDir, testBubbles[:1])
+
+	change, err := (&CursorAgent{}).OnFileChange(globalDB)
+	if err != nil {
+		t.Fatalf("OnFileChange() error = %v", err)
+	}
+	if change == nil || change.EventType != agent.HookUserPromptSubmit {
+		t.Errorf("OnFileChange() = %+v, want %q event", change, agent.HookUserPromptSubmit)
+	}
+}
+
+func TestOnFileChange_Ignored(t *testing.T) {
+	t.Run("unrelated file", func(t *testing.T) {
+		repoDir := initRepo(t)
+		setupCursorUserDir(t, repoDir, testBubbles)
+		change, err := (&CursorAgent{}).OnFileChange(filepath.Join(repoDir, "storage.json"))
+		if err != nil || change != nil {
+			t.Errorf("OnFileChange() = (%+v, %v), want (nil, nil)", change, err)
+		}
+	})
+
+	t.Run("repository not opened in cursor", func(t *testing.T) {
+		initRepo(t)
+		globalDB := setupCursorUserDir(t, t.TempDir(), testBubbles)
+		change, err := (&CursorAgent{}).OnFileChange(globalDB)
+		if err != nil || change != nil {
+			t.Errorf("OnFileChange() = (%+v, %v), want (nil, nil)", change, err)
+		}
+	})
+}
+
+func TestExtractModifiedFilesFromOffset(t *testing.T) {
+	globalDB := setupCursorUserDir(t, t.TempDir(), testBubbles)
+	ref := SessionRef(globalDB, testComposerID)
+	ag := &CursorAgent{}
+
+	pos, err := ag.GetTranscriptPosition(ref)
+	if err != nil {
+		t.Fatalf("GetTranscriptPosition() error = %v", err)
+	}
+	if pos != len(testBubbles) {
+		t.Errorf("GetTranscriptPosition() = %d, want %d", pos, len(testBubbles))
+	}
+
+	files, pos, err := ag.ExtractModifiedFilesFromOffset(ref, 0)
+	if err != nil {
+		t.Fatalf("ExtractModifiedFilesFromOffset() error = %v", err)
+	}
+	if len(files) != 1 || files[0] != "hello.go" || pos != len(testBubbles) {
+		t.Errorf("ExtractModifiedFilesFromOffset(0) = (%v, %d)", files, pos)
+	}
+
+	files, _, err = ag.ExtractModifiedFilesFromOffset(ref, 2)
+	if err != nil {
+		t.Fatalf("ExtractModifiedFilesFromOffset() error = %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("ExtractModifiedFilesFromOffset(2) = %v, want none", files)
+	}
+
+	// Stored transcripts use the same positions
+	transcriptPath := filepath.Join(t.TempDir(), "transcript.jsonl")
+	if err := os.WriteFile(transcriptPath, []byte(strings.Join(testBubbles, "\n")), 0o644); err != nil {
+		t.Fatalf("failed to write transcript: %v", err)
+	}
+	files, pos, err = ag.ExtractModifiedFilesFromOffset(transcriptPath, 1)
+	if err != nil {
+		t.Fatalf("ExtractModifiedFilesFromOffset() error = %v", err)
+	}
+	if len(files) != 1 || pos != len(testBubbles) {
+		t.Errorf("ExtractModifiedFilesFromOffset(file, 1) = (%v, %d)", files, pos)
+	}
+}
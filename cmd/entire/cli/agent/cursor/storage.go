@@ -0,0 +1,291 @@
+package cursor
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	// Pure-Go SQLite driver (registered as "sqlite"); keeps builds CGO-free.
+	_ "modernc.org/sqlite"
+)
+
+// Table names in Cursor's state databases
+const (
+	itemTable = "ItemTable"
+	kvTable   = "cursorDiskKV"
+)
+
+// errNotFound is returned when a key does not exist in a state database.
+var errNotFound = errors.New("not found")
+
+// UserDir returns Cursor's user data directory:
+//
+//	macOS:   ~/Library/Application Support/Cursor/User
+//	Linux:   ~/.config/Cursor/User
+//	Windows: %APPDATA%\Cursor\User
+func UserDir() (string, error) {
+	if override := os.Getenv(userDirOverrideEnv); override != "" {
+		return override, nil
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return filepath.Join(configDir, "Cursor", "User"), nil
+}
+
+// GlobalDBPath returns the path to Cursor's global state database.
+func GlobalDBPath() (string, error) {
+	userDir, err := UserDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(userDir, globalStorageDir, stateDBFileName), nil
+}
+
+// SessionRef builds the session reference for a composer: "<global-db-path>#<composer-id>".
+// Cursor has no per-session transcript file, so this reference stands in for one.
+func SessionRef(dbPath, composerID string) string {
+	return dbPath + sessionRefSeparator + composerID
+}
+
+// ParseSessionRef splits a session reference built by SessionRef.
+// Returns ok=false if ref is not a Cursor session reference (e.g. a transcript file path).
+func ParseSessionRef(ref string) (dbPath, composerID string, ok bool) {
+	idx := strings.LastIndex(ref, sessionRefSeparator)
+	if idx <= 0 || idx == len(ref)-1 {
+		return "", "", false
+	}
+	dbPath, composerID = ref[:idx], ref[idx+1:]
+	if filepath.Base(dbPath) != stateDBFileName {
+		return "", "", false
+	}
+	return dbPath, composerID, true
+}
+
+// openDB opens a Cursor state database. Cursor keeps the database open while
+// running, so a busy timeout is set to wait out its write locks.
+func openDB(path string, readOnly bool) (*sql.DB, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("cursor state database not found: %w", err)
+	}
+
+	p := filepath.ToSlash(path)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p // Windows drive paths: file:///C:/...
+	}
+	query := url.Values{}
+	if readOnly {
+		query.Set("mode", "ro")
+	}
+	query.Add("_pragma", "busy_timeout("+strconv.Itoa(defaultBusyTimeoutMs)+")")
+	dsn := (&url.URL{Scheme: "file", Path: p, RawQuery: query.Encode()}).String()
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cursor state database: %w", err)
+	}
+	return db, nil
+}
+
+// readValue reads a single value from a key/value table.
+// Returns errNotFound if the key does not exist.
+func readValue(db *sql.DB, table, key string) ([]byte, error) {
+	var value []byte
+	//nolint:gosec // table is one of the package constants, not user input
+	err := db.QueryRow("SELECT value FROM "+table+" WHERE key = ?", key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	return value, nil
+}
+
+// FindWorkspaceDB returns the workspace state database for a repository.
+// Cursor creates one workspaceStorage/<hash>/ directory per opened folder and
+// records the folder URI in workspace.json. Returns "" if the repository has
+// never been opened in Cursor.
+func FindWorkspaceDB(userDir, repoPath string) (string, error) {
+	storageDir := filepath.Join(userDir, workspaceStorageDir)
+	entries, err := os.ReadDir(storageDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read workspace storage: %w", err)
+	}
+
+	want := canonicalPath(repoPath)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(storageDir, entry.Name())
+		data, err := os.ReadFile(filepath.Join(dir, workspaceFileName)) //nolint:gosec // Path built from Cursor's storage directory
+		if err != nil {
+			continue
+		}
+		var info workspaceInfo
+		if err := json.Unmarshal(data, &info); err != nil || info.Folder == "" {
+			continue
+		}
+		folder, err := url.Parse(info.Folder)
+		if err != nil || folder.Scheme != "file" {
+			continue // Remote workspaces (vscode-remote://...) can't match a local repo
+		}
+		if canonicalPath(filepath.FromSlash(folder.Path)) == want {
+			dbPath := filepath.Join(dir, stateDBFileName)
+			if _, err := os.Stat(dbPath); err == nil {
+				return dbPath, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// canonicalPath resolves symlinks (e.g. /var -> /private/var on macOS) so
+// workspace folders and repository roots compare equal.
+func canonicalPath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	return filepath.Clean(path)
+}
+
+// readComposerList reads the composers opened in a workspace.
+func readComposerList(workspaceDB string) (*composerList, error) {
+	db, err := openDB(workspaceDB, true)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	data, err := readValue(db, itemTable, composerListKey)
+	if errors.Is(err, errNotFound) {
+		return &composerList{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var list composerList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse composer list: %w", err)
+	}
+	return &list, nil
+}
+
+// readComposer reads a composer and its bubbles, in conversation order.
+func readComposer(globalDB, composerID string) (*composerData, []Bubble, error) {
+	db, err := openDB(globalDB, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer db.Close()
+
+	data, err := readValue(db, kvTable, composerDataPrefix+composerID)
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			return nil, nil, fmt.Errorf("composer %s not found", composerID)
+		}
+		return nil, nil, err
+	}
+
+	var composer composerData
+	if err := json.Unmarshal(data, &composer); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse composer %s: %w", composerID, err)
+	}
+
+	// Older format: bubbles inlined in the composer
+	if len(composer.Conversation) > 0 {
+		bubbles := make([]Bubble, 0, len(composer.Conversation))
+		for _, raw := range composer.Conversation {
+			b, err := parseBubble(raw)
+			if err != nil {
+				return nil, nil, err
+			}
+			bubbles = append(bubbles, b)
+		}
+		return &composer, bubbles, nil
+	}
+
+	bubbles := make([]Bubble, 0, len(composer.FullConversationHeadersOnly))
+	for _, header := range composer.FullConversationHeadersOnly {
+		raw, err := readValue(db, kvTable, bubbleKey(composerID, header.BubbleID))
+		if errors.Is(err, errNotFound) {
+			continue // Bubble not persisted yet (still streaming)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		b, err := parseBubble(raw)
+		if err != nil {
+			return nil, nil, err
+		}
+		bubbles = append(bubbles, b)
+	}
+	return &composer, bubbles, nil
+}
+
+// writeComposer stores a composer and its bubbles in the global database,
+// replacing any existing rows for the same composer.
+// Existing composer fields that Entire does not model are preserved.
+func writeComposer(globalDB, composerID string, bubbles []Bubble) error {
+	db, err := openDB(globalDB, false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	composer := make(map[string]interface{})
+	if existing, err := readValue(db, kvTable, composerDataPrefix+composerID); err == nil {
+		if err := json.Unmarshal(existing, &composer); err != nil {
+			return fmt.Errorf("failed to parse existing composer %s: %w", composerID, err)
+		}
+	}
+
+	headers := make([]bubbleHeader, 0, len(bubbles))
+	for _, b := range bubbles {
+		headers = append(headers, bubbleHeader{BubbleID: b.BubbleID, Type: b.Type})
+	}
+	composer["composerId"] = composerID
+	composer["fullConversationHeadersOnly"] = headers
+	delete(composer, "conversation")
+
+	composerJSON, err := json.Marshal(composer)
+	if err != nil {
+		return fmt.Errorf("failed to marshal composer: %w", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // Rollback after commit is a no-op
+
+	upsert := "INSERT OR REPLACE INTO " + kvTable + " (key, value) VALUES (?, ?)"
+	if _, err := tx.Exec(upsert, composerDataPrefix+composerID, string(composerJSON)); err != nil {
+		return fmt.Errorf("failed to write composer: %w", err)
+	}
+	for _, b := range bubbles {
+		if _, err := tx.Exec(upsert, bubbleKey(composerID, b.BubbleID), string(b.Raw)); err != nil {
+			return fmt.Errorf("failed to write bubble %s: %w", b.BubbleID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit composer: %w", err)
+	}
+	return nil
+}
+
+func bubbleKey(composerID, bubbleID string) string {
+	return bubbleKeyPrefix + composerID + ":" + bubbleID
+}
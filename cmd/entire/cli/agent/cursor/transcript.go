@@ -0,0 +1,180 @@
+package cursor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+// Transcript format: Cursor has no transcript file, so Entire stores a composer
+// as JSONL with one bubble (in Cursor's own JSON) per line. The transcript
+// position used by TranscriptAnalyzer is the bubble index, which is the same
+// whether bubbles are read from the state database or from a stored transcript.
+
+// scannerBufferSize is the max line size when reading JSONL transcripts (10MB).
+const scannerBufferSize = 10 * 1024 * 1024
+
+// parseBubble parses a bubble, keeping its original JSON.
+func parseBubble(raw []byte) (Bubble, error) {
+	var b Bubble
+	if err := json.Unmarshal(raw, &b); err != nil {
+		return Bubble{}, fmt.Errorf("failed to parse bubble: %w", err)
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, raw); err != nil {
+		return Bubble{}, fmt.Errorf("failed to compact bubble: %w", err)
+	}
+	b.Raw = compact.Bytes()
+	return b, nil
+}
+
+// ParseTranscript parses a JSONL transcript into bubbles.
+// Blank lines are skipped.
+func ParseTranscript(data []byte) ([]Bubble, error) {
+	var bubbles []Bubble
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), scannerBufferSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		b, err := parseBubble(line)
+		if err != nil {
+			return nil, err
+		}
+		bubbles = append(bubbles, b)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+	return bubbles, nil
+}
+
+// FormatTranscript serializes bubbles as a JSONL transcript.
+func FormatTranscript(bubbles []Bubble) []byte {
+	var buf bytes.Buffer
+	for _, b := range bubbles {
+		buf.Write(b.Raw)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// loadBubbles reads bubbles from a session reference (state database) or from
+// a stored JSONL transcript. A missing transcript file yields no bubbles.
+func loadBubbles(path string) ([]Bubble, error) {
+	if dbPath, composerID, ok := ParseSessionRef(path); ok {
+		_, bubbles, err := readComposer(dbPath, composerID)
+		return bubbles, err
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // Reading from controlled transcript path
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+	return ParseTranscript(data)
+}
+
+// toolArgs decodes a tool call's arguments. Cursor stores them as a JSON string
+// in rawArgs, with params as a fallback for some tools.
+func (t *toolFormerData) toolArgs() map[string]interface{} {
+	for _, encoded := range []string{t.RawArgs, t.Params} {
+		if encoded == "" {
+			continue
+		}
+		var args map[string]interface{}
+		if err := json.Unmarshal([]byte(encoded), &args); err == nil {
+			return args
+		}
+	}
+	return nil
+}
+
+// filesFromTool returns the files a tool call modifies, or nil for read-only tools.
+func filesFromTool(t *toolFormerData) []string {
+	if t == nil || !slices.Contains(FileModificationTools, t.Name) {
+		return nil
+	}
+	args := t.toolArgs()
+	for _, key := range []string{"target_file", "file_path", "path", "relativeWorkspacePath"} {
+		if file, ok := args[key].(string); ok && file != "" {
+			return []string{file}
+		}
+	}
+	return nil
+}
+
+// ExtractModifiedFiles returns files modified by tool calls, in first-seen order.
+func ExtractModifiedFiles(bubbles []Bubble) []string {
+	fileSet := make(map[string]bool)
+	var files []string
+	for _, b := range bubbles {
+		if b.Type != BubbleTypeAssistant {
+			continue
+		}
+		for _, file := range filesFromTool(b.ToolFormerData) {
+			if !fileSet[file] {
+				fileSet[file] = true
+				files = append(files, file)
+			}
+		}
+	}
+	return files
+}
+
+// ToSessionEntries normalizes bubbles into agent session entries, one per
+// bubble, so entry indexes match transcript positions.
+// Assistant bubbles carrying a tool call become tool entries.
+func ToSessionEntries(bubbles []Bubble) []agent.SessionEntry {
+	entries := make([]agent.SessionEntry, 0, len(bubbles))
+	for i, b := range bubbles {
+		entry := agent.SessionEntry{
+			UUID:     b.BubbleID,
+			Content:  b.Text,
+			Position: i,
+		}
+		if ts, err := time.Parse(time.RFC3339, b.CreatedAt); err == nil {
+			entry.Timestamp = ts
+		}
+		if b.ModelInfo != nil {
+			entry.Model = b.ModelInfo.ModelName
+		}
+		if tc := b.TokenCount; tc != nil && tc.InputTokens+tc.OutputTokens > 0 {
+			entry.TokenUsage = &agent.TokenUsage{
+				InputTokens:  tc.InputTokens,
+				OutputTokens: tc.OutputTokens,
+				APICallCount: 1,
+			}
+		}
+
+		switch {
+		case b.Type == BubbleTypeUser:
+			entry.Type = agent.EntryUser
+		case b.ToolFormerData != nil:
+			entry.Type = agent.EntryTool
+			entry.ToolName = b.ToolFormerData.Name
+			entry.ToolUseID = b.ToolFormerData.ToolCallID
+			entry.ToolInput = b.ToolFormerData.toolArgs()
+			if b.ToolFormerData.Result != "" {
+				entry.ToolOutput = b.ToolFormerData.Result
+			}
+			entry.FilesAffected = filesFromTool(b.ToolFormerData)
+		case b.Type == BubbleTypeAssistant:
+			entry.Type = agent.EntryAssistant
+		default:
+			entry.Type = agent.EntrySystem
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
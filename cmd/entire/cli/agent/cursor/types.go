@@ -0,0 +1,122 @@
+package cursor
+
+import "encoding/json"
+
+// Cursor keeps its state in VS Code-style SQLite databases ("state.vscdb"):
+//   - <user-dir>/globalStorage/state.vscdb holds composer (chat) data and bubbles
+//     in the cursorDiskKV table, keyed "composerData:<composerId>" and
+//     "bubbleId:<composerId>:<bubbleId>".
+//   - <user-dir>/workspaceStorage/<hash>/state.vscdb holds per-workspace state in
+//     ItemTable, including the list of composers opened in that workspace under
+//     "composer.composerData". The workspace folder is recorded in workspace.json.
+
+// Storage file and key names
+const (
+	stateDBFileName      = "state.vscdb"
+	workspaceFileName    = "workspace.json"
+	globalStorageDir     = "globalStorage"
+	workspaceStorageDir  = "workspaceStorage"
+	composerListKey      = "composer.composerData"
+	composerDataPrefix   = "composerData:"
+	bubbleKeyPrefix      = "bubbleId:"
+	sessionRefSeparator  = "#"
+	userDirOverrideEnv   = "ENTIRE_TEST_CURSOR_USER_DIR"
+	defaultBusyTimeoutMs = 5000
+)
+
+// Bubble types used by Cursor for chat messages
+const (
+	BubbleTypeUser      = 1
+	BubbleTypeAssistant = 2
+)
+
+// Tool names used by Cursor's agent that modify files
+const (
+	ToolEditFile      = "edit_file"
+	ToolSearchReplace = "search_replace"
+	ToolWrite         = "write"
+	ToolMultiEdit     = "multi_edit"
+	ToolDeleteFile    = "delete_file"
+)
+
+// FileModificationTools lists tools that create, modify or delete files in Cursor
+var FileModificationTools = []string{
+	ToolEditFile,
+	ToolSearchReplace,
+	ToolWrite,
+	ToolMultiEdit,
+	ToolDeleteFile,
+}
+
+// workspaceInfo is the workspace.json file in a workspaceStorage directory
+type workspaceInfo struct {
+	Folder string `json:"folder"`
+}
+
+// composerList is the "composer.composerData" value in a workspace database
+type composerList struct {
+	AllComposers       []composerHead `json:"allComposers"`
+	SelectedComposerID string         `json:"selectedComposerId,omitempty"`
+}
+
+// composerHead is a composer entry in a workspace's composer list
+type composerHead struct {
+	ComposerID    string `json:"composerId"`
+	Name          string `json:"name,omitempty"`
+	CreatedAt     int64  `json:"createdAt,omitempty"`
+	LastUpdatedAt int64  `json:"lastUpdatedAt,omitempty"`
+}
+
+// composerData is the "composerData:<id>" value in the global database.
+// Newer Cursor versions store bubbles as separate rows and list them in
+// FullConversationHeadersOnly; older versions inline them in Conversation.
+type composerData struct {
+	ComposerID                  string            `json:"composerId"`
+	Name                        string            `json:"name,omitempty"`
+	CreatedAt                   int64             `json:"createdAt,omitempty"`
+	LastUpdatedAt               int64             `json:"lastUpdatedAt,omitempty"`
+	FullConversationHeadersOnly []bubbleHeader    `json:"fullConversationHeadersOnly,omitempty"`
+	Conversation                []json.RawMessage `json:"conversation,omitempty"`
+}
+
+// bubbleHeader references a bubble stored in its own row
+type bubbleHeader struct {
+	BubbleID string `json:"bubbleId"`
+	Type     int    `json:"type"`
+}
+
+// Bubble is a single chat message in a Cursor composer.
+// Raw holds the bubble's original JSON so it can be stored and restored unchanged.
+type Bubble struct {
+	BubbleID       string          `json:"bubbleId"`
+	Type           int             `json:"type"`
+	Text           string          `json:"text,omitempty"`
+	CreatedAt      string          `json:"createdAt,omitempty"`
+	ToolFormerData *toolFormerData `json:"toolFormerData,omitempty"`
+	TokenCount     *tokenCount     `json:"tokenCount,omitempty"`
+	ModelInfo      *modelInfo      `json:"modelInfo,omitempty"`
+
+	Raw json.RawMessage `json:"-"`
+}
+
+// tokenCount is the token usage Cursor records on assistant bubbles.
+type tokenCount struct {
+	InputTokens  int `json:"inputTokens"`
+	OutputTokens int `json:"outputTokens"`
+}
+
+// modelInfo names the model that produced an assistant bubble.
+type modelInfo struct {
+	ModelName string `json:"modelName"`
+}
+
+// toolFormerData describes a tool call made by the assistant.
+// RawArgs and Params are JSON-encoded strings.
+type toolFormerData struct {
+	Name       string `json:"name"`
+	ToolCallID string `json:"toolCallId,omitempty"`
+	RawArgs    string `json:"rawArgs,omitempty"`
+	Params     string `json:"params,omitempty"`
+	Status     string `json:"status,omitempty"`
+	Result     string `json:"result,omitempty"`
+}
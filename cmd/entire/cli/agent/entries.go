@@ -0,0 +1,91 @@
+package agent
+
+import "fmt"
+
+// legacyTranscriptTypes are tried, in order, for transcripts without a known
+// agent type (checkpoints written before the agent was recorded).
+var legacyTranscriptTypes = []AgentType{AgentTypeGemini, AgentTypeClaudeCode}
+
+// ParseTranscriptEntries normalizes transcript data written by an agent of the
+// given type into session entries, using the agent's TranscriptParser.
+// For an empty or unknown agent type, the transcript is parsed as Gemini JSON
+// if possible and as Claude Code JSONL otherwise.
+func ParseTranscriptEntries(agentType AgentType, data []byte) ([]SessionEntry, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	if agentType == "" || agentType == AgentTypeUnknown {
+		for _, t := range legacyTranscriptTypes {
+			if entries, err := ParseTranscriptEntries(t, data); err == nil && len(entries) > 0 {
+				return entries, nil
+			}
+		}
+		return nil, nil
+	}
+
+	ag, err := GetByAgentType(agentType)
+	if err != nil {
+		return nil, err
+	}
+	parser, ok := ag.(TranscriptParser)
+	if !ok {
+		return nil, fmt.Errorf("agent %s can't parse transcripts", ag.Name())
+	}
+	entries, err := parser.ParseEntries(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s transcript: %w", agentType, err)
+	}
+	return entries, nil
+}
+
+// EntriesFrom returns the entries at or after a transcript position,
+// e.g. the part of a session transcript that belongs to one checkpoint.
+func EntriesFrom(entries []SessionEntry, position int) []SessionEntry {
+	if position <= 0 {
+		return entries
+	}
+	for i, entry := range entries {
+		if entry.Position >= position {
+			return entries[i:]
+		}
+	}
+	return nil
+}
+
+// TranscriptLength returns the transcript position following the last entry,
+// i.e. the number of transcript items (lines, messages) the entries cover.
+func TranscriptLength(entries []SessionEntry) int {
+	if len(entries) == 0 {
+		return 0
+	}
+	return entries[len(entries)-1].Position + 1
+}
+
+// SumTokenUsage adds up the token usage recorded on entries.
+func SumTokenUsage(entries []SessionEntry) *TokenUsage {
+	usage := &TokenUsage{}
+	for _, entry := range entries {
+		u := entry.TokenUsage
+		if u == nil {
+			continue
+		}
+		usage.InputTokens += u.InputTokens
+		usage.CacheCreationTokens += u.CacheCreationTokens
+		usage.CacheReadTokens += u.CacheReadTokens
+		usage.OutputTokens += u.OutputTokens
+		usage.APICallCount += u.APICallCount
+	}
+	return usage
+}
+
+// UserPrompts returns the content of the user entries, skipping empty ones.
+func UserPrompts(entries []SessionEntry) []string {
+	var prompts []string
+	for _, entry := range entries {
+		if entry.Type == EntryUser && entry.Content != "" {
+			prompts = append(prompts, entry.Content)
+		}
+	}
+	return prompts
+}
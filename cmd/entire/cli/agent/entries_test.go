@@ -0,0 +1,86 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEntriesFrom(t *testing.T) {
+	entries := []SessionEntry{
+		{UUID: "a", Position: 0},
+		{UUID: "b", Position: 2},
+		{UUID: "c", Position: 2},
+		{UUID: "d", Position: 5},
+	}
+
+	tests := []struct {
+		position int
+		want     string
+	}{
+		{0, "a,b,c,d"},
+		{1, "b,c,d"},
+		{2, "b,c,d"},
+		{3, "d"},
+		{6, ""},
+	}
+	for _, tt := range tests {
+		var uuids []string
+		for _, e := range EntriesFrom(entries, tt.position) {
+			uuids = append(uuids, e.UUID)
+		}
+		if got := strings.Join(uuids, ","); got != tt.want {
+			t.Errorf("EntriesFrom(%d) = %q, want %q", tt.position, got, tt.want)
+		}
+	}
+}
+
+func TestTranscriptLength(t *testing.T) {
+	if got := TranscriptLength(nil); got != 0 {
+		t.Errorf("TranscriptLength(nil) = %d, want 0", got)
+	}
+	entries := []SessionEntry{{Position: 0}, {Position: 3}, {Position: 3}}
+	if got := TranscriptLength(entries); got != 4 {
+		t.Errorf("TranscriptLength() = %d, want 4", got)
+	}
+}
+
+func TestSumTokenUsage(t *testing.T) {
+	entries := []SessionEntry{
+		{Type: EntryUser},
+		{Type: EntryAssistant, TokenUsage: &TokenUsage{InputTokens: 10, CacheReadTokens: 5, OutputTokens: 20, APICallCount: 1}},
+		{Type: EntryTool},
+		{Type: EntryAssistant, TokenUsage: &TokenUsage{InputTokens: 7, CacheCreationTokens: 3, OutputTokens: 2, APICallCount: 1}},
+	}
+
+	usage := SumTokenUsage(entries)
+	if usage.InputTokens != 17 || usage.CacheReadTokens != 5 || usage.CacheCreationTokens != 3 ||
+		usage.OutputTokens != 22 || usage.APICallCount != 2 {
+		t.Errorf("SumTokenUsage() = %+v", usage)
+	}
+	if empty := SumTokenUsage(nil); empty.APICallCount != 0 {
+		t.Errorf("SumTokenUsage(nil) = %+v, want zero usage", empty)
+	}
+}
+
+func TestUserPrompts(t *testing.T) {
+	entries := []SessionEntry{
+		{Type: EntryUser, Content: "first"},
+		{Type: EntryAssistant, Content: "answer"},
+		{Type: EntryUser, Content: ""},
+		{Type: EntryToolResult, ToolOutput: "ok"},
+		{Type: EntryUser, Content: "second"},
+	}
+	if got := strings.Join(UserPrompts(entries), "|"); got != "first|second" {
+		t.Errorf("UserPrompts() = %q, want %q", got, "first|second")
+	}
+}
+
+func TestParseTranscriptEntries_UnregisteredType(t *testing.T) {
+	if _, err := ParseTranscriptEntries("No Such Agent", []byte("data")); err == nil {
+		t.Error("expected error for unregistered agent type")
+	}
+	entries, err := ParseTranscriptEntries(AgentTypeClaudeCode, nil)
+	if err != nil || entries != nil {
+		t.Errorf("ParseTranscriptEntries(empty) = %v, %v; want nil, nil", entries, err)
+	}
+}
@@ -0,0 +1,518 @@
+// Package external implements the Agent interface for agents provided by
+// plugin executables named entire-agent-<name> on PATH.
+//
+// A plugin is run once per call, with the method name as its argument, a
+// Request on stdin and a Response on stdout (see protocol.go). Go programs can
+// implement a plugin by passing an agent.Agent to Serve.
+package external
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/sessionid"
+)
+
+// ExecutablePrefix is the file name prefix of agent plugin executables.
+const ExecutablePrefix = "entire-agent-"
+
+// callTimeout bounds a single plugin call, so a hung plugin can't block the agent's hooks.
+const callTimeout = 30 * time.Second
+
+// metadataTimeout bounds the calls that only describe a plugin (info, detect_presence).
+// They run while resolving agents, often for plugins of agents that aren't used.
+const metadataTimeout = 5 * time.Second
+
+// pluginNameRegex matches valid plugin agent names
+var pluginNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// Ensure ExternalAgent implements the interfaces it mirrors
+var (
+	_ agent.HookSupport        = (*ExternalAgent)(nil)
+	_ agent.HookHandler        = (*ExternalAgent)(nil)
+	_ agent.TranscriptAnalyzer = (*ExternalAgent)(nil)
+	_ agent.TranscriptChunker  = (*ExternalAgent)(nil)
+)
+
+//nolint:gochecknoinits // Agent self-registration is the intended pattern
+func init() {
+	agent.RegisterDiscoverer(func() map[agent.AgentName]agent.Factory {
+		factories := make(map[agent.AgentName]agent.Factory)
+		for name, path := range Discover(os.Getenv("PATH")) {
+			factories[name] = func() agent.Agent { return New(name, path) }
+		}
+		return factories
+	})
+}
+
+// Discover finds agent plugins in the directories of a PATH list.
+// As with commands, the first executable with a given name wins.
+func Discover(pathList string) map[agent.AgentName]string {
+	found := make(map[agent.AgentName]string)
+	for _, dir := range filepath.SplitList(pathList) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := pluginName(entry.Name())
+			if !ok {
+				continue
+			}
+			if _, exists := found[name]; exists {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if isExecutable(path) {
+				found[name] = path
+			}
+		}
+	}
+	return found
+}
+
+// pluginName returns the agent name of a plugin executable's file name.
+func pluginName(fileName string) (agent.AgentName, bool) {
+	if !strings.HasPrefix(fileName, ExecutablePrefix) {
+		return "", false
+	}
+	name := strings.TrimPrefix(fileName, ExecutablePrefix)
+	if runtime.GOOS == "windows" {
+		if !strings.EqualFold(filepath.Ext(name), ".exe") {
+			return "", false
+		}
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	if !pluginNameRegex.MatchString(name) {
+		return "", false
+	}
+	return agent.AgentName(name), true
+}
+
+// isExecutable reports whether path is an executable file.
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode().Perm()&0o111 != 0
+}
+
+// infoResult is a plugin's answer to the info method, successful or not.
+type infoResult struct {
+	info *Info
+	err  error
+}
+
+var (
+	infoMu    sync.Mutex
+	infoCache = make(map[string]infoResult)
+)
+
+// ExternalAgent adapts an agent plugin executable to the Agent interfaces.
+//
+//nolint:revive // ExternalAgent is clearer than Agent in this context
+type ExternalAgent struct {
+	name agent.AgentName
+	path string
+}
+
+// New returns the adapter for the plugin at path, registered as name.
+func New(name agent.AgentName, path string) *ExternalAgent {
+	return &ExternalAgent{name: name, path: path}
+}
+
+// Path returns the plugin executable's path.
+func (e *ExternalAgent) Path() string {
+	return e.path
+}
+
+// call runs a plugin method, decoding its result into result (if not nil).
+func (e *ExternalAgent) call(method string, params, result any) error {
+	return e.callWithTimeout(callTimeout, method, params, result)
+}
+
+// callWithTimeout runs a plugin method like call, bounded by timeout.
+func (e *ExternalAgent) callWithTimeout(timeout time.Duration, method string, params, result any) error {
+	req := Request{ProtocolVersion: ProtocolVersion, Method: method}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s params: %w", method, err)
+		}
+		req.Params = data
+	}
+	input, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s request: %w", method, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, e.path, method) //nolint:gosec // Path is a discovered plugin executable
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+
+	var resp Response
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		if runErr != nil {
+			return fmt.Errorf("agent plugin %s %s failed: %w: %s", e.name, method, runErr, strings.TrimSpace(stderr.String()))
+		}
+		return fmt.Errorf("agent plugin %s %s returned an invalid response: %w", e.name, method, err)
+	}
+	if resp.Error != nil {
+		if resp.Error.Code == ErrorCodeUnsupported {
+			return fmt.Errorf("%s %s: %w", e.name, method, ErrUnsupported)
+		}
+		return fmt.Errorf("agent plugin %s %s: %s", e.name, method, resp.Error.Message)
+	}
+	if runErr != nil {
+		return fmt.Errorf("agent plugin %s %s failed: %w: %s", e.name, method, runErr, strings.TrimSpace(stderr.String()))
+	}
+	if result != nil {
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("agent plugin %s %s returned an invalid result: %w", e.name, method, err)
+		}
+	}
+	return nil
+}
+
+// Info returns the plugin's description, asking it once per process.
+// Failures are remembered too, so a broken plugin isn't run again.
+// Fails if the plugin speaks another protocol version.
+func (e *ExternalAgent) Info() (*Info, error) {
+	infoMu.Lock()
+	defer infoMu.Unlock()
+	if cached, ok := infoCache[e.path]; ok {
+		return cached.info, cached.err
+	}
+
+	info, err := e.queryInfo()
+	infoCache[e.path] = infoResult{info: info, err: err}
+	return info, err
+}
+
+// queryInfo runs the plugin's info method.
+func (e *ExternalAgent) queryInfo() (*Info, error) {
+	var info Info
+	if err := e.callWithTimeout(metadataTimeout, MethodInfo, nil, &info); err != nil {
+		return nil, err
+	}
+	if info.ProtocolVersion != ProtocolVersion {
+		return nil, fmt.Errorf("agent plugin %s speaks protocol version %d, entire supports version %d",
+			e.name, info.ProtocolVersion, ProtocolVersion)
+	}
+	return &info, nil
+}
+
+// HookType returns the lifecycle event a hook verb reports.
+func (e *ExternalAgent) HookType(hookName string) (agent.HookType, bool) {
+	info, err := e.Info()
+	if err != nil {
+		return "", false
+	}
+	for _, hook := range info.Hooks {
+		if hook.Name == hookName {
+			return hook.Type, true
+		}
+	}
+	return "", false
+}
+
+// Name returns the agent registry key, from the executable name.
+func (e *ExternalAgent) Name() agent.AgentName {
+	return e.name
+}
+
+// Type returns the agent type identifier reported by the plugin, or the
+// agent name if the plugin can't be queried.
+func (e *ExternalAgent) Type() agent.AgentType {
+	if info, err := e.Info(); err == nil && info.Type != "" {
+		return agent.AgentType(info.Type)
+	}
+	return agent.AgentType(e.name)
+}
+
+// Description returns a human-readable description.
+func (e *ExternalAgent) Description() string {
+	if info, err := e.Info(); err == nil && info.Description != "" {
+		return info.Description
+	}
+	return string(e.name) + " (agent plugin)"
+}
+
+// DetectPresence asks the plugin whether its agent is configured in the repository.
+func (e *ExternalAgent) DetectPresence() (bool, error) {
+	var result detectPresenceResult
+	if err := e.callWithTimeout(metadataTimeout, MethodDetectPresence, nil, &result); err != nil {
+		return false, err
+	}
+	return result.Present, nil
+}
+
+// GetHookConfigPath returns the path to the agent's hook config file.
+func (e *ExternalAgent) GetHookConfigPath() string {
+	var result pathResult
+	if err := e.call(MethodGetHookConfigPath, nil, &result); err != nil {
+		return ""
+	}
+	return result.Path
+}
+
+// SupportsHooks returns true if the plugin declares hooks.
+func (e *ExternalAgent) SupportsHooks() bool {
+	info, err := e.Info()
+	return err == nil && len(info.Hooks) > 0
+}
+
+// ParseHookInput passes the hook's input to the plugin to parse.
+func (e *ExternalAgent) ParseHookInput(hookType agent.HookType, reader io.Reader) (*agent.HookInput, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, errors.New("empty input")
+	}
+
+	var result HookInputMessage
+	if err := e.call(MethodParseHookInput, parseHookInputParams{HookType: hookType, Input: string(data)}, &result); err != nil {
+		return nil, err
+	}
+	input := result.toHookInput()
+	input.HookType = hookType
+	if input.Timestamp.IsZero() {
+		input.Timestamp = time.Now()
+	}
+	return input, nil
+}
+
+// GetSessionID extracts the session ID from hook input.
+func (e *ExternalAgent) GetSessionID(input *agent.HookInput) string {
+	return input.SessionID
+}
+
+// TransformSessionID converts an agent session ID to an Entire session ID.
+// This is an identity function - the agent session ID IS the Entire session ID.
+func (e *ExternalAgent) TransformSessionID(agentSessionID string) string {
+	return agentSessionID
+}
+
+// ExtractAgentSessionID extracts the agent session ID from an Entire session ID.
+// For backwards compatibility with legacy date-prefixed IDs, it strips the prefix if present.
+func (e *ExternalAgent) ExtractAgentSessionID(entireSessionID string) string {
+	return sessionid.ModelSessionID(entireSessionID)
+}
+
+// GetSessionDir returns where the agent stores session data for a repository.
+func (e *ExternalAgent) GetSessionDir(repoPath string) (string, error) {
+	var result pathResult
+	if err := e.call(MethodGetSessionDir, getSessionDirParams{RepoPath: repoPath}, &result); err != nil {
+		return "", err
+	}
+	return result.Path, nil
+}
+
+// ReadSession reads a session through the plugin.
+// Entries must be indexed by transcript position, as with agents driven by
+// `entire watch`.
+func (e *ExternalAgent) ReadSession(input *agent.HookInput) (*agent.AgentSession, error) {
+	var result SessionMessage
+	if err := e.call(MethodReadSession, toHookInputMessage(input), &result); err != nil {
+		return nil, err
+	}
+	session := result.toSession()
+	if session.AgentName == "" {
+		session.AgentName = e.name
+	}
+	if session.SessionID == "" {
+		session.SessionID = input.SessionID
+	}
+	return session, nil
+}
+
+// WriteSession writes a session through the plugin for resumption.
+func (e *ExternalAgent) WriteSession(session *agent.AgentSession) error {
+	if session == nil {
+		return errors.New("session is nil")
+	}
+	if session.AgentName != "" && session.AgentName != e.name {
+		return fmt.Errorf("session belongs to agent %q, not %q", session.AgentName, e.name)
+	}
+	return e.call(MethodWriteSession, toSessionMessage(session), nil)
+}
+
+// FormatResumeCommand returns the command to resume a session.
+func (e *ExternalAgent) FormatResumeCommand(sessionID string) string {
+	var result formatResumeCommandResult
+	if err := e.call(MethodFormatResumeCommand, formatResumeCommandParams{SessionID: sessionID}, &result); err != nil {
+		return ""
+	}
+	return result.Command
+}
+
+// HookSupport interface implementation
+
+// hookCommand returns the command the plugin's hooks run, with the verb to be appended.
+func (e *ExternalAgent) hookCommand(localDev bool) (string, error) {
+	if !localDev {
+		return "entire hooks " + string(e.name), nil
+	}
+	repoRoot, err := paths.RepoRoot()
+	if err != nil {
+		return "", fmt.Errorf("failed to get repository root: %w", err)
+	}
+	return "go run " + filepath.Join(repoRoot, "cmd", "entire", "main.go") + " hooks " + string(e.name), nil
+}
+
+// InstallHooks asks the plugin to install its hooks.
+// Returns the number of hooks installed.
+func (e *ExternalAgent) InstallHooks(localDev bool, force bool) (int, error) {
+	if !e.SupportsHooks() {
+		return 0, fmt.Errorf("install hooks for %s: %w", e.name, ErrUnsupported)
+	}
+	command, err := e.hookCommand(localDev)
+	if err != nil {
+		return 0, err
+	}
+	var result installHooksResult
+	if err := e.call(MethodInstallHooks, installHooksParams{LocalDev: localDev, Force: force, HookCommand: command}, &result); err != nil {
+		return 0, err
+	}
+	return result.Count, nil
+}
+
+// UninstallHooks asks the plugin to remove its hooks.
+func (e *ExternalAgent) UninstallHooks() error {
+	if !e.SupportsHooks() {
+		return nil
+	}
+	return e.call(MethodUninstallHooks, nil, nil)
+}
+
+// AreHooksInstalled asks the plugin whether its hooks are installed.
+func (e *ExternalAgent) AreHooksInstalled() bool {
+	if !e.SupportsHooks() {
+		return false
+	}
+	var result areHooksInstalledResult
+	if err := e.call(MethodAreHooksInstalled, nil, &result); err != nil {
+		return false
+	}
+	return result.Installed
+}
+
+// GetSupportedHooks returns the hook types the plugin's hooks report.
+func (e *ExternalAgent) GetSupportedHooks() []agent.HookType {
+	info, err := e.Info()
+	if err != nil {
+		return nil
+	}
+	var types []agent.HookType
+	for _, hook := range info.Hooks {
+		if !slices.Contains(types, hook.Type) {
+			types = append(types, hook.Type)
+		}
+	}
+	return types
+}
+
+// GetHookNames returns the hook verbs the plugin declares.
+// These become subcommands: entire hooks <agent> <verb>
+func (e *ExternalAgent) GetHookNames() []string {
+	info, err := e.Info()
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(info.Hooks))
+	for _, hook := range info.Hooks {
+		names = append(names, hook.Name)
+	}
+	return names
+}
+
+// TranscriptAnalyzer interface implementation
+
+// GetTranscriptPosition asks the plugin for the current position of a transcript.
+func (e *ExternalAgent) GetTranscriptPosition(path string) (int, error) {
+	var result transcriptPositionResult
+	if err := e.call(MethodGetTranscriptPos, transcriptPositionParams{Path: path}, &result); err != nil {
+		return 0, err
+	}
+	return result.Position, nil
+}
+
+// ExtractModifiedFilesFromOffset asks the plugin for the files modified since a position.
+func (e *ExternalAgent) ExtractModifiedFilesFromOffset(path string, startOffset int) (files []string, currentPosition int, err error) {
+	var result extractModifiedFilesResult
+	if err := e.call(MethodExtractModifiedFiles, extractModifiedFilesParams{Path: path, Offset: startOffset}, &result); err != nil {
+		return nil, 0, err
+	}
+	return result.Files, result.Position, nil
+}
+
+// TranscriptChunker interface implementation
+
+// ChunkTranscript asks the plugin to split a transcript. Plugins that don't
+// chunk transcripts get line-based JSONL chunking.
+func (e *ExternalAgent) ChunkTranscript(content []byte, maxSize int) ([][]byte, error) {
+	var result chunksMessage
+	err := e.call(MethodChunkTranscript, chunkTranscriptParams{Content: content, MaxSize: maxSize}, &result)
+	if errors.Is(err, ErrUnsupported) {
+		chunks, chunkErr := agent.ChunkJSONL(content, maxSize)
+		if chunkErr != nil {
+			return nil, fmt.Errorf("failed to chunk transcript: %w", chunkErr)
+		}
+		return chunks, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result.Chunks, nil
+}
+
+// ReassembleTranscript asks the plugin to combine transcript chunks. Plugins
+// that don't chunk transcripts get JSONL reassembly.
+func (e *ExternalAgent) ReassembleTranscript(chunks [][]byte) ([]byte, error) {
+	var result contentMessage
+	err := e.call(MethodReassembleTranscript, chunksMessage{Chunks: chunks}, &result)
+	if errors.Is(err, ErrUnsupported) {
+		return agent.ReassembleJSONL(chunks), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result.Content, nil
+}
+
+// TranscriptParser interface implementation
+
+// ParseEntries asks the plugin to normalize transcript data into session entries.
+func (e *ExternalAgent) ParseEntries(data []byte) ([]agent.SessionEntry, error) {
+	var result entriesMessage
+	if err := e.call(MethodParseEntries, contentMessage{Content: data}, &result); err != nil {
+		return nil, err
+	}
+	return toEntries(result.Entries), nil
+}
@@ -0,0 +1,505 @@
+package external
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+// servePluginEnv makes the test binary act as the sample plugin, so the
+// adapter is tested against a real plugin process.
+const servePluginEnv = "ENTIRE_TEST_SERVE_AGENT_PLUGIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(servePluginEnv) != "" {
+		if err := Serve(&sampleAgent{}, sampleHooks, os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+var sampleHooks = []HookSpec{
+	{Name: "prompt", Type: agent.HookUserPromptSubmit},
+	{Name: "done", Type: agent.HookStop},
+}
+
+// sampleAgent is a minimal agent whose transcripts are JSONL files of
+// {"role": ..., "content": ..., "files": [...]} lines, one entry per line.
+// Hooks are "installed" by creating .sample-hooks in the working directory.
+type sampleAgent struct{}
+
+type sampleLine struct {
+	Role    string   `json:"role"`
+	Content string   `json:"content"`
+	Files   []string `json:"files,omitempty"`
+}
+
+type sampleHookInput struct {
+	SessionID  string `json:"session_id"`
+	Transcript string `json:"transcript"`
+}
+
+const sampleHooksFile = ".sample-hooks"
+
+func (s *sampleAgent) Name() agent.AgentName     { return "sample" }
+func (s *sampleAgent) Type() agent.AgentType     { return "Sample Agent" }
+func (s *sampleAgent) Description() string       { return "Sample - agent plugin for tests" }
+func (s *sampleAgent) GetHookConfigPath() string { return sampleHooksFile }
+func (s *sampleAgent) SupportsHooks() bool       { return true }
+func (s *sampleAgent) GetSessionID(in *agent.HookInput) string {
+	return in.SessionID
+}
+func (s *sampleAgent) TransformSessionID(id string) string    { return id }
+func (s *sampleAgent) ExtractAgentSessionID(id string) string { return id }
+func (s *sampleAgent) FormatResumeCommand(id string) string   { return "sample --resume " + id }
+
+func (s *sampleAgent) DetectPresence() (bool, error) {
+	_, err := os.Stat(sampleHooksFile)
+	return err == nil, nil
+}
+
+func (s *sampleAgent) GetSessionDir(repoPath string) (string, error) {
+	return filepath.Join(repoPath, ".sample"), nil
+}
+
+func (s *sampleAgent) ParseHookInput(hookType agent.HookType, r io.Reader) (*agent.HookInput, error) {
+	var raw sampleHookInput
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("bad hook input: %w", err)
+	}
+	return &agent.HookInput{HookType: hookType, SessionID: raw.SessionID, SessionRef: raw.Transcript}, nil
+}
+
+func (s *sampleAgent) ReadSession(in *agent.HookInput) (*agent.AgentSession, error) {
+	data, err := os.ReadFile(in.SessionRef)
+	if err != nil {
+		return nil, fmt.Errorf("read transcript: %w", err)
+	}
+	entries, err := parseSampleEntries(in.SessionID, data)
+	if err != nil {
+		return nil, err
+	}
+	session := &agent.AgentSession{SessionID: in.SessionID, AgentName: s.Name(), SessionRef: in.SessionRef, NativeData: data, Entries: entries}
+	for _, e := range entries {
+		session.ModifiedFiles = append(session.ModifiedFiles, e.FilesAffected...)
+	}
+	return session, nil
+}
+
+func (s *sampleAgent) ParseEntries(data []byte) ([]agent.SessionEntry, error) {
+	return parseSampleEntries("stored", data)
+}
+
+func parseSampleEntries(sessionID string, data []byte) ([]agent.SessionEntry, error) {
+	var entries []agent.SessionEntry
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for i := 0; scanner.Scan(); i++ {
+		var line sampleLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return nil, fmt.Errorf("bad line %d: %w", i, err)
+		}
+		entries = append(entries, agent.SessionEntry{
+			UUID:          fmt.Sprintf("%s-%d", sessionID, i),
+			Type:          agent.EntryType(line.Role),
+			Content:       line.Content,
+			Position:      i,
+			FilesAffected: line.Files,
+		})
+	}
+	return entries, nil
+}
+
+func (s *sampleAgent) WriteSession(session *agent.AgentSession) error {
+	if session.AgentName != s.Name() {
+		return fmt.Errorf("wrong agent %q", session.AgentName)
+	}
+	return os.WriteFile(session.SessionRef, session.NativeData, 0o600) //nolint:wrapcheck // test agent
+}
+
+func (s *sampleAgent) InstallHooks(_ bool, force bool) (int, error) {
+	if _, err := os.Stat(sampleHooksFile); err == nil && !force {
+		return 0, nil
+	}
+	return len(sampleHooks), os.WriteFile(sampleHooksFile, []byte("prompt\ndone\n"), 0o600) //nolint:wrapcheck // test agent
+}
+
+func (s *sampleAgent) UninstallHooks() error {
+	if err := os.Remove(sampleHooksFile); err != nil && !os.IsNotExist(err) {
+		return err //nolint:wrapcheck // test agent
+	}
+	return nil
+}
+
+func (s *sampleAgent) AreHooksInstalled() bool {
+	_, err := os.Stat(sampleHooksFile)
+	return err == nil
+}
+
+func (s *sampleAgent) GetSupportedHooks() []agent.HookType {
+	return []agent.HookType{agent.HookUserPromptSubmit, agent.HookStop}
+}
+
+func (s *sampleAgent) GetTranscriptPosition(path string) (int, error) {
+	session, err := s.ReadSession(&agent.HookInput{SessionRef: path})
+	if err != nil {
+		return 0, err
+	}
+	return len(session.Entries), nil
+}
+
+func (s *sampleAgent) ExtractModifiedFilesFromOffset(path string, offset int) ([]string, int, error) {
+	session, err := s.ReadSession(&agent.HookInput{SessionRef: path})
+	if err != nil {
+		return nil, 0, err
+	}
+	var files []string
+	for _, e := range session.Entries[min(offset, len(session.Entries)):] {
+		files = append(files, e.FilesAffected...)
+	}
+	return files, len(session.Entries), nil
+}
+
+const sampleTranscript = `{"role":"user","content":"Add a README"}
+{"role":"tool","content":"write","files":["README.md"]}
+{"role":"assistant","content":"Added README.md"}
+{"role":"user","content":"Add a license"}
+{"role":"tool","content":"write","files":["LICENSE"]}
+`
+
+// installSamplePlugin puts the sample plugin on PATH as entire-agent-sample,
+// in a fresh working directory, and returns its adapter.
+func installSamplePlugin(t *testing.T) *ExternalAgent {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin executable is a symlink to the test binary")
+	}
+	testBinary, err := os.Executable()
+	if err != nil {
+		t.Fatalf("os.Executable() error = %v", err)
+	}
+	binDir := t.TempDir()
+	path := filepath.Join(binDir, ExecutablePrefix+"sample")
+	if err := os.Symlink(testBinary, path); err != nil {
+		t.Fatalf("failed to install plugin: %v", err)
+	}
+	t.Setenv(servePluginEnv, "1")
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Chdir(t.TempDir())
+	return New("sample", path)
+}
+
+func TestDiscover(t *testing.T) {
+	dirA := t.TempDir()
+	dirB := t.TempDir()
+	write := func(dir, name string, mode os.FileMode) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"), mode); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return path
+	}
+	exe := ""
+	if runtime.GOOS == "windows" {
+		exe = ".exe"
+	}
+	first := write(dirA, ExecutablePrefix+"mine"+exe, 0o755)
+	write(dirB, ExecutablePrefix+"mine"+exe, 0o755)
+	other := write(dirB, ExecutablePrefix+"other"+exe, 0o755)
+	write(dirB, "entire-agent-"+exe, 0o755)
+	write(dirB, "entire-other"+exe, 0o755)
+	if runtime.GOOS != "windows" {
+		write(dirB, ExecutablePrefix+"noexec", 0o644)
+	}
+	if err := os.Mkdir(filepath.Join(dirB, ExecutablePrefix+"dir"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	found := Discover(strings.Join([]string{dirA, "", filepath.Join(dirA, "missing"), dirB}, string(os.PathListSeparator)))
+	want := map[agent.AgentName]string{"mine": first, "other": other}
+	if len(found) != len(want) {
+		t.Fatalf("Discover() = %v, want %v", found, want)
+	}
+	for name, path := range want {
+		if found[name] != path {
+			t.Errorf("Discover()[%s] = %q, want %q", name, found[name], path)
+		}
+	}
+}
+
+func TestRegisteredFromPath(t *testing.T) {
+	installSamplePlugin(t)
+
+	// The registry discovers plugins once; ask the discoverer directly
+	found := Discover(os.Getenv("PATH"))
+	if _, ok := found["sample"]; !ok {
+		t.Fatalf("Discover() = %v, want sample plugin", found)
+	}
+}
+
+func TestInfo(t *testing.T) {
+	ext := installSamplePlugin(t)
+
+	if ext.Name() != "sample" {
+		t.Errorf("Name() = %q, want sample", ext.Name())
+	}
+	if ext.Type() != "Sample Agent" {
+		t.Errorf("Type() = %q, want Sample Agent", ext.Type())
+	}
+	if ext.Description() != "Sample - agent plugin for tests" {
+		t.Errorf("Description() = %q", ext.Description())
+	}
+	if !ext.SupportsHooks() {
+		t.Error("SupportsHooks() = false, want true")
+	}
+	if names := ext.GetHookNames(); strings.Join(names, ",") != "prompt,done" {
+		t.Errorf("GetHookNames() = %v, want [prompt done]", names)
+	}
+	if hookType, ok := ext.HookType("done"); !ok || hookType != agent.HookStop {
+		t.Errorf("HookType(done) = %q, %v; want stop", hookType, ok)
+	}
+	if _, ok := ext.HookType("unknown"); ok {
+		t.Error("HookType(unknown) found a hook")
+	}
+	if got := ext.FormatResumeCommand("s1"); got != "sample --resume s1" {
+		t.Errorf("FormatResumeCommand() = %q", got)
+	}
+}
+
+func TestHooks(t *testing.T) {
+	ext := installSamplePlugin(t)
+
+	if ext.AreHooksInstalled() {
+		t.Error("AreHooksInstalled() = true before install")
+	}
+	if present, err := ext.DetectPresence(); err != nil || present {
+		t.Errorf("DetectPresence() = %v, %v; want false", present, err)
+	}
+	count, err := ext.InstallHooks(false, false)
+	if err != nil {
+		t.Fatalf("InstallHooks() error = %v", err)
+	}
+	if count != 2 {
+		t.Errorf("InstallHooks() = %d, want 2", count)
+	}
+	if !ext.AreHooksInstalled() {
+		t.Error("AreHooksInstalled() = false after install")
+	}
+	if present, err := ext.DetectPresence(); err != nil || !present {
+		t.Errorf("DetectPresence() = %v, %v; want true", present, err)
+	}
+	if err := ext.UninstallHooks(); err != nil {
+		t.Fatalf("UninstallHooks() error = %v", err)
+	}
+	if ext.AreHooksInstalled() {
+		t.Error("AreHooksInstalled() = true after uninstall")
+	}
+}
+
+func TestSessionRoundTrip(t *testing.T) {
+	ext := installSamplePlugin(t)
+	transcript, err := filepath.Abs("transcript.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(transcript, []byte(sampleTranscript), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	input, err := ext.ParseHookInput(agent.HookStop, strings.NewReader(`{"session_id":"s1","transcript":"`+transcript+`"}`))
+	if err != nil {
+		t.Fatalf("ParseHookInput() error = %v", err)
+	}
+	if input.SessionID != "s1" || input.SessionRef != transcript || input.HookType != agent.HookStop {
+		t.Errorf("ParseHookInput() = %+v", input)
+	}
+	if input.Timestamp.IsZero() {
+		t.Error("ParseHookInput() left the timestamp unset")
+	}
+
+	session, err := ext.ReadSession(input)
+	if err != nil {
+		t.Fatalf("ReadSession() error = %v", err)
+	}
+	if session.AgentName != "sample" || string(session.NativeData) != sampleTranscript {
+		t.Errorf("ReadSession() = %+v", session)
+	}
+	if len(session.Entries) != 5 || session.Entries[2].Type != agent.EntryAssistant || session.Entries[2].Content != "Added README.md" {
+		t.Errorf("Entries = %+v", session.Entries)
+	}
+	if session.GetLastUserPrompt() != "Add a license" {
+		t.Errorf("GetLastUserPrompt() = %q", session.GetLastUserPrompt())
+	}
+
+	pos, err := ext.GetTranscriptPosition(transcript)
+	if err != nil || pos != 5 {
+		t.Errorf("GetTranscriptPosition() = %d, %v; want 5", pos, err)
+	}
+	files, pos, err := ext.ExtractModifiedFilesFromOffset(transcript, 3)
+	if err != nil || pos != 5 || len(files) != 1 || files[0] != "LICENSE" {
+		t.Errorf("ExtractModifiedFilesFromOffset() = %v, %d, %v; want [LICENSE], 5", files, pos, err)
+	}
+
+	session.SessionRef = filepath.Join(filepath.Dir(transcript), "restored.jsonl")
+	if err := ext.WriteSession(session); err != nil {
+		t.Fatalf("WriteSession() error = %v", err)
+	}
+	if data, err := os.ReadFile(session.SessionRef); err != nil || string(data) != sampleTranscript {
+		t.Errorf("restored transcript = %q, %v", data, err)
+	}
+
+	if _, err := ext.ReadSession(&agent.HookInput{SessionID: "s2", SessionRef: "missing.jsonl"}); err == nil {
+		t.Error("ReadSession() expected error for missing transcript")
+	}
+}
+
+func TestParseEntries(t *testing.T) {
+	ext := installSamplePlugin(t)
+
+	entries, err := ext.ParseEntries([]byte(sampleTranscript))
+	if err != nil {
+		t.Fatalf("ParseEntries() error = %v", err)
+	}
+	if len(entries) != 5 || entries[4].Position != 4 || entries[4].FilesAffected[0] != "LICENSE" {
+		t.Errorf("ParseEntries() = %+v", entries)
+	}
+	if prompts := agent.UserPrompts(agent.EntriesFrom(entries, 3)); len(prompts) != 1 || prompts[0] != "Add a license" {
+		t.Errorf("prompts since position 3 = %q", prompts)
+	}
+
+	if _, err := ext.ParseEntries([]byte("not json\n")); err == nil {
+		t.Error("ParseEntries() expected error for malformed transcript")
+	}
+}
+
+func TestChunkTranscript_Fallback(t *testing.T) {
+	ext := installSamplePlugin(t)
+
+	// The sample agent doesn't chunk transcripts, so JSONL chunking is used
+	chunks, err := ext.ChunkTranscript([]byte(sampleTranscript), 100)
+	if err != nil {
+		t.Fatalf("ChunkTranscript() error = %v", err)
+	}
+	if len(chunks) < 2 {
+		t.Errorf("ChunkTranscript() = %d chunks, want several", len(chunks))
+	}
+	content, err := ext.ReassembleTranscript(chunks)
+	if err != nil {
+		t.Fatalf("ReassembleTranscript() error = %v", err)
+	}
+	if strings.TrimSuffix(string(content), "\n") != strings.TrimSuffix(sampleTranscript, "\n") {
+		t.Errorf("ReassembleTranscript() = %q", content)
+	}
+}
+
+func TestServe_Errors(t *testing.T) {
+	t.Parallel()
+
+	call := func(request string) Response {
+		t.Helper()
+		var out strings.Builder
+		if err := Serve(&sampleAgent{}, sampleHooks, strings.NewReader(request), &out); err != nil {
+			t.Fatalf("Serve() error = %v", err)
+		}
+		var resp Response
+		if err := json.Unmarshal([]byte(out.String()), &resp); err != nil {
+			t.Fatalf("invalid response %q: %v", out.String(), err)
+		}
+		return resp
+	}
+
+	if resp := call(`{"protocol_version":1,"method":"chunk_transcript","params":{"content":"","max_size":1}}`); resp.Error == nil || resp.Error.Code != ErrorCodeUnsupported {
+		t.Errorf("chunk_transcript response = %+v, want unsupported", resp.Error)
+	}
+	if resp := call(`{"protocol_version":1,"method":"teleport"}`); resp.Error == nil || resp.Error.Code != ErrorCodeUnsupported {
+		t.Errorf("unknown method response = %+v, want unsupported", resp.Error)
+	}
+	if resp := call(`{"protocol_version":99,"method":"info"}`); resp.Error == nil || resp.Error.Code != "" {
+		t.Errorf("version mismatch response = %+v, want error", resp.Error)
+	}
+	if resp := call(`{"protocol_version":1,"method":"parse_hook_input","params":{"input":"nope"}}`); resp.Error == nil || !strings.Contains(resp.Error.Message, "bad hook input") {
+		t.Errorf("agent error response = %+v, want agent's error", resp.Error)
+	}
+}
+
+func TestCall_ProtocolMismatch(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin is a shell script")
+	}
+	path := filepath.Join(t.TempDir(), ExecutablePrefix+"future")
+	script := "#!/bin/sh\ncat >/dev/null\necho '{\"protocol_version\":2,\"result\":{\"protocol_version\":2,\"type\":\"Future\"}}'\n"
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	ext := New("future", path)
+	if _, err := ext.Info(); err == nil || !strings.Contains(err.Error(), "protocol version 2") {
+		t.Errorf("Info() error = %v, want protocol version mismatch", err)
+	}
+	// Falls back to the registry name
+	if ext.Type() != "future" {
+		t.Errorf("Type() = %q, want future", ext.Type())
+	}
+	if _, err := ext.InstallHooks(false, false); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("InstallHooks() error = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestCall_PluginFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin is a shell script")
+	}
+	path := filepath.Join(t.TempDir(), ExecutablePrefix+"broken")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\necho 'boom' >&2\nexit 3\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	ext := New("broken", path)
+	_, err := ext.DetectPresence()
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("DetectPresence() error = %v, want plugin's stderr", err)
+	}
+}
+
+func TestInfo_CachesFailures(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin is a shell script")
+	}
+	dir := t.TempDir()
+	runs := filepath.Join(dir, "runs")
+	path := filepath.Join(dir, ExecutablePrefix+"flaky")
+	script := "#!/bin/sh\necho run >> '" + runs + "'\nexit 1\n"
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	ext := New("flaky", path)
+	if _, err := ext.Info(); err == nil {
+		t.Error("Info() error = nil, want plugin failure")
+	}
+	// Resolving the agent again doesn't run the plugin again
+	if ext.Type() != "flaky" {
+		t.Errorf("Type() = %q, want flaky", ext.Type())
+	}
+	if _, err := New("flaky", path).Info(); err == nil {
+		t.Error("Info() error = nil, want cached plugin failure")
+	}
+	data, err := os.ReadFile(runs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "run"); n != 1 {
+		t.Errorf("plugin ran %d times, want 1", n)
+	}
+}
@@ -0,0 +1,315 @@
+package external
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+// ProtocolVersion is the version of the plugin protocol this build speaks.
+// It is bumped for changes that existing plugins can't handle.
+const ProtocolVersion = 1
+
+// Plugin methods. Each call runs the plugin executable once with the method
+// name as its only argument and a Request on stdin, and reads a Response from stdout.
+const (
+	MethodInfo                 = "info"
+	MethodDetectPresence       = "detect_presence"
+	MethodGetHookConfigPath    = "get_hook_config_path"
+	MethodParseHookInput       = "parse_hook_input"
+	MethodGetSessionDir        = "get_session_dir"
+	MethodReadSession          = "read_session"
+	MethodWriteSession         = "write_session"
+	MethodFormatResumeCommand  = "format_resume_command"
+	MethodInstallHooks         = "install_hooks"
+	MethodUninstallHooks       = "uninstall_hooks"
+	MethodAreHooksInstalled    = "are_hooks_installed"
+	MethodGetTranscriptPos     = "get_transcript_position"
+	MethodExtractModifiedFiles = "extract_modified_files"
+	MethodChunkTranscript      = "chunk_transcript"
+	MethodReassembleTranscript = "reassemble_transcript"
+	MethodParseEntries         = "parse_entries"
+)
+
+// ErrorCodeUnsupported is the error code of a plugin that doesn't implement a method.
+const ErrorCodeUnsupported = "unsupported"
+
+// ErrUnsupported is returned for methods a plugin doesn't implement.
+var ErrUnsupported = errors.New("not supported by agent plugin")
+
+// Request is the message sent to a plugin on stdin.
+type Request struct {
+	ProtocolVersion int             `json:"protocol_version"`
+	Method          string          `json:"method"`
+	Params          json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is the message a plugin writes to stdout.
+// Exactly one of Result and Error is set.
+type Response struct {
+	ProtocolVersion int             `json:"protocol_version"`
+	Result          json.RawMessage `json:"result,omitempty"`
+	Error           *ResponseError  `json:"error,omitempty"`
+}
+
+// ResponseError describes a failed call.
+type ResponseError struct {
+	// Code is ErrorCodeUnsupported for methods the plugin doesn't implement, or empty
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// Info describes a plugin. It is the result of the info method.
+type Info struct {
+	// ProtocolVersion is the protocol version the plugin speaks
+	ProtocolVersion int `json:"protocol_version"`
+	// Type is the agent type stored in metadata and trailers (e.g., "My Agent")
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	// Hooks lists the hook verbs the plugin installs; empty if it has no hooks
+	Hooks []HookSpec `json:"hooks,omitempty"`
+}
+
+// HookSpec maps a hook verb, run as `entire hooks <agent> <verb>`, to the
+// lifecycle event it reports.
+type HookSpec struct {
+	Name string         `json:"name"`
+	Type agent.HookType `json:"type"`
+}
+
+// Method parameters and results. Methods without parameters send none;
+// methods without results return an empty object.
+
+type detectPresenceResult struct {
+	Present bool `json:"present"`
+}
+
+type pathResult struct {
+	Path string `json:"path"`
+}
+
+type parseHookInputParams struct {
+	HookType agent.HookType `json:"hook_type"`
+	// Input is the hook's stdin, as the agent wrote it
+	Input string `json:"input"`
+}
+
+type getSessionDirParams struct {
+	RepoPath string `json:"repo_path"`
+}
+
+type formatResumeCommandParams struct {
+	SessionID string `json:"session_id"`
+}
+
+type formatResumeCommandResult struct {
+	Command string `json:"command"`
+}
+
+type installHooksParams struct {
+	LocalDev bool `json:"local_dev"`
+	Force    bool `json:"force"`
+	// HookCommand is the command to run with a hook verb appended,
+	// e.g. "entire hooks my-agent"
+	HookCommand string `json:"hook_command"`
+}
+
+type installHooksResult struct {
+	Count int `json:"count"`
+}
+
+type areHooksInstalledResult struct {
+	Installed bool `json:"installed"`
+}
+
+type transcriptPositionParams struct {
+	Path string `json:"path"`
+}
+
+type transcriptPositionResult struct {
+	Position int `json:"position"`
+}
+
+type extractModifiedFilesParams struct {
+	Path   string `json:"path"`
+	Offset int    `json:"offset"`
+}
+
+type extractModifiedFilesResult struct {
+	Files    []string `json:"files"`
+	Position int      `json:"position"`
+}
+
+type chunkTranscriptParams struct {
+	Content []byte `json:"content"`
+	MaxSize int    `json:"max_size"`
+}
+
+type chunksMessage struct {
+	Chunks [][]byte `json:"chunks"`
+}
+
+type contentMessage struct {
+	Content []byte `json:"content"`
+}
+
+type entriesMessage struct {
+	Entries []EntryMessage `json:"entries"`
+}
+
+// HookInputMessage is agent.HookInput on the wire.
+type HookInputMessage struct {
+	HookType     agent.HookType         `json:"hook_type,omitempty"`
+	SessionID    string                 `json:"session_id"`
+	SessionRef   string                 `json:"session_ref,omitempty"`
+	Timestamp    time.Time              `json:"timestamp,omitzero"`
+	UserPrompt   string                 `json:"user_prompt,omitempty"`
+	ToolName     string                 `json:"tool_name,omitempty"`
+	ToolUseID    string                 `json:"tool_use_id,omitempty"`
+	ToolInput    json.RawMessage        `json:"tool_input,omitempty"`
+	ToolResponse json.RawMessage        `json:"tool_response,omitempty"`
+	RawData      map[string]interface{} `json:"raw_data,omitempty"`
+}
+
+// SessionMessage is agent.AgentSession on the wire.
+// Native data is base64-encoded; entries are indexed by transcript position.
+type SessionMessage struct {
+	SessionID     string          `json:"session_id"`
+	AgentName     agent.AgentName `json:"agent_name,omitempty"`
+	RepoPath      string          `json:"repo_path,omitempty"`
+	SessionRef    string          `json:"session_ref"`
+	StartTime     time.Time       `json:"start_time,omitzero"`
+	NativeData    []byte          `json:"native_data"`
+	ModifiedFiles []string        `json:"modified_files,omitempty"`
+	NewFiles      []string        `json:"new_files,omitempty"`
+	DeletedFiles  []string        `json:"deleted_files,omitempty"`
+	Entries       []EntryMessage  `json:"entries,omitempty"`
+}
+
+// EntryMessage is agent.SessionEntry on the wire.
+type EntryMessage struct {
+	UUID          string            `json:"uuid"`
+	Type          agent.EntryType   `json:"type"`
+	Timestamp     time.Time         `json:"timestamp,omitzero"`
+	Content       string            `json:"content,omitempty"`
+	Position      int               `json:"position"`
+	Model         string            `json:"model,omitempty"`
+	TokenUsage    *agent.TokenUsage `json:"token_usage,omitempty"`
+	ToolName      string            `json:"tool_name,omitempty"`
+	ToolUseID     string            `json:"tool_use_id,omitempty"`
+	ToolInput     interface{}       `json:"tool_input,omitempty"`
+	ToolOutput    interface{}       `json:"tool_output,omitempty"`
+	FilesAffected []string          `json:"files_affected,omitempty"`
+}
+
+func toEntryMessages(entries []agent.SessionEntry) []EntryMessage {
+	var msgs []EntryMessage
+	for _, e := range entries {
+		msgs = append(msgs, EntryMessage{
+			UUID:          e.UUID,
+			Type:          e.Type,
+			Timestamp:     e.Timestamp,
+			Content:       e.Content,
+			Position:      e.Position,
+			Model:         e.Model,
+			TokenUsage:    e.TokenUsage,
+			ToolName:      e.ToolName,
+			ToolUseID:     e.ToolUseID,
+			ToolInput:     e.ToolInput,
+			ToolOutput:    e.ToolOutput,
+			FilesAffected: e.FilesAffected,
+		})
+	}
+	return msgs
+}
+
+func toEntries(msgs []EntryMessage) []agent.SessionEntry {
+	var entries []agent.SessionEntry
+	for _, m := range msgs {
+		entries = append(entries, agent.SessionEntry{
+			UUID:          m.UUID,
+			Type:          m.Type,
+			Timestamp:     m.Timestamp,
+			Content:       m.Content,
+			Position:      m.Position,
+			Model:         m.Model,
+			TokenUsage:    m.TokenUsage,
+			ToolName:      m.ToolName,
+			ToolUseID:     m.ToolUseID,
+			ToolInput:     m.ToolInput,
+			ToolOutput:    m.ToolOutput,
+			FilesAffected: m.FilesAffected,
+		})
+	}
+	return entries
+}
+
+func toHookInputMessage(input *agent.HookInput) *HookInputMessage {
+	return &HookInputMessage{
+		HookType:     input.HookType,
+		SessionID:    input.SessionID,
+		SessionRef:   input.SessionRef,
+		Timestamp:    input.Timestamp,
+		UserPrompt:   input.UserPrompt,
+		ToolName:     input.ToolName,
+		ToolUseID:    input.ToolUseID,
+		ToolInput:    rawJSON(input.ToolInput),
+		ToolResponse: rawJSON(input.ToolResponse),
+		RawData:      input.RawData,
+	}
+}
+
+func (m *HookInputMessage) toHookInput() *agent.HookInput {
+	return &agent.HookInput{
+		HookType:     m.HookType,
+		SessionID:    m.SessionID,
+		SessionRef:   m.SessionRef,
+		Timestamp:    m.Timestamp,
+		UserPrompt:   m.UserPrompt,
+		ToolName:     m.ToolName,
+		ToolUseID:    m.ToolUseID,
+		ToolInput:    []byte(m.ToolInput),
+		ToolResponse: []byte(m.ToolResponse),
+		RawData:      m.RawData,
+	}
+}
+
+// rawJSON returns data as a raw JSON value, or nil if it isn't valid JSON.
+func rawJSON(data []byte) json.RawMessage {
+	if len(data) == 0 || !json.Valid(data) {
+		return nil
+	}
+	return json.RawMessage(data)
+}
+
+func toSessionMessage(session *agent.AgentSession) *SessionMessage {
+	return &SessionMessage{
+		SessionID:     session.SessionID,
+		AgentName:     session.AgentName,
+		RepoPath:      session.RepoPath,
+		SessionRef:    session.SessionRef,
+		StartTime:     session.StartTime,
+		NativeData:    session.NativeData,
+		ModifiedFiles: session.ModifiedFiles,
+		NewFiles:      session.NewFiles,
+		DeletedFiles:  session.DeletedFiles,
+		Entries:       toEntryMessages(session.Entries),
+	}
+}
+
+func (m *SessionMessage) toSession() *agent.AgentSession {
+	return &agent.AgentSession{
+		SessionID:     m.SessionID,
+		AgentName:     m.AgentName,
+		RepoPath:      m.RepoPath,
+		SessionRef:    m.SessionRef,
+		StartTime:     m.StartTime,
+		NativeData:    m.NativeData,
+		ModifiedFiles: m.ModifiedFiles,
+		NewFiles:      m.NewFiles,
+		DeletedFiles:  m.DeletedFiles,
+		Entries:       toEntries(m.Entries),
+	}
+}
@@ -0,0 +1,246 @@
+package external
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+// Serve handles one plugin call for ag: it reads a Request from in, calls the
+// matching method and writes the Response to out. hooks lists the hook verbs
+// ag installs and the events they report.
+//
+// A plugin written in Go is a main package calling
+//
+//	external.Serve(myAgent, hooks, os.Stdin, os.Stdout)
+//
+// Methods of optional interfaces ag doesn't implement are answered as unsupported.
+// Serve only returns an error if the response can't be written.
+func Serve(ag agent.Agent, hooks []HookSpec, in io.Reader, out io.Writer) error {
+	resp := Response{ProtocolVersion: ProtocolVersion}
+	result, err := dispatch(ag, hooks, in)
+	switch {
+	case errors.Is(err, ErrUnsupported):
+		resp.Error = &ResponseError{Code: ErrorCodeUnsupported, Message: err.Error()}
+	case err != nil:
+		resp.Error = &ResponseError{Message: err.Error()}
+	default:
+		data, marshalErr := json.Marshal(result)
+		if marshalErr != nil {
+			resp.Error = &ResponseError{Message: fmt.Sprintf("failed to encode result: %v", marshalErr)}
+		} else {
+			resp.Result = data
+		}
+	}
+
+	if err := json.NewEncoder(out).Encode(resp); err != nil {
+		return fmt.Errorf("failed to write response: %w", err)
+	}
+	return nil
+}
+
+// dispatch decodes a request and calls the method it names.
+func dispatch(ag agent.Agent, hooks []HookSpec, in io.Reader) (any, error) {
+	var req Request
+	if err := json.NewDecoder(in).Decode(&req); err != nil {
+		return nil, fmt.Errorf("failed to decode request: %w", err)
+	}
+	if req.ProtocolVersion != ProtocolVersion {
+		return nil, fmt.Errorf("unsupported protocol version %d (plugin speaks version %d)", req.ProtocolVersion, ProtocolVersion)
+	}
+	params := func(v any) error {
+		if len(req.Params) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(req.Params, v); err != nil {
+			return fmt.Errorf("failed to decode %s params: %w", req.Method, err)
+		}
+		return nil
+	}
+
+	switch req.Method {
+	case MethodInfo:
+		return &Info{
+			ProtocolVersion: ProtocolVersion,
+			Type:            string(ag.Type()),
+			Description:     ag.Description(),
+			Hooks:           hooks,
+		}, nil
+
+	case MethodDetectPresence:
+		present, err := ag.DetectPresence()
+		if err != nil {
+			return nil, err //nolint:wrapcheck // Plugin errors are reported as is
+		}
+		return detectPresenceResult{Present: present}, nil
+
+	case MethodGetHookConfigPath:
+		return pathResult{Path: ag.GetHookConfigPath()}, nil
+
+	case MethodParseHookInput:
+		var p parseHookInputParams
+		if err := params(&p); err != nil {
+			return nil, err
+		}
+		input, err := ag.ParseHookInput(p.HookType, strings.NewReader(p.Input))
+		if err != nil {
+			return nil, err //nolint:wrapcheck // Plugin errors are reported as is
+		}
+		return toHookInputMessage(input), nil
+
+	case MethodGetSessionDir:
+		var p getSessionDirParams
+		if err := params(&p); err != nil {
+			return nil, err
+		}
+		dir, err := ag.GetSessionDir(p.RepoPath)
+		if err != nil {
+			return nil, err //nolint:wrapcheck // Plugin errors are reported as is
+		}
+		return pathResult{Path: dir}, nil
+
+	case MethodReadSession:
+		var p HookInputMessage
+		if err := params(&p); err != nil {
+			return nil, err
+		}
+		session, err := ag.ReadSession(p.toHookInput())
+		if err != nil {
+			return nil, err //nolint:wrapcheck // Plugin errors are reported as is
+		}
+		return toSessionMessage(session), nil
+
+	case MethodWriteSession:
+		var p SessionMessage
+		if err := params(&p); err != nil {
+			return nil, err
+		}
+		session := p.toSession()
+		// The adapter's registry name may differ from the served agent's
+		session.AgentName = ag.Name()
+		if err := ag.WriteSession(session); err != nil {
+			return nil, err //nolint:wrapcheck // Plugin errors are reported as is
+		}
+		return struct{}{}, nil
+
+	case MethodFormatResumeCommand:
+		var p formatResumeCommandParams
+		if err := params(&p); err != nil {
+			return nil, err
+		}
+		return formatResumeCommandResult{Command: ag.FormatResumeCommand(p.SessionID)}, nil
+
+	case MethodInstallHooks, MethodUninstallHooks, MethodAreHooksInstalled:
+		hookAgent, ok := ag.(agent.HookSupport)
+		if !ok {
+			return nil, fmt.Errorf("%s: %w", req.Method, ErrUnsupported)
+		}
+		return dispatchHooks(hookAgent, req.Method, params)
+
+	case MethodGetTranscriptPos, MethodExtractModifiedFiles:
+		analyzer, ok := ag.(agent.TranscriptAnalyzer)
+		if !ok {
+			return nil, fmt.Errorf("%s: %w", req.Method, ErrUnsupported)
+		}
+		return dispatchAnalyzer(analyzer, req.Method, params)
+
+	case MethodChunkTranscript, MethodReassembleTranscript:
+		chunker, ok := ag.(agent.TranscriptChunker)
+		if !ok {
+			return nil, fmt.Errorf("%s: %w", req.Method, ErrUnsupported)
+		}
+		return dispatchChunker(chunker, req.Method, params)
+
+	case MethodParseEntries:
+		parser, ok := ag.(agent.TranscriptParser)
+		if !ok {
+			return nil, fmt.Errorf("%s: %w", req.Method, ErrUnsupported)
+		}
+		var p contentMessage
+		if err := params(&p); err != nil {
+			return nil, err
+		}
+		entries, err := parser.ParseEntries(p.Content)
+		if err != nil {
+			return nil, err //nolint:wrapcheck // Plugin errors are reported as is
+		}
+		return entriesMessage{Entries: toEntryMessages(entries)}, nil
+
+	default:
+		return nil, fmt.Errorf("method %q: %w", req.Method, ErrUnsupported)
+	}
+}
+
+func dispatchHooks(ag agent.HookSupport, method string, params func(any) error) (any, error) {
+	switch method {
+	case MethodInstallHooks:
+		var p installHooksParams
+		if err := params(&p); err != nil {
+			return nil, err
+		}
+		count, err := ag.InstallHooks(p.LocalDev, p.Force)
+		if err != nil {
+			return nil, err //nolint:wrapcheck // Plugin errors are reported as is
+		}
+		return installHooksResult{Count: count}, nil
+	case MethodUninstallHooks:
+		if err := ag.UninstallHooks(); err != nil {
+			return nil, err //nolint:wrapcheck // Plugin errors are reported as is
+		}
+		return struct{}{}, nil
+	default:
+		return areHooksInstalledResult{Installed: ag.AreHooksInstalled()}, nil
+	}
+}
+
+func dispatchAnalyzer(ag agent.TranscriptAnalyzer, method string, params func(any) error) (any, error) {
+	if method == MethodGetTranscriptPos {
+		var p transcriptPositionParams
+		if err := params(&p); err != nil {
+			return nil, err
+		}
+		pos, err := ag.GetTranscriptPosition(p.Path)
+		if err != nil {
+			return nil, err //nolint:wrapcheck // Plugin errors are reported as is
+		}
+		return transcriptPositionResult{Position: pos}, nil
+	}
+
+	var p extractModifiedFilesParams
+	if err := params(&p); err != nil {
+		return nil, err
+	}
+	files, pos, err := ag.ExtractModifiedFilesFromOffset(p.Path, p.Offset)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // Plugin errors are reported as is
+	}
+	return extractModifiedFilesResult{Files: files, Position: pos}, nil
+}
+
+func dispatchChunker(ag agent.TranscriptChunker, method string, params func(any) error) (any, error) {
+	if method == MethodChunkTranscript {
+		var p chunkTranscriptParams
+		if err := params(&p); err != nil {
+			return nil, err
+		}
+		chunks, err := ag.ChunkTranscript(p.Content, p.MaxSize)
+		if err != nil {
+			return nil, err //nolint:wrapcheck // Plugin errors are reported as is
+		}
+		return chunksMessage{Chunks: chunks}, nil
+	}
+
+	var p chunksMessage
+	if err := params(&p); err != nil {
+		return nil, err
+	}
+	content, err := ag.ReassembleTranscript(p.Chunks)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // Plugin errors are reported as is
+	}
+	return contentMessage{Content: content}, nil
+}
@@ -207,6 +207,11 @@ func (g *GeminiCLIAgent) ReadSession(input *agent.HookInput) (*agent.AgentSessio
 		// Non-fatal: we can still return the session without modified files
 		modifiedFiles = nil
 	}
+	entries, err := ToSessionEntries(data)
+	if err != nil {
+		// Non-fatal, like modified files
+		entries = nil
+	}
 
 	return &agent.AgentSession{
 		SessionID:     input.SessionID,
@@ -215,6 +220,7 @@ func (g *GeminiCLIAgent) ReadSession(input *agent.HookInput) (*agent.AgentSessio
 		StartTime:     time.Now(),
 		NativeData:    data,
 		ModifiedFiles: modifiedFiles,
+		Entries:       entries,
 	}, nil
 }
 
@@ -451,3 +457,33 @@ func (g *GeminiCLIAgent) ReassembleTranscript(chunks [][]byte) ([]byte, error) {
 	}
 	return result, nil
 }
+
+// TranscriptParser interface implementation
+
+// ParseEntries normalizes a Gemini JSON transcript into session entries positioned by message.
+func (g *GeminiCLIAgent) ParseEntries(data []byte) ([]agent.SessionEntry, error) {
+	return ToSessionEntries(data)
+}
+
+// TranscriptBuilder interface implementation
+
+// BuildTranscript renders session entries recorded by another agent as a
+// Gemini JSON session file. Tool calls are down-converted to text, since
+// Gemini CLI can't replay another agent's tools.
+func (g *GeminiCLIAgent) BuildTranscript(session *agent.AgentSession) ([]byte, error) {
+	return BuildTranscript(session.SessionID, session.RepoPath, agent.ConversationTurns(session.Entries))
+}
+
+// ResolveSessionFile returns the path of a session file, named like the
+// session-<date>-<id prefix>.json files Gemini CLI writes. An existing file
+// of the session is reused.
+func (g *GeminiCLIAgent) ResolveSessionFile(sessionDir, agentSessionID string) string {
+	idPrefix := agentSessionID
+	if len(idPrefix) > 8 {
+		idPrefix = idPrefix[:8]
+	}
+	if existing, err := filepath.Glob(filepath.Join(sessionDir, "session-*-"+idPrefix+".json")); err == nil && len(existing) > 0 {
+		return existing[len(existing)-1]
+	}
+	return filepath.Join(sessionDir, "session-"+time.Now().Format("2006-01-02T15-04")+"-"+idPrefix+".json")
+}
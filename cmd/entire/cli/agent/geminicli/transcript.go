@@ -1,11 +1,17 @@
 package geminicli
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
+	"time"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
+
+	"github.com/google/uuid"
 )
 
 // Transcript parsing types - Gemini CLI uses JSON format for session storage
@@ -83,16 +89,7 @@ func ExtractModifiedFilesFromTranscript(transcript *GeminiTranscript) []string {
 				continue
 			}
 
-			// Extract file path from args map
-			var file string
-			if fp, ok := toolCall.Args["file_path"].(string); ok && fp != "" {
-				file = fp
-			} else if p, ok := toolCall.Args["path"].(string); ok && p != "" {
-				file = p
-			} else if fn, ok := toolCall.Args["filename"].(string); ok && fn != "" {
-				file = fn
-			}
-
+			file := toolCallFile(toolCall)
 			if file != "" && !fileSet[file] {
 				fileSet[file] = true
 				files = append(files, file)
@@ -103,6 +100,20 @@ func ExtractModifiedFilesFromTranscript(transcript *GeminiTranscript) []string {
 	return files
 }
 
+// toolCallFile returns the file path in a tool call's args, or "".
+func toolCallFile(toolCall GeminiToolCall) string {
+	if fp, ok := toolCall.Args["file_path"].(string); ok && fp != "" {
+		return fp
+	}
+	if p, ok := toolCall.Args["path"].(string); ok && p != "" {
+		return p
+	}
+	if fn, ok := toolCall.Args["filename"].(string); ok && fn != "" {
+		return fn
+	}
+	return ""
+}
+
 // ExtractLastUserPrompt extracts the last user message from transcript data
 func ExtractLastUserPrompt(data []byte) (string, error) {
 	transcript, err := ParseTranscript(data)
@@ -268,3 +279,117 @@ func CalculateTokenUsageFromFile(path string, startMessageIndex int) (*agent.Tok
 
 	return CalculateTokenUsage(data, startMessageIndex), nil
 }
+
+// ToSessionEntries normalizes a Gemini transcript into agent session entries.
+// Entry positions are message indexes, matching GetTranscriptPosition; every
+// message yields at least one entry. A gemini message yields an entry for its
+// text and, per tool call, a tool entry and (once the call ran) a tool result
+// entry. Model and token usage are set on the message's first entry.
+func ToSessionEntries(data []byte) ([]agent.SessionEntry, error) {
+	var transcript struct {
+		Messages []geminiEntryMessage `json:"messages"`
+	}
+	if err := json.Unmarshal(data, &transcript); err != nil {
+		return nil, fmt.Errorf("failed to parse transcript: %w", err)
+	}
+
+	var entries []agent.SessionEntry
+	for i, msg := range transcript.Messages {
+		var msgEntries []agent.SessionEntry
+		switch msg.Type {
+		case MessageTypeUser:
+			msgEntries = append(msgEntries, agent.SessionEntry{Type: agent.EntryUser, Content: msg.Content})
+		case MessageTypeGemini:
+			if msg.Content != "" {
+				msgEntries = append(msgEntries, agent.SessionEntry{Type: agent.EntryAssistant, Content: msg.Content})
+			}
+			for _, call := range msg.ToolCalls {
+				entry := agent.SessionEntry{
+					Type:      agent.EntryTool,
+					ToolName:  call.Name,
+					ToolUseID: call.ID,
+					ToolInput: call.Args,
+				}
+				if slices.Contains(FileModificationTools, call.Name) {
+					if file := toolCallFile(call.GeminiToolCall); file != "" {
+						entry.FilesAffected = []string{file}
+					}
+				}
+				msgEntries = append(msgEntries, entry)
+				if call.ResultDisplay != nil {
+					msgEntries = append(msgEntries, agent.SessionEntry{
+						Type:       agent.EntryToolResult,
+						ToolName:   call.Name,
+						ToolUseID:  call.ID,
+						ToolOutput: call.ResultDisplay,
+					})
+				}
+			}
+			if len(msgEntries) == 0 {
+				msgEntries = append(msgEntries, agent.SessionEntry{Type: agent.EntryAssistant})
+			}
+		default:
+			msgEntries = append(msgEntries, agent.SessionEntry{Type: agent.EntrySystem, Content: msg.Content})
+		}
+
+		msgEntries[0].Model = msg.Model
+		if msg.Type == MessageTypeGemini && msg.Tokens != nil {
+			msgEntries[0].TokenUsage = &agent.TokenUsage{
+				InputTokens:     msg.Tokens.Input,
+				OutputTokens:    msg.Tokens.Output,
+				CacheReadTokens: msg.Tokens.Cached,
+				APICallCount:    1,
+			}
+		}
+
+		timestamp, _ := time.Parse(time.RFC3339Nano, msg.Timestamp) //nolint:errcheck // Zero time if missing
+		for _, entry := range msgEntries {
+			entry.UUID = msg.ID
+			entry.Position = i
+			entry.Timestamp = timestamp
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// BuildTranscript renders conversation turns, e.g. of another agent's session,
+// as a Gemini JSON session file of sessionID for the project at projectPath.
+func BuildTranscript(sessionID, projectPath string, turns []agent.ConversationTurn) ([]byte, error) {
+	projectHash := sha256.Sum256([]byte(projectPath))
+	file := chatFile{
+		SessionID:   sessionID,
+		ProjectHash: hex.EncodeToString(projectHash[:]),
+		Messages:    make([]chatMessage, 0, len(turns)),
+	}
+	for _, turn := range turns {
+		timestamp := turn.Timestamp
+		if timestamp.IsZero() {
+			timestamp = time.Now()
+		}
+		msg := chatMessage{
+			ID:        uuid.NewString(),
+			Timestamp: timestamp.UTC().Format(time.RFC3339Nano),
+			Type:      MessageTypeGemini,
+			Content:   turn.Content,
+			Model:     turn.Model,
+		}
+		if turn.Type == agent.EntryUser {
+			msg.Type = MessageTypeUser
+			msg.Model = ""
+		}
+		file.Messages = append(file.Messages, msg)
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	file.StartTime, file.LastUpdated = now, now
+	if n := len(file.Messages); n > 0 {
+		file.StartTime, file.LastUpdated = file.Messages[0].Timestamp, file.Messages[n-1].Timestamp
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal session file: %w", err)
+	}
+	return data, nil
+}
@@ -1,8 +1,11 @@
 package geminicli
 
 import (
+	"encoding/json"
 	"os"
 	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
 )
 
 func TestParseTranscript(t *testing.T) {
@@ -669,3 +672,92 @@ func writeTestFile(t *testing.T, path string, data []byte) error {
 	t.Helper()
 	return os.WriteFile(path, data, 0o644)
 }
+
+func TestToSessionEntries(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`{
+  "messages": [
+    {"id": "m1", "timestamp": "2025-10-16T09:00:00.000Z", "type": "user", "content": "Create a file"},
+    {"id": "m2", "type": "gemini", "content": "Creating it", "model": "gemini-2.5-pro",
+     "toolCalls": [{"id": "call_1", "name": "write_file", "args": {"file_path": "a.txt"}, "status": "success", "resultDisplay": "Wrote a.txt"}],
+     "tokens": {"input": 100, "output": 20, "cached": 10}},
+    {"id": "m3", "type": "info", "content": "Request cancelled"},
+    {"id": "m4", "type": "gemini", "content": "", "tokens": {"input": 5, "output": 1}}
+  ]
+}`)
+
+	entries, err := ToSessionEntries(data)
+	if err != nil {
+		t.Fatalf("ToSessionEntries() error = %v", err)
+	}
+
+	wantTypes := []agent.EntryType{agent.EntryUser, agent.EntryAssistant, agent.EntryTool, agent.EntryToolResult, agent.EntrySystem, agent.EntryAssistant}
+	wantPositions := []int{0, 1, 1, 1, 2, 3}
+	if len(entries) != len(wantTypes) {
+		t.Fatalf("got %d entries, want %d: %+v", len(entries), len(wantTypes), entries)
+	}
+	for i := range entries {
+		if entries[i].Type != wantTypes[i] || entries[i].Position != wantPositions[i] {
+			t.Errorf("entry %d = %s at %d, want %s at %d", i, entries[i].Type, entries[i].Position, wantTypes[i], wantPositions[i])
+		}
+	}
+
+	if entries[0].Timestamp.IsZero() || entries[0].UUID != "m1" {
+		t.Errorf("user entry = %+v", entries[0])
+	}
+	if entries[1].Model != "gemini-2.5-pro" {
+		t.Errorf("Model = %q, want gemini-2.5-pro", entries[1].Model)
+	}
+	if tool := entries[2]; tool.ToolUseID != "call_1" || len(tool.FilesAffected) != 1 || tool.FilesAffected[0] != "a.txt" {
+		t.Errorf("tool entry = %+v", tool)
+	}
+
+	usage := agent.SumTokenUsage(entries)
+	if usage.APICallCount != 2 || usage.InputTokens != 105 || usage.OutputTokens != 21 || usage.CacheReadTokens != 10 {
+		t.Errorf("SumTokenUsage() = %+v", usage)
+	}
+	if got := agent.TranscriptLength(entries); got != 4 {
+		t.Errorf("TranscriptLength() = %d, want 4", got)
+	}
+}
+
+func TestBuildTranscript(t *testing.T) {
+	t.Parallel()
+
+	data, err := BuildTranscript("sess-1", "/repo", []agent.ConversationTurn{
+		{Type: agent.EntryUser, Content: "Hello", Model: "ignored"},
+		{Type: agent.EntryAssistant, Content: "Hi there\n\n[Tool call: Bash] ls", Model: "claude-sonnet-4"},
+	})
+	if err != nil {
+		t.Fatalf("BuildTranscript() error = %v", err)
+	}
+
+	var file chatFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatalf("failed to parse session file: %v", err)
+	}
+	if file.SessionID != "sess-1" || file.ProjectHash == "" {
+		t.Errorf("sessionId = %q, projectHash = %q", file.SessionID, file.ProjectHash)
+	}
+	if file.StartTime != file.Messages[0].Timestamp || file.LastUpdated != file.Messages[1].Timestamp {
+		t.Errorf("startTime/lastUpdated = %q/%q, want message timestamps", file.StartTime, file.LastUpdated)
+	}
+	if file.Messages[0].Model != "" {
+		t.Errorf("user message model = %q, want empty", file.Messages[0].Model)
+	}
+
+	entries, err := ToSessionEntries(data)
+	if err != nil {
+		t.Fatalf("ToSessionEntries() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if entries[0].Type != agent.EntryUser || entries[0].Content != "Hello" {
+		t.Errorf("entry 0 = %+v", entries[0])
+	}
+	if entries[1].Type != agent.EntryAssistant || entries[1].Model != "claude-sonnet-4" {
+		t.Errorf("entry 1 = %+v", entries[1])
+	}
+}
@@ -114,3 +114,40 @@ type geminiMessageWithTokens struct {
 	Type   string               `json:"type"`
 	Tokens *geminiMessageTokens `json:"tokens,omitempty"`
 }
+
+// geminiEntryMessage represents a Gemini message with the fields normalized
+// into session entries.
+type geminiEntryMessage struct {
+	ID        string                `json:"id"`
+	Timestamp string                `json:"timestamp"`
+	Type      string                `json:"type"`
+	Content   string                `json:"content"`
+	Model     string                `json:"model"`
+	ToolCalls []geminiEntryToolCall `json:"toolCalls"`
+	Tokens    *geminiMessageTokens  `json:"tokens,omitempty"`
+}
+
+// geminiEntryToolCall represents a tool call with its displayed result.
+type geminiEntryToolCall struct {
+	GeminiToolCall
+
+	ResultDisplay interface{} `json:"resultDisplay,omitempty"`
+}
+
+// chatFile is a session file built from another agent's session,
+// with the fields Gemini CLI needs to resume it.
+type chatFile struct {
+	SessionID   string        `json:"sessionId"`
+	ProjectHash string        `json:"projectHash"`
+	StartTime   string        `json:"startTime"`
+	LastUpdated string        `json:"lastUpdated"`
+	Messages    []chatMessage `json:"messages"`
+}
+
+type chatMessage struct {
+	ID        string `json:"id"`
+	Timestamp string `json:"timestamp"`
+	Type      string `json:"type"`
+	Content   string `json:"content"`
+	Model     string `json:"model,omitempty"`
+}
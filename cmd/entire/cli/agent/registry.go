@@ -3,29 +3,101 @@ package agent
 import (
 	"fmt"
 	"slices"
+	"strings"
 	"sync"
 )
 
 var (
 	registryMu sync.RWMutex
 	registry   = make(map[AgentName]Factory)
+	// pluginNames holds the names of the registered agents found by discoverers
+	pluginNames = make(map[AgentName]bool)
+
+	discoveryMu sync.Mutex
+	discoverers []Discoverer
+	discovered  bool
 )
 
 // Factory creates a new agent instance
 type Factory func() Agent
 
+// Discoverer finds agents that aren't compiled in (e.g. plugin executables)
+// and returns their factories by name.
+type Discoverer func() map[AgentName]Factory
+
+// RegisterDiscoverer adds a discoverer to the registry.
+// Discoverers run once, when the registry is first read, so that looking for
+// agents costs nothing for commands that don't use them.
+func RegisterDiscoverer(d Discoverer) {
+	discoveryMu.Lock()
+	defer discoveryMu.Unlock()
+	discoverers = append(discoverers, d)
+	discovered = false
+}
+
+// discover registers the agents found by the discoverers, unless already done.
+// Registered agents take precedence over discovered agents with the same name.
+func discover() {
+	discoveryMu.Lock()
+	defer discoveryMu.Unlock()
+	if discovered {
+		return
+	}
+	discovered = true
+
+	for _, d := range discoverers {
+		for name, factory := range d() {
+			registryMu.Lock()
+			if _, exists := registry[name]; !exists {
+				registry[name] = factory
+				pluginNames[name] = true
+			}
+			registryMu.Unlock()
+		}
+	}
+}
+
 // Register adds an agent factory to the registry.
 // Called from init() in each agent implementation.
 func Register(name AgentName, factory Factory) {
 	registryMu.Lock()
 	defer registryMu.Unlock()
 	registry[name] = factory
+	delete(pluginNames, name)
+}
+
+// orderedFactories returns the factories of the registered agents by name,
+// compiled-in agents first, so that looking up an agent only starts plugin
+// processes when no compiled-in agent matches.
+// Must be called with registryMu held.
+func orderedFactories() []Factory {
+	names := make([]AgentName, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	slices.SortFunc(names, func(a, b AgentName) int {
+		if pluginNames[a] != pluginNames[b] {
+			if pluginNames[a] {
+				return 1
+			}
+			return -1
+		}
+		return strings.Compare(string(a), string(b))
+	})
+
+	factories := make([]Factory, len(names))
+	for i, name := range names {
+		factories[i] = registry[name]
+	}
+	return factories
 }
 
 // Get retrieves an agent by name.
 //
 
 func Get(name AgentName) (Agent, error) {
+	discover()
+
 	registryMu.RLock()
 	defer registryMu.RUnlock()
 
@@ -38,6 +110,8 @@ func Get(name AgentName) (Agent, error) {
 
 // List returns all registered agent names in sorted order.
 func List() []AgentName {
+	discover()
+
 	registryMu.RLock()
 	defer registryMu.RUnlock()
 
@@ -50,14 +124,17 @@ func List() []AgentName {
 }
 
 // Detect attempts to auto-detect which agent is being used.
-// Checks each registered agent's DetectPresence method.
+// Checks each registered agent's DetectPresence method, compiled-in agents
+// before plugins.
 //
 
 func Detect() (Agent, error) {
+	discover()
+
 	registryMu.RLock()
 	defer registryMu.RUnlock()
 
-	for _, factory := range registry {
+	for _, factory := range orderedFactories() {
 		ag := factory()
 		if present, err := ag.DetectPresence(); err == nil && present {
 			return ag, nil
@@ -80,12 +157,18 @@ type AgentType string
 const (
 	AgentNameClaudeCode AgentName = "claude-code"
 	AgentNameGemini     AgentName = "gemini"
+	AgentNameCursor     AgentName = "cursor"
+	AgentNameAider      AgentName = "aider"
+	AgentNameCodex      AgentName = "codex"
 )
 
 // Agent type constants (type identifiers stored in metadata/trailers)
 const (
 	AgentTypeClaudeCode AgentType = "Claude Code"
 	AgentTypeGemini     AgentType = "Gemini CLI"
+	AgentTypeCursor     AgentType = "Cursor"
+	AgentTypeAider      AgentType = "Aider"
+	AgentTypeCodex      AgentType = "Codex"
 	AgentTypeUnknown    AgentType = "Agent" // Fallback for backwards compatibility
 )
 
@@ -97,16 +180,19 @@ const DefaultAgentName AgentName = AgentNameClaudeCode
 // Note: This uses a linear search that instantiates agents until a match is found.
 // This is acceptable because:
 //   - Agent count is small (~2-20 agents)
-//   - Agent factories are lightweight (empty struct allocation)
+//   - Compiled-in agent factories are lightweight (empty struct allocation)
+//     and are checked first; a plugin's Type runs the plugin (once per process),
+//     so plugins are only asked when no compiled-in agent matches
 //   - Called infrequently (commit hooks, rewind, debug commands - not hot paths)
-//   - Cost is ~400ns worst case vs milliseconds for I/O operations
 //
 // Only optimize if agent count exceeds 100 or profiling shows this as a bottleneck.
 func GetByAgentType(agentType AgentType) (Agent, error) {
+	discover()
+
 	registryMu.RLock()
 	defer registryMu.RUnlock()
 
-	for _, factory := range registry {
+	for _, factory := range orderedFactories() {
 		ag := factory()
 		if ag.Type() == agentType {
 			return ag, nil
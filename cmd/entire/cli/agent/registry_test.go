@@ -178,3 +178,143 @@ func TestDefault(t *testing.T) {
 		t.Error("expected non-nil agent after registering default")
 	}
 }
+
+func TestRegisterDiscoverer(t *testing.T) {
+	originalRegistry := make(map[AgentName]Factory)
+	registryMu.Lock()
+	for k, v := range registry {
+		originalRegistry[k] = v
+	}
+	registry = make(map[AgentName]Factory)
+	registryMu.Unlock()
+
+	discoveryMu.Lock()
+	originalDiscoverers := discoverers
+	discoverers = nil
+	discoveryMu.Unlock()
+
+	defer func() {
+		registryMu.Lock()
+		registry = originalRegistry
+		pluginNames = make(map[AgentName]bool)
+		registryMu.Unlock()
+		discoveryMu.Lock()
+		discoverers = originalDiscoverers
+		discovered = false
+		discoveryMu.Unlock()
+	}()
+
+	Register(AgentName("builtin"), func() Agent { return &mockAgent{} })
+
+	calls := 0
+	RegisterDiscoverer(func() map[AgentName]Factory {
+		calls++
+		return map[AgentName]Factory{
+			"plugin":  func() Agent { return &detectableAgent{} },
+			"builtin": func() Agent { return &detectableAgent{} },
+		}
+	})
+
+	names := List()
+	if len(names) != 2 || names[0] != "builtin" || names[1] != "plugin" {
+		t.Errorf("List() = %v, want [builtin plugin]", names)
+	}
+
+	// Registered agents take precedence over discovered ones
+	ag, err := Get("builtin")
+	if err != nil {
+		t.Fatalf("Get(builtin) error = %v", err)
+	}
+	if ag.Name() != mockAgentName {
+		t.Errorf("Get(builtin) returned the discovered agent %q", ag.Name())
+	}
+	if _, err := Get("plugin"); err != nil {
+		t.Errorf("Get(plugin) error = %v", err)
+	}
+
+	// Discovery runs once
+	List()
+	if calls != 1 {
+		t.Errorf("discoverer called %d times, want 1", calls)
+	}
+}
+
+// pluginAgent is a mock of a discovered plugin that counts the calls that
+// would start its process.
+type pluginAgent struct {
+	mockAgent
+	calls *int
+}
+
+func (p *pluginAgent) Type() AgentType {
+	*p.calls++
+	return "Plugin Agent"
+}
+
+func (p *pluginAgent) DetectPresence() (bool, error) {
+	*p.calls++
+	return true, nil
+}
+
+func TestLookup_BuiltinsBeforePlugins(t *testing.T) {
+	originalRegistry := make(map[AgentName]Factory)
+	registryMu.Lock()
+	for k, v := range registry {
+		originalRegistry[k] = v
+	}
+	registry = make(map[AgentName]Factory)
+	registryMu.Unlock()
+
+	discoveryMu.Lock()
+	originalDiscoverers := discoverers
+	discoverers = nil
+	discoveryMu.Unlock()
+
+	defer func() {
+		registryMu.Lock()
+		registry = originalRegistry
+		pluginNames = make(map[AgentName]bool)
+		registryMu.Unlock()
+		discoveryMu.Lock()
+		discoverers = originalDiscoverers
+		discovered = false
+		discoveryMu.Unlock()
+	}()
+
+	calls := 0
+	// Named to sort before the compiled-in agents
+	RegisterDiscoverer(func() map[AgentName]Factory {
+		return map[AgentName]Factory{
+			"a-plugin": func() Agent { return &pluginAgent{calls: &calls} },
+		}
+	})
+	Register(AgentName("builtin"), func() Agent { return &mockAgent{} })
+	Register(AgentName("detected"), func() Agent { return &detectableAgent{} })
+
+	ag, err := GetByAgentType(mockAgentType)
+	if err != nil {
+		t.Fatalf("GetByAgentType() error = %v", err)
+	}
+	if ag.Name() != mockAgentName {
+		t.Errorf("GetByAgentType() = %q, want %q", ag.Name(), mockAgentName)
+	}
+	ag, err = Detect()
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if ag.Name() != "detectable" {
+		t.Errorf("Detect() = %q, want detectable", ag.Name())
+	}
+	if calls != 0 {
+		t.Errorf("plugin called %d times, want 0", calls)
+	}
+
+	// Plugins are asked when no compiled-in agent matches
+	ag, err = GetByAgentType("Plugin Agent")
+	if err != nil {
+		t.Fatalf("GetByAgentType(plugin) error = %v", err)
+	}
+	if _, ok := ag.(*pluginAgent); !ok {
+		t.Errorf("GetByAgentType(plugin) = %T, want the plugin", ag)
+	}
+}
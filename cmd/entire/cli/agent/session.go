@@ -6,9 +6,10 @@ import "time"
 // Each agent stores data in its native format (JSONL, SQLite, Markdown, etc.)
 // and only the originating agent can read/write it.
 //
-// Design: Sessions are NOT interoperable between agents. A session created by
-// Claude Code can only be read/written by Claude Code. This simplifies the
-// implementation as we don't need format conversion.
+// Design: NativeData is NOT interoperable between agents. A session created by
+// Claude Code can only be read/written by Claude Code. Sessions move between
+// agents through Entries instead: ConvertSession parses them with the source
+// agent and rebuilds native data with the target's TranscriptBuilder.
 //
 //nolint:revive // AgentSession is clearer than Session in context of the package
 type AgentSession struct {
@@ -31,20 +32,37 @@ type AgentSession struct {
 	NewFiles      []string
 	DeletedFiles  []string
 
-	// Optional normalized entries - agents may populate this if needed
-	// for operations that benefit from structured access
+	// Entries is the transcript normalized into agent-agnostic entries, in
+	// transcript order. Explain, summaries, prompt extraction and token
+	// accounting work from entries rather than from NativeData.
 	Entries []SessionEntry
 }
 
 // SessionEntry represents a single entry in the session
 type SessionEntry struct {
+	// UUID identifies the transcript item the entry comes from. Entries from
+	// the same item (e.g. a message with text and tool calls) share it.
 	UUID      string
 	Type      EntryType
 	Timestamp time.Time
 	Content   string
 
+	// Position is the transcript position of the item the entry comes from,
+	// in the agent's TranscriptAnalyzer units (line, message or entry index).
+	// Several entries may share a position.
+	Position int
+
+	// Model is the model that produced an assistant entry, if recorded
+	Model string
+
+	// TokenUsage is the usage of the API call that produced the entry.
+	// Agents set it on one entry per call, so usage can be summed over entries.
+	TokenUsage *TokenUsage
+
 	// Tool-specific fields
-	ToolName      string
+	ToolName string
+	// ToolUseID links a tool call to its result
+	ToolUseID     string
 	ToolInput     interface{}
 	ToolOutput    interface{}
 	FilesAffected []string
@@ -56,8 +74,11 @@ type EntryType string
 const (
 	EntryUser      EntryType = "user"
 	EntryAssistant EntryType = "assistant"
-	EntryTool      EntryType = "tool"
-	EntrySystem    EntryType = "system"
+	// EntryTool is a tool call; its ToolInput holds the arguments
+	EntryTool EntryType = "tool"
+	// EntryToolResult is the result of a tool call; its ToolOutput holds the output
+	EntryToolResult EntryType = "tool_result"
+	EntrySystem     EntryType = "system"
 )
 
 // GetLastUserPrompt returns the last user message content
@@ -120,7 +141,7 @@ func (s *AgentSession) TruncateAtUUID(uuid string) *AgentSession {
 // for the given tool use ID. Returns the UUID and true if found.
 func (s *AgentSession) FindToolResultUUID(toolUseID string) (string, bool) {
 	for _, entry := range s.Entries {
-		if entry.Type == EntryTool && entry.UUID == toolUseID {
+		if entry.Type == EntryToolResult && entry.ToolUseID == toolUseID {
 			return entry.UUID, true
 		}
 	}
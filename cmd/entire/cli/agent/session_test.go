@@ -215,19 +215,19 @@ func TestFindToolResultUUID(t *testing.T) {
 	session := &AgentSession{
 		Entries: []SessionEntry{
 			{UUID: "user-1", Type: EntryUser},
-			{UUID: "tool-1", Type: EntryTool},
-			{UUID: "assistant-1", Type: EntryAssistant},
-			{UUID: "tool-2", Type: EntryTool},
+			{UUID: "assistant-1", Type: EntryTool, ToolUseID: "tool-1"},
+			{UUID: "result-1", Type: EntryToolResult, ToolUseID: "tool-1"},
+			{UUID: "assistant-2", Type: EntryAssistant},
 		},
 	}
 
-	t.Run("finds existing tool uuid", func(t *testing.T) {
+	t.Run("finds result of tool call", func(t *testing.T) {
 		uuid, found := session.FindToolResultUUID("tool-1")
 		if !found {
-			t.Error("expected to find tool-1")
+			t.Error("expected to find result of tool-1")
 		}
-		if uuid != "tool-1" {
-			t.Errorf("expected uuid %q, got %q", "tool-1", uuid)
+		if uuid != "result-1" {
+			t.Errorf("expected uuid %q, got %q", "result-1", uuid)
 		}
 	})
 
@@ -0,0 +1,322 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/cobra"
+)
+
+// checkpointTranscript is the session transcript of a checkpoint, as it was
+// at that checkpoint.
+type checkpointTranscript struct {
+	SessionID string
+	Agent     agent.AgentType
+	Content   []byte
+}
+
+func newCheckoutCmd() *cobra.Command {
+	var worktreeFlag string
+
+	cmd := &cobra.Command{
+		Use:   "checkout <checkpoint>",
+		Short: "Check out a checkpoint into a new git worktree",
+		Long: `Check out the code of a checkpoint into a new, detached git worktree, leaving
+the current working copy untouched.
+
+The checkpoint can be a temporary checkpoint (shadow branch commit SHA or
+prefix, as listed by 'entire rewind --list'), a committed checkpoint ID, or a
+commit. For a temporary checkpoint, the worktree is detached at the commit the
+session started from and the checkpoint's files are restored on top, as a
+rewind would. For a committed checkpoint, it is detached at the commit that
+references it.
+
+The session transcript, as it was at the checkpoint, is written to the agent's
+session directory for the new worktree, and the command to resume the session
+there is printed.
+
+The worktree is created next to the repository unless --worktree is given.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if checkDisabledGuard(cmd.OutOrStdout()) {
+				return nil
+			}
+			return runCheckout(context.Background(), cmd.OutOrStdout(), cmd.ErrOrStderr(), args[0], worktreeFlag)
+		},
+	}
+
+	cmd.Flags().StringVar(&worktreeFlag, "worktree", "", "Directory to create the worktree in")
+
+	return cmd
+}
+
+func runCheckout(ctx context.Context, w, errW io.Writer, ref, dir string) error {
+	repo, err := openRepository()
+	if err != nil {
+		return fmt.Errorf("not a git repository: %w", err)
+	}
+	store := checkpoint.NewGitStore(repo)
+
+	target, err := resolveDiffSide(ctx, repo, store, ref)
+	if err != nil {
+		return err
+	}
+
+	if dir == "" {
+		dir, err = defaultWorktreeDir(target.Commit[:7])
+		if err != nil {
+			return err
+		}
+	}
+	dir, _, err = addCheckpointWorktree(ctx, repo, store, target, dir)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Checked out %s into %s\n", ref, dir)
+
+	transcript, err := readCheckpointTranscript(ctx, repo, store, target)
+	if err != nil {
+		fmt.Fprintf(errW, "Warning: failed to read the session transcript: %v\n", err)
+		return nil
+	}
+	if transcript == nil {
+		fmt.Fprintln(w, "No session transcript for this checkpoint; only the code was checked out.")
+		return nil
+	}
+
+	ag := checkpointAgent(transcript.Agent)
+	if ag == nil {
+		fmt.Fprintln(errW, "Warning: no agent available to restore the session transcript")
+		return nil
+	}
+	sessionFile, err := writeTranscriptForWorktree(dir, transcript.Content, transcript.SessionID, ag)
+	if err != nil {
+		fmt.Fprintf(errW, "Warning: failed to restore the session transcript: %v\n", err)
+		return nil
+	}
+	if sessionFile != "" {
+		fmt.Fprintf(errW, "Writing transcript to: %s\n", sessionFile)
+	}
+	fmt.Fprintf(w, "\nTo continue the session there, run:\n  cd %s && %s\n", dir, formatResumeCommand(transcript.SessionID, ag))
+	return nil
+}
+
+// defaultWorktreeDir returns the directory next to the repository that a
+// worktree is created in by default: <repo>-<suffix>.
+func defaultWorktreeDir(suffix string) (string, error) {
+	repoRoot, err := paths.RepoRoot()
+	if err != nil {
+		return "", fmt.Errorf("failed to get repository root: %w", err)
+	}
+	return filepath.Join(filepath.Dir(repoRoot), filepath.Base(repoRoot)+"-"+suffix), nil
+}
+
+// addCheckpointWorktree creates a detached git worktree at dir with the code of
+// a checkpoint. Temporary checkpoints are restored on top of the commit the
+// session started from. Returns the absolute worktree directory and the commit
+// it is detached at.
+func addCheckpointWorktree(ctx context.Context, repo *git.Repository, store *checkpoint.GitStore, target *diffSide, dir string) (string, string, error) {
+	baseCommit := target.Commit
+	if target.Kind == diffKindTemporary {
+		var err error
+		baseCommit, err = shadowCheckpointBase(ctx, repo, store, plumbing.NewHash(target.Commit))
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve worktree directory: %w", err)
+	}
+	if err := AddDetachedWorktree(dir, baseCommit); err != nil {
+		return "", "", err
+	}
+	if target.Kind == diffKindTemporary {
+		if err := RestoreWorktreeFiles(dir, target.Commit); err != nil {
+			return "", "", fmt.Errorf("failed to restore checkpoint files in %s: %w", dir, err)
+		}
+	}
+	return dir, baseCommit, nil
+}
+
+// shadowCheckpointBase returns the commit the shadow branch of a temporary
+// checkpoint is based on.
+func shadowCheckpointBase(ctx context.Context, repo *git.Repository, store *checkpoint.GitStore, checkpointHash plumbing.Hash) (string, error) {
+	branches, err := store.ListTemporary(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to list shadow branches: %w", err)
+	}
+	for _, branch := range branches {
+		checkpoints, err := store.ListCheckpointsForBranch(ctx, branch.BranchName, "", branchCheckpointsLimit)
+		if err != nil {
+			continue
+		}
+		for _, cp := range checkpoints {
+			if cp.CommitHash != checkpointHash {
+				continue
+			}
+			hash, err := repo.ResolveRevision(plumbing.Revision(branch.BaseCommit))
+			if err != nil {
+				return "", fmt.Errorf("base commit %s of %s not found: %w", branch.BaseCommit, branch.BranchName, err)
+			}
+			return hash.String(), nil
+		}
+	}
+	return "", fmt.Errorf("no shadow branch contains checkpoint %s", checkpointHash.String()[:7])
+}
+
+// readCheckpointTranscript returns the transcript of the session of a
+// checkpoint as it was at that checkpoint, or nil if it has none. Task
+// checkpoints are truncated at the task; committed checkpoints use the latest
+// session.
+func readCheckpointTranscript(ctx context.Context, repo *git.Repository, store *checkpoint.GitStore, target *diffSide) (*checkpointTranscript, error) {
+	commit, err := repo.CommitObject(plumbing.NewHash(target.Commit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit: %w", err)
+	}
+
+	if target.Kind == diffKindTemporary {
+		sessionID, found := trailers.ParseSession(commit.Message)
+		if !found {
+			return nil, nil //nolint:nilnil // A shadow commit without a session has no transcript
+		}
+		transcript := &checkpointTranscript{SessionID: sessionID}
+		if state, err := strategy.LoadSessionState(sessionID); err == nil && state != nil {
+			transcript.Agent = state.AgentType
+		}
+
+		if taskMetadataDir, isTask := trailers.ParseTaskMetadata(commit.Message); isTask {
+			transcript.Content, err = readTaskCheckpointTranscript(commit.Hash, taskMetadataDir)
+		} else {
+			metadataDir, ok := trailers.ParseMetadata(commit.Message)
+			if !ok {
+				metadataDir = paths.SessionMetadataDirFromSessionID(sessionID)
+			}
+			transcript.Content, err = store.GetTranscriptFromCommit(commit.Hash, metadataDir, transcript.Agent)
+		}
+		if errors.Is(err, checkpoint.ErrNoTranscript) {
+			return nil, nil //nolint:nilnil // No transcript is an expected case
+		}
+		if err != nil {
+			return nil, err
+		}
+		return transcript, nil
+	}
+
+	cpID := target.CheckpointID
+	if cpID.IsEmpty() {
+		linked, found := store.CheckpointIDForCommit(ctx, commit)
+		if !found {
+			return nil, nil //nolint:nilnil // Commits without a checkpoint have no transcript
+		}
+		cpID = linked
+	}
+	return readCommittedTranscript(ctx, store, cpID)
+}
+
+func readCommittedTranscript(ctx context.Context, store *checkpoint.GitStore, cpID id.CheckpointID) (*checkpointTranscript, error) {
+	content, err := store.ReadLatestSessionContent(ctx, cpID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint %s: %w", cpID, err)
+	}
+	if len(content.Transcript) == 0 {
+		return nil, nil //nolint:nilnil // No transcript is an expected case
+	}
+	return &checkpointTranscript{
+		SessionID: content.Metadata.SessionID,
+		Agent:     content.Metadata.Agent,
+		Content:   content.Transcript,
+	}, nil
+}
+
+// readTaskCheckpointTranscript returns the session transcript of a task
+// checkpoint, truncated at the task.
+func readTaskCheckpointTranscript(commitHash plumbing.Hash, taskMetadataDir string) ([]byte, error) {
+	strat := GetStrategy()
+	point := strategy.RewindPoint{ID: commitHash.String(), MetadataDir: taskMetadataDir, IsTaskCheckpoint: true}
+	task, err := strat.GetTaskCheckpoint(point)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read task checkpoint: %w", err)
+	}
+	content, err := strat.GetTaskCheckpointTranscript(point)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task checkpoint transcript: %w", err)
+	}
+	lines, err := parseTranscriptFromBytes(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	for _, line := range TruncateTranscriptAtUUID(lines, task.CheckpointUUID) {
+		data, err := json.Marshal(line)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal line: %w", err)
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// checkpointAgent returns the agent of a checkpoint, or the detected or
+// default agent if it is unknown.
+func checkpointAgent(agentType agent.AgentType) agent.Agent {
+	if agentType != "" {
+		if ag, err := agent.GetByAgentType(agentType); err == nil {
+			return ag
+		}
+	}
+	if ag, err := agent.Detect(); err == nil {
+		return ag
+	}
+	return agent.Default()
+}
+
+// writeTranscriptForWorktree restores a transcript with the agent's WriteSession,
+// where the agent looks for the sessions of the worktree at dir. Returns the
+// path of the session file, or "" for agents that don't keep a session in a
+// file of its own (e.g. Cursor's state database or Aider's chat history).
+func writeTranscriptForWorktree(dir string, content []byte, sessionID string, ag agent.Agent) (string, error) {
+	sessionDir, err := ag.GetSessionDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to get agent session directory: %w", err)
+	}
+	if err := os.MkdirAll(sessionDir, 0o750); err != nil {
+		return "", fmt.Errorf("failed to create agent session directory: %w", err)
+	}
+
+	agentSessionID := ag.ExtractAgentSessionID(sessionID)
+	var sessionFile string
+	sessionRef := filepath.Join(sessionDir, agentSessionID+".jsonl")
+	if builder, ok := ag.(agent.TranscriptBuilder); ok {
+		sessionFile = builder.ResolveSessionFile(sessionDir, agentSessionID)
+		sessionRef = sessionFile
+	}
+	if err := ag.WriteSession(&agent.AgentSession{
+		SessionID:  agentSessionID,
+		AgentName:  ag.Name(),
+		RepoPath:   dir,
+		SessionRef: sessionRef,
+		NativeData: content,
+	}); err != nil {
+		return "", fmt.Errorf("failed to write session: %w", err)
+	}
+	return sessionFile, nil
+}
@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/agent/aider"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+// writeAgentCheckpoint commits a committed checkpoint of a session of another agent.
+func writeAgentCheckpoint(t *testing.T, cpID id.CheckpointID, agentType agent.AgentType, transcript string) {
+	t.Helper()
+	repo, err := git.PlainOpen(".")
+	require.NoError(t, err)
+	require.NoError(t, checkpoint.NewGitStore(repo).WriteCommitted(context.Background(), checkpoint.WriteCommittedOptions{
+		CheckpointID:     cpID,
+		SessionID:        "session-2",
+		Strategy:         "manual-commit",
+		Agent:            agentType,
+		Transcript:       []byte(transcript),
+		CheckpointsCount: 1,
+		AuthorName:       "Test",
+		AuthorEmail:      "test@test.com",
+	}))
+
+	require.NoError(t, os.WriteFile("c.txt", []byte(agentType+"\n"), 0o644))
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = wt.Add("c.txt")
+	require.NoError(t, err)
+	_, err = wt.Commit(trailers.FormatCheckpoint("third", cpID), &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@test.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+}
+
+func TestRunCheckout_TemporaryCheckpoint(t *testing.T) {
+	tr := setupDiffTestRepo(t)
+	projectDir := t.TempDir()
+	t.Setenv("ENTIRE_TEST_CLAUDE_PROJECT_DIR", projectDir)
+	dir := filepath.Join(t.TempDir(), "wt")
+
+	var out bytes.Buffer
+	require.NoError(t, runCheckout(context.Background(), &out, &bytes.Buffer{}, tr.shadow.String()[:7], dir))
+
+	// The worktree is detached at the session's base commit with the checkpoint's files on top
+	wtRepo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	head, err := wtRepo.Head()
+	require.NoError(t, err)
+	assert.Equal(t, tr.first, head.Hash())
+	assert.Equal(t, "two\n", readTestFile(t, filepath.Join(dir, "a.txt")))
+	assert.NoDirExists(t, filepath.Join(dir, ".entire", "metadata"))
+
+	// The main working copy is untouched
+	assert.Equal(t, "three\n", readTestFile(t, filepath.Join(tr.dir, "a.txt")))
+
+	assert.Equal(t, `{"type":"user"}`+"\n", readTestFile(t, filepath.Join(projectDir, "session-1.jsonl")))
+	assert.Contains(t, out.String(), "cd "+dir+" && ")
+}
+
+func TestRunCheckout_CommittedCheckpoint(t *testing.T) {
+	tr := setupDiffTestRepo(t)
+	projectDir := t.TempDir()
+	t.Setenv("ENTIRE_TEST_CLAUDE_PROJECT_DIR", projectDir)
+	dir := filepath.Join(t.TempDir(), "wt")
+
+	var out bytes.Buffer
+	require.NoError(t, runCheckout(context.Background(), &out, &bytes.Buffer{}, tr.checkpointID.String(), dir))
+
+	wtRepo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	head, err := wtRepo.Head()
+	require.NoError(t, err)
+	assert.Equal(t, tr.second, head.Hash())
+	assert.Equal(t, "new\n", readTestFile(t, filepath.Join(dir, "b.txt")))
+	assert.FileExists(t, filepath.Join(projectDir, "session-1.jsonl"))
+}
+
+func TestRunCheckout_CommitWithoutCheckpoint(t *testing.T) {
+	tr := setupDiffTestRepo(t)
+	t.Setenv("ENTIRE_TEST_CLAUDE_PROJECT_DIR", t.TempDir())
+	dir := filepath.Join(t.TempDir(), "wt")
+
+	var out bytes.Buffer
+	require.NoError(t, runCheckout(context.Background(), &out, &bytes.Buffer{}, tr.first.String(), dir))
+
+	assert.Equal(t, "one\n", readTestFile(t, filepath.Join(dir, "a.txt")))
+	assert.Contains(t, out.String(), "No session transcript")
+}
+
+func TestRunCheckout_AgentWithoutSessionFiles(t *testing.T) {
+	setupDiffTestRepo(t)
+	cpID := id.MustCheckpointID("a1d2a1d2a1d2")
+	history := "# aider chat started at 2026-01-02 10:00:00\n\n#### hello\n\nHi!\n"
+	writeAgentCheckpoint(t, cpID, agent.AgentTypeAider, history)
+	dir := filepath.Join(t.TempDir(), "wt")
+
+	var out, errOut bytes.Buffer
+	require.NoError(t, runCheckout(context.Background(), &out, &errOut, cpID.String(), dir))
+
+	// The session is restored into the worktree's chat history, not a JSONL file
+	assert.Equal(t, history, readTestFile(t, filepath.Join(dir, aider.ChatHistoryFileName)))
+	matches, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+	assert.NotContains(t, errOut.String(), "Warning")
+	assert.Contains(t, out.String(), "aider --restore-chat-history")
+}
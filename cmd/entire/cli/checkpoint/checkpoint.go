@@ -276,6 +276,26 @@ type WriteCommittedOptions struct {
 	//   - the transcript was empty or too short to summarize
 	//   - the checkpoint predates the summarization feature
 	Summary *Summary
+
+	// TranscriptCompression compresses the transcript and subagent transcripts
+	// (CompressionZstd, CompressionGzip); CompressionNone stores them raw
+	TranscriptCompression string
+
+	// EncryptionRecipients are age X25519 recipients (age1...) the transcript,
+	// subagent transcripts, prompts and context are encrypted to; metadata
+	// stays readable. Empty stores them unencrypted.
+	EncryptionRecipients []string
+
+	// TranscriptDelta stores only the transcript lines appended since
+	// PreviousCheckpointID, the session's previous checkpoint, when that
+	// checkpoint's transcript is a prefix of this one (see TranscriptDelta)
+	TranscriptDelta      bool
+	PreviousCheckpointID id.CheckpointID
+
+	// ParentSessionID and ParentCheckpoint link a forked session to the
+	// session and checkpoint it was forked from (see WriteForkPoint)
+	ParentSessionID  string
+	ParentCheckpoint string
 }
 
 // CommittedInfo contains summary information about a committed checkpoint.
@@ -307,6 +327,12 @@ type CommittedInfo struct {
 	// Multi-session support
 	SessionCount int      // Number of sessions (1 if single session)
 	SessionIDs   []string // All session IDs that contributed
+
+	// Branch is the branch where the checkpoint was created (empty if detached HEAD)
+	Branch string
+
+	// Commits are the commit SHAs linked to the checkpoint by commit links (see CommitLink)
+	Commits []string
 }
 
 // SessionContent contains the actual content for a session.
@@ -359,6 +385,28 @@ type CommittedMetadata struct {
 
 	// InitialAttribution is line-level attribution calculated at commit time
 	InitialAttribution *InitialAttribution `json:"initial_attribution,omitempty"`
+
+	// TranscriptCompression is the format the transcript and subagent transcripts
+	// are compressed with ("zstd", "gzip"); empty for uncompressed checkpoints
+	TranscriptCompression string `json:"transcript_compression,omitempty"`
+
+	// Encryption is the format the transcripts, prompts and context are
+	// encrypted with ("age"); empty for unencrypted checkpoints
+	Encryption string `json:"encryption,omitempty"`
+
+	// TranscriptDelta is set if the stored transcript only holds the lines
+	// appended since a previous checkpoint of the session
+	TranscriptDelta *TranscriptDelta `json:"transcript_delta,omitempty"`
+
+	// TranscriptPruned is set when `entire prune` removed the transcripts of
+	// this checkpoint under the retention policy
+	TranscriptPruned bool `json:"transcript_pruned,omitempty"`
+
+	// ParentSessionID is the session this session was forked from, and
+	// ParentCheckpoint the checkpoint it was forked at: a checkpoint ID, or the
+	// shadow commit SHA of a temporary checkpoint
+	ParentSessionID  string `json:"parent_session_id,omitempty"`
+	ParentCheckpoint string `json:"parent_checkpoint,omitempty"`
 }
 
 // GetTranscriptStart returns the transcript line offset at which this checkpoint's data begins.
@@ -539,6 +587,92 @@ type WriteTemporaryTaskOptions struct {
 	IncrementalData []byte
 }
 
+// WriteRewindSnapshotOptions contains options for writing a pre-rewind snapshot.
+type WriteRewindSnapshotOptions struct {
+	// BaseCommit is the commit the working tree is on (HEAD)
+	BaseCommit string
+
+	// WorktreeID is the internal git worktree identifier (empty for main worktree)
+	WorktreeID string
+
+	// Target is the ID of the rewind point about to be restored
+	Target string
+
+	// SessionIDs are the sessions whose transcripts the rewind may change (may be empty)
+	SessionIDs []string
+
+	// Transcripts are the live transcripts by session ID. Sessions without a
+	// transcript file are omitted.
+	Transcripts map[string][]byte
+
+	// Agent is the agent of the sessions, if known
+	Agent agent.AgentType
+
+	// CommitMessage is the commit subject line
+	CommitMessage string
+
+	// AuthorName is the name to use for commits
+	AuthorName string
+
+	// AuthorEmail is the email to use for commits
+	AuthorEmail string
+}
+
+// WriteForkPointOptions contains options for starting the shadow branch of a
+// forked session.
+type WriteForkPointOptions struct {
+	// BaseCommit is the commit the fork's worktree is on (HEAD)
+	BaseCommit string
+
+	// WorktreeID is the internal git worktree identifier of the fork's worktree
+	WorktreeID string
+
+	// SessionID is the forked session
+	SessionID string
+
+	// Tree is the code the session was forked at. Infrastructure paths in it
+	// (such as the parent's session metadata) are left out.
+	Tree plumbing.Hash
+
+	// Transcript is the forked session's transcript
+	Transcript []byte
+
+	// ParentSessionID and ParentCheckpoint are where the session was forked from
+	ParentSessionID  string
+	ParentCheckpoint string
+
+	// AuthorName is the name to use for commits
+	AuthorName string
+
+	// AuthorEmail is the email to use for commits
+	AuthorEmail string
+}
+
+// RewindSnapshotInfo describes the snapshot taken before the last rewind.
+type RewindSnapshotInfo struct {
+	// CommitHash is the hash of the snapshot commit
+	CommitHash plumbing.Hash
+
+	// ShadowTip is the tip of the shadow branch when the snapshot was taken
+	// (ZeroHash if there was no shadow branch)
+	ShadowTip plumbing.Hash
+
+	// Message is the first line of the commit message
+	Message string
+
+	// Target is the ID of the rewind point that was restored
+	Target string
+
+	// SessionIDs are the sessions whose transcripts were saved
+	SessionIDs []string
+
+	// Agent is the agent of the sessions, if known
+	Agent agent.AgentType
+
+	// Timestamp is when the snapshot was taken
+	Timestamp time.Time
+}
+
 // TemporaryCheckpointInfo contains information about a single commit on a shadow branch.
 // Used by ListTemporaryCheckpoints to provide rewind point data.
 type TemporaryCheckpointInfo struct {
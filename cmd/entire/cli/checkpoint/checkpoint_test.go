@@ -76,7 +76,7 @@ func TestCopyMetadataDir_SkipsSymlinks(t *testing.T) {
 	store := NewGitStore(repo)
 	entries := make(map[string]object.TreeEntry)
 
-	err = store.copyMetadataDir(metadataDir, "checkpoint/", entries)
+	err = store.copyMetadataDir(metadataDir, "checkpoint/", entries, CompressionNone, nil)
 	if err != nil {
 		t.Fatalf("copyMetadataDir failed: %v", err)
 	}
@@ -619,7 +619,7 @@ func TestUpdateSummary(t *testing.T) {
 		OpenItems: []string{"Open item 1"},
 	}
 
-	err = store.UpdateSummary(context.Background(), checkpointID, summary)
+	err = store.UpdateSummary(context.Background(), checkpointID, summary, nil)
 	if err != nil {
 		t.Fatalf("UpdateSummary() error = %v", err)
 	}
@@ -667,7 +667,7 @@ func TestUpdateSummary_NotFound(t *testing.T) {
 	checkpointID := id.MustCheckpointID("000000000000")
 	summary := &Summary{Intent: "Test", Outcome: "Test"}
 
-	err = store.UpdateSummary(context.Background(), checkpointID, summary)
+	err = store.UpdateSummary(context.Background(), checkpointID, summary, nil)
 	if err == nil {
 		t.Error("UpdateSummary() should return error for non-existent checkpoint")
 	}
@@ -1428,6 +1428,40 @@ func TestWriteCommitted_SessionWithSummary(t *testing.T) {
 	}
 }
 
+// TestWriteCommitted_ForkedSession verifies that the session and checkpoint a
+// session was forked from are stored in its metadata.
+func TestWriteCommitted_ForkedSession(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	checkpointID := id.MustCheckpointID("f0f1f2f3f4f5")
+
+	err := store.WriteCommitted(context.Background(), WriteCommittedOptions{
+		CheckpointID:     checkpointID,
+		SessionID:        "forked-session",
+		Strategy:         "manual-commit",
+		Transcript:       []byte(`{"test": true}`),
+		CheckpointsCount: 1,
+		AuthorName:       "Test Author",
+		AuthorEmail:      "test@example.com",
+		ParentSessionID:  "parent-session",
+		ParentCheckpoint: "a1b2c3d4e5f6",
+	})
+	if err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+
+	content, err := store.ReadSessionContent(context.Background(), checkpointID, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionContent() error = %v", err)
+	}
+	if content.Metadata.ParentSessionID != "parent-session" {
+		t.Errorf("ParentSessionID = %q, want %q", content.Metadata.ParentSessionID, "parent-session")
+	}
+	if content.Metadata.ParentCheckpoint != "a1b2c3d4e5f6" {
+		t.Errorf("ParentCheckpoint = %q, want %q", content.Metadata.ParentCheckpoint, "a1b2c3d4e5f6")
+	}
+}
+
 // TestWriteCommitted_SessionWithNoContext verifies that a session can be
 // written without context and still be read correctly.
 func TestWriteCommitted_SessionWithNoContext(t *testing.T) {
@@ -2819,7 +2853,7 @@ func TestCopyMetadataDir_RedactsSecrets(t *testing.T) {
 	store := NewGitStore(repo)
 	entries := make(map[string]object.TreeEntry)
 
-	if err := store.copyMetadataDir(metadataDir, "cp/", entries); err != nil {
+	if err := store.copyMetadataDir(metadataDir, "cp/", entries, CompressionNone, nil); err != nil {
 		t.Fatalf("copyMetadataDir() error = %v", err)
 	}
 
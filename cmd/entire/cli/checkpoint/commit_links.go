@@ -0,0 +1,201 @@
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// CommitLink links a code commit to a checkpoint without relying on the commit's
+// Entire-Checkpoint trailer. Links are recorded by the post-rewrite hook when git
+// rewrites a linked commit (amend, rebase) and the new commit no longer carries the trailer.
+type CommitLink struct {
+	// CommitSHA is the full SHA of the rewritten (new) commit
+	CommitSHA string `json:"commit_sha"`
+
+	// CheckpointID is the checkpoint the original commit was linked to
+	CheckpointID id.CheckpointID `json:"checkpoint_id"`
+
+	// OriginalCommit is the full SHA of the commit that was rewritten
+	OriginalCommit string `json:"original_commit"`
+
+	// RewriteType is the git operation that rewrote the commit ("amend" or "rebase")
+	RewriteType string `json:"rewrite_type"`
+
+	// RecordedAt is when the link was recorded
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// commitLinkPath returns the sharded path of a commit link on the metadata branch.
+// Example: "a3b2c4..." -> "commits/a3/b2c4....json"
+func commitLinkPath(commitSHA string) string {
+	return paths.CommitLinksDir + "/" + commitSHA[:2] + "/" + commitSHA[2:] + ".json"
+}
+
+// isFullCommitSHA reports whether s is a 40-char lowercase hex SHA.
+func isFullCommitSHA(s string) bool {
+	if len(s) != 40 {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteCommitLinks records commit → checkpoint links on the entire/checkpoints/v1 branch
+// in a single metadata commit. Existing links for the same commit SHA are overwritten.
+func (s *GitStore) WriteCommitLinks(ctx context.Context, links []CommitLink) error {
+	if err := s.writeCommitLinks(ctx, links); err != nil {
+		return err
+	}
+	s.updateCheckpointIndex()
+	return nil
+}
+
+// writeCommitLinks does the work of WriteCommitLinks, before the checkpoint index is updated.
+func (s *GitStore) writeCommitLinks(ctx context.Context, links []CommitLink) error {
+	_ = ctx // Reserved for future use
+
+	if len(links) == 0 {
+		return nil
+	}
+	for _, link := range links {
+		if !isFullCommitSHA(link.CommitSHA) {
+			return fmt.Errorf("invalid commit link: commit SHA %q is not a full SHA", link.CommitSHA)
+		}
+		if link.CheckpointID.IsEmpty() {
+			return fmt.Errorf("invalid commit link for %s: checkpoint ID is required", link.CommitSHA)
+		}
+	}
+
+	if err := s.ensureSessionsBranch(); err != nil {
+		return fmt.Errorf("failed to ensure sessions branch: %w", err)
+	}
+
+	ref, entries, err := s.getSessionsBranchEntries()
+	if err != nil {
+		return err
+	}
+
+	// Sort for a deterministic commit message
+	sorted := make([]CommitLink, len(links))
+	copy(sorted, links)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].CommitSHA < sorted[j].CommitSHA })
+
+	var body strings.Builder
+	for _, link := range sorted {
+		if link.RecordedAt.IsZero() {
+			link.RecordedAt = time.Now().UTC()
+		}
+		data, err := jsonutil.MarshalIndentWithNewline(link, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal commit link: %w", err)
+		}
+		blobHash, err := CreateBlobFromContent(s.repo, data)
+		if err != nil {
+			return err
+		}
+		linkPath := commitLinkPath(link.CommitSHA)
+		entries[linkPath] = object.TreeEntry{
+			Name: linkPath,
+			Mode: filemode.Regular,
+			Hash: blobHash,
+		}
+		fmt.Fprintf(&body, "%s -> %s (%s)\n", link.CommitSHA[:7], link.CheckpointID, link.RewriteType)
+	}
+
+	newTreeHash, err := BuildTreeFromEntries(s.repo, entries)
+	if err != nil {
+		return err
+	}
+
+	authorName, authorEmail := getGitAuthorFromRepo(s.repo)
+	commitMsg := fmt.Sprintf("Link rewritten commits (%d)\n\n%s", len(sorted), body.String())
+	newCommitHash, err := s.createCommit(newTreeHash, ref.Hash(), commitMsg, authorName, authorEmail)
+	if err != nil {
+		return err
+	}
+
+	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	newRef := plumbing.NewHashReference(refName, newCommitHash)
+	if err := s.repo.Storer.SetReference(newRef); err != nil {
+		return fmt.Errorf("failed to set branch reference: %w", err)
+	}
+
+	return nil
+}
+
+// ReadCommitLink returns the link recorded for a commit SHA.
+// Returns nil, nil if no link exists.
+func (s *GitStore) ReadCommitLink(ctx context.Context, commitSHA string) (*CommitLink, error) {
+	_ = ctx // Reserved for future use
+
+	if !isFullCommitSHA(commitSHA) {
+		return nil, nil //nolint:nilnil // Not a full SHA, so no link can exist
+	}
+
+	tree, err := s.getSessionsBranchTree()
+	if err != nil {
+		return nil, nil //nolint:nilnil,nilerr // No sessions branch means no links
+	}
+
+	file, err := tree.File(commitLinkPath(commitSHA))
+	if err != nil {
+		if errors.Is(err, object.ErrFileNotFound) || errors.Is(err, object.ErrDirectoryNotFound) {
+			return nil, nil //nolint:nilnil // No link recorded for this commit
+		}
+		return nil, fmt.Errorf("failed to read commit link: %w", err)
+	}
+
+	content, err := file.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit link: %w", err)
+	}
+
+	var link CommitLink
+	if err := json.Unmarshal([]byte(content), &link); err != nil {
+		return nil, fmt.Errorf("failed to parse commit link: %w", err)
+	}
+	return &link, nil
+}
+
+// CheckpointIDForCommit returns the checkpoint linked to a code commit.
+// The Entire-Checkpoint trailer takes precedence, then the commit's note on
+// refs/notes/entire. Commits that were rewritten without either fall back to
+// links recorded by the post-rewrite hook, which are looked up in the checkpoint index.
+func (s *GitStore) CheckpointIDForCommit(ctx context.Context, commit *object.Commit) (id.CheckpointID, bool) {
+	if cpID, found := trailers.ParseCheckpoint(commit.Message); found {
+		return cpID, true
+	}
+	if cpID, found := s.ReadCommitNote(ctx, commit.Hash); found {
+		return cpID, true
+	}
+	if idx, err := s.checkpointIndex(); err == nil {
+		if idx == nil {
+			return id.EmptyCheckpointID, false
+		}
+		cpID, found := idx.Commits[commit.Hash.String()]
+		return cpID, found
+	}
+	link, err := s.ReadCommitLink(ctx, commit.Hash.String())
+	if err != nil || link == nil {
+		return id.EmptyCheckpointID, false
+	}
+	return link.CheckpointID, true
+}
@@ -0,0 +1,131 @@
+package checkpoint
+
+import (
+	"context"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// TestWriteCommitLinks_RoundTrip verifies that links written to the metadata
+// branch can be read back by commit SHA.
+func TestWriteCommitLinks_RoundTrip(t *testing.T) {
+	repo, commitHash := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	cpID := id.MustCheckpointID("a1b2c3d4e5f6")
+
+	err := store.WriteCommitLinks(context.Background(), []CommitLink{{
+		CommitSHA:      commitHash.String(),
+		CheckpointID:   cpID,
+		OriginalCommit: "0123456789abcdef0123456789abcdef01234567",
+		RewriteType:    "amend",
+	}})
+	if err != nil {
+		t.Fatalf("WriteCommitLinks() error = %v", err)
+	}
+
+	link, err := store.ReadCommitLink(context.Background(), commitHash.String())
+	if err != nil {
+		t.Fatalf("ReadCommitLink() error = %v", err)
+	}
+	if link == nil {
+		t.Fatal("ReadCommitLink() returned nil, want link")
+	}
+	if link.CheckpointID != cpID {
+		t.Errorf("link.CheckpointID = %q, want %q", link.CheckpointID, cpID)
+	}
+	if link.RewriteType != "amend" {
+		t.Errorf("link.RewriteType = %q, want %q", link.RewriteType, "amend")
+	}
+	if link.RecordedAt.IsZero() {
+		t.Error("link.RecordedAt should be set")
+	}
+
+	// Links must not show up as checkpoints
+	committed, err := store.ListCommitted(context.Background())
+	if err != nil {
+		t.Fatalf("ListCommitted() error = %v", err)
+	}
+	if len(committed) != 0 {
+		t.Errorf("ListCommitted() returned %d checkpoints, want 0", len(committed))
+	}
+}
+
+// TestReadCommitLink_NotFound verifies that a missing link is not an error.
+func TestReadCommitLink_NotFound(t *testing.T) {
+	repo, commitHash := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+
+	link, err := store.ReadCommitLink(context.Background(), commitHash.String())
+	if err != nil {
+		t.Fatalf("ReadCommitLink() error = %v", err)
+	}
+	if link != nil {
+		t.Errorf("ReadCommitLink() = %+v, want nil", link)
+	}
+}
+
+// TestWriteCommitLinks_RejectsInvalidLinks verifies that abbreviated SHAs and
+// empty checkpoint IDs are rejected.
+func TestWriteCommitLinks_RejectsInvalidLinks(t *testing.T) {
+	repo, commitHash := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+
+	tests := []struct {
+		name string
+		link CommitLink
+	}{
+		{"short SHA", CommitLink{CommitSHA: commitHash.String()[:7], CheckpointID: id.MustCheckpointID("a1b2c3d4e5f6")}},
+		{"empty checkpoint ID", CommitLink{CommitSHA: commitHash.String()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := store.WriteCommitLinks(context.Background(), []CommitLink{tt.link}); err == nil {
+				t.Error("WriteCommitLinks() should return error")
+			}
+		})
+	}
+}
+
+// TestCheckpointIDForCommit verifies that the trailer takes precedence and
+// that recorded links are used as a fallback.
+func TestCheckpointIDForCommit(t *testing.T) {
+	repo, commitHash := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	linkedID := id.MustCheckpointID("a1b2c3d4e5f6")
+	trailerID := id.MustCheckpointID("f6e5d4c3b2a1")
+
+	commit, err := repo.CommitObject(commitHash)
+	if err != nil {
+		t.Fatalf("failed to get commit: %v", err)
+	}
+
+	if _, found := store.CheckpointIDForCommit(context.Background(), commit); found {
+		t.Error("CheckpointIDForCommit() found checkpoint before link was recorded")
+	}
+
+	if err := store.WriteCommitLinks(context.Background(), []CommitLink{{
+		CommitSHA:    commitHash.String(),
+		CheckpointID: linkedID,
+		RewriteType:  "rebase",
+	}}); err != nil {
+		t.Fatalf("WriteCommitLinks() error = %v", err)
+	}
+
+	got, found := store.CheckpointIDForCommit(context.Background(), commit)
+	if !found || got != linkedID {
+		t.Errorf("CheckpointIDForCommit() = %q, %v; want %q, true", got, found, linkedID)
+	}
+
+	withTrailer := &object.Commit{
+		Hash:    commitHash,
+		Message: "msg\n\n" + trailers.CheckpointTrailerKey + ": " + trailerID.String() + "\n",
+	}
+	got, found = store.CheckpointIDForCommit(context.Background(), withTrailer)
+	if !found || got != trailerID {
+		t.Errorf("CheckpointIDForCommit() = %q, %v; want trailer %q, true", got, found, trailerID)
+	}
+}
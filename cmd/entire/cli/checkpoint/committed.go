@@ -39,9 +39,19 @@ var errStopIteration = errors.New("stop iteration")
 // Checkpoints are stored at sharded paths: <id[:2]>/<id[2:]>/
 //
 // For task checkpoints (IsTask=true), additional files are written under tasks/<tool-use-id>/:
-//   - For incremental checkpoints: checkpoints/NNN-<tool-use-id>.json
+//   - For incremental checkpoints: checkpoints/NNN-<tool-use-id>.json (.json.age when encrypted)
 //   - For final checkpoints: checkpoint.json and agent-<agent-id>.jsonl
 func (s *GitStore) WriteCommitted(ctx context.Context, opts WriteCommittedOptions) error {
+	if err := s.writeCommitted(ctx, opts); err != nil {
+		return err
+	}
+	s.updateCheckpointIndex()
+	s.repackAfterWrite(ctx)
+	return nil
+}
+
+// writeCommitted does the work of WriteCommitted, before the checkpoint index is updated.
+func (s *GitStore) writeCommitted(ctx context.Context, opts WriteCommittedOptions) error {
 	_ = ctx // Reserved for future use
 
 	// Validate identifiers to prevent path traversal and malformed data
@@ -57,6 +67,12 @@ func (s *GitStore) WriteCommitted(ctx context.Context, opts WriteCommittedOption
 	if err := validation.ValidateAgentID(opts.AgentID); err != nil {
 		return fmt.Errorf("invalid checkpoint options: %w", err)
 	}
+	if err := ValidateCompression(opts.TranscriptCompression); err != nil {
+		return fmt.Errorf("invalid checkpoint options: %w", err)
+	}
+	if _, err := ParseRecipients(opts.EncryptionRecipients); err != nil {
+		return fmt.Errorf("invalid checkpoint options: %w", err)
+	}
 
 	// Ensure sessions branch exists
 	if err := s.ensureSessionsBranch(); err != nil {
@@ -161,17 +177,12 @@ func (s *GitStore) writeIncrementalTaskCheckpoint(opts WriteCommittedOptions, ta
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal incremental checkpoint: %w", err)
 	}
-	cpBlobHash, err := CreateBlobFromContent(s.repo, cpData)
-	if err != nil {
-		return "", fmt.Errorf("failed to create incremental checkpoint blob: %w", err)
-	}
 
+	// The tool input in Data is as sensitive as the transcript
 	cpFilename := fmt.Sprintf("%03d-%s.json", opts.IncrementalSequence, opts.ToolUseID)
-	cpPath := taskPath + "checkpoints/" + cpFilename
-	entries[cpPath] = object.TreeEntry{
-		Name: cpPath,
-		Mode: filemode.Regular,
-		Hash: cpBlobHash,
+	cpPath := encryptedFileName(taskPath+"checkpoints/"+cpFilename, opts.EncryptionRecipients)
+	if err := s.writeEncryptedBlob(opts, cpPath, cpData, entries); err != nil {
+		return "", fmt.Errorf("failed to create incremental checkpoint blob: %w", err)
 	}
 	return cpPath, nil
 }
@@ -206,10 +217,17 @@ func (s *GitStore) writeFinalTaskCheckpoint(opts WriteCommittedOptions, taskPath
 		if readErr == nil {
 			agentContent, readErr = redact.JSONLBytes(agentContent)
 		}
+		if readErr == nil {
+			agentContent, readErr = compress(opts.TranscriptCompression, agentContent)
+		}
+		if readErr == nil {
+			agentContent, readErr = encrypt(opts.EncryptionRecipients, agentContent)
+		}
 		if readErr == nil {
 			agentBlobHash, agentBlobErr := CreateBlobFromContent(s.repo, agentContent)
 			if agentBlobErr == nil {
-				agentPath := taskPath + "agent-" + opts.AgentID + ".jsonl"
+				agentPath := taskPath + encryptedFileName(
+					compressedFileName("agent-"+opts.AgentID+".jsonl", opts.TranscriptCompression), opts.EncryptionRecipients)
 				entries[agentPath] = object.TreeEntry{
 					Name: agentPath,
 					Mode: filemode.Regular,
@@ -261,7 +279,7 @@ func (s *GitStore) writeStandardCheckpointEntries(opts WriteCommittedOptions, ba
 
 	// Copy additional metadata files from directory if specified (to session subdirectory)
 	if opts.MetadataDir != "" {
-		if err := s.copyMetadataDir(opts.MetadataDir, sessionPath, entries); err != nil {
+		if err := s.copyMetadataDir(opts.MetadataDir, sessionPath, entries, opts.TranscriptCompression, opts.EncryptionRecipients); err != nil {
 			return fmt.Errorf("failed to copy metadata directory: %w", err)
 		}
 	}
@@ -294,39 +312,42 @@ func (s *GitStore) writeSessionToSubdirectory(opts WriteCommittedOptions, sessio
 	}
 
 	// Write transcript
-	if err := s.writeTranscript(opts, sessionPath, entries); err != nil {
+	transcriptDelta, err := s.writeTranscript(opts, sessionPath, entries)
+	if err != nil {
 		return filePaths, err
 	}
-	filePaths.Transcript = "/" + sessionPath + paths.TranscriptFileName
-	filePaths.ContentHash = "/" + sessionPath + paths.ContentHashFileName
+	filePaths.Transcript = "/" + sessionPath + encryptedFileName(
+		compressedFileName(paths.TranscriptFileName, opts.TranscriptCompression), opts.EncryptionRecipients)
+	if len(opts.EncryptionRecipients) == 0 {
+		filePaths.ContentHash = "/" + sessionPath + paths.ContentHashFileName
+	}
 
 	// Write prompts
 	if len(opts.Prompts) > 0 {
 		promptContent := redact.String(strings.Join(opts.Prompts, "\n\n---\n\n"))
-		blobHash, err := CreateBlobFromContent(s.repo, []byte(promptContent))
-		if err != nil {
+		promptPath := sessionPath + encryptedFileName(paths.PromptFileName, opts.EncryptionRecipients)
+		if err := s.writeEncryptedBlob(opts, promptPath, []byte(promptContent), entries); err != nil {
 			return filePaths, err
 		}
-		entries[sessionPath+paths.PromptFileName] = object.TreeEntry{
-			Name: sessionPath + paths.PromptFileName,
-			Mode: filemode.Regular,
-			Hash: blobHash,
-		}
-		filePaths.Prompt = "/" + sessionPath + paths.PromptFileName
+		filePaths.Prompt = "/" + promptPath
 	}
 
 	// Write context
 	if len(opts.Context) > 0 {
-		blobHash, err := CreateBlobFromContent(s.repo, redact.Bytes(opts.Context))
-		if err != nil {
+		contextPath := sessionPath + encryptedFileName(paths.ContextFileName, opts.EncryptionRecipients)
+		if err := s.writeEncryptedBlob(opts, contextPath, redact.Bytes(opts.Context), entries); err != nil {
 			return filePaths, err
 		}
-		entries[sessionPath+paths.ContextFileName] = object.TreeEntry{
-			Name: sessionPath + paths.ContextFileName,
-			Mode: filemode.Regular,
-			Hash: blobHash,
+		filePaths.Context = "/" + contextPath
+	}
+
+	// The summary of an encrypted session is stored next to metadata.json, encrypted
+	summary := opts.Summary
+	if summary != nil && len(opts.EncryptionRecipients) > 0 {
+		if err := s.writeEncryptedSummary(opts, sessionPath, summary, entries); err != nil {
+			return filePaths, err
 		}
-		filePaths.Context = "/" + sessionPath + paths.ContextFileName
+		summary = nil
 	}
 
 	// Write session-level metadata.json (CommittedMetadata with all fields including initial_attribution)
@@ -346,7 +367,12 @@ func (s *GitStore) writeSessionToSubdirectory(opts WriteCommittedOptions, sessio
 		TranscriptLinesAtStart:      opts.CheckpointTranscriptStart, // Deprecated: kept for backward compat
 		TokenUsage:                  opts.TokenUsage,
 		InitialAttribution:          opts.InitialAttribution,
-		Summary:                     opts.Summary,
+		Summary:                     summary,
+		TranscriptCompression:       opts.TranscriptCompression,
+		Encryption:                  encryptionFormat(opts.EncryptionRecipients),
+		TranscriptDelta:             transcriptDelta,
+		ParentSessionID:             opts.ParentSessionID,
+		ParentCheckpoint:            opts.ParentCheckpoint,
 		CLIVersion:                  buildinfo.Version,
 	}
 
@@ -368,6 +394,35 @@ func (s *GitStore) writeSessionToSubdirectory(opts WriteCommittedOptions, sessio
 	return filePaths, nil
 }
 
+// writeEncryptedSummary stores the summary of a session in summary.json.age,
+// encrypted to opts.EncryptionRecipients, instead of in its plaintext metadata.json.
+func (s *GitStore) writeEncryptedSummary(opts WriteCommittedOptions, sessionPath string, summary *Summary, entries map[string]object.TreeEntry) error {
+	summaryJSON, err := jsonutil.MarshalIndentWithNewline(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal summary: %w", err)
+	}
+	summaryPath := sessionPath + encryptedFileName(paths.SessionSummaryFileName, opts.EncryptionRecipients)
+	return s.writeEncryptedBlob(opts, summaryPath, summaryJSON, entries)
+}
+
+// writeEncryptedBlob stores content at entryPath, encrypted to opts.EncryptionRecipients if set.
+func (s *GitStore) writeEncryptedBlob(opts WriteCommittedOptions, entryPath string, content []byte, entries map[string]object.TreeEntry) error {
+	content, err := encrypt(opts.EncryptionRecipients, content)
+	if err != nil {
+		return err
+	}
+	blobHash, err := CreateBlobFromContent(s.repo, content)
+	if err != nil {
+		return err
+	}
+	entries[entryPath] = object.TreeEntry{
+		Name: entryPath,
+		Mode: filemode.Regular,
+		Hash: blobHash,
+	}
+	return nil
+}
+
 // writeCheckpointSummary writes the root-level CheckpointSummary with aggregated statistics.
 // sessions is the complete sessions array (already built by the caller).
 func (s *GitStore) writeCheckpointSummary(opts WriteCommittedOptions, basePath string, entries map[string]object.TreeEntry, sessions []SessionFilePaths) error {
@@ -507,7 +562,13 @@ func aggregateTokenUsage(a, b *agent.TokenUsage) *agent.TokenUsage {
 
 // writeTranscript writes the transcript file from in-memory content or file path.
 // If the transcript exceeds MaxChunkSize, it's split into multiple chunk files.
-func (s *GitStore) writeTranscript(opts WriteCommittedOptions, basePath string, entries map[string]object.TreeEntry) error {
+// With opts.TranscriptCompression set, each chunk is compressed separately and
+// stored under the compressed name (full.jsonl.zst, full.jsonl.zst.001, ...).
+// With opts.EncryptionRecipients set, each chunk is then encrypted and ".age"
+// is appended to the name (full.jsonl.zst.age, full.jsonl.zst.age.001, ...).
+// With opts.TranscriptDelta set, only the lines appended since the session's
+// previous checkpoint may be stored; the returned delta is non-nil if so.
+func (s *GitStore) writeTranscript(opts WriteCommittedOptions, basePath string, entries map[string]object.TreeEntry) (*TranscriptDelta, error) {
 	transcript := opts.Transcript
 	if len(transcript) == 0 && opts.TranscriptPath != "" {
 		var readErr error
@@ -518,47 +579,53 @@ func (s *GitStore) writeTranscript(opts WriteCommittedOptions, basePath string,
 		}
 	}
 	if len(transcript) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	// Redact secrets before chunking so content hash reflects redacted content
 	transcript, err := redact.JSONLBytes(transcript)
 	if err != nil {
-		return fmt.Errorf("failed to redact transcript secrets: %w", err)
+		return nil, fmt.Errorf("failed to redact transcript secrets: %w", err)
 	}
 
+	delta, stored := s.transcriptDelta(opts, transcript)
+
 	// Chunk the transcript if it's too large
-	chunks, err := agent.ChunkTranscript(transcript, opts.Agent)
+	chunks, err := agent.ChunkTranscript(stored, opts.Agent)
 	if err != nil {
-		return fmt.Errorf("failed to chunk transcript: %w", err)
+		return nil, fmt.Errorf("failed to chunk transcript: %w", err)
 	}
 
 	// Write chunk files
+	transcriptFileName := encryptedFileName(
+		compressedFileName(paths.TranscriptFileName, opts.TranscriptCompression), opts.EncryptionRecipients)
 	for i, chunk := range chunks {
-		chunkPath := basePath + agent.ChunkFileName(paths.TranscriptFileName, i)
-		blobHash, err := CreateBlobFromContent(s.repo, chunk)
+		chunk, err := compress(opts.TranscriptCompression, chunk)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		entries[chunkPath] = object.TreeEntry{
-			Name: chunkPath,
-			Mode: filemode.Regular,
-			Hash: blobHash,
+		chunkPath := basePath + agent.ChunkFileName(transcriptFileName, i)
+		if err := s.writeEncryptedBlob(opts, chunkPath, chunk, entries); err != nil {
+			return nil, err
 		}
 	}
 
-	// Content hash for deduplication (hash of full transcript)
+	// Content hash for deduplication (hash of full transcript, also for deltas).
+	// Encrypted transcripts have none: the hash would identify the plaintext.
+	if len(opts.EncryptionRecipients) > 0 {
+		return delta, nil
+	}
 	contentHash := fmt.Sprintf("sha256:%x", sha256.Sum256(transcript))
 	hashBlob, err := CreateBlobFromContent(s.repo, []byte(contentHash))
 	if err != nil {
-		return err
+		return nil, err
 	}
 	entries[basePath+paths.ContentHashFileName] = object.TreeEntry{
 		Name: basePath + paths.ContentHashFileName,
 		Mode: filemode.Regular,
 		Hash: hashBlob,
 	}
-	return nil
+	return delta, nil
 }
 
 // mergeFilesTouched combines two file lists, removing duplicates.
@@ -648,37 +715,63 @@ type taskCheckpointData struct {
 //	│   └── full.jsonl     # Transcript
 //	├── 1/                 # Second session
 //	└── ...
+//
+// The summary is taken from the checkpoint index; if the index can't be
+// brought up to date, it is read from the metadata branch directly.
 func (s *GitStore) ReadCommitted(ctx context.Context, checkpointID id.CheckpointID) (*CheckpointSummary, error) {
-	_ = ctx // Reserved for future use
+	idx, err := s.checkpointIndex()
+	if err != nil {
+		logging.Warn(ctx, "checkpoint index unavailable, reading checkpoint from the metadata branch",
+			slog.String("checkpoint_id", checkpointID.String()),
+			slog.String("error", err.Error()),
+		)
+		return s.readCommittedFromTree(checkpointID)
+	}
+	if idx == nil {
+		return nil, nil //nolint:nilnil // No sessions branch means no checkpoint exists
+	}
 
+	entry, ok := idx.Checkpoints[checkpointID]
+	if !ok || entry.Summary == nil {
+		return nil, nil //nolint:nilnil // Checkpoint or its metadata.json not found
+	}
+	return entry.summary(), nil
+}
+
+// readCommittedFromTree reads the summary of a checkpoint from the metadata
+// branch tree, without the checkpoint index.
+func (s *GitStore) readCommittedFromTree(checkpointID id.CheckpointID) (*CheckpointSummary, error) {
 	tree, err := s.getSessionsBranchTree()
 	if err != nil {
 		return nil, nil //nolint:nilnil,nilerr // No sessions branch means no checkpoint exists
 	}
-
-	checkpointPath := checkpointID.Path()
-	checkpointTree, err := tree.Tree(checkpointPath)
+	metadataFile, err := tree.File(checkpointID.Path() + "/" + paths.MetadataFileName)
 	if err != nil {
-		return nil, nil //nolint:nilnil,nilerr // Checkpoint directory not found
+		return nil, nil //nolint:nilnil,nilerr // Checkpoint or its metadata.json not found
 	}
-
-	// Read root metadata.json as CheckpointSummary
-	metadataFile, err := checkpointTree.File(paths.MetadataFileName)
+	summary, err := s.readSummaryFromBlob(metadataFile.Hash)
 	if err != nil {
-		return nil, nil //nolint:nilnil,nilerr // metadata.json not found
+		return nil, fmt.Errorf("failed to read metadata.json: %w", err)
 	}
+	return summary, nil
+}
 
-	content, err := metadataFile.Contents()
+// indexFromTree indexes the checkpoints on the metadata branch tree without
+// the cached index, for when the index can't be built. Commit links are left
+// out. Returns nil if the branch tree can't be read.
+func (s *GitStore) indexFromTree() *checkpointIndex {
+	tree, err := s.getSessionsBranchTree()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read metadata.json: %w", err)
+		return nil
 	}
-
-	var summary CheckpointSummary
-	if err := json.Unmarshal([]byte(content), &summary); err != nil {
-		return nil, fmt.Errorf("failed to parse metadata.json: %w", err)
+	idx := newCheckpointIndex(plumbing.ZeroHash)
+	prev := newCheckpointIndex(plumbing.ZeroHash)
+	for _, entry := range tree.Entries {
+		if entry.Mode == filemode.Dir && len(entry.Name) == 2 {
+			s.indexCheckpointShard(idx, prev, entry.Name, entry.Hash)
+		}
 	}
-
-	return &summary, nil
+	return idx
 }
 
 // ReadSessionContent reads the actual content for a specific session within a checkpoint.
@@ -719,27 +812,68 @@ func (s *GitStore) ReadSessionContent(ctx context.Context, checkpointID id.Check
 	}
 
 	// Read transcript
-	if transcript, transcriptErr := readTranscriptFromTree(sessionTree, agentType); transcriptErr == nil && transcript != nil {
+	transcript, transcriptErr := readTranscriptFromTree(sessionTree, agentType)
+	if errors.Is(transcriptErr, ErrEncrypted) {
+		return nil, fmt.Errorf("failed to read transcript of session %d: %w", sessionIndex, transcriptErr)
+	}
+	if transcriptErr == nil && transcript != nil {
 		result.Transcript = transcript
 	}
-
-	// Read prompts
-	if file, fileErr := sessionTree.File(paths.PromptFileName); fileErr == nil {
-		if content, contentErr := file.Contents(); contentErr == nil {
-			result.Prompts = content
+	if result.Metadata.TranscriptDelta != nil {
+		transcript, err := s.resolveTranscriptDelta(tree, &result.Metadata, result.Transcript, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reconstruct transcript of session %d: %w", sessionIndex, err)
 		}
+		result.Transcript = transcript
+	}
+
+	// Read prompts and context
+	if result.Prompts, err = readSessionFile(sessionTree, paths.PromptFileName); err != nil {
+		return nil, fmt.Errorf("failed to read prompts of session %d: %w", sessionIndex, err)
+	}
+	if result.Context, err = readSessionFile(sessionTree, paths.ContextFileName); err != nil {
+		return nil, fmt.Errorf("failed to read context of session %d: %w", sessionIndex, err)
 	}
 
-	// Read context
-	if file, fileErr := sessionTree.File(paths.ContextFileName); fileErr == nil {
-		if content, contentErr := file.Contents(); contentErr == nil {
-			result.Context = content
+	// Read the summary of an encrypted session
+	if result.Metadata.Summary == nil {
+		summaryJSON, err := readSessionFile(sessionTree, paths.SessionSummaryFileName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read summary of session %d: %w", sessionIndex, err)
+		}
+		if summaryJSON != "" {
+			var summary Summary
+			if err := json.Unmarshal([]byte(summaryJSON), &summary); err == nil {
+				result.Metadata.Summary = &summary
+			}
 		}
 	}
 
 	return result, nil
 }
 
+// readSessionFile reads a file of a session directory that may be stored
+// encrypted (name.age). Returns an empty string if the file doesn't exist or
+// can't be read, and an error wrapping ErrEncrypted if it can't be decrypted.
+func readSessionFile(sessionTree *object.Tree, name string) (string, error) {
+	for _, storedName := range []string{name, name + encryptedExtension} {
+		file, err := sessionTree.File(storedName)
+		if err != nil {
+			continue
+		}
+		content, err := file.Contents()
+		if err != nil {
+			return "", nil //nolint:nilerr // Unreadable files are treated as missing, as before
+		}
+		decoded, err := DecodeFile(storedName, []byte(content))
+		if err != nil {
+			return "", err
+		}
+		return string(decoded), nil
+	}
+	return "", nil
+}
+
 // ReadLatestSessionContent is a convenience method that reads the latest session's content.
 // This is equivalent to ReadSessionContent(ctx, checkpointID, len(summary.Sessions)-1).
 func (s *GitStore) ReadLatestSessionContent(ctx context.Context, checkpointID id.CheckpointID) (*SessionContent, error) {
@@ -785,95 +919,32 @@ func (s *GitStore) ReadSessionContentByID(ctx context.Context, checkpointID id.C
 	return nil, fmt.Errorf("session %q not found in checkpoint %s", sessionID, checkpointID)
 }
 
-// ListCommitted lists all committed checkpoints from the entire/checkpoints/v1 branch.
-// Scans sharded paths: <id[:2]>/<id[2:]>/ directories containing metadata.json.
-//
-
+// ListCommitted lists all committed checkpoints from the entire/checkpoints/v1 branch,
+// most recent first. Checkpoints are read from the checkpoint index, which only
+// decodes the metadata of checkpoints added or changed since it was last updated.
 func (s *GitStore) ListCommitted(ctx context.Context) ([]CommittedInfo, error) {
-	_ = ctx // Reserved for future use
-
-	tree, err := s.getSessionsBranchTree()
+	idx, err := s.checkpointIndex()
 	if err != nil {
-		return []CommittedInfo{}, nil //nolint:nilerr // No sessions branch means empty list
+		logging.Warn(ctx, "checkpoint index unavailable, listing checkpoints from the metadata branch",
+			slog.String("error", err.Error()),
+		)
+		idx = s.indexFromTree()
+	}
+	if idx == nil {
+		return []CommittedInfo{}, nil // No readable sessions branch means empty list
 	}
 
-	var checkpoints []CommittedInfo
-
-	// Scan sharded structure: <2-char-prefix>/<remaining-id>/metadata.json
-	for _, bucketEntry := range tree.Entries {
-		if bucketEntry.Mode != filemode.Dir {
-			continue
-		}
-		// Bucket should be 2 hex chars
-		if len(bucketEntry.Name) != 2 {
-			continue
-		}
-
-		bucketTree, treeErr := s.repo.TreeObject(bucketEntry.Hash)
-		if treeErr != nil {
-			continue
-		}
-
-		// Each entry in the bucket is the remaining part of the checkpoint ID
-		for _, checkpointEntry := range bucketTree.Entries {
-			if checkpointEntry.Mode != filemode.Dir {
-				continue
-			}
-
-			checkpointTree, cpTreeErr := s.repo.TreeObject(checkpointEntry.Hash)
-			if cpTreeErr != nil {
-				continue
-			}
-
-			// Reconstruct checkpoint ID: <bucket><remaining>
-			checkpointIDStr := bucketEntry.Name + checkpointEntry.Name
-			checkpointID, cpIDErr := id.NewCheckpointID(checkpointIDStr)
-			if cpIDErr != nil {
-				// Skip invalid checkpoint IDs (shouldn't happen with our own data)
-				continue
-			}
-
-			info := CommittedInfo{
-				CheckpointID: checkpointID,
-			}
-
-			// Get details from root metadata file (CheckpointSummary format)
-			if metadataFile, fileErr := checkpointTree.File(paths.MetadataFileName); fileErr == nil {
-				if content, contentErr := metadataFile.Contents(); contentErr == nil {
-					var summary CheckpointSummary
-					if err := json.Unmarshal([]byte(content), &summary); err == nil {
-						info.CheckpointsCount = summary.CheckpointsCount
-						info.FilesTouched = summary.FilesTouched
-						info.SessionCount = len(summary.Sessions)
-
-						// Read session metadata from latest session to get Agent, SessionID, CreatedAt
-						if len(summary.Sessions) > 0 {
-							latestIndex := len(summary.Sessions) - 1
-							latestDir := strconv.Itoa(latestIndex)
-							if sessionTree, treeErr := checkpointTree.Tree(latestDir); treeErr == nil {
-								if sessionMetadataFile, smErr := sessionTree.File(paths.MetadataFileName); smErr == nil {
-									if sessionContent, scErr := sessionMetadataFile.Contents(); scErr == nil {
-										var sessionMetadata CommittedMetadata
-										if json.Unmarshal([]byte(sessionContent), &sessionMetadata) == nil {
-											info.Agent = sessionMetadata.Agent
-											info.SessionID = sessionMetadata.SessionID
-											info.CreatedAt = sessionMetadata.CreatedAt
-										}
-									}
-								}
-							}
-						}
-					}
-				}
-			}
-
-			checkpoints = append(checkpoints, info)
-		}
+	checkpoints := make([]CommittedInfo, 0, len(idx.Checkpoints))
+	for checkpointID, entry := range idx.Checkpoints {
+		checkpoints = append(checkpoints, entry.committedInfo(checkpointID))
 	}
 
-	// Sort by time (most recent first)
+	// Sort by time (most recent first), then by ID so ties are stable
 	sort.Slice(checkpoints, func(i, j int) bool {
-		return checkpoints[i].CreatedAt.After(checkpoints[j].CreatedAt)
+		if !checkpoints[i].CreatedAt.Equal(checkpoints[j].CreatedAt) {
+			return checkpoints[i].CreatedAt.After(checkpoints[j].CreatedAt)
+		}
+		return checkpoints[i].CheckpointID < checkpoints[j].CheckpointID
 	})
 
 	return checkpoints, nil
@@ -925,8 +996,19 @@ func LookupSessionLog(cpID id.CheckpointID) ([]byte, string, error) {
 }
 
 // UpdateSummary updates the summary field in the latest session's metadata.
+// The summary of an encrypted session is stored encrypted to encryptionRecipients
+// in summary.json.age instead, so they are required for one.
 // Returns ErrCheckpointNotFound if the checkpoint doesn't exist.
-func (s *GitStore) UpdateSummary(ctx context.Context, checkpointID id.CheckpointID, summary *Summary) error {
+func (s *GitStore) UpdateSummary(ctx context.Context, checkpointID id.CheckpointID, summary *Summary, encryptionRecipients []string) error {
+	if err := s.updateSummary(ctx, checkpointID, summary, encryptionRecipients); err != nil {
+		return err
+	}
+	s.updateCheckpointIndex()
+	return nil
+}
+
+// updateSummary does the work of UpdateSummary, before the checkpoint index is updated.
+func (s *GitStore) updateSummary(ctx context.Context, checkpointID id.CheckpointID, summary *Summary, encryptionRecipients []string) error {
 	_ = ctx // Reserved for future use
 
 	// Ensure sessions branch exists
@@ -967,8 +1049,19 @@ func (s *GitStore) UpdateSummary(ctx context.Context, checkpointID id.Checkpoint
 		return fmt.Errorf("failed to read session metadata: %w", err)
 	}
 
-	// Update the summary
+	// Update the summary, in summary.json.age if the session is encrypted
 	existingMetadata.Summary = summary
+	if existingMetadata.Encryption != "" {
+		if len(encryptionRecipients) == 0 {
+			return fmt.Errorf("checkpoint %s is encrypted; configure encryption recipients to update its summary", checkpointID)
+		}
+		sessionPath := fmt.Sprintf("%s%d/", basePath, latestIndex)
+		opts := WriteCommittedOptions{EncryptionRecipients: encryptionRecipients}
+		if err := s.writeEncryptedSummary(opts, sessionPath, summary, entries); err != nil {
+			return err
+		}
+		existingMetadata.Summary = nil
+	}
 
 	// Write updated session metadata
 	metadataJSON, err := jsonutil.MarshalIndentWithNewline(existingMetadata, "", "  ")
@@ -1037,15 +1130,9 @@ func (s *GitStore) ensureSessionsBranch() error {
 // getSessionsBranchTree returns the tree object for the entire/checkpoints/v1 branch.
 // Falls back to origin/entire/checkpoints/v1 if the local branch doesn't exist.
 func (s *GitStore) getSessionsBranchTree() (*object.Tree, error) {
-	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
-	ref, err := s.repo.Reference(refName, true)
+	ref, err := s.sessionsBranchRef()
 	if err != nil {
-		// Local branch doesn't exist, try remote-tracking branch
-		remoteRefName := plumbing.NewRemoteReferenceName("origin", paths.MetadataBranchName)
-		ref, err = s.repo.Reference(remoteRefName, true)
-		if err != nil {
-			return nil, fmt.Errorf("sessions branch not found: %w", err)
-		}
+		return nil, err
 	}
 
 	commit, err := s.repo.CommitObject(ref.Hash())
@@ -1061,6 +1148,22 @@ func (s *GitStore) getSessionsBranchTree() (*object.Tree, error) {
 	return tree, nil
 }
 
+// sessionsBranchRef returns the entire/checkpoints/v1 branch reference,
+// falling back to origin/entire/checkpoints/v1 if the local branch doesn't exist.
+func (s *GitStore) sessionsBranchRef() (*plumbing.Reference, error) {
+	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	ref, err := s.repo.Reference(refName, true)
+	if err != nil {
+		// Local branch doesn't exist, try remote-tracking branch
+		remoteRefName := plumbing.NewRemoteReferenceName("origin", paths.MetadataBranchName)
+		ref, err = s.repo.Reference(remoteRefName, true)
+		if err != nil {
+			return nil, fmt.Errorf("sessions branch not found: %w", err)
+		}
+	}
+	return ref, nil
+}
+
 // CreateBlobFromContent creates a blob object from in-memory content.
 // Exported for use by strategy package (session_test.go)
 func CreateBlobFromContent(repo *git.Repository, content []byte) (plumbing.Hash, error) {
@@ -1091,7 +1194,7 @@ func CreateBlobFromContent(repo *git.Repository, content []byte) (plumbing.Hash,
 
 // copyMetadataDir copies all files from a directory to the checkpoint path.
 // Used to include additional metadata files like task checkpoints, subagent transcripts, etc.
-func (s *GitStore) copyMetadataDir(metadataDir, basePath string, entries map[string]object.TreeEntry) error {
+func (s *GitStore) copyMetadataDir(metadataDir, basePath string, entries map[string]object.TreeEntry, compression string, recipients []string) error {
 	err := filepath.Walk(metadataDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -1118,14 +1221,25 @@ func (s *GitStore) copyMetadataDir(metadataDir, basePath string, entries map[str
 			return fmt.Errorf("path traversal detected: %s", relPath)
 		}
 
+		// Transcripts are compressed like the ones written by writeTranscript,
+		// and conversation content is encrypted like prompts and context
+		fileCompression := CompressionNone
+		if isTranscriptFile(relPath) {
+			fileCompression = compression
+		}
+		var fileRecipients []string
+		if isConversationFile(relPath) {
+			fileRecipients = recipients
+		}
+
 		// Create blob from file with secrets redaction
-		blobHash, mode, err := createRedactedBlobFromFile(s.repo, path, relPath)
+		blobHash, mode, err := createRedactedBlobFromFile(s.repo, path, relPath, fileCompression, fileRecipients)
 		if err != nil {
 			return fmt.Errorf("failed to create blob for %s: %w", path, err)
 		}
 
 		// Store at checkpoint path
-		fullPath := basePath + relPath
+		fullPath := basePath + encryptedFileName(compressedFileName(relPath, fileCompression), fileRecipients)
 		entries[fullPath] = object.TreeEntry{
 			Name: fullPath,
 			Mode: mode,
@@ -1140,9 +1254,29 @@ func (s *GitStore) copyMetadataDir(metadataDir, basePath string, entries map[str
 	return nil
 }
 
+// isTranscriptFile reports whether a metadata file is a session or subagent
+// transcript (full.jsonl, agent-<id>.jsonl), which are stored compressed if configured.
+func isTranscriptFile(relPath string) bool {
+	name := filepath.Base(relPath)
+	return name == paths.TranscriptFileName || (strings.HasPrefix(name, "agent-") && strings.HasSuffix(name, ".jsonl"))
+}
+
+// isConversationFile reports whether a metadata file holds conversation
+// content (transcripts, prompts, context, summary), which is stored encrypted
+// if configured.
+func isConversationFile(relPath string) bool {
+	switch filepath.Base(relPath) {
+	case paths.PromptFileName, paths.ContextFileName, paths.SummaryFileName:
+		return true
+	}
+	return isTranscriptFile(relPath)
+}
+
 // createRedactedBlobFromFile reads a file, applies secrets redaction, and creates a git blob.
 // JSONL files get JSONL-aware redaction; all other files get plain string redaction.
-func createRedactedBlobFromFile(repo *git.Repository, filePath, treePath string) (plumbing.Hash, filemode.FileMode, error) {
+// The redacted content is compressed with the given compression, then
+// encrypted to recipients if any.
+func createRedactedBlobFromFile(repo *git.Repository, filePath, treePath, compression string, recipients []string) (plumbing.Hash, filemode.FileMode, error) {
 	info, err := os.Stat(filePath)
 	if err != nil {
 		return plumbing.ZeroHash, 0, fmt.Errorf("failed to stat file: %w", err)
@@ -1161,21 +1295,25 @@ func createRedactedBlobFromFile(repo *git.Repository, filePath, treePath string)
 	// Skip redaction for binary files — they can't contain text secrets and
 	// running string replacement on them would corrupt the data.
 	isBin, binErr := binary.IsBinary(bytes.NewReader(content))
-	if binErr != nil || isBin {
-		hash, err := CreateBlobFromContent(repo, content)
-		if err != nil {
-			return plumbing.ZeroHash, 0, fmt.Errorf("failed to create blob: %w", err)
+	if binErr == nil && !isBin {
+		if strings.HasSuffix(treePath, ".jsonl") {
+			content, err = redact.JSONLBytes(content)
+			if err != nil {
+				return plumbing.ZeroHash, 0, fmt.Errorf("failed to redact secrets: %w", err)
+			}
+		} else {
+			content = redact.Bytes(content)
 		}
-		return hash, mode, nil
-	}
 
-	if strings.HasSuffix(treePath, ".jsonl") {
-		content, err = redact.JSONLBytes(content)
+		content, err = compress(compression, content)
 		if err != nil {
-			return plumbing.ZeroHash, 0, fmt.Errorf("failed to redact secrets: %w", err)
+			return plumbing.ZeroHash, 0, err
 		}
-	} else {
-		content = redact.Bytes(content)
+	}
+
+	content, err = encrypt(recipients, content)
+	if err != nil {
+		return plumbing.ZeroHash, 0, err
 	}
 
 	hash, err := CreateBlobFromContent(repo, content)
@@ -1207,19 +1345,49 @@ func getGitAuthorFromRepo(repo *git.Repository) (name, email string) {
 
 // readTranscriptFromTree reads a transcript from a git tree, handling both chunked and non-chunked formats.
 // It checks for chunk files first (.001, .002, etc.), then falls back to the base file.
+// Compressed transcripts (full.jsonl.zst, full.jsonl.gz) are decompressed transparently,
+// and encrypted ones (full.jsonl.age, full.jsonl.zst.age) decrypted; decryption
+// errors wrap ErrEncrypted.
 // The agentType is used for reassembling chunks in the correct format.
 func readTranscriptFromTree(tree *object.Tree, agentType agent.AgentType) ([]byte, error) {
+	for _, compression := range transcriptCompressions {
+		for _, encrypted := range []bool{false, true} {
+			transcript, err := readTranscriptFiles(tree, compression, encrypted, agentType)
+			if err != nil || transcript != nil {
+				return transcript, err
+			}
+		}
+	}
+
+	// Try legacy filename
+	if file, err := tree.File(paths.TranscriptFileNameLegacy); err == nil {
+		if content, err := file.Contents(); err == nil {
+			return []byte(content), nil
+		}
+	}
+
+	return nil, nil
+}
+
+// readTranscriptFiles reads the transcript stored with the given compression,
+// encrypted or not. Returns nil, nil if the tree has no transcript in that format.
+func readTranscriptFiles(tree *object.Tree, compression string, encrypted bool, agentType agent.AgentType) ([]byte, error) {
+	baseName := compressedFileName(paths.TranscriptFileName, compression)
+	if encrypted {
+		baseName += encryptedExtension
+	}
+
 	// Collect all transcript-related files
 	var chunkFiles []string
 	var hasBaseFile bool
 
 	for _, entry := range tree.Entries {
-		if entry.Name == paths.TranscriptFileName || entry.Name == paths.TranscriptFileNameLegacy {
+		if entry.Name == baseName {
 			hasBaseFile = true
 		}
 		// Check for chunk files (full.jsonl.001, full.jsonl.002, etc.)
-		if strings.HasPrefix(entry.Name, paths.TranscriptFileName+".") {
-			idx := agent.ParseChunkIndex(entry.Name, paths.TranscriptFileName)
+		if strings.HasPrefix(entry.Name, baseName+".") {
+			idx := agent.ParseChunkIndex(entry.Name, baseName)
 			if idx > 0 {
 				chunkFiles = append(chunkFiles, entry.Name)
 			}
@@ -1229,13 +1397,13 @@ func readTranscriptFromTree(tree *object.Tree, agentType agent.AgentType) ([]byt
 	// If we have chunk files, read and reassemble them
 	if len(chunkFiles) > 0 {
 		// Sort chunk files by index
-		chunkFiles = agent.SortChunkFiles(chunkFiles, paths.TranscriptFileName)
+		chunkFiles = agent.SortChunkFiles(chunkFiles, baseName)
 
 		// Check if base file should be included as chunk 0.
 		// NOTE: This assumes the chunking convention where the unsuffixed file
 		// (full.jsonl) is chunk 0, and numbered files (.001, .002) are chunks 1+.
 		if hasBaseFile {
-			chunkFiles = append([]string{paths.TranscriptFileName}, chunkFiles...)
+			chunkFiles = append([]string{baseName}, chunkFiles...)
 		}
 
 		var chunks [][]byte
@@ -1256,7 +1424,11 @@ func readTranscriptFromTree(tree *object.Tree, agentType agent.AgentType) ([]byt
 				)
 				continue
 			}
-			chunks = append(chunks, []byte(content))
+			chunk, err := DecodeFile(baseName, []byte(content))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read transcript chunk %s: %w", chunkFile, err)
+			}
+			chunks = append(chunks, chunk)
 		}
 
 		if len(chunks) > 0 {
@@ -1269,20 +1441,22 @@ func readTranscriptFromTree(tree *object.Tree, agentType agent.AgentType) ([]byt
 	}
 
 	// Fall back to reading base file (non-chunked or backwards compatibility)
-	if file, err := tree.File(paths.TranscriptFileName); err == nil {
-		if content, err := file.Contents(); err == nil {
-			return []byte(content), nil
-		}
+	if !hasBaseFile {
+		return nil, nil
 	}
-
-	// Try legacy filename
-	if file, err := tree.File(paths.TranscriptFileNameLegacy); err == nil {
-		if content, err := file.Contents(); err == nil {
-			return []byte(content), nil
-		}
+	file, err := tree.File(baseName)
+	if err != nil {
+		return nil, nil //nolint:nilnil,nilerr // Unreadable base file is treated as missing, as before
 	}
-
-	return nil, nil
+	content, err := file.Contents()
+	if err != nil {
+		return nil, nil //nolint:nilnil,nilerr // Unreadable base file is treated as missing, as before
+	}
+	transcript, err := DecodeFile(baseName, []byte(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transcript %s: %w", baseName, err)
+	}
+	return transcript, nil
 }
 
 // Author contains author information for a checkpoint.
@@ -1297,10 +1471,23 @@ type Author struct {
 func (s *GitStore) GetCheckpointAuthor(ctx context.Context, checkpointID id.CheckpointID) (Author, error) {
 	_ = ctx // Reserved for future use
 
+	commit := s.checkpointCreationCommit(checkpointID)
+	if commit == nil {
+		return Author{}, nil
+	}
+	return Author{
+		Name:  commit.Author.Name,
+		Email: commit.Author.Email,
+	}, nil
+}
+
+// checkpointCreationCommit returns the entire/checkpoints/v1 commit that
+// introduced the checkpoint's metadata.json file, or nil if it isn't found.
+func (s *GitStore) checkpointCreationCommit(checkpointID id.CheckpointID) *object.Commit {
 	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
 	ref, err := s.repo.Reference(refName, true)
 	if err != nil {
-		return Author{}, nil
+		return nil
 	}
 
 	// Path to the checkpoint's metadata file
@@ -1312,11 +1499,10 @@ func (s *GitStore) GetCheckpointAuthor(ctx context.Context, checkpointID id.Chec
 		Order: git.LogOrderCommitterTime,
 	})
 	if err != nil {
-		return Author{}, nil
+		return nil
 	}
 	defer iter.Close()
 
-	var author Author
 	var foundCommit *object.Commit
 
 	err = iter.ForEach(func(c *object.Commit) error {
@@ -1336,17 +1522,13 @@ func (s *GitStore) GetCheckpointAuthor(ctx context.Context, checkpointID id.Chec
 
 		// File exists - track it (oldest one with file is the creator)
 		foundCommit = c
-		author = Author{
-			Name:  c.Author.Name,
-			Email: c.Author.Email,
-		}
 		return nil
 	})
 
 	// Ignore errStopIteration - it's just for early exit
 	if err != nil && !errors.Is(err, errStopIteration) {
-		return Author{}, nil
+		return nil
 	}
 
-	return author, nil
+	return foundCommit
 }
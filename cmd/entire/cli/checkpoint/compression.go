@@ -0,0 +1,122 @@
+package checkpoint
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Transcript compression formats, recorded in CommittedMetadata.TranscriptCompression.
+// Compressed transcripts are stored with the format's extension appended
+// (full.jsonl.zst, agent-<id>.jsonl.gz); uncompressed ones keep their plain name.
+const (
+	CompressionNone = ""
+	CompressionZstd = "zstd"
+	CompressionGzip = "gzip"
+)
+
+// compressionExtensions maps compression formats to their file extensions.
+var compressionExtensions = map[string]string{
+	CompressionZstd: ".zst",
+	CompressionGzip: ".gz",
+}
+
+// transcriptCompressions lists the formats readers look for, uncompressed first.
+var transcriptCompressions = []string{CompressionNone, CompressionZstd, CompressionGzip}
+
+// ValidateCompression returns an error if compression isn't a supported format.
+func ValidateCompression(compression string) error {
+	if compression == CompressionNone {
+		return nil
+	}
+	if _, ok := compressionExtensions[compression]; !ok {
+		return fmt.Errorf("unsupported transcript compression %q (use %q or %q)", compression, CompressionZstd, CompressionGzip)
+	}
+	return nil
+}
+
+// compressedFileName returns the name of a file stored with the given compression.
+func compressedFileName(name, compression string) string {
+	return name + compressionExtensions[compression]
+}
+
+// compress compresses data in the given format.
+func compress(compression string, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	switch compression {
+	case CompressionNone:
+		return data, nil
+	case CompressionZstd:
+		w, err := zstd.NewWriter(&buf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create zstd writer: %w", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			_ = w.Close()
+			return nil, fmt.Errorf("failed to compress transcript: %w", err)
+		}
+		if err := w.Close(); err != nil {
+			return nil, fmt.Errorf("failed to compress transcript: %w", err)
+		}
+	case CompressionGzip:
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			_ = w.Close()
+			return nil, fmt.Errorf("failed to compress transcript: %w", err)
+		}
+		if err := w.Close(); err != nil {
+			return nil, fmt.Errorf("failed to compress transcript: %w", err)
+		}
+	default:
+		return nil, ValidateCompression(compression)
+	}
+	return buf.Bytes(), nil
+}
+
+// decompress decompresses data in the given format.
+func decompress(compression string, data []byte) ([]byte, error) {
+	switch compression {
+	case CompressionNone:
+		return data, nil
+	case CompressionZstd:
+		r, err := zstd.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create zstd reader: %w", err)
+		}
+		defer r.Close()
+		out, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress transcript: %w", err)
+		}
+		return out, nil
+	case CompressionGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress transcript: %w", err)
+		}
+		defer r.Close()
+		out, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress transcript: %w", err)
+		}
+		return out, nil
+	default:
+		return nil, ValidateCompression(compression)
+	}
+}
+
+// DecompressFile returns the content of a file read from a checkpoint tree,
+// decompressed according to the file's extension. Content of files without a
+// compression extension is returned unchanged.
+func DecompressFile(name string, data []byte) ([]byte, error) {
+	for compression, ext := range compressionExtensions {
+		if strings.HasSuffix(name, ext) {
+			return decompress(compression, data)
+		}
+	}
+	return data, nil
+}
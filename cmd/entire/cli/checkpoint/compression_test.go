@@ -0,0 +1,133 @@
+package checkpoint
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+// TestWriteCommitted_CompressedTranscript verifies that compressed transcripts
+// are stored under the compressed name, recorded in the session metadata and
+// decompressed transparently on read.
+func TestWriteCommitted_CompressedTranscript(t *testing.T) {
+	transcript := []byte(`{"type":"user","message":{"content":"hello"}}` + "\n" +
+		`{"type":"assistant","message":{"content":"hi there"}}` + "\n")
+
+	tests := []struct {
+		compression string
+		fileName    string
+	}{
+		{CompressionZstd, "full.jsonl.zst"},
+		{CompressionGzip, "full.jsonl.gz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.compression, func(t *testing.T) {
+			repo, _ := setupBranchTestRepo(t)
+			store := NewGitStore(repo)
+			checkpointID := id.MustCheckpointID("c0c1c2c3c4c5")
+
+			err := store.WriteCommitted(context.Background(), WriteCommittedOptions{
+				CheckpointID:          checkpointID,
+				SessionID:             "compressed-session",
+				Strategy:              "manual-commit",
+				Transcript:            transcript,
+				CheckpointsCount:      1,
+				AuthorName:            "Test Author",
+				AuthorEmail:           "test@example.com",
+				TranscriptCompression: tt.compression,
+			})
+			if err != nil {
+				t.Fatalf("WriteCommitted() error = %v", err)
+			}
+
+			tree, err := store.getSessionsBranchTree()
+			if err != nil {
+				t.Fatalf("getSessionsBranchTree() error = %v", err)
+			}
+			sessionPath := checkpointID.Path() + "/0/"
+			file, err := tree.File(sessionPath + tt.fileName)
+			if err != nil {
+				t.Fatalf("compressed transcript %s not found: %v", tt.fileName, err)
+			}
+			stored, err := file.Contents()
+			if err != nil {
+				t.Fatalf("failed to read stored transcript: %v", err)
+			}
+			if bytes.Equal([]byte(stored), transcript) {
+				t.Error("stored transcript is not compressed")
+			}
+			if _, err := tree.File(sessionPath + paths.TranscriptFileName); err == nil {
+				t.Errorf("uncompressed %s should not be written", paths.TranscriptFileName)
+			}
+
+			content, err := store.ReadSessionContent(context.Background(), checkpointID, 0)
+			if err != nil {
+				t.Fatalf("ReadSessionContent() error = %v", err)
+			}
+			if !bytes.Equal(content.Transcript, transcript) {
+				t.Errorf("Transcript = %q, want %q", content.Transcript, transcript)
+			}
+			if content.Metadata.TranscriptCompression != tt.compression {
+				t.Errorf("TranscriptCompression = %q, want %q", content.Metadata.TranscriptCompression, tt.compression)
+			}
+
+			summary, err := store.ReadCommitted(context.Background(), checkpointID)
+			if err != nil {
+				t.Fatalf("ReadCommitted() error = %v", err)
+			}
+			if want := "/" + sessionPath + tt.fileName; summary.Sessions[0].Transcript != want {
+				t.Errorf("Sessions[0].Transcript = %q, want %q", summary.Sessions[0].Transcript, want)
+			}
+		})
+	}
+}
+
+// TestWriteCommitted_RejectsUnknownCompression verifies that unsupported
+// compression formats are rejected before anything is written.
+func TestWriteCommitted_RejectsUnknownCompression(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+
+	err := store.WriteCommitted(context.Background(), WriteCommittedOptions{
+		CheckpointID:          id.MustCheckpointID("c0c1c2c3c4c6"),
+		SessionID:             "compressed-session",
+		Strategy:              "manual-commit",
+		Transcript:            []byte(`{"type":"user"}` + "\n"),
+		AuthorName:            "Test Author",
+		AuthorEmail:           "test@example.com",
+		TranscriptCompression: "brotli",
+	})
+	if err == nil {
+		t.Fatal("WriteCommitted() error = nil, want error for unsupported compression")
+	}
+}
+
+func TestDecompressFile(t *testing.T) {
+	t.Parallel()
+	data := []byte("line one\nline two\n")
+
+	for _, compression := range []string{CompressionZstd, CompressionGzip} {
+		compressed, err := compress(compression, data)
+		if err != nil {
+			t.Fatalf("compress(%s) error = %v", compression, err)
+		}
+		got, err := DecompressFile(compressedFileName("full.jsonl", compression), compressed)
+		if err != nil {
+			t.Fatalf("DecompressFile(%s) error = %v", compression, err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("DecompressFile(%s) = %q, want %q", compression, got, data)
+		}
+	}
+
+	got, err := DecompressFile("full.jsonl", data)
+	if err != nil {
+		t.Fatalf("DecompressFile(uncompressed) error = %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("DecompressFile(uncompressed) = %q, want %q", got, data)
+	}
+}
@@ -0,0 +1,174 @@
+package checkpoint
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"filippo.io/age"
+)
+
+// Encryption formats, recorded in CommittedMetadata.Encryption.
+// Encrypted files are stored with ".age" appended to their (compressed) name
+// (full.jsonl.age, full.jsonl.zst.age, prompt.txt.age); chunks of encrypted
+// transcripts append their index after it (full.jsonl.age.001).
+const (
+	EncryptionNone = ""
+	EncryptionAge  = "age"
+)
+
+const encryptedExtension = ".age"
+
+// IdentityFileEnvVar overrides the age identity file used to decrypt checkpoints.
+const IdentityFileEnvVar = "ENTIRE_AGE_IDENTITY_FILE"
+
+// ErrEncrypted is wrapped by errors returned when encrypted checkpoint data
+// can't be decrypted with the user's identity file.
+var ErrEncrypted = errors.New("checkpoint data is encrypted")
+
+// ParseRecipients parses age X25519 recipients (age1...).
+// Returns an error naming the first invalid recipient.
+func ParseRecipients(recipients []string) ([]age.Recipient, error) {
+	parsed := make([]age.Recipient, 0, len(recipients))
+	for _, r := range recipients {
+		recipient, err := age.ParseX25519Recipient(strings.TrimSpace(r))
+		if err != nil {
+			return nil, fmt.Errorf("invalid age recipient %q: %w", r, err)
+		}
+		parsed = append(parsed, recipient)
+	}
+	return parsed, nil
+}
+
+// encryptionFormat returns the encryption format used for the given recipients.
+func encryptionFormat(recipients []string) string {
+	if len(recipients) == 0 {
+		return EncryptionNone
+	}
+	return EncryptionAge
+}
+
+// encryptedFileName returns the name of a file stored encrypted for the given
+// recipients; files without recipients keep their name.
+func encryptedFileName(name string, recipients []string) string {
+	if len(recipients) == 0 {
+		return name
+	}
+	return name + encryptedExtension
+}
+
+// encrypt encrypts data to the given recipients. Data is returned unchanged
+// if there are no recipients.
+func encrypt(recipients []string, data []byte) ([]byte, error) {
+	if len(recipients) == 0 {
+		return data, nil
+	}
+	parsed, err := ParseRecipients(recipients)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, parsed...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to encrypt: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encrypt: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// decrypt decrypts data with the identities of the user's identity file.
+// Errors wrap ErrEncrypted and say which identity file was tried.
+func decrypt(data []byte) ([]byte, error) {
+	identityFile := IdentityFile()
+	identities, err := loadIdentities(identityFile)
+	if err != nil {
+		return nil, err
+	}
+	r, err := age.Decrypt(bytes.NewReader(data), identities...)
+	if err != nil {
+		var noMatch *age.NoIdentityMatchError
+		if errors.As(err, &noMatch) {
+			return nil, fmt.Errorf("%w for other recipients: no key in %s can decrypt it", ErrEncrypted, identityFile)
+		}
+		return nil, fmt.Errorf("%w: failed to decrypt with %s: %w", ErrEncrypted, identityFile, err)
+	}
+	out, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decrypt with %s: %w", ErrEncrypted, identityFile, err)
+	}
+	return out, nil
+}
+
+// IdentityFile returns the age identity file used to decrypt checkpoints:
+// $ENTIRE_AGE_IDENTITY_FILE, or keys.txt in the user's entire/age config directory.
+func IdentityFile() string {
+	if identityFile := os.Getenv(IdentityFileEnvVar); identityFile != "" {
+		return identityFile
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(configDir, "entire", "age", "keys.txt")
+}
+
+// identityCache holds the identities of the last identity file loaded, so
+// reading a checkpoint's chunks doesn't parse the file again for each one.
+var identityCache struct {
+	mu         sync.Mutex
+	path       string
+	identities []age.Identity
+}
+
+func loadIdentities(identityFile string) ([]age.Identity, error) {
+	identityCache.mu.Lock()
+	defer identityCache.mu.Unlock()
+	if identityCache.path == identityFile && identityCache.identities != nil {
+		return identityCache.identities, nil
+	}
+
+	if identityFile == "" {
+		return nil, fmt.Errorf("%w: set %s to your age identity file", ErrEncrypted, IdentityFileEnvVar)
+	}
+	f, err := os.Open(identityFile) //nolint:gosec // Identity file path comes from the user's environment
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: no age identity file at %s (set %s to use another file)",
+				ErrEncrypted, identityFile, IdentityFileEnvVar)
+		}
+		return nil, fmt.Errorf("%w: failed to open identity file: %w", ErrEncrypted, err)
+	}
+	defer f.Close()
+	identities, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to parse identity file %s: %w", ErrEncrypted, identityFile, err)
+	}
+
+	identityCache.path = identityFile
+	identityCache.identities = identities
+	return identities, nil
+}
+
+// DecodeFile returns the content of a file read from a checkpoint tree,
+// decrypted and decompressed according to the file's extensions. Content of
+// files without such extensions is returned unchanged.
+func DecodeFile(name string, data []byte) ([]byte, error) {
+	if trimmed, ok := strings.CutSuffix(name, encryptedExtension); ok {
+		decrypted, err := decrypt(data)
+		if err != nil {
+			return nil, err
+		}
+		name, data = trimmed, decrypted
+	}
+	return DecompressFile(name, data)
+}
@@ -0,0 +1,260 @@
+package checkpoint
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"filippo.io/age"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// writeIdentityFile writes a new age identity to a temporary identity file and
+// returns its recipient.
+func writeIdentityFile(t *testing.T) (path, recipient string) {
+	t.Helper()
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("GenerateX25519Identity() error = %v", err)
+	}
+	path = filepath.Join(t.TempDir(), "keys.txt")
+	if err := os.WriteFile(path, []byte(identity.String()+"\n"), 0o600); err != nil {
+		t.Fatalf("failed to write identity file: %v", err)
+	}
+	return path, identity.Recipient().String()
+}
+
+// TestWriteCommitted_Encrypted verifies that transcripts, prompts and context
+// are stored encrypted, that metadata stays readable, and that reading them
+// needs a matching identity.
+func TestWriteCommitted_Encrypted(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	identityFile, recipient := writeIdentityFile(t)
+	t.Setenv(IdentityFileEnvVar, identityFile)
+
+	checkpointID := id.MustCheckpointID("e0e0e0e0e0e1")
+	transcript := []byte(`{"type":"user","message":"secret plan"}` + "\n")
+	err := store.WriteCommitted(context.Background(), WriteCommittedOptions{
+		CheckpointID:         checkpointID,
+		SessionID:            "session-1",
+		Strategy:             "manual-commit",
+		Transcript:           transcript,
+		Prompts:              []string{"secret prompt"},
+		Context:              []byte("secret context"),
+		FilesTouched:         []string{"main.go"},
+		CheckpointsCount:     1,
+		AuthorName:           "Test",
+		AuthorEmail:          "test@test.com",
+		EncryptionRecipients: []string{recipient},
+	})
+	if err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+
+	tree, err := store.getSessionsBranchTree()
+	if err != nil {
+		t.Fatalf("failed to get metadata tree: %v", err)
+	}
+	sessionTree, err := tree.Tree(checkpointID.Path() + "/0")
+	if err != nil {
+		t.Fatalf("failed to get session tree: %v", err)
+	}
+	for name, plaintext := range map[string]string{
+		paths.TranscriptFileName + encryptedExtension: "secret plan",
+		paths.PromptFileName + encryptedExtension:     "secret prompt",
+		paths.ContextFileName + encryptedExtension:    "secret context",
+	} {
+		file, err := sessionTree.File(name)
+		if err != nil {
+			t.Fatalf("%s not found: %v", name, err)
+		}
+		content, err := file.Contents()
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		if bytes.Contains([]byte(content), []byte(plaintext)) {
+			t.Errorf("%s contains the plaintext %q", name, plaintext)
+		}
+	}
+
+	content, err := store.ReadSessionContent(context.Background(), checkpointID, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionContent() error = %v", err)
+	}
+	if !bytes.Equal(content.Transcript, transcript) || content.Prompts != "secret prompt" || content.Context != "secret context" {
+		t.Errorf("ReadSessionContent() = %q, %q, %q", content.Transcript, content.Prompts, content.Context)
+	}
+	if content.Metadata.Encryption != EncryptionAge || len(content.Metadata.FilesTouched) != 1 {
+		t.Errorf("metadata = %+v, want age encryption and readable files touched", content.Metadata)
+	}
+
+	// Incremental task checkpoints carry the tool input
+	taskCheckpointID := id.MustCheckpointID("e0e0e0e0e0e4")
+	err = store.WriteCommitted(context.Background(), WriteCommittedOptions{
+		CheckpointID:         taskCheckpointID,
+		SessionID:            "session-1",
+		Strategy:             "manual-commit",
+		Transcript:           transcript,
+		IsTask:               true,
+		ToolUseID:            "toolu_1",
+		IsIncremental:        true,
+		IncrementalSequence:  1,
+		IncrementalType:      "TodoWrite",
+		IncrementalData:      []byte(`{"todos":[{"content":"secret tool input"}]}`),
+		AuthorName:           "Test",
+		AuthorEmail:          "test@test.com",
+		EncryptionRecipients: []string{recipient},
+	})
+	if err != nil {
+		t.Fatalf("WriteCommitted(incremental task) error = %v", err)
+	}
+	tree, err = store.getSessionsBranchTree()
+	if err != nil {
+		t.Fatalf("failed to get metadata tree: %v", err)
+	}
+	taskTree, err := tree.Tree(taskCheckpointID.Path())
+	if err != nil {
+		t.Fatalf("failed to get task checkpoint tree: %v", err)
+	}
+	err = taskTree.Files().ForEach(func(file *object.File) error {
+		content, err := file.Contents()
+		if err != nil {
+			return err
+		}
+		if bytes.Contains([]byte(content), []byte("secret tool input")) {
+			t.Errorf("%s contains the plaintext tool input", file.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to read task checkpoint files: %v", err)
+	}
+	incrementalName := "tasks/toolu_1/checkpoints/001-toolu_1.json" + encryptedExtension
+	file, err := taskTree.File(incrementalName)
+	if err != nil {
+		t.Fatalf("%s not found: %v", incrementalName, err)
+	}
+	stored, err := file.Contents()
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", incrementalName, err)
+	}
+	decoded, err := DecodeFile(incrementalName, []byte(stored))
+	if err != nil {
+		t.Fatalf("DecodeFile(%s) error = %v", incrementalName, err)
+	}
+	if !bytes.Contains(decoded, []byte("secret tool input")) {
+		t.Errorf("DecodeFile(%s) = %s, want the tool input", incrementalName, decoded)
+	}
+
+	otherIdentityFile, _ := writeIdentityFile(t)
+	for _, identityFile := range []string{otherIdentityFile, filepath.Join(t.TempDir(), "missing.txt")} {
+		t.Setenv(IdentityFileEnvVar, identityFile)
+		if _, err := store.ReadSessionContent(context.Background(), checkpointID, 0); !errors.Is(err, ErrEncrypted) {
+			t.Errorf("ReadSessionContent() with %s error = %v, want ErrEncrypted", identityFile, err)
+		}
+		if summary, err := store.ReadCommitted(context.Background(), checkpointID); err != nil || summary == nil {
+			t.Errorf("ReadCommitted() with %s = %v, %v; want the summary", identityFile, summary, err)
+		}
+	}
+}
+
+func TestWriteCommitted_InvalidRecipient(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	err := NewGitStore(repo).WriteCommitted(context.Background(), WriteCommittedOptions{
+		CheckpointID:         id.MustCheckpointID("e0e0e0e0e0e2"),
+		SessionID:            "session-1",
+		Strategy:             "manual-commit",
+		Transcript:           []byte(`{"type":"user"}` + "\n"),
+		AuthorName:           "Test",
+		AuthorEmail:          "test@test.com",
+		EncryptionRecipients: []string{"not-a-recipient"},
+	})
+	if err == nil {
+		t.Fatal("WriteCommitted() with an invalid recipient should fail")
+	}
+}
+
+// TestWriteCommitted_EncryptedSummary verifies that no blob of the metadata
+// branch contains the summary or the plaintext transcript hash of an encrypted
+// session, written with the checkpoint or updated later, and that the summary
+// is read back from summary.json.age.
+func TestWriteCommitted_EncryptedSummary(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	identityFile, recipient := writeIdentityFile(t)
+	t.Setenv(IdentityFileEnvVar, identityFile)
+
+	checkpointID := id.MustCheckpointID("e0e0e0e0e0e3")
+	transcript := []byte(`{"type":"user","message":"hello"}` + "\n")
+	err := store.WriteCommitted(context.Background(), WriteCommittedOptions{
+		CheckpointID:     checkpointID,
+		SessionID:        "session-1",
+		Strategy:         "manual-commit",
+		Transcript:       transcript,
+		CheckpointsCount: 1,
+		AuthorName:       "Test",
+		AuthorEmail:      "test@test.com",
+		Summary: &Summary{
+			Intent:    "secret intent",
+			Outcome:   "secret outcome",
+			Learnings: LearningsSummary{Repo: []string{"secret learning"}},
+			Friction:  []string{"secret friction"},
+			OpenItems: []string{"secret open item"},
+		},
+		EncryptionRecipients: []string{recipient},
+	})
+	if err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+	if err := store.UpdateSummary(context.Background(), checkpointID, &Summary{Intent: "secret update"}, nil); err == nil {
+		t.Error("UpdateSummary() of an encrypted checkpoint without recipients should fail")
+	}
+	if err := store.UpdateSummary(context.Background(), checkpointID, &Summary{Intent: "secret update"}, []string{recipient}); err != nil {
+		t.Fatalf("UpdateSummary() error = %v", err)
+	}
+
+	// Every blob ever written to the branch, including the first version
+	transcriptHash := fmt.Sprintf("%x", sha256.Sum256(transcript))
+	blobs, err := repo.BlobObjects()
+	if err != nil {
+		t.Fatalf("BlobObjects() error = %v", err)
+	}
+	err = blobs.ForEach(func(blob *object.Blob) error {
+		reader, err := blob.Reader()
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+		for _, plaintext := range []string{"secret", transcriptHash} {
+			if bytes.Contains(content, []byte(plaintext)) {
+				t.Errorf("blob %s contains the plaintext %q", blob.Hash, plaintext)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to read blobs: %v", err)
+	}
+
+	content, err := store.ReadSessionContent(context.Background(), checkpointID, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionContent() error = %v", err)
+	}
+	if content.Metadata.Summary == nil || content.Metadata.Summary.Intent != "secret update" {
+		t.Errorf("Metadata.Summary = %+v, want the updated summary", content.Metadata.Summary)
+	}
+}
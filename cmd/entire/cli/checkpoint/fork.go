@@ -0,0 +1,76 @@
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+	"github.com/entireio/cli/cmd/entire/cli/validation"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// WriteForkPoint starts the shadow branch of a forked session with a
+// checkpoint of the code and transcript it was forked at, so the fork can be
+// rewound to where it started. The shadow branch must not exist yet.
+func (s *GitStore) WriteForkPoint(ctx context.Context, opts WriteForkPointOptions) (plumbing.Hash, error) {
+	_ = ctx // Reserved for future use
+
+	if opts.BaseCommit == "" {
+		return plumbing.ZeroHash, errors.New("BaseCommit is required for a fork point")
+	}
+	if err := validation.ValidateSessionID(opts.SessionID); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("invalid fork point options: %w", err)
+	}
+
+	shadowBranchName := ShadowBranchNameForCommit(opts.BaseCommit, opts.WorktreeID)
+	refName := plumbing.NewBranchReferenceName(shadowBranchName)
+	if _, err := s.repo.Reference(refName, true); err == nil {
+		return plumbing.ZeroHash, fmt.Errorf("shadow branch %s already exists", shadowBranchName)
+	}
+
+	tree, err := s.repo.TreeObject(opts.Tree)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to get tree of the fork point: %w", err)
+	}
+	entries := make(map[string]object.TreeEntry)
+	if err := FlattenTree(s.repo, tree, "", entries); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to flatten tree of the fork point: %w", err)
+	}
+	for entryPath := range entries {
+		if paths.IsInfrastructurePath(entryPath) {
+			delete(entries, entryPath)
+		}
+	}
+
+	metadataDir := paths.SessionMetadataDirFromSessionID(opts.SessionID)
+	if len(opts.Transcript) > 0 {
+		blobHash, err := CreateBlobFromContent(s.repo, opts.Transcript)
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to store transcript: %w", err)
+		}
+		treePath := metadataDir + "/" + paths.TranscriptFileName
+		entries[treePath] = object.TreeEntry{Name: treePath, Mode: filemode.Regular, Hash: blobHash}
+	}
+
+	treeHash, err := BuildTreeFromEntries(s.repo, entries)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to build tree: %w", err)
+	}
+
+	message := fmt.Sprintf("Fork of session %s at %s", opts.ParentSessionID, opts.ParentCheckpoint)
+	commitMsg := trailers.FormatShadowCommit(message, metadataDir, opts.SessionID)
+	commitHash, err := s.createCommit(treeHash, plumbing.ZeroHash, commitMsg, opts.AuthorName, opts.AuthorEmail)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to create commit: %w", err)
+	}
+
+	if err := s.repo.Storer.SetReference(plumbing.NewHashReference(refName, commitHash)); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to update branch reference: %w", err)
+	}
+	return commitHash, nil
+}
@@ -0,0 +1,476 @@
+package checkpoint
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// FsckCategory classifies a problem found by VerifyCommitted.
+type FsckCategory string
+
+// Problem categories found on the metadata branch.
+const (
+	// FsckMetadata: a checkpoint or session metadata.json is missing or unreadable
+	FsckMetadata FsckCategory = "metadata"
+
+	// FsckContentHash: content_hash.txt doesn't match the session's transcript
+	FsckContentHash FsckCategory = "content_hash"
+
+	// FsckMissingChunk: a chunk file of a chunked transcript is missing
+	FsckMissingChunk FsckCategory = "missing_chunk"
+
+	// FsckTranscript: a transcript can't be read, decompressed or reconstructed from its delta base
+	FsckTranscript FsckCategory = "transcript"
+
+	// FsckSessionPath: a path in CheckpointSummary.Sessions doesn't exist
+	FsckSessionPath FsckCategory = "dangling_session_path"
+
+	// FsckSummary: the aggregated statistics or session list of a
+	// CheckpointSummary don't match its session directories
+	FsckSummary FsckCategory = "summary_mismatch"
+)
+
+// FsckProblem is a single integrity problem.
+type FsckProblem struct {
+	Category     FsckCategory    `json:"category"`
+	CheckpointID id.CheckpointID `json:"checkpoint_id,omitempty"`
+	Path         string          `json:"path,omitempty"`
+	Message      string          `json:"message"`
+
+	// Repairable is true if RepairCommitted can fix the problem
+	Repairable bool `json:"repairable"`
+}
+
+// VerifyCommitted checks the integrity of every checkpoint on the metadata
+// branch: session paths listed in the summaries, aggregated statistics,
+// transcript chunks, delta bases and content hashes. Falls back to origin's
+// metadata branch like the readers. Returns no problems if neither exists.
+func (s *GitStore) VerifyCommitted(ctx context.Context) ([]FsckProblem, error) {
+	_ = ctx // Reserved for future use
+
+	if _, err := s.sessionsBranchRef(); err != nil {
+		return nil, nil //nolint:nilerr // No sessions branch means nothing to verify
+	}
+	tree, err := s.getSessionsBranchTree()
+	if err != nil {
+		return nil, err
+	}
+
+	// The checkpoints are listed from the tree rather than the checkpoint
+	// index, which can't be built from some of the damage reported here
+	checkpointIDs, err := s.listCheckpointIDs(tree)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []FsckProblem
+	for _, cpID := range checkpointIDs {
+		checkpointProblems, err := s.verifyCheckpoint(tree, cpID)
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, checkpointProblems...)
+	}
+	return problems, nil
+}
+
+// listCheckpointIDs lists the checkpoint directories of a metadata branch tree, sorted.
+func (s *GitStore) listCheckpointIDs(tree *object.Tree) ([]id.CheckpointID, error) {
+	var checkpointIDs []id.CheckpointID
+	for _, shard := range tree.Entries {
+		if shard.Mode != filemode.Dir || len(shard.Name) != 2 {
+			continue
+		}
+		shardTree, err := s.repo.TreeObject(shard.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read checkpoint shard %s: %w", shard.Name, err)
+		}
+		for _, entry := range shardTree.Entries {
+			if entry.Mode != filemode.Dir {
+				continue
+			}
+			if cpID, err := id.NewCheckpointID(shard.Name + entry.Name); err == nil {
+				checkpointIDs = append(checkpointIDs, cpID)
+			}
+		}
+	}
+	slices.Sort(checkpointIDs)
+	return checkpointIDs, nil
+}
+
+// verifyCheckpoint checks a single checkpoint directory.
+func (s *GitStore) verifyCheckpoint(tree *object.Tree, cpID id.CheckpointID) ([]FsckProblem, error) {
+	checkpointTree, err := tree.Tree(cpID.Path())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint %s: %w", cpID, err)
+	}
+	basePath := cpID.Path() + "/"
+	entries := make(map[string]object.TreeEntry)
+	if err := FlattenTree(s.repo, checkpointTree, cpID.Path(), entries); err != nil {
+		return nil, err
+	}
+
+	var problems []FsckProblem
+	report := func(category FsckCategory, path, format string, args ...any) {
+		problems = append(problems, FsckProblem{
+			Category:     category,
+			CheckpointID: cpID,
+			Path:         path,
+			Message:      fmt.Sprintf(format, args...),
+		})
+	}
+
+	summaryPath := basePath + paths.MetadataFileName
+	entry, ok := entries[summaryPath]
+	if !ok {
+		report(FsckMetadata, "/"+summaryPath, "checkpoint has no %s", paths.MetadataFileName)
+		return problems, nil
+	}
+	summary, err := s.readSummaryFromBlob(entry.Hash)
+	if err != nil {
+		report(FsckMetadata, "/"+summaryPath, "unreadable checkpoint summary: %v", err)
+		return problems, nil
+	}
+
+	// Paths listed in the summary
+	for i, session := range summary.Sessions {
+		for _, path := range []string{session.Metadata, session.Transcript, session.ContentHash, session.Prompt, session.Context} {
+			if path == "" {
+				continue
+			}
+			if _, ok := entries[strings.TrimPrefix(path, "/")]; !ok {
+				report(FsckSessionPath, path, "session %d lists a file that doesn't exist", i)
+			}
+		}
+	}
+
+	// Aggregated statistics and session list
+	repaired, repairErr := s.repairedSummary(basePath, summary, entries)
+	if repairErr != nil {
+		report(FsckSummary, "/"+summaryPath, "%v", repairErr)
+	} else {
+		if len(repaired.Sessions) > len(summary.Sessions) {
+			report(FsckSummary, "/"+summaryPath, "session directories %d-%d are not listed in the summary",
+				len(summary.Sessions), len(repaired.Sessions)-1)
+		}
+		if !sameStats(repaired, summary) {
+			report(FsckSummary, "/"+summaryPath, "aggregated statistics don't match the session metadata")
+		}
+	}
+
+	// Session directories
+	for i := range sessionDirCount(basePath, entries) {
+		sessionPath := basePath + strconv.Itoa(i)
+		sessionTree, err := checkpointTree.Tree(strconv.Itoa(i))
+		if err != nil {
+			continue
+		}
+		metadataEntry, ok := entries[sessionPath+"/"+paths.MetadataFileName]
+		if !ok {
+			continue
+		}
+		metadata, err := s.readMetadataFromBlob(metadataEntry.Hash)
+		if err != nil {
+			report(FsckMetadata, "/"+sessionPath+"/"+paths.MetadataFileName, "unreadable session metadata: %v", err)
+			continue
+		}
+
+		for _, missing := range missingTranscriptChunks(sessionTree) {
+			report(FsckMissingChunk, "/"+sessionPath+"/"+missing, "transcript chunk is missing")
+		}
+		for _, problem := range s.verifyContentHash(tree, sessionTree, metadata) {
+			report(problem.Category, "/"+sessionPath+"/"+problem.Path, "%s", problem.Message)
+		}
+	}
+
+	if repairErr == nil {
+		for i := range problems {
+			problems[i].Repairable = problems[i].Category == FsckSessionPath || problems[i].Category == FsckSummary
+		}
+	}
+	return problems, nil
+}
+
+// verifyContentHash checks content_hash.txt against the session's full
+// transcript, following delta bases. Paths of the returned problems are
+// relative to the session directory.
+func (s *GitStore) verifyContentHash(tree, sessionTree *object.Tree, metadata *CommittedMetadata) []FsckProblem {
+	hashFile, err := sessionTree.File(paths.ContentHashFileName)
+	if err != nil {
+		return nil
+	}
+	want, err := hashFile.Contents()
+	if err != nil {
+		return []FsckProblem{{Category: FsckContentHash, Path: paths.ContentHashFileName, Message: fmt.Sprintf("unreadable content hash: %v", err)}}
+	}
+
+	transcript, err := readTranscriptFromTree(sessionTree, metadata.Agent)
+	if errors.Is(err, ErrEncrypted) {
+		return nil // Can't be verified without the recipients' identity
+	}
+	if err != nil {
+		return []FsckProblem{{Category: FsckTranscript, Path: paths.TranscriptFileName, Message: err.Error()}}
+	}
+	if metadata.TranscriptDelta != nil {
+		transcript, err = s.resolveTranscriptDelta(tree, metadata, transcript, 0)
+		if errors.Is(err, ErrEncrypted) {
+			return nil
+		}
+		if err != nil {
+			return []FsckProblem{{Category: FsckTranscript, Path: paths.TranscriptFileName, Message: err.Error()}}
+		}
+	}
+
+	got := fmt.Sprintf("sha256:%x", sha256.Sum256(transcript))
+	if strings.TrimSpace(want) != got {
+		return []FsckProblem{{
+			Category: FsckContentHash,
+			Path:     paths.ContentHashFileName,
+			Message:  fmt.Sprintf("content hash is %s, transcript hashes to %s", strings.TrimSpace(want), got),
+		}}
+	}
+	return nil
+}
+
+// missingTranscriptChunks returns the names of missing chunk files of a
+// chunked transcript: chunks are numbered without gaps and chunk 0 is the
+// unsuffixed file (see agent.ChunkFileName).
+func missingTranscriptChunks(sessionTree *object.Tree) []string {
+	var missing []string
+	for _, compression := range transcriptCompressions {
+		for _, suffix := range []string{"", encryptedExtension} {
+			baseName := compressedFileName(paths.TranscriptFileName, compression) + suffix
+			present := make(map[int]bool)
+			last := -1
+			for _, entry := range sessionTree.Entries {
+				if idx := agent.ParseChunkIndex(entry.Name, baseName); idx >= 0 {
+					present[idx] = true
+					last = max(last, idx)
+				}
+			}
+			for i := 0; i < last; i++ {
+				if !present[i] {
+					missing = append(missing, agent.ChunkFileName(baseName, i))
+				}
+			}
+		}
+	}
+	return missing
+}
+
+// sessionDirCount returns the number of session directories of the
+// checkpoint at basePath, including directories after a gap.
+func sessionDirCount(basePath string, entries map[string]object.TreeEntry) int {
+	count := 0
+	for path := range entries {
+		rest, ok := strings.CutPrefix(path, basePath)
+		if !ok {
+			continue
+		}
+		dir, _, ok := strings.Cut(rest, "/")
+		if !ok {
+			continue
+		}
+		if i, err := strconv.Atoi(dir); err == nil && i >= 0 && strconv.Itoa(i) == dir {
+			count = max(count, i+1)
+		}
+	}
+	return count
+}
+
+// repairedSummary returns summary rebuilt from the session directories of the
+// checkpoint at basePath: session paths that don't exist are dropped, session
+// directories missing from the summary are added and the statistics are
+// reaggregated with reaggregateFromEntries. Returns an error if a session
+// directory has no metadata.json, since dropping it would renumber the
+// sessions after it.
+func (s *GitStore) repairedSummary(basePath string, summary *CheckpointSummary, entries map[string]object.TreeEntry) (*CheckpointSummary, error) {
+	dirs := sessionDirCount(basePath, entries)
+	var sessions []SessionFilePaths
+	for i := range dirs {
+		sessionPath := fmt.Sprintf("%s%d/", basePath, i)
+		if _, ok := entries[sessionPath+paths.MetadataFileName]; !ok {
+			return nil, fmt.Errorf("session %d has no %s, rebuilding the summary would renumber sessions", i, paths.MetadataFileName)
+		}
+		sessions = append(sessions, sessionFilePathsFromEntries(sessionPath, entries))
+	}
+	if len(sessions) == 0 {
+		return nil, errors.New("checkpoint has no session with metadata")
+	}
+
+	checkpointsCount, filesTouched, tokenUsage, err := s.reaggregateFromEntries(basePath, len(sessions), entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate session stats: %w", err)
+	}
+
+	repaired := *summary
+	repaired.Sessions = sessions
+	repaired.CheckpointsCount = checkpointsCount
+	repaired.FilesTouched = filesTouched
+	repaired.TokenUsage = tokenUsage
+	return &repaired, nil
+}
+
+// sessionFilePathsFromEntries returns the paths of the files present in the
+// session directory at sessionPath, in the form written by writeSessionToSubdirectory.
+func sessionFilePathsFromEntries(sessionPath string, entries map[string]object.TreeEntry) SessionFilePaths {
+	existing := func(name string) string {
+		for _, storedName := range []string{name, name + encryptedExtension} {
+			if _, ok := entries[sessionPath+storedName]; ok {
+				return "/" + sessionPath + storedName
+			}
+		}
+		return ""
+	}
+
+	filePaths := SessionFilePaths{
+		Metadata:    existing(paths.MetadataFileName),
+		ContentHash: existing(paths.ContentHashFileName),
+		Prompt:      existing(paths.PromptFileName),
+		Context:     existing(paths.ContextFileName),
+	}
+	for _, compression := range transcriptCompressions {
+		if filePaths.Transcript = existing(compressedFileName(paths.TranscriptFileName, compression)); filePaths.Transcript != "" {
+			break
+		}
+	}
+	if filePaths.Transcript == "" {
+		filePaths.Transcript = existing(paths.TranscriptFileNameLegacy)
+	}
+	return filePaths
+}
+
+// sameStats reports whether two summaries have the same aggregated statistics.
+func sameStats(a, b *CheckpointSummary) bool {
+	filesA, filesB := slices.Clone(a.FilesTouched), slices.Clone(b.FilesTouched)
+	slices.Sort(filesA)
+	slices.Sort(filesB)
+	return a.CheckpointsCount == b.CheckpointsCount &&
+		slices.Equal(filesA, filesB) &&
+		reflect.DeepEqual(a.TokenUsage, b.TokenUsage)
+}
+
+// RepairCommitted rebuilds the summaries of the given checkpoints from their
+// session directories (see repairedSummary) in a single commit on the local
+// metadata branch. Checkpoints that can't be repaired safely or don't need it
+// are left alone. Returns the checkpoints whose summary was rewritten.
+func (s *GitStore) RepairCommitted(ctx context.Context, checkpointIDs []id.CheckpointID) ([]id.CheckpointID, error) {
+	repaired, err := s.repairCommitted(ctx, checkpointIDs)
+	if err != nil {
+		return nil, err
+	}
+	s.updateCheckpointIndex()
+	return repaired, nil
+}
+
+// repairCommitted does the work of RepairCommitted, before the checkpoint index is updated.
+func (s *GitStore) repairCommitted(ctx context.Context, checkpointIDs []id.CheckpointID) ([]id.CheckpointID, error) {
+	_ = ctx // Reserved for future use
+
+	ref, entries, err := s.getSessionsBranchEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := slices.Clone(checkpointIDs)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+
+	var repaired []id.CheckpointID
+	for _, cpID := range sorted {
+		changed, err := s.repairCheckpointSummary(cpID, entries)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			repaired = append(repaired, cpID)
+		}
+	}
+	if len(repaired) == 0 {
+		return nil, nil
+	}
+
+	newTreeHash, err := BuildTreeFromEntries(s.repo, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	var commitMsg strings.Builder
+	fmt.Fprintf(&commitMsg, "Repair %d checkpoint summaries\n\n", len(repaired))
+	for _, cpID := range repaired {
+		fmt.Fprintf(&commitMsg, "%s\n", cpID)
+	}
+
+	authorName, authorEmail := getGitAuthorFromRepo(s.repo)
+	newCommitHash, err := s.createCommit(newTreeHash, ref.Hash(), commitMsg.String(), authorName, authorEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	if err := s.repo.Storer.SetReference(plumbing.NewHashReference(refName, newCommitHash)); err != nil {
+		return nil, fmt.Errorf("failed to set branch reference: %w", err)
+	}
+	return repaired, nil
+}
+
+// repairCheckpointSummary replaces the summary of a checkpoint in entries with
+// its repaired version. Returns false if the summary can't be repaired safely
+// or is already correct.
+func (s *GitStore) repairCheckpointSummary(cpID id.CheckpointID, entries map[string]object.TreeEntry) (bool, error) {
+	basePath := cpID.Path() + "/"
+	summaryPath := basePath + paths.MetadataFileName
+	entry, ok := entries[summaryPath]
+	if !ok {
+		return false, nil
+	}
+	summary, err := s.readSummaryFromBlob(entry.Hash)
+	if err != nil {
+		return false, nil //nolint:nilerr // Unreadable summaries are not repaired
+	}
+
+	checkpointEntries := make(map[string]object.TreeEntry)
+	for path, e := range entries {
+		if strings.HasPrefix(path, basePath) {
+			checkpointEntries[path] = e
+		}
+	}
+	repaired, err := s.repairedSummary(basePath, summary, checkpointEntries)
+	if err != nil {
+		return false, nil //nolint:nilerr // Summaries that can't be rebuilt safely are left alone
+	}
+
+	if slices.Equal(repaired.Sessions, summary.Sessions) && sameStats(repaired, summary) {
+		return false, nil
+	}
+
+	metadataJSON, err := jsonutil.MarshalIndentWithNewline(repaired, "", "  ")
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal checkpoint summary: %w", err)
+	}
+	metadataHash, err := CreateBlobFromContent(s.repo, metadataJSON)
+	if err != nil {
+		return false, err
+	}
+	entries[summaryPath] = object.TreeEntry{
+		Name: summaryPath,
+		Mode: filemode.Regular,
+		Hash: metadataHash,
+	}
+	return true, nil
+}
@@ -0,0 +1,166 @@
+package checkpoint
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// rewriteMetadataBranch commits the result of edit applied to the metadata
+// branch's entries, simulating corruption.
+func rewriteMetadataBranch(t *testing.T, store *GitStore, edit func(entries map[string]object.TreeEntry)) {
+	t.Helper()
+	ref, entries, err := store.getSessionsBranchEntries()
+	if err != nil {
+		t.Fatalf("getSessionsBranchEntries() error = %v", err)
+	}
+	edit(entries)
+	treeHash, err := BuildTreeFromEntries(store.repo, entries)
+	if err != nil {
+		t.Fatalf("BuildTreeFromEntries() error = %v", err)
+	}
+	commitHash, err := store.createCommit(treeHash, ref.Hash(), "corrupt", "Test", "test@test.com")
+	if err != nil {
+		t.Fatalf("createCommit() error = %v", err)
+	}
+	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	if err := store.repo.Storer.SetReference(plumbing.NewHashReference(refName, commitHash)); err != nil {
+		t.Fatalf("SetReference() error = %v", err)
+	}
+}
+
+func setBlob(t *testing.T, store *GitStore, entries map[string]object.TreeEntry, path, content string) {
+	t.Helper()
+	hash, err := CreateBlobFromContent(store.repo, []byte(content))
+	if err != nil {
+		t.Fatalf("CreateBlobFromContent() error = %v", err)
+	}
+	entries[path] = object.TreeEntry{Name: path, Mode: filemode.Regular, Hash: hash}
+}
+
+func problemsByCategory(problems []FsckProblem) map[FsckCategory][]FsckProblem {
+	byCategory := make(map[FsckCategory][]FsckProblem)
+	for _, problem := range problems {
+		byCategory[problem.Category] = append(byCategory[problem.Category], problem)
+	}
+	return byCategory
+}
+
+// TestVerifyCommitted_Healthy verifies that checkpoints written by the store,
+// including delta transcripts, have no problems.
+func TestVerifyCommitted_Healthy(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	first := id.MustCheckpointID("f1f2f3f4f5a1")
+	second := id.MustCheckpointID("f1f2f3f4f5a2")
+	writeDeltaCheckpoint(t, store, first, id.EmptyCheckpointID, transcriptLines(3), CompressionNone)
+	writeDeltaCheckpoint(t, store, second, first, transcriptLines(5), CompressionGzip)
+
+	problems, err := store.VerifyCommitted(context.Background())
+	if err != nil {
+		t.Fatalf("VerifyCommitted() error = %v", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("VerifyCommitted() = %+v, want no problems", problems)
+	}
+}
+
+// TestVerifyCommitted_DetectsAndRepairs verifies that corruption is reported
+// by category and that only the summary problems are repaired.
+func TestVerifyCommitted_DetectsAndRepairs(t *testing.T) {
+	store, cpID := writeSingleSession(t, "f1f2f3f4f5b1", "session-1", `{"type":"user"}`+"\n")
+	sessionPath := cpID.Path() + "/0/"
+
+	rewriteMetadataBranch(t, store, func(entries map[string]object.TreeEntry) {
+		setBlob(t, store, entries, sessionPath+paths.ContentHashFileName, "sha256:0000")
+		setBlob(t, store, entries, sessionPath+paths.TranscriptFileName+".002", `{"type":"assistant"}`+"\n")
+		// A second session directory the summary doesn't list
+		setBlob(t, store, entries, cpID.Path()+"/1/"+paths.MetadataFileName,
+			`{"session_id":"session-2","checkpoints_count":2,"files_touched":["b.go"]}`)
+	})
+
+	problems, err := store.VerifyCommitted(context.Background())
+	if err != nil {
+		t.Fatalf("VerifyCommitted() error = %v", err)
+	}
+	byCategory := problemsByCategory(problems)
+	if got := byCategory[FsckContentHash]; len(got) != 1 || got[0].Repairable {
+		t.Errorf("content_hash problems = %+v, want 1 not repairable", got)
+	}
+	if got := byCategory[FsckMissingChunk]; len(got) != 1 || got[0].Path != "/"+sessionPath+paths.TranscriptFileName+".001" {
+		t.Errorf("missing_chunk problems = %+v, want %s.001", got, paths.TranscriptFileName)
+	}
+	if got := byCategory[FsckSummary]; len(got) != 2 || !got[0].Repairable {
+		t.Errorf("summary_mismatch problems = %+v, want 2 repairable", got)
+	}
+
+	repaired, err := store.RepairCommitted(context.Background(), []id.CheckpointID{cpID})
+	if err != nil {
+		t.Fatalf("RepairCommitted() error = %v", err)
+	}
+	if len(repaired) != 1 || repaired[0] != cpID {
+		t.Errorf("RepairCommitted() = %v, want [%s]", repaired, cpID)
+	}
+
+	summary, err := store.ReadCommitted(context.Background(), cpID)
+	if err != nil {
+		t.Fatalf("ReadCommitted() error = %v", err)
+	}
+	if len(summary.Sessions) != 2 || summary.CheckpointsCount != 3 || len(summary.FilesTouched) != 1 {
+		t.Errorf("repaired summary = %+v, want 2 sessions, 3 checkpoints, 1 file", summary)
+	}
+	if summary.Sessions[1].Transcript != "" {
+		t.Errorf("Sessions[1].Transcript = %q, want dangling path dropped", summary.Sessions[1].Transcript)
+	}
+
+	problems, err = store.VerifyCommitted(context.Background())
+	if err != nil {
+		t.Fatalf("VerifyCommitted() error = %v", err)
+	}
+	byCategory = problemsByCategory(problems)
+	if len(byCategory[FsckSummary]) != 0 || len(byCategory[FsckContentHash]) != 1 {
+		t.Errorf("problems after repair = %+v, want only unrepairable ones", problems)
+	}
+}
+
+// TestVerifyCommitted_DanglingSessionPath verifies that a session listed in
+// the summary whose directory is gone is dropped by the repair.
+func TestVerifyCommitted_DanglingSessionPath(t *testing.T) {
+	store, cpID := writeSingleSession(t, "f1f2f3f4f5c1", "session-1", `{"type":"user"}`+"\n")
+	writeIndexTestCheckpoint(t, store, "f1f2f3f4f5c1", "session-2")
+
+	rewriteMetadataBranch(t, store, func(entries map[string]object.TreeEntry) {
+		for path := range entries {
+			if strings.HasPrefix(path, cpID.Path()+"/1/") {
+				delete(entries, path)
+			}
+		}
+	})
+
+	problems, err := store.VerifyCommitted(context.Background())
+	if err != nil {
+		t.Fatalf("VerifyCommitted() error = %v", err)
+	}
+	dangling := problemsByCategory(problems)[FsckSessionPath]
+	if len(dangling) == 0 || !dangling[0].Repairable {
+		t.Fatalf("dangling_session_path problems = %+v, want repairable problems", dangling)
+	}
+
+	if _, err := store.RepairCommitted(context.Background(), []id.CheckpointID{cpID}); err != nil {
+		t.Fatalf("RepairCommitted() error = %v", err)
+	}
+	summary, err := store.ReadCommitted(context.Background(), cpID)
+	if err != nil {
+		t.Fatalf("ReadCommitted() error = %v", err)
+	}
+	if len(summary.Sessions) != 1 || summary.CheckpointsCount != 1 {
+		t.Errorf("repaired summary = %+v, want the remaining session only", summary)
+	}
+}
@@ -0,0 +1,415 @@
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+)
+
+// checkpointIndexFileName is the file in the git directory that caches the
+// checkpoint index (.git/entire-checkpoint-index.json).
+const checkpointIndexFileName = "entire-checkpoint-index.json"
+
+// checkpointIndexVersion is bumped when the index format changes; indexes
+// with another version are rebuilt from scratch.
+const checkpointIndexVersion = 1
+
+// checkpointIndex is a local cache of the checkpoints and commit links on the
+// metadata branch, so listing and lookups don't decode every metadata.json.
+//
+// The index records the branch tip it describes and the tree hash of every
+// shard directory and checkpoint. When the tip moves (a new checkpoint, a fetch,
+// a merge), only shards and checkpoints whose tree hash changed are read again,
+// so the index never goes stale no matter who updated the branch.
+type checkpointIndex struct {
+	Version int `json:"version"`
+
+	// Tip is the metadata branch commit the index describes
+	Tip string `json:"tip"`
+
+	// Shards maps shard directories ("a3", "commits/a3") to their tree hashes
+	Shards map[string]string `json:"shards"`
+
+	Checkpoints map[id.CheckpointID]*checkpointIndexEntry `json:"checkpoints"`
+
+	// Commits maps commit SHAs to checkpoint IDs from commit links (see CommitLink)
+	Commits map[string]id.CheckpointID `json:"commits"`
+}
+
+// checkpointIndexEntry describes one checkpoint in the index.
+type checkpointIndexEntry struct {
+	// Tree is the hash of the checkpoint's directory tree
+	Tree string `json:"tree"`
+
+	// Summary is the root metadata.json; nil if the checkpoint has none
+	Summary *CheckpointSummary `json:"summary,omitempty"`
+
+	// Details of the latest session
+	SessionID string          `json:"session_id,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	Agent     agent.AgentType `json:"agent,omitempty"`
+	IsTask    bool            `json:"is_task,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+
+	SessionIDs []string `json:"session_ids,omitempty"`
+	Branch     string   `json:"branch,omitempty"`
+
+	// Commits are the commit SHAs linked to the checkpoint by commit links.
+	// Commits linked by their Entire-Checkpoint trailer aren't known to the metadata branch.
+	Commits []string `json:"commits,omitempty"`
+}
+
+func newCheckpointIndex(tip plumbing.Hash) *checkpointIndex {
+	return &checkpointIndex{
+		Version:     checkpointIndexVersion,
+		Tip:         tip.String(),
+		Shards:      make(map[string]string),
+		Checkpoints: make(map[id.CheckpointID]*checkpointIndexEntry),
+		Commits:     make(map[string]id.CheckpointID),
+	}
+}
+
+// committedInfo returns the CommittedInfo for an indexed checkpoint.
+func (e *checkpointIndexEntry) committedInfo(checkpointID id.CheckpointID) CommittedInfo {
+	info := CommittedInfo{
+		CheckpointID: checkpointID,
+		SessionID:    e.SessionID,
+		CreatedAt:    e.CreatedAt,
+		Agent:        e.Agent,
+		IsTask:       e.IsTask,
+		ToolUseID:    e.ToolUseID,
+		SessionIDs:   slices.Clone(e.SessionIDs),
+		Branch:       e.Branch,
+		Commits:      slices.Clone(e.Commits),
+	}
+	if e.Summary != nil {
+		info.CheckpointsCount = e.Summary.CheckpointsCount
+		info.FilesTouched = slices.Clone(e.Summary.FilesTouched)
+		info.SessionCount = len(e.Summary.Sessions)
+	}
+	return info
+}
+
+// summary returns a copy of the checkpoint's summary that callers may modify.
+func (e *checkpointIndexEntry) summary() *CheckpointSummary {
+	if e.Summary == nil {
+		return nil
+	}
+	summary := *e.Summary
+	summary.FilesTouched = slices.Clone(e.Summary.FilesTouched)
+	summary.Sessions = slices.Clone(e.Summary.Sessions)
+	if e.Summary.TokenUsage != nil {
+		usage := *e.Summary.TokenUsage
+		summary.TokenUsage = &usage
+	}
+	return &summary
+}
+
+// checkpointIndex returns the index of the metadata branch, bringing it up to
+// date with the branch tip first. Falls back to origin's metadata branch like
+// getSessionsBranchTree. Returns nil, nil if neither branch exists.
+func (s *GitStore) checkpointIndex() (*checkpointIndex, error) {
+	ref, err := s.sessionsBranchRef()
+	if err != nil {
+		return nil, nil //nolint:nilnil,nilerr // No sessions branch means nothing to index
+	}
+	tip := ref.Hash()
+
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
+	// Another process may have indexed the current tip already; otherwise
+	// whichever index is at hand is the starting point of the refresh
+	if s.index == nil || s.index.Tip != tip.String() {
+		if cached := s.readIndexFile(); cached != nil && (s.index == nil || cached.Tip == tip.String()) {
+			s.index = cached
+		}
+	}
+	if s.index != nil && s.index.Tip == tip.String() {
+		return s.index, nil
+	}
+
+	idx, err := s.buildCheckpointIndex(tip, s.index)
+	if err != nil {
+		return nil, err
+	}
+	s.index = idx
+	s.writeIndexFile(idx)
+	return idx, nil
+}
+
+// updateCheckpointIndex brings the index up to date after this store moved the
+// metadata branch. The index is only a cache, so failures are left to the next
+// reader, which retries the refresh.
+func (s *GitStore) updateCheckpointIndex() {
+	_, _ = s.checkpointIndex() //nolint:errcheck // Best-effort, readers refresh the index themselves
+}
+
+// buildCheckpointIndex indexes the metadata branch at tip. Entries of prev
+// whose shard or checkpoint tree is unchanged are reused without reading them.
+func (s *GitStore) buildCheckpointIndex(tip plumbing.Hash, prev *checkpointIndex) (*checkpointIndex, error) {
+	commit, err := s.repo.CommitObject(tip)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit object: %w", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit tree: %w", err)
+	}
+
+	if prev == nil || prev.Version != checkpointIndexVersion {
+		prev = newCheckpointIndex(plumbing.ZeroHash)
+	}
+	idx := newCheckpointIndex(tip)
+	unchanged := make(map[string]bool)
+
+	for _, entry := range tree.Entries {
+		if entry.Mode != filemode.Dir {
+			continue
+		}
+		switch {
+		case entry.Name == paths.CommitLinksDir:
+			s.indexCommitLinks(idx, prev, entry.Hash, unchanged)
+		case len(entry.Name) == 2:
+			if prev.Shards[entry.Name] == entry.Hash.String() {
+				unchanged[entry.Name] = true
+				idx.Shards[entry.Name] = entry.Hash.String()
+				continue
+			}
+			s.indexCheckpointShard(idx, prev, entry.Name, entry.Hash)
+		}
+	}
+
+	for cpID, entry := range prev.Checkpoints {
+		if unchanged[string(cpID)[:2]] {
+			idx.Checkpoints[cpID] = entry
+		}
+	}
+	for commitSHA, cpID := range prev.Commits {
+		if unchanged[paths.CommitLinksDir+"/"+commitSHA[:2]] {
+			idx.Commits[commitSHA] = cpID
+		}
+	}
+
+	for _, entry := range idx.Checkpoints {
+		entry.Commits = nil
+	}
+	for commitSHA, cpID := range idx.Commits {
+		if entry, ok := idx.Checkpoints[cpID]; ok {
+			entry.Commits = append(entry.Commits, commitSHA)
+		}
+	}
+	for _, entry := range idx.Checkpoints {
+		slices.Sort(entry.Commits)
+	}
+
+	return idx, nil
+}
+
+// indexCheckpointShard indexes the checkpoints in a shard directory (<id[:2]>/).
+//
+// Unreadable trees don't fail the index, so one damaged checkpoint doesn't hide
+// the others: an unreadable shard is left out, and a checkpoint whose tree is
+// unreadable is indexed with empty fields, like one without metadata.json. In
+// both cases the shard isn't recorded as indexed, so the next refresh reads it
+// again in case the missing objects were fetched since.
+func (s *GitStore) indexCheckpointShard(idx, prev *checkpointIndex, shard string, hash plumbing.Hash) {
+	shardTree, err := s.repo.TreeObject(hash)
+	if err != nil {
+		warnUnreadableTree("checkpoint shard "+shard, err)
+		return
+	}
+
+	complete := true
+	for _, entry := range shardTree.Entries {
+		if entry.Mode != filemode.Dir {
+			continue
+		}
+		cpID, err := id.NewCheckpointID(shard + entry.Name)
+		if err != nil {
+			// Skip invalid checkpoint IDs (shouldn't happen with our own data)
+			continue
+		}
+		if old, ok := prev.Checkpoints[cpID]; ok && old.Tree == entry.Hash.String() {
+			idx.Checkpoints[cpID] = old
+			continue
+		}
+		indexed, err := s.readCheckpointIndexEntry(entry.Hash)
+		if err != nil {
+			warnUnreadableTree("checkpoint "+cpID.String(), err)
+			// No tree hash, so the entry isn't reused by the next refresh
+			indexed = &checkpointIndexEntry{}
+			complete = false
+		}
+		idx.Checkpoints[cpID] = indexed
+	}
+
+	if complete {
+		idx.Shards[shard] = hash.String()
+	}
+}
+
+// warnUnreadableTree logs a tree left out of the checkpoint index.
+func warnUnreadableTree(what string, err error) {
+	logging.Warn(context.Background(), "skipping unreadable tree in checkpoint index",
+		slog.String("tree", what),
+		slog.String("error", err.Error()),
+	)
+}
+
+// readCheckpointIndexEntry reads the index entry of a checkpoint directory.
+// Unreadable metadata leaves the entry's fields empty, as ListCommitted always did.
+func (s *GitStore) readCheckpointIndexEntry(hash plumbing.Hash) (*checkpointIndexEntry, error) {
+	checkpointTree, err := s.repo.TreeObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint tree: %w", err)
+	}
+
+	entry := &checkpointIndexEntry{Tree: hash.String()}
+
+	metadataFile, err := checkpointTree.File(paths.MetadataFileName)
+	if err != nil {
+		return entry, nil //nolint:nilerr // Checkpoint without metadata.json
+	}
+	summary, err := s.readSummaryFromBlob(metadataFile.Hash)
+	if err != nil {
+		return entry, nil //nolint:nilerr // Unparseable metadata.json
+	}
+	entry.Summary = summary
+	entry.Branch = summary.Branch
+
+	for i := range summary.Sessions {
+		sessionFile, fileErr := checkpointTree.File(strconv.Itoa(i) + "/" + paths.MetadataFileName)
+		if fileErr != nil {
+			continue
+		}
+		metadata, readErr := s.readMetadataFromBlob(sessionFile.Hash)
+		if readErr != nil {
+			continue
+		}
+		entry.SessionIDs = append(entry.SessionIDs, metadata.SessionID)
+		if i == len(summary.Sessions)-1 {
+			entry.SessionID = metadata.SessionID
+			entry.CreatedAt = metadata.CreatedAt
+			entry.Agent = metadata.Agent
+			entry.IsTask = metadata.IsTask
+			entry.ToolUseID = metadata.ToolUseID
+			if entry.Branch == "" {
+				entry.Branch = metadata.Branch
+			}
+		}
+	}
+
+	return entry, nil
+}
+
+// indexCommitLinks indexes the commit links under commits/. Unchanged shards
+// are marked in unchanged so their links are carried over from prev. Unreadable
+// shards are left out and read again by the next refresh, like checkpoint shards.
+func (s *GitStore) indexCommitLinks(idx, prev *checkpointIndex, hash plumbing.Hash, unchanged map[string]bool) {
+	linksTree, err := s.repo.TreeObject(hash)
+	if err != nil {
+		warnUnreadableTree(paths.CommitLinksDir, err)
+		return
+	}
+
+	for _, shardEntry := range linksTree.Entries {
+		if shardEntry.Mode != filemode.Dir || len(shardEntry.Name) != 2 {
+			continue
+		}
+		shard := paths.CommitLinksDir + "/" + shardEntry.Name
+		if prev.Shards[shard] == shardEntry.Hash.String() {
+			idx.Shards[shard] = shardEntry.Hash.String()
+			unchanged[shard] = true
+			continue
+		}
+
+		shardTree, err := s.repo.TreeObject(shardEntry.Hash)
+		if err != nil {
+			warnUnreadableTree(shard, err)
+			continue
+		}
+		idx.Shards[shard] = shardEntry.Hash.String()
+		for _, entry := range shardTree.Entries {
+			commitSHA := shardEntry.Name + strings.TrimSuffix(entry.Name, ".json")
+			if !entry.Mode.IsFile() || !isFullCommitSHA(commitSHA) {
+				continue
+			}
+			link, err := readJSONFromBlob[CommitLink](s.repo, entry.Hash)
+			if err != nil || link.CheckpointID.IsEmpty() {
+				continue
+			}
+			idx.Commits[commitSHA] = link.CheckpointID
+		}
+	}
+}
+
+// indexFilePath returns the path of the index file, or "" if the repository
+// isn't stored on disk (e.g. in-memory repositories in tests).
+func (s *GitStore) indexFilePath() string {
+	gitDir := repositoryGitDir(s.repo)
+	if gitDir == "" {
+		return ""
+	}
+	return filepath.Join(gitDir, checkpointIndexFileName)
+}
+
+// readIndexFile loads the cached index. Returns nil if there is none or it
+// can't be used; the index is then rebuilt from the metadata branch.
+func (s *GitStore) readIndexFile() *checkpointIndex {
+	indexPath := s.indexFilePath()
+	if indexPath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(indexPath) //nolint:gosec // Path is in the repository's git directory
+	if err != nil {
+		return nil
+	}
+	var idx checkpointIndex
+	if err := json.Unmarshal(data, &idx); err != nil || idx.Version != checkpointIndexVersion {
+		return nil
+	}
+	if idx.Shards == nil || idx.Checkpoints == nil || idx.Commits == nil {
+		return nil
+	}
+	return &idx
+}
+
+// writeIndexFile caches the index. Failures are ignored, as the index is
+// rebuilt from the metadata branch when it can't be read.
+func (s *GitStore) writeIndexFile(idx *checkpointIndex) {
+	indexPath := s.indexFilePath()
+	if indexPath == "" {
+		return
+	}
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return
+	}
+
+	// Atomic write: write to temp file, then rename, so concurrent hooks never read a partial index
+	tmpFile, err := os.CreateTemp(filepath.Dir(indexPath), checkpointIndexFileName+".tmp-")
+	if err != nil {
+		return
+	}
+	_, writeErr := tmpFile.Write(data)
+	closeErr := tmpFile.Close()
+	if writeErr != nil || closeErr != nil || os.Rename(tmpFile.Name(), indexPath) != nil {
+		_ = os.Remove(tmpFile.Name())
+	}
+}
@@ -0,0 +1,221 @@
+package checkpoint
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func writeIndexTestCheckpoint(t *testing.T, store *GitStore, cpIDStr, sessionID string) id.CheckpointID {
+	t.Helper()
+	checkpointID := id.MustCheckpointID(cpIDStr)
+	err := store.WriteCommitted(context.Background(), WriteCommittedOptions{
+		CheckpointID:     checkpointID,
+		SessionID:        sessionID,
+		Strategy:         "manual-commit",
+		Branch:           "feature",
+		Transcript:       []byte(`{"type":"user","message":"hi"}` + "\n"),
+		FilesTouched:     []string{"main.go"},
+		CheckpointsCount: 1,
+		AuthorName:       "Test Author",
+		AuthorEmail:      "test@example.com",
+	})
+	if err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+	return checkpointID
+}
+
+// TestCheckpointIndex_ReusesUnchangedCheckpoints verifies that updating the
+// index after a write only reads the checkpoints that changed.
+func TestCheckpointIndex_ReusesUnchangedCheckpoints(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+
+	first := writeIndexTestCheckpoint(t, store, "a1b2c3d4e5f6", "session-1")
+	idx, err := store.checkpointIndex()
+	if err != nil {
+		t.Fatalf("checkpointIndex() error = %v", err)
+	}
+	firstEntry := idx.Checkpoints[first]
+	if firstEntry == nil {
+		t.Fatalf("index has no entry for %s", first)
+	}
+
+	second := writeIndexTestCheckpoint(t, store, "b1b2c3d4e5f6", "session-2")
+	idx, err = store.checkpointIndex()
+	if err != nil {
+		t.Fatalf("checkpointIndex() error = %v", err)
+	}
+	if idx.Checkpoints[first] != firstEntry {
+		t.Error("entry of unchanged checkpoint was read again, want it reused")
+	}
+	entry := idx.Checkpoints[second]
+	if entry == nil {
+		t.Fatalf("index has no entry for %s", second)
+	}
+	if entry.SessionID != "session-2" || entry.Branch != "feature" || entry.Summary == nil {
+		t.Errorf("entry = %+v, want session-2 on branch feature with a summary", entry)
+	}
+}
+
+// TestCheckpointIndex_FollowsBranchUpdatedElsewhere verifies that a store sees
+// checkpoints written by another store (e.g. another process) and that a
+// corrupt index file is rebuilt.
+func TestCheckpointIndex_FollowsBranchUpdatedElsewhere(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	writer := NewGitStore(repo)
+	reader := NewGitStore(repo)
+
+	writeIndexTestCheckpoint(t, writer, "a1b2c3d4e5f6", "session-1")
+	committed, err := reader.ListCommitted(context.Background())
+	if err != nil {
+		t.Fatalf("ListCommitted() error = %v", err)
+	}
+	if len(committed) != 1 {
+		t.Fatalf("ListCommitted() returned %d checkpoints, want 1", len(committed))
+	}
+
+	writeIndexTestCheckpoint(t, writer, "a1c2c3d4e5f6", "session-2")
+	committed, err = reader.ListCommitted(context.Background())
+	if err != nil {
+		t.Fatalf("ListCommitted() error = %v", err)
+	}
+	if len(committed) != 2 {
+		t.Fatalf("ListCommitted() returned %d checkpoints, want 2", len(committed))
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	indexPath := filepath.Join(wt.Filesystem.Root(), git.GitDirName, checkpointIndexFileName)
+	if _, err := os.Stat(indexPath); err != nil {
+		t.Fatalf("index file not written: %v", err)
+	}
+	if err := os.WriteFile(indexPath, []byte("not json"), 0o600); err != nil {
+		t.Fatalf("failed to corrupt index: %v", err)
+	}
+
+	fresh := NewGitStore(repo)
+	summary, err := fresh.ReadCommitted(context.Background(), id.MustCheckpointID("a1c2c3d4e5f6"))
+	if err != nil {
+		t.Fatalf("ReadCommitted() error = %v", err)
+	}
+	if summary == nil || summary.CheckpointsCount != 1 {
+		t.Errorf("ReadCommitted() = %+v, want checkpoint with 1 checkpoint", summary)
+	}
+}
+
+// TestListCommitted_IncludesBranchAndLinkedCommits verifies that the branch
+// and the commits linked by commit links are listed with a checkpoint.
+func TestListCommitted_IncludesBranchAndLinkedCommits(t *testing.T) {
+	repo, commitHash := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	cpID := writeIndexTestCheckpoint(t, store, "a1b2c3d4e5f6", "session-1")
+
+	if err := store.WriteCommitLinks(context.Background(), []CommitLink{{
+		CommitSHA:    commitHash.String(),
+		CheckpointID: cpID,
+		RewriteType:  "rebase",
+	}}); err != nil {
+		t.Fatalf("WriteCommitLinks() error = %v", err)
+	}
+
+	committed, err := store.ListCommitted(context.Background())
+	if err != nil {
+		t.Fatalf("ListCommitted() error = %v", err)
+	}
+	if len(committed) != 1 {
+		t.Fatalf("ListCommitted() returned %d checkpoints, want 1", len(committed))
+	}
+	info := committed[0]
+	if info.Branch != "feature" {
+		t.Errorf("Branch = %q, want %q", info.Branch, "feature")
+	}
+	if len(info.Commits) != 1 || info.Commits[0] != commitHash.String() {
+		t.Errorf("Commits = %v, want [%s]", info.Commits, commitHash)
+	}
+	if len(info.SessionIDs) != 1 || info.SessionIDs[0] != "session-1" {
+		t.Errorf("SessionIDs = %v, want [session-1]", info.SessionIDs)
+	}
+}
+
+// TestCheckpointIndex_MissingCheckpointTree verifies that a checkpoint whose
+// tree object is missing doesn't hide the other checkpoints.
+func TestCheckpointIndex_MissingCheckpointTree(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	readable := writeIndexTestCheckpoint(t, store, "a1b2c3d4e5f6", "session-1")
+	broken := writeIndexTestCheckpoint(t, store, "b1b2c3d4e5f6", "session-2")
+
+	tree, err := store.getSessionsBranchTree()
+	if err != nil {
+		t.Fatalf("failed to read metadata branch: %v", err)
+	}
+	brokenTree, err := tree.Tree(broken.Path())
+	if err != nil {
+		t.Fatalf("failed to find %s: %v", broken, err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	dir := wt.Filesystem.Root()
+	hash := brokenTree.Hash.String()
+	if err := os.Remove(filepath.Join(dir, ".git", "objects", hash[:2], hash[2:])); err != nil {
+		t.Fatalf("failed to remove the tree of %s: %v", broken, err)
+	}
+	if err := os.Remove(store.indexFilePath()); err != nil {
+		t.Fatalf("failed to remove the checkpoint index: %v", err)
+	}
+
+	reopened, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+	store = NewGitStore(reopened)
+
+	checkpoints, err := store.ListCommitted(context.Background())
+	if err != nil {
+		t.Fatalf("ListCommitted() error = %v", err)
+	}
+	listed := make(map[id.CheckpointID]CommittedInfo)
+	for _, info := range checkpoints {
+		listed[info.CheckpointID] = info
+	}
+	if info, ok := listed[readable]; !ok || info.SessionID != "session-1" {
+		t.Errorf("ListCommitted() = %+v, want %s with session-1", checkpoints, readable)
+	}
+	if info, ok := listed[broken]; !ok || info.SessionID != "" {
+		t.Errorf("ListCommitted() = %+v, want %s with empty fields", checkpoints, broken)
+	}
+
+	summary, err := store.ReadCommitted(context.Background(), readable)
+	if err != nil {
+		t.Fatalf("ReadCommitted() error = %v", err)
+	}
+	if summary == nil || summary.CheckpointID != readable {
+		t.Errorf("ReadCommitted() = %+v, want the summary of %s", summary, readable)
+	}
+	if summary, err := store.ReadCommitted(context.Background(), broken); err != nil || summary != nil {
+		t.Errorf("ReadCommitted(broken) = %v, %v; want nil, nil", summary, err)
+	}
+
+	// The shard with the broken checkpoint is read again on the next refresh
+	idx, err := store.checkpointIndex()
+	if err != nil {
+		t.Fatalf("checkpointIndex() error = %v", err)
+	}
+	if _, ok := idx.Shards[string(broken)[:2]]; ok {
+		t.Errorf("shard of %s recorded as indexed", broken)
+	}
+	if _, ok := idx.Shards[string(readable)[:2]]; !ok {
+		t.Errorf("shard of %s not recorded as indexed", readable)
+	}
+}
@@ -0,0 +1,378 @@
+package checkpoint
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/buildinfo"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// MergeMetadataEntries merges two versions of the metadata branch, given as
+// flattened trees, into a new set of entries.
+//
+// Checkpoints present on one side only, or identical on both, are kept as they
+// are. For a checkpoint both sides wrote to:
+//   - sessions only one side has keep their directory on the local side and
+//     are renumbered after the local sessions on the remote side;
+//   - a session both sides have (same session ID) is taken from the side with
+//     more checkpoints, or the later one, preferring local on a tie, and its
+//     Summary is merged with the other side's (an encrypted summary is kept
+//     whole, from the other side only if the winner has none);
+//   - the root metadata.json is rebuilt from the merged sessions.
+//
+// A session directory whose metadata.json can't be read is kept unchanged: in
+// place on the local side, after the local sessions on the remote side (unless
+// the local side has the same one).
+//
+// Other files are combined, the remote version winning, as before.
+//
+// There is no merge base: a checkpoint or session one side deleted (e.g. with
+// 'entire prune' on another clone) is brought back by the other side's copy.
+// Pruning records the rewritten branch so that pre-push force-pushes it
+// instead of merging (see strategy.RecordPrunedMetadataBranch), but only in
+// the clone that pruned.
+func MergeMetadataEntries(repo *git.Repository, local, remote map[string]object.TreeEntry) (map[string]object.TreeEntry, error) {
+	merged := make(map[string]object.TreeEntry, len(local)+len(remote))
+	localCheckpoints := splitCheckpointEntries(local, merged)
+	remoteCheckpoints := splitCheckpointEntries(remote, merged)
+
+	for cpID, localFiles := range localCheckpoints {
+		remoteFiles, ok := remoteCheckpoints[cpID]
+		if !ok || sameEntries(localFiles, remoteFiles) {
+			addCheckpointEntries(merged, cpID, localFiles)
+			continue
+		}
+		files, err := mergeCheckpointFiles(repo, cpID, localFiles, remoteFiles)
+		if err != nil {
+			return nil, fmt.Errorf("failed to merge checkpoint %s: %w", cpID, err)
+		}
+		addCheckpointEntries(merged, cpID, files)
+	}
+	for cpID, remoteFiles := range remoteCheckpoints {
+		if _, ok := localCheckpoints[cpID]; !ok {
+			addCheckpointEntries(merged, cpID, remoteFiles)
+		}
+	}
+	return merged, nil
+}
+
+// splitCheckpointEntries groups the entries of checkpoint directories by
+// checkpoint, keyed by path relative to the checkpoint directory. Other
+// entries are added to rest.
+func splitCheckpointEntries(entries, rest map[string]object.TreeEntry) map[id.CheckpointID]map[string]object.TreeEntry {
+	checkpoints := make(map[id.CheckpointID]map[string]object.TreeEntry)
+	for entryPath, entry := range entries {
+		parts := strings.SplitN(entryPath, "/", 3)
+		if len(parts) == 3 && len(parts[0]) == 2 {
+			if cpID, err := id.NewCheckpointID(parts[0] + parts[1]); err == nil {
+				if checkpoints[cpID] == nil {
+					checkpoints[cpID] = make(map[string]object.TreeEntry)
+				}
+				checkpoints[cpID][parts[2]] = entry
+				continue
+			}
+		}
+		rest[entryPath] = entry
+	}
+	return checkpoints
+}
+
+func addCheckpointEntries(merged map[string]object.TreeEntry, cpID id.CheckpointID, files map[string]object.TreeEntry) {
+	basePath := cpID.Path() + "/"
+	for relPath, entry := range files {
+		entry.Name = basePath + relPath
+		merged[entry.Name] = entry
+	}
+}
+
+func sameEntries(a, b map[string]object.TreeEntry) bool {
+	return maps.EqualFunc(a, b, func(x, y object.TreeEntry) bool {
+		return x.Hash == y.Hash && x.Mode == y.Mode
+	})
+}
+
+// checkpointSide is one side's version of a checkpoint being merged.
+type checkpointSide struct {
+	files    map[string]object.TreeEntry
+	summary  *CheckpointSummary
+	sessions []mergeSession
+}
+
+// mergeSession is a session directory of a checkpoint being merged.
+type mergeSession struct {
+	index int
+
+	// metadata is nil if the session's metadata.json can't be read; the
+	// directory is then kept as it is
+	metadata *CommittedMetadata
+
+	paths SessionFilePaths
+}
+
+func readCheckpointSide(repo *git.Repository, files map[string]object.TreeEntry) *checkpointSide {
+	side := &checkpointSide{files: files}
+	if entry, ok := files[paths.MetadataFileName]; ok {
+		if summary, err := readJSONFromBlob[CheckpointSummary](repo, entry.Hash); err == nil {
+			side.summary = summary
+		}
+	}
+
+	var indexes []int
+	for relPath := range files {
+		dir, name, found := strings.Cut(relPath, "/")
+		if !found || name != paths.MetadataFileName {
+			continue
+		}
+		if index, err := strconv.Atoi(dir); err == nil && index >= 0 && strconv.Itoa(index) == dir {
+			indexes = append(indexes, index)
+		}
+	}
+	slices.Sort(indexes)
+
+	for _, index := range indexes {
+		// Unreadable metadata is left nil
+		metadata, _ := readJSONFromBlob[CommittedMetadata](repo, files[strconv.Itoa(index)+"/"+paths.MetadataFileName].Hash) //nolint:errcheck // See mergeSession
+		session := mergeSession{index: index, metadata: metadata}
+		if side.summary != nil && index < len(side.summary.Sessions) {
+			session.paths = side.summary.Sessions[index]
+		}
+		side.sessions = append(side.sessions, session)
+	}
+	return side
+}
+
+// hasSessionMetadata reports whether one of the side's sessions has the given
+// metadata.json, to recognize a session directory both sides have unchanged.
+func (side *checkpointSide) hasSessionMetadata(metadataEntry object.TreeEntry) bool {
+	for _, session := range side.sessions {
+		if side.files[strconv.Itoa(session.index)+"/"+paths.MetadataFileName].Hash == metadataEntry.Hash {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeCheckpointFiles merges the files of a checkpoint both sides changed.
+func mergeCheckpointFiles(repo *git.Repository, cpID id.CheckpointID, localFiles, remoteFiles map[string]object.TreeEntry) (map[string]object.TreeEntry, error) {
+	local := readCheckpointSide(repo, localFiles)
+	remote := readCheckpointSide(repo, remoteFiles)
+	basePath := cpID.Path() + "/"
+
+	// Files outside session directories (task checkpoints), local winning
+	files := make(map[string]object.TreeEntry)
+	for _, side := range []*checkpointSide{remote, local} {
+		for relPath, entry := range side.files {
+			if !isSessionFile(relPath) && relPath != paths.MetadataFileName {
+				files[relPath] = entry
+			}
+		}
+	}
+
+	remoteByID := make(map[string]mergeSession, len(remote.sessions))
+	for _, session := range remote.sessions {
+		if session.metadata != nil {
+			remoteByID[session.metadata.SessionID] = session
+		}
+	}
+
+	var sessions []SessionFilePaths
+	var metadata []*CommittedMetadata
+	addSession := func(side *checkpointSide, session mergeSession, sessionMetadata *CommittedMetadata) error {
+		newIndex := len(sessions)
+		oldPrefix := strconv.Itoa(session.index) + "/"
+		newPrefix := strconv.Itoa(newIndex) + "/"
+		for relPath, entry := range side.files {
+			if rest, ok := strings.CutPrefix(relPath, oldPrefix); ok {
+				files[newPrefix+rest] = entry
+			}
+		}
+		if sessionMetadata != session.metadata {
+			hash, err := writeJSONBlob(repo, sessionMetadata)
+			if err != nil {
+				return err
+			}
+			files[newPrefix+paths.MetadataFileName] = object.TreeEntry{Mode: filemode.Regular, Hash: hash}
+		}
+		sessions = append(sessions, renumberSessionPaths(session.paths, basePath, oldPrefix, newPrefix))
+		metadata = append(metadata, sessionMetadata)
+		return nil
+	}
+
+	// Local sessions keep their place; sessions both sides have are merged.
+	// Sessions whose metadata can't be read are kept as they are.
+	for _, session := range local.sessions {
+		if session.metadata == nil {
+			if err := addSession(local, session, nil); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		other, both := remoteByID[session.metadata.SessionID]
+		if !both {
+			if err := addSession(local, session, session.metadata); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		delete(remoteByID, session.metadata.SessionID)
+
+		winnerSide, winner, loserSide, loser := local, session, remote, other
+		if isNewerSession(other.metadata, session.metadata) {
+			winnerSide, winner, loserSide, loser = remote, other, local, session
+		}
+		sessionMetadata := winner.metadata
+		if summary := mergeSummaries(winner.metadata.Summary, loser.metadata.Summary); summary != winner.metadata.Summary {
+			updated := *winner.metadata
+			updated.Summary = summary
+			sessionMetadata = &updated
+		}
+		if err := addSession(winnerSide, winner, sessionMetadata); err != nil {
+			return nil, err
+		}
+		// Encrypted summaries can't be merged; keep the loser's if the winner has none
+		summaryName := paths.SessionSummaryFileName + encryptedExtension
+		newSummaryPath := strconv.Itoa(len(sessions)-1) + "/" + summaryName
+		if _, ok := files[newSummaryPath]; !ok && winner.metadata.Summary == nil {
+			if entry, ok := loserSide.files[strconv.Itoa(loser.index)+"/"+summaryName]; ok {
+				files[newSummaryPath] = entry
+			}
+		}
+	}
+
+	// Remote-only sessions are renumbered after the local ones
+	for _, session := range remote.sessions {
+		if session.metadata == nil {
+			if local.hasSessionMetadata(remote.files[strconv.Itoa(session.index)+"/"+paths.MetadataFileName]) {
+				continue
+			}
+		} else if _, ok := remoteByID[session.metadata.SessionID]; !ok {
+			continue
+		}
+		if err := addSession(remote, session, session.metadata); err != nil {
+			return nil, err
+		}
+	}
+
+	summary := &CheckpointSummary{CheckpointID: cpID, CLIVersion: buildinfo.Version}
+	for _, side := range []*checkpointSide{remote, local} {
+		if side.summary != nil {
+			summary.Strategy = side.summary.Strategy
+			summary.Branch = side.summary.Branch
+		}
+	}
+	summary.Sessions = sessions
+	for _, sessionMetadata := range metadata {
+		if sessionMetadata == nil {
+			continue
+		}
+		summary.CheckpointsCount += sessionMetadata.CheckpointsCount
+		summary.FilesTouched = mergeFilesTouched(summary.FilesTouched, sessionMetadata.FilesTouched)
+		summary.TokenUsage = aggregateTokenUsage(summary.TokenUsage, sessionMetadata.TokenUsage)
+	}
+	hash, err := writeJSONBlob(repo, summary)
+	if err != nil {
+		return nil, err
+	}
+	files[paths.MetadataFileName] = object.TreeEntry{Mode: filemode.Regular, Hash: hash}
+	return files, nil
+}
+
+// isSessionFile reports whether a path relative to a checkpoint directory is
+// inside a session directory (0/, 1/, ...).
+func isSessionFile(relPath string) bool {
+	dir, _, found := strings.Cut(relPath, "/")
+	if !found {
+		return false
+	}
+	_, err := strconv.Atoi(dir)
+	return err == nil
+}
+
+// isNewerSession reports whether a is a later version of the same session than b.
+func isNewerSession(a, b *CommittedMetadata) bool {
+	if a.CheckpointsCount != b.CheckpointsCount {
+		return a.CheckpointsCount > b.CheckpointsCount
+	}
+	return a.CreatedAt.After(b.CreatedAt)
+}
+
+// renumberSessionPaths moves the file paths of a session from its old
+// directory to its new one.
+func renumberSessionPaths(filePaths SessionFilePaths, basePath, oldPrefix, newPrefix string) SessionFilePaths {
+	if oldPrefix == newPrefix {
+		return filePaths
+	}
+	move := func(p string) string {
+		if rest, ok := strings.CutPrefix(p, "/"+basePath+oldPrefix); ok {
+			return "/" + basePath + newPrefix + rest
+		}
+		return p
+	}
+	return SessionFilePaths{
+		Metadata:    move(filePaths.Metadata),
+		Transcript:  move(filePaths.Transcript),
+		Context:     move(filePaths.Context),
+		ContentHash: move(filePaths.ContentHash),
+		Prompt:      move(filePaths.Prompt),
+	}
+}
+
+// mergeSummaries merges the summaries of two versions of a session. Intent and
+// outcome come from primary unless empty; lists are combined without
+// duplicates. Returns primary itself if other is nil.
+func mergeSummaries(primary, other *Summary) *Summary {
+	if other == nil || primary == other {
+		return primary
+	}
+	if primary == nil {
+		return other
+	}
+	merged := &Summary{
+		Intent:  primary.Intent,
+		Outcome: primary.Outcome,
+		Learnings: LearningsSummary{
+			Repo:     union(primary.Learnings.Repo, other.Learnings.Repo),
+			Code:     union(primary.Learnings.Code, other.Learnings.Code),
+			Workflow: union(primary.Learnings.Workflow, other.Learnings.Workflow),
+		},
+		Friction:  union(primary.Friction, other.Friction),
+		OpenItems: union(primary.OpenItems, other.OpenItems),
+	}
+	if merged.Intent == "" {
+		merged.Intent = other.Intent
+	}
+	if merged.Outcome == "" {
+		merged.Outcome = other.Outcome
+	}
+	return merged
+}
+
+// union appends the items of b missing from a, keeping a's order.
+func union[T comparable](a, b []T) []T {
+	result := slices.Clone(a)
+	for _, item := range b {
+		if !slices.Contains(result, item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func writeJSONBlob(repo *git.Repository, v any) (plumbing.Hash, error) {
+	data, err := jsonutil.MarshalIndentWithNewline(v, "", "  ")
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	return CreateBlobFromContent(repo, data)
+}
@@ -0,0 +1,76 @@
+package checkpoint
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// TestMergeMetadataEntries_KeepsUnreadableSessions verifies that a session
+// whose metadata.json can't be parsed is kept unchanged by a merge instead of
+// being dropped, and isn't duplicated when both sides have it.
+func TestMergeMetadataEntries_KeepsUnreadableSessions(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	cpID := writeIndexTestCheckpoint(t, store, "d0d0d0d0d0d1", "session-1")
+	_, base, err := store.getSessionsBranchEntries()
+	if err != nil {
+		t.Fatalf("failed to read metadata branch: %v", err)
+	}
+	basePath := cpID.Path() + "/"
+
+	blob := func(content string) plumbing.Hash {
+		t.Helper()
+		hash, err := CreateBlobFromContent(repo, []byte(content))
+		if err != nil {
+			t.Fatalf("failed to create blob: %v", err)
+		}
+		return hash
+	}
+	withSession := func(entries map[string]object.TreeEntry, metadata, transcript plumbing.Hash) map[string]object.TreeEntry {
+		entries = maps.Clone(entries)
+		for name, hash := range map[string]plumbing.Hash{paths.MetadataFileName: metadata, paths.TranscriptFileName: transcript} {
+			entries[basePath+"1/"+name] = object.TreeEntry{Name: basePath + "1/" + name, Mode: filemode.Regular, Hash: hash}
+		}
+		return entries
+	}
+	localMetadata, localTranscript := blob("{not json"), blob("local transcript\n")
+	remoteMetadata, remoteTranscript := blob("{also not json"), blob("remote transcript\n")
+	local := withSession(base, localMetadata, localTranscript)
+	remote := withSession(base, remoteMetadata, remoteTranscript)
+
+	merged, err := MergeMetadataEntries(repo, local, remote)
+	if err != nil {
+		t.Fatalf("MergeMetadataEntries() error = %v", err)
+	}
+	for relPath, want := range map[string]plumbing.Hash{
+		"0/" + paths.MetadataFileName:   base[basePath+"0/"+paths.MetadataFileName].Hash,
+		"1/" + paths.MetadataFileName:   localMetadata,
+		"1/" + paths.TranscriptFileName: localTranscript,
+		"2/" + paths.MetadataFileName:   remoteMetadata,
+		"2/" + paths.TranscriptFileName: remoteTranscript,
+	} {
+		if got := merged[basePath+relPath].Hash; got != want {
+			t.Errorf("merged %s = %s, want %s", relPath, got, want)
+		}
+	}
+
+	// The same unreadable session on both sides is kept once
+	remote = maps.Clone(local)
+	remote[basePath+"tasks/tool-1/checkpoint.json"] = object.TreeEntry{Mode: filemode.Regular, Hash: blob("{}")}
+	merged, err = MergeMetadataEntries(repo, local, remote)
+	if err != nil {
+		t.Fatalf("MergeMetadataEntries() error = %v", err)
+	}
+	if merged[basePath+"1/"+paths.MetadataFileName].Hash != localMetadata {
+		t.Errorf("the unreadable session isn't kept in place")
+	}
+	if _, ok := merged[basePath+"2/"+paths.MetadataFileName]; ok {
+		t.Errorf("the unreadable session both sides have is duplicated")
+	}
+}
@@ -0,0 +1,229 @@
+package checkpoint
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// commitNotes holds the checkpoint IDs of the notes on refs/notes/entire at Tip.
+type commitNotes struct {
+	Tip     plumbing.Hash
+	Commits map[string]id.CheckpointID
+}
+
+// WriteCommitNote links a code commit to a checkpoint with a note on
+// refs/notes/entire, replacing any note the commit already has.
+func (s *GitStore) WriteCommitNote(ctx context.Context, commitHash plumbing.Hash, checkpointID id.CheckpointID) error {
+	_ = ctx // Reserved for future use
+
+	if checkpointID.IsEmpty() {
+		return fmt.Errorf("invalid note for %s: checkpoint ID is required", commitHash)
+	}
+
+	refName := plumbing.ReferenceName(paths.CommitNotesRef)
+	parentHash := plumbing.ZeroHash
+	entries := make(map[string]object.TreeEntry)
+	if ref, err := s.repo.Reference(refName, true); err == nil {
+		parentHash = ref.Hash()
+		if err := s.readNotesEntries(parentHash, entries); err != nil {
+			return err
+		}
+	}
+
+	content := fmt.Sprintf("%s: %s\n", trailers.CheckpointTrailerKey, checkpointID)
+	blobHash, err := CreateBlobFromContent(s.repo, []byte(content))
+	if err != nil {
+		return err
+	}
+	sha := commitHash.String()
+	entries[sha] = object.TreeEntry{Name: sha, Mode: filemode.Regular, Hash: blobHash}
+
+	treeHash, err := BuildTreeFromEntries(s.repo, entries)
+	if err != nil {
+		return err
+	}
+	authorName, authorEmail := getGitAuthorFromRepo(s.repo)
+	commitMsg := fmt.Sprintf("Link %s to checkpoint %s\n", sha[:7], checkpointID)
+	notesCommit, err := s.createCommit(treeHash, parentHash, commitMsg, authorName, authorEmail)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Storer.SetReference(plumbing.NewHashReference(refName, notesCommit)); err != nil {
+		return fmt.Errorf("failed to set notes reference: %w", err)
+	}
+	return nil
+}
+
+// readNotesEntries adds the notes of a notes commit to entries, keyed by the
+// annotated commit's full SHA. Fanout directories (ab/cdef...) written by git
+// are flattened.
+func (s *GitStore) readNotesEntries(notesCommit plumbing.Hash, entries map[string]object.TreeEntry) error {
+	commit, err := s.repo.CommitObject(notesCommit)
+	if err != nil {
+		return fmt.Errorf("failed to read notes commit: %w", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return fmt.Errorf("failed to read notes tree: %w", err)
+	}
+	flat := make(map[string]object.TreeEntry)
+	if err := FlattenTree(s.repo, tree, "", flat); err != nil {
+		return fmt.Errorf("failed to read notes tree: %w", err)
+	}
+	for notePath, entry := range flat {
+		sha := strings.ReplaceAll(notePath, "/", "")
+		if !isFullCommitSHA(sha) {
+			continue
+		}
+		entry.Name = sha
+		entries[sha] = entry
+	}
+	return nil
+}
+
+// MergeCommitNotes merges a notes commit fetched from a remote into
+// refs/notes/entire. Notes of commits only annotated on one side are kept;
+// for commits annotated on both sides the local note wins.
+func (s *GitStore) MergeCommitNotes(ctx context.Context, remoteTip plumbing.Hash) error {
+	_ = ctx // Reserved for future use
+
+	refName := plumbing.ReferenceName(paths.CommitNotesRef)
+	ref, err := s.repo.Reference(refName, true)
+	if err != nil {
+		// No local notes yet: take the remote ones as they are
+		if err := s.repo.Storer.SetReference(plumbing.NewHashReference(refName, remoteTip)); err != nil {
+			return fmt.Errorf("failed to set notes reference: %w", err)
+		}
+		return nil
+	}
+	localTip := ref.Hash()
+	if localTip == remoteTip {
+		return nil
+	}
+
+	localCommit, err := s.repo.CommitObject(localTip)
+	if err != nil {
+		return fmt.Errorf("failed to read local notes commit: %w", err)
+	}
+	remoteCommit, err := s.repo.CommitObject(remoteTip)
+	if err != nil {
+		return fmt.Errorf("failed to read remote notes commit: %w", err)
+	}
+	if isAncestor, err := remoteCommit.IsAncestor(localCommit); err == nil && isAncestor {
+		return nil // Remote notes are already merged
+	}
+	if isAncestor, err := localCommit.IsAncestor(remoteCommit); err == nil && isAncestor {
+		if err := s.repo.Storer.SetReference(plumbing.NewHashReference(refName, remoteTip)); err != nil {
+			return fmt.Errorf("failed to set notes reference: %w", err)
+		}
+		return nil
+	}
+
+	entries := make(map[string]object.TreeEntry)
+	if err := s.readNotesEntries(remoteTip, entries); err != nil {
+		return err
+	}
+	if err := s.readNotesEntries(localTip, entries); err != nil {
+		return err
+	}
+	treeHash, err := BuildTreeFromEntries(s.repo, entries)
+	if err != nil {
+		return err
+	}
+
+	authorName, authorEmail := getGitAuthorFromRepo(s.repo)
+	sig := object.Signature{Name: authorName, Email: authorEmail, When: time.Now()}
+	merge := &object.Commit{
+		TreeHash:     treeHash,
+		ParentHashes: []plumbing.Hash{localTip, remoteTip},
+		Author:       sig,
+		Committer:    sig,
+		Message:      "Merge remote checkpoint notes\n",
+	}
+	SignCommit(s.repo, merge)
+	obj := s.repo.Storer.NewEncodedObject()
+	if err := merge.Encode(obj); err != nil {
+		return fmt.Errorf("failed to encode notes merge commit: %w", err)
+	}
+	mergeHash, err := s.repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return fmt.Errorf("failed to store notes merge commit: %w", err)
+	}
+	if err := s.repo.Storer.SetReference(plumbing.NewHashReference(refName, mergeHash)); err != nil {
+		return fmt.Errorf("failed to set notes reference: %w", err)
+	}
+	return nil
+}
+
+// ReadCommitNote returns the checkpoint a commit's note on refs/notes/entire links it to.
+func (s *GitStore) ReadCommitNote(ctx context.Context, commitHash plumbing.Hash) (id.CheckpointID, bool) {
+	_ = ctx // Reserved for future use
+
+	notes := s.commitNotes()
+	if notes == nil {
+		return id.EmptyCheckpointID, false
+	}
+	cpID, found := notes.Commits[commitHash.String()]
+	return cpID, found
+}
+
+// commitNotes returns the notes on refs/notes/entire, reading them again only
+// when the notes ref moved. Returns nil if there are no notes.
+func (s *GitStore) commitNotes() *commitNotes {
+	ref, err := s.repo.Reference(plumbing.ReferenceName(paths.CommitNotesRef), true)
+	if err != nil {
+		return nil
+	}
+
+	s.notesMu.Lock()
+	defer s.notesMu.Unlock()
+	if s.notes != nil && s.notes.Tip == ref.Hash() {
+		return s.notes
+	}
+
+	entries := make(map[string]object.TreeEntry)
+	if err := s.readNotesEntries(ref.Hash(), entries); err != nil {
+		return nil
+	}
+	notes := &commitNotes{Tip: ref.Hash(), Commits: make(map[string]id.CheckpointID, len(entries))}
+	for sha, entry := range entries {
+		blob, err := s.repo.BlobObject(entry.Hash)
+		if err != nil {
+			continue
+		}
+		content, err := readBlob(blob)
+		if err != nil {
+			continue
+		}
+		if cpID, found := trailers.ParseCheckpoint(string(content)); found {
+			notes.Commits[sha] = cpID
+		}
+	}
+	s.notes = notes
+	return notes
+}
+
+func readBlob(blob *object.Blob) ([]byte, error) {
+	r, err := blob.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob: %w", err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob: %w", err)
+	}
+	return data, nil
+}
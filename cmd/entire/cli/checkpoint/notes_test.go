@@ -0,0 +1,175 @@
+package checkpoint
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// runGitNotes runs git notes on the entire notes ref of the repository.
+func runGitNotes(t *testing.T, repo *git.Repository, args ...string) string {
+	t.Helper()
+	cmdArgs := append([]string{"--git-dir", repositoryGitDir(repo), "notes", "--ref", paths.CommitNotesRef}, args...)
+	cmd := exec.CommandContext(context.Background(), "git", cmdArgs...)
+	cmd.Env = append(cmd.Environ(),
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@test.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@test.com",
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git notes %v failed: %v: %s", args, err, output)
+	}
+	return string(output)
+}
+
+// commitEmpty creates an empty commit on HEAD.
+func commitEmpty(t *testing.T, repo *git.Repository, message string) plumbing.Hash {
+	t.Helper()
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	hash, err := worktree.Commit(message, &git.CommitOptions{
+		Author:            &object.Signature{Name: "Test", Email: "test@test.com"},
+		AllowEmptyCommits: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	return hash
+}
+
+// TestWriteCommitNote_RoundTrip verifies that a note links the commit to its
+// checkpoint and is readable by git notes.
+func TestWriteCommitNote_RoundTrip(t *testing.T) {
+	repo, commitHash := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	cpID := id.MustCheckpointID("a1b2c3d4e5f6")
+
+	commit, err := repo.CommitObject(commitHash)
+	if err != nil {
+		t.Fatalf("failed to get commit: %v", err)
+	}
+	if _, found := store.CheckpointIDForCommit(context.Background(), commit); found {
+		t.Error("CheckpointIDForCommit() found checkpoint before note was written")
+	}
+
+	if err := store.WriteCommitNote(context.Background(), commitHash, cpID); err != nil {
+		t.Fatalf("WriteCommitNote() error = %v", err)
+	}
+
+	got, found := store.CheckpointIDForCommit(context.Background(), commit)
+	if !found || got != cpID {
+		t.Errorf("CheckpointIDForCommit() = %q, %v; want %q, true", got, found, cpID)
+	}
+
+	note := runGitNotes(t, repo, "show", commitHash.String())
+	if want := "Entire-Checkpoint: " + cpID.String(); strings.TrimSpace(note) != want {
+		t.Errorf("git notes show = %q, want %q", note, want)
+	}
+
+	// Writing again replaces the note
+	replacedID := id.MustCheckpointID("f6e5d4c3b2a1")
+	if err := store.WriteCommitNote(context.Background(), commitHash, replacedID); err != nil {
+		t.Fatalf("WriteCommitNote() error = %v", err)
+	}
+	if got, _ := store.ReadCommitNote(context.Background(), commitHash); got != replacedID {
+		t.Errorf("ReadCommitNote() = %q, want %q", got, replacedID)
+	}
+}
+
+// TestReadCommitNote_WrittenByGit verifies that notes added with git notes are read.
+func TestReadCommitNote_WrittenByGit(t *testing.T) {
+	repo, commitHash := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	cpID := id.MustCheckpointID("a1b2c3d4e5f6")
+
+	runGitNotes(t, repo, "add", "-m", "Entire-Checkpoint: "+cpID.String(), commitHash.String())
+
+	got, found := store.ReadCommitNote(context.Background(), commitHash)
+	if !found || got != cpID {
+		t.Errorf("ReadCommitNote() = %q, %v; want %q, true", got, found, cpID)
+	}
+}
+
+// TestMergeCommitNotes_Diverged verifies that notes written on both sides
+// since the last sync are all kept in a merge commit.
+func TestMergeCommitNotes_Diverged(t *testing.T) {
+	repo, first := setupBranchTestRepo(t)
+	second := commitEmpty(t, repo, "Second commit")
+	third := commitEmpty(t, repo, "Third commit")
+	store := NewGitStore(repo)
+	ctx := context.Background()
+	refName := plumbing.ReferenceName(paths.CommitNotesRef)
+
+	firstID := id.MustCheckpointID("111111111111")
+	secondID := id.MustCheckpointID("222222222222")
+	thirdID := id.MustCheckpointID("333333333333")
+
+	if err := store.WriteCommitNote(ctx, first, firstID); err != nil {
+		t.Fatalf("WriteCommitNote() error = %v", err)
+	}
+	base, err := repo.Reference(refName, true)
+	if err != nil {
+		t.Fatalf("failed to read notes ref: %v", err)
+	}
+
+	// Remote side annotates the third commit
+	if err := store.WriteCommitNote(ctx, third, thirdID); err != nil {
+		t.Fatalf("WriteCommitNote() error = %v", err)
+	}
+	remote, err := repo.Reference(refName, true)
+	if err != nil {
+		t.Fatalf("failed to read notes ref: %v", err)
+	}
+
+	// Local side annotates the second commit
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(refName, base.Hash())); err != nil {
+		t.Fatalf("failed to reset notes ref: %v", err)
+	}
+	if err := store.WriteCommitNote(ctx, second, secondID); err != nil {
+		t.Fatalf("WriteCommitNote() error = %v", err)
+	}
+	local, err := repo.Reference(refName, true)
+	if err != nil {
+		t.Fatalf("failed to read notes ref: %v", err)
+	}
+
+	if err := store.MergeCommitNotes(ctx, remote.Hash()); err != nil {
+		t.Fatalf("MergeCommitNotes() error = %v", err)
+	}
+
+	merged, err := repo.Reference(refName, true)
+	if err != nil {
+		t.Fatalf("failed to read notes ref: %v", err)
+	}
+	mergeCommit, err := repo.CommitObject(merged.Hash())
+	if err != nil {
+		t.Fatalf("failed to read merge commit: %v", err)
+	}
+	if len(mergeCommit.ParentHashes) != 2 || mergeCommit.ParentHashes[0] != local.Hash() || mergeCommit.ParentHashes[1] != remote.Hash() {
+		t.Errorf("merge parents = %v, want [%s %s]", mergeCommit.ParentHashes, local.Hash(), remote.Hash())
+	}
+
+	for commitHash, want := range map[plumbing.Hash]id.CheckpointID{first: firstID, second: secondID, third: thirdID} {
+		if got, found := store.ReadCommitNote(ctx, commitHash); !found || got != want {
+			t.Errorf("ReadCommitNote(%s) = %q, %v; want %q, true", commitHash, got, found, want)
+		}
+	}
+
+	// Merging the remote again is a no-op
+	if err := store.MergeCommitNotes(ctx, remote.Hash()); err != nil {
+		t.Fatalf("MergeCommitNotes() error = %v", err)
+	}
+	if again, _ := repo.Reference(refName, true); again.Hash() != merged.Hash() {
+		t.Errorf("notes ref moved to %s after merging an ancestor", again.Hash())
+	}
+}
@@ -0,0 +1,75 @@
+package checkpoint
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// overlayStorage is a repository storage that keeps new objects in memory and
+// reads other objects from the repository, so trees can be built and compared
+// without writing anything to .git/objects. References, config and the index
+// are the repository's.
+type overlayStorage struct {
+	storage.Storer
+
+	objects *memory.Storage
+}
+
+// newOverlayRepository returns a repository handle over repo that keeps the
+// objects written through it in memory. Objects read through it include
+// repo's; repo doesn't see the in-memory ones.
+func newOverlayRepository(repo *git.Repository) (*git.Repository, error) {
+	overlay, err := git.Open(&overlayStorage{Storer: repo.Storer, objects: memory.NewStorage()}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open in-memory repository: %w", err)
+	}
+	return overlay, nil
+}
+
+func (s *overlayStorage) NewEncodedObject() plumbing.EncodedObject {
+	return &plumbing.MemoryObject{}
+}
+
+func (s *overlayStorage) SetEncodedObject(obj plumbing.EncodedObject) (plumbing.Hash, error) {
+	return s.objects.SetEncodedObject(obj) //nolint:wrapcheck // Storer interface
+}
+
+func (s *overlayStorage) EncodedObject(t plumbing.ObjectType, h plumbing.Hash) (plumbing.EncodedObject, error) {
+	obj, err := s.objects.EncodedObject(t, h)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return s.Storer.EncodedObject(t, h) //nolint:wrapcheck // Storer interface
+	}
+	return obj, err //nolint:wrapcheck // Storer interface
+}
+
+func (s *overlayStorage) HasEncodedObject(h plumbing.Hash) error {
+	if s.objects.HasEncodedObject(h) == nil {
+		return nil
+	}
+	return s.Storer.HasEncodedObject(h) //nolint:wrapcheck // Storer interface
+}
+
+func (s *overlayStorage) EncodedObjectSize(h plumbing.Hash) (int64, error) {
+	if size, err := s.objects.EncodedObjectSize(h); err == nil {
+		return size, nil
+	}
+	return s.Storer.EncodedObjectSize(h) //nolint:wrapcheck // Storer interface
+}
+
+func (s *overlayStorage) IterEncodedObjects(t plumbing.ObjectType) (storer.EncodedObjectIter, error) {
+	memIter, err := s.objects.IterEncodedObjects(t)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // Storer interface
+	}
+	repoIter, err := s.Storer.IterEncodedObjects(t)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // Storer interface
+	}
+	return storer.NewMultiEncodedObjectIter([]storer.EncodedObjectIter{memIter, repoIter}), nil
+}
@@ -0,0 +1,709 @@
+package checkpoint
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// PruneOptions configures Prune. Zero durations and sizes disable their rule.
+type PruneOptions struct {
+	// MaxAge removes checkpoints created longer ago
+	MaxAge time.Duration
+
+	// MaxSize removes the oldest checkpoints until the checkpoint data at the
+	// branch tip fits in this many bytes
+	MaxSize int64
+
+	// DropTranscriptsAfter removes the transcripts of checkpoints created
+	// longer ago, keeping their metadata, prompts and context
+	DropTranscriptsAfter time.Duration
+
+	// Protected checkpoints are never pruned (e.g. referenced from protected branches)
+	Protected map[id.CheckpointID]bool
+
+	// Now is the time ages are measured from; defaults to time.Now()
+	Now time.Time
+
+	// DryRun computes the plan and the bytes reclaimed without rewriting the branch
+	DryRun bool
+}
+
+// PrunePlan lists the checkpoints affected by a prune.
+type PrunePlan struct {
+	// Remove lists checkpoints removed entirely, with their commit links
+	Remove []id.CheckpointID `json:"remove,omitempty"`
+
+	// DropTranscripts lists checkpoints whose transcripts and content hashes are removed
+	DropTranscripts []id.CheckpointID `json:"drop_transcripts,omitempty"`
+}
+
+// IsEmpty reports whether the plan prunes nothing.
+func (p PrunePlan) IsEmpty() bool {
+	return len(p.Remove) == 0 && len(p.DropTranscripts) == 0
+}
+
+// Contains reports whether a metadata branch path belongs to a checkpoint
+// in the plan.
+func (p PrunePlan) Contains(entryPath string) bool {
+	for _, cpID := range slices.Concat(p.Remove, p.DropTranscripts) {
+		if strings.HasPrefix(entryPath, cpID.Path()+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// PruneResult describes the outcome of Prune.
+type PruneResult struct {
+	Plan PrunePlan
+
+	// Checkpoints is the number of checkpoints before pruning
+	Checkpoints int
+
+	// Size is the size in bytes of the checkpoint data at the branch tip before pruning
+	Size int64
+
+	// RetainedSize is the size in bytes of the checkpoint data left at the
+	// branch tip after pruning. It can exceed PruneOptions.MaxSize when
+	// protected checkpoints or delta bases don't fit.
+	RetainedSize int64
+
+	// KeptBases lists checkpoints the policy would prune that are kept
+	// because kept delta transcripts build on their transcripts
+	KeptBases []id.CheckpointID
+
+	// BytesReclaimed is the size of the objects that only pruned data
+	// referenced, across the branch history. The space is freed once the old
+	// history is unreachable and git gc runs.
+	BytesReclaimed int64
+
+	// CommitsRewritten is the number of metadata branch commits rewritten
+	CommitsRewritten int
+
+	// OldTip and NewTip are the branch tips before and after pruning;
+	// NewTip is zero for dry runs and empty plans
+	OldTip plumbing.Hash
+	NewTip plumbing.Hash
+}
+
+// checkpointUsage describes the storage used by a checkpoint at the branch tip.
+type checkpointUsage struct {
+	id             id.CheckpointID
+	createdAt      time.Time
+	size           int64
+	transcriptSize int64
+
+	// bases are the checkpoints whose transcripts the delta transcripts of this checkpoint extend
+	bases []id.CheckpointID
+}
+
+// Prune applies a retention policy to the local metadata branch. Checkpoints
+// are removed or stripped of their transcripts by rewriting the branch
+// history, so the pruned data is no longer referenced. Protected checkpoints,
+// and the delta bases of transcripts that are kept, are never pruned.
+//
+// The rewritten branch no longer descends from the remote one; pushing it
+// requires a force push (see strategy.RecordPrunedMetadataBranch).
+func (s *GitStore) Prune(ctx context.Context, opts PruneOptions) (*PruneResult, error) {
+	if opts.DryRun {
+		return s.prune(ctx, opts)
+	}
+	result, err := s.prune(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	s.updateCheckpointIndex()
+	return result, nil
+}
+
+// prune does the work of Prune, before the checkpoint index is updated.
+func (s *GitStore) prune(ctx context.Context, opts PruneOptions) (*PruneResult, error) {
+	_ = ctx // Reserved for future use
+
+	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	ref, err := s.repo.Reference(refName, true)
+	if err != nil {
+		return &PruneResult{}, nil //nolint:nilerr // No local metadata branch means nothing to prune
+	}
+	tipCommit, err := s.repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit object: %w", err)
+	}
+	tipTree, err := tipCommit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit tree: %w", err)
+	}
+	idx, err := s.buildCheckpointIndex(ref.Hash(), s.index)
+	if err != nil {
+		return nil, err
+	}
+
+	usages := make([]*checkpointUsage, 0, len(idx.Checkpoints))
+	for cpID, entry := range idx.Checkpoints {
+		usage, err := s.readCheckpointUsage(tipTree, cpID, entry.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		usages = append(usages, usage)
+	}
+
+	plan, keptBases := planPrune(usages, opts)
+	result := &PruneResult{
+		Plan:        plan,
+		Checkpoints: len(usages),
+		KeptBases:   keptBases,
+		OldTip:      ref.Hash(),
+	}
+	for _, usage := range usages {
+		result.Size += usage.size
+		switch {
+		case slices.Contains(plan.Remove, usage.id):
+		case slices.Contains(plan.DropTranscripts, usage.id):
+			result.RetainedSize += usage.size - usage.transcriptSize
+		default:
+			result.RetainedSize += usage.size
+		}
+	}
+	if result.Plan.IsEmpty() {
+		return result, nil
+	}
+
+	r := newPruneRewriter(s, result.Plan, idx, opts.DryRun)
+	newTip, err := r.rewriteHistory(ref.Hash())
+	if err != nil {
+		return nil, err
+	}
+	result.CommitsRewritten = r.rewritten
+	result.BytesReclaimed, err = r.bytesReclaimed()
+	if err != nil {
+		return nil, err
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	newTip, err = s.commitPrunedSummaries(newTip, result.Plan)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Storer.SetReference(plumbing.NewHashReference(refName, newTip)); err != nil {
+		return nil, fmt.Errorf("failed to set branch reference: %w", err)
+	}
+	result.NewTip = newTip
+	return result, nil
+}
+
+// readCheckpointUsage measures a checkpoint directory at the branch tip.
+func (s *GitStore) readCheckpointUsage(tree *object.Tree, cpID id.CheckpointID, createdAt time.Time) (*checkpointUsage, error) {
+	usage := &checkpointUsage{id: cpID, createdAt: createdAt}
+
+	checkpointTree, err := tree.Tree(cpID.Path())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint %s: %w", cpID, err)
+	}
+	err = checkpointTree.Files().ForEach(func(f *object.File) error {
+		usage.size += f.Size
+		if isPrunableFile(path.Base(f.Name)) {
+			usage.transcriptSize += f.Size
+		}
+
+		dir, name, found := strings.Cut(f.Name, "/")
+		if _, atoiErr := strconv.Atoi(dir); !found || atoiErr != nil || name != paths.MetadataFileName {
+			return nil
+		}
+		metadata, err := s.readMetadataFromBlob(f.Hash)
+		if err != nil {
+			return nil //nolint:nilerr // Unreadable session metadata has no delta base
+		}
+		if metadata.TranscriptDelta != nil {
+			usage.bases = append(usage.bases, metadata.TranscriptDelta.Base)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint %s: %w", cpID, err)
+	}
+	return usage, nil
+}
+
+// planPrune decides which checkpoints to remove and which to strip of their
+// transcripts. It also returns the checkpoints the policy would prune that are
+// kept as delta bases; keeping them isn't offset against MaxSize.
+func planPrune(usages []*checkpointUsage, opts PruneOptions) (PrunePlan, []id.CheckpointID) {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	// Oldest first; checkpoints without a creation time are never pruned by age
+	sorted := slices.Clone(usages)
+	sort.Slice(sorted, func(i, j int) bool {
+		if !sorted[i].createdAt.Equal(sorted[j].createdAt) {
+			return sorted[i].createdAt.Before(sorted[j].createdAt)
+		}
+		return sorted[i].id < sorted[j].id
+	})
+
+	remove := make(map[id.CheckpointID]bool)
+	drop := make(map[id.CheckpointID]bool)
+	var total int64
+	for _, usage := range sorted {
+		total += usage.size
+		if opts.Protected[usage.id] || usage.createdAt.IsZero() {
+			continue
+		}
+		age := now.Sub(usage.createdAt)
+		switch {
+		case opts.MaxAge > 0 && age > opts.MaxAge:
+			remove[usage.id] = true
+			total -= usage.size
+		case opts.DropTranscriptsAfter > 0 && age > opts.DropTranscriptsAfter && usage.transcriptSize > 0:
+			drop[usage.id] = true
+			total -= usage.transcriptSize
+		}
+	}
+
+	if opts.MaxSize > 0 {
+		for _, usage := range sorted {
+			if total <= opts.MaxSize {
+				break
+			}
+			if opts.Protected[usage.id] || remove[usage.id] || usage.createdAt.IsZero() {
+				continue
+			}
+			remaining := usage.size
+			if drop[usage.id] {
+				remaining -= usage.transcriptSize
+				delete(drop, usage.id)
+			}
+			remove[usage.id] = true
+			total -= remaining
+		}
+	}
+
+	// Transcripts that are kept need the transcripts of their delta bases
+	byID := make(map[id.CheckpointID]*checkpointUsage, len(usages))
+	for _, usage := range usages {
+		byID[usage.id] = usage
+	}
+	var keptBases []id.CheckpointID
+	var keepBases func(usage *checkpointUsage)
+	keepBases = func(usage *checkpointUsage) {
+		for _, base := range usage.bases {
+			if !remove[base] && !drop[base] {
+				continue
+			}
+			keptBases = append(keptBases, base)
+			delete(remove, base)
+			delete(drop, base)
+			if baseUsage, ok := byID[base]; ok {
+				keepBases(baseUsage)
+			}
+		}
+	}
+	for _, usage := range sorted {
+		if !remove[usage.id] && !drop[usage.id] {
+			keepBases(usage)
+		}
+	}
+
+	var plan PrunePlan
+	for _, usage := range sorted {
+		switch {
+		case remove[usage.id]:
+			plan.Remove = append(plan.Remove, usage.id)
+		case drop[usage.id]:
+			plan.DropTranscripts = append(plan.DropTranscripts, usage.id)
+		}
+	}
+	slices.Sort(plan.Remove)
+	slices.Sort(plan.DropTranscripts)
+	slices.Sort(keptBases)
+	return plan, keptBases
+}
+
+// isPrunableFile reports whether a file of a checkpoint is removed when its
+// transcripts are dropped: session and subagent transcripts in any
+// compression and chunk, and the content hash of the session transcript.
+func isPrunableFile(name string) bool {
+	if name == paths.ContentHashFileName || name == paths.TranscriptFileNameLegacy {
+		return true
+	}
+	if i := strings.LastIndexByte(name, '.'); i > 0 && agent.ParseChunkIndex(name, name[:i]) > 0 {
+		name = name[:i]
+	}
+	name = strings.TrimSuffix(name, encryptedExtension)
+	for _, ext := range compressionExtensions {
+		name = strings.TrimSuffix(name, ext)
+	}
+	return isTranscriptFile(name)
+}
+
+// pruneRewriter rewrites the metadata branch history without pruned data.
+// Trees are rewritten once per path and hash, so the unchanged parts shared
+// by most commits are only visited once.
+type pruneRewriter struct {
+	s      *GitStore
+	dryRun bool
+
+	remove      map[id.CheckpointID]bool
+	drop        map[id.CheckpointID]bool
+	removeLinks map[string]bool
+
+	trees      map[string]plumbing.Hash
+	commits    map[plumbing.Hash]plumbing.Hash
+	commitTree map[plumbing.Hash]plumbing.Hash
+	rewritten  int
+
+	// Blobs referenced by kept and by pruned data, to compute the bytes reclaimed
+	keptBlobs    map[plumbing.Hash]bool
+	removedBlobs map[plumbing.Hash]bool
+	keptTrees    map[plumbing.Hash]bool
+	removedTrees map[plumbing.Hash]bool
+}
+
+func newPruneRewriter(s *GitStore, plan PrunePlan, idx *checkpointIndex, dryRun bool) *pruneRewriter {
+	r := &pruneRewriter{
+		s:            s,
+		dryRun:       dryRun,
+		remove:       make(map[id.CheckpointID]bool),
+		drop:         make(map[id.CheckpointID]bool),
+		removeLinks:  make(map[string]bool),
+		trees:        make(map[string]plumbing.Hash),
+		commits:      make(map[plumbing.Hash]plumbing.Hash),
+		commitTree:   make(map[plumbing.Hash]plumbing.Hash),
+		keptBlobs:    make(map[plumbing.Hash]bool),
+		removedBlobs: make(map[plumbing.Hash]bool),
+		keptTrees:    make(map[plumbing.Hash]bool),
+		removedTrees: make(map[plumbing.Hash]bool),
+	}
+	for _, cpID := range plan.Remove {
+		r.remove[cpID] = true
+	}
+	for _, cpID := range plan.DropTranscripts {
+		r.drop[cpID] = true
+	}
+	for commitSHA, cpID := range idx.Commits {
+		if r.remove[cpID] {
+			r.removeLinks[commitLinkPath(commitSHA)] = true
+		}
+	}
+	return r
+}
+
+// rewriteHistory rewrites the commits reachable from tip, parents first, and
+// returns the new tip. Commits whose tree and parents are unchanged keep their
+// hash; commits that no longer change anything are dropped.
+func (r *pruneRewriter) rewriteHistory(tip plumbing.Hash) (plumbing.Hash, error) {
+	stack := []plumbing.Hash{tip}
+	for len(stack) > 0 {
+		hash := stack[len(stack)-1]
+		if _, done := r.commits[hash]; done {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		commit, err := r.s.repo.CommitObject(hash)
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to read metadata branch commit %s: %w", hash, err)
+		}
+		pending := false
+		for _, parent := range commit.ParentHashes {
+			if _, done := r.commits[parent]; !done {
+				stack = append(stack, parent)
+				pending = true
+			}
+		}
+		if pending {
+			continue
+		}
+		stack = stack[:len(stack)-1]
+
+		newHash, err := r.rewriteCommit(commit)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		r.commits[hash] = newHash
+	}
+	return r.commits[tip], nil
+}
+
+// rewriteCommit returns the rewritten commit, writing it if it changed.
+func (r *pruneRewriter) rewriteCommit(commit *object.Commit) (plumbing.Hash, error) {
+	tree, err := r.rewriteTree("", commit.TreeHash)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	var parents []plumbing.Hash
+	for _, parent := range commit.ParentHashes {
+		if newParent := r.commits[parent]; !slices.Contains(parents, newParent) {
+			parents = append(parents, newParent)
+		}
+	}
+
+	if tree == commit.TreeHash && slices.Equal(parents, commit.ParentHashes) {
+		r.commitTree[commit.Hash] = tree
+		return commit.Hash, nil
+	}
+	if len(parents) == 1 && r.commitTree[parents[0]] == tree {
+		// Only touched pruned data
+		return parents[0], nil
+	}
+
+	rewritten := &object.Commit{
+		Author:       commit.Author,
+		Committer:    commit.Committer,
+		Message:      commit.Message,
+		TreeHash:     tree,
+		ParentHashes: parents,
+	}
+	// The original signatures don't cover the rewritten commit; re-sign with the user's key
+	if !r.dryRun {
+		SignCommit(r.s.repo, rewritten)
+	}
+	hash, err := r.writeObject(rewritten)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to write commit: %w", err)
+	}
+	r.commitTree[hash] = tree
+	r.rewritten++
+	return hash, nil
+}
+
+// rewriteTree returns the tree at treePath without pruned data. Returns the
+// zero hash if nothing is left (except for the root tree).
+func (r *pruneRewriter) rewriteTree(treePath string, hash plumbing.Hash) (plumbing.Hash, error) {
+	key := treePath + ":" + hash.String()
+	if newHash, ok := r.trees[key]; ok {
+		return newHash, nil
+	}
+
+	tree, err := r.s.repo.TreeObject(hash)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to read tree %s: %w", treePath, err)
+	}
+
+	entries := make([]object.TreeEntry, 0, len(tree.Entries))
+	for _, entry := range tree.Entries {
+		entryPath := path.Join(treePath, entry.Name)
+		action := r.classify(treePath, entryPath, entry)
+		switch action {
+		case pruneKeep:
+			if err := r.collect(entry, r.keptBlobs, r.keptTrees); err != nil {
+				return plumbing.ZeroHash, err
+			}
+		case pruneRemove:
+			if err := r.collect(entry, r.removedBlobs, r.removedTrees); err != nil {
+				return plumbing.ZeroHash, err
+			}
+			continue
+		case pruneDescend:
+			newHash, err := r.rewriteTree(entryPath, entry.Hash)
+			if err != nil {
+				return plumbing.ZeroHash, err
+			}
+			if newHash.IsZero() {
+				continue
+			}
+			entry.Hash = newHash
+		}
+		entries = append(entries, entry)
+	}
+
+	var newHash plumbing.Hash
+	switch {
+	case len(entries) == 0 && treePath != "":
+		newHash = plumbing.ZeroHash
+	case len(entries) == len(tree.Entries) && slices.Equal(entries, tree.Entries):
+		newHash = hash
+	default:
+		newHash, err = r.writeObject(&object.Tree{Entries: entries})
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to write tree %s: %w", treePath, err)
+		}
+	}
+	r.trees[key] = newHash
+	return newHash, nil
+}
+
+type pruneAction int
+
+const (
+	pruneKeep pruneAction = iota
+	pruneRemove
+	pruneDescend
+)
+
+// classify decides what happens to an entry of the tree at treePath.
+func (r *pruneRewriter) classify(treePath, entryPath string, entry object.TreeEntry) pruneAction {
+	isDir := entry.Mode == filemode.Dir
+	parts := strings.Split(entryPath, "/")
+
+	// Commit links: commits/<sha[:2]>/<sha[2:]>.json
+	if parts[0] == paths.CommitLinksDir {
+		switch {
+		case isDir && len(parts) <= 2:
+			return pruneDescend
+		case r.removeLinks[entryPath]:
+			return pruneRemove
+		default:
+			return pruneKeep
+		}
+	}
+
+	// Checkpoints: <id[:2]>/<id[2:]>/...
+	if len(parts[0]) != 2 || !isDir && treePath == "" {
+		return pruneKeep
+	}
+	if len(parts) == 1 {
+		return pruneDescend
+	}
+	cpID := id.CheckpointID(parts[0] + parts[1])
+	switch {
+	case r.remove[cpID]:
+		return pruneRemove
+	case !r.drop[cpID]:
+		return pruneKeep
+	case isDir:
+		return pruneDescend
+	case len(parts) > 2 && isPrunableFile(entry.Name):
+		return pruneRemove
+	default:
+		return pruneKeep
+	}
+}
+
+// collect records the blobs of an entry in blobs, walking directories once.
+func (r *pruneRewriter) collect(entry object.TreeEntry, blobs, trees map[plumbing.Hash]bool) error {
+	if entry.Mode != filemode.Dir {
+		blobs[entry.Hash] = true
+		return nil
+	}
+	if trees[entry.Hash] {
+		return nil
+	}
+	trees[entry.Hash] = true
+	tree, err := r.s.repo.TreeObject(entry.Hash)
+	if err != nil {
+		return fmt.Errorf("failed to read tree %s: %w", entry.Name, err)
+	}
+	for _, child := range tree.Entries {
+		if err := r.collect(child, blobs, trees); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bytesReclaimed returns the size of the blobs only referenced by pruned data.
+func (r *pruneRewriter) bytesReclaimed() (int64, error) {
+	var total int64
+	for hash := range r.removedBlobs {
+		if r.keptBlobs[hash] {
+			continue
+		}
+		blob, err := r.s.repo.BlobObject(hash)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read blob %s: %w", hash, err)
+		}
+		total += blob.Size
+	}
+	return total, nil
+}
+
+// encodable is an object that can be encoded to a git object (trees, commits).
+type encodable interface {
+	Encode(o plumbing.EncodedObject) error
+}
+
+// writeObject stores an object and returns its hash. Dry runs only hash it.
+func (r *pruneRewriter) writeObject(o encodable) (plumbing.Hash, error) {
+	obj := r.s.repo.Storer.NewEncodedObject()
+	if r.dryRun {
+		obj = &plumbing.MemoryObject{}
+	}
+	if err := o.Encode(obj); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to encode object: %w", err)
+	}
+	if r.dryRun {
+		return obj.Hash(), nil
+	}
+	hash, err := r.s.repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to store object: %w", err)
+	}
+	return hash, nil
+}
+
+// commitPrunedSummaries commits, on top of the rewritten tip, the session
+// metadata and summaries of checkpoints whose transcripts were dropped: the
+// sessions are marked TranscriptPruned (and lose their delta, which has nothing
+// left to extend) and the summaries no longer list the removed files.
+func (s *GitStore) commitPrunedSummaries(tip plumbing.Hash, plan PrunePlan) (plumbing.Hash, error) {
+	if len(plan.DropTranscripts) == 0 {
+		return tip, nil
+	}
+
+	commit, err := s.repo.CommitObject(tip)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to get commit object: %w", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to get commit tree: %w", err)
+	}
+	entries := make(map[string]object.TreeEntry)
+	if err := FlattenTree(s.repo, tree, "", entries); err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	for _, cpID := range plan.DropTranscripts {
+		for i := 0; ; i++ {
+			metadataPath := fmt.Sprintf("%s/%d/%s", cpID.Path(), i, paths.MetadataFileName)
+			entry, ok := entries[metadataPath]
+			if !ok {
+				break
+			}
+			metadata, err := s.readMetadataFromBlob(entry.Hash)
+			if err != nil {
+				continue
+			}
+			metadata.TranscriptPruned = true
+			metadata.TranscriptDelta = nil
+			metadataJSON, err := jsonutil.MarshalIndentWithNewline(metadata, "", "  ")
+			if err != nil {
+				return plumbing.ZeroHash, fmt.Errorf("failed to marshal metadata: %w", err)
+			}
+			metadataHash, err := CreateBlobFromContent(s.repo, metadataJSON)
+			if err != nil {
+				return plumbing.ZeroHash, err
+			}
+			entries[metadataPath] = object.TreeEntry{Name: metadataPath, Mode: filemode.Regular, Hash: metadataHash}
+		}
+		if _, err := s.repairCheckpointSummary(cpID, entries); err != nil {
+			return plumbing.ZeroHash, err
+		}
+	}
+
+	treeHash, err := BuildTreeFromEntries(s.repo, entries)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	authorName, authorEmail := getGitAuthorFromRepo(s.repo)
+	commitMsg := fmt.Sprintf("Prune transcripts of %d checkpoints", len(plan.DropTranscripts))
+	return s.createCommit(treeHash, tip, commitMsg, authorName, authorEmail)
+}
@@ -0,0 +1,214 @@
+package checkpoint
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestPlanPrune(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(n int) time.Time { return now.Add(-time.Duration(n) * 24 * time.Hour) }
+	old := id.MustCheckpointID("a0a0a0a0a0a1")
+	middle := id.MustCheckpointID("a0a0a0a0a0a2")
+	recent := id.MustCheckpointID("a0a0a0a0a0a3")
+	undated := id.MustCheckpointID("a0a0a0a0a0a4")
+	usages := func() []*checkpointUsage {
+		return []*checkpointUsage{
+			{id: old, createdAt: daysAgo(100), size: 100, transcriptSize: 80},
+			{id: middle, createdAt: daysAgo(40), size: 100, transcriptSize: 80, bases: []id.CheckpointID{old}},
+			{id: recent, createdAt: daysAgo(1), size: 100, transcriptSize: 80},
+			{id: undated, size: 100, transcriptSize: 80},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		opts      PruneOptions
+		want      PrunePlan
+		keptBases []id.CheckpointID
+	}{
+		{
+			name: "max age",
+			opts: PruneOptions{MaxAge: 30 * 24 * time.Hour},
+			want: PrunePlan{Remove: []id.CheckpointID{old, middle}},
+		},
+		{
+			name: "protected",
+			opts: PruneOptions{MaxAge: 30 * 24 * time.Hour, Protected: map[id.CheckpointID]bool{old: true}},
+			want: PrunePlan{Remove: []id.CheckpointID{middle}},
+		},
+		{
+			name: "drop transcripts",
+			opts: PruneOptions{MaxAge: 60 * 24 * time.Hour, DropTranscriptsAfter: 30 * 24 * time.Hour},
+			want: PrunePlan{Remove: []id.CheckpointID{old}, DropTranscripts: []id.CheckpointID{middle}},
+		},
+		{
+			name: "max size removes oldest first",
+			opts: PruneOptions{MaxSize: 250},
+			want: PrunePlan{Remove: []id.CheckpointID{old, middle}},
+		},
+		{
+			name:      "delta base of a kept transcript",
+			opts:      PruneOptions{MaxAge: 60 * 24 * time.Hour},
+			want:      PrunePlan{},
+			keptBases: []id.CheckpointID{old},
+		},
+		{
+			name:      "delta base kept over max size",
+			opts:      PruneOptions{MaxSize: 250, Protected: map[id.CheckpointID]bool{middle: true}},
+			want:      PrunePlan{Remove: []id.CheckpointID{recent}},
+			keptBases: []id.CheckpointID{old},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Now = now
+			got, keptBases := planPrune(usages(), tt.opts)
+			if !slices.Equal(got.Remove, tt.want.Remove) || !slices.Equal(got.DropTranscripts, tt.want.DropTranscripts) {
+				t.Errorf("planPrune() = %+v, want %+v", got, tt.want)
+			}
+			if !slices.Equal(keptBases, tt.keptBases) {
+				t.Errorf("planPrune() kept bases = %v, want %v", keptBases, tt.keptBases)
+			}
+		})
+	}
+}
+
+// metadataBranchTip returns the local metadata branch tip.
+func metadataBranchTip(t *testing.T, repo *git.Repository) plumbing.Hash {
+	t.Helper()
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	if err != nil {
+		t.Fatalf("failed to get metadata branch: %v", err)
+	}
+	return ref.Hash()
+}
+
+// TestPrune_RemovesFromHistory verifies that a dry run only reports the plan
+// and that applying it removes the checkpoint from every commit of the branch.
+func TestPrune_RemovesFromHistory(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	removed := writeIndexTestCheckpoint(t, store, "b0b0b0b0b0b1", "session-1")
+	kept := writeIndexTestCheckpoint(t, store, "b0b0b0b0b0b2", "session-2")
+	tip := metadataBranchTip(t, repo)
+
+	opts := PruneOptions{
+		MaxAge:    24 * time.Hour,
+		Protected: map[id.CheckpointID]bool{kept: true},
+		Now:       time.Now().Add(48 * time.Hour),
+		DryRun:    true,
+	}
+	result, err := store.Prune(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Prune(dry run) error = %v", err)
+	}
+	if !slices.Equal(result.Plan.Remove, []id.CheckpointID{removed}) || result.BytesReclaimed <= 0 {
+		t.Errorf("Prune(dry run) = %+v, want %s removed and bytes reclaimed", result, removed)
+	}
+	if result.RetainedSize <= 0 || result.RetainedSize >= result.Size {
+		t.Errorf("Prune(dry run) RetainedSize = %d, want between 0 and %d", result.RetainedSize, result.Size)
+	}
+	if got := metadataBranchTip(t, repo); got != tip {
+		t.Errorf("dry run moved the branch from %s to %s", tip, got)
+	}
+
+	opts.DryRun = false
+	result, err = store.Prune(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	newTip := metadataBranchTip(t, repo)
+	if result.NewTip != newTip || newTip == tip {
+		t.Errorf("Prune() NewTip = %s, branch at %s, old tip %s", result.NewTip, newTip, tip)
+	}
+
+	if summary, err := store.ReadCommitted(context.Background(), removed); err != nil || summary != nil {
+		t.Errorf("ReadCommitted(removed) = %v, %v; want nil", summary, err)
+	}
+	if summary, err := store.ReadCommitted(context.Background(), kept); err != nil || summary == nil {
+		t.Errorf("ReadCommitted(kept) = %v, %v; want the checkpoint", summary, err)
+	}
+
+	iter, err := repo.Log(&git.LogOptions{From: newTip})
+	if err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	err = iter.ForEach(func(c *object.Commit) error {
+		tree, err := c.Tree()
+		if err != nil {
+			return err
+		}
+		if _, err := tree.Tree(removed.Path()); err == nil {
+			t.Errorf("commit %s still contains %s", c.Hash, removed)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEach() error = %v", err)
+	}
+}
+
+// TestPrune_DropTranscripts verifies that dropped transcripts leave the
+// metadata in place, marked as pruned, and that delta bases of transcripts
+// that are kept are never dropped.
+func TestPrune_DropTranscripts(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	first := id.MustCheckpointID("c0c0c0c0c0c1")
+	second := id.MustCheckpointID("c0c0c0c0c0c2")
+	writeDeltaCheckpoint(t, store, first, id.EmptyCheckpointID, transcriptLines(3), CompressionNone)
+	writeDeltaCheckpoint(t, store, second, first, transcriptLines(5), CompressionNone)
+
+	opts := PruneOptions{
+		DropTranscriptsAfter: 24 * time.Hour,
+		Protected:            map[id.CheckpointID]bool{second: true},
+		Now:                  time.Now().Add(48 * time.Hour),
+	}
+	result, err := store.Prune(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if !result.Plan.IsEmpty() {
+		t.Fatalf("Prune() plan = %+v, want the delta base kept", result.Plan)
+	}
+
+	opts.Protected = nil
+	result, err = store.Prune(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if len(result.Plan.DropTranscripts) != 2 || len(result.Plan.Remove) != 0 {
+		t.Fatalf("Prune() plan = %+v, want both transcripts dropped", result.Plan)
+	}
+
+	for _, cpID := range []id.CheckpointID{first, second} {
+		content, err := store.ReadSessionContent(context.Background(), cpID, 0)
+		if err != nil {
+			t.Fatalf("ReadSessionContent(%s) error = %v", cpID, err)
+		}
+		if !content.Metadata.TranscriptPruned || content.Metadata.TranscriptDelta != nil {
+			t.Errorf("metadata of %s = %+v, want transcript pruned without delta", cpID, content.Metadata)
+		}
+		if len(content.Transcript) != 0 {
+			t.Errorf("transcript of %s = %q, want none", cpID, content.Transcript)
+		}
+	}
+
+	problems, err := store.VerifyCommitted(context.Background())
+	if err != nil {
+		t.Fatalf("VerifyCommitted() error = %v", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("VerifyCommitted() after prune = %+v, want no problems", problems)
+	}
+}
@@ -0,0 +1,86 @@
+package checkpoint
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// looseObjectRepackLimit is the estimated number of loose objects above which
+// RepackLooseObjects packs them. It is well below git's gc.auto default (6700),
+// so checkpoints don't push the repository into `git gc --auto`, which can
+// prune objects that worktree indexes still reference.
+const looseObjectRepackLimit = 2048
+
+// looseObjectSampleDir is the objects directory git samples to estimate the
+// loose object count (see `git gc --auto`).
+const looseObjectSampleDir = "objects/17"
+
+// RepackLooseObjects packs the repository's loose objects into a packfile once
+// there are more than looseObjectRepackLimit of them. Returns whether it repacked.
+//
+// Checkpoints are written as loose objects, which every repository instance
+// (in this process or another) sees as soon as they are written. The store's
+// repository reloads its pack index after a repack so it keeps seeing them;
+// other instances that already loaded their pack index don't until reopened,
+// which the limit keeps rare.
+//
+// `git repack -d` only packs reachable objects (including those referenced by
+// worktree indexes) and never prunes, so unlike `git gc` it can't drop objects
+// a worktree index still needs. Unreachable loose objects are left for git gc.
+func (s *GitStore) RepackLooseObjects(ctx context.Context) (bool, error) {
+	if estimateLooseObjects(s.repo) <= looseObjectRepackLimit {
+		return false, nil
+	}
+	args := []string{"repack", "-d", "-q"}
+	if gitDir := repositoryGitDir(s.repo); gitDir != "" {
+		args = append([]string{"--git-dir", gitDir}, args...)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return false, fmt.Errorf("failed to repack loose objects: %s: %w", strings.TrimSpace(string(output)), err)
+	}
+	if fs, ok := s.repo.Storer.(*filesystem.Storage); ok {
+		fs.Reindex()
+	}
+	return true, nil
+}
+
+// repackAfterWrite runs RepackLooseObjects after a checkpoint write, so long
+// sessions don't pile up loose objects between commits. Failures are only
+// logged, as the checkpoint itself was written.
+func (s *GitStore) repackAfterWrite(ctx context.Context) {
+	if _, err := s.RepackLooseObjects(ctx); err != nil {
+		logging.Warn(ctx, "failed to repack loose objects after checkpoint write",
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// estimateLooseObjects estimates the repository's loose object count the way git
+// does, from the number of objects in one of the 256 object directories.
+// Repositories not stored on disk have none.
+func estimateLooseObjects(repo *git.Repository) int {
+	fs, ok := repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return 0
+	}
+	files, err := fs.Filesystem().ReadDir(looseObjectSampleDir)
+	if err != nil {
+		return 0
+	}
+	count := 0
+	for _, file := range files {
+		if !file.IsDir() && len(file.Name()) == 38 {
+			count++
+		}
+	}
+	return count * 256
+}
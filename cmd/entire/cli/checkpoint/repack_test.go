@@ -0,0 +1,158 @@
+package checkpoint
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// writeSampledLooseObjects writes n reachable blobs that land in the object
+// directory sampled by estimateLooseObjects, and returns their hashes.
+func writeSampledLooseObjects(t *testing.T, repo *git.Repository, n int) []plumbing.Hash {
+	t.Helper()
+	entries := make(map[string]object.TreeEntry)
+	var hashes []plumbing.Hash
+	for i := 0; len(hashes) < n; i++ {
+		content := []byte(fmt.Sprintf("blob %d\n", i))
+		if hash := plumbing.ComputeHash(plumbing.BlobObject, content); hash.String()[:2] != "17" {
+			continue
+		}
+		hash, err := CreateBlobFromContent(repo, content)
+		if err != nil {
+			t.Fatalf("CreateBlobFromContent() error = %v", err)
+		}
+		name := fmt.Sprintf("file-%d.txt", i)
+		entries[name] = object.TreeEntry{Name: name, Mode: filemode.Regular, Hash: hash}
+		hashes = append(hashes, hash)
+	}
+
+	// Reference the blobs, as repack only packs reachable objects
+	treeHash, err := BuildTreeFromEntries(repo, entries)
+	if err != nil {
+		t.Fatalf("BuildTreeFromEntries() error = %v", err)
+	}
+	commitHash, err := NewGitStore(repo).createCommit(treeHash, plumbing.ZeroHash, "objects", "Test", "test@test.com")
+	if err != nil {
+		t.Fatalf("createCommit() error = %v", err)
+	}
+	ref := plumbing.NewHashReference(plumbing.NewBranchReferenceName("objects"), commitHash)
+	if err := repo.Storer.SetReference(ref); err != nil {
+		t.Fatalf("SetReference() error = %v", err)
+	}
+	return hashes
+}
+
+func countSampledLooseObjects(t *testing.T, repo *git.Repository) int {
+	t.Helper()
+	files, err := os.ReadDir(filepath.Join(repositoryGitDir(repo), looseObjectSampleDir))
+	if os.IsNotExist(err) {
+		return 0
+	}
+	if err != nil {
+		t.Fatalf("failed to read objects directory: %v", err)
+	}
+	return len(files)
+}
+
+func TestRepackLooseObjects_BelowLimit(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	writeSampledLooseObjects(t, repo, looseObjectRepackLimit/256)
+
+	repacked, err := NewGitStore(repo).RepackLooseObjects(context.Background())
+	if err != nil {
+		t.Fatalf("RepackLooseObjects() error = %v", err)
+	}
+	if repacked {
+		t.Error("RepackLooseObjects() repacked below the limit")
+	}
+}
+
+func TestRepackLooseObjects_AboveLimit(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+
+	// A repository instance opened before the writes sees the loose objects
+	earlier, err := git.PlainOpen(filepath.Dir(repositoryGitDir(repo)))
+	if err != nil {
+		t.Fatalf("failed to open repository: %v", err)
+	}
+	if _, err := earlier.CommitObjects(); err != nil {
+		t.Fatalf("failed to load objects: %v", err)
+	}
+	hashes := writeSampledLooseObjects(t, repo, looseObjectRepackLimit/256+1)
+	if _, err := earlier.BlobObject(hashes[0]); err != nil {
+		t.Fatalf("loose object not visible to an existing repository instance: %v", err)
+	}
+
+	repacked, err := NewGitStore(repo).RepackLooseObjects(context.Background())
+	if err != nil {
+		t.Fatalf("RepackLooseObjects() error = %v", err)
+	}
+	if !repacked {
+		t.Fatal("RepackLooseObjects() did not repack above the limit")
+	}
+	if n := countSampledLooseObjects(t, repo); n != 0 {
+		t.Errorf("%d loose objects left after repack", n)
+	}
+
+	reopened, err := git.PlainOpen(filepath.Dir(repositoryGitDir(repo)))
+	if err != nil {
+		t.Fatalf("failed to reopen repository: %v", err)
+	}
+	for _, hash := range hashes {
+		if _, err := reopened.BlobObject(hash); err != nil {
+			t.Errorf("object %s not found after repack: %v", hash, err)
+		}
+	}
+}
+
+func TestWriteTemporary_RepacksAboveLimit(t *testing.T) {
+	repo, initialCommit := setupBranchTestRepo(t)
+
+	// Load the repository's pack index, which a repack makes stale
+	if _, err := repo.BlobObject(plumbing.ComputeHash(plumbing.BlobObject, []byte("missing"))); err == nil {
+		t.Fatal("expected missing blob lookup to fail")
+	}
+	hashes := writeSampledLooseObjects(t, repo, looseObjectRepackLimit/256+1)
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(worktree.Filesystem.Root(), "README.md"), []byte("# Changed"), 0o644); err != nil {
+		t.Fatalf("failed to write README: %v", err)
+	}
+
+	store := NewGitStore(repo)
+	result, err := store.WriteTemporary(context.Background(), WriteTemporaryOptions{
+		SessionID:     "test-session",
+		BaseCommit:    initialCommit.String(),
+		ModifiedFiles: []string{"README.md"},
+		MetadataDir:   ".entire/metadata/test-session",
+		CommitMessage: "Checkpoint",
+		AuthorName:    "Test",
+		AuthorEmail:   "test@test.com",
+	})
+	if err != nil {
+		t.Fatalf("WriteTemporary() error = %v", err)
+	}
+	if n := countSampledLooseObjects(t, repo); n != 0 {
+		t.Errorf("%d loose objects left after WriteTemporary", n)
+	}
+
+	// The store's repository still sees the objects that moved into the pack
+	if _, err := repo.CommitObject(result.CommitHash); err != nil {
+		t.Errorf("checkpoint commit not found after repack: %v", err)
+	}
+	for _, hash := range hashes {
+		if _, err := repo.BlobObject(hash); err != nil {
+			t.Errorf("object %s not found after repack: %v", hash, err)
+		}
+	}
+}
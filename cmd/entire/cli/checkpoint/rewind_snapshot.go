@@ -0,0 +1,238 @@
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+	"github.com/entireio/cli/cmd/entire/cli/validation"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// PreRewindRefPrefix is the prefix of the refs recording the snapshot taken
+// before the last rewind, one per shadow branch. The snapshot is a checkpoint
+// on top of the shadow branch, but rewinding moves the branch back, so the
+// snapshot is kept reachable by its own ref.
+const PreRewindRefPrefix = "refs/entire/pre-rewind/"
+
+// PreRewindRefName returns the ref recording the pre-rewind snapshot for the
+// shadow branch of a base commit and worktree.
+func PreRewindRefName(baseCommit, worktreeID string) plumbing.ReferenceName {
+	return PreRewindRefForBranch(ShadowBranchNameForCommit(baseCommit, worktreeID))
+}
+
+// PreRewindRefForBranch returns the ref recording the pre-rewind snapshot for
+// a shadow branch.
+func PreRewindRefForBranch(shadowBranchName string) plumbing.ReferenceName {
+	return plumbing.ReferenceName(PreRewindRefPrefix + strings.TrimPrefix(shadowBranchName, ShadowBranchPrefix))
+}
+
+// ShadowBranchForPreRewindRef returns the shadow branch a pre-rewind ref
+// belongs to (the inverse of PreRewindRefForBranch).
+func ShadowBranchForPreRewindRef(refName plumbing.ReferenceName) string {
+	return ShadowBranchPrefix + strings.TrimPrefix(refName.String(), PreRewindRefPrefix)
+}
+
+// WriteRewindSnapshot saves the current state of the working tree and the live
+// transcripts of the given sessions (if any) as a checkpoint on top of the
+// shadow branch, and records it as the pre-rewind snapshot. Unlike
+// WriteTemporary, the tree is the full working tree state (the base commit's
+// tree plus every change git status reports), so restoring it restores exactly
+// this state. The base commit is recorded too, so a rewind that moves HEAD can
+// be undone. The shadow branch itself is not moved.
+func (s *GitStore) WriteRewindSnapshot(ctx context.Context, opts WriteRewindSnapshotOptions) (plumbing.Hash, error) {
+	if opts.BaseCommit == "" {
+		return plumbing.ZeroHash, errors.New("BaseCommit is required for a pre-rewind snapshot")
+	}
+	for _, sessionID := range opts.SessionIDs {
+		if err := validation.ValidateSessionID(sessionID); err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("invalid pre-rewind snapshot options: %w", err)
+		}
+	}
+
+	entries, err := s.worktreeEntries(ctx, opts.BaseCommit, s.repo)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	// Live transcripts go where shadow checkpoints keep them
+	for sessionID, transcript := range opts.Transcripts {
+		blobHash, err := CreateBlobFromContent(s.repo, transcript)
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to store transcript of session %s: %w", sessionID, err)
+		}
+		treePath := paths.SessionMetadataDirFromSessionID(sessionID) + "/" + paths.TranscriptFileName
+		entries[treePath] = object.TreeEntry{Name: treePath, Mode: filemode.Regular, Hash: blobHash}
+	}
+
+	treeHash, err := BuildTreeFromEntries(s.repo, entries)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to build tree: %w", err)
+	}
+
+	var parentHash plumbing.Hash
+	shadowRef := plumbing.NewBranchReferenceName(ShadowBranchNameForCommit(opts.BaseCommit, opts.WorktreeID))
+	if ref, err := s.repo.Reference(shadowRef, true); err == nil {
+		parentHash = ref.Hash()
+	}
+
+	var metadataDir string
+	if len(opts.SessionIDs) > 0 {
+		metadataDir = paths.SessionMetadataDirFromSessionID(opts.SessionIDs[len(opts.SessionIDs)-1])
+	}
+	commitMsg := trailers.FormatPreRewindCommit(opts.CommitMessage, metadataDir, opts.SessionIDs, string(opts.Agent), opts.BaseCommit, opts.Target)
+	commitHash, err := s.createCommit(treeHash, parentHash, commitMsg, opts.AuthorName, opts.AuthorEmail)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to create commit: %w", err)
+	}
+
+	ref := plumbing.NewHashReference(PreRewindRefName(opts.BaseCommit, opts.WorktreeID), commitHash)
+	if err := s.repo.Storer.SetReference(ref); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to update pre-rewind reference: %w", err)
+	}
+	return commitHash, nil
+}
+
+// MoveRewindSnapshot records the pre-rewind snapshot of fromBase's shadow
+// branch as the one of toBase, after a rewind moved HEAD from one to the
+// other, so it can still be found and undone. Does nothing if there is none.
+func (s *GitStore) MoveRewindSnapshot(ctx context.Context, fromBase, toBase, worktreeID string) error {
+	fromRef, toRef := PreRewindRefName(fromBase, worktreeID), PreRewindRefName(toBase, worktreeID)
+	if fromRef == toRef {
+		return nil
+	}
+	ref, err := s.repo.Reference(fromRef, true)
+	if err != nil {
+		return nil //nolint:nilerr // No snapshot to move
+	}
+	if err := s.repo.Storer.SetReference(plumbing.NewHashReference(toRef, ref.Hash())); err != nil {
+		return fmt.Errorf("failed to update pre-rewind reference: %w", err)
+	}
+	// Uses git CLI for the same reasons as DeleteShadowBranch
+	if output, err := exec.CommandContext(ctx, "git", "update-ref", "-d", fromRef.String()).CombinedOutput(); err != nil { //nolint:gosec // Ref name is constructed from a commit hash
+		return fmt.Errorf("failed to remove pre-rewind reference: %s: %w", strings.TrimSpace(string(output)), err)
+	}
+	return nil
+}
+
+// BuildWorktreeTree writes a tree of the current state of the working tree:
+// the base commit's tree plus every change git status reports. Ignored files
+// and infrastructure paths are not included.
+func (s *GitStore) BuildWorktreeTree(ctx context.Context, baseCommit string) (plumbing.Hash, error) {
+	entries, err := s.worktreeEntries(ctx, baseCommit, s.repo)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return BuildTreeFromEntries(s.repo, entries)
+}
+
+// ReadWorktreeTree returns the tree BuildWorktreeTree would write, without
+// writing it: the blobs and trees missing from the repository are kept in
+// memory, readable only through the returned tree.
+func (s *GitStore) ReadWorktreeTree(ctx context.Context, baseCommit string) (*object.Tree, error) {
+	overlay, err := newOverlayRepository(s.repo)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.worktreeEntries(ctx, baseCommit, overlay)
+	if err != nil {
+		return nil, err
+	}
+	treeHash, err := BuildTreeFromEntries(overlay, entries)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := overlay.TreeObject(treeHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read worktree tree: %w", err)
+	}
+	return tree, nil
+}
+
+// worktreeEntries returns the flattened tree of the current state of the
+// working tree, writing the blobs of changed files to objects.
+func (s *GitStore) worktreeEntries(ctx context.Context, baseCommit string, objects *git.Repository) (map[string]object.TreeEntry, error) {
+	commit, err := s.repo.CommitObject(plumbing.NewHash(baseCommit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get base commit: %w", err)
+	}
+	baseTree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get base tree: %w", err)
+	}
+	entries := make(map[string]object.TreeEntry)
+	if err := FlattenTree(s.repo, baseTree, "", entries); err != nil {
+		return nil, fmt.Errorf("failed to flatten base tree: %w", err)
+	}
+
+	changes, err := collectChangedFiles(ctx, s.repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect changed files: %w", err)
+	}
+	repoRoot, err := paths.RepoRoot()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repo root: %w", err)
+	}
+
+	for _, file := range changes.Deleted {
+		delete(entries, file)
+	}
+	for _, file := range changes.Changed {
+		absPath := filepath.Join(repoRoot, file)
+		if !fileExists(absPath) {
+			delete(entries, file)
+			continue
+		}
+		blobHash, mode, err := createBlobFromFile(objects, absPath)
+		if err != nil {
+			// Skip files that can't be read (may have been deleted since detection)
+			continue
+		}
+		entries[file] = object.TreeEntry{Name: file, Mode: mode, Hash: blobHash}
+	}
+	return entries, nil
+}
+
+// ReadRewindSnapshot returns the snapshot taken before the last rewind on the
+// shadow branch of a base commit and worktree, or nil if there is none.
+func (s *GitStore) ReadRewindSnapshot(ctx context.Context, baseCommit, worktreeID string) (*RewindSnapshotInfo, error) {
+	_ = ctx // Reserved for future use
+
+	ref, err := s.repo.Reference(PreRewindRefName(baseCommit, worktreeID), true)
+	if err != nil {
+		return nil, nil //nolint:nilnil,nilerr // No snapshot is an expected case
+	}
+	commit, err := s.repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pre-rewind snapshot: %w", err)
+	}
+	target, found := trailers.ParsePreRewind(commit.Message)
+	if !found {
+		return nil, fmt.Errorf("commit %s is not a pre-rewind snapshot", commit.Hash.String()[:7])
+	}
+
+	info := &RewindSnapshotInfo{
+		CommitHash: commit.Hash,
+		Message:    strings.Split(commit.Message, "\n")[0],
+		Target:     target,
+		SessionIDs: trailers.ParseAllSessions(commit.Message),
+		Timestamp:  commit.Author.When,
+	}
+	if len(commit.ParentHashes) > 0 {
+		info.ShadowTip = commit.ParentHashes[0]
+	}
+	if agentName, found := trailers.ParseAgent(commit.Message); found {
+		info.Agent = agent.AgentType(agentName)
+	}
+	return info, nil
+}
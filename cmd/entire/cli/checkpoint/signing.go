@@ -0,0 +1,608 @@
+package checkpoint
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	format "github.com/go-git/go-git/v5/plumbing/format/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// Signing formats, as configured with git's gpg.format.
+const (
+	SigningFormatOpenPGP = "openpgp"
+	SigningFormatSSH     = "ssh"
+	SigningFormatX509    = "x509"
+)
+
+// signingTimeout bounds how long a signing program may run, e.g. waiting for a
+// passphrase, before the commit is written unsigned.
+const signingTimeout = 10 * time.Second
+
+// signingFailed is set once signing a commit failed; later commits of the
+// process are written unsigned without trying again.
+var signingFailed atomic.Bool
+
+// commitSigner signs commits the way git does for commit.gpgsign, using the
+// user's gpg.format, user.signingkey and signing program.
+type commitSigner struct {
+	format  string
+	key     string
+	program string
+}
+
+var _ git.Signer = (*commitSigner)(nil)
+
+// SignCommit signs commit with the user's signing key if commit.gpgsign is
+// enabled in the repository's git config. The commit is left unsigned otherwise.
+// Signing failures (a locked key, a signing program that hangs) don't fail the
+// commit: it is written unsigned, with a warning the first time.
+// Must be called after all other fields of the commit are set.
+func SignCommit(repo *git.Repository, commit *object.Commit) {
+	if signingFailed.Load() {
+		return
+	}
+	signer, err := loadCommitSigner(repo)
+	if err == nil && signer != nil {
+		err = signer.signCommit(commit)
+	}
+	if err != nil && !signingFailed.Swap(true) {
+		logging.Warn(context.Background(), "failed to sign commit, writing unsigned commits",
+			slog.String("error", err.Error()))
+		fmt.Fprintf(os.Stderr, "[entire] Warning: failed to sign commit, checkpoints are written unsigned: %v\n", err)
+	}
+}
+
+func (s *commitSigner) signCommit(commit *object.Commit) error {
+	encoded := &plumbing.MemoryObject{}
+	if err := commit.EncodeWithoutSignature(encoded); err != nil {
+		return fmt.Errorf("failed to encode commit for signing: %w", err)
+	}
+	r, err := encoded.Reader()
+	if err != nil {
+		return fmt.Errorf("failed to read encoded commit: %w", err)
+	}
+	signature, err := s.Sign(r)
+	if err != nil {
+		return err
+	}
+	commit.PGPSignature = string(signature)
+	return nil
+}
+
+// loadCommitSigner returns the signer configured for the repository, or nil
+// if commits aren't signed. The git config is read on each call so signing
+// follows config changes made while a long-running process is alive.
+func loadCommitSigner(repo *git.Repository) (*commitSigner, error) {
+	if repositoryGitDir(repo) == "" {
+		return nil, nil //nolint:nilnil // In-memory repositories aren't signed
+	}
+	return newCommitSigner(loadGitConfig(repo))
+}
+
+func newCommitSigner(cfg gitConfig) (*commitSigner, error) {
+	if !isGitConfigTrue(cfg.get("commit.gpgsign")) {
+		return nil, nil //nolint:nilnil // Signing is disabled
+	}
+
+	signer := &commitSigner{
+		format: cfg.get("gpg.format"),
+		key:    cfg.get("user.signingkey"),
+	}
+	switch signer.format {
+	case "", SigningFormatOpenPGP:
+		signer.format = SigningFormatOpenPGP
+		signer.program = firstNonEmpty(cfg.get("gpg.openpgp.program"), cfg.get("gpg.program"), "gpg")
+	case SigningFormatX509:
+		signer.program = firstNonEmpty(cfg.get("gpg.x509.program"), "gpgsm")
+	case SigningFormatSSH:
+		signer.program = firstNonEmpty(cfg.get("gpg.ssh.program"), "ssh-keygen")
+		if signer.key == "" {
+			return nil, errors.New("commit.gpgsign is set with gpg.format=ssh but user.signingkey is not set")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported gpg.format %q", signer.format)
+	}
+	return signer, nil
+}
+
+// Sign returns the armored signature of message.
+func (s *commitSigner) Sign(message io.Reader) ([]byte, error) {
+	if s.format == SigningFormatSSH {
+		return s.signSSH(message)
+	}
+
+	// Same invocation as git; without a signing key, the program's default key is used
+	args := []string{"--status-fd=2", "-bsa"}
+	if s.key != "" {
+		args = append(args, "-u", s.key)
+	}
+	return s.run(message, args...)
+}
+
+func (s *commitSigner) signSSH(message io.Reader) ([]byte, error) {
+	keyFile := s.key
+	args := []string{"-Y", "sign", "-n", "git"}
+
+	// A literal public key signs through ssh-agent
+	if literal, ok := sshLiteralKey(s.key); ok {
+		f, err := os.CreateTemp("", "entire-signing-key-*.pub")
+		if err != nil {
+			return nil, fmt.Errorf("failed to write signing key: %w", err)
+		}
+		defer os.Remove(f.Name())
+		if _, err := f.WriteString(literal + "\n"); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to write signing key: %w", err)
+		}
+		if err := f.Close(); err != nil {
+			return nil, fmt.Errorf("failed to write signing key: %w", err)
+		}
+		keyFile = f.Name()
+		args = append(args, "-U")
+	} else if home, err := os.UserHomeDir(); err == nil && strings.HasPrefix(keyFile, "~/") {
+		keyFile = filepath.Join(home, keyFile[2:])
+	}
+
+	return s.run(message, append(args, "-f", keyFile)...)
+}
+
+func (s *commitSigner) run(message io.Reader, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), signingTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.program, args...) //nolint:gosec // Program and key come from the user's git config
+	cmd.Stdin = message
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("failed to sign commit with %s: timed out after %s", s.program, signingTimeout)
+		}
+		return nil, fmt.Errorf("failed to sign commit with %s: %w: %s", s.program, err, strings.TrimSpace(stderr.String()))
+	}
+	if stdout.Len() == 0 {
+		return nil, fmt.Errorf("failed to sign commit with %s: no signature produced", s.program)
+	}
+	return stdout.Bytes(), nil
+}
+
+// sshLiteralKey returns the public key of a user.signingkey given literally
+// ("key::ssh-ed25519 ..." or "ssh-ed25519 ...") rather than as a key file.
+func sshLiteralKey(key string) (string, bool) {
+	if literal, ok := strings.CutPrefix(key, "key::"); ok {
+		return literal, true
+	}
+	if strings.HasPrefix(key, "ssh-") || strings.HasPrefix(key, "ecdsa-") || strings.HasPrefix(key, "sk-") {
+		return key, true
+	}
+	return "", false
+}
+
+// gitConfig is the repository's git config files, most specific first. They
+// are read directly rather than through `git config`, which would cost a
+// process per commit; include directives aren't followed.
+type gitConfig []*format.Config
+
+// loadGitConfig reads the repository's local, global and system git config.
+// Unreadable files are skipped.
+func loadGitConfig(repo *git.Repository) gitConfig {
+	var cfg gitConfig
+	if local, err := repo.Storer.Config(); err == nil && local.Raw != nil {
+		cfg = append(cfg, local.Raw)
+	}
+	for _, file := range userGitConfigFiles() {
+		f, err := os.Open(file) //nolint:gosec // Paths are git's config file locations
+		if err != nil {
+			continue
+		}
+		raw := format.New()
+		if err := format.NewDecoder(f).Decode(raw); err == nil {
+			cfg = append(cfg, raw)
+		}
+		f.Close()
+	}
+	return cfg
+}
+
+// userGitConfigFiles returns the global and system git config files, most
+// specific first, honoring GIT_CONFIG_GLOBAL, GIT_CONFIG_SYSTEM and
+// GIT_CONFIG_NOSYSTEM like git.
+func userGitConfigFiles() []string {
+	var files []string
+	if global, ok := os.LookupEnv("GIT_CONFIG_GLOBAL"); ok {
+		files = append(files, global)
+	} else {
+		xdgConfig := os.Getenv("XDG_CONFIG_HOME")
+		if home, err := os.UserHomeDir(); err == nil {
+			files = append(files, filepath.Join(home, ".gitconfig"))
+			if xdgConfig == "" {
+				xdgConfig = filepath.Join(home, ".config")
+			}
+		}
+		if xdgConfig != "" {
+			files = append(files, filepath.Join(xdgConfig, "git", "config"))
+		}
+	}
+	if isGitConfigTrue(os.Getenv("GIT_CONFIG_NOSYSTEM")) {
+		return files
+	}
+	return append(files, firstNonEmpty(os.Getenv("GIT_CONFIG_SYSTEM"), "/etc/gitconfig"))
+}
+
+// get returns the value of a config key ("section.name" or
+// "section.subsection.name") from the most specific file that sets it, or "".
+func (c gitConfig) get(key string) string {
+	section, rest, _ := strings.Cut(key, ".")
+	subsection, name := "", rest
+	if i := strings.LastIndexByte(rest, '.'); i >= 0 {
+		subsection, name = rest[:i], rest[i+1:]
+	}
+	for _, raw := range c {
+		if !raw.HasSection(section) {
+			continue
+		}
+		options := raw.Section(section).Options
+		if subsection != "" {
+			if !raw.Section(section).HasSubsection(subsection) {
+				continue
+			}
+			options = raw.Section(section).Subsection(subsection).Options
+		}
+		if options.Has(name) {
+			return strings.TrimSpace(options.Get(name))
+		}
+	}
+	return ""
+}
+
+// isGitConfigTrue reports whether a git config value is a true boolean.
+func isGitConfigTrue(value string) bool {
+	switch strings.ToLower(value) {
+	case "true", "yes", "on", "1":
+		return true
+	}
+	return false
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// repositoryGitDir returns the repository's git directory, or "" if it isn't
+// stored on disk (e.g. in-memory repositories in tests).
+func repositoryGitDir(repo *git.Repository) string {
+	fs, ok := repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return ""
+	}
+	return fs.Filesystem().Root()
+}
+
+// SignatureStatus is the verification status of a commit signature.
+type SignatureStatus string
+
+// Signature statuses, from git's %G? format placeholder.
+const (
+	SignatureNone         SignatureStatus = "none"         // N: not signed
+	SignatureGood         SignatureStatus = "good"         // G: good, trusted signature
+	SignatureUntrusted    SignatureStatus = "untrusted"    // U: good signature with unknown validity
+	SignatureExpired      SignatureStatus = "expired"      // X, Y: good signature by an expired key, or expired signature
+	SignatureRevoked      SignatureStatus = "revoked"      // R: good signature by a revoked key
+	SignatureBad          SignatureStatus = "bad"          // B: bad signature
+	SignatureUnverifiable SignatureStatus = "unverifiable" // E: signature can't be checked (e.g. missing key)
+)
+
+// CommitSignature is the verified signature of a metadata commit.
+type CommitSignature struct {
+	Commit plumbing.Hash   `json:"commit"`
+	Status SignatureStatus `json:"status"`
+
+	// Signer is the signer's identity (GPG user ID or SSH principal), if known
+	Signer string `json:"signer,omitempty"`
+
+	// Key is the fingerprint or ID of the signing key, if known
+	Key string `json:"key,omitempty"`
+}
+
+// IsSigned reports whether the commit carries a signature, valid or not.
+func (s CommitSignature) IsSigned() bool {
+	return s.Status != SignatureNone
+}
+
+// IsValid reports whether the signature verifies with a known key, trusted or not.
+func (s CommitSignature) IsValid() bool {
+	return s.Status == SignatureGood || s.Status == SignatureUntrusted
+}
+
+// String describes the signature for display, e.g. "good signature from Jane <jane@example.com>".
+func (s CommitSignature) String() string {
+	if s.Status == SignatureNone {
+		return "not signed"
+	}
+	desc := string(s.Status) + " signature"
+	if s.Signer != "" {
+		desc += " from " + s.Signer
+	}
+	if s.Key != "" {
+		desc += " (key " + s.Key + ")"
+	}
+	return desc
+}
+
+// verifyCommitBatchSize bounds the number of commits verified per git invocation.
+const verifyCommitBatchSize = 200
+
+// verifyCommitSignatures verifies the signatures of the given commits with
+// git, which checks them against the user's GPG keyring or SSH allowed signers.
+func verifyCommitSignatures(repo *git.Repository, hashes []plumbing.Hash) (map[plumbing.Hash]CommitSignature, error) {
+	result := make(map[plumbing.Hash]CommitSignature, len(hashes))
+	gitDir := repositoryGitDir(repo)
+	if gitDir == "" {
+		for _, h := range hashes {
+			result[h] = CommitSignature{Commit: h, Status: SignatureUnverifiable}
+		}
+		return result, nil
+	}
+
+	for start := 0; start < len(hashes); start += verifyCommitBatchSize {
+		batch := hashes[start:min(start+verifyCommitBatchSize, len(hashes))]
+		args := []string{"--git-dir", gitDir, "log", "--no-walk=unsorted", "--format=%H%x00%G?%x00%GS%x00%GK"}
+		for _, h := range batch {
+			args = append(args, h.String())
+		}
+		output, err := exec.CommandContext(context.Background(), "git", args...).Output() //nolint:gosec // Arguments are commit hashes
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify commit signatures: %w", err)
+		}
+		for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+			fields := strings.Split(line, "\x00")
+			if len(fields) != 4 {
+				continue
+			}
+			h := plumbing.NewHash(fields[0])
+			result[h] = CommitSignature{
+				Commit: h,
+				Status: signatureStatus(fields[1]),
+				Signer: fields[2],
+				Key:    fields[3],
+			}
+		}
+	}
+	return result, nil
+}
+
+func signatureStatus(code string) SignatureStatus {
+	switch code {
+	case "G":
+		return SignatureGood
+	case "U":
+		return SignatureUntrusted
+	case "X", "Y":
+		return SignatureExpired
+	case "R":
+		return SignatureRevoked
+	case "B":
+		return SignatureBad
+	case "E":
+		return SignatureUnverifiable
+	default:
+		return SignatureNone
+	}
+}
+
+// CheckpointSignature is the least trusted signature among the commits that
+// wrote to a checkpoint.
+type CheckpointSignature struct {
+	CheckpointID id.CheckpointID `json:"checkpoint_id"`
+	CommitSignature
+}
+
+// GetCheckpointSignature verifies the signatures of the entire/checkpoints/v1
+// commits that wrote to the checkpoint and returns the least trusted one.
+// Returns nil if the checkpoint is not found.
+func (s *GitStore) GetCheckpointSignature(ctx context.Context, checkpointID id.CheckpointID) (*CommitSignature, error) {
+	signatures, err := s.verifyCheckpointCommits(ctx, func(cpID id.CheckpointID) bool { return cpID == checkpointID })
+	if err != nil {
+		return nil, err
+	}
+	if len(signatures) == 0 {
+		return nil, nil //nolint:nilnil // Checkpoint not found
+	}
+	return &signatures[0].CommitSignature, nil
+}
+
+// VerifySignatures verifies the signatures of the entire/checkpoints/v1
+// commits that wrote to each checkpoint, from the one that introduced it to
+// later updates (summaries, merges), and reports the least trusted one per
+// checkpoint. Results are sorted by checkpoint ID.
+func (s *GitStore) VerifySignatures(ctx context.Context) ([]CheckpointSignature, error) {
+	return s.verifyCheckpointCommits(ctx, nil)
+}
+
+// verifyCheckpointCommits verifies the commits that wrote to the checkpoints
+// matching include (all if nil) and returns the least trusted signature of
+// each, sorted by checkpoint ID. On a tie the oldest commit is reported.
+func (s *GitStore) verifyCheckpointCommits(ctx context.Context, include func(id.CheckpointID) bool) ([]CheckpointSignature, error) {
+	_ = ctx // Reserved for future use
+
+	ref, err := s.repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	if err != nil {
+		return nil, nil //nolint:nilerr // No metadata branch, no checkpoints
+	}
+	iter, err := s.repo.Log(&git.LogOptions{From: ref.Hash()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metadata branch log: %w", err)
+	}
+	defer iter.Close()
+
+	// Commits that wrote to each checkpoint, newest first
+	written := make(map[id.CheckpointID][]plumbing.Hash)
+	var commits []plumbing.Hash
+	err = iter.ForEach(func(c *object.Commit) error {
+		changed, err := s.changedCheckpoints(c)
+		if err != nil {
+			return err
+		}
+		wrote := false
+		for _, cpID := range changed {
+			if include == nil || include(cpID) {
+				written[cpID] = append(written[cpID], c.Hash)
+				wrote = true
+			}
+		}
+		if wrote {
+			commits = append(commits, c.Hash)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk metadata branch: %w", err)
+	}
+
+	signatures, err := verifyCommitSignatures(s.repo, commits)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]CheckpointSignature, 0, len(written))
+	for cpID, commitHashes := range written {
+		var least CommitSignature
+		for i, commitHash := range commitHashes {
+			signature, ok := signatures[commitHash]
+			if !ok {
+				signature = CommitSignature{Commit: commitHash, Status: SignatureUnverifiable}
+			}
+			if i == 0 || signatureTrust(signature.Status) <= signatureTrust(least.Status) {
+				least = signature
+			}
+		}
+		result = append(result, CheckpointSignature{CheckpointID: cpID, CommitSignature: least})
+	}
+	slices.SortFunc(result, func(a, b CheckpointSignature) int {
+		return strings.Compare(a.CheckpointID.String(), b.CheckpointID.String())
+	})
+	return result, nil
+}
+
+// signatureTrust ranks signature statuses from bad (lowest) to good.
+func signatureTrust(status SignatureStatus) int {
+	switch status {
+	case SignatureGood:
+		return 6
+	case SignatureUntrusted:
+		return 5
+	case SignatureExpired:
+		return 4
+	case SignatureUnverifiable:
+		return 3
+	case SignatureNone:
+		return 2
+	case SignatureRevoked:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// changedCheckpoints returns the checkpoints whose directory in the commit's
+// tree differs from (or is missing in) at least one of its parents'. Only
+// shard directories that differ from a parent are listed.
+func (s *GitStore) changedCheckpoints(c *object.Commit) ([]id.CheckpointID, error) {
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tree of %s: %w", c.Hash, err)
+	}
+	var parentTrees []*object.Tree
+	for _, parentHash := range c.ParentHashes {
+		parent, err := s.repo.CommitObject(parentHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read parent of %s: %w", c.Hash, err)
+		}
+		parentTree, err := parent.Tree()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tree of %s: %w", parentHash, err)
+		}
+		parentTrees = append(parentTrees, parentTree)
+	}
+
+	var changed []id.CheckpointID
+	for _, shard := range tree.Entries {
+		if shard.Mode != filemode.Dir || len(shard.Name) != 2 {
+			continue
+		}
+		// Each parent's version of the shard's checkpoint directories, nil if
+		// the parent doesn't have the shard
+		parentShards := make([]map[string]plumbing.Hash, 0, len(parentTrees))
+		unchanged := len(parentTrees) > 0
+		for _, parentTree := range parentTrees {
+			parentShard, err := parentTree.FindEntry(shard.Name)
+			if err != nil {
+				unchanged = false
+				parentShards = append(parentShards, nil)
+				continue
+			}
+			if parentShard.Hash != shard.Hash {
+				unchanged = false
+			}
+			existing := make(map[string]plumbing.Hash)
+			if shardTree, err := s.repo.TreeObject(parentShard.Hash); err == nil {
+				for _, entry := range shardTree.Entries {
+					existing[entry.Name] = entry.Hash
+				}
+			}
+			parentShards = append(parentShards, existing)
+		}
+		if unchanged {
+			continue
+		}
+
+		shardTree, err := s.repo.TreeObject(shard.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", shard.Name, err)
+		}
+		for _, entry := range shardTree.Entries {
+			if entry.Mode != filemode.Dir {
+				continue
+			}
+			differs := len(parentShards) == 0
+			for _, existing := range parentShards {
+				if hash, ok := existing[entry.Name]; !ok || hash != entry.Hash {
+					differs = true
+					break
+				}
+			}
+			if !differs {
+				continue
+			}
+			if cpID, err := id.NewCheckpointID(shard.Name + entry.Name); err == nil {
+				changed = append(changed, cpID)
+			}
+		}
+	}
+	return changed, nil
+}
@@ -0,0 +1,150 @@
+package checkpoint
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	format "github.com/go-git/go-git/v5/plumbing/format/config"
+)
+
+// configureSSHSigning creates an SSH key and configures the repository to sign
+// commits with it and to trust it for verification.
+func configureSSHSigning(t *testing.T, repo *git.Repository) {
+	t.Helper()
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not available")
+	}
+	keyDir := t.TempDir()
+	keyFile := filepath.Join(keyDir, "id_ed25519")
+	if output, err := exec.CommandContext(context.Background(), "ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "test", "-f", keyFile).CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen failed: %v: %s", err, output)
+	}
+	publicKey, err := os.ReadFile(keyFile + ".pub")
+	if err != nil {
+		t.Fatalf("failed to read public key: %v", err)
+	}
+	allowedSigners := filepath.Join(keyDir, "allowed_signers")
+	if err := os.WriteFile(allowedSigners, []byte("test@test.com "+string(publicKey)), 0o600); err != nil {
+		t.Fatalf("failed to write allowed signers: %v", err)
+	}
+
+	setGitConfig(t, repo, "commit.gpgsign", "true")
+	setGitConfig(t, repo, "gpg.format", "ssh")
+	setGitConfig(t, repo, "user.signingkey", keyFile)
+	setGitConfig(t, repo, "gpg.ssh.allowedSignersFile", allowedSigners)
+}
+
+func setGitConfig(t *testing.T, repo *git.Repository, key, value string) {
+	t.Helper()
+	if output, err := exec.CommandContext(context.Background(), "git", "--git-dir", repositoryGitDir(repo), "config", key, value).CombinedOutput(); err != nil {
+		t.Fatalf("git config %s failed: %v: %s", key, err, output)
+	}
+}
+
+// TestVerifySignatures verifies that metadata commits are signed when
+// commit.gpgsign is set and that each checkpoint reports who signed the
+// commit that created it.
+func TestVerifySignatures(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	unsigned := writeIndexTestCheckpoint(t, store, "f0f0f0f0f0f1", "session-1")
+
+	configureSSHSigning(t, repo)
+	signed := writeIndexTestCheckpoint(t, store, "f0f0f0f0f0f2", "session-2")
+
+	tip, err := repo.CommitObject(metadataBranchTip(t, repo))
+	if err != nil {
+		t.Fatalf("failed to read metadata branch tip: %v", err)
+	}
+	if !strings.Contains(tip.PGPSignature, "BEGIN SSH SIGNATURE") {
+		t.Fatalf("metadata commit signature = %q, want an SSH signature", tip.PGPSignature)
+	}
+
+	signatures, err := store.VerifySignatures(context.Background())
+	if err != nil {
+		t.Fatalf("VerifySignatures() error = %v", err)
+	}
+	if len(signatures) != 2 {
+		t.Fatalf("VerifySignatures() = %+v, want 2 checkpoints", signatures)
+	}
+	if signatures[0].CheckpointID != unsigned || signatures[0].IsSigned() {
+		t.Errorf("signature of %s = %+v, want not signed", unsigned, signatures[0])
+	}
+	if signatures[1].CheckpointID != signed || !signatures[1].IsValid() || signatures[1].Signer != "test@test.com" {
+		t.Errorf("signature of %s = %+v, want a valid signature from test@test.com", signed, signatures[1])
+	}
+
+	signature, err := store.GetCheckpointSignature(context.Background(), signed)
+	if err != nil {
+		t.Fatalf("GetCheckpointSignature() error = %v", err)
+	}
+	if signature == nil || signature.Commit != tip.Hash || !signature.IsValid() {
+		t.Errorf("GetCheckpointSignature() = %+v, want a valid signature on %s", signature, tip.Hash)
+	}
+
+	// An unsigned commit updating the checkpoint later is reported
+	setGitConfig(t, repo, "commit.gpgsign", "false")
+	if err := store.UpdateSummary(context.Background(), signed, &Summary{Intent: "update"}, nil); err != nil {
+		t.Fatalf("UpdateSummary() error = %v", err)
+	}
+	signature, err = store.GetCheckpointSignature(context.Background(), signed)
+	if err != nil {
+		t.Fatalf("GetCheckpointSignature() error = %v", err)
+	}
+	if signature == nil || signature.Commit != metadataBranchTip(t, repo) || signature.IsSigned() {
+		t.Errorf("GetCheckpointSignature() after an unsigned update = %+v, want the unsigned update", signature)
+	}
+}
+
+// TestSignCommit_FailureWritesUnsigned verifies that a failing signing program
+// doesn't fail checkpoint writes: the commits are written unsigned.
+func TestSignCommit_FailureWritesUnsigned(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	setGitConfig(t, repo, "commit.gpgsign", "true")
+	setGitConfig(t, repo, "gpg.program", "false")
+	t.Cleanup(func() { signingFailed.Store(false) })
+
+	writeIndexTestCheckpoint(t, store, "f0f0f0f0f0f3", "session-1")
+	writeIndexTestCheckpoint(t, store, "f0f0f0f0f0f4", "session-2")
+
+	tip, err := repo.CommitObject(metadataBranchTip(t, repo))
+	if err != nil {
+		t.Fatalf("failed to read metadata branch tip: %v", err)
+	}
+	if tip.PGPSignature != "" {
+		t.Errorf("metadata commit signature = %q, want none", tip.PGPSignature)
+	}
+	if !signingFailed.Load() {
+		t.Error("signing failure wasn't recorded")
+	}
+}
+
+// TestGitConfigGet verifies that config values come from the most specific
+// file, including subsection keys.
+func TestGitConfigGet(t *testing.T) {
+	local := format.New()
+	local.Section("commit").SetOption("gpgsign", "true")
+	global := format.New()
+	global.Section("commit").SetOption("gpgsign", "false")
+	global.Section("gpg").SetOption("program", "gpg2")
+	global.Section("gpg").Subsection("ssh").SetOption("program", "ssh-sign")
+	cfg := gitConfig{local, global}
+
+	for key, want := range map[string]string{
+		"commit.gpgsign":   "true",
+		"gpg.program":      "gpg2",
+		"gpg.ssh.program":  "ssh-sign",
+		"gpg.x509.program": "",
+		"user.signingkey":  "",
+	} {
+		if got := cfg.get(key); got != want {
+			t.Errorf("get(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
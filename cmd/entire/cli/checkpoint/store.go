@@ -1,6 +1,8 @@
 package checkpoint
 
 import (
+	"sync"
+
 	"github.com/go-git/go-git/v5"
 )
 
@@ -11,6 +13,14 @@ var _ Store = (*GitStore)(nil)
 // It implements the Store interface by wrapping a git repository.
 type GitStore struct {
 	repo *git.Repository
+
+	// index caches the checkpoint index between calls (see checkpointIndex)
+	indexMu sync.Mutex
+	index   *checkpointIndex
+
+	// notes caches the commit notes between calls (see commitNotes)
+	notesMu sync.Mutex
+	notes   *commitNotes
 }
 
 // NewGitStore creates a new checkpoint store backed by the given git repository.
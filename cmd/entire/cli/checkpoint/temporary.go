@@ -133,6 +133,7 @@ func (s *GitStore) WriteTemporary(ctx context.Context, opts WriteTemporaryOption
 	if err := s.repo.Storer.SetReference(newRef); err != nil {
 		return WriteTemporaryResult{}, fmt.Errorf("failed to update branch reference: %w", err)
 	}
+	s.repackAfterWrite(ctx)
 
 	return WriteTemporaryResult{
 		CommitHash: commitHash,
@@ -226,8 +227,6 @@ func (s *GitStore) ListTemporary(ctx context.Context) ([]TemporaryInfo, error) {
 // Task checkpoints include both code changes and task-specific metadata.
 // Returns the commit hash of the created checkpoint.
 func (s *GitStore) WriteTemporaryTask(ctx context.Context, opts WriteTemporaryTaskOptions) (plumbing.Hash, error) {
-	_ = ctx // Reserved for future use
-
 	// Validate base commit - required for shadow branch naming
 	if opts.BaseCommit == "" {
 		return plumbing.ZeroHash, errors.New("BaseCommit is required for task checkpoint")
@@ -282,6 +281,7 @@ func (s *GitStore) WriteTemporaryTask(ctx context.Context, opts WriteTemporaryTa
 	if err := s.repo.Storer.SetReference(ref); err != nil {
 		return plumbing.ZeroHash, fmt.Errorf("failed to update shadow branch reference: %w", err)
 	}
+	s.repackAfterWrite(ctx)
 
 	return commitHash, nil
 }
@@ -619,6 +619,13 @@ func (s *GitStore) DeleteShadowBranch(baseCommit, worktreeID string) error {
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("failed to delete shadow branch %s: %s: %w", shadowBranchName, strings.TrimSpace(string(output)), err)
 	}
+
+	// The pre-rewind snapshot belongs to the shadow branch; drop it too (best-effort)
+	preRewindRef := PreRewindRefName(baseCommit, worktreeID).String()
+	if _, err := s.repo.Reference(plumbing.ReferenceName(preRewindRef), true); err == nil {
+		//nolint:errcheck,gosec // Best-effort cleanup; ref name is constructed from commit hash
+		_ = exec.CommandContext(context.Background(), "git", "update-ref", "-d", preRewindRef).Run()
+	}
 	return nil
 }
 
@@ -772,6 +779,8 @@ func (s *GitStore) createCommit(treeHash, parentHash plumbing.Hash, message, aut
 		commit.ParentHashes = []plumbing.Hash{parentHash}
 	}
 
+	SignCommit(s.repo, commit)
+
 	obj := s.repo.Storer.NewEncodedObject()
 	if err := commit.Encode(obj); err != nil {
 		return plumbing.ZeroHash, fmt.Errorf("failed to encode commit: %w", err)
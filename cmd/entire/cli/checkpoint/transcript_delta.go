@@ -0,0 +1,116 @@
+package checkpoint
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// maxTranscriptDeltaDepth bounds the chain of base checkpoints followed when
+// reconstructing a transcript, so a malformed chain can't loop forever.
+const maxTranscriptDeltaDepth = 1000
+
+// TranscriptDelta describes a transcript stored as a delta: the stored file
+// holds only the lines appended since the session's previous checkpoint, and
+// the transcript starts with the session's full transcript in that checkpoint.
+type TranscriptDelta struct {
+	// Base is the checkpoint holding the beginning of the transcript
+	Base id.CheckpointID `json:"base"`
+
+	// BaseSize is the size in bytes of the base transcript, checked on reconstruction
+	BaseSize int `json:"base_size"`
+}
+
+// ErrTranscriptDeltaBase is returned when a delta transcript can't be
+// reconstructed because its base checkpoint is missing or has changed.
+var ErrTranscriptDeltaBase = errors.New("transcript delta base not available")
+
+// transcriptDelta returns the part of transcript to store when opts ask for
+// delta storage, and the delta describing the rest. The previous checkpoint's
+// transcript of the session must be a prefix of transcript ending at a line
+// boundary; otherwise (e.g. agents that rewrite a JSON document) the whole
+// transcript is returned with a nil delta.
+func (s *GitStore) transcriptDelta(opts WriteCommittedOptions, transcript []byte) (*TranscriptDelta, []byte) {
+	if !opts.TranscriptDelta || opts.PreviousCheckpointID.IsEmpty() || opts.PreviousCheckpointID == opts.CheckpointID {
+		return nil, transcript
+	}
+
+	tree, err := s.getSessionsBranchTree()
+	if err != nil {
+		return nil, transcript
+	}
+	base, err := s.readSessionTranscript(tree, opts.PreviousCheckpointID, opts.SessionID, 0)
+	if err != nil || len(base) == 0 || base[len(base)-1] != '\n' {
+		return nil, transcript
+	}
+	if len(base) >= len(transcript) || !bytes.HasPrefix(transcript, base) {
+		return nil, transcript
+	}
+
+	return &TranscriptDelta{Base: opts.PreviousCheckpointID, BaseSize: len(base)}, transcript[len(base):]
+}
+
+// resolveTranscriptDelta returns the full transcript of a session stored as a
+// delta, by prepending the session's transcript in the base checkpoint.
+func (s *GitStore) resolveTranscriptDelta(tree *object.Tree, metadata *CommittedMetadata, stored []byte, depth int) ([]byte, error) {
+	delta := metadata.TranscriptDelta
+	if depth >= maxTranscriptDeltaDepth {
+		return nil, fmt.Errorf("%w: chain from %s is longer than %d checkpoints", ErrTranscriptDeltaBase, delta.Base, maxTranscriptDeltaDepth)
+	}
+
+	base, err := s.readSessionTranscript(tree, delta.Base, metadata.SessionID, depth+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(base) != delta.BaseSize {
+		return nil, fmt.Errorf("%w: checkpoint %s transcript is %d bytes, want %d", ErrTranscriptDeltaBase, delta.Base, len(base), delta.BaseSize)
+	}
+
+	transcript := make([]byte, 0, len(base)+len(stored))
+	transcript = append(transcript, base...)
+	return append(transcript, stored...), nil
+}
+
+// readSessionTranscript returns the full transcript of a session in a
+// checkpoint, reconstructing it if it is stored as a delta.
+func (s *GitStore) readSessionTranscript(tree *object.Tree, checkpointID id.CheckpointID, sessionID string, depth int) ([]byte, error) {
+	checkpointTree, err := tree.Tree(checkpointID.Path())
+	if err != nil {
+		return nil, fmt.Errorf("%w: checkpoint %s not found", ErrTranscriptDeltaBase, checkpointID)
+	}
+
+	for i := 0; ; i++ {
+		sessionTree, err := checkpointTree.Tree(strconv.Itoa(i))
+		if err != nil {
+			return nil, fmt.Errorf("%w: session %s not found in checkpoint %s", ErrTranscriptDeltaBase, sessionID, checkpointID)
+		}
+		metadataFile, err := sessionTree.File(paths.MetadataFileName)
+		if err != nil {
+			continue
+		}
+		content, err := metadataFile.Contents()
+		if err != nil {
+			continue
+		}
+		var metadata CommittedMetadata
+		if json.Unmarshal([]byte(content), &metadata) != nil || metadata.SessionID != sessionID {
+			continue
+		}
+
+		stored, err := readTranscriptFromTree(sessionTree, metadata.Agent)
+		if err != nil {
+			return nil, err
+		}
+		if metadata.TranscriptDelta == nil {
+			return stored, nil
+		}
+		return s.resolveTranscriptDelta(tree, &metadata, stored, depth)
+	}
+}
@@ -0,0 +1,136 @@
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+func transcriptLines(n int) string {
+	var sb strings.Builder
+	for i := range n {
+		sb.WriteString(`{"type":"user","uuid":"u` + string(rune('a'+i)) + `","message":{"content":"prompt"}}` + "\n")
+	}
+	return sb.String()
+}
+
+func writeDeltaCheckpoint(t *testing.T, store *GitStore, cpID, previous id.CheckpointID, transcript, compression string) {
+	t.Helper()
+	err := store.WriteCommitted(context.Background(), WriteCommittedOptions{
+		CheckpointID:          cpID,
+		SessionID:             "delta-session",
+		Strategy:              "manual-commit",
+		Transcript:            []byte(transcript),
+		CheckpointsCount:      1,
+		AuthorName:            "Test Author",
+		AuthorEmail:           "test@example.com",
+		TranscriptCompression: compression,
+		TranscriptDelta:       true,
+		PreviousCheckpointID:  previous,
+	})
+	if err != nil {
+		t.Fatalf("WriteCommitted(%s) error = %v", cpID, err)
+	}
+}
+
+// TestWriteCommitted_TranscriptDelta verifies that checkpoints of a session
+// only store the lines added since the previous checkpoint and that the full
+// transcript is reconstructed through the chain on read.
+func TestWriteCommitted_TranscriptDelta(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	first := id.MustCheckpointID("d1d2d3d4d5d1")
+	second := id.MustCheckpointID("d1d2d3d4d5d2")
+	third := id.MustCheckpointID("d1d2d3d4d5d3")
+
+	writeDeltaCheckpoint(t, store, first, id.EmptyCheckpointID, transcriptLines(3), CompressionNone)
+	writeDeltaCheckpoint(t, store, second, first, transcriptLines(5), CompressionNone)
+	writeDeltaCheckpoint(t, store, third, second, transcriptLines(7), CompressionZstd)
+
+	tree, err := store.getSessionsBranchTree()
+	if err != nil {
+		t.Fatalf("getSessionsBranchTree() error = %v", err)
+	}
+	file, err := tree.File(second.Path() + "/0/" + paths.TranscriptFileName)
+	if err != nil {
+		t.Fatalf("transcript of second checkpoint not found: %v", err)
+	}
+	stored, err := file.Contents()
+	if err != nil {
+		t.Fatalf("failed to read stored transcript: %v", err)
+	}
+	if want := strings.TrimPrefix(transcriptLines(5), transcriptLines(3)); stored != want {
+		t.Errorf("stored transcript = %q, want only the new lines %q", stored, want)
+	}
+
+	for _, tt := range []struct {
+		cpID  id.CheckpointID
+		lines int
+		base  id.CheckpointID
+	}{
+		{first, 3, id.EmptyCheckpointID},
+		{second, 5, first},
+		{third, 7, second},
+	} {
+		content, err := store.ReadSessionContent(context.Background(), tt.cpID, 0)
+		if err != nil {
+			t.Fatalf("ReadSessionContent(%s) error = %v", tt.cpID, err)
+		}
+		if got, want := string(content.Transcript), transcriptLines(tt.lines); got != want {
+			t.Errorf("ReadSessionContent(%s) transcript = %q, want %q", tt.cpID, got, want)
+		}
+		delta := content.Metadata.TranscriptDelta
+		switch {
+		case tt.base.IsEmpty() && delta != nil:
+			t.Errorf("checkpoint %s: TranscriptDelta = %+v, want nil", tt.cpID, delta)
+		case !tt.base.IsEmpty() && (delta == nil || delta.Base != tt.base):
+			t.Errorf("checkpoint %s: TranscriptDelta = %+v, want base %s", tt.cpID, delta, tt.base)
+		}
+	}
+}
+
+// TestWriteCommitted_TranscriptDelta_NotAPrefix verifies that a transcript
+// that doesn't extend the previous one is stored in full.
+func TestWriteCommitted_TranscriptDelta_NotAPrefix(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	first := id.MustCheckpointID("d1d2d3d4d5e1")
+	second := id.MustCheckpointID("d1d2d3d4d5e2")
+
+	writeDeltaCheckpoint(t, store, first, id.EmptyCheckpointID, `{"messages":[1,2]}`+"\n", CompressionNone)
+	writeDeltaCheckpoint(t, store, second, first, `{"messages":[1,2,3]}`+"\n", CompressionNone)
+
+	content, err := store.ReadSessionContent(context.Background(), second, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionContent() error = %v", err)
+	}
+	if content.Metadata.TranscriptDelta != nil {
+		t.Errorf("TranscriptDelta = %+v, want nil", content.Metadata.TranscriptDelta)
+	}
+	if got := string(content.Transcript); got != `{"messages":[1,2,3]}`+"\n" {
+		t.Errorf("Transcript = %q", got)
+	}
+}
+
+// TestReadSessionContent_TranscriptDeltaBaseChanged verifies that a delta
+// whose base no longer matches is reported instead of returning a corrupt transcript.
+func TestReadSessionContent_TranscriptDeltaBaseChanged(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	first := id.MustCheckpointID("d1d2d3d4d5f1")
+	second := id.MustCheckpointID("d1d2d3d4d5f2")
+
+	writeDeltaCheckpoint(t, store, first, id.EmptyCheckpointID, transcriptLines(3), CompressionNone)
+	writeDeltaCheckpoint(t, store, second, first, transcriptLines(5), CompressionNone)
+	// Rewrite the base with a shorter transcript
+	writeDeltaCheckpoint(t, store, first, id.EmptyCheckpointID, transcriptLines(2), CompressionNone)
+
+	_, err := store.ReadSessionContent(context.Background(), second, 0)
+	if !errors.Is(err, ErrTranscriptDeltaBase) {
+		t.Fatalf("ReadSessionContent() error = %v, want ErrTranscriptDeltaBase", err)
+	}
+}
@@ -15,7 +15,7 @@ func newCleanCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "clean",
 		Short: "Clean up orphaned Entire data",
-		Long: `Remove orphaned Entire data (session state, shadow branches, checkpoint metadata) that wasn't cleaned up automatically.
+		Long: `Remove orphaned Entire data (session state, shadow branches, checkpoint metadata, pre-rewind snapshots) that wasn't cleaned up automatically.
 
 This command finds and removes orphaned data from any strategy:
 
@@ -33,6 +33,10 @@ This command finds and removes orphaned data from any strategy:
     Manual-commit checkpoints are permanent (condensed history) and are
     never considered orphaned.
 
+  Pre-rewind snapshots (refs/entire/pre-rewind/)
+    Let a rewind be undone. Orphaned when their shadow branch or the
+    base commit they would restore no longer exists.
+
 Default: shows a preview of items that would be deleted.
 With --force, actually deletes the orphaned items.
 
@@ -74,7 +78,7 @@ func runCleanWithItems(w io.Writer, force bool, items []strategy.CleanupItem) er
 	}
 
 	// Group items by type for display
-	var branches, states, checkpoints []strategy.CleanupItem
+	var branches, states, checkpoints, preRewindRefs []strategy.CleanupItem
 	for _, item := range items {
 		switch item.Type {
 		case strategy.CleanupTypeShadowBranch:
@@ -83,6 +87,8 @@ func runCleanWithItems(w io.Writer, force bool, items []strategy.CleanupItem) er
 			states = append(states, item)
 		case strategy.CleanupTypeCheckpoint:
 			checkpoints = append(checkpoints, item)
+		case strategy.CleanupTypePreRewindRef:
+			preRewindRefs = append(preRewindRefs, item)
 		}
 	}
 
@@ -114,6 +120,14 @@ func runCleanWithItems(w io.Writer, force bool, items []strategy.CleanupItem) er
 			fmt.Fprintln(w)
 		}
 
+		if len(preRewindRefs) > 0 {
+			fmt.Fprintf(w, "Pre-rewind snapshots (%d):\n", len(preRewindRefs))
+			for _, item := range preRewindRefs {
+				fmt.Fprintf(w, "  %s\n", item.ID)
+			}
+			fmt.Fprintln(w)
+		}
+
 		fmt.Fprintln(w, "Run with --force to delete these items.")
 		return nil
 	}
@@ -125,8 +139,8 @@ func runCleanWithItems(w io.Writer, force bool, items []strategy.CleanupItem) er
 	}
 
 	// Report results
-	totalDeleted := len(result.ShadowBranches) + len(result.SessionStates) + len(result.Checkpoints)
-	totalFailed := len(result.FailedBranches) + len(result.FailedStates) + len(result.FailedCheckpoints)
+	totalDeleted := len(result.ShadowBranches) + len(result.SessionStates) + len(result.Checkpoints) + len(result.PreRewindRefs)
+	totalFailed := len(result.FailedBranches) + len(result.FailedStates) + len(result.FailedCheckpoints) + len(result.FailedPreRewindRefs)
 
 	if totalDeleted > 0 {
 		fmt.Fprintf(w, "Deleted %d items:\n", totalDeleted)
@@ -151,6 +165,13 @@ func runCleanWithItems(w io.Writer, force bool, items []strategy.CleanupItem) er
 				fmt.Fprintf(w, "    %s\n", cp)
 			}
 		}
+
+		if len(result.PreRewindRefs) > 0 {
+			fmt.Fprintf(w, "\n  Pre-rewind snapshots (%d):\n", len(result.PreRewindRefs))
+			for _, ref := range result.PreRewindRefs {
+				fmt.Fprintf(w, "    %s\n", ref)
+			}
+		}
 	}
 
 	if totalFailed > 0 {
@@ -177,6 +198,13 @@ func runCleanWithItems(w io.Writer, force bool, items []strategy.CleanupItem) er
 			}
 		}
 
+		if len(result.FailedPreRewindRefs) > 0 {
+			fmt.Fprintf(w, "\n  Pre-rewind snapshots:\n")
+			for _, ref := range result.FailedPreRewindRefs {
+				fmt.Fprintf(w, "    %s\n", ref)
+			}
+		}
+
 		return fmt.Errorf("failed to delete %d items", totalFailed)
 	}
 
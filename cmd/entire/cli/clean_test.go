@@ -6,8 +6,10 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
@@ -383,3 +385,75 @@ func TestRunCleanWithItems_MixedTypes_Preview(t *testing.T) {
 		t.Errorf("Expected 'Found 3 orphaned items', got: %s", output)
 	}
 }
+
+func TestRunClean_PreRewindRefs(t *testing.T) {
+	repo, commitHash := setupCleanTestRepo(t)
+
+	snapshot := func(baseCommit string) plumbing.Hash {
+		t.Helper()
+		sig := object.Signature{Name: "test", Email: "test@test.com"}
+		parent, err := repo.CommitObject(commitHash)
+		if err != nil {
+			t.Fatalf("failed to read commit: %v", err)
+		}
+		commit := &object.Commit{
+			TreeHash:     parent.TreeHash,
+			Author:       sig,
+			Committer:    sig,
+			Message:      trailers.FormatPreRewindCommit("Before rewind", "", nil, "", baseCommit, "abc1234"),
+			ParentHashes: []plumbing.Hash{commitHash},
+		}
+		obj := repo.Storer.NewEncodedObject()
+		if err := commit.Encode(obj); err != nil {
+			t.Fatalf("failed to encode commit: %v", err)
+		}
+		hash, err := repo.Storer.SetEncodedObject(obj)
+		if err != nil {
+			t.Fatalf("failed to store commit: %v", err)
+		}
+		return hash
+	}
+	setRef := func(name plumbing.ReferenceName, hash plumbing.Hash) {
+		t.Helper()
+		if err := repo.Storer.SetReference(plumbing.NewHashReference(name, hash)); err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		}
+	}
+
+	// Kept: shadow branch and base commit exist
+	kept := checkpoint.PreRewindRefForBranch("entire/abc1234")
+	setRef(plumbing.NewBranchReferenceName("entire/abc1234"), commitHash)
+	setRef(kept, snapshot(commitHash.String()))
+	// Orphaned: no shadow branch
+	noBranch := checkpoint.PreRewindRefForBranch("entire/def5678")
+	setRef(noBranch, snapshot(commitHash.String()))
+	// Orphaned: base commit is gone
+	noBase := checkpoint.PreRewindRefForBranch("entire/fed4321")
+	setRef(plumbing.NewBranchReferenceName("entire/fed4321"), commitHash)
+	setRef(noBase, snapshot(strings.Repeat("f", 40)))
+
+	var stdout bytes.Buffer
+	if err := runClean(&stdout, false); err != nil {
+		t.Fatalf("runClean() error = %v", err)
+	}
+	output := stdout.String()
+	for _, ref := range []plumbing.ReferenceName{noBranch, noBase} {
+		if !strings.Contains(output, ref.String()) {
+			t.Errorf("Expected %s in output, got: %s", ref, output)
+		}
+	}
+	if strings.Contains(output, kept.String()) {
+		t.Errorf("Should not list %s, got: %s", kept, output)
+	}
+
+	// Deleting the (orphaned) shadow branches takes their pre-rewind refs along
+	stdout.Reset()
+	if err := runClean(&stdout, true); err != nil {
+		t.Fatalf("runClean() error = %v", err)
+	}
+	for _, ref := range []plumbing.ReferenceName{kept, noBranch, noBase} {
+		if _, err := repo.Reference(ref, true); err == nil {
+			t.Errorf("%s should be deleted but still exists", ref)
+		}
+	}
+}
@@ -0,0 +1,375 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/merkletrie"
+	"github.com/spf13/cobra"
+)
+
+// Kinds of diff sides, also used in the --json output.
+const (
+	diffKindCheckpoint = "checkpoint" // committed checkpoint, diffed as the commit that references it
+	diffKindTemporary  = "temporary"  // commit on a shadow branch
+	diffKindCommit     = "commit"
+	diffKindWorktree   = "worktree"
+)
+
+// diffMode selects how entire diff prints the changes.
+type diffMode int
+
+const (
+	diffModePatch diffMode = iota
+	diffModeStat
+	diffModeNameOnly
+	diffModeJSON
+)
+
+// diffSide is one side of a diff: what it was resolved from and its tree.
+type diffSide struct {
+	Ref          string          `json:"ref"`
+	Kind         string          `json:"kind"`
+	Commit       string          `json:"commit,omitempty"`
+	CheckpointID id.CheckpointID `json:"checkpoint_id,omitempty"`
+	tree         *object.Tree
+}
+
+// diffFile is a changed file in the --json output.
+type diffFile struct {
+	Path      string `json:"path"`
+	Status    string `json:"status"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+}
+
+// diffReport is the --json output of entire diff.
+type diffReport struct {
+	From  diffSide   `json:"from"`
+	To    diffSide   `json:"to"`
+	Files []diffFile `json:"files"`
+}
+
+func newDiffCmd() *cobra.Command {
+	var worktreeFlag bool
+	var statFlag bool
+	var nameOnlyFlag bool
+	var jsonFlag bool
+	var noPagerFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "diff <checkpoint> [<checkpoint>]",
+		Short: "Show changes between checkpoints, commits and the working tree",
+		Long: `Show the code changes between two checkpoints as a unified diff.
+
+A checkpoint can be given as:
+  - a committed checkpoint ID (or prefix), compared as the commit that
+    references it
+  - a temporary checkpoint, by the SHA (or prefix) of its shadow branch commit,
+    as listed by 'entire rewind --list'
+  - any commit SHA or ref
+
+Committed checkpoint IDs are tried first, then temporary checkpoints, then
+commits.
+
+With one checkpoint, it is compared with HEAD, or with the working tree when
+--worktree is given. Files under .entire/ are never shown.
+
+Output modes:
+  Default       Unified diff
+  --stat        Changed files with a count of changed lines
+  --name-only   Changed file paths only
+  --json        Changed files with their status and line counts as JSON`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if checkDisabledGuard(cmd.OutOrStdout()) {
+				return nil
+			}
+			if worktreeFlag && len(args) == 2 {
+				return errors.New("--worktree cannot be combined with a second checkpoint")
+			}
+
+			mode := diffModePatch
+			switch {
+			case statFlag:
+				mode = diffModeStat
+			case nameOnlyFlag:
+				mode = diffModeNameOnly
+			case jsonFlag:
+				mode = diffModeJSON
+			}
+
+			to := ""
+			if len(args) == 2 {
+				to = args[1]
+			}
+			return runDiff(context.Background(), cmd.OutOrStdout(), args[0], to, worktreeFlag, mode, noPagerFlag)
+		},
+	}
+
+	cmd.Flags().BoolVar(&worktreeFlag, "worktree", false, "Compare with the working tree instead of HEAD")
+	cmd.Flags().BoolVar(&statFlag, "stat", false, "Show changed files with a count of changed lines")
+	cmd.Flags().BoolVar(&nameOnlyFlag, "name-only", false, "Show changed file paths only")
+	cmd.Flags().BoolVar(&jsonFlag, "json", false, "Output changed files as JSON")
+	cmd.Flags().BoolVar(&noPagerFlag, "no-pager", false, "Disable pager output")
+
+	cmd.MarkFlagsMutuallyExclusive("stat", "name-only", "json")
+
+	return cmd
+}
+
+// runDiff prints the changes from one checkpoint to another. An empty to
+// compares with HEAD, or with the working tree if worktree is set.
+func runDiff(ctx context.Context, w io.Writer, from, to string, worktree bool, mode diffMode, noPager bool) error {
+	repo, err := openRepository()
+	if err != nil {
+		return fmt.Errorf("not a git repository: %w", err)
+	}
+	store := checkpoint.NewGitStore(repo)
+
+	fromSide, err := resolveDiffSide(ctx, repo, store, from)
+	if err != nil {
+		return err
+	}
+	var toSide *diffSide
+	switch {
+	case worktree:
+		toSide, err = resolveWorktreeDiffSide(ctx, repo, store)
+	case to != "":
+		toSide, err = resolveDiffSide(ctx, repo, store, to)
+	default:
+		toSide, err = resolveDiffSide(ctx, repo, store, "HEAD")
+	}
+	if err != nil {
+		return err
+	}
+
+	changes, err := diffCheckpointTrees(ctx, fromSide.tree, toSide.tree)
+	if err != nil {
+		return err
+	}
+
+	if mode == diffModeNameOnly {
+		for _, change := range changes {
+			fmt.Fprintln(w, diffChangePath(change))
+		}
+		return nil
+	}
+
+	patch, err := changes.PatchContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to compute diff: %w", err)
+	}
+
+	var content string
+	switch mode {
+	case diffModeJSON:
+		report := diffReport{From: *fromSide, To: *toSide, Files: diffFiles(changes, patch)}
+		data, err := jsonutil.MarshalIndentWithNewline(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal diff: %w", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("failed to write diff: %w", err)
+		}
+		return nil
+	case diffModeStat:
+		content = formatDiffStat(changes, patch)
+	default:
+		content = patch.String()
+	}
+
+	if content == "" {
+		return nil
+	}
+	if noPager {
+		fmt.Fprint(w, content)
+	} else {
+		outputWithPager(w, content)
+	}
+	return nil
+}
+
+// resolveDiffSide resolves a committed checkpoint ID, a temporary checkpoint
+// or a commit to the tree to diff, in that order.
+func resolveDiffSide(ctx context.Context, repo *git.Repository, store *checkpoint.GitStore, ref string) (*diffSide, error) {
+	// Committed checkpoint by ID prefix
+	committed, err := store.ListCommitted(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+	var matches []id.CheckpointID
+	for _, info := range committed {
+		if strings.HasPrefix(info.CheckpointID.String(), ref) {
+			matches = append(matches, info.CheckpointID)
+		}
+	}
+	if len(matches) > 1 {
+		examples := make([]string, 0, 5)
+		for i := 0; i < len(matches) && i < 5; i++ {
+			examples = append(examples, matches[i].String())
+		}
+		return nil, fmt.Errorf("ambiguous checkpoint prefix %q matches %d checkpoints: %s", ref, len(matches), strings.Join(examples, ", "))
+	}
+	if len(matches) == 1 {
+		commits, err := getAssociatedCommits(repo, matches[0], true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the commit of checkpoint %s: %w", matches[0], err)
+		}
+		if len(commits) == 0 {
+			return nil, fmt.Errorf("checkpoint %s is not referenced by any commit in the history of HEAD", matches[0])
+		}
+		// Commits are listed newest first; an amended or rebased commit wins over the original
+		side, err := diffSideForCommit(repo, ref, diffKindCheckpoint, plumbing.NewHash(commits[0].SHA))
+		if err != nil {
+			return nil, err
+		}
+		side.CheckpointID = matches[0]
+		return side, nil
+	}
+
+	// Temporary checkpoint by shadow commit SHA prefix
+	tempCheckpoints, err := store.ListAllTemporaryCheckpoints(ctx, "", branchCheckpointsLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list temporary checkpoints: %w", err)
+	}
+	var tempMatches []checkpoint.TemporaryCheckpointInfo
+	for _, tc := range tempCheckpoints {
+		if strings.HasPrefix(tc.CommitHash.String(), ref) {
+			tempMatches = append(tempMatches, tc)
+		}
+	}
+	if len(tempMatches) > 1 {
+		return nil, fmt.Errorf("ambiguous checkpoint prefix %q matches %d temporary checkpoints", ref, len(tempMatches))
+	}
+	if len(tempMatches) == 1 {
+		return diffSideForCommit(repo, ref, diffKindTemporary, tempMatches[0].CommitHash)
+	}
+
+	// Any commit
+	hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint or commit not found: %s", ref)
+	}
+	return diffSideForCommit(repo, ref, diffKindCommit, *hash)
+}
+
+func diffSideForCommit(repo *git.Repository, ref, kind string, hash plumbing.Hash) (*diffSide, error) {
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", hash.String()[:7], err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree of commit %s: %w", hash.String()[:7], err)
+	}
+	return &diffSide{Ref: ref, Kind: kind, Commit: hash.String(), tree: tree}, nil
+}
+
+// resolveWorktreeDiffSide returns the current state of the working tree,
+// including uncommitted and untracked files but not ignored ones.
+func resolveWorktreeDiffSide(ctx context.Context, repo *git.Repository, store *checkpoint.GitStore) (*diffSide, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	// Built in memory so diffing doesn't write objects to the repository
+	tree, err := store.ReadWorktreeTree(ctx, head.Hash().String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the working tree: %w", err)
+	}
+	return &diffSide{Ref: "worktree", Kind: diffKindWorktree, tree: tree}, nil
+}
+
+// diffCheckpointTrees returns the changes between two trees, leaving out
+// infrastructure paths such as the session metadata on shadow branches.
+func diffCheckpointTrees(ctx context.Context, from, to *object.Tree) (object.Changes, error) {
+	changes, err := object.DiffTreeWithOptions(ctx, from, to, object.DefaultDiffTreeOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff trees: %w", err)
+	}
+	filtered := make(object.Changes, 0, len(changes))
+	for _, change := range changes {
+		if !paths.IsInfrastructurePath(diffChangePath(change)) {
+			filtered = append(filtered, change)
+		}
+	}
+	return filtered, nil
+}
+
+func diffChangePath(change *object.Change) string {
+	if change.To.Name != "" {
+		return change.To.Name
+	}
+	return change.From.Name
+}
+
+func diffChangeStatus(change *object.Change) string {
+	action, err := change.Action()
+	if err != nil {
+		return "modified"
+	}
+	switch action {
+	case merkletrie.Insert:
+		return "added"
+	case merkletrie.Delete:
+		return "deleted"
+	default:
+		if change.From.Name != change.To.Name {
+			return "renamed"
+		}
+		return "modified"
+	}
+}
+
+// diffFiles lists the changed files with their line counts. Binary files
+// have no line counts.
+func diffFiles(changes object.Changes, patch *object.Patch) []diffFile {
+	stats := make(map[string]object.FileStat)
+	for _, stat := range patch.Stats() {
+		stats[stat.Name] = stat
+	}
+
+	files := make([]diffFile, 0, len(changes))
+	for _, change := range changes {
+		file := diffFile{Path: diffChangePath(change), Status: diffChangeStatus(change)}
+		statName := file.Path
+		if file.Status == "renamed" {
+			statName = change.From.Name + " => " + change.To.Name
+		}
+		if stat, ok := stats[statName]; ok {
+			file.Additions = stat.Addition
+			file.Deletions = stat.Deletion
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// formatDiffStat formats the changes like git diff --stat.
+func formatDiffStat(changes object.Changes, patch *object.Patch) string {
+	if len(changes) == 0 {
+		return ""
+	}
+	var additions, deletions int
+	for _, file := range diffFiles(changes, patch) {
+		additions += file.Additions
+		deletions += file.Deletions
+	}
+
+	var sb strings.Builder
+	sb.WriteString(patch.Stats().String())
+	fmt.Fprintf(&sb, " %d file(s) changed, %d insertion(s)(+), %d deletion(s)(-)\n", len(changes), additions, deletions)
+	return sb.String()
+}
@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// diffTestRepo holds the commits and checkpoints created by setupDiffTestRepo.
+type diffTestRepo struct {
+	dir          string
+	first        plumbing.Hash // a.txt "one"
+	shadow       plumbing.Hash // temporary checkpoint on first: a.txt "two", plus session metadata
+	checkpointID id.CheckpointID
+	second       plumbing.Hash // a.txt "three" and b.txt, referencing checkpointID
+}
+
+func setupDiffTestRepo(t *testing.T) *diffTestRepo {
+	t.Helper()
+	tr := &diffTestRepo{dir: t.TempDir(), checkpointID: id.MustCheckpointID("d1f2d1f2d1f2")}
+	t.Chdir(tr.dir)
+	paths.ClearRepoRootCache()
+
+	repo, err := git.PlainInit(tr.dir, false)
+	require.NoError(t, err)
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	commit := func(message string, files map[string]string) plumbing.Hash {
+		for name, content := range files {
+			require.NoError(t, os.WriteFile(filepath.Join(tr.dir, name), []byte(content), 0o644))
+			_, err := wt.Add(name)
+			require.NoError(t, err)
+		}
+		hash, err := wt.Commit(message, &git.CommitOptions{
+			Author: &object.Signature{Name: "Test", Email: "test@test.com", When: time.Now()},
+		})
+		require.NoError(t, err)
+		return hash
+	}
+
+	tr.first = commit("first", map[string]string{"a.txt": "one\n"})
+
+	// Temporary checkpoint: the code plus the session's metadata
+	entries := make(map[string]object.TreeEntry)
+	for name, content := range map[string]string{
+		"a.txt":                                 "two\n",
+		".entire/metadata/session-1/full.jsonl": `{"type":"user"}` + "\n",
+	} {
+		blob, err := checkpoint.CreateBlobFromContent(repo, []byte(content))
+		require.NoError(t, err)
+		entries[name] = object.TreeEntry{Name: name, Mode: filemode.Regular, Hash: blob}
+	}
+	treeHash, err := checkpoint.BuildTreeFromEntries(repo, entries)
+	require.NoError(t, err)
+	shadowCommit := &object.Commit{
+		Author:       object.Signature{Name: "Test", Email: "test@test.com", When: time.Now()},
+		Committer:    object.Signature{Name: "Test", Email: "test@test.com", When: time.Now()},
+		Message:      trailers.FormatShadowCommit("Checkpoint", ".entire/metadata/session-1", "session-1"),
+		TreeHash:     treeHash,
+		ParentHashes: []plumbing.Hash{tr.first},
+	}
+	obj := repo.Storer.NewEncodedObject()
+	require.NoError(t, shadowCommit.Encode(obj))
+	tr.shadow, err = repo.Storer.SetEncodedObject(obj)
+	require.NoError(t, err)
+	shadowRef := plumbing.NewBranchReferenceName(checkpoint.ShadowBranchNameForCommit(tr.first.String(), ""))
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(shadowRef, tr.shadow)))
+
+	require.NoError(t, checkpoint.NewGitStore(repo).WriteCommitted(context.Background(), checkpoint.WriteCommittedOptions{
+		CheckpointID:     tr.checkpointID,
+		SessionID:        "session-1",
+		Strategy:         "manual-commit",
+		Transcript:       []byte(`{"type":"user"}` + "\n"),
+		CheckpointsCount: 1,
+		AuthorName:       "Test",
+		AuthorEmail:      "test@test.com",
+	}))
+	tr.second = commit(trailers.FormatCheckpoint("second", tr.checkpointID), map[string]string{
+		"a.txt": "three\n",
+		"b.txt": "new\n",
+	})
+	return tr
+}
+
+func TestRunDiff_TemporaryToCommittedCheckpoint(t *testing.T) {
+	tr := setupDiffTestRepo(t)
+
+	var out bytes.Buffer
+	require.NoError(t, runDiff(context.Background(), &out, tr.shadow.String()[:7], tr.checkpointID.String(), false, diffModePatch, true))
+
+	patch := out.String()
+	assert.Contains(t, patch, "diff --git a/a.txt b/a.txt")
+	assert.Contains(t, patch, "-two")
+	assert.Contains(t, patch, "+three")
+	assert.Contains(t, patch, "+++ b/b.txt")
+	assert.NotContains(t, patch, paths.EntireDir, "session metadata should not be diffed")
+}
+
+func TestRunDiff_NameOnlyDefaultsToHead(t *testing.T) {
+	tr := setupDiffTestRepo(t)
+
+	var out bytes.Buffer
+	require.NoError(t, runDiff(context.Background(), &out, tr.first.String(), "", false, diffModeNameOnly, true))
+
+	assert.Equal(t, "a.txt\nb.txt\n", out.String())
+}
+
+func TestRunDiff_JSONWithWorktree(t *testing.T) {
+	tr := setupDiffTestRepo(t)
+	require.NoError(t, os.WriteFile(filepath.Join(tr.dir, "a.txt"), []byte("three\nfour\n"), 0o644))
+	require.NoError(t, os.Remove(filepath.Join(tr.dir, "b.txt")))
+	require.NoError(t, os.WriteFile(filepath.Join(tr.dir, "c.txt"), []byte("untracked\n"), 0o644))
+
+	var out bytes.Buffer
+	require.NoError(t, runDiff(context.Background(), &out, tr.checkpointID.String()[:6], "", true, diffModeJSON, true))
+
+	var report diffReport
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	assert.Equal(t, diffKindCheckpoint, report.From.Kind)
+	assert.Equal(t, tr.second.String(), report.From.Commit)
+	assert.Equal(t, diffKindWorktree, report.To.Kind)
+	assert.Equal(t, []diffFile{
+		{Path: "a.txt", Status: "modified", Additions: 1},
+		{Path: "b.txt", Status: "deleted", Deletions: 1},
+		{Path: "c.txt", Status: "added", Additions: 1},
+	}, report.Files)
+
+	// Diffing the worktree doesn't write its blobs to the repository
+	repo, err := git.PlainOpen(tr.dir)
+	require.NoError(t, err)
+	for _, content := range []string{"three\nfour\n", "untracked\n"} {
+		err := repo.Storer.HasEncodedObject(plumbing.ComputeHash(plumbing.BlobObject, []byte(content)))
+		assert.ErrorIs(t, err, plumbing.ErrObjectNotFound, "blob %q was written", content)
+	}
+}
+
+func TestRunDiff_Stat(t *testing.T) {
+	tr := setupDiffTestRepo(t)
+
+	var out bytes.Buffer
+	require.NoError(t, runDiff(context.Background(), &out, tr.first.String(), tr.second.String(), false, diffModeStat, true))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[0], "a.txt")
+	assert.Contains(t, lines[1], "b.txt")
+	assert.Equal(t, "2 file(s) changed, 2 insertion(s)(+), 1 deletion(s)(-)", strings.TrimSpace(lines[2]))
+}
+
+func TestRunDiff_NotFound(t *testing.T) {
+	setupDiffTestRepo(t)
+
+	err := runDiff(context.Background(), &bytes.Buffer{}, "nonexistent", "", false, diffModePatch, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "checkpoint or commit not found")
+}
+
+func TestDiffCmd_WorktreeWithSecondCheckpoint(t *testing.T) {
+	setupDiffTestRepo(t)
+
+	cmd := newDiffCmd()
+	cmd.SetArgs([]string{"abc", "def", "--worktree"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--worktree")
+}
@@ -16,10 +16,9 @@ import (
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
 	"github.com/entireio/cli/cmd/entire/cli/logging"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
 	"github.com/entireio/cli/cmd/entire/cli/strategy"
 	"github.com/entireio/cli/cmd/entire/cli/summarize"
-	"github.com/entireio/cli/cmd/entire/cli/trailers"
-	"github.com/entireio/cli/cmd/entire/cli/transcript"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
@@ -286,11 +285,14 @@ func runExplainCheckpoint(w, errW io.Writer, checkpointIDPrefix string, noPager,
 	// Look up the author for this checkpoint (best-effort, ignore errors)
 	author, _ := store.GetCheckpointAuthor(context.Background(), fullCheckpointID) //nolint:errcheck // Author is optional
 
+	// Verify the signatures of the commits that wrote to it (best-effort, ignore errors)
+	signature, _ := store.GetCheckpointSignature(context.Background(), fullCheckpointID) //nolint:errcheck // Signature is optional
+
 	// Find associated commits (git commits with matching Entire-Checkpoint trailer)
 	associatedCommits, _ := getAssociatedCommits(repo, fullCheckpointID, searchAll) //nolint:errcheck // Best-effort
 
 	// Format and output
-	output := formatCheckpointOutput(summary, content, fullCheckpointID, associatedCommits, author, verbose, full)
+	output := formatCheckpointOutput(summary, content, fullCheckpointID, associatedCommits, author, signature, verbose, full)
 	outputExplainContent(w, output, noPager)
 	return nil
 }
@@ -310,8 +312,8 @@ func generateCheckpointSummary(w, _ io.Writer, store *checkpoint.GitStore, check
 	}
 
 	// Scope the transcript to only this checkpoint's portion
-	scopedTranscript := scopeTranscriptForCheckpoint(content.Transcript, content.Metadata.GetTranscriptStart())
-	if len(scopedTranscript) == 0 {
+	scopedEntries := scopeTranscriptForCheckpoint(parseCheckpointTranscript(content.Metadata.Agent, content.Transcript), content.Metadata.GetTranscriptStart())
+	if len(scopedEntries) == 0 {
 		return fmt.Errorf("checkpoint %s has no transcript content for this checkpoint (scoped)", checkpointID)
 	}
 
@@ -319,13 +321,17 @@ func generateCheckpointSummary(w, _ io.Writer, store *checkpoint.GitStore, check
 	ctx := context.Background()
 	logging.Info(ctx, "generating checkpoint summary")
 
-	summary, err := summarize.GenerateFromTranscript(ctx, scopedTranscript, cpSummary.FilesTouched, nil)
+	summary, err := summarize.GenerateFromEntries(ctx, scopedEntries, cpSummary.FilesTouched, nil)
 	if err != nil {
 		return fmt.Errorf("failed to generate summary: %w", err)
 	}
 
-	// Persist the summary
-	if err := store.UpdateSummary(ctx, checkpointID, summary); err != nil {
+	// Persist the summary, encrypted like the checkpoint if it is
+	var recipients []string
+	if entireSettings, err := settings.Load(); err == nil {
+		recipients = entireSettings.EncryptionRecipients()
+	}
+	if err := store.UpdateSummary(ctx, checkpointID, summary, recipients); err != nil {
 		return fmt.Errorf("failed to save summary: %w", err)
 	}
 
@@ -424,29 +430,33 @@ func explainTemporaryCheckpoint(w io.Writer, repo *git.Repository, store *checkp
 
 	// Transcript section: full shows entire session, verbose shows checkpoint scope
 	// For temporary checkpoints, load transcript and compute scope from parent commit
-	var fullTranscript []byte
-	var scopedTranscript []byte
+	var fullEntries []agent.SessionEntry
+	var scopedEntries []agent.SessionEntry
 	if full || verbose {
-		fullTranscript, _ = store.GetTranscriptFromCommit(tc.CommitHash, tc.MetadataDir, agent.AgentTypeUnknown) //nolint:errcheck // Best-effort
+		// The session state records the agent while the session is active
+		agentType := agent.AgentTypeUnknown
+		if state, stateErr := strategy.LoadSessionState(tc.SessionID); stateErr == nil && state != nil {
+			agentType = state.AgentType
+		}
+		fullTranscript, _ := store.GetTranscriptFromCommit(tc.CommitHash, tc.MetadataDir, agentType) //nolint:errcheck // Best-effort
+		fullEntries = parseCheckpointTranscript(agentType, fullTranscript)
 
-		if verbose && len(fullTranscript) > 0 {
+		if verbose && len(fullEntries) > 0 {
 			// Compute scoped transcript by finding where parent's transcript ended
 			// Each shadow branch commit has the full transcript up to that point,
 			// so we diff against parent to get just this checkpoint's activity
-			scopedTranscript = fullTranscript // Default to full if no parent
+			scopedEntries = fullEntries // Default to full if no parent
 			if shadowCommit.NumParents() > 0 {
 				if parent, parentErr := shadowCommit.Parent(0); parentErr == nil {
-					parentTranscript, _ := store.GetTranscriptFromCommit(parent.Hash, tc.MetadataDir, agent.AgentTypeUnknown) //nolint:errcheck // Best-effort
-					if len(parentTranscript) > 0 {
-						// Count lines in parent transcript to know where to slice from
-						parentLineCount := countLines(parentTranscript)
-						scopedTranscript = transcript.SliceFromLine(fullTranscript, parentLineCount)
+					parentTranscript, _ := store.GetTranscriptFromCommit(parent.Hash, tc.MetadataDir, agentType) //nolint:errcheck // Best-effort
+					if parentEntries := parseCheckpointTranscript(agentType, parentTranscript); len(parentEntries) > 0 {
+						scopedEntries = scopeTranscriptForCheckpoint(fullEntries, agent.TranscriptLength(parentEntries))
 					}
 				}
 			}
 		}
 	}
-	appendTranscriptSection(&sb, verbose, full, fullTranscript, scopedTranscript, sessionPrompt)
+	appendTranscriptSection(&sb, verbose, full, fullEntries, scopedEntries, sessionPrompt)
 
 	return sb.String(), true
 }
@@ -463,6 +473,7 @@ func getAssociatedCommits(repo *git.Repository, checkpointID id.CheckpointID, se
 
 	commits := []associatedCommit{} // Initialize as empty slice, not nil (nil means "not searched")
 	targetID := checkpointID.String()
+	store := checkpoint.NewGitStore(repo)
 
 	collectCommit := func(c *object.Commit) {
 		fullSHA := c.Hash.String()
@@ -492,7 +503,7 @@ func getAssociatedCommits(repo *git.Repository, checkpointID id.CheckpointID, se
 		defer iter.Close()
 
 		err = iter.ForEach(func(c *object.Commit) error {
-			cpID, found := trailers.ParseCheckpoint(c.Message)
+			cpID, found := store.CheckpointIDForCommit(context.Background(), c)
 			if found && cpID.String() == targetID {
 				collectCommit(c)
 			}
@@ -510,7 +521,7 @@ func getAssociatedCommits(repo *git.Repository, checkpointID id.CheckpointID, se
 				return errStopIteration
 			}
 
-			cpID, found := trailers.ParseCheckpoint(c.Message)
+			cpID, found := store.CheckpointIDForCommit(context.Background(), c)
 			if found && cpID.String() == targetID {
 				collectCommit(c)
 			}
@@ -525,24 +536,27 @@ func getAssociatedCommits(repo *git.Repository, checkpointID id.CheckpointID, se
 	return commits, nil
 }
 
-// scopeTranscriptForCheckpoint slices a transcript to include only the lines
-// relevant to a specific checkpoint, starting from linesAtStart.
+// parseCheckpointTranscript normalizes a checkpoint's transcript into session entries.
+// Transcripts that can't be parsed yield no entries.
+func parseCheckpointTranscript(agentType agent.AgentType, transcriptBytes []byte) []agent.SessionEntry {
+	entries, err := agent.ParseTranscriptEntries(agentType, transcriptBytes)
+	if err != nil {
+		return nil
+	}
+	return entries
+}
+
+// scopeTranscriptForCheckpoint slices transcript entries to include only those
+// relevant to a specific checkpoint, starting from transcript position positionAtStart.
 // This allows showing only what happened during a checkpoint, not the entire session.
-func scopeTranscriptForCheckpoint(fullTranscript []byte, linesAtStart int) []byte {
-	return transcript.SliceFromLine(fullTranscript, linesAtStart)
+func scopeTranscriptForCheckpoint(entries []agent.SessionEntry, positionAtStart int) []agent.SessionEntry {
+	return agent.EntriesFrom(entries, positionAtStart)
 }
 
-// extractPromptsFromTranscript extracts user prompts from transcript bytes.
+// extractPromptsFromTranscript extracts user prompts from transcript entries.
 // Returns a slice of prompt strings.
-func extractPromptsFromTranscript(transcriptBytes []byte) []string {
-	if len(transcriptBytes) == 0 {
-		return nil
-	}
-
-	condensed, err := summarize.BuildCondensedTranscriptFromBytes(transcriptBytes)
-	if err != nil {
-		return nil
-	}
+func extractPromptsFromTranscript(entries []agent.SessionEntry) []string {
+	condensed := summarize.BuildCondensedTranscript(entries)
 
 	var prompts []string
 	for _, entry := range condensed {
@@ -562,18 +576,20 @@ func extractPromptsFromTranscript(transcriptBytes []byte) []string {
 // where this checkpoint's content begins in the full session transcript.
 //
 // Author is displayed when available (only for committed checkpoints).
+// The least trusted signature of the metadata commits that wrote to the checkpoint is displayed when verified.
 // Associated commits are git commits that reference this checkpoint via Entire-Checkpoint trailer.
-func formatCheckpointOutput(summary *checkpoint.CheckpointSummary, content *checkpoint.SessionContent, checkpointID id.CheckpointID, associatedCommits []associatedCommit, author checkpoint.Author, verbose, full bool) string {
+func formatCheckpointOutput(summary *checkpoint.CheckpointSummary, content *checkpoint.SessionContent, checkpointID id.CheckpointID, associatedCommits []associatedCommit, author checkpoint.Author, signature *checkpoint.CommitSignature, verbose, full bool) string {
 	var sb strings.Builder
 	meta := content.Metadata
 
 	// Scope the transcript to this checkpoint's portion
 	// If CheckpointTranscriptStart > 0, we slice the transcript to only include
-	// lines from that point onwards (excluding earlier checkpoint content)
-	scopedTranscript := scopeTranscriptForCheckpoint(content.Transcript, meta.GetTranscriptStart())
+	// entries from that position onwards (excluding earlier checkpoint content)
+	fullEntries := parseCheckpointTranscript(meta.Agent, content.Transcript)
+	scopedEntries := scopeTranscriptForCheckpoint(fullEntries, meta.GetTranscriptStart())
 
 	// Extract prompts from the scoped transcript for intent extraction
-	scopedPrompts := extractPromptsFromTranscript(scopedTranscript)
+	scopedPrompts := extractPromptsFromTranscript(scopedEntries)
 
 	// Header - always shown
 	// Note: CheckpointID is always exactly 12 characters, matching checkpointIDDisplayLength
@@ -586,6 +602,11 @@ func formatCheckpointOutput(summary *checkpoint.CheckpointSummary, content *chec
 		fmt.Fprintf(&sb, "Author: %s <%s>\n", author.Name, author.Email)
 	}
 
+	// Least trusted signature of the metadata commits that wrote to the checkpoint, when verified
+	if signature != nil {
+		fmt.Fprintf(&sb, "Signature: %s\n", signature)
+	}
+
 	// Token usage - prefer content metadata, fall back to summary
 	tokenUsage := meta.TokenUsage
 	if tokenUsage == nil && summary != nil {
@@ -653,48 +674,41 @@ func formatCheckpointOutput(summary *checkpoint.CheckpointSummary, content *chec
 	}
 
 	// Transcript section: full shows entire session, verbose shows checkpoint scope
-	appendTranscriptSection(&sb, verbose, full, content.Transcript, scopedTranscript, content.Prompts)
+	appendTranscriptSection(&sb, verbose, full, fullEntries, scopedEntries, content.Prompts)
 
 	return sb.String()
 }
 
 // appendTranscriptSection appends the appropriate transcript section to the builder
 // based on verbosity level. Full mode shows the entire session, verbose shows checkpoint scope.
-// fullTranscript is the entire session transcript, scopedContent is either scoped transcript bytes
-// or a pre-formatted string (for backwards compat), and scopedFallback is used when scoped parsing fails.
-func appendTranscriptSection(sb *strings.Builder, verbose, full bool, fullTranscript, scopedTranscript []byte, scopedFallback string) {
+// fullEntries is the entire session transcript, scopedEntries is this checkpoint's portion,
+// and scopedFallback (pre-formatted prompts, for backwards compat) is used when it has no content.
+func appendTranscriptSection(sb *strings.Builder, verbose, full bool, fullEntries, scopedEntries []agent.SessionEntry, scopedFallback string) {
 	switch {
 	case full:
 		sb.WriteString("\n")
 		sb.WriteString("Transcript (full session):\n")
-		sb.WriteString(formatTranscriptBytes(fullTranscript, ""))
+		sb.WriteString(formatTranscriptEntries(fullEntries, ""))
 
 	case verbose:
 		sb.WriteString("\n")
 		sb.WriteString("Transcript (checkpoint scope):\n")
-		sb.WriteString(formatTranscriptBytes(scopedTranscript, scopedFallback))
+		sb.WriteString(formatTranscriptEntries(scopedEntries, scopedFallback))
 	}
 }
 
-// formatTranscriptBytes formats transcript bytes into a human-readable string.
-// It parses the JSONL transcript and formats it using the condensed format.
-// The fallback is used for backwards compatibility when transcript parsing fails or is empty.
-func formatTranscriptBytes(transcriptBytes []byte, fallback string) string {
-	if len(transcriptBytes) == 0 {
+// formatTranscriptEntries formats transcript entries into a human-readable string
+// using the condensed format.
+// The fallback is used for backwards compatibility when the transcript has no content.
+func formatTranscriptEntries(entries []agent.SessionEntry, fallback string) string {
+	condensed := summarize.BuildCondensedTranscript(entries)
+	if len(condensed) == 0 {
 		if fallback != "" {
 			return fallback + "\n"
 		}
 		return "  (none)\n"
 	}
 
-	condensed, err := summarize.BuildCondensedTranscriptFromBytes(transcriptBytes)
-	if err != nil || len(condensed) == 0 {
-		if fallback != "" {
-			return fallback + "\n"
-		}
-		return "  (failed to parse transcript)\n"
-	}
-
 	input := summarize.Input{Transcript: condensed}
 	return summarize.FormatCondensedTranscript(input)
 }
@@ -897,7 +911,7 @@ func getBranchCheckpoints(repo *git.Repository, limit int) ([]strategy.RewindPoi
 	var points []strategy.RewindPoint
 
 	collectCheckpoint := func(c *object.Commit) {
-		cpID, found := trailers.ParseCheckpoint(c.Message)
+		cpID, found := store.CheckpointIDForCommit(context.Background(), c)
 		if !found {
 			return
 		}
@@ -921,8 +935,8 @@ func getBranchCheckpoints(repo *git.Repository, limit int) ([]strategy.RewindPoi
 		// Read session prompt from metadata branch (best-effort)
 		content, _ := store.ReadLatestSessionContent(context.Background(), cpID) //nolint:errcheck  // Best-effort
 		if content != nil {
-			scopedTranscript := scopeTranscriptForCheckpoint(content.Transcript, content.Metadata.GetTranscriptStart())
-			scopedPrompts := extractPromptsFromTranscript(scopedTranscript)
+			scopedEntries := scopeTranscriptForCheckpoint(parseCheckpointTranscript(content.Metadata.Agent, content.Transcript), content.Metadata.GetTranscriptStart())
+			scopedPrompts := extractPromptsFromTranscript(scopedEntries)
 			if len(scopedPrompts) > 0 && scopedPrompts[0] != "" {
 				point.SessionPrompt = scopedPrompts[0]
 			}
@@ -1140,7 +1154,8 @@ func outputExplainContent(w io.Writer, content string, noPager bool) {
 }
 
 // runExplainCommit looks up the checkpoint associated with a commit.
-// Extracts the Entire-Checkpoint trailer and delegates to checkpoint detail view.
+// Uses the Entire-Checkpoint trailer (or the link recorded when the commit was
+// rewritten by amend/rebase) and delegates to checkpoint detail view.
 // If no trailer found, shows a message indicating no associated checkpoint.
 func runExplainCommit(w io.Writer, commitRef string, noPager, verbose, full, searchAll bool) error {
 	repo, err := openRepository()
@@ -1159,8 +1174,8 @@ func runExplainCommit(w io.Writer, commitRef string, noPager, verbose, full, sea
 		return fmt.Errorf("failed to get commit: %w", err)
 	}
 
-	// Extract Entire-Checkpoint trailer
-	checkpointID, hasCheckpoint := trailers.ParseCheckpoint(commit.Message)
+	// Extract Entire-Checkpoint trailer, falling back to rewritten-commit links
+	checkpointID, hasCheckpoint := checkpoint.NewGitStore(repo).CheckpointIDForCommit(context.Background(), commit)
 	if !hasCheckpoint {
 		fmt.Fprintln(w, "No associated Entire checkpoint")
 		fmt.Fprintf(w, "\nCommit %s does not have an Entire-Checkpoint trailer.\n", hash.String()[:7])
@@ -1500,22 +1515,6 @@ func formatCheckpointGroup(sb *strings.Builder, group checkpointGroup) {
 	}
 }
 
-// countLines counts the number of lines in a byte slice.
-// For JSONL content (where each line ends with \n), this returns the line count.
-// Empty content returns 0.
-func countLines(content []byte) int {
-	if len(content) == 0 {
-		return 0
-	}
-	count := 0
-	for _, b := range content {
-		if b == '\n' {
-			count++
-		}
-	}
-	return count
-}
-
 // hasCodeChanges returns true if the commit has changes to non-metadata files.
 // Used by getBranchCheckpoints to filter out metadata-only temporary checkpoints.
 // Returns false only if the commit has a parent AND only modified .entire/ metadata files.
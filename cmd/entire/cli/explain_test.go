@@ -893,7 +893,7 @@ func TestFormatCheckpointOutput_Short(t *testing.T) {
 	}
 
 	// Default mode: empty commit message (not shown anyway in default mode)
-	output := formatCheckpointOutput(summary, content, id.MustCheckpointID("abc123def456"), nil, checkpoint.Author{}, false, false)
+	output := formatCheckpointOutput(summary, content, id.MustCheckpointID("abc123def456"), nil, checkpoint.Author{}, nil, false, false)
 
 	// Should show checkpoint ID
 	if !strings.Contains(output, "abc123def456") {
@@ -956,7 +956,7 @@ func TestFormatCheckpointOutput_Verbose(t *testing.T) {
 		Transcript: transcriptContent,
 	}
 
-	output := formatCheckpointOutput(summary, content, id.MustCheckpointID("abc123def456"), nil, checkpoint.Author{}, true, false)
+	output := formatCheckpointOutput(summary, content, id.MustCheckpointID("abc123def456"), nil, checkpoint.Author{}, nil, true, false)
 
 	// Should show checkpoint ID (like default)
 	if !strings.Contains(output, "abc123def456") {
@@ -1007,7 +1007,7 @@ func TestFormatCheckpointOutput_Verbose_NoCommitMessage(t *testing.T) {
 	}
 
 	// When commit message is empty, should not show Commit section
-	output := formatCheckpointOutput(summary, content, id.MustCheckpointID("abc123def456"), nil, checkpoint.Author{}, true, false)
+	output := formatCheckpointOutput(summary, content, id.MustCheckpointID("abc123def456"), nil, checkpoint.Author{}, nil, true, false)
 
 	if strings.Contains(output, "Commits:") {
 		t.Error("verbose output should not show Commits section when nil (not searched)")
@@ -1044,7 +1044,7 @@ func TestFormatCheckpointOutput_Full(t *testing.T) {
 		Transcript: []byte(transcriptData),
 	}
 
-	output := formatCheckpointOutput(summary, content, id.MustCheckpointID("abc123def456"), nil, checkpoint.Author{}, false, true)
+	output := formatCheckpointOutput(summary, content, id.MustCheckpointID("abc123def456"), nil, checkpoint.Author{}, nil, false, true)
 
 	// Should show checkpoint ID (like default)
 	if !strings.Contains(output, "abc123def456") {
@@ -1095,7 +1095,7 @@ func TestFormatCheckpointOutput_WithSummary(t *testing.T) {
 	}
 
 	// Test default output (non-verbose) with summary
-	output := formatCheckpointOutput(summary, content, cpID, nil, checkpoint.Author{}, false, false)
+	output := formatCheckpointOutput(summary, content, cpID, nil, checkpoint.Author{}, nil, false, false)
 
 	// Should show AI-generated intent and outcome
 	if !strings.Contains(output, "Intent: Implement user authentication") {
@@ -1110,7 +1110,7 @@ func TestFormatCheckpointOutput_WithSummary(t *testing.T) {
 	}
 
 	// Test verbose output with summary
-	verboseOutput := formatCheckpointOutput(summary, content, cpID, nil, checkpoint.Author{}, true, false)
+	verboseOutput := formatCheckpointOutput(summary, content, cpID, nil, checkpoint.Author{}, nil, true, false)
 
 	// Verbose should show learnings sections
 	if !strings.Contains(verboseOutput, "Learnings:") {
@@ -2138,13 +2138,7 @@ func TestScopeTranscriptForCheckpoint_SlicesTranscript(t *testing.T) {
 
 	// Checkpoint starts at line 2 (after prompt 1 and response 1)
 	// Should only include lines 2-4 (prompt 2, response 2, prompt 3)
-	scoped := scopeTranscriptForCheckpoint(fullTranscript, 2)
-
-	// Parse the scoped transcript to verify content
-	lines, err := parseTranscriptFromBytes(scoped)
-	if err != nil {
-		t.Fatalf("failed to parse scoped transcript: %v", err)
-	}
+	lines := scopeTranscriptForCheckpoint(parseCheckpointTranscript(agent.AgentTypeClaudeCode, fullTranscript), 2)
 
 	if len(lines) != 3 {
 		t.Fatalf("expected 3 lines in scoped transcript, got %d", len(lines))
@@ -2167,12 +2161,7 @@ func TestScopeTranscriptForCheckpoint_ZeroLinesReturnsAll(t *testing.T) {
 `)
 
 	// With linesAtStart=0, should return full transcript
-	scoped := scopeTranscriptForCheckpoint(transcript, 0)
-
-	lines, err := parseTranscriptFromBytes(scoped)
-	if err != nil {
-		t.Fatalf("failed to parse scoped transcript: %v", err)
-	}
+	lines := scopeTranscriptForCheckpoint(parseCheckpointTranscript(agent.AgentTypeClaudeCode, transcript), 0)
 
 	if len(lines) != 2 {
 		t.Fatalf("expected 2 lines with linesAtStart=0, got %d", len(lines))
@@ -2187,7 +2176,7 @@ func TestExtractPromptsFromScopedTranscript(t *testing.T) {
 {"type":"assistant","uuid":"a2","message":{"content":[{"type":"text","text":"Second response"}]}}
 `)
 
-	prompts := extractPromptsFromTranscript(transcript)
+	prompts := extractPromptsFromTranscript(parseCheckpointTranscript(agent.AgentTypeClaudeCode, transcript))
 
 	if len(prompts) != 2 {
 		t.Fatalf("expected 2 prompts, got %d", len(prompts))
@@ -2228,7 +2217,7 @@ func TestFormatCheckpointOutput_UsesScopedPrompts(t *testing.T) {
 	}
 
 	// Verbose output should use scoped prompts
-	output := formatCheckpointOutput(summary, content, id.MustCheckpointID("abc123def456"), nil, checkpoint.Author{}, true, false)
+	output := formatCheckpointOutput(summary, content, id.MustCheckpointID("abc123def456"), nil, checkpoint.Author{}, nil, true, false)
 
 	// Should show ONLY the second prompt (scoped)
 	if !strings.Contains(output, "Second prompt - SHOULD appear") {
@@ -2260,7 +2249,7 @@ func TestFormatCheckpointOutput_FallsBackToStoredPrompts(t *testing.T) {
 	}
 
 	// Verbose output should fall back to stored prompts
-	output := formatCheckpointOutput(summary, content, id.MustCheckpointID("abc123def456"), nil, checkpoint.Author{}, true, false)
+	output := formatCheckpointOutput(summary, content, id.MustCheckpointID("abc123def456"), nil, checkpoint.Author{}, nil, true, false)
 
 	// Intent should use stored prompt
 	if !strings.Contains(output, "Stored prompt from older checkpoint") {
@@ -2292,7 +2281,7 @@ func TestFormatCheckpointOutput_FullShowsEntireTranscript(t *testing.T) {
 	}
 
 	// Full mode should show the ENTIRE transcript (not scoped)
-	output := formatCheckpointOutput(summary, content, id.MustCheckpointID("abc123def456"), nil, checkpoint.Author{}, false, true)
+	output := formatCheckpointOutput(summary, content, id.MustCheckpointID("abc123def456"), nil, checkpoint.Author{}, nil, false, true)
 
 	// Should show the full transcript including first prompt (even though scoped prompts exclude it)
 	if !strings.Contains(output, "First prompt") {
@@ -2552,11 +2541,49 @@ func TestFormatCheckpointOutput_WithAuthor(t *testing.T) {
 	}
 
 	// With author, should show author line
-	output := formatCheckpointOutput(summary, content, id.MustCheckpointID("abc123def456"), nil, author, true, false)
+	output := formatCheckpointOutput(summary, content, id.MustCheckpointID("abc123def456"), nil, author, nil, true, false)
 
 	if !strings.Contains(output, "Author: Alice Developer <alice@example.com>") {
 		t.Errorf("expected author line in output, got:\n%s", output)
 	}
+	if strings.Contains(output, "Signature:") {
+		t.Errorf("expected no signature line without a verified signature, got:\n%s", output)
+	}
+}
+
+func TestFormatCheckpointOutput_WithSignature(t *testing.T) {
+	summary := &checkpoint.CheckpointSummary{
+		CheckpointID: id.MustCheckpointID("abc123def456"),
+		FilesTouched: []string{"main.go"},
+	}
+	content := &checkpoint.SessionContent{
+		Metadata: checkpoint.CommittedMetadata{
+			CheckpointID: "abc123def456",
+			SessionID:    "2026-01-30-test-session",
+			CreatedAt:    time.Date(2026, 1, 30, 10, 30, 0, 0, time.UTC),
+			FilesTouched: []string{"main.go"},
+		},
+		Prompts: "Add a new feature",
+	}
+	author := checkpoint.Author{Name: "Alice Developer", Email: "alice@example.com"}
+	signature := &checkpoint.CommitSignature{
+		Status: checkpoint.SignatureGood,
+		Signer: "alice@example.com",
+		Key:    "SHA256:abc",
+	}
+
+	output := formatCheckpointOutput(summary, content, id.MustCheckpointID("abc123def456"), nil, author, signature, false, false)
+
+	if !strings.Contains(output, "Signature: good signature from alice@example.com (key SHA256:abc)") {
+		t.Errorf("expected signature line in output, got:\n%s", output)
+	}
+
+	signature = &checkpoint.CommitSignature{Status: checkpoint.SignatureNone}
+	output = formatCheckpointOutput(summary, content, id.MustCheckpointID("abc123def456"), nil, author, signature, false, false)
+
+	if !strings.Contains(output, "Signature: not signed") {
+		t.Errorf("expected unsigned signature line in output, got:\n%s", output)
+	}
 }
 
 func TestFormatCheckpointOutput_EmptyAuthor(t *testing.T) {
@@ -2580,7 +2607,7 @@ func TestFormatCheckpointOutput_EmptyAuthor(t *testing.T) {
 	// Empty author - should not show author line
 	author := checkpoint.Author{}
 
-	output := formatCheckpointOutput(summary, content, id.MustCheckpointID("abc123def456"), nil, author, true, false)
+	output := formatCheckpointOutput(summary, content, id.MustCheckpointID("abc123def456"), nil, author, nil, true, false)
 
 	if strings.Contains(output, "Author:") {
 		t.Errorf("expected no author line for empty author, got:\n%s", output)
@@ -2857,7 +2884,7 @@ func TestFormatCheckpointOutput_WithAssociatedCommits(t *testing.T) {
 		},
 	}
 
-	output := formatCheckpointOutput(summary, content, id.MustCheckpointID("abc123def456"), associatedCommits, checkpoint.Author{}, true, false)
+	output := formatCheckpointOutput(summary, content, id.MustCheckpointID("abc123def456"), associatedCommits, checkpoint.Author{}, nil, true, false)
 
 	// Should show commits section with count
 	if !strings.Contains(output, "Commits: (2)") {
@@ -3316,7 +3343,7 @@ func TestFormatCheckpointOutput_NoCommitsOnBranch(t *testing.T) {
 	// No associated commits - use empty slice (not nil) to indicate "searched but found none"
 	associatedCommits := []associatedCommit{}
 
-	output := formatCheckpointOutput(summary, content, id.MustCheckpointID("abc123def456"), associatedCommits, checkpoint.Author{}, true, false)
+	output := formatCheckpointOutput(summary, content, id.MustCheckpointID("abc123def456"), associatedCommits, checkpoint.Author{}, nil, true, false)
 
 	// Should show message indicating no commits found
 	if !strings.Contains(output, "Commits: No commits found on this branch") {
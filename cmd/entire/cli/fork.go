@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+func newForkCmd() *cobra.Command {
+	var worktreeFlag string
+
+	cmd := &cobra.Command{
+		Use:   "fork <checkpoint>",
+		Short: "Fork a new session from a checkpoint",
+		Long: `Start a new session from a checkpoint of another session, in a new git
+worktree, leaving the original session and the current working copy untouched.
+
+The checkpoint can be a temporary checkpoint (shadow branch commit SHA or
+prefix, as listed by 'entire rewind --list'), a committed checkpoint ID, or a
+commit with a checkpoint. The worktree is created with the checkpoint's code,
+as 'entire checkout' would, and the new session gets a new ID and a copy of
+the transcript as it was at the checkpoint. Task checkpoints are cut at the
+task.
+
+The new session has its own shadow branch, starting at the fork point, and
+records the session and checkpoint it was forked from in its metadata.
+
+The worktree is created next to the repository unless --worktree is given.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if checkDisabledGuard(cmd.OutOrStdout()) {
+				return nil
+			}
+			return runFork(context.Background(), cmd.OutOrStdout(), cmd.ErrOrStderr(), args[0], worktreeFlag)
+		},
+	}
+
+	cmd.Flags().StringVar(&worktreeFlag, "worktree", "", "Directory to create the worktree in")
+
+	return cmd
+}
+
+func runFork(ctx context.Context, w, errW io.Writer, ref, dir string) error {
+	forker, ok := GetStrategy().(strategy.SessionForker)
+	if !ok {
+		return errors.New("the current strategy does not support forking sessions")
+	}
+
+	repo, err := openRepository()
+	if err != nil {
+		return fmt.Errorf("not a git repository: %w", err)
+	}
+	store := checkpoint.NewGitStore(repo)
+
+	target, err := resolveDiffSide(ctx, repo, store, ref)
+	if err != nil {
+		return err
+	}
+	transcript, err := readCheckpointTranscript(ctx, repo, store, target)
+	if err != nil {
+		return fmt.Errorf("failed to read the session transcript: %w", err)
+	}
+	if transcript == nil {
+		return fmt.Errorf("no session transcript for %s; use 'entire checkout' to check out its code", ref)
+	}
+	ag := checkpointAgent(transcript.Agent)
+	if ag == nil {
+		return errors.New("no agent available to fork the session")
+	}
+	// The fork's transcript is tracked by its session file
+	if _, ok := ag.(agent.TranscriptBuilder); !ok {
+		return fmt.Errorf("forking %s sessions is not supported: their transcripts aren't kept in session files", ag.Type())
+	}
+
+	// The checkpoint the fork starts from: its ID if it has one, else the commit
+	parentCheckpoint := target.CheckpointID.String()
+	if target.CheckpointID.IsEmpty() {
+		parentCheckpoint = target.Commit
+		if target.Kind == diffKindCommit {
+			if commit, err := repo.CommitObject(plumbing.NewHash(target.Commit)); err == nil {
+				if cpID, found := store.CheckpointIDForCommit(ctx, commit); found {
+					parentCheckpoint = cpID.String()
+				}
+			}
+		}
+	}
+
+	agentSessionID := uuid.NewString()
+	sessionID := ag.TransformSessionID(agentSessionID)
+	content := transcript.Content
+	if transcriptForker, ok := ag.(agent.TranscriptForker); ok {
+		content, err = transcriptForker.ForkTranscript(content, agentSessionID)
+		if err != nil {
+			return fmt.Errorf("failed to fork the session transcript: %w", err)
+		}
+	}
+
+	if dir == "" {
+		dir, err = defaultWorktreeDir("fork-" + agentSessionID[:8])
+		if err != nil {
+			return err
+		}
+	}
+	dir, baseCommit, err := addCheckpointWorktree(ctx, repo, store, target, dir)
+	if err != nil {
+		return err
+	}
+	// Hooks run in the worktree see its real path
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+
+	sessionFile, err := writeTranscriptForWorktree(dir, content, sessionID, ag)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(errW, "Writing transcript to: %s\n", sessionFile)
+
+	transcriptStart := 0
+	if analyzer, ok := ag.(agent.TranscriptAnalyzer); ok {
+		if pos, err := analyzer.GetTranscriptPosition(sessionFile); err == nil {
+			transcriptStart = pos
+		}
+	}
+
+	if err := forker.ForkSession(strategy.ForkSessionOptions{
+		SessionID:        sessionID,
+		AgentType:        ag.Type(),
+		WorktreePath:     dir,
+		BaseCommit:       baseCommit,
+		Tree:             target.tree.Hash,
+		Transcript:       content,
+		TranscriptPath:   sessionFile,
+		TranscriptStart:  transcriptStart,
+		ParentSessionID:  transcript.SessionID,
+		ParentCheckpoint: parentCheckpoint,
+	}); err != nil {
+		return fmt.Errorf("failed to start the forked session: %w", err)
+	}
+
+	fmt.Fprintf(w, "Forked session %s at %s into %s\n", transcript.SessionID, ref, dir)
+	fmt.Fprintf(w, "New session: %s\n", sessionID)
+	fmt.Fprintf(w, "\nTo continue the forked session, run:\n  cd %s && %s\n", dir, formatResumeCommand(sessionID, ag))
+	return nil
+}
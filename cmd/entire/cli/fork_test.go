@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var forkedSessionPattern = regexp.MustCompile(`New session: (\S+)`)
+
+func TestRunFork_TemporaryCheckpoint(t *testing.T) {
+	tr := setupDiffTestRepo(t)
+	t.Setenv("ENTIRE_TEST_CLAUDE_PROJECT_DIR", t.TempDir())
+	dir := filepath.Join(t.TempDir(), "fork")
+
+	var out bytes.Buffer
+	require.NoError(t, runFork(context.Background(), &out, &bytes.Buffer{}, tr.shadow.String()[:7], dir))
+
+	match := forkedSessionPattern.FindStringSubmatch(out.String())
+	require.NotNil(t, match, out.String())
+	sessionID := match[1]
+	assert.NotEqual(t, "session-1", sessionID)
+
+	state, err := strategy.LoadSessionState(sessionID)
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	assert.Equal(t, "session-1", state.ParentSessionID)
+	assert.Equal(t, tr.shadow.String(), state.ParentCheckpoint)
+	assert.Equal(t, tr.first.String(), state.BaseCommit)
+	assert.Equal(t, 1, state.CheckpointTranscriptStart)
+	assert.Equal(t, 0, state.StepCount)
+	assert.Equal(t, `{"type":"user"}`+"\n", readTestFile(t, state.TranscriptPath))
+
+	// The fork has its own shadow branch, starting with the fork point
+	repo, err := git.PlainOpen(tr.dir)
+	require.NoError(t, err)
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(checkpoint.ShadowBranchNameForCommit(state.BaseCommit, state.WorktreeID)), true)
+	require.NoError(t, err)
+	forkPoint, err := repo.CommitObject(ref.Hash())
+	require.NoError(t, err)
+	assert.Empty(t, forkPoint.ParentHashes)
+	forkSessionID, found := trailers.ParseSession(forkPoint.Message)
+	require.True(t, found)
+	assert.Equal(t, sessionID, forkSessionID)
+	file, err := forkPoint.File("a.txt")
+	require.NoError(t, err)
+	contents, err := file.Contents()
+	require.NoError(t, err)
+	assert.Equal(t, "two\n", contents)
+	_, err = forkPoint.File(paths.SessionMetadataDirFromSessionID(sessionID) + "/" + paths.TranscriptFileName)
+	require.NoError(t, err)
+
+	// The original session's shadow branch is untouched
+	originalRef, err := repo.Reference(plumbing.NewBranchReferenceName(checkpoint.ShadowBranchNameForCommit(tr.first.String(), "")), true)
+	require.NoError(t, err)
+	assert.Equal(t, tr.shadow, originalRef.Hash())
+}
+
+func TestRunFork_CommittedCheckpoint(t *testing.T) {
+	tr := setupDiffTestRepo(t)
+	t.Setenv("ENTIRE_TEST_CLAUDE_PROJECT_DIR", t.TempDir())
+
+	var out bytes.Buffer
+	require.NoError(t, runFork(context.Background(), &out, &bytes.Buffer{}, tr.second.String(), filepath.Join(t.TempDir(), "fork")))
+
+	match := forkedSessionPattern.FindStringSubmatch(out.String())
+	require.NotNil(t, match, out.String())
+	state, err := strategy.LoadSessionState(match[1])
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	assert.Equal(t, tr.checkpointID.String(), state.ParentCheckpoint)
+	assert.Equal(t, tr.second.String(), state.BaseCommit)
+}
+
+func TestRunFork_NoTranscript(t *testing.T) {
+	tr := setupDiffTestRepo(t)
+	dir := filepath.Join(t.TempDir(), "fork")
+
+	err := runFork(context.Background(), &bytes.Buffer{}, &bytes.Buffer{}, tr.first.String(), dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no session transcript")
+	assert.NoDirExists(t, dir)
+}
+
+func TestRunFork_AgentWithoutSessionFiles(t *testing.T) {
+	setupDiffTestRepo(t)
+	cpID := id.MustCheckpointID("a1d2a1d2a1d2")
+	writeAgentCheckpoint(t, cpID, agent.AgentTypeAider, "#### hello\n")
+	dir := filepath.Join(t.TempDir(), "fork")
+
+	err := runFork(context.Background(), &bytes.Buffer{}, &bytes.Buffer{}, cpID.String(), dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not supported")
+	assert.NoDirExists(t, dir)
+}
@@ -0,0 +1,324 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/cobra"
+)
+
+// Problem categories found outside the metadata branch. The categories of
+// problems on the metadata branch are defined by checkpoint.VerifyCommitted.
+const (
+	// fsckUnresolvedTrailer: an Entire-Checkpoint trailer or commit note has no checkpoint on the metadata branch
+	fsckUnresolvedTrailer checkpoint.FsckCategory = "unresolved_trailer"
+
+	// fsckSessionState: a session state with checkpoints points at a missing shadow branch
+	fsckSessionState checkpoint.FsckCategory = "session_state"
+
+	// fsckBadSignature: the commit that created a checkpoint has a bad or revoked signature
+	fsckBadSignature checkpoint.FsckCategory = "bad_signature"
+)
+
+// fsckReport is the result of entire fsck, also its --json output.
+type fsckReport struct {
+	Problems []checkpoint.FsckProblem `json:"problems"`
+	Repaired []id.CheckpointID        `json:"repaired,omitempty"`
+
+	// Least trusted signature of the commits that wrote to each checkpoint (--signatures only)
+	Signatures []checkpoint.CheckpointSignature `json:"signatures,omitempty"`
+}
+
+func newFsckCmd() *cobra.Command {
+	var jsonFlag bool
+	var repairFlag bool
+	var signaturesFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "fsck",
+		Short: "Verify the integrity of checkpoints and sessions",
+		Long: `Check checkpoint data for corruption and dangling references.
+
+Checks:
+  - Checkpoint summaries on entire/checkpoints/v1 list session files that exist
+    and aggregate the statistics of their sessions
+  - Chunked transcripts have all their chunk files
+  - content_hash.txt matches the session's transcript (following delta bases)
+  - Every Entire-Checkpoint trailer or commit note in the history of HEAD
+    resolves to a checkpoint on entire/checkpoints/v1
+  - Session state files with checkpoints point at an existing shadow branch
+
+With --signatures, also verifies the signatures of the entire/checkpoints/v1
+commits that wrote to each checkpoint and reports the least trusted one and
+who signed it, so an unsigned later update shows up. Signatures are
+checked by git against your GPG keyring or gpg.ssh.allowedSignersFile; bad or
+revoked signatures are problems, unsigned checkpoints are only reported.
+
+Problems are reported by category. With --repair, checkpoint summaries are
+rebuilt from their session directories: dangling session paths are dropped
+and statistics are re-aggregated. Other problems are only reported; use
+'entire doctor' for stuck sessions.
+
+Exits with an error if problems remain.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runFsck(context.Background(), cmd.OutOrStdout(), jsonFlag, repairFlag, signaturesFlag)
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonFlag, "json", false, "Output the report as JSON")
+	cmd.Flags().BoolVar(&repairFlag, "repair", false, "Apply safe fixes to checkpoint summaries")
+	cmd.Flags().BoolVar(&signaturesFlag, "signatures", false, "Verify who signed each checkpoint")
+
+	return cmd
+}
+
+func runFsck(ctx context.Context, w io.Writer, jsonOutput, repair, signatures bool) error {
+	repo, err := openRepository()
+	if err != nil {
+		return fmt.Errorf("not a git repository: %w", err)
+	}
+	store := checkpoint.NewGitStore(repo)
+
+	report, err := collectFsckReport(ctx, repo, store, repair, signatures)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		data, err := jsonutil.MarshalIndentWithNewline(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal report: %w", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+	} else {
+		printFsckReport(w, report)
+	}
+
+	if len(report.Problems) > 0 {
+		return NewSilentError(fmt.Errorf("fsck found %d problem(s)", len(report.Problems)))
+	}
+	return nil
+}
+
+// collectFsckReport runs all checks. With repair, repairable checkpoint
+// problems are fixed first and the metadata branch is verified again, so the
+// report only lists what remains. With signatures, the signature of each
+// checkpoint is verified as well.
+func collectFsckReport(ctx context.Context, repo *git.Repository, store *checkpoint.GitStore, repair, signatures bool) (*fsckReport, error) {
+	report := &fsckReport{Problems: []checkpoint.FsckProblem{}}
+
+	problems, err := store.VerifyCommitted(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify checkpoints: %w", err)
+	}
+
+	if repair {
+		var toRepair []id.CheckpointID
+		for _, problem := range problems {
+			if problem.Repairable {
+				toRepair = append(toRepair, problem.CheckpointID)
+			}
+		}
+		if len(toRepair) > 0 {
+			report.Repaired, err = store.RepairCommitted(ctx, toRepair)
+			if err != nil {
+				return nil, fmt.Errorf("failed to repair checkpoints: %w", err)
+			}
+			problems, err = store.VerifyCommitted(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to verify checkpoints: %w", err)
+			}
+		}
+	}
+	report.Problems = append(report.Problems, problems...)
+
+	trailerProblems, err := checkCheckpointTrailers(ctx, repo, store)
+	if err != nil {
+		return nil, err
+	}
+	report.Problems = append(report.Problems, trailerProblems...)
+
+	states, err := strategy.ListSessionStates()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session states: %w", err)
+	}
+	report.Problems = append(report.Problems, checkSessionStates(repo, states)...)
+
+	if signatures {
+		report.Signatures, err = store.VerifySignatures(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify signatures: %w", err)
+		}
+		report.Problems = append(report.Problems, checkSignatures(report.Signatures)...)
+	}
+
+	return report, nil
+}
+
+// checkSignatures reports checkpoints created by commits with a bad or
+// revoked signature.
+func checkSignatures(signatures []checkpoint.CheckpointSignature) []checkpoint.FsckProblem {
+	var problems []checkpoint.FsckProblem
+	for _, signature := range signatures {
+		if signature.Status != checkpoint.SignatureBad && signature.Status != checkpoint.SignatureRevoked {
+			continue
+		}
+		problems = append(problems, checkpoint.FsckProblem{
+			Category:     fsckBadSignature,
+			CheckpointID: signature.CheckpointID,
+			Message:      fmt.Sprintf("commit %s has a %s", signature.Commit.String()[:7], signature),
+		})
+	}
+	return problems
+}
+
+// checkCheckpointTrailers reports Entire-Checkpoint trailers and commit notes
+// in the history of HEAD whose checkpoint doesn't exist on the metadata branch.
+func checkCheckpointTrailers(ctx context.Context, repo *git.Repository, store *checkpoint.GitStore) ([]checkpoint.FsckProblem, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, nil //nolint:nilerr // No commits yet means no trailers to check
+	}
+
+	iter, err := repo.Log(&git.LogOptions{
+		From:  head.Hash(),
+		Order: git.LogOrderCommitterTime,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit log: %w", err)
+	}
+	defer iter.Close()
+
+	var problems []checkpoint.FsckProblem
+	err = iter.ForEach(func(c *object.Commit) error {
+		cpID, found := store.CheckpointIDForCommit(ctx, c)
+		if !found {
+			return nil
+		}
+		summary, err := store.ReadCommitted(ctx, cpID)
+		if err != nil {
+			return fmt.Errorf("failed to read checkpoint %s: %w", cpID, err)
+		}
+		if summary == nil {
+			problems = append(problems, checkpoint.FsckProblem{
+				Category:     fsckUnresolvedTrailer,
+				CheckpointID: cpID,
+				Message: fmt.Sprintf("commit %s references a checkpoint that is not on %s",
+					c.Hash.String()[:7], paths.MetadataBranchName),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk history: %w", err)
+	}
+	return problems, nil
+}
+
+// checkSessionStates reports session states that have uncondensed checkpoints
+// but whose shadow branch no longer exists.
+func checkSessionStates(repo *git.Repository, states []*strategy.SessionState) []checkpoint.FsckProblem {
+	var problems []checkpoint.FsckProblem
+	for _, state := range states {
+		if state.StepCount <= 0 || state.BaseCommit == "" {
+			continue
+		}
+		shadowBranch := checkpoint.ShadowBranchNameForCommit(state.BaseCommit, state.WorktreeID)
+		_, err := repo.Reference(plumbing.NewBranchReferenceName(shadowBranch), true)
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			problems = append(problems, checkpoint.FsckProblem{
+				Category: fsckSessionState,
+				Path:     shadowBranch,
+				Message: fmt.Sprintf("session %s has %d checkpoint(s) but its shadow branch is missing",
+					state.SessionID, state.StepCount),
+			})
+		}
+	}
+	return problems
+}
+
+// printFsckReport prints the signatures, if verified, and the problems grouped by category.
+func printFsckReport(w io.Writer, report *fsckReport) {
+	if len(report.Signatures) > 0 {
+		printSignatures(w, report.Signatures)
+	}
+
+	if len(report.Repaired) > 0 {
+		fmt.Fprintf(w, "Repaired %d checkpoint(s):\n", len(report.Repaired))
+		for _, cpID := range report.Repaired {
+			fmt.Fprintf(w, "  %s\n", cpID)
+		}
+		fmt.Fprintln(w)
+	}
+
+	if len(report.Problems) == 0 {
+		fmt.Fprintln(w, "No problems found.")
+		return
+	}
+
+	byCategory := make(map[checkpoint.FsckCategory][]checkpoint.FsckProblem)
+	var categories []checkpoint.FsckCategory
+	for _, problem := range report.Problems {
+		if _, ok := byCategory[problem.Category]; !ok {
+			categories = append(categories, problem.Category)
+		}
+		byCategory[problem.Category] = append(byCategory[problem.Category], problem)
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i] < categories[j] })
+
+	repairable := 0
+	for _, category := range categories {
+		problems := byCategory[category]
+		fmt.Fprintf(w, "%s (%d):\n", category, len(problems))
+		for _, problem := range problems {
+			line := "  "
+			if !problem.CheckpointID.IsEmpty() {
+				line += problem.CheckpointID.String() + " "
+			}
+			if problem.Path != "" {
+				line += problem.Path + ": "
+			}
+			line += problem.Message
+			if problem.Repairable {
+				line += " (repairable)"
+				repairable++
+			}
+			fmt.Fprintln(w, line)
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "Found %d problem(s).", len(report.Problems))
+	if repairable > 0 {
+		fmt.Fprintf(w, " Run 'entire fsck --repair' to fix %d of them.", repairable)
+	}
+	fmt.Fprintln(w)
+}
+
+// printSignatures prints who signed each checkpoint.
+func printSignatures(w io.Writer, signatures []checkpoint.CheckpointSignature) {
+	signed := 0
+	fmt.Fprintf(w, "Signatures (%d checkpoints):\n", len(signatures))
+	for _, signature := range signatures {
+		if signature.IsValid() {
+			signed++
+		}
+		fmt.Fprintf(w, "  %s %s %s\n", signature.CheckpointID, signature.Commit.String()[:7], signature)
+	}
+	fmt.Fprintf(w, "%d of %d checkpoint(s) have a valid signature.\n", signed, len(signatures))
+	fmt.Fprintln(w)
+}
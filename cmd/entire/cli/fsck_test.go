@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSessionStates(t *testing.T) {
+	dir := setupGitRepoForPhaseTest(t)
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	createShadowBranchRef(t, repo, testBaseCommit, "")
+
+	states := []*strategy.SessionState{
+		{SessionID: "live", BaseCommit: testBaseCommit, StepCount: 2},
+		{SessionID: "condensed", BaseCommit: "1111111111111111111111111111111111111111", StepCount: 0},
+		{SessionID: "lost", BaseCommit: "2222222222222222222222222222222222222222", StepCount: 3},
+	}
+
+	problems := checkSessionStates(repo, states)
+
+	require.Len(t, problems, 1)
+	assert.Equal(t, fsckSessionState, problems[0].Category)
+	assert.Equal(t, checkpoint.ShadowBranchNameForCommit("2222222222222222222222222222222222222222", ""), problems[0].Path)
+	assert.Contains(t, problems[0].Message, "lost")
+}
+
+func TestCheckCheckpointTrailers(t *testing.T) {
+	dir := setupGitRepoForPhaseTest(t)
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	store := checkpoint.NewGitStore(repo)
+
+	known := id.MustCheckpointID("a1a2a3a4a5a6")
+	missing := id.MustCheckpointID("b1b2b3b4b5b6")
+	require.NoError(t, store.WriteCommitted(context.Background(), checkpoint.WriteCommittedOptions{
+		CheckpointID:     known,
+		SessionID:        "session-1",
+		Strategy:         "manual-commit",
+		Transcript:       []byte(`{"type":"user"}` + "\n"),
+		CheckpointsCount: 1,
+		AuthorName:       "Test",
+		AuthorEmail:      "test@test.com",
+	}))
+
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	for _, cpID := range []id.CheckpointID{known, missing} {
+		_, err := wt.Commit(trailers.FormatCheckpoint("Change", cpID), &git.CommitOptions{
+			AllowEmptyCommits: true,
+			Author:            &object.Signature{Name: "Test", Email: "test@test.com", When: time.Now()},
+		})
+		require.NoError(t, err)
+	}
+
+	problems, err := checkCheckpointTrailers(context.Background(), repo, store)
+	require.NoError(t, err)
+
+	require.Len(t, problems, 1)
+	assert.Equal(t, fsckUnresolvedTrailer, problems[0].Category)
+	assert.Equal(t, missing, problems[0].CheckpointID)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
 	"time"
@@ -299,6 +300,28 @@ func CheckoutBranch(ref string) error {
 	return nil
 }
 
+// AddDetachedWorktree creates a linked worktree at dir with commit checked out
+// on a detached HEAD.
+func AddDetachedWorktree(dir, commit string) error {
+	ctx := context.Background()
+	cmd := exec.CommandContext(ctx, "git", "worktree", "add", "--detach", dir, commit)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git worktree add failed: %s: %w", strings.TrimSpace(string(output)), err)
+	}
+	return nil
+}
+
+// RestoreWorktreeFiles makes the files of the worktree at dir match the tree of
+// source, leaving HEAD and the index alone. Files under .entire/ are not touched.
+func RestoreWorktreeFiles(dir, source string) error {
+	ctx := context.Background()
+	cmd := exec.CommandContext(ctx, "git", "-C", dir, "restore", "--source="+source, "--worktree", "--", ".", ":(exclude)"+paths.EntireDir)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git restore failed: %s: %w", strings.TrimSpace(string(output)), err)
+	}
+	return nil
+}
+
 // ValidateBranchName checks if a branch name is valid using git check-ref-format.
 // Returns an error if the name is invalid or contains unsafe characters.
 func ValidateBranchName(branchName string) error {
@@ -359,21 +382,14 @@ func FetchAndCheckoutRemoteBranch(branchName string) error {
 // This is used when the metadata branch exists on remote but not locally.
 // Uses git CLI instead of go-git for fetch because go-git doesn't use credential helpers,
 // which breaks HTTPS URLs that require authentication.
+// Fetches from the checkpoint remote and ref instead of origin if they are configured.
+// Also fetches the refs/notes/entire commit notes and merges them into the local ones.
 func FetchMetadataBranch() error {
 	branchName := paths.MetadataBranchName
+	target := strategy.ResolveCheckpointRemote("origin")
 
-	// Use git CLI for fetch (go-git's fetch can be tricky with auth)
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
-	defer cancel()
-
-	refSpec := fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", branchName, branchName)
-	//nolint:gosec // G204: branchName is a constant from paths package
-	fetchCmd := exec.CommandContext(ctx, "git", "fetch", "origin", refSpec)
-	if output, err := fetchCmd.CombinedOutput(); err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			return errors.New("fetch timed out after 2 minutes")
-		}
-		return fmt.Errorf("failed to fetch %s from origin: %s: %w", branchName, strings.TrimSpace(string(output)), err)
+	if err := fetchRemoteMetadataBranch(target); err != nil {
+		return err
 	}
 
 	repo, err := openRepository()
@@ -382,9 +398,9 @@ func FetchMetadataBranch() error {
 	}
 
 	// Get the remote branch reference
-	remoteRef, err := repo.Reference(plumbing.NewRemoteReferenceName("origin", branchName), true)
+	remoteRef, err := repo.Reference(target.TrackingRef(), true)
 	if err != nil {
-		return fmt.Errorf("branch '%s' not found on origin: %w", branchName, err)
+		return fmt.Errorf("'%s' not found on %s: %w", target.Ref, target.Remote, err)
 	}
 
 	// Create or update local branch pointing to the same commit
@@ -393,5 +409,28 @@ func FetchMetadataBranch() error {
 		return fmt.Errorf("failed to create local %s branch: %w", branchName, err)
 	}
 
+	// Commit notes linking commits to checkpoints come along (best-effort)
+	if err := strategy.FetchCommitNotes(target.Remote); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to fetch checkpoint notes: %v\n", err)
+	}
+
+	return nil
+}
+
+// fetchRemoteMetadataBranch fetches the metadata branch on the checkpoint
+// remote into its tracking ref, without touching the local branch.
+func fetchRemoteMetadataBranch(target strategy.CheckpointRemote) error {
+	// Use git CLI for fetch (go-git's fetch can be tricky with auth)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	//nolint:gosec // G204: remote and ref come from settings, passed as separate arguments
+	fetchCmd := exec.CommandContext(ctx, "git", "fetch", target.Remote, target.FetchRefspec())
+	if output, err := fetchCmd.CombinedOutput(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return errors.New("fetch timed out after 2 minutes")
+		}
+		return fmt.Errorf("failed to fetch %s from %s: %s: %w", target.Ref, target.Remote, strings.TrimSpace(string(output)), err)
+	}
 	return nil
 }
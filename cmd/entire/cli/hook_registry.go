@@ -9,6 +9,7 @@ import (
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
 	"github.com/entireio/cli/cmd/entire/cli/agent/claudecode"
+	"github.com/entireio/cli/cmd/entire/cli/agent/codex"
 	"github.com/entireio/cli/cmd/entire/cli/agent/geminicli"
 	"github.com/entireio/cli/cmd/entire/cli/logging"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
@@ -16,8 +17,10 @@ import (
 	"github.com/spf13/cobra"
 )
 
-// HookHandlerFunc is a function that handles a specific hook event.
-type HookHandlerFunc func() error
+// HookHandlerFunc is a function that handles a specific hook event. args are
+// the hook command's arguments: most agents send hook input on stdin, but
+// Codex passes it as the last argument.
+type HookHandlerFunc func(args []string) error
 
 // hookRegistry maps (agentName, hookName) to handler functions.
 // This allows agents to define their hook vocabulary while keeping
@@ -46,7 +49,7 @@ func GetHookHandler(agentName agent.AgentName, hookName string) HookHandlerFunc
 //nolint:gochecknoinits // Hook handler registration at startup is the intended pattern
 func init() {
 	// Register Claude Code handlers
-	RegisterHookHandler(agent.AgentNameClaudeCode, claudecode.HookNameSessionStart, func() error {
+	RegisterHookHandler(agent.AgentNameClaudeCode, claudecode.HookNameSessionStart, func(_ []string) error {
 		enabled, err := IsEnabled()
 		if err == nil && !enabled {
 			return nil
@@ -54,7 +57,7 @@ func init() {
 		return handleClaudeCodeSessionStart()
 	})
 
-	RegisterHookHandler(agent.AgentNameClaudeCode, claudecode.HookNameSessionEnd, func() error {
+	RegisterHookHandler(agent.AgentNameClaudeCode, claudecode.HookNameSessionEnd, func(_ []string) error {
 		enabled, err := IsEnabled()
 		if err == nil && !enabled {
 			return nil
@@ -62,7 +65,7 @@ func init() {
 		return handleClaudeCodeSessionEnd()
 	})
 
-	RegisterHookHandler(agent.AgentNameClaudeCode, claudecode.HookNameStop, func() error {
+	RegisterHookHandler(agent.AgentNameClaudeCode, claudecode.HookNameStop, func(_ []string) error {
 		enabled, err := IsEnabled()
 		if err == nil && !enabled {
 			return nil
@@ -70,7 +73,7 @@ func init() {
 		return commitWithMetadata()
 	})
 
-	RegisterHookHandler(agent.AgentNameClaudeCode, claudecode.HookNameUserPromptSubmit, func() error {
+	RegisterHookHandler(agent.AgentNameClaudeCode, claudecode.HookNameUserPromptSubmit, func(_ []string) error {
 		enabled, err := IsEnabled()
 		if err == nil && !enabled {
 			return nil
@@ -78,7 +81,7 @@ func init() {
 		return captureInitialState()
 	})
 
-	RegisterHookHandler(agent.AgentNameClaudeCode, claudecode.HookNamePreTask, func() error {
+	RegisterHookHandler(agent.AgentNameClaudeCode, claudecode.HookNamePreTask, func(_ []string) error {
 		enabled, err := IsEnabled()
 		if err == nil && !enabled {
 			return nil
@@ -86,7 +89,7 @@ func init() {
 		return handleClaudeCodePreTask()
 	})
 
-	RegisterHookHandler(agent.AgentNameClaudeCode, claudecode.HookNamePostTask, func() error {
+	RegisterHookHandler(agent.AgentNameClaudeCode, claudecode.HookNamePostTask, func(_ []string) error {
 		enabled, err := IsEnabled()
 		if err == nil && !enabled {
 			return nil
@@ -94,7 +97,7 @@ func init() {
 		return handleClaudeCodePostTask()
 	})
 
-	RegisterHookHandler(agent.AgentNameClaudeCode, claudecode.HookNamePostTodo, func() error {
+	RegisterHookHandler(agent.AgentNameClaudeCode, claudecode.HookNamePostTodo, func(_ []string) error {
 		enabled, err := IsEnabled()
 		if err == nil && !enabled {
 			return nil
@@ -103,7 +106,7 @@ func init() {
 	})
 
 	// Register Gemini CLI handlers
-	RegisterHookHandler(agent.AgentNameGemini, geminicli.HookNameSessionStart, func() error {
+	RegisterHookHandler(agent.AgentNameGemini, geminicli.HookNameSessionStart, func(_ []string) error {
 		enabled, err := IsEnabled()
 		if err == nil && !enabled {
 			return nil
@@ -111,7 +114,7 @@ func init() {
 		return handleGeminiSessionStart()
 	})
 
-	RegisterHookHandler(agent.AgentNameGemini, geminicli.HookNameSessionEnd, func() error {
+	RegisterHookHandler(agent.AgentNameGemini, geminicli.HookNameSessionEnd, func(_ []string) error {
 		enabled, err := IsEnabled()
 		if err == nil && !enabled {
 			return nil
@@ -119,7 +122,7 @@ func init() {
 		return handleGeminiSessionEnd()
 	})
 
-	RegisterHookHandler(agent.AgentNameGemini, geminicli.HookNameBeforeTool, func() error {
+	RegisterHookHandler(agent.AgentNameGemini, geminicli.HookNameBeforeTool, func(_ []string) error {
 		enabled, err := IsEnabled()
 		if err == nil && !enabled {
 			return nil
@@ -127,7 +130,7 @@ func init() {
 		return handleGeminiBeforeTool()
 	})
 
-	RegisterHookHandler(agent.AgentNameGemini, geminicli.HookNameAfterTool, func() error {
+	RegisterHookHandler(agent.AgentNameGemini, geminicli.HookNameAfterTool, func(_ []string) error {
 		enabled, err := IsEnabled()
 		if err == nil && !enabled {
 			return nil
@@ -135,7 +138,7 @@ func init() {
 		return handleGeminiAfterTool()
 	})
 
-	RegisterHookHandler(agent.AgentNameGemini, geminicli.HookNameBeforeAgent, func() error {
+	RegisterHookHandler(agent.AgentNameGemini, geminicli.HookNameBeforeAgent, func(_ []string) error {
 		enabled, err := IsEnabled()
 		if err == nil && !enabled {
 			return nil
@@ -143,7 +146,7 @@ func init() {
 		return handleGeminiBeforeAgent()
 	})
 
-	RegisterHookHandler(agent.AgentNameGemini, geminicli.HookNameAfterAgent, func() error {
+	RegisterHookHandler(agent.AgentNameGemini, geminicli.HookNameAfterAgent, func(_ []string) error {
 		enabled, err := IsEnabled()
 		if err == nil && !enabled {
 			return nil
@@ -151,7 +154,7 @@ func init() {
 		return handleGeminiAfterAgent()
 	})
 
-	RegisterHookHandler(agent.AgentNameGemini, geminicli.HookNameBeforeModel, func() error {
+	RegisterHookHandler(agent.AgentNameGemini, geminicli.HookNameBeforeModel, func(_ []string) error {
 		enabled, err := IsEnabled()
 		if err == nil && !enabled {
 			return nil
@@ -159,7 +162,7 @@ func init() {
 		return handleGeminiBeforeModel()
 	})
 
-	RegisterHookHandler(agent.AgentNameGemini, geminicli.HookNameAfterModel, func() error {
+	RegisterHookHandler(agent.AgentNameGemini, geminicli.HookNameAfterModel, func(_ []string) error {
 		enabled, err := IsEnabled()
 		if err == nil && !enabled {
 			return nil
@@ -167,7 +170,7 @@ func init() {
 		return handleGeminiAfterModel()
 	})
 
-	RegisterHookHandler(agent.AgentNameGemini, geminicli.HookNameBeforeToolSelection, func() error {
+	RegisterHookHandler(agent.AgentNameGemini, geminicli.HookNameBeforeToolSelection, func(_ []string) error {
 		enabled, err := IsEnabled()
 		if err == nil && !enabled {
 			return nil
@@ -175,7 +178,7 @@ func init() {
 		return handleGeminiBeforeToolSelection()
 	})
 
-	RegisterHookHandler(agent.AgentNameGemini, geminicli.HookNamePreCompress, func() error {
+	RegisterHookHandler(agent.AgentNameGemini, geminicli.HookNamePreCompress, func(_ []string) error {
 		enabled, err := IsEnabled()
 		if err == nil && !enabled {
 			return nil
@@ -183,13 +186,22 @@ func init() {
 		return handleGeminiPreCompress()
 	})
 
-	RegisterHookHandler(agent.AgentNameGemini, geminicli.HookNameNotification, func() error {
+	RegisterHookHandler(agent.AgentNameGemini, geminicli.HookNameNotification, func(_ []string) error {
 		enabled, err := IsEnabled()
 		if err == nil && !enabled {
 			return nil
 		}
 		return handleGeminiNotification()
 	})
+
+	// Register Codex CLI handlers
+	RegisterHookHandler(agent.AgentNameCodex, codex.HookNameNotify, func(args []string) error {
+		enabled, err := IsEnabled()
+		if err == nil && !enabled {
+			return nil
+		}
+		return handleCodexNotify(codexHookInput(args))
+	})
 }
 
 // agentHookLogCleanup stores the cleanup function for agent hook logging.
@@ -267,53 +279,60 @@ func newAgentHookVerbCmdWithLogging(agentName agent.AgentName, hookName string)
 		Use:    hookName,
 		Hidden: true,
 		Short:  "Called on " + hookName,
-		RunE: func(_ *cobra.Command, _ []string) error {
-			// Skip silently if not in a git repository - hooks shouldn't prevent the agent from working
-			if _, err := paths.RepoRoot(); err != nil {
-				return nil
-			}
+		RunE: func(_ *cobra.Command, args []string) error {
+			return runAgentHook(agentName, hookName, GetHookHandler(agentName, hookName), args)
+		},
+	}
+}
 
-			start := time.Now()
+// runAgentHook runs the handler of an agent hook with structured logging.
+// A nil handler is reported as an error.
+func runAgentHook(agentName agent.AgentName, hookName string, handler HookHandlerFunc, args []string) error {
+	// Skip silently if not in a git repository - hooks shouldn't prevent the agent from working
+	if _, err := paths.RepoRoot(); err != nil {
+		return nil
+	}
 
-			// Initialize logging context with agent name
-			ctx := logging.WithAgent(logging.WithComponent(context.Background(), "hooks"), agentName)
+	start := time.Now()
 
-			// Get strategy name for logging
-			strategyName := unknownStrategyName //nolint:ineffassign,wastedassign // already present in codebase
-			strategyName = GetStrategy().Name()
+	// Initialize logging context with agent name
+	ctx := logging.WithAgent(logging.WithComponent(context.Background(), "hooks"), agentName)
 
-			hookType := getHookType(hookName)
+	// Get strategy name for logging
+	strategyName := unknownStrategyName //nolint:ineffassign,wastedassign // already present in codebase
+	strategyName = GetStrategy().Name()
 
-			logging.Debug(ctx, "hook invoked",
-				slog.String("hook", hookName),
-				slog.String("hook_type", hookType),
-				slog.String("strategy", strategyName),
-			)
+	hookType := getHookType(hookName)
 
-			handler := GetHookHandler(agentName, hookName)
-			if handler == nil {
-				logging.Error(ctx, "no handler registered",
-					slog.String("hook", hookName),
-					slog.String("hook_type", hookType),
-				)
-				return fmt.Errorf("no handler registered for %s/%s", agentName, hookName)
-			}
+	logging.Debug(ctx, "hook invoked",
+		slog.String("hook", hookName),
+		slog.String("hook_type", hookType),
+		slog.String("strategy", strategyName),
+	)
 
-			// Set the current hook agent so handlers can retrieve it
-			// without guessing from directory presence
-			currentHookAgentName = agentName
-			defer func() { currentHookAgentName = "" }()
+	if handler == nil {
+		logging.Error(ctx, "no handler registered",
+			slog.String("hook", hookName),
+			slog.String("hook_type", hookType),
+		)
+		return fmt.Errorf("no handler registered for %s/%s", agentName, hookName)
+	}
 
-			hookErr := handler()
+	// Set the current hook agent so handlers can retrieve it
+	// without guessing from directory presence
+	currentHookAgentName = agentName
+	defer func() {
+		currentHookAgentName = ""
+	}()
 
-			logging.LogDuration(ctx, slog.LevelDebug, "hook completed", start,
-				slog.String("hook", hookName),
-				slog.String("hook_type", hookType),
-				slog.String("strategy", strategyName),
-				slog.Bool("success", hookErr == nil),
-			)
+	hookErr := handler(args)
 
-			return hookErr
-		},
-	}
+	logging.LogDuration(ctx, slog.LevelDebug, "hook completed", start,
+		slog.String("hook", hookName),
+		slog.String("hook_type", hookType),
+		slog.String("strategy", strategyName),
+		slog.Bool("success", hookErr == nil),
+	)
+
+	return hookErr
 }
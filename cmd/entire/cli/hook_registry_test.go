@@ -58,7 +58,7 @@ func TestNewAgentHookVerbCmd_LogsInvocation(t *testing.T) {
 
 	// Register a test handler
 	testHandlerCalled := false
-	RegisterHookHandler(agent.AgentName("test-agent"), "test-hook", func() error {
+	RegisterHookHandler(agent.AgentName("test-agent"), "test-hook", func(_ []string) error {
 		testHandlerCalled = true
 		return nil
 	})
@@ -173,7 +173,7 @@ func TestNewAgentHookVerbCmd_LogsFailure(t *testing.T) {
 	defer cleanup()
 
 	// Register a handler that fails
-	RegisterHookHandler(agent.AgentName("test-agent"), "failing-hook", func() error {
+	RegisterHookHandler(agent.AgentName("test-agent"), "failing-hook", func(_ []string) error {
 		return context.DeadlineExceeded // Use a real error
 	})
 
@@ -305,7 +305,7 @@ func TestHookCommand_SetsCurrentHookAgentName(t *testing.T) {
 			var agentNameInsideHandler agent.AgentName
 
 			hookName := "test-hook-" + string(tt.agentName)
-			RegisterHookHandler(tt.agentName, hookName, func() error {
+			RegisterHookHandler(tt.agentName, hookName, func(_ []string) error {
 				agentNameInsideHandler = currentHookAgentName
 				return nil
 			})
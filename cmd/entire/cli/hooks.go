@@ -278,10 +278,15 @@ func handleSessionStartCommon() error {
 		return err
 	}
 
-	// Fire EventSessionStart for the current session (if state exists).
-	// This handles ENDED → IDLE (re-entering a session).
-	// TODO(ENT-221): dispatch ActionWarnStaleSession for ACTIVE/ACTIVE_COMMITTED sessions.
-	if state, loadErr := strategy.LoadSessionState(input.SessionID); loadErr != nil {
+	transitionSessionStart(input.SessionID)
+	return nil
+}
+
+// transitionSessionStart fires EventSessionStart for a session (if state exists).
+// This handles ENDED → IDLE (re-entering a session).
+// TODO(ENT-221): dispatch ActionWarnStaleSession for ACTIVE/ACTIVE_COMMITTED sessions.
+func transitionSessionStart(sessionID string) {
+	if state, loadErr := strategy.LoadSessionState(sessionID); loadErr != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to load session state on start: %v\n", loadErr)
 	} else if state != nil {
 		strategy.TransitionAndLog(state, session.EventSessionStart, session.TransitionContext{})
@@ -289,8 +294,6 @@ func handleSessionStartCommon() error {
 			fmt.Fprintf(os.Stderr, "Warning: failed to update session state on start: %v\n", saveErr)
 		}
 	}
-
-	return nil
 }
 
 // hookResponse represents a JSON response.
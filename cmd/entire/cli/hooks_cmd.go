@@ -3,7 +3,11 @@ package cli
 import (
 	"github.com/entireio/cli/cmd/entire/cli/agent"
 	// Import agents to ensure they are registered before we iterate
+	_ "github.com/entireio/cli/cmd/entire/cli/agent/aider"
 	_ "github.com/entireio/cli/cmd/entire/cli/agent/claudecode"
+	_ "github.com/entireio/cli/cmd/entire/cli/agent/codex"
+	_ "github.com/entireio/cli/cmd/entire/cli/agent/cursor"
+	"github.com/entireio/cli/cmd/entire/cli/agent/external"
 	_ "github.com/entireio/cli/cmd/entire/cli/agent/geminicli"
 
 	"github.com/spf13/cobra"
@@ -27,6 +31,10 @@ func newHooksCmd() *cobra.Command {
 		if err != nil {
 			continue
 		}
+		if ext, ok := ag.(*external.ExternalAgent); ok {
+			cmd.AddCommand(newExternalAgentHooksCmd(ext))
+			continue
+		}
 		if handler, ok := ag.(agent.HookHandler); ok {
 			cmd.AddCommand(newAgentHooksCmd(agentName, handler))
 		}
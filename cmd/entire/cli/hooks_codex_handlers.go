@@ -0,0 +1,96 @@
+// hooks_codex_handlers.go contains Codex CLI specific hook handler implementations.
+// These are called by the hook registry in hook_registry.go.
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/agent/codex"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+)
+
+// codexHookInput returns the input of a Codex hook from its arguments: Codex
+// passes the notify payload as the last argument rather than on stdin.
+func codexHookInput(args []string) io.Reader {
+	if len(args) > 0 {
+		return strings.NewReader(args[len(args)-1])
+	}
+	return os.Stdin
+}
+
+// handleCodexNotify handles Codex's notify hook, run when a turn ends.
+//
+// Codex has no hook before a prompt, so the pre-prompt state of a turn is
+// captured when the previous turn ends. The first turn seen in a session starts
+// at its last prompt, without a snapshot of untracked files from before it.
+// Turns are then saved like those detected by `entire watch`.
+func handleCodexNotify(hookInput io.Reader) error {
+	ag, err := GetCurrentHookAgent()
+	if err != nil {
+		return fmt.Errorf("failed to get agent: %w", err)
+	}
+
+	input, err := ag.ParseHookInput(agent.HookStop, hookInput)
+	if err != nil {
+		if errors.Is(err, codex.ErrUnsupportedNotification) {
+			return nil
+		}
+		return fmt.Errorf("failed to parse hook input: %w", err)
+	}
+
+	logCtx := logging.WithAgent(logging.WithComponent(context.Background(), "hooks"), ag.Name())
+	logging.Info(logCtx, "notify",
+		slog.String("hook", codex.HookNameNotify),
+		slog.String("hook_type", "agent"),
+		slog.String("model_session_id", input.SessionID),
+		slog.String("transcript_path", input.SessionRef),
+	)
+
+	sessionID := input.SessionID
+	change := &agent.SessionChange{
+		SessionID:  sessionID,
+		SessionRef: input.SessionRef,
+		EventType:  agent.HookStop,
+		Timestamp:  input.Timestamp,
+	}
+
+	session, err := ag.ReadSession(&agent.HookInput{SessionID: sessionID, SessionRef: input.SessionRef})
+	if err != nil {
+		return fmt.Errorf("failed to read session: %w", err)
+	}
+	rollout := codex.ParseRollout(session.NativeData)
+
+	handler := strategyTurnHandler{}
+	preState, err := LoadPrePromptState(sessionID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to load pre-prompt state: %v\n", err)
+	}
+	if preState == nil || preState.StepTranscriptStart > len(rollout.Lines) {
+		if err := handler.TurnStart(ag, change, codex.LastPromptLine(rollout)); err != nil {
+			return err
+		}
+	} else {
+		transcriptPath, err := writeWatchedTranscript(sessionID, session)
+		if err != nil {
+			return err
+		}
+		initializeWatchedSession(ag, sessionID, transcriptPath, session)
+	}
+
+	if err := handler.TurnEnd(ag, change); err != nil {
+		return err
+	}
+
+	// The next turn starts where this one ended
+	if err := CaptureWatchedPrePromptState(sessionID, len(rollout.Lines), entryIdentifierBefore(session, len(rollout.Lines))); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to capture pre-prompt state: %v\n", err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// hooks_external_handlers.go contains the hook handler for agent plugins.
+// Plugins declare their own hook verbs, each reporting a lifecycle event, so
+// one handler serves every plugin by dispatching on the event.
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/agent/external"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+
+	"github.com/spf13/cobra"
+)
+
+// newExternalAgentHooksCmd creates the hooks subcommand of an agent plugin.
+// The plugin is only asked for its hook verbs when a hook runs, so building
+// the command tree doesn't start every plugin on PATH.
+func newExternalAgentHooksCmd(ext *external.ExternalAgent) *cobra.Command {
+	agentName := ext.Name()
+	return &cobra.Command{
+		Use:    string(agentName) + " <hook>",
+		Short:  "Agent plugin hook handlers",
+		Hidden: true,
+		Args:   cobra.MinimumNArgs(1),
+		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+			agentHookLogCleanup = initHookLogging()
+			return nil
+		},
+		PersistentPostRunE: func(_ *cobra.Command, _ []string) error {
+			if agentHookLogCleanup != nil {
+				agentHookLogCleanup()
+			}
+			return nil
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			hookName := args[0]
+			var handler HookHandlerFunc
+			if hookType, ok := ext.HookType(hookName); ok {
+				handler = func(_ []string) error {
+					enabled, err := IsEnabled()
+					if err == nil && !enabled {
+						return nil
+					}
+					return handleExternalAgentHook(hookName, hookType)
+				}
+			}
+			return runAgentHook(agentName, hookName, handler, args[1:])
+		},
+	}
+}
+
+// handleExternalAgentHook handles a hook of an agent plugin.
+// Prompt submission and stop are handled like turns detected by `entire watch`,
+// so plugins report transcript positions as the Position of the session's entries.
+func handleExternalAgentHook(hookName string, hookType agent.HookType) error {
+	ag, err := GetCurrentHookAgent()
+	if err != nil {
+		return fmt.Errorf("failed to get agent: %w", err)
+	}
+
+	input, err := ag.ParseHookInput(hookType, os.Stdin)
+	if err != nil {
+		return fmt.Errorf("failed to parse hook input: %w", err)
+	}
+
+	logCtx := logging.WithAgent(logging.WithComponent(context.Background(), "hooks"), ag.Name())
+	logging.Info(logCtx, hookName,
+		slog.String("hook", hookName),
+		slog.String("hook_type", "agent"),
+		slog.String("model_session_id", input.SessionID),
+		slog.String("transcript_path", input.SessionRef),
+	)
+
+	if input.SessionID == "" {
+		return errors.New("no session_id in input")
+	}
+	change := &agent.SessionChange{
+		SessionID:  input.SessionID,
+		SessionRef: input.SessionRef,
+		EventType:  hookType,
+		Timestamp:  input.Timestamp,
+	}
+	handler := strategyTurnHandler{}
+
+	switch hookType {
+	case agent.HookSessionStart:
+		transitionSessionStart(input.SessionID)
+	case agent.HookUserPromptSubmit:
+		session, err := ag.ReadSession(input)
+		if err != nil {
+			return fmt.Errorf("failed to read session: %w", err)
+		}
+		return handler.TurnStart(ag, change, agent.TranscriptLength(session.Entries))
+	case agent.HookStop:
+		return handler.TurnEnd(ag, change)
+	case agent.HookSessionEnd:
+		if err := markSessionEnded(input.SessionID); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to mark session ended: %v\n", err)
+		}
+	case agent.HookPreToolUse, agent.HookPostToolUse:
+		// Tool events carry nothing Entire records for plugins
+	}
+	return nil
+}
@@ -224,7 +224,7 @@ func commitGeminiSession(ctx *geminiSessionContext) error {
 	logFileChanges(relModifiedFiles, relNewFiles, relDeletedFiles)
 
 	contextFile := filepath.Join(ctx.sessionDirAbs, paths.ContextFileName)
-	if err := createContextFileForGemini(contextFile, ctx.commitMessage, ctx.sessionID, ctx.allPrompts, ctx.summary); err != nil {
+	if err := createContextFileFromPrompts(contextFile, ctx.commitMessage, ctx.sessionID, ctx.allPrompts, ctx.summary); err != nil {
 		return fmt.Errorf("failed to create context file: %w", err)
 	}
 	fmt.Fprintf(os.Stderr, "Created context file: %s\n", ctx.sessionDir+"/"+paths.ContextFileName)
@@ -302,8 +302,9 @@ func logFileChanges(modified, newFiles, deleted []string) {
 	}
 }
 
-// createContextFileForGemini creates a context.md file for Gemini sessions.
-func createContextFileForGemini(contextFile, commitMessage, sessionID string, prompts []string, summary string) error {
+// createContextFileFromPrompts creates a context.md file from extracted prompts and summary.
+// Used for Gemini sessions and for sessions of watched agents (see watch.go).
+func createContextFileFromPrompts(contextFile, commitMessage, sessionID string, prompts []string, summary string) error {
 	var sb strings.Builder
 
 	sb.WriteString("# Session Context\n\n")
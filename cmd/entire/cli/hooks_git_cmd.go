@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
 	"github.com/entireio/cli/cmd/entire/cli/logging"
 	"github.com/entireio/cli/cmd/entire/cli/strategy"
 
@@ -57,6 +58,23 @@ func (g *gitHookContext) logCompleted(err error, extraAttrs ...any) {
 	logging.LogDuration(g.ctx, slog.LevelDebug, g.hookName+" hook completed", g.start, append(attrs, extraAttrs...)...)
 }
 
+// repackLooseObjects packs the repository's loose objects if there are many of
+// them (see checkpoint.GitStore.RepackLooseObjects). Failures are only logged.
+func (g *gitHookContext) repackLooseObjects() {
+	repo, err := openRepository()
+	if err != nil {
+		return
+	}
+	repacked, err := checkpoint.NewGitStore(repo).RepackLooseObjects(g.ctx)
+	if err != nil {
+		logging.Warn(g.ctx, "failed to repack loose objects", slog.String("error", err.Error()))
+		return
+	}
+	if repacked {
+		logging.Debug(g.ctx, "repacked loose objects")
+	}
+}
+
 // initHookLogging initializes logging for hooks by finding the most recent session.
 // Returns a cleanup function that should be deferred.
 func initHookLogging() func() {
@@ -97,6 +115,7 @@ func newHooksGitCmd() *cobra.Command {
 	cmd.AddCommand(newHooksGitPrepareCommitMsgCmd())
 	cmd.AddCommand(newHooksGitCommitMsgCmd())
 	cmd.AddCommand(newHooksGitPostCommitCmd())
+	cmd.AddCommand(newHooksGitPostRewriteCmd())
 	cmd.AddCommand(newHooksGitPrePushCmd())
 
 	return cmd
@@ -163,6 +182,35 @@ func newHooksGitPostCommitCmd() *cobra.Command {
 				g.logCompleted(hookErr)
 			}
 
+			// Checkpoints are written as loose objects; pack them once enough
+			// have piled up. Nothing reads objects in this process after this.
+			g.repackLooseObjects()
+
+			return nil
+		},
+	}
+}
+
+func newHooksGitPostRewriteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "post-rewrite <amend|rebase>",
+		Short: "Handle post-rewrite git hook",
+		Long:  "Handle post-rewrite git hook. Reads \"<old-sha> <new-sha>\" lines from stdin.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rewriteType := args[0]
+
+			g := newGitHookContext("post-rewrite")
+			g.logInvoked(slog.String("rewrite_type", rewriteType))
+
+			if handler, ok := g.strategy.(strategy.PostRewriteHandler); ok {
+				rewrites, hookErr := strategy.ParsePostRewriteInput(cmd.InOrStdin())
+				if hookErr == nil {
+					hookErr = handler.PostRewrite(rewriteType, rewrites)
+				}
+				g.logCompleted(hookErr, slog.String("rewrite_type", rewriteType), slog.Int("rewrites", len(rewrites)))
+			}
+
 			return nil
 		},
 	}
@@ -5,6 +5,7 @@ package integration
 import (
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -574,6 +575,79 @@ func TestLogsOnlyRewind_Reset(t *testing.T) {
 	}
 }
 
+// TestLogsOnlyRewind_ResetUndo verifies that 'rewind --undo' after a --reset
+// moves HEAD back and restores the uncommitted changes the reset discarded,
+// and that undoing again redoes the reset.
+func TestLogsOnlyRewind_ResetUndo(t *testing.T) {
+	t.Parallel()
+	env := NewTestEnv(t)
+	defer env.Cleanup()
+
+	env.InitRepo()
+	env.WriteFile("README.md", "# Test Repository")
+	env.GitAdd("README.md")
+	env.GitCommit("Initial commit")
+	env.GitCheckoutNewBranch("feature/reset-undo")
+	env.InitEntire(strategy.StrategyNameManualCommit)
+
+	var commitHashes []string
+	for i, content := range []string{"version 1 content", "version 2 content"} {
+		session := env.NewSession()
+		if err := env.SimulateUserPromptSubmit(session.ID); err != nil {
+			t.Fatalf("SimulateUserPromptSubmit failed: %v", err)
+		}
+		env.WriteFile("file.txt", content)
+		session.CreateTranscript("Write "+content, []FileChange{{Path: "file.txt", Content: content}})
+		if err := env.SimulateStop(session.ID, session.TranscriptPath); err != nil {
+			t.Fatalf("SimulateStop failed: %v", err)
+		}
+		env.GitCommitWithShadowHooks("Write version "+strconv.Itoa(i+1), "file.txt")
+		commitHashes = append(commitHashes, env.GetHeadHash())
+		if err := env.ClearSessionState(session.ID); err != nil {
+			t.Fatalf("ClearSessionState failed: %v", err)
+		}
+	}
+	commit1Hash, commit2Hash := commitHashes[0], commitHashes[1]
+
+	// Uncommitted changes the reset discards
+	env.WriteFile("file.txt", "uncommitted edit")
+
+	if err := env.RewindReset(commit1Hash); err != nil {
+		t.Fatalf("RewindReset failed: %v", err)
+	}
+	if env.GetHeadHash() != commit1Hash {
+		t.Fatalf("HEAD should be at commit 1 after the reset, got %s", env.GetHeadHash()[:7])
+	}
+
+	output, err := env.RunCLIWithError("rewind", "--undo")
+	if err != nil {
+		t.Fatalf("rewind --undo failed: %v: %s", err, output)
+	}
+	if strings.Contains(output, "Nothing to undo") {
+		t.Fatalf("rewind --undo found nothing to undo after a reset: %s", output)
+	}
+	if env.GetHeadHash() != commit2Hash {
+		t.Errorf("HEAD should be back at commit 2 (%s), got %s", commit2Hash[:7], env.GetHeadHash()[:7])
+	}
+	if branch := env.GetCurrentBranch(); branch != "feature/reset-undo" {
+		t.Errorf("Should still be on the feature branch, got: %s", branch)
+	}
+	if content := env.ReadFile("file.txt"); content != "uncommitted edit" {
+		t.Errorf("file.txt should have the uncommitted edit back, got: %s", content)
+	}
+
+	// Undoing again redoes the reset
+	if output, err := env.RunCLIWithError("rewind", "--undo"); err != nil {
+		t.Fatalf("second rewind --undo failed: %v: %s", err, output)
+	}
+	if env.GetHeadHash() != commit1Hash {
+		t.Errorf("HEAD should be at commit 1 again, got %s", env.GetHeadHash()[:7])
+	}
+	if content := env.ReadFile("file.txt"); content != "version 1 content" {
+		t.Errorf("file.txt should be at version 1 again, got: %s", content)
+	}
+}
+
 // TestLogsOnlyRewind_ResetRestoresTranscript verifies that reset also restores transcript.
 func TestLogsOnlyRewind_ResetRestoresTranscript(t *testing.T) {
 	t.Parallel()
@@ -25,6 +25,7 @@ const (
 	ContextFileName          = "context.md"
 	PromptFileName           = "prompt.txt"
 	SummaryFileName          = "summary.txt"
+	SessionSummaryFileName   = "summary.json"
 	TranscriptFileName       = "full.jsonl"
 	TranscriptFileNameLegacy = "full.log"
 	MetadataFileName         = "metadata.json"
@@ -36,6 +37,16 @@ const (
 // MetadataBranchName is the orphan branch used by auto-commit and manual-commit strategies to store metadata
 const MetadataBranchName = "entire/checkpoints/v1"
 
+// CommitNotesRef is the git notes ref that links code commits to checkpoints when
+// commit linking is set to notes instead of the Entire-Checkpoint trailer.
+// Each note holds an "Entire-Checkpoint: <id>" line.
+const CommitNotesRef = "refs/notes/entire"
+
+// CommitLinksDir is the top-level directory on the metadata branch that links rewritten
+// code commits (amend, rebase) to checkpoints when the new commit lost its Entire-Checkpoint trailer.
+// Links are sharded by commit SHA: commits/<sha[:2]>/<sha[2:]>.json
+const CommitLinksDir = "commits"
+
 // CheckpointPath returns the sharded storage path for a checkpoint ID.
 // Uses first 2 characters as shard (256 buckets), remaining as folder name.
 // Example: "a3b2c4d5e6f7" -> "a3/b2c4d5e6f7"
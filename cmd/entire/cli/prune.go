@@ -0,0 +1,220 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/cobra"
+)
+
+func newPruneCmd() *cobra.Command {
+	var forceFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Apply the retention policy to checkpoint metadata",
+		Long: `Remove old checkpoint data from the entire/checkpoints/v1 branch according to
+the retention policy in .entire/settings.json:
+
+  "strategy_options": {
+    "retention": {
+      "max_age_days": 180,
+      "max_size_mb": 500,
+      "drop_transcripts_after_days": 30,
+      "protected_branches": ["main"]
+    }
+  }
+
+  max_age_days                 Remove checkpoints older than this
+  max_size_mb                  Remove the oldest checkpoints until the rest fits
+  drop_transcripts_after_days  Remove transcripts of older checkpoints, keeping
+                               their metadata, summaries, prompts and context
+  protected_branches           Never prune checkpoints referenced from commits
+                               on these branches (default: the default branch)
+
+Transcripts that newer delta transcripts build on are always kept, along with
+their checkpoints, even when that leaves more than max_size_mb. The preview
+lists such checkpoints and the size that remains.
+
+The branch history is rewritten so the pruned data is no longer referenced.
+The next 'git push' force-pushes the rewritten branch to each remote, keeping
+checkpoints pushed by others in the meantime.
+
+Only this clone knows about the prune. Another clone that still has the
+pruned checkpoints merges them back into the remote branch when it next
+pushes, so run 'entire prune --force' there too with the same policy.
+
+Default: shows a preview with the bytes that would be reclaimed.
+With --force, actually rewrites the branch.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runPrune(context.Background(), cmd.OutOrStdout(), forceFlag)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&forceFlag, "force", "f", false, "Actually rewrite the branch (default: dry run)")
+
+	return cmd
+}
+
+func runPrune(ctx context.Context, w io.Writer, force bool) error {
+	s, err := settings.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load settings: %w", err)
+	}
+	policy := s.Retention()
+	if policy.IsEmpty() {
+		fmt.Fprintln(w, "No retention policy configured.")
+		fmt.Fprintln(w, "Set strategy_options.retention in .entire/settings.json (see 'entire prune --help').")
+		return nil
+	}
+
+	repo, err := openRepository()
+	if err != nil {
+		return fmt.Errorf("not a git repository: %w", err)
+	}
+	store := checkpoint.NewGitStore(repo)
+
+	branches := policy.ProtectedBranches
+	if len(branches) == 0 {
+		if defaultBranch := strategy.GetDefaultBranchName(repo); defaultBranch != "" {
+			branches = []string{defaultBranch}
+		}
+	}
+	protected, err := protectedCheckpoints(ctx, repo, store, branches)
+	if err != nil {
+		return err
+	}
+
+	result, err := store.Prune(ctx, checkpoint.PruneOptions{
+		MaxAge:               days(policy.MaxAgeDays),
+		MaxSize:              int64(policy.MaxSizeMB) * 1024 * 1024,
+		DropTranscriptsAfter: days(policy.DropTranscriptsAfterDays),
+		Protected:            protected,
+		DryRun:               !force,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to prune checkpoints: %w", err)
+	}
+
+	if force && !result.Plan.IsEmpty() {
+		if err := strategy.RecordPrunedMetadataBranch(paths.MetadataBranchName, result.OldTip, result.Plan); err != nil {
+			return fmt.Errorf("failed to record pruned branch: %w", err)
+		}
+	}
+
+	printPruneResult(w, result, len(protected), int64(policy.MaxSizeMB)*1024*1024, force)
+	return nil
+}
+
+// days converts a number of days from the settings to a duration.
+func days(n int) time.Duration {
+	return time.Duration(n) * 24 * time.Hour
+}
+
+// protectedCheckpoints returns the checkpoints referenced from commits on the
+// given branches, local or on origin.
+func protectedCheckpoints(ctx context.Context, repo *git.Repository, store *checkpoint.GitStore, branches []string) (map[id.CheckpointID]bool, error) {
+	protected := make(map[id.CheckpointID]bool)
+	seen := make(map[plumbing.Hash]bool)
+	for _, branch := range branches {
+		for _, refName := range []plumbing.ReferenceName{
+			plumbing.NewBranchReferenceName(branch),
+			plumbing.NewRemoteReferenceName("origin", branch),
+		} {
+			ref, err := repo.Reference(refName, true)
+			if err != nil || seen[ref.Hash()] {
+				continue
+			}
+			seen[ref.Hash()] = true
+
+			iter, err := repo.Log(&git.LogOptions{From: ref.Hash()})
+			if err != nil {
+				return nil, fmt.Errorf("failed to get log of %s: %w", refName.Short(), err)
+			}
+			err = iter.ForEach(func(c *object.Commit) error {
+				if cpID, found := store.CheckpointIDForCommit(ctx, c); found {
+					protected[cpID] = true
+				}
+				return nil
+			})
+			iter.Close()
+			if err != nil {
+				return nil, fmt.Errorf("failed to walk %s: %w", refName.Short(), err)
+			}
+		}
+	}
+	return protected, nil
+}
+
+func printPruneResult(w io.Writer, result *checkpoint.PruneResult, protected int, maxSize int64, applied bool) {
+	if result.Plan.IsEmpty() {
+		fmt.Fprintf(w, "Nothing to prune (%d checkpoints, %s).\n", result.Checkpoints, formatBytes(result.Size))
+		if maxSize > 0 && result.Size > maxSize {
+			fmt.Fprintln(w, "The checkpoints are over max_size_mb, but are protected or delta bases of newer transcripts.")
+		}
+		return
+	}
+
+	if len(result.Plan.Remove) > 0 {
+		fmt.Fprintf(w, "Remove checkpoints (%d):\n", len(result.Plan.Remove))
+		for _, cpID := range result.Plan.Remove {
+			fmt.Fprintf(w, "  %s\n", cpID)
+		}
+		fmt.Fprintln(w)
+	}
+	if len(result.Plan.DropTranscripts) > 0 {
+		fmt.Fprintf(w, "Drop transcripts (%d):\n", len(result.Plan.DropTranscripts))
+		for _, cpID := range result.Plan.DropTranscripts {
+			fmt.Fprintf(w, "  %s\n", cpID)
+		}
+		fmt.Fprintln(w)
+	}
+	if len(result.KeptBases) > 0 {
+		fmt.Fprintf(w, "Kept as delta bases of newer transcripts (%d):\n", len(result.KeptBases))
+		for _, cpID := range result.KeptBases {
+			fmt.Fprintf(w, "  %s\n", cpID)
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "Checkpoints: %d (%s), %d protected\n", result.Checkpoints, formatBytes(result.Size), protected)
+	fmt.Fprintf(w, "Remaining: %s", formatBytes(result.RetainedSize))
+	if maxSize > 0 && result.RetainedSize > maxSize {
+		fmt.Fprintf(w, " (over max_size_mb; protected checkpoints and delta bases are kept)")
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Reclaimable: %s across %d rewritten commits\n", formatBytes(result.BytesReclaimed), result.CommitsRewritten)
+	fmt.Fprintln(w)
+
+	if !applied {
+		fmt.Fprintln(w, "Run with --force to rewrite "+paths.MetadataBranchName+".")
+		return
+	}
+	fmt.Fprintln(w, "Rewrote "+paths.MetadataBranchName+".")
+	fmt.Fprintln(w, "The next 'git push' force-pushes it; space is freed once 'git gc' runs.")
+}
+
+// formatBytes formats a byte count for display, e.g. "1.5 MB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit && exp < 3; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGT"[exp])
+}
@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
@@ -14,7 +15,6 @@ import (
 	"github.com/entireio/cli/cmd/entire/cli/logging"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/strategy"
-	"github.com/entireio/cli/cmd/entire/cli/trailers"
 
 	"github.com/charmbracelet/huh"
 	"github.com/go-git/go-git/v5"
@@ -25,6 +25,7 @@ import (
 
 func newResumeCmd() *cobra.Command {
 	var force bool
+	var agentName string
 
 	cmd := &cobra.Command{
 		Use:   "resume <branch>",
@@ -42,27 +43,43 @@ to fetch it.
 
 If newer commits without checkpoints exist on the branch (e.g., after merging main
 or cherry-picking from elsewhere), this operation will reset your Git status to the
-most recent commit with a checkpoint.  You'll be prompted to confirm resuming in this case.`,
+most recent commit with a checkpoint.  You'll be prompted to confirm resuming in this case.
+
+With --agent, the sessions are restored for the given agent instead of the one
+that recorded them: each transcript is converted through Entire's normalized
+transcript model (tool calls become text), so a Claude Code session can be
+continued in Gemini CLI and vice versa.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if checkDisabledGuard(cmd.OutOrStdout()) {
 				return nil
 			}
-			return runResume(args[0], force)
+			var target agent.Agent
+			if agentName != "" {
+				ag, err := agent.Get(agent.AgentName(agentName))
+				if err != nil {
+					return fmt.Errorf("unknown agent %q: %w", agentName, err)
+				}
+				target = ag
+			}
+			return runResume(args[0], force, target)
 		},
 	}
 
 	cmd.Flags().BoolVarP(&force, "force", "f", false, "Resume from older checkpoint without confirmation")
+	cmd.Flags().StringVar(&agentName, "agent", "", "Resume the session in another agent (e.g. gemini, claude-code)")
 
 	return cmd
 }
 
-func runResume(branchName string, force bool) error {
+// runResume switches to a branch and resumes its session.
+// If target is non-nil, the session is converted for and resumed in that agent.
+func runResume(branchName string, force bool, target agent.Agent) error {
 	// Check if we're already on this branch
 	currentBranch, err := GetCurrentBranch()
 	if err == nil && currentBranch == branchName {
 		// Already on the branch, skip checkout
-		return resumeFromCurrentBranch(branchName, force)
+		return resumeFromCurrentBranch(branchName, force, target)
 	}
 
 	// Check if branch exists locally
@@ -116,10 +133,10 @@ func runResume(branchName string, force bool) error {
 		fmt.Fprintf(os.Stderr, "Switched to branch '%s'\n", branchName)
 	}
 
-	return resumeFromCurrentBranch(branchName, force)
+	return resumeFromCurrentBranch(branchName, force, target)
 }
 
-func resumeFromCurrentBranch(branchName string, force bool) error {
+func resumeFromCurrentBranch(branchName string, force bool, target agent.Agent) error {
 	repo, err := openRepository()
 	if err != nil {
 		return fmt.Errorf("not a git repository: %w", err)
@@ -158,17 +175,17 @@ func resumeFromCurrentBranch(branchName string, force bool) error {
 	metadataTree, err := strategy.GetMetadataBranchTree(repo)
 	if err != nil {
 		// No local metadata branch, check if remote has it
-		return checkRemoteMetadata(repo, checkpointID)
+		return checkRemoteMetadata(repo, checkpointID, target)
 	}
 
 	// Look up metadata from sharded path
 	metadata, err := strategy.ReadCheckpointMetadata(metadataTree, checkpointID.Path())
 	if err != nil {
 		// Checkpoint exists in commit but no local metadata - check remote
-		return checkRemoteMetadata(repo, checkpointID)
+		return checkRemoteMetadata(repo, checkpointID, target)
 	}
 
-	return resumeSession(metadata.SessionID, checkpointID, force)
+	return resumeSession(metadata.SessionID, checkpointID, force, target)
 }
 
 // branchCheckpointResult contains the result of searching for a checkpoint on a branch.
@@ -185,6 +202,7 @@ type branchCheckpointResult struct {
 // This handles the case where main has been merged into the feature branch.
 func findBranchCheckpoint(repo *git.Repository, branchName string) (*branchCheckpointResult, error) {
 	result := &branchCheckpointResult{}
+	store := checkpoint.NewGitStore(repo)
 
 	// Get HEAD commit
 	head, err := repo.Head()
@@ -198,7 +216,7 @@ func findBranchCheckpoint(repo *git.Repository, branchName string) (*branchCheck
 	}
 
 	// First, check if HEAD itself has a checkpoint (most common case)
-	if cpID, found := trailers.ParseCheckpoint(headCommit.Message); found {
+	if cpID, found := store.CheckpointIDForCommit(context.Background(), headCommit); found {
 		result.checkpointID = cpID
 		result.commitHash = head.Hash().String()
 		result.commitMessage = headCommit.Message
@@ -221,39 +239,40 @@ func findBranchCheckpoint(repo *git.Repository, branchName string) (*branchCheck
 
 	// If we can't find a default branch, or we're on it, just walk all commits
 	if defaultBranch == "" || defaultBranch == branchName {
-		return findCheckpointInHistory(headCommit, nil), nil
+		return findCheckpointInHistory(store, headCommit, nil), nil
 	}
 
 	// Get the default branch reference
 	defaultRef, err := repo.Reference(plumbing.NewBranchReferenceName(defaultBranch), true)
 	if err != nil {
 		// Default branch doesn't exist locally, fall back to walking all commits
-		return findCheckpointInHistory(headCommit, nil), nil //nolint:nilerr // Intentional fallback
+		return findCheckpointInHistory(store, headCommit, nil), nil //nolint:nilerr // Intentional fallback
 	}
 
 	defaultCommit, err := repo.CommitObject(defaultRef.Hash())
 	if err != nil {
 		// Can't get default commit, fall back to walking all commits
-		return findCheckpointInHistory(headCommit, nil), nil //nolint:nilerr // Intentional fallback
+		return findCheckpointInHistory(store, headCommit, nil), nil //nolint:nilerr // Intentional fallback
 	}
 
 	// Find merge base
 	mergeBase, err := headCommit.MergeBase(defaultCommit)
 	if err != nil || len(mergeBase) == 0 {
 		// No common ancestor, fall back to walking all commits
-		return findCheckpointInHistory(headCommit, nil), nil //nolint:nilerr // Intentional fallback
+		return findCheckpointInHistory(store, headCommit, nil), nil //nolint:nilerr // Intentional fallback
 	}
 
 	// Walk from HEAD to merge base, looking for checkpoint
-	return findCheckpointInHistory(headCommit, &mergeBase[0].Hash), nil
+	return findCheckpointInHistory(store, headCommit, &mergeBase[0].Hash), nil
 }
 
-// findCheckpointInHistory walks commit history from start looking for a checkpoint trailer.
+// findCheckpointInHistory walks commit history from start looking for a checkpoint trailer
+// (or a link recorded when the commit was rewritten by amend/rebase).
 // If stopAt is provided, stops when reaching that commit (exclusive).
 // Returns the first checkpoint found and info about commits between HEAD and the checkpoint.
 // It distinguishes between merge commits (bringing in other branches) and regular commits
 // (actual branch work) to avoid false warnings after merging main.
-func findCheckpointInHistory(start *object.Commit, stopAt *plumbing.Hash) *branchCheckpointResult {
+func findCheckpointInHistory(store *checkpoint.GitStore, start *object.Commit, stopAt *plumbing.Hash) *branchCheckpointResult {
 	result := &branchCheckpointResult{}
 	branchWorkCommits := 0 // Regular commits without checkpoints (actual work)
 	const maxCommits = 100 // Limit search depth
@@ -267,7 +286,7 @@ func findCheckpointInHistory(start *object.Commit, stopAt *plumbing.Hash) *branc
 		}
 
 		// Check for checkpoint trailer
-		if cpID, found := trailers.ParseCheckpoint(current.Message); found {
+		if cpID, found := store.CheckpointIDForCommit(context.Background(), current); found {
 			result.checkpointID = cpID
 			result.commitHash = current.Hash.String()
 			result.commitMessage = current.Message
@@ -323,8 +342,17 @@ func promptResumeFromOlderCheckpoint() (bool, error) {
 }
 
 // checkRemoteMetadata checks if checkpoint metadata exists on origin/entire/checkpoints/v1
-// and automatically fetches it if available.
-func checkRemoteMetadata(repo *git.Repository, checkpointID id.CheckpointID) error {
+// (or on the checkpoint remote and ref from settings) and automatically fetches it if available.
+func checkRemoteMetadata(repo *git.Repository, checkpointID id.CheckpointID, target agent.Agent) error {
+	checkpointRemote := strategy.ResolveCheckpointRemote("origin")
+
+	// A plain git fetch only keeps origin's metadata branch up to date
+	if checkpointRemote.Remote != "origin" || !checkpointRemote.IsDefaultRef() {
+		if err := fetchRemoteMetadataBranch(checkpointRemote); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
 	// Try to get remote metadata branch tree
 	remoteTree, err := strategy.GetRemoteMetadataBranchTree(repo)
 	if err != nil {
@@ -341,21 +369,26 @@ func checkRemoteMetadata(repo *git.Repository, checkpointID id.CheckpointID) err
 	}
 
 	// Metadata exists on remote but not locally - fetch it automatically
-	fmt.Fprintf(os.Stderr, "Fetching session metadata from origin...\n")
+	fmt.Fprintf(os.Stderr, "Fetching session metadata from %s...\n", checkpointRemote.Remote)
 	if err := FetchMetadataBranch(); err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to fetch metadata: %v\n", err)
-		fmt.Fprintf(os.Stderr, "You can try manually: git fetch origin entire/checkpoints/v1:entire/checkpoints/v1\n")
+		fmt.Fprintf(os.Stderr, "You can try manually: git fetch %s %s:%s\n", checkpointRemote.Remote, checkpointRemote.Ref, plumbing.NewBranchReferenceName(paths.MetadataBranchName))
 		return NewSilentError(errors.New("failed to fetch metadata"))
 	}
 
 	// Now resume the session with the fetched metadata
-	return resumeSession(metadata.SessionID, checkpointID, false)
+	return resumeSession(metadata.SessionID, checkpointID, false, target)
 }
 
 // resumeSession restores and displays the resume command for a specific session.
 // For multi-session checkpoints, restores ALL sessions and shows commands for each.
 // If force is false, prompts for confirmation when local logs have newer timestamps.
-func resumeSession(sessionID string, checkpointID id.CheckpointID, force bool) error {
+// If target is non-nil, the sessions are converted for and resumed in that agent.
+func resumeSession(sessionID string, checkpointID id.CheckpointID, force bool, target agent.Agent) error {
+	if target != nil {
+		return resumeInAgent(target, checkpointID, force)
+	}
+
 	// Get the current agent (auto-detect or use default)
 	ag, err := agent.Detect()
 	if err != nil {
@@ -571,6 +604,150 @@ func resumeSingleSession(ctx context.Context, ag agent.Agent, sessionID string,
 	return nil
 }
 
+// resumeInAgent restores a checkpoint's sessions for the target agent, converting
+// transcripts recorded by other agents into its native format.
+// If force is false, prompts for confirmation when local logs have newer timestamps.
+func resumeInAgent(target agent.Agent, checkpointID id.CheckpointID, force bool) error {
+	builder, ok := target.(agent.TranscriptBuilder)
+	if !ok {
+		return fmt.Errorf("agent %s can't resume sessions recorded by other agents", target.Name())
+	}
+
+	ctx := logging.WithAgent(logging.WithComponent(context.Background(), "resume"), target.Name())
+	logging.Debug(ctx, "resume session in agent started",
+		slog.String("checkpoint_id", checkpointID.String()),
+	)
+
+	repoRoot, err := paths.RepoRoot()
+	if err != nil {
+		return fmt.Errorf("failed to get repository root: %w", err)
+	}
+	sessionDir, err := target.GetSessionDir(repoRoot)
+	if err != nil {
+		return fmt.Errorf("failed to determine session directory: %w", err)
+	}
+	if err := os.MkdirAll(sessionDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	repo, err := openRepository()
+	if err != nil {
+		return fmt.Errorf("not a git repository: %w", err)
+	}
+	store := checkpoint.NewGitStore(repo)
+	summary, err := store.ReadCommitted(ctx, checkpointID)
+	if err != nil {
+		return fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	if summary == nil {
+		return fmt.Errorf("checkpoint not found: %s", checkpointID)
+	}
+
+	type convertedSession struct {
+		entireSessionID string
+		source          agent.AgentType
+		session         *agent.AgentSession
+	}
+	var converted []convertedSession
+	var conflicts []strategy.SessionRestoreInfo
+	for i := range summary.Sessions {
+		content, err := store.ReadSessionContent(ctx, checkpointID, i)
+		if errors.Is(err, checkpoint.ErrEncrypted) {
+			return fmt.Errorf("failed to read session %d: %w", i, err)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to read session %d: %v\n", i, err)
+			continue
+		}
+		if len(content.Transcript) == 0 || content.Metadata.SessionID == "" {
+			continue
+		}
+
+		agentSessionID := target.ExtractAgentSessionID(content.Metadata.SessionID)
+		session, err := agent.ConvertSession(content.Metadata.Agent, content.Transcript, builder, agentSessionID, repoRoot)
+		if err != nil {
+			return fmt.Errorf("failed to convert session %s: %w", content.Metadata.SessionID, err)
+		}
+		session.SessionRef = builder.ResolveSessionFile(sessionDir, agentSessionID)
+		converted = append(converted, convertedSession{
+			entireSessionID: content.Metadata.SessionID,
+			source:          content.Metadata.Agent,
+			session:         session,
+		})
+
+		if !force {
+			localTime := lastEntryTimestamp(target, session.SessionRef)
+			var checkpointTime time.Time
+			if n := len(session.Entries); n > 0 {
+				checkpointTime = session.Entries[n-1].Timestamp
+			}
+			if status := strategy.ClassifyTimestamps(localTime, checkpointTime); status == strategy.StatusLocalNewer {
+				conflicts = append(conflicts, strategy.SessionRestoreInfo{
+					SessionID:      content.Metadata.SessionID,
+					Status:         status,
+					LocalTime:      localTime,
+					CheckpointTime: checkpointTime,
+				})
+			}
+		}
+	}
+	if len(converted) == 0 {
+		fmt.Fprintf(os.Stderr, "Checkpoint '%s' has no session logs to resume\n", checkpointID)
+		return nil
+	}
+
+	if len(conflicts) > 0 {
+		shouldOverwrite, promptErr := strategy.PromptOverwriteNewerLogs(conflicts)
+		if promptErr != nil {
+			return fmt.Errorf("failed to get confirmation: %w", promptErr)
+		}
+		if !shouldOverwrite {
+			fmt.Fprintf(os.Stderr, "Resume cancelled. Local session logs preserved.\n")
+			return nil
+		}
+	}
+
+	for _, c := range converted {
+		if err := target.WriteSession(c.session); err != nil {
+			return fmt.Errorf("failed to write session: %w", err)
+		}
+		if c.source != target.Type() {
+			fmt.Fprintf(os.Stderr, "Converted session %s from %s to %s: %s\n", c.entireSessionID, c.source, target.Type(), c.session.SessionRef)
+		} else {
+			fmt.Fprintf(os.Stderr, "Session restored to: %s\n", c.session.SessionRef)
+		}
+	}
+
+	logging.Debug(ctx, "resume session in agent completed",
+		slog.String("checkpoint_id", checkpointID.String()),
+		slog.Int("session_count", len(converted)),
+	)
+
+	fmt.Fprintf(os.Stderr, "\nTo continue, run:\n")
+	for i, c := range converted {
+		cmd := target.FormatResumeCommand(c.session.SessionID)
+		if len(converted) > 1 && i == len(converted)-1 {
+			fmt.Fprintf(os.Stderr, "  %s  # (most recent)\n", cmd)
+		} else {
+			fmt.Fprintf(os.Stderr, "  %s\n", cmd)
+		}
+	}
+	return nil
+}
+
+// lastEntryTimestamp returns the timestamp of the last entry of an agent's
+// local session file, or zero time if it doesn't exist or can't be read.
+func lastEntryTimestamp(ag agent.Agent, sessionRef string) time.Time {
+	if _, err := os.Stat(sessionRef); err != nil {
+		return time.Time{}
+	}
+	session, err := ag.ReadSession(&agent.HookInput{SessionRef: sessionRef})
+	if err != nil || len(session.Entries) == 0 {
+		return time.Time{}
+	}
+	return session.Entries[len(session.Entries)-1].Timestamp
+}
+
 func promptFetchFromRemote(branchName string) (bool, error) {
 	var confirmed bool
 
@@ -1,12 +1,15 @@
 package cli
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/sessionid"
@@ -174,7 +177,7 @@ func TestResumeFromCurrentBranch_NoCheckpoint(t *testing.T) {
 	setupResumeTestRepo(t, tmpDir, false)
 
 	// Run resumeFromCurrentBranch - should not error, just report no checkpoint found
-	err := resumeFromCurrentBranch("master", false)
+	err := resumeFromCurrentBranch("master", false, nil)
 	if err != nil {
 		t.Errorf("resumeFromCurrentBranch() returned error for commit without checkpoint: %v", err)
 	}
@@ -230,7 +233,7 @@ func TestResumeFromCurrentBranch_WithEntireCheckpointTrailer(t *testing.T) {
 	}
 
 	// Run resumeFromCurrentBranch
-	err := resumeFromCurrentBranch("master", false)
+	err := resumeFromCurrentBranch("master", false, nil)
 	if err != nil {
 		t.Errorf("resumeFromCurrentBranch() returned error: %v", err)
 	}
@@ -267,7 +270,7 @@ func TestRunResume_AlreadyOnBranch(t *testing.T) {
 	}
 
 	// Run resume on the branch we're already on - should skip checkout
-	err := runResume("feature", false)
+	err := runResume("feature", false, nil)
 	// Should not error (no session, but shouldn't error)
 	if err != nil {
 		t.Errorf("runResume() returned error when already on branch: %v", err)
@@ -281,7 +284,7 @@ func TestRunResume_BranchDoesNotExist(t *testing.T) {
 	setupResumeTestRepo(t, tmpDir, false)
 
 	// Run resume on a branch that doesn't exist
-	err := runResume("nonexistent", false)
+	err := runResume("nonexistent", false, nil)
 	if err == nil {
 		t.Error("runResume() expected error for nonexistent branch, got nil")
 	}
@@ -300,7 +303,7 @@ func TestRunResume_UncommittedChanges(t *testing.T) {
 	}
 
 	// Run resume - should fail due to uncommitted changes
-	err := runResume("feature", false)
+	err := runResume("feature", false, nil)
 	if err == nil {
 		t.Error("runResume() expected error for uncommitted changes, got nil")
 	}
@@ -503,7 +506,7 @@ func TestCheckRemoteMetadata_MetadataExistsOnRemote(t *testing.T) {
 	// Call checkRemoteMetadata - should find it on remote and attempt to fetch
 	// In this test environment without a real origin remote, the fetch will fail
 	// but it should return a SilentError (user-friendly error message already printed)
-	err = checkRemoteMetadata(repo, checkpointID)
+	err = checkRemoteMetadata(repo, checkpointID, nil)
 	if err == nil {
 		t.Error("checkRemoteMetadata() should return SilentError when fetch fails")
 	} else {
@@ -528,7 +531,7 @@ func TestCheckRemoteMetadata_NoRemoteMetadataBranch(t *testing.T) {
 	// Don't create any remote ref - simulating no remote entire/checkpoints/v1
 
 	// Call checkRemoteMetadata - should handle gracefully (no remote branch)
-	err := checkRemoteMetadata(repo, "nonexistent123")
+	err := checkRemoteMetadata(repo, "nonexistent123", nil)
 	if err != nil {
 		t.Errorf("checkRemoteMetadata() returned error when no remote branch: %v", err)
 	}
@@ -563,7 +566,7 @@ func TestCheckRemoteMetadata_CheckpointNotOnRemote(t *testing.T) {
 	}
 
 	// Call checkRemoteMetadata with a DIFFERENT checkpoint ID (not on remote)
-	err = checkRemoteMetadata(repo, "abcd12345678")
+	err = checkRemoteMetadata(repo, "abcd12345678", nil)
 	if err != nil {
 		t.Errorf("checkRemoteMetadata() returned error for missing checkpoint: %v", err)
 	}
@@ -624,7 +627,7 @@ func TestResumeFromCurrentBranch_FallsBackToRemote(t *testing.T) {
 	// Run resumeFromCurrentBranch - should fall back to remote and attempt fetch
 	// In this test environment without a real origin remote, the fetch will fail
 	// but it should return a SilentError (user-friendly error message already printed)
-	err = resumeFromCurrentBranch("master", false)
+	err = resumeFromCurrentBranch("master", false, nil)
 	if err == nil {
 		t.Error("resumeFromCurrentBranch() should return SilentError when fetch fails")
 	} else {
@@ -634,3 +637,70 @@ func TestResumeFromCurrentBranch_FallsBackToRemote(t *testing.T) {
 		}
 	}
 }
+
+func TestResumeInAgent_ConvertsClaudeSessionToGemini(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+	geminiDir := filepath.Join(tmpDir, "gemini-chats")
+	t.Setenv("ENTIRE_TEST_GEMINI_PROJECT_DIR", geminiDir)
+
+	repo, _, _ := setupResumeTestRepo(t, tmpDir, false)
+
+	sessionID := "f736da47-b2ca-4f86-bb32-a1bbe582e464"
+	checkpointID := id.MustCheckpointID("a1b2c3d4e5f6")
+	claudeTranscript := `{"type":"user","uuid":"u1","timestamp":"2026-01-01T10:00:00Z","message":{"content":"Add a README"}}
+{"type":"assistant","uuid":"a1","timestamp":"2026-01-01T10:00:05Z","message":{"model":"claude-sonnet-4","content":[{"type":"text","text":"Creating it."},{"type":"tool_use","id":"t1","name":"Write","input":{"file_path":"README.md"}}]}}
+{"type":"user","uuid":"u2","timestamp":"2026-01-01T10:00:06Z","message":{"content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]}}
+`
+	store := checkpoint.NewGitStore(repo)
+	if err := store.WriteCommitted(context.Background(), checkpoint.WriteCommittedOptions{
+		CheckpointID: checkpointID,
+		SessionID:    sessionID,
+		Strategy:     strategy.StrategyNameManualCommit,
+		Transcript:   []byte(claudeTranscript),
+		Agent:        agent.AgentTypeClaudeCode,
+		AuthorName:   "Test User",
+		AuthorEmail:  "test@example.com",
+	}); err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+
+	gemini, err := agent.Get("gemini")
+	if err != nil {
+		t.Fatalf("agent.Get(gemini) error = %v", err)
+	}
+	if err := resumeInAgent(gemini, checkpointID, true); err != nil {
+		t.Fatalf("resumeInAgent() error = %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(geminiDir, "session-*-f736da47.json"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one Gemini session file, got %v (err %v)", files, err)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("failed to read session file: %v", err)
+	}
+	entries, err := agent.ParseTranscriptEntries(agent.AgentTypeGemini, data)
+	if err != nil {
+		t.Fatalf("converted transcript is not a Gemini transcript: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2: %+v", len(entries), entries)
+	}
+	if entries[0].Type != agent.EntryUser || entries[0].Content != "Add a README" {
+		t.Errorf("entry 0 = %+v", entries[0])
+	}
+	want := "Creating it.\n\n[Tool call: Write] {\"file_path\":\"README.md\"}\n\n[Tool result] ok"
+	if entries[1].Content != want {
+		t.Errorf("entry 1 content = %q, want %q", entries[1].Content, want)
+	}
+
+	// Resuming again reuses the session file
+	if err := resumeInAgent(gemini, checkpointID, true); err != nil {
+		t.Fatalf("second resumeInAgent() error = %v", err)
+	}
+	if again, _ := filepath.Glob(filepath.Join(geminiDir, "*.json")); len(again) != 1 {
+		t.Errorf("expected the session file to be reused, got %v", again)
+	}
+}
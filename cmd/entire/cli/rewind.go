@@ -46,6 +46,9 @@ func newRewindCmd() *cobra.Command {
 	var toFlag string
 	var logsOnlyFlag bool
 	var resetFlag bool
+	var pathFlags []string
+	var withLogsFlag bool
+	var undoFlag bool
 
 	cmd := &cobra.Command{
 		Use:   "rewind",
@@ -54,7 +57,14 @@ func newRewindCmd() *cobra.Command {
 
 This command will show you an interactive list of recent checkpoints.  You'll be
 able to select one for Entire to rewind your branch state, including your code and
-your agent's context.`,
+your agent's context, or to restore only some of its files.
+
+With --path, only the files matching the given paths are restored (a file, a
+directory, or a glob where ** matches across directories). Other files, the
+session transcript and the shadow branch are left alone unless --with-logs is set.
+
+Every rewind first saves the current files and transcript. --undo puts them
+back, undoing the last rewind; running it again redoes the rewind.`,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			// Check if Entire is disabled
 			if checkDisabledGuard(cmd.OutOrStdout()) {
@@ -64,6 +74,21 @@ your agent's context.`,
 			if listFlag {
 				return runRewindList()
 			}
+			if undoFlag {
+				if toFlag != "" || len(pathFlags) > 0 || logsOnlyFlag || resetFlag || withLogsFlag {
+					return errors.New("--undo can't be combined with other rewind options")
+				}
+				return runRewindUndo()
+			}
+			if len(pathFlags) > 0 {
+				if toFlag == "" {
+					return errors.New("--path requires --to")
+				}
+				return runRewindPathsTo(toFlag, pathFlags, withLogsFlag)
+			}
+			if withLogsFlag {
+				return errors.New("--with-logs only applies with --path")
+			}
 			if toFlag != "" {
 				return runRewindToWithOptions(toFlag, logsOnlyFlag, resetFlag)
 			}
@@ -75,6 +100,9 @@ your agent's context.`,
 	cmd.Flags().StringVar(&toFlag, "to", "", "Rewind to specific commit ID (non-interactive)")
 	cmd.Flags().BoolVar(&logsOnlyFlag, "logs-only", false, "Only restore logs, don't modify working directory (for logs-only points)")
 	cmd.Flags().BoolVar(&resetFlag, "reset", false, "Reset branch to commit (destructive, for logs-only points)")
+	cmd.Flags().StringArrayVar(&pathFlags, "path", nil, "Only restore files matching this path or glob (repeatable, requires --to)")
+	cmd.Flags().BoolVar(&withLogsFlag, "with-logs", false, "With --path, also rewind the session transcript and shadow branch")
+	cmd.Flags().BoolVar(&undoFlag, "undo", false, "Undo the last rewind, restoring the files and transcript saved before it")
 
 	return cmd
 }
@@ -135,6 +163,9 @@ func runRewindInteractive() error { //nolint:maintidx // already present in code
 				shortID = shortID[:7]
 			}
 			label = fmt.Sprintf("%s (%s) %s%s", shortID, timestamp, sanitizeForTerminal(p.Message), sessionLabel)
+		case p.IsPreRewind:
+			// State saved before the last rewind
+			label = fmt.Sprintf("        (%s) [Undo] %s%s", timestamp, sanitizeForTerminal(p.Message), sessionLabel)
 		case p.IsTaskCheckpoint:
 			// Task checkpoint (uncommitted) - no sha shown
 			label = fmt.Sprintf("        (%s) [Task] %s%s", timestamp, sanitizeForTerminal(p.Message), sessionLabel)
@@ -190,6 +221,8 @@ func runRewindInteractive() error { //nolint:maintidx // already present in code
 	case selectedPoint.IsLogsOnly:
 		// Committed checkpoint - show sha
 		fmt.Printf("\nSelected: %s %s\n", shortID, sanitizeForTerminal(selectedPoint.Message))
+	case selectedPoint.IsPreRewind:
+		fmt.Printf("\nSelected: [Undo] %s\n", sanitizeForTerminal(selectedPoint.Message))
 	case selectedPoint.IsTaskCheckpoint:
 		// Task checkpoint - no sha
 		fmt.Printf("\nSelected: [Task] %s\n", sanitizeForTerminal(selectedPoint.Message))
@@ -203,6 +236,17 @@ func runRewindInteractive() error { //nolint:maintidx // already present in code
 		return handleLogsOnlyRewindInteractive(start, *selectedPoint, shortID)
 	}
 
+	// Offer to restore only some of the checkpoint's files
+	if pathRewinder, ok := start.(strategy.PathRewinder); ok && !selectedPoint.IsPreRewind {
+		picked, partial, err := pickRewindPaths(pathRewinder, *selectedPoint)
+		if err != nil {
+			return err
+		}
+		if partial {
+			return rewindPickedPaths(start, *selectedPoint, picked)
+		}
+	}
+
 	// Preview rewind to show warnings about files that will be deleted
 	preview, previewErr := start.PreviewRewind(*selectedPoint)
 	if previewErr == nil && preview != nil && len(preview.FilesToDelete) > 0 {
@@ -234,6 +278,10 @@ func runRewindInteractive() error { //nolint:maintidx // already present in code
 		return nil
 	}
 
+	if selectedPoint.IsPreRewind {
+		return undoRewindTo(start, *selectedPoint)
+	}
+
 	// Resolve agent once for use throughout
 	agent, err := getAgent(selectedPoint.Agent)
 	if err != nil {
@@ -299,9 +347,13 @@ func runRewindInteractive() error { //nolint:maintidx // already present in code
 	var restored bool
 	if !selectedPoint.CheckpointID.IsEmpty() {
 		// Try checkpoint storage first for committed checkpoints
-		if returnedSessionID, err := restoreSessionTranscriptFromStrategy(selectedPoint.CheckpointID, sessionID, agent); err == nil {
+		returnedSessionID, err := restoreSessionTranscriptFromStrategy(selectedPoint.CheckpointID, sessionID, agent)
+		switch {
+		case err == nil:
 			sessionID = returnedSessionID
 			restored = true
+		case errors.Is(err, checkpoint.ErrEncrypted):
+			fmt.Fprintf(os.Stderr, "Warning: can't restore the session transcript: %v\n", err)
 		}
 	}
 
@@ -343,6 +395,7 @@ func runRewindList() error {
 		IsTaskCheckpoint bool   `json:"is_task_checkpoint"`
 		ToolUseID        string `json:"tool_use_id,omitempty"`
 		IsLogsOnly       bool   `json:"is_logs_only"`
+		IsPreRewind      bool   `json:"is_pre_rewind"`
 		CondensationID   string `json:"condensation_id,omitempty"`
 		SessionID        string `json:"session_id,omitempty"`
 		SessionPrompt    string `json:"session_prompt,omitempty"`
@@ -358,6 +411,7 @@ func runRewindList() error {
 			IsTaskCheckpoint: p.IsTaskCheckpoint,
 			ToolUseID:        p.ToolUseID,
 			IsLogsOnly:       p.IsLogsOnly,
+			IsPreRewind:      p.IsPreRewind,
 			CondensationID:   p.CheckpointID.String(),
 			SessionID:        p.SessionID,
 			SessionPrompt:    p.SessionPrompt,
@@ -373,6 +427,18 @@ func runRewindList() error {
 	return nil
 }
 
+// findRewindPoint returns the point with the given ID, supporting both full
+// and short commit IDs. Returns nil if there is none.
+func findRewindPoint(points []strategy.RewindPoint, commitID string) *strategy.RewindPoint {
+	for _, p := range points {
+		if p.ID == commitID || (len(commitID) >= 7 && len(p.ID) >= 7 && strings.HasPrefix(p.ID, commitID)) {
+			pointCopy := p
+			return &pointCopy
+		}
+	}
+	return nil
+}
+
 func runRewindToWithOptions(commitID string, logsOnly bool, reset bool) error {
 	return runRewindToInternal(commitID, logsOnly, reset)
 }
@@ -397,16 +463,7 @@ func runRewindToInternal(commitID string, logsOnly bool, reset bool) error {
 		return fmt.Errorf("failed to find rewind points: %w", err)
 	}
 
-	// Find the matching point (support both full and short commit IDs)
-	var selectedPoint *strategy.RewindPoint
-	for _, p := range points {
-		if p.ID == commitID || (len(commitID) >= 7 && len(p.ID) >= 7 && strings.HasPrefix(p.ID, commitID)) {
-			pointCopy := p
-			selectedPoint = &pointCopy
-			break
-		}
-	}
-
+	selectedPoint := findRewindPoint(points, commitID)
 	if selectedPoint == nil {
 		return fmt.Errorf("rewind point not found: %s", commitID)
 	}
@@ -433,6 +490,10 @@ func runRewindToInternal(commitID string, logsOnly bool, reset bool) error {
 		fmt.Fprintf(os.Stderr, "\n")
 	}
 
+	if selectedPoint.IsPreRewind {
+		return undoRewindTo(start, *selectedPoint)
+	}
+
 	// Resolve agent once for use throughout
 	agent, err := getAgent(selectedPoint.Agent)
 	if err != nil {
@@ -462,31 +523,95 @@ func runRewindToInternal(commitID string, logsOnly bool, reset bool) error {
 		slog.String("checkpoint_id", selectedPoint.ID),
 	)
 
-	// Handle transcript restoration
+	restoreRewindTranscript(start, *selectedPoint, agent)
+	return nil
+}
+
+// runRewindUndo undoes the last rewind (--undo).
+func runRewindUndo() error {
+	start := GetStrategy()
+	undoer, ok := start.(strategy.RewindUndoer)
+	if !ok {
+		return errors.New("strategy does not support undoing rewinds")
+	}
+
+	point, err := undoer.GetPreRewindPoint()
+	if err != nil {
+		return fmt.Errorf("failed to find the state before the last rewind: %w", err)
+	}
+	if point == nil {
+		fmt.Println("Nothing to undo: no rewind since the last commit.")
+		return nil
+	}
+
+	// Preview to show warnings about files that will be deleted
+	preview, previewErr := start.PreviewRewind(*point)
+	if previewErr == nil && preview != nil && len(preview.FilesToDelete) > 0 {
+		fmt.Fprintf(os.Stderr, "\nWarning: The following files created since the rewind will be DELETED:\n")
+		for _, f := range preview.FilesToDelete {
+			fmt.Fprintf(os.Stderr, "  - %s\n", f)
+		}
+		fmt.Fprintf(os.Stderr, "\n")
+	}
+
+	return undoRewindTo(start, *point)
+}
+
+// undoRewindTo restores a pre-rewind point. The strategy puts back the files,
+// transcripts and shadow branch itself, so only the resume commands are left.
+func undoRewindTo(start strategy.Strategy, point strategy.RewindPoint) error {
+	ctx := logging.WithComponent(context.Background(), "rewind")
+	logging.Debug(ctx, "rewind undo started",
+		slog.String("checkpoint_id", point.ID),
+		slog.String("session_id", point.SessionID),
+	)
+
+	if err := start.Rewind(point); err != nil {
+		logging.Error(ctx, "rewind undo failed",
+			slog.String("checkpoint_id", point.ID),
+			slog.String("error", err.Error()),
+		)
+		return fmt.Errorf("failed to undo rewind: %w", err)
+	}
+
+	logging.Debug(ctx, "rewind undo completed",
+		slog.String("checkpoint_id", point.ID),
+	)
+
+	if agent, err := getAgent(point.Agent); err == nil {
+		printMultiSessionResumeCommands(point, agent)
+	}
+	return nil
+}
+
+// restoreRewindTranscript restores the session transcript of a rewind point
+// after its files were restored, and prints how to resume the session.
+// Failures are reported as warnings.
+func restoreRewindTranscript(start strategy.Strategy, point strategy.RewindPoint, agent agentpkg.Agent) {
 	var sessionID string
 	var transcriptFile string
 
-	if selectedPoint.IsTaskCheckpoint {
-		checkpoint, err := start.GetTaskCheckpoint(*selectedPoint)
+	if point.IsTaskCheckpoint {
+		checkpoint, err := start.GetTaskCheckpoint(point)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to read task checkpoint: %v\n", err)
-			return nil
+			return
 		}
 
 		sessionID = checkpoint.SessionID
 
 		if checkpoint.CheckpointUUID != "" {
 			// Use strategy-based transcript restoration for task checkpoints
-			if err := restoreTaskCheckpointTranscript(start, *selectedPoint, sessionID, checkpoint.CheckpointUUID, agent); err != nil {
+			if err := restoreTaskCheckpointTranscript(start, point, sessionID, checkpoint.CheckpointUUID, agent); err != nil {
 				fmt.Fprintf(os.Stderr, "Warning: failed to restore truncated session transcript: %v\n", err)
 			} else {
 				fmt.Printf("Rewound to task checkpoint. %s\n", formatResumeCommand(sessionID, agent))
 			}
-			return nil
+			return
 		}
 	} else {
-		sessionID = extractSessionIDFromMetadata(selectedPoint.MetadataDir)
-		transcriptFile = filepath.Join(selectedPoint.MetadataDir, paths.TranscriptFileNameLegacy)
+		sessionID = extractSessionIDFromMetadata(point.MetadataDir)
+		transcriptFile = filepath.Join(point.MetadataDir, paths.TranscriptFileNameLegacy)
 	}
 
 	// Try to restore transcript using the appropriate method:
@@ -494,17 +619,21 @@ func runRewindToInternal(commitID string, logsOnly bool, reset bool) error {
 	// 2. Shadow branch (uncommitted checkpoints with commit hash)
 	// 3. Local file (active sessions)
 	var restored bool
-	if !selectedPoint.CheckpointID.IsEmpty() {
+	if !point.CheckpointID.IsEmpty() {
 		// Try checkpoint storage first for committed checkpoints
-		if returnedSessionID, err := restoreSessionTranscriptFromStrategy(selectedPoint.CheckpointID, sessionID, agent); err == nil {
+		returnedSessionID, err := restoreSessionTranscriptFromStrategy(point.CheckpointID, sessionID, agent)
+		switch {
+		case err == nil:
 			sessionID = returnedSessionID
 			restored = true
+		case errors.Is(err, checkpoint.ErrEncrypted):
+			fmt.Fprintf(os.Stderr, "Warning: can't restore the session transcript: %v\n", err)
 		}
 	}
 
-	if !restored && selectedPoint.MetadataDir != "" && len(selectedPoint.ID) == 40 {
+	if !restored && point.MetadataDir != "" && len(point.ID) == 40 {
 		// Try shadow branch for uncommitted checkpoints (ID is a 40-char commit hash)
-		if returnedSessionID, err := restoreSessionTranscriptFromShadow(selectedPoint.ID, selectedPoint.MetadataDir, sessionID, agent); err == nil {
+		if returnedSessionID, err := restoreSessionTranscriptFromShadow(point.ID, point.MetadataDir, sessionID, agent); err == nil {
 			sessionID = returnedSessionID
 			restored = true
 		}
@@ -517,10 +646,165 @@ func runRewindToInternal(commitID string, logsOnly bool, reset bool) error {
 		}
 	}
 
-	fmt.Printf("Rewound to %s. %s\n", selectedPoint.ID[:7], formatResumeCommand(sessionID, agent))
+	fmt.Printf("Rewound to %s. %s\n", point.ID[:7], formatResumeCommand(sessionID, agent))
+}
+
+// runRewindPathsTo restores only the files matching patterns from a rewind
+// point (non-interactive --path mode).
+func runRewindPathsTo(commitID string, patterns []string, withLogs bool) error {
+	start := GetStrategy()
+
+	points, err := start.GetRewindPoints(20)
+	if err != nil {
+		return fmt.Errorf("failed to find rewind points: %w", err)
+	}
+
+	selectedPoint := findRewindPoint(points, commitID)
+	if selectedPoint == nil {
+		return fmt.Errorf("rewind point not found: %s", commitID)
+	}
+
+	return runRewindPaths(start, *selectedPoint, strategy.RewindPathSelection{Patterns: patterns}, withLogs)
+}
+
+// runRewindPaths restores the selected files from the point and, if withLogs
+// is set, the session transcript too.
+func runRewindPaths(start strategy.Strategy, point strategy.RewindPoint, paths strategy.RewindPathSelection, withLogs bool) error {
+	rewinder, ok := start.(strategy.PathRewinder)
+	if !ok {
+		return errors.New("strategy does not support restoring selected paths")
+	}
+
+	// Preview rewind to show warnings about files that will be deleted
+	preview, previewErr := rewinder.PreviewRewindPaths(point, paths)
+	if previewErr == nil && preview != nil && len(preview.FilesToDelete) > 0 {
+		fmt.Fprintf(os.Stderr, "\nWarning: The following untracked files will be DELETED:\n")
+		for _, f := range preview.FilesToDelete {
+			fmt.Fprintf(os.Stderr, "  - %s\n", f)
+		}
+		fmt.Fprintf(os.Stderr, "\n")
+	}
+
+	ctx := logging.WithComponent(context.Background(), "rewind")
+	logging.Debug(ctx, "path rewind started",
+		slog.String("checkpoint_id", point.ID),
+		slog.String("session_id", point.SessionID),
+		slog.Any("patterns", paths.Patterns),
+		slog.Any("files", paths.Files),
+		slog.Bool("with_logs", withLogs),
+	)
+
+	if err := rewinder.RewindPaths(point, paths, withLogs); err != nil {
+		logging.Error(ctx, "path rewind failed",
+			slog.String("checkpoint_id", point.ID),
+			slog.String("error", err.Error()),
+		)
+		return fmt.Errorf("failed to restore paths: %w", err)
+	}
+
+	logging.Debug(ctx, "path rewind completed",
+		slog.String("checkpoint_id", point.ID),
+	)
+
+	if !withLogs {
+		return nil
+	}
+	if point.IsLogsOnly {
+		return handleLogsOnlyRestore(start, point)
+	}
+	agent, err := getAgent(point.Agent)
+	if err != nil {
+		return fmt.Errorf("failed to get agent: %w", err)
+	}
+	restoreRewindTranscript(start, point, agent)
 	return nil
 }
 
+// pickRewindPaths asks whether to restore everything from the point or only
+// some files, and lets the user pick them. Returns partial=false to restore
+// everything.
+func pickRewindPaths(rewinder strategy.PathRewinder, point strategy.RewindPoint) (picked []string, partial bool, err error) {
+	var scope string
+	form := NewAccessibleForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("What do you want to restore?").
+				Options(
+					huh.NewOption("Everything (files and session)", "all"),
+					huh.NewOption("Only some files", "paths"),
+				).
+				Value(&scope),
+		),
+	)
+	if err := form.Run(); err != nil {
+		return nil, false, fmt.Errorf("selection cancelled: %w", err)
+	}
+	if scope != "paths" {
+		return nil, false, nil
+	}
+
+	picked, err = selectRewindPaths(rewinder, point)
+	return picked, true, err
+}
+
+// selectRewindPaths lets the user pick which of the files the point would
+// change to restore.
+func selectRewindPaths(rewinder strategy.PathRewinder, point strategy.RewindPoint) ([]string, error) {
+	changes, err := rewinder.RewindPointChanges(point)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changed files: %w", err)
+	}
+	if len(changes) == 0 {
+		fmt.Println("No files differ from this checkpoint.")
+		return nil, nil
+	}
+
+	options := make([]huh.Option[string], 0, len(changes))
+	for _, f := range changes {
+		options = append(options, huh.NewOption(sanitizeForTerminal(f), f))
+	}
+
+	var picked []string
+	form := NewAccessibleForm(
+		huh.NewGroup(
+			huh.NewMultiSelect[string]().
+				Title("Select files to restore").
+				Description("Files that differ from this checkpoint. Unselected files are left as they are.").
+				Options(options...).
+				Value(&picked),
+		),
+	)
+	if err := form.Run(); err != nil {
+		return nil, fmt.Errorf("selection cancelled: %w", err)
+	}
+	return picked, nil
+}
+
+// rewindPickedPaths asks whether to restore the session transcript along with
+// the picked files, then restores them.
+func rewindPickedPaths(start strategy.Strategy, point strategy.RewindPoint, picked []string) error {
+	if len(picked) == 0 {
+		fmt.Println("No files selected. Rewind cancelled.")
+		return nil
+	}
+
+	var withLogs bool
+	form := NewAccessibleForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title("Also restore the session transcript?").
+				Description("The agent's context goes back to this checkpoint too. Other files are left as they are either way.").
+				Value(&withLogs),
+		),
+	)
+	if err := form.Run(); err != nil {
+		return fmt.Errorf("confirmation cancelled: %w", err)
+	}
+
+	// Picked files are exact paths, not patterns
+	return runRewindPaths(start, point, strategy.RewindPathSelection{Files: picked}, withLogs)
+}
+
 // handleLogsOnlyRewindNonInteractive handles logs-only rewind in non-interactive mode.
 // Defaults to restoring logs only (no checkout) for safety.
 func handleLogsOnlyRewindNonInteractive(start strategy.Strategy, point strategy.RewindPoint) error {
@@ -610,6 +894,8 @@ func handleLogsOnlyResetNonInteractive(start strategy.Strategy, point strategy.R
 		return fmt.Errorf("failed to reset branch: %w", err)
 	}
 
+	keepPreRewindPointAfterReset(start, currentHead)
+
 	logging.Debug(ctx, "logs-only reset completed",
 		slog.String("checkpoint_id", point.ID),
 	)
@@ -840,6 +1126,7 @@ func handleLogsOnlyRewindInteractive(start strategy.Strategy, point strategy.Rew
 				Description("This commit has session logs but no checkpoint state. Choose an action:").
 				Options(
 					huh.NewOption("Restore logs only (keep current files)", "logs"),
+					huh.NewOption("Restore some files from this commit", "paths"),
 					huh.NewOption("Checkout commit (detached HEAD, for viewing)", "checkout"),
 					huh.NewOption("Reset branch to this commit (destructive!)", "reset"),
 					huh.NewOption("Cancel", "cancel"),
@@ -855,6 +1142,16 @@ func handleLogsOnlyRewindInteractive(start strategy.Strategy, point strategy.Rew
 	switch action {
 	case "logs":
 		return handleLogsOnlyRestore(start, point)
+	case "paths":
+		rewinder, ok := start.(strategy.PathRewinder)
+		if !ok {
+			return errors.New("strategy does not support restoring selected paths")
+		}
+		picked, err := selectRewindPaths(rewinder, point)
+		if err != nil {
+			return err
+		}
+		return rewindPickedPaths(start, point, picked)
 	case "checkout":
 		return handleLogsOnlyCheckout(start, point, shortID)
 	case "reset":
@@ -1070,6 +1367,8 @@ func handleLogsOnlyReset(start strategy.Strategy, point strategy.RewindPoint, sh
 		return fmt.Errorf("failed to reset branch: %w", err)
 	}
 
+	keepPreRewindPointAfterReset(start, currentHead)
+
 	logging.Debug(ctx, "logs-only reset (interactive) completed",
 		slog.String("checkpoint_id", point.ID),
 	)
@@ -1191,6 +1490,19 @@ func countCommitsBetween(repo *git.Repository, ancestor, descendant plumbing.Has
 	return -1, nil
 }
 
+// keepPreRewindPointAfterReset keeps the state saved before a logs-only
+// rewind undoable after the branch was reset from previousHead, so
+// 'entire rewind --undo' finds it and moves HEAD back. Failures are warnings.
+func keepPreRewindPointAfterReset(start strategy.Strategy, previousHead string) {
+	undoer, ok := start.(strategy.RewindUndoer)
+	if !ok || previousHead == "" {
+		return
+	}
+	if err := undoer.MovePreRewindPoint(previousHead); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: the reset can't be undone with 'entire rewind --undo': %v\n", err)
+	}
+}
+
 // performGitResetHard performs a git reset --hard to the specified commit.
 // Uses the git CLI instead of go-git because go-git's HardReset incorrectly
 // deletes untracked directories (like .entire/) even when they're in .gitignore.
@@ -72,6 +72,7 @@ func NewRootCmd() *cobra.Command {
 	cmd.AddCommand(newRewindCmd())
 	cmd.AddCommand(newResumeCmd())
 	cmd.AddCommand(newCleanCmd())
+	cmd.AddCommand(newPruneCmd())
 	cmd.AddCommand(newResetCmd())
 	cmd.AddCommand(newEnableCmd())
 	cmd.AddCommand(newDisableCmd())
@@ -79,8 +80,13 @@ func NewRootCmd() *cobra.Command {
 	cmd.AddCommand(newHooksCmd())
 	cmd.AddCommand(newVersionCmd())
 	cmd.AddCommand(newExplainCmd())
+	cmd.AddCommand(newDiffCmd())
+	cmd.AddCommand(newCheckoutCmd())
+	cmd.AddCommand(newForkCmd())
 	cmd.AddCommand(newDebugCmd())
 	cmd.AddCommand(newDoctorCmd())
+	cmd.AddCommand(newFsckCmd())
+	cmd.AddCommand(newWatchCmd())
 	cmd.AddCommand(newSendAnalyticsCmd())
 	cmd.AddCommand(newCurlBashPostInstallCmd())
 
@@ -118,6 +118,12 @@ type State struct {
 	// PendingPromptAttribution holds attribution calculated at prompt start (before agent runs).
 	// This is moved to PromptAttributions when SaveChanges is called.
 	PendingPromptAttribution *PromptAttribution `json:"pending_prompt_attribution,omitempty"`
+
+	// ParentSessionID is the session this session was forked from (entire fork), and
+	// ParentCheckpoint the checkpoint ID or shadow commit SHA it was forked at.
+	// Carried into the metadata of the session's committed checkpoints.
+	ParentSessionID  string `json:"parent_session_id,omitempty"`
+	ParentCheckpoint string `json:"parent_checkpoint,omitempty"`
 }
 
 // PromptAttribution captures line-level attribution data at the start of each prompt.
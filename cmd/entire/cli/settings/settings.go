@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
@@ -255,6 +256,155 @@ func (s *EntireSettings) IsPushSessionsDisabled() bool {
 	return false
 }
 
+// CheckpointRemote returns the remote (name or URL) checkpoints are pushed to
+// and fetched from, configured with strategy_options.checkpoint_remote.
+// Returns "" to use the remote code is pushed to (the default).
+func (s *EntireSettings) CheckpointRemote() string {
+	if s.StrategyOptions == nil {
+		return ""
+	}
+	remote, ok := s.StrategyOptions["checkpoint_remote"].(string)
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(remote)
+}
+
+// CheckpointRef returns the ref the metadata branch is stored at on the
+// checkpoint remote, configured with strategy_options.checkpoint_ref (e.g.
+// "refs/entire/checkpoints"). Returns "" to store it as the
+// entire/checkpoints/v1 branch (the default) or if the value isn't a full ref.
+func (s *EntireSettings) CheckpointRef() string {
+	if s.StrategyOptions == nil {
+		return ""
+	}
+	ref, ok := s.StrategyOptions["checkpoint_ref"].(string)
+	if !ok || !strings.HasPrefix(ref, "refs/") {
+		return ""
+	}
+	return strings.TrimSuffix(ref, "/")
+}
+
+// TranscriptCompression returns the compression for transcripts written to the
+// metadata branch ("zstd", "gzip"), or "" to store them uncompressed (the default).
+func (s *EntireSettings) TranscriptCompression() string {
+	if s.StrategyOptions == nil {
+		return ""
+	}
+	compression, ok := s.StrategyOptions["transcript_compression"].(string)
+	if !ok || compression == "none" {
+		return ""
+	}
+	return compression
+}
+
+// EncryptionRecipients returns the age X25519 recipients checkpoint
+// transcripts, prompts and context are encrypted to, configured under
+// strategy_options.encryption:
+//
+//	"encryption": {"recipients": ["age1...", "age1..."]}
+//
+// Returns nil if encryption isn't configured.
+func (s *EntireSettings) EncryptionRecipients() []string {
+	if s.StrategyOptions == nil {
+		return nil
+	}
+	encryptionOpts, ok := s.StrategyOptions["encryption"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	values, ok := encryptionOpts["recipients"].([]any)
+	if !ok {
+		return nil
+	}
+	var recipients []string
+	for _, v := range values {
+		if recipient, ok := v.(string); ok && recipient != "" {
+			recipients = append(recipients, recipient)
+		}
+	}
+	return recipients
+}
+
+// IsCommitNotesEnabled checks if commits are linked to checkpoints through
+// notes on refs/notes/entire instead of the Entire-Checkpoint trailer, i.e.
+// strategy_options.commit_linking is "notes". Defaults to trailers.
+func (s *EntireSettings) IsCommitNotesEnabled() bool {
+	if s.StrategyOptions == nil {
+		return false
+	}
+	linking, ok := s.StrategyOptions["commit_linking"].(string)
+	return ok && linking == "notes"
+}
+
+// IsTranscriptDeltaEnabled checks if delta transcript storage is enabled, i.e.
+// checkpoints only store the transcript lines added since the session's previous checkpoint.
+// Returns false by default.
+func (s *EntireSettings) IsTranscriptDeltaEnabled() bool {
+	if s.StrategyOptions == nil {
+		return false
+	}
+	enabled, ok := s.StrategyOptions["transcript_delta"].(bool)
+	return ok && enabled
+}
+
+// RetentionPolicy controls which checkpoints `entire prune` removes from the
+// metadata branch. Zero values disable the corresponding rule.
+type RetentionPolicy struct {
+	// MaxAgeDays removes checkpoints older than this many days
+	MaxAgeDays int
+
+	// MaxSizeMB removes the oldest checkpoints until the checkpoint data fits in this many megabytes
+	MaxSizeMB int
+
+	// DropTranscriptsAfterDays removes the transcripts of checkpoints older than
+	// this many days, keeping their metadata, summaries, prompts and context
+	DropTranscriptsAfterDays int
+
+	// ProtectedBranches lists branches whose checkpoints are never pruned.
+	// Defaults to the repository's default branch.
+	ProtectedBranches []string
+}
+
+// IsEmpty reports whether the policy has no rule that prunes anything.
+func (p RetentionPolicy) IsEmpty() bool {
+	return p.MaxAgeDays <= 0 && p.MaxSizeMB <= 0 && p.DropTranscriptsAfterDays <= 0
+}
+
+// Retention returns the retention policy configured under strategy_options.retention:
+//
+//	"retention": {"max_age_days": 180, "max_size_mb": 500, "drop_transcripts_after_days": 30, "protected_branches": ["main"]}
+func (s *EntireSettings) Retention() RetentionPolicy {
+	var policy RetentionPolicy
+	if s.StrategyOptions == nil {
+		return policy
+	}
+	retentionOpts, ok := s.StrategyOptions["retention"].(map[string]any)
+	if !ok {
+		return policy
+	}
+
+	intOption := func(key string) int {
+		// JSON numbers decode to float64
+		if v, ok := retentionOpts[key].(float64); ok && v > 0 {
+			return int(v)
+		}
+		return 0
+	}
+	policy.MaxAgeDays = intOption("max_age_days")
+	policy.MaxSizeMB = intOption("max_size_mb")
+	policy.DropTranscriptsAfterDays = intOption("drop_transcripts_after_days")
+
+	if branches, ok := retentionOpts["protected_branches"].([]any); ok {
+		for _, b := range branches {
+			if name, ok := b.(string); ok && name != "" {
+				policy.ProtectedBranches = append(policy.ProtectedBranches, name)
+			}
+		}
+	}
+	return policy
+}
+
 // Save saves the settings to .entire/settings.json.
 func Save(settings *EntireSettings) error {
 	return saveToFile(settings, EntireSettingsFile)
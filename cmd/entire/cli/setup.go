@@ -53,6 +53,7 @@ func newEnableCmd() *cobra.Command {
 	var forceHooks bool
 	var skipPushSessions bool
 	var telemetry bool
+	var userConfigHooks bool
 
 	cmd := &cobra.Command{
 		Use:   "enable",
@@ -88,7 +89,7 @@ Strategies: manual-commit (default), auto-commit`,
 					printWrongAgentError(cmd.ErrOrStderr(), agentName)
 					return NewSilentError(errors.New("wrong agent name"))
 				}
-				return setupAgentHooksNonInteractive(cmd.OutOrStdout(), ag, strategyFlag, localDev, forceHooks, skipPushSessions, telemetry)
+				return setupAgentHooksNonInteractive(cmd.OutOrStdout(), ag, strategyFlag, localDev, forceHooks, skipPushSessions, telemetry, userConfigHooks)
 			}
 			// If strategy is specified via flag, skip interactive selection
 			if strategyFlag != "" {
@@ -109,6 +110,7 @@ Strategies: manual-commit (default), auto-commit`,
 	cmd.Flags().BoolVarP(&forceHooks, "force", "f", false, "Force reinstall hooks (removes existing Entire hooks first)")
 	cmd.Flags().BoolVar(&skipPushSessions, "skip-push-sessions", false, "Disable automatic pushing of session logs on git push")
 	cmd.Flags().BoolVar(&telemetry, "telemetry", true, "Enable anonymous usage analytics")
+	cmd.Flags().BoolVar(&userConfigHooks, "user-config", false, "Allow installing hooks in the agent's user configuration, which applies to every repository (Codex)")
 	//nolint:errcheck,gosec // completion is optional, flag is defined above
 	cmd.RegisterFlagCompletionFunc("strategy", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
 		return []string{strategyDisplayManualCommit, strategyDisplayAutoCommit}, cobra.ShellCompDirectiveNoFileComp
@@ -146,7 +148,7 @@ show a disabled message.
 
 To completely remove Entire integrations from this repository, use --uninstall:
   - .entire/ directory (settings, logs, metadata)
-  - Git hooks (prepare-commit-msg, commit-msg, post-commit, pre-push)
+  - Git hooks (prepare-commit-msg, commit-msg, post-commit, post-rewrite, pre-push)
   - Session state files (.git/entire-sessions/)
   - Shadow branches (entire/<hash>)
   - Agent hooks (Claude Code, Gemini CLI)`,
@@ -537,7 +539,9 @@ func printWrongAgentError(w io.Writer, name string) {
 
 // setupAgentHooksNonInteractive sets up hooks for a specific agent non-interactively.
 // If strategyName is provided, it sets the strategy; otherwise uses default.
-func setupAgentHooksNonInteractive(w io.Writer, ag agent.Agent, strategyName string, localDev, forceHooks, skipPushSessions, telemetry bool) error {
+// Hooks that go in the agent's user configuration are only installed if
+// userConfigHooks is set.
+func setupAgentHooksNonInteractive(w io.Writer, ag agent.Agent, strategyName string, localDev, forceHooks, skipPushSessions, telemetry, userConfigHooks bool) error {
 	agentName := ag.Name()
 	// Check if agent supports hooks
 	hookAgent, ok := ag.(agent.HookSupport)
@@ -547,6 +551,15 @@ func setupAgentHooksNonInteractive(w io.Writer, ag agent.Agent, strategyName str
 
 	fmt.Fprintf(w, "Agent: %s\n\n", ag.Type())
 
+	// Hooks in the user configuration apply to every repository: don't add them unasked
+	if installer, ok := ag.(agent.UserConfigHookInstaller); ok && !userConfigHooks && !hookAgent.AreHooksInstalled() {
+		configPath, err := installer.HookConfigPath()
+		if err != nil {
+			return fmt.Errorf("failed to locate hook configuration for %s: %w", agentName, err)
+		}
+		return fmt.Errorf("%s hooks are installed in %s, which applies to every repository; run again with --user-config to install them there, or use 'entire watch' instead", ag.Type(), configPath)
+	}
+
 	// Install agent hooks (agent hooks don't depend on settings)
 	installedHooks, err := hookAgent.InstallHooks(localDev, forceHooks)
 	if err != nil {
@@ -609,13 +622,13 @@ func setupAgentHooksNonInteractive(w io.Writer, ag agent.Agent, strategyName str
 
 	if installedHooks == 0 {
 		msg := fmt.Sprintf("Hooks for %s already installed", ag.Description())
-		if agentName == agent.AgentNameGemini {
+		if agentName == agent.AgentNameGemini || agentName == agent.AgentNameCodex {
 			msg += " (Preview)"
 		}
 		fmt.Fprintf(w, "%s\n", msg)
 	} else {
 		msg := fmt.Sprintf("Installed %d hooks for %s", installedHooks, ag.Description())
-		if agentName == agent.AgentNameGemini {
+		if agentName == agent.AgentNameGemini || agentName == agent.AgentNameCodex {
 			msg += " (Preview)"
 		}
 		fmt.Fprintf(w, "%s\n", msg)
@@ -931,7 +944,7 @@ func runUninstall(w, errW io.Writer, force bool) error {
 			fmt.Fprintln(w, "  - .entire/ directory")
 		}
 		if gitHooksInstalled {
-			fmt.Fprintln(w, "  - Git hooks (prepare-commit-msg, commit-msg, post-commit, pre-push)")
+			fmt.Fprintln(w, "  - Git hooks (prepare-commit-msg, commit-msg, post-commit, post-rewrite, pre-push)")
 		}
 		if sessionStateCount > 0 {
 			fmt.Fprintf(w, "  - Session state files (%d)\n", sessionStateCount)
@@ -10,6 +10,7 @@ import (
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
 	_ "github.com/entireio/cli/cmd/entire/cli/agent/claudecode"
+	_ "github.com/entireio/cli/cmd/entire/cli/agent/codex"
 	_ "github.com/entireio/cli/cmd/entire/cli/agent/geminicli"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/strategy"
@@ -878,3 +879,33 @@ func TestEnableCmd_AgentFlagEmptyValue(t *testing.T) {
 		t.Error("should not contain default cobra/pflag error message")
 	}
 }
+
+func TestSetupAgentHooksNonInteractive_UserConfigNeedsOptIn(t *testing.T) {
+	setupTestRepo(t)
+	codexHome := t.TempDir()
+	t.Setenv("CODEX_HOME", codexHome)
+
+	ag, err := agent.Get(agent.AgentNameCodex)
+	if err != nil {
+		t.Fatalf("agent.Get(codex) error = %v", err)
+	}
+
+	err = setupAgentHooksNonInteractive(&bytes.Buffer{}, ag, "", false, false, false, false, false)
+	if err == nil || !strings.Contains(err.Error(), "--user-config") {
+		t.Fatalf("setupAgentHooksNonInteractive() error = %v, want one asking for --user-config", err)
+	}
+	if _, err := os.Stat(filepath.Join(codexHome, "config.toml")); !os.IsNotExist(err) {
+		t.Errorf("Codex config was written without --user-config, stat error = %v", err)
+	}
+
+	if err := setupAgentHooksNonInteractive(&bytes.Buffer{}, ag, "", false, false, false, false, true); err != nil {
+		t.Fatalf("setupAgentHooksNonInteractive(--user-config) error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(codexHome, "config.toml"))
+	if err != nil {
+		t.Fatalf("failed to read Codex config: %v", err)
+	}
+	if !strings.Contains(string(data), "notify") {
+		t.Errorf("Codex config = %q, want the notify hook", data)
+	}
+}
@@ -175,6 +175,54 @@ func CaptureGeminiPrePromptState(sessionID, transcriptPath string) error {
 	return nil
 }
 
+// CaptureWatchedPrePromptState captures current untracked files and transcript position
+// before a prompt for sessions detected by `entire watch` (agents without hooks).
+// startPosition is the agent's transcript position (see agent.TranscriptAnalyzer) where
+// the turn begins; lastIdentifier identifies the last entry before it.
+func CaptureWatchedPrePromptState(sessionID string, startPosition int, lastIdentifier string) error {
+	if sessionID == "" {
+		sessionID = unknownSessionID
+	}
+
+	// Get absolute path for tmp directory
+	tmpDirAbs, err := paths.AbsPath(paths.EntireTmpDir)
+	if err != nil {
+		tmpDirAbs = paths.EntireTmpDir // Fallback to relative
+	}
+
+	// Create tmp directory if it doesn't exist
+	if err := os.MkdirAll(tmpDirAbs, 0o750); err != nil {
+		return fmt.Errorf("failed to create tmp directory: %w", err)
+	}
+
+	// Get list of untracked files (excluding .entire directory itself)
+	untrackedFiles, err := getUntrackedFilesForState()
+	if err != nil {
+		return fmt.Errorf("failed to get untracked files: %w", err)
+	}
+
+	state := PrePromptState{
+		SessionID:                sessionID,
+		Timestamp:                time.Now().UTC().Format(time.RFC3339),
+		UntrackedFiles:           untrackedFiles,
+		LastTranscriptIdentifier: lastIdentifier,
+		StepTranscriptStart:      startPosition,
+	}
+
+	data, err := jsonutil.MarshalIndentWithNewline(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	if err := os.WriteFile(prePromptStateFile(sessionID), data, 0o600); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Captured state before prompt: %d untracked files, transcript at position %d\n",
+		len(untrackedFiles), startPosition)
+	return nil
+}
+
 // LoadPrePromptState loads previously captured state.
 // Returns nil if no state file exists.
 func LoadPrePromptState(sessionID string) (*PrePromptState, error) {
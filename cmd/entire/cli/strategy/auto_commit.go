@@ -120,6 +120,26 @@ func (s *AutoCommitStrategy) PrePush(remote string) error {
 	return pushSessionsBranchCommon(remote, paths.MetadataBranchName)
 }
 
+// PostRewrite is called by the git post-rewrite hook after an amend or rebase.
+// Auto-commit keeps no per-commit session state, so it only records commit → checkpoint
+// links for rewritten commits that lost their Entire-Checkpoint trailer.
+//
+//nolint:unparam // error return required by interface but hooks must return nil
+func (s *AutoCommitStrategy) PostRewrite(rewriteType string, rewrites []RewrittenCommit) error {
+	if len(rewrites) == 0 {
+		return nil
+	}
+	repo, err := OpenRepository()
+	if err != nil {
+		return nil //nolint:nilerr // Hook must be silent on failure
+	}
+	logCtx := logging.WithComponent(context.Background(), "checkpoint")
+	if _, err := recordCommitRewrites(logCtx, repo, rewriteType, rewrites); err != nil {
+		fmt.Fprintf(os.Stderr, "[entire] Warning: %v\n", err)
+	}
+	return nil
+}
+
 func (s *AutoCommitStrategy) SaveChanges(ctx SaveContext) error {
 	repo, err := OpenRepository()
 	if err != nil {
@@ -209,8 +229,12 @@ func (s *AutoCommitStrategy) commitCodeToActive(repo *git.Repository, ctx SaveCo
 	// Stage code changes
 	StageFiles(worktree, ctx.ModifiedFiles, ctx.NewFiles, ctx.DeletedFiles, StageForSession)
 
-	// Add checkpoint ID trailer to commit message
-	commitMsg := ctx.CommitMessage + "\n\n" + trailers.CheckpointTrailerKey + ": " + checkpointID.String()
+	// Add checkpoint ID trailer to commit message, unless commits are linked with notes
+	notesEnabled := isCommitNotesEnabled()
+	commitMsg := ctx.CommitMessage
+	if !notesEnabled {
+		commitMsg += "\n\n" + trailers.CheckpointTrailerKey + ": " + checkpointID.String()
+	}
 
 	author := &object.Signature{
 		Name:  ctx.AuthorName,
@@ -226,6 +250,9 @@ func (s *AutoCommitStrategy) commitCodeToActive(repo *git.Repository, ctx SaveCo
 	created := commitHash != headBefore.Hash()
 	if created {
 		fmt.Fprintf(os.Stderr, "Committed code changes to active branch (%s)\n", commitHash.String()[:7])
+		if notesEnabled {
+			linkCommitWithNote(context.Background(), repo, commitHash, checkpointID)
+		}
 	}
 	return commitCodeResult{CommitHash: commitHash, Created: created}, nil
 }
@@ -264,6 +291,8 @@ func (s *AutoCommitStrategy) commitMetadataToMetadataBranch(repo *git.Repository
 		TokenUsage:                  ctx.TokenUsage,
 		CheckpointsCount:            1,            // Each auto-commit checkpoint = 1
 		FilesTouched:                filesTouched, // Track modified files (same as manual-commit)
+		TranscriptCompression:       transcriptCompression(),
+		EncryptionRecipients:        encryptionRecipients(),
 	})
 	if err != nil {
 		return plumbing.ZeroHash, fmt.Errorf("failed to write committed checkpoint: %w", err)
@@ -294,6 +323,11 @@ func (s *AutoCommitStrategy) GetRewindPoints(limit int) ([]RewindPoint, error) {
 		return []RewindPoint{}, nil //nolint:nilerr // Expected when no metadata exists
 	}
 
+	store, err := s.getCheckpointStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checkpoint store: %w", err)
+	}
+
 	// Get the main branch commit hash to determine branch-only commits
 	mainBranchHash := GetMainBranchHash(repo)
 
@@ -315,8 +349,8 @@ func (s *AutoCommitStrategy) GetRewindPoints(limit int) ([]RewindPoint, error) {
 		}
 		count++
 
-		// Check for Entire-Checkpoint trailer
-		cpID, found := trailers.ParseCheckpoint(c.Message)
+		// Check for Entire-Checkpoint trailer or commit note
+		cpID, found := store.CheckpointIDForCommit(context.Background(), c)
 		if !found {
 			return nil
 		}
@@ -582,8 +616,12 @@ func (s *AutoCommitStrategy) commitTaskCodeToActive(repo *git.Repository, ctx Ta
 		subject = FormatSubagentEndMessage(ctx.SubagentType, ctx.TaskDescription, shortToolUseID)
 	}
 
-	// Add checkpoint ID trailer to commit message
-	commitMsg := subject + "\n\n" + trailers.CheckpointTrailerKey + ": " + checkpointID.String()
+	// Add checkpoint ID trailer to commit message, unless commits are linked with notes
+	notesEnabled := isCommitNotesEnabled()
+	commitMsg := subject
+	if !notesEnabled {
+		commitMsg += "\n\n" + trailers.CheckpointTrailerKey + ": " + checkpointID.String()
+	}
 
 	author := &object.Signature{
 		Name:  ctx.AuthorName,
@@ -591,10 +629,17 @@ func (s *AutoCommitStrategy) commitTaskCodeToActive(repo *git.Repository, ctx Ta
 		When:  time.Now(),
 	}
 
+	headBefore, err := repo.Head()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to get HEAD: %w", err)
+	}
 	commitHash, err := commitOrHead(repo, worktree, commitMsg, author)
 	if err != nil {
 		return plumbing.ZeroHash, err
 	}
+	if notesEnabled && commitHash != headBefore.Hash() {
+		linkCommitWithNote(context.Background(), repo, commitHash, checkpointID)
+	}
 
 	if ctx.IsIncremental {
 		fmt.Fprintf(os.Stderr, "Committed incremental checkpoint #%d to active branch (%s)\n", ctx.IncrementalSequence, commitHash.String()[:7])
@@ -658,6 +703,8 @@ func (s *AutoCommitStrategy) commitTaskMetadataToMetadataBranch(repo *git.Reposi
 		AuthorName:             ctx.AuthorName,
 		AuthorEmail:            ctx.AuthorEmail,
 		Agent:                  ctx.AgentType,
+		TranscriptCompression:  transcriptCompression(),
+		EncryptionRecipients:   encryptionRecipients(),
 	})
 	if err != nil {
 		return plumbing.ZeroHash, fmt.Errorf("failed to write task checkpoint: %w", err)
@@ -797,7 +844,11 @@ func (s *AutoCommitStrategy) GetTaskCheckpointTranscript(point RewindPoint) ([]b
 		if err != nil {
 			return nil, fmt.Errorf("failed to read transcript: %w", err)
 		}
-		return []byte(content), nil
+		transcript, err := checkpoint.DecodeFile(transcriptPath, []byte(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read transcript: %w", err)
+		}
+		return transcript, nil
 	}
 
 	return nil, fmt.Errorf("invalid metadata path format: %s", metadataDir)
@@ -1017,9 +1068,11 @@ func (s *AutoCommitStrategy) ListOrphanedItems() ([]CleanupItem, error) {
 	return items, nil
 }
 
-// findReferencedCheckpoints scans commits for Entire-Checkpoint trailers.
+// findReferencedCheckpoints scans commits for Entire-Checkpoint trailers and
+// links recorded for rewritten commits.
 func (s *AutoCommitStrategy) findReferencedCheckpoints(repo *git.Repository) map[string]bool {
 	referenced := make(map[string]bool)
+	store := checkpoint.NewGitStore(repo)
 
 	refs, err := repo.References()
 	if err != nil {
@@ -1054,7 +1107,7 @@ func (s *AutoCommitStrategy) findReferencedCheckpoints(repo *git.Repository) map
 			}
 			visited[c.Hash] = true
 
-			if cpID, found := trailers.ParseCheckpoint(c.Message); found {
+			if cpID, found := store.CheckpointIDForCommit(context.Background(), c); found {
 				referenced[cpID.String()] = true
 			}
 			return nil
@@ -0,0 +1,98 @@
+package strategy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// CheckpointRemote is where the metadata branch is pushed to and fetched
+// from. By default that's the entire/checkpoints/v1 branch on the remote code
+// is pushed to; strategy_options.checkpoint_remote and
+// strategy_options.checkpoint_ref move it to another remote or ref, e.g. to
+// keep transcripts on an internal mirror while code goes to a public host.
+type CheckpointRemote struct {
+	// Remote is the remote name or URL
+	Remote string
+
+	// Ref is the full ref name of the metadata branch on the remote
+	Ref string
+}
+
+// ResolveCheckpointRemote returns where checkpoints go when pushing code to
+// remote, applying the checkpoint remote and ref settings.
+func ResolveCheckpointRemote(remote string) CheckpointRemote {
+	target := CheckpointRemote{
+		Remote: remote,
+		Ref:    plumbing.NewBranchReferenceName(paths.MetadataBranchName).String(),
+	}
+	s, err := settings.Load()
+	if err != nil {
+		return target
+	}
+	if configured := s.CheckpointRemote(); configured != "" {
+		target.Remote = configured
+	}
+	if ref := s.CheckpointRef(); ref != "" {
+		target.Ref = ref
+	}
+	return target
+}
+
+// isCheckpointRemoteConfigured reports whether checkpoints go to a dedicated
+// remote rather than to every remote code is pushed to.
+func isCheckpointRemoteConfigured() bool {
+	s, err := settings.Load()
+	if err != nil {
+		return false
+	}
+	return s.CheckpointRemote() != ""
+}
+
+// IsDefaultRef reports whether the metadata branch is stored on the remote
+// under its local name.
+func (c CheckpointRemote) IsDefaultRef() bool {
+	return c.Ref == plumbing.NewBranchReferenceName(paths.MetadataBranchName).String()
+}
+
+// TrackingRef is the local ref recording the tip of the metadata branch on
+// the remote: refs/remotes/<remote>/entire/checkpoints/v1 for the default ref
+// on a named remote, like any remote-tracking branch, and
+// refs/entire/remotes/<remote>/checkpoints otherwise.
+func (c CheckpointRemote) TrackingRef() plumbing.ReferenceName {
+	if c.IsDefaultRef() && isRemoteName(c.Remote) {
+		return plumbing.NewRemoteReferenceName(c.Remote, paths.MetadataBranchName)
+	}
+	return plumbing.ReferenceName("refs/entire/remotes/" + remoteRefKey(c.Remote) + "/checkpoints")
+}
+
+// PushRefspec pushes the local branch to the metadata branch on the remote.
+func (c CheckpointRemote) PushRefspec(branchName string) string {
+	return plumbing.NewBranchReferenceName(branchName).String() + ":" + c.Ref
+}
+
+// FetchRefspec fetches the metadata branch on the remote into TrackingRef.
+func (c CheckpointRemote) FetchRefspec() string {
+	return "+" + c.Ref + ":" + c.TrackingRef().String()
+}
+
+// isRemoteName reports whether remote is the name of a configured remote
+// rather than a URL or path.
+func isRemoteName(remote string) bool {
+	return remote != "" && !strings.ContainsAny(remote, ":/\\") && !strings.HasPrefix(remote, ".")
+}
+
+// remoteRefKey returns a ref path component identifying the remote: its name,
+// or a hash of its URL.
+func remoteRefKey(remote string) string {
+	if isRemoteName(remote) {
+		return remote
+	}
+	sum := sha256.Sum256([]byte(remote))
+	return "url-" + hex.EncodeToString(sum[:6])
+}
@@ -0,0 +1,119 @@
+package strategy
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lsRemote returns the hash of ref on remote, or "" if it doesn't exist.
+func lsRemote(t *testing.T, remote, ref string) string {
+	t.Helper()
+	output, err := exec.CommandContext(context.Background(), "git", "ls-remote", remote, ref).Output()
+	require.NoError(t, err)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 && fields[1] == ref {
+			return fields[0]
+		}
+	}
+	return ""
+}
+
+// TestPushSessionsBranch_CheckpointRemoteAndRef verifies that checkpoints go
+// to the configured checkpoint remote and ref rather than the remote code is
+// pushed to, and that checkpoints pushed there by someone else are merged.
+func TestPushSessionsBranch_CheckpointRemoteAndRef(t *testing.T) {
+	const checkpointRef = "refs/entire/checkpoints"
+	originDir := t.TempDir()
+	runGit(t, originDir, "init", "--bare", "-q")
+	mirrorDir := t.TempDir()
+	runGit(t, mirrorDir, "init", "--bare", "-q")
+
+	dir := setupGitRepo(t)
+	runGit(t, dir, "remote", "add", "origin", originDir)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, paths.EntireDir), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, paths.EntireDir, paths.SettingsFileName), []byte(`{
+  "strategy": "manual-commit",
+  "strategy_options": {"checkpoint_remote": "`+mirrorDir+`", "checkpoint_ref": "`+checkpointRef+`"}
+}`), 0o644))
+	t.Chdir(dir)
+	paths.ClearRepoRootCache()
+
+	target := ResolveCheckpointRemote("origin")
+	assert.Equal(t, CheckpointRemote{Remote: mirrorDir, Ref: checkpointRef}, target)
+	assert.True(t, strings.HasPrefix(target.TrackingRef().String(), "refs/entire/remotes/url-"))
+
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	first := id.MustCheckpointID("e0e0e0e0e0e1")
+	writePruneTestCheckpoint(t, repo, first)
+	require.NoError(t, pushSessionsBranchCommon("origin", paths.MetadataBranchName))
+
+	localRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	require.NoError(t, err)
+	assert.Equal(t, localRef.Hash().String(), lsRemote(t, mirrorDir, checkpointRef))
+	assert.Empty(t, lsRemote(t, mirrorDir, "refs/heads/"+paths.MetadataBranchName))
+	assert.Empty(t, lsRemote(t, originDir, "refs/heads/"+paths.MetadataBranchName))
+
+	remoteTree, err := GetRemoteMetadataBranchTree(repo)
+	require.NoError(t, err)
+	_, err = remoteTree.Tree(first.Path())
+	require.NoError(t, err, "tracking ref should have the pushed checkpoint")
+
+	// Someone else pushes a checkpoint to the mirror
+	otherDir := setupGitRepo(t)
+	runGit(t, otherDir, "fetch", "-q", mirrorDir, checkpointRef+":refs/heads/"+paths.MetadataBranchName)
+	otherRepo, err := git.PlainOpen(otherDir)
+	require.NoError(t, err)
+	concurrent := id.MustCheckpointID("e0e0e0e0e0e2")
+	writePruneTestCheckpoint(t, otherRepo, concurrent)
+	runGit(t, otherDir, "push", "-q", "--no-verify", mirrorDir, paths.MetadataBranchName+":"+checkpointRef)
+
+	second := id.MustCheckpointID("e0e0e0e0e0e3")
+	writePruneTestCheckpoint(t, repo, second)
+	require.NoError(t, pushSessionsBranchCommon("origin", paths.MetadataBranchName))
+
+	runGit(t, otherDir, "fetch", "-q", mirrorDir, "+"+checkpointRef+":refs/heads/"+paths.MetadataBranchName)
+	mirrorTree, err := GetMetadataBranchTree(otherRepo)
+	require.NoError(t, err)
+	for _, cpID := range []id.CheckpointID{first, concurrent, second} {
+		_, err := mirrorTree.Tree(cpID.Path())
+		assert.NoError(t, err, "checkpoint %s should be on the mirror", cpID)
+	}
+}
+
+// TestCheckpointRemote_TrackingRef verifies where the tip of the remote
+// metadata branch is recorded.
+func TestCheckpointRemote_TrackingRef(t *testing.T) {
+	t.Parallel()
+	defaultRef := "refs/heads/" + paths.MetadataBranchName
+
+	tests := []struct {
+		name   string
+		remote CheckpointRemote
+		want   string
+	}{
+		{"default", CheckpointRemote{Remote: "origin", Ref: defaultRef}, "refs/remotes/origin/" + paths.MetadataBranchName},
+		{"custom ref", CheckpointRemote{Remote: "mirror", Ref: "refs/entire/checkpoints"}, "refs/entire/remotes/mirror/checkpoints"},
+		{"url", CheckpointRemote{Remote: "git@example.com:org/repo.git", Ref: defaultRef}, "refs/entire/remotes/url-"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := tt.remote.TrackingRef().String()
+			assert.True(t, strings.HasPrefix(got, tt.want), "TrackingRef() = %q, want prefix %q", got, tt.want)
+			assert.NoError(t, plumbing.ReferenceName(got).Validate())
+		})
+	}
+}
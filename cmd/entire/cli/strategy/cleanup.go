@@ -13,6 +13,7 @@ import (
 	"github.com/entireio/cli/cmd/entire/cli/logging"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/session"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
 
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
@@ -32,23 +33,26 @@ const (
 	CleanupTypeShadowBranch CleanupType = "shadow-branch"
 	CleanupTypeSessionState CleanupType = "session-state"
 	CleanupTypeCheckpoint   CleanupType = "checkpoint"
+	CleanupTypePreRewindRef CleanupType = "pre-rewind-ref"
 )
 
 // CleanupItem represents an orphaned item that can be cleaned up.
 type CleanupItem struct {
 	Type   CleanupType
-	ID     string // Branch name, session ID, or checkpoint ID
+	ID     string // Branch name, session ID, checkpoint ID, or ref name
 	Reason string // Why this item is considered orphaned
 }
 
 // CleanupResult contains the results of a cleanup operation.
 type CleanupResult struct {
-	ShadowBranches    []string // Deleted shadow branches
-	SessionStates     []string // Deleted session state files
-	Checkpoints       []string // Deleted checkpoint metadata
-	FailedBranches    []string // Shadow branches that failed to delete
-	FailedStates      []string // Session states that failed to delete
-	FailedCheckpoints []string // Checkpoints that failed to delete
+	ShadowBranches      []string // Deleted shadow branches
+	SessionStates       []string // Deleted session state files
+	Checkpoints         []string // Deleted checkpoint metadata
+	PreRewindRefs       []string // Deleted pre-rewind refs
+	FailedBranches      []string // Shadow branches that failed to delete
+	FailedStates        []string // Session states that failed to delete
+	FailedCheckpoints   []string // Checkpoints that failed to delete
+	FailedPreRewindRefs []string // Pre-rewind refs that failed to delete
 }
 
 // shadowBranchPattern matches shadow branch names in both old and new formats:
@@ -136,6 +140,70 @@ func DeleteShadowBranches(branches []string) (deleted []string, failed []string,
 	return deleted, failed, nil
 }
 
+// ListOrphanedPreRewindRefs returns the pre-rewind refs (see
+// checkpoint.PreRewindRefPrefix) that are orphaned: their shadow branch no
+// longer exists, or the base commit their snapshot would restore is gone.
+// Shadow branches deleted by Entire take their pre-rewind ref with them; these
+// are left over from branches deleted otherwise.
+func ListOrphanedPreRewindRefs() ([]CleanupItem, error) {
+	repo, err := OpenRepository()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	refs, err := repo.References()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get references: %w", err)
+	}
+
+	orphaned := []CleanupItem{}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if !strings.HasPrefix(ref.Name().String(), checkpoint.PreRewindRefPrefix) {
+			return nil
+		}
+
+		shadowBranch := checkpoint.ShadowBranchForPreRewindRef(ref.Name())
+		if _, err := repo.Reference(plumbing.NewBranchReferenceName(shadowBranch), true); err != nil {
+			orphaned = append(orphaned, CleanupItem{
+				Type:   CleanupTypePreRewindRef,
+				ID:     ref.Name().String(),
+				Reason: "shadow branch no longer exists",
+			})
+			return nil
+		}
+
+		var baseCommit string
+		if commit, err := repo.CommitObject(ref.Hash()); err == nil {
+			baseCommit, _ = trailers.ParseBaseCommit(commit.Message)
+		}
+		if _, err := repo.CommitObject(plumbing.NewHash(baseCommit)); baseCommit == "" || err != nil {
+			orphaned = append(orphaned, CleanupItem{
+				Type:   CleanupTypePreRewindRef,
+				ID:     ref.Name().String(),
+				Reason: "base commit no longer exists",
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to iterate references: %w", err)
+	}
+
+	return orphaned, nil
+}
+
+// DeletePreRewindRefs deletes the specified pre-rewind refs.
+func DeletePreRewindRefs(refNames []string) (deleted []string, failed []string) {
+	for _, refName := range refNames {
+		if err := DeleteRefCLI(refName); err != nil {
+			failed = append(failed, refName)
+			continue
+		}
+		deleted = append(deleted, refName)
+	}
+	return deleted, failed
+}
+
 // ListOrphanedSessionStates returns session state files that are orphaned.
 // A session state is orphaned if:
 //   - No checkpoints on entire/checkpoints/v1 reference this session ID
@@ -312,6 +380,8 @@ func DeleteOrphanedCheckpoints(checkpointIDs []string) (deleted []string, failed
 		ParentHashes: []plumbing.Hash{ref.Hash()},
 	}
 
+	checkpoint.SignCommit(repo, commit)
+
 	obj := repo.Storer.NewEncodedObject()
 	if err := commit.Encode(obj); err != nil {
 		return nil, nil, fmt.Errorf("failed to encode commit: %w", err)
@@ -373,6 +443,16 @@ func ListAllCleanupItems() ([]CleanupItem, error) {
 		items = append(items, states...)
 	}
 
+	// Orphaned pre-rewind refs (strategy-agnostic)
+	preRewindRefs, err := ListOrphanedPreRewindRefs()
+	if err != nil {
+		if firstErr == nil {
+			firstErr = err
+		}
+	} else {
+		items = append(items, preRewindRefs...)
+	}
+
 	return items, firstErr
 }
 
@@ -389,7 +469,7 @@ func DeleteAllCleanupItems(items []CleanupItem) (*CleanupResult, error) {
 	}
 
 	// Group items by type
-	var branches, states, checkpoints []string
+	var branches, states, checkpoints, preRewindRefs []string
 	for _, item := range items {
 		switch item.Type {
 		case CleanupTypeShadowBranch:
@@ -398,6 +478,8 @@ func DeleteAllCleanupItems(items []CleanupItem) (*CleanupResult, error) {
 			states = append(states, item.ID)
 		case CleanupTypeCheckpoint:
 			checkpoints = append(checkpoints, item.ID)
+		case CleanupTypePreRewindRef:
+			preRewindRefs = append(preRewindRefs, item.ID)
 		}
 	}
 
@@ -482,17 +564,41 @@ func DeleteAllCleanupItems(items []CleanupItem) (*CleanupResult, error) {
 		}
 	}
 
+	// Delete pre-rewind refs
+	if len(preRewindRefs) > 0 {
+		deleted, failed := DeletePreRewindRefs(preRewindRefs)
+		result.PreRewindRefs = deleted
+		result.FailedPreRewindRefs = failed
+
+		for _, id := range deleted {
+			logging.Info(logCtx, "deleted orphaned pre-rewind ref",
+				slog.String("type", string(CleanupTypePreRewindRef)),
+				slog.String("id", id),
+				slog.String("reason", reasonMap[id]),
+			)
+		}
+		for _, id := range failed {
+			logging.Warn(logCtx, "failed to delete orphaned pre-rewind ref",
+				slog.String("type", string(CleanupTypePreRewindRef)),
+				slog.String("id", id),
+				slog.String("reason", reasonMap[id]),
+			)
+		}
+	}
+
 	// Log summary
-	totalDeleted := len(result.ShadowBranches) + len(result.SessionStates) + len(result.Checkpoints)
-	totalFailed := len(result.FailedBranches) + len(result.FailedStates) + len(result.FailedCheckpoints)
+	totalDeleted := len(result.ShadowBranches) + len(result.SessionStates) + len(result.Checkpoints) + len(result.PreRewindRefs)
+	totalFailed := len(result.FailedBranches) + len(result.FailedStates) + len(result.FailedCheckpoints) + len(result.FailedPreRewindRefs)
 	if totalDeleted > 0 || totalFailed > 0 {
 		logging.Info(logCtx, "cleanup completed",
 			slog.Int("deleted_branches", len(result.ShadowBranches)),
 			slog.Int("deleted_session_states", len(result.SessionStates)),
 			slog.Int("deleted_checkpoints", len(result.Checkpoints)),
+			slog.Int("deleted_pre_rewind_refs", len(result.PreRewindRefs)),
 			slog.Int("failed_branches", len(result.FailedBranches)),
 			slog.Int("failed_session_states", len(result.FailedStates)),
 			slog.Int("failed_checkpoints", len(result.FailedCheckpoints)),
+			slog.Int("failed_pre_rewind_refs", len(result.FailedPreRewindRefs)),
 		)
 	}
 
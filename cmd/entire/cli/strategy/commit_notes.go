@@ -0,0 +1,227 @@
+package strategy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// pendingCommitNoteFileName is the file in the git dir recording the checkpoint
+// prepare-commit-msg chose for the commit being made, when commits are linked
+// with notes instead of trailers (.git/entire-pending-note.json).
+const pendingCommitNoteFileName = "entire-pending-note.json"
+
+// pendingCommitNote is the checkpoint post-commit links the new commit to.
+type pendingCommitNote struct {
+	CheckpointID id.CheckpointID `json:"checkpoint_id"`
+
+	// Head is HEAD when the commit was prepared ("" before the first commit).
+	// The note only applies to a commit made on top of it, or amending it.
+	Head string `json:"head"`
+}
+
+func pendingCommitNotePath() (string, error) {
+	gitDir, err := GetGitDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(gitDir, pendingCommitNoteFileName), nil
+}
+
+// deferLinkToNote records the checkpoint for post-commit to link with a note
+// when commit notes are enabled. Returns false if commits are linked with
+// trailers, in which case the caller adds the trailer.
+func deferLinkToNote(logCtx context.Context, repo *git.Repository, checkpointID id.CheckpointID) bool {
+	if !isCommitNotesEnabled() {
+		return false
+	}
+	pending := pendingCommitNote{CheckpointID: checkpointID}
+	if head, err := repo.Head(); err == nil {
+		pending.Head = head.Hash().String()
+	}
+	if err := savePendingCommitNote(&pending); err != nil {
+		logging.Warn(logCtx, "prepare-commit-msg: failed to record pending commit note",
+			slog.String("checkpoint_id", checkpointID.String()),
+			slog.String("error", err.Error()),
+		)
+		return true
+	}
+	logging.Info(logCtx, "prepare-commit-msg: commit note pending",
+		slog.String("strategy", "manual-commit"),
+		slog.String("checkpoint_id", checkpointID.String()),
+	)
+	return true
+}
+
+func savePendingCommitNote(pending *pendingCommitNote) error {
+	filePath, err := pendingCommitNotePath()
+	if err != nil {
+		return err
+	}
+	data, err := jsonutil.MarshalIndentWithNewline(pending, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal pending commit note: %w", err)
+	}
+	if err := os.WriteFile(filePath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write pending commit note: %w", err)
+	}
+	return nil
+}
+
+// clearPendingCommitNote removes a pending note left by a commit that was
+// aborted after prepare-commit-msg.
+func clearPendingCommitNote() {
+	if filePath, err := pendingCommitNotePath(); err == nil {
+		_ = os.Remove(filePath)
+	}
+}
+
+// takePendingCommitNote returns and removes the pending note if it was
+// prepared for commit, i.e. commit was made on top of or amends the HEAD the
+// note was prepared on.
+func takePendingCommitNote(repo *git.Repository, commit *object.Commit) (id.CheckpointID, bool) {
+	filePath, err := pendingCommitNotePath()
+	if err != nil {
+		return id.EmptyCheckpointID, false
+	}
+	data, err := os.ReadFile(filePath) //nolint:gosec // Path is in the git dir
+	if err != nil {
+		return id.EmptyCheckpointID, false
+	}
+	_ = os.Remove(filePath)
+
+	var pending pendingCommitNote
+	if err := json.Unmarshal(data, &pending); err != nil || pending.CheckpointID.IsEmpty() {
+		return id.EmptyCheckpointID, false
+	}
+	if !preparedOn(repo, commit, pending.Head) {
+		return id.EmptyCheckpointID, false
+	}
+	return pending.CheckpointID, true
+}
+
+// preparedOn reports whether commit was made on top of head or amends it.
+func preparedOn(repo *git.Repository, commit *object.Commit, head string) bool {
+	if head == "" {
+		return len(commit.ParentHashes) == 0
+	}
+	if len(commit.ParentHashes) > 0 && commit.ParentHashes[0].String() == head {
+		return true
+	}
+	amended, err := repo.CommitObject(plumbing.NewHash(head))
+	if err != nil {
+		return false
+	}
+	if len(amended.ParentHashes) != len(commit.ParentHashes) {
+		return false
+	}
+	for i, parent := range amended.ParentHashes {
+		if commit.ParentHashes[i] != parent {
+			return false
+		}
+	}
+	return true
+}
+
+// linkCommitWithNote writes the note linking a commit to its checkpoint.
+func linkCommitWithNote(logCtx context.Context, repo *git.Repository, commitHash plumbing.Hash, checkpointID id.CheckpointID) {
+	if err := checkpoint.NewGitStore(repo).WriteCommitNote(logCtx, commitHash, checkpointID); err != nil {
+		fmt.Fprintf(os.Stderr, "[entire] Warning: failed to link commit to checkpoint %s: %v\n", checkpointID, err)
+		return
+	}
+	logging.Info(logCtx, "linked commit with note",
+		slog.String("commit", commitHash.String()),
+		slog.String("checkpoint_id", checkpointID.String()),
+	)
+}
+
+// commitNotesTrackingRef records the notes last pushed to or fetched from a
+// remote, so pre-push only pushes notes that changed.
+func commitNotesTrackingRef(remote string) plumbing.ReferenceName {
+	return plumbing.ReferenceName("refs/entire/remotes/" + remoteRefKey(remote) + "/notes")
+}
+
+// commitNotesFetchRef receives the notes fetched from a remote before they are
+// merged into the local notes. Fetching into it rather than FETCH_HEAD leaves
+// the user's FETCH_HEAD alone.
+func commitNotesFetchRef(remote string) plumbing.ReferenceName {
+	return plumbing.ReferenceName("refs/entire/remotes/" + remoteRefKey(remote) + "/notes-fetch")
+}
+
+// pushCommitNotes pushes refs/notes/entire alongside the metadata branch.
+// Notes written since the last push are merged with the remote ones if the
+// push is rejected. Failures are reported as warnings only.
+func pushCommitNotes(repo *git.Repository, remote string) {
+	localRef, err := repo.Reference(plumbing.ReferenceName(paths.CommitNotesRef), true)
+	if err != nil {
+		return // No notes
+	}
+	trackingRef, err := repo.Reference(commitNotesTrackingRef(remote), true)
+	if err == nil && trackingRef.Hash() == localRef.Hash() {
+		return // Nothing new
+	}
+
+	fmt.Fprintf(os.Stderr, "[entire] Pushing checkpoint notes to %s...\n", remote)
+	if err := tryPushSessionsCommon(remote, paths.CommitNotesRef); err != nil {
+		if err := FetchCommitNotes(remote); err != nil {
+			fmt.Fprintf(os.Stderr, "[entire] Warning: couldn't sync checkpoint notes: %v\n", err)
+			return
+		}
+		if err := tryPushSessionsCommon(remote, paths.CommitNotesRef); err != nil {
+			fmt.Fprintf(os.Stderr, "[entire] Warning: failed to push checkpoint notes: %v\n", err)
+			return
+		}
+	}
+
+	if localRef, err = repo.Reference(plumbing.ReferenceName(paths.CommitNotesRef), true); err == nil {
+		_ = repo.Storer.SetReference(plumbing.NewHashReference(commitNotesTrackingRef(remote), localRef.Hash())) //nolint:errcheck // Only saves a push next time
+	}
+}
+
+// FetchCommitNotes fetches refs/notes/entire from the remote and merges it
+// into the local notes. Does nothing if the remote has no notes.
+func FetchCommitNotes(remote string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	// Use git CLI for fetch (go-git's fetch can be tricky with auth)
+	fetchRef := commitNotesFetchRef(remote)
+	refSpec := "+" + paths.CommitNotesRef + ":" + fetchRef.String()
+	fetchCmd := exec.CommandContext(ctx, "git", "fetch", "--no-write-fetch-head", remote, refSpec)
+	fetchCmd.Stdin = nil
+	if output, err := fetchCmd.CombinedOutput(); err != nil {
+		if strings.Contains(string(output), "couldn't find remote ref") {
+			return nil
+		}
+		return fmt.Errorf("fetch failed: %s", output)
+	}
+
+	repo, err := OpenRepository()
+	if err != nil {
+		return fmt.Errorf("failed to open git repository: %w", err)
+	}
+	fetchedRef, err := repo.Reference(fetchRef, true)
+	if err != nil {
+		return fmt.Errorf("failed to get fetched notes: %w", err)
+	}
+	if err := checkpoint.NewGitStore(repo).MergeCommitNotes(ctx, fetchedRef.Hash()); err != nil {
+		return fmt.Errorf("failed to merge checkpoint notes: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package strategy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTakePendingCommitNote verifies that the pending note only applies to a
+// commit made on top of, or amending, the HEAD it was prepared on.
+func TestTakePendingCommitNote(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	cpID := id.MustCheckpointID("a1b2c3d4e5f6")
+
+	head, err := repo.Head()
+	require.NoError(t, err)
+	runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "second")
+	second, err := repo.Head()
+	require.NoError(t, err)
+	runGit(t, dir, "commit", "-q", "--amend", "--allow-empty", "-m", "second amended")
+	amended, err := repo.Head()
+	require.NoError(t, err)
+	runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "third")
+	third, err := repo.Head()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		prepared plumbing.Hash
+		commit   plumbing.Hash
+		want     bool
+	}{
+		{"on top of head", head.Hash(), second.Hash(), true},
+		{"amends head", second.Hash(), amended.Hash(), true},
+		{"unrelated commit", head.Hash(), third.Hash(), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, savePendingCommitNote(&pendingCommitNote{CheckpointID: cpID, Head: tt.prepared.String()}))
+			commit, err := repo.CommitObject(tt.commit)
+			require.NoError(t, err)
+
+			got, found := takePendingCommitNote(repo, commit)
+			assert.Equal(t, tt.want, found)
+			if tt.want {
+				assert.Equal(t, cpID, got)
+			}
+
+			// The pending note is consumed either way
+			_, found = takePendingCommitNote(repo, commit)
+			assert.False(t, found)
+		})
+	}
+}
+
+// TestPushCommitNotes_MergesDivergedNotes verifies that notes pushed by
+// someone else are merged before pushing, so no note is lost on either side.
+func TestPushCommitNotes_MergesDivergedNotes(t *testing.T) {
+	remoteDir := t.TempDir()
+	runGit(t, remoteDir, "init", "--bare", "-q")
+
+	otherDir := setupGitRepo(t)
+	runGit(t, otherDir, "remote", "add", "origin", remoteDir)
+	dir := setupGitRepo(t)
+	runGit(t, dir, "remote", "add", "origin", remoteDir)
+	runGit(t, otherDir, "commit", "-q", "--allow-empty", "-m", "other")
+
+	otherRepo, err := git.PlainOpen(otherDir)
+	require.NoError(t, err)
+	otherHead, err := otherRepo.Head()
+	require.NoError(t, err)
+	otherID := id.MustCheckpointID("b1b2b3b4b5b6")
+	require.NoError(t, checkpoint.NewGitStore(otherRepo).WriteCommitNote(context.Background(), otherHead.Hash(), otherID))
+	runGit(t, otherDir, "push", "-q", "--no-verify", "origin", paths.CommitNotesRef)
+
+	t.Chdir(dir)
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	head, err := repo.Head()
+	require.NoError(t, err)
+	cpID := id.MustCheckpointID("a1b2c3d4e5f6")
+	require.NoError(t, checkpoint.NewGitStore(repo).WriteCommitNote(context.Background(), head.Hash(), cpID))
+
+	pushCommitNotes(repo, "origin")
+
+	// The remote has both notes
+	runGit(t, otherDir, "fetch", "-q", "origin", "+"+paths.CommitNotesRef+":"+paths.CommitNotesRef)
+	otherStore := checkpoint.NewGitStore(otherRepo)
+	got, found := otherStore.ReadCommitNote(context.Background(), otherHead.Hash())
+	assert.True(t, found)
+	assert.Equal(t, otherID, got)
+	got, found = otherStore.ReadCommitNote(context.Background(), head.Hash())
+	assert.True(t, found)
+	assert.Equal(t, cpID, got)
+
+	// The tracking ref records what was pushed
+	localRef, err := repo.Reference(plumbing.ReferenceName(paths.CommitNotesRef), true)
+	require.NoError(t, err)
+	trackingRef, err := repo.Reference(commitNotesTrackingRef("origin"), true)
+	require.NoError(t, err)
+	assert.Equal(t, localRef.Hash(), trackingRef.Hash())
+}
+
+// TestFetchCommitNotes_KeepsFetchHead verifies that fetching notes merges them
+// through a private ref and leaves the user's FETCH_HEAD alone.
+func TestFetchCommitNotes_KeepsFetchHead(t *testing.T) {
+	remoteDir := t.TempDir()
+	runGit(t, remoteDir, "init", "--bare", "-q")
+
+	otherDir := setupGitRepo(t)
+	runGit(t, otherDir, "remote", "add", "origin", remoteDir)
+	otherRepo, err := git.PlainOpen(otherDir)
+	require.NoError(t, err)
+	otherHead, err := otherRepo.Head()
+	require.NoError(t, err)
+	otherID := id.MustCheckpointID("c1c2c3c4c5c6")
+	require.NoError(t, checkpoint.NewGitStore(otherRepo).WriteCommitNote(context.Background(), otherHead.Hash(), otherID))
+	runGit(t, otherDir, "push", "-q", "--no-verify", "origin", "HEAD:refs/heads/main", paths.CommitNotesRef)
+
+	dir := setupGitRepo(t)
+	runGit(t, dir, "remote", "add", "origin", remoteDir)
+	runGit(t, dir, "fetch", "-q", "origin", "main")
+	fetchHead := filepath.Join(dir, ".git", "FETCH_HEAD")
+	before, err := os.ReadFile(fetchHead)
+	require.NoError(t, err)
+
+	t.Chdir(dir)
+	require.NoError(t, FetchCommitNotes("origin"))
+
+	after, err := os.ReadFile(fetchHead)
+	require.NoError(t, err)
+	assert.Equal(t, string(before), string(after))
+
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	got, found := checkpoint.NewGitStore(repo).ReadCommitNote(context.Background(), otherHead.Hash())
+	assert.True(t, found)
+	assert.Equal(t, otherID, got)
+}
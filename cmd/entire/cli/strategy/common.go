@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -15,7 +16,9 @@ import (
 	"github.com/entireio/cli/cmd/entire/cli/agent"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
 	"github.com/entireio/cli/cmd/entire/cli/trailers"
 
 	"github.com/go-git/go-git/v5"
@@ -252,6 +255,8 @@ func EnsureMetadataBranch(repo *git.Repository) error {
 	}
 	// Note: No ParentHashes - this is an orphan commit
 
+	checkpoint.SignCommit(repo, commit)
+
 	commitObj := repo.Storer.NewEncodedObject()
 	if err := commit.Encode(commitObj); err != nil {
 		return fmt.Errorf("failed to encode orphan commit: %w", err)
@@ -468,9 +473,10 @@ func ReadSessionPromptFromShadow(repo *git.Repository, baseCommit, worktreeID, s
 	return ReadSessionPromptFromTree(tree, checkpointPath)
 }
 
-// GetRemoteMetadataBranchTree returns the tree object for origin/entire/checkpoints/v1.
+// GetRemoteMetadataBranchTree returns the tree object for origin/entire/checkpoints/v1,
+// or for the metadata branch last fetched from the checkpoint remote if one is configured.
 func GetRemoteMetadataBranchTree(repo *git.Repository) (*object.Tree, error) {
-	refName := plumbing.NewRemoteReferenceName("origin", paths.MetadataBranchName)
+	refName := ResolveCheckpointRemote("origin").TrackingRef()
 	ref, err := repo.Reference(refName, true)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get remote metadata branch reference: %w", err)
@@ -1137,6 +1143,21 @@ func DeleteBranchCLI(branchName string) error {
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("failed to delete branch %s: %s: %w", branchName, strings.TrimSpace(string(output)), err)
 	}
+
+	// The pre-rewind snapshot belongs to the shadow branch; drop it too (best-effort)
+	if IsShadowBranch(branchName) {
+		_ = DeleteRefCLI(checkpoint.PreRewindRefForBranch(branchName).String()) //nolint:errcheck // Best-effort cleanup
+	}
+	return nil
+}
+
+// DeleteRefCLI deletes a ref using the git CLI, for the same reasons as
+// DeleteBranchCLI. Deleting a ref that doesn't exist is not an error.
+func DeleteRefCLI(refName string) error {
+	cmd := exec.CommandContext(context.Background(), "git", "update-ref", "-d", refName) //nolint:gosec // refName comes from internal ref naming
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to delete ref %s: %s: %w", refName, strings.TrimSpace(string(output)), err)
+	}
 	return nil
 }
 
@@ -1268,6 +1289,8 @@ func createCommit(repo *git.Repository, treeHash, parentHash plumbing.Hash, mess
 		commit.ParentHashes = []plumbing.Hash{parentHash}
 	}
 
+	checkpoint.SignCommit(repo, commit)
+
 	obj := repo.Storer.NewEncodedObject()
 	if err := commit.Encode(obj); err != nil {
 		return plumbing.ZeroHash, fmt.Errorf("failed to encode commit: %w", err)
@@ -1480,3 +1503,49 @@ func IsOnDefaultBranch(repo *git.Repository) (bool, string) {
 
 	return currentBranch == defaultBranch, currentBranch
 }
+
+// transcriptCompression returns the transcript compression configured in settings.
+// Unsupported values are ignored with a warning, so a typo never blocks a checkpoint.
+func transcriptCompression() string {
+	s, err := settings.Load()
+	if err != nil {
+		return checkpoint.CompressionNone
+	}
+	compression := s.TranscriptCompression()
+	if err := checkpoint.ValidateCompression(compression); err != nil {
+		logging.Warn(context.Background(), "ignoring transcript_compression setting",
+			slog.String("error", err.Error()))
+		return checkpoint.CompressionNone
+	}
+	return compression
+}
+
+// isCommitNotesEnabled reports whether commits are linked to checkpoints with
+// notes on refs/notes/entire instead of the Entire-Checkpoint trailer.
+func isCommitNotesEnabled() bool {
+	s, err := settings.Load()
+	if err != nil {
+		return false
+	}
+	return s.IsCommitNotesEnabled()
+}
+
+// encryptionRecipients returns the age recipients configured in settings.
+// Unlike transcriptCompression, invalid recipients aren't dropped: they are
+// rejected by WriteCommitted, so a typo never stores a checkpoint unencrypted.
+func encryptionRecipients() []string {
+	s, err := settings.Load()
+	if err != nil {
+		return nil
+	}
+	return s.EncryptionRecipients()
+}
+
+// isTranscriptDeltaEnabled checks if delta transcript storage is enabled in settings.
+func isTranscriptDeltaEnabled() bool {
+	s, err := settings.Load()
+	if err != nil {
+		return false
+	}
+	return s.IsTranscriptDeltaEnabled()
+}
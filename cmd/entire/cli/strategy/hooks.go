@@ -16,7 +16,7 @@ import (
 const entireHookMarker = "Entire CLI hooks"
 
 // gitHookNames are the git hooks managed by Entire CLI
-var gitHookNames = []string{"prepare-commit-msg", "commit-msg", "post-commit", "pre-push"}
+var gitHookNames = []string{"prepare-commit-msg", "commit-msg", "post-commit", "post-rewrite", "pre-push"}
 
 // GetGitDir returns the actual git directory path by delegating to git itself.
 // This handles both regular repositories and worktrees, and inherits git's
@@ -47,14 +47,57 @@ func getGitDirInPath(dir string) (string, error) {
 	return filepath.Clean(gitDir), nil
 }
 
+// Markers around the Entire block in a hook file. A pre-existing hook is wrapped
+// in place: the block goes right after its shebang and its own script follows,
+// so the hook keeps its name and location (hook managers like Husky dispatch on
+// basename "$0"). RemoveGitHook strips the block again.
+const (
+	entireHookBegin = "# Entire CLI hooks: begin"
+	entireHookEnd   = "# Entire CLI hooks: end"
+)
+
+// chainedHookEnv is set when the Entire block re-runs its own hook file to run
+// the wrapped hook, which skips the block the second time.
+const chainedHookEnv = "ENTIRE_CHAINED_HOOK"
+
+// chainedHooksDir is the directory in the hooks directory that pre-existing
+// hooks which aren't shell scripts are moved to, keeping their names, since
+// they can't be wrapped in place.
+const chainedHooksDir = "pre-entire"
+
+// GetHooksDir returns the directory git reads hooks from.
+// This honors core.hooksPath and resolves to the common hooks directory for worktrees.
+func GetHooksDir() (string, error) {
+	return getHooksDirInPath(".")
+}
+
+// getHooksDirInPath returns the hooks directory for a repository at the given path.
+// It delegates to `git rev-parse --git-path hooks`, which applies core.hooksPath.
+func getHooksDirInPath(dir string) (string, error) {
+	ctx := context.Background()
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--git-path", "hooks")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", errors.New("not a git repository")
+	}
+
+	hooksDir := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(hooksDir) {
+		hooksDir = filepath.Join(dir, hooksDir)
+	}
+
+	return filepath.Clean(hooksDir), nil
+}
+
 // IsGitHookInstalled checks if all generic Entire CLI hooks are installed.
 func IsGitHookInstalled() bool {
-	gitDir, err := GetGitDir()
+	hooksDir, err := GetHooksDir()
 	if err != nil {
 		return false
 	}
 	for _, hook := range gitHookNames {
-		hookPath := filepath.Join(gitDir, "hooks", hook)
+		hookPath := filepath.Join(hooksDir, hook)
 		data, err := os.ReadFile(hookPath) //nolint:gosec // Path is constructed from constants
 		if err != nil {
 			return false
@@ -68,15 +111,17 @@ func IsGitHookInstalled() bool {
 
 // InstallGitHook installs generic git hooks that delegate to `entire hook` commands.
 // These hooks work with any strategy - the strategy is determined at runtime.
+// Hooks are written to the directory git actually uses (core.hooksPath is honored).
+// Pre-existing non-Entire hooks are kept and chained from the Entire block so that
+// existing commit policies keep running (see preserveExistingHook).
 // If silent is true, no output is printed.
 // Returns the number of hooks that were installed (0 if all already up to date).
 func InstallGitHook(silent bool) (int, error) {
-	gitDir, err := GetGitDir()
+	hooksDir, err := GetHooksDir()
 	if err != nil {
 		return 0, err
 	}
 
-	hooksDir := filepath.Join(gitDir, "hooks")
 	if err := os.MkdirAll(hooksDir, 0o755); err != nil { //nolint:gosec // Git hooks require executable permissions
 		return 0, fmt.Errorf("failed to create hooks directory: %w", err)
 	}
@@ -90,80 +135,278 @@ func InstallGitHook(silent bool) (int, error) {
 	}
 
 	installedCount := 0
+	var chainedHooks []string
 
 	// Install prepare-commit-msg hook
 	// $1 = commit message file, $2 = source (message, template, merge, squash, commit, or empty)
-	prepareCommitMsgPath := filepath.Join(hooksDir, "prepare-commit-msg")
-	prepareCommitMsgContent := fmt.Sprintf(`#!/bin/sh
-# %s
-%s hooks git prepare-commit-msg "$1" "$2" 2>/dev/null || true
-`, entireHookMarker, cmdPrefix)
+	// The previous hook runs first so templates/generators fill the message before the trailer is added.
+	existing, err := preserveExistingHook(hooksDir, "prepare-commit-msg")
+	if err != nil {
+		return 0, fmt.Errorf("failed to install prepare-commit-msg hook: %w", err)
+	}
+	prepareCommitMsgContent := fmt.Sprintf(`%s%s hooks git prepare-commit-msg "$1" "$2" 2>/dev/null || true
+`, chainedHookCall("prepare-commit-msg", existing, ""), cmdPrefix)
 
-	written, err := writeHookFile(prepareCommitMsgPath, prepareCommitMsgContent)
+	written, err := writeHookFile(filepath.Join(hooksDir, "prepare-commit-msg"), wrapHook(existing, prepareCommitMsgContent))
 	if err != nil {
 		return 0, fmt.Errorf("failed to install prepare-commit-msg hook: %w", err)
 	}
 	if written {
 		installedCount++
 	}
+	if existing.chained() {
+		chainedHooks = append(chainedHooks, "prepare-commit-msg")
+	}
 
 	// Install commit-msg hook
-	commitMsgPath := filepath.Join(hooksDir, "commit-msg")
-	commitMsgContent := fmt.Sprintf(`#!/bin/sh
-# %s
-# Commit-msg hook: strip trailer if no user content (allows aborting empty commits)
+	// The previous hook runs last so linters validate the final message (after trailer stripping).
+	existing, err = preserveExistingHook(hooksDir, "commit-msg")
+	if err != nil {
+		return 0, fmt.Errorf("failed to install commit-msg hook: %w", err)
+	}
+	commitMsgContent := fmt.Sprintf(`# Commit-msg hook: strip trailer if no user content (allows aborting empty commits)
 %s hooks git commit-msg "$1" || exit 1
-`, entireHookMarker, cmdPrefix)
+%s`, cmdPrefix, chainedHookCall("commit-msg", existing, ""))
 
-	written, err = writeHookFile(commitMsgPath, commitMsgContent)
+	written, err = writeHookFile(filepath.Join(hooksDir, "commit-msg"), wrapHook(existing, commitMsgContent))
 	if err != nil {
 		return 0, fmt.Errorf("failed to install commit-msg hook: %w", err)
 	}
 	if written {
 		installedCount++
 	}
+	if existing.chained() {
+		chainedHooks = append(chainedHooks, "commit-msg")
+	}
 
 	// Install post-commit hook
-	postCommitPath := filepath.Join(hooksDir, "post-commit")
-	postCommitContent := fmt.Sprintf(`#!/bin/sh
-# %s
-# Post-commit hook: condense session data if commit has Entire-Checkpoint trailer
+	existing, err = preserveExistingHook(hooksDir, "post-commit")
+	if err != nil {
+		return 0, fmt.Errorf("failed to install post-commit hook: %w", err)
+	}
+	postCommitContent := fmt.Sprintf(`# Post-commit hook: condense session data if commit has Entire-Checkpoint trailer
 %s hooks git post-commit 2>/dev/null || true
-`, entireHookMarker, cmdPrefix)
+%s`, cmdPrefix, chainedHookCall("post-commit", existing, ""))
 
-	written, err = writeHookFile(postCommitPath, postCommitContent)
+	written, err = writeHookFile(filepath.Join(hooksDir, "post-commit"), wrapHook(existing, postCommitContent))
 	if err != nil {
 		return 0, fmt.Errorf("failed to install post-commit hook: %w", err)
 	}
 	if written {
 		installedCount++
 	}
+	if existing.chained() {
+		chainedHooks = append(chainedHooks, "post-commit")
+	}
+
+	// Install post-rewrite hook
+	// $1 = rewrite command (amend or rebase); stdin = "<old-sha> <new-sha>" lines.
+	// Stdin is buffered so both Entire and a preserved hook can read the mapping.
+	existing, err = preserveExistingHook(hooksDir, "post-rewrite")
+	if err != nil {
+		return 0, fmt.Errorf("failed to install post-rewrite hook: %w", err)
+	}
+	postRewriteContent := fmt.Sprintf(`# Post-rewrite hook: keep checkpoint links and session state on rewritten commits
+rewrites=$(cat)
+printf '%%s\n' "$rewrites" | %s hooks git post-rewrite "$1" 2>/dev/null || true
+%s`, cmdPrefix, chainedHookCall("post-rewrite", existing, "rewrites"))
+
+	written, err = writeHookFile(filepath.Join(hooksDir, "post-rewrite"), wrapHook(existing, postRewriteContent))
+	if err != nil {
+		return 0, fmt.Errorf("failed to install post-rewrite hook: %w", err)
+	}
+	if written {
+		installedCount++
+	}
+	if existing.chained() {
+		chainedHooks = append(chainedHooks, "post-rewrite")
+	}
 
 	// Install pre-push hook
-	prePushPath := filepath.Join(hooksDir, "pre-push")
-	prePushContent := fmt.Sprintf(`#!/bin/sh
-# %s
-# Pre-push hook: push session logs alongside user's push
+	// The previous hook runs first (and receives git's stdin) so a rejected push
+	// doesn't push session logs either.
+	existing, err = preserveExistingHook(hooksDir, "pre-push")
+	if err != nil {
+		return 0, fmt.Errorf("failed to install pre-push hook: %w", err)
+	}
+	prePushContent := fmt.Sprintf(`# Pre-push hook: push session logs alongside user's push
 # $1 is the remote name (e.g., "origin")
-%s hooks git pre-push "$1" || true
-`, entireHookMarker, cmdPrefix)
+%s%s hooks git pre-push "$1" || true
+`, chainedHookCall("pre-push", existing, ""), cmdPrefix)
 
-	written, err = writeHookFile(prePushPath, prePushContent)
+	written, err = writeHookFile(filepath.Join(hooksDir, "pre-push"), wrapHook(existing, prePushContent))
 	if err != nil {
 		return 0, fmt.Errorf("failed to install pre-push hook: %w", err)
 	}
 	if written {
 		installedCount++
 	}
+	if existing.chained() {
+		chainedHooks = append(chainedHooks, "pre-push")
+	}
 
 	if !silent {
-		fmt.Println("✓ Installed git hooks (prepare-commit-msg, commit-msg, post-commit, pre-push)")
+		fmt.Println("✓ Installed git hooks (prepare-commit-msg, commit-msg, post-commit, post-rewrite, pre-push)")
 		fmt.Println("  Hooks delegate to the current strategy at runtime")
+		if len(chainedHooks) > 0 {
+			fmt.Printf("  Existing hooks preserved and chained: %s\n", strings.Join(chainedHooks, ", "))
+		}
 	}
 
 	return installedCount, nil
 }
 
+// existingHook is a pre-existing (non-Entire) hook the Entire block chains to.
+type existingHook struct {
+	// shebang and body of a shell script hook that is wrapped in place
+	shebang string
+	body    string
+
+	// movedAside is true for a hook that isn't a shell script, which was moved
+	// to <hooks>/pre-entire/<hook> instead
+	movedAside bool
+}
+
+// chained returns true if there is a hook to chain to.
+func (h existingHook) chained() bool {
+	return h.body != "" || h.movedAside
+}
+
+// preserveExistingHook returns the pre-existing non-Entire hook at hookName, if any.
+// Shell scripts are wrapped in place, with any Entire block from a previous install
+// stripped first. Other hooks are moved to <hooks>/pre-entire/<hook>, which keeps
+// their name; a hook moved there by a previous install is chained as well.
+func preserveExistingHook(hooksDir, hookName string) (existingHook, error) {
+	hookPath := filepath.Join(hooksDir, hookName)
+	movedPath := filepath.Join(hooksDir, chainedHooksDir, hookName)
+
+	_, movedErr := os.Lstat(movedPath)
+	movedExists := movedErr == nil
+
+	data, err := os.ReadFile(hookPath) //nolint:gosec // path is controlled
+	if err != nil {
+		return existingHook{movedAside: movedExists}, nil
+	}
+	shebang, body := splitShebang(stripEntireBlock(string(data)))
+	if strings.TrimSpace(body) == "" {
+		// No hook of its own (just ours) - chain only if a previous install moved one aside
+		return existingHook{movedAside: movedExists}, nil
+	}
+	if isShellShebang(shebang) {
+		return existingHook{shebang: shebang, body: body}, nil
+	}
+
+	if movedExists {
+		return existingHook{}, fmt.Errorf("cannot preserve existing %s hook: %s already exists", hookName, movedPath)
+	}
+	if err := os.MkdirAll(filepath.Dir(movedPath), 0o755); err != nil { //nolint:gosec // Git hooks require executable permissions
+		return existingHook{}, fmt.Errorf("failed to move existing %s hook aside: %w", hookName, err)
+	}
+	if err := os.Rename(hookPath, movedPath); err != nil {
+		return existingHook{}, fmt.Errorf("failed to move existing %s hook aside: %w", hookName, err)
+	}
+	return existingHook{movedAside: true}, nil
+}
+
+// stripEntireBlock returns the content of a hook file without its Entire block.
+// Hook files written entirely by older versions (marker but no block) are
+// returned empty.
+func stripEntireBlock(content string) string {
+	begin := strings.Index(content, entireHookBegin+"\n")
+	end := strings.Index(content, entireHookEnd+"\n")
+	if begin == -1 || end < begin {
+		if strings.Contains(content, entireHookMarker) {
+			return ""
+		}
+		return content
+	}
+	return content[:begin] + content[end+len(entireHookEnd)+1:]
+}
+
+// splitShebang splits a hook file into its shebang line (without newline, empty
+// if there is none) and the rest.
+func splitShebang(content string) (string, string) {
+	if !strings.HasPrefix(content, "#!") {
+		return "", content
+	}
+	line, rest, _ := strings.Cut(content, "\n")
+	return line, rest
+}
+
+// isShellShebang returns true if the shebang runs a POSIX-style shell, whose
+// scripts the Entire block can be inserted into.
+func isShellShebang(shebang string) bool {
+	fields := strings.Fields(strings.TrimPrefix(shebang, "#!"))
+	if len(fields) == 0 {
+		return false
+	}
+	interpreter := filepath.Base(fields[0])
+	if interpreter == "env" {
+		interpreter = ""
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") {
+				interpreter = field
+				break
+			}
+		}
+	}
+	switch interpreter {
+	case "sh", "bash", "dash", "ksh", "zsh", "ash":
+		return true
+	default:
+		return false
+	}
+}
+
+// wrapHook returns the hook file for the Entire script: the Entire block after
+// the existing hook's shebang, followed by the existing hook's script. When
+// there is one, the block runs it by re-running the file with chainedHookEnv
+// set (see chainedHookCall), and then exits, so it runs exactly once and sees
+// its own path as $0.
+func wrapHook(existing existingHook, script string) string {
+	shebang := existing.shebang
+	if shebang == "" {
+		shebang = "#!/bin/sh"
+	}
+	if existing.body == "" {
+		return fmt.Sprintf("%s\n%s\n%s%s\n", shebang, entireHookBegin, script, entireHookEnd)
+	}
+	return fmt.Sprintf(`%[1]s
+%[2]s
+if [ -z "$%[3]s" ]; then
+%[4]sexit 0
+fi
+unset %[3]s
+%[5]s
+%[6]s`, shebang, entireHookBegin, chainedHookEnv, script, entireHookEnd, existing.body)
+}
+
+// chainedHookCall returns the shell snippet that invokes the preserved hook with the
+// original arguments and stdin, aborting with its exit status on failure.
+// If stdinVar is non-empty, the hook's stdin is replayed from that shell variable
+// (for hooks whose stdin was already consumed by the Entire command).
+// Returns an empty string when there is no hook to chain.
+func chainedHookCall(hookName string, existing existingHook, stdinVar string) string {
+	stdinPipe := ""
+	if stdinVar != "" {
+		stdinPipe = fmt.Sprintf(`printf '%%s\n' "$%s" | `, stdinVar)
+	}
+	switch {
+	case existing.body != "":
+		return fmt.Sprintf(`# Run the %[1]s hook that existed before Entire was enabled (below)
+%[2]s%[3]s=1 "$0" "$@" || exit $?
+`, hookName, stdinPipe, chainedHookEnv)
+	case existing.movedAside:
+		return fmt.Sprintf(`# Run the %[1]s hook that existed before Entire was enabled
+if [ -x "$(dirname "$0")/%[2]s/%[1]s" ]; then
+	%[3]s"$(dirname "$0")/%[2]s/%[1]s" "$@" || exit $?
+fi
+`, hookName, chainedHooksDir, stdinPipe)
+	default:
+		return ""
+	}
+}
+
 // writeHookFile writes a hook file if it doesn't exist or has different content.
 // Returns true if the file was written, false if it already had the same content.
 func writeHookFile(path, content string) (bool, error) {
@@ -181,9 +424,11 @@ func writeHookFile(path, content string) (bool, error) {
 }
 
 // RemoveGitHook removes all Entire CLI git hooks from the repository.
+// The Entire block is stripped from wrapped hooks, and hooks that were moved
+// aside during installation are restored to their original names.
 // Returns the number of hooks removed.
 func RemoveGitHook() (int, error) {
-	gitDir, err := GetGitDir()
+	hooksDir, err := GetHooksDir()
 	if err != nil {
 		return 0, err
 	}
@@ -192,20 +437,35 @@ func RemoveGitHook() (int, error) {
 	var removeErrors []string
 
 	for _, hook := range gitHookNames {
-		hookPath := filepath.Join(gitDir, "hooks", hook)
+		hookPath := filepath.Join(hooksDir, hook)
 		data, err := os.ReadFile(hookPath) //nolint:gosec // path is controlled
-		if err != nil {
-			continue // Hook doesn't exist
-		}
+		hookExists := err == nil
 
-		if strings.Contains(string(data), entireHookMarker) {
-			if err := os.Remove(hookPath); err != nil {
-				removeErrors = append(removeErrors, fmt.Sprintf("%s: %v", hook, err))
+		if hookExists && strings.Contains(string(data), entireHookMarker) {
+			original := stripEntireBlock(string(data))
+			if _, body := splitShebang(original); strings.TrimSpace(body) == "" {
+				err = os.Remove(hookPath)
+				hookExists = false
 			} else {
-				removed++
+				err = os.WriteFile(hookPath, []byte(original), 0o755) //nolint:gosec // Git hooks require executable permissions
+			}
+			if err != nil {
+				removeErrors = append(removeErrors, fmt.Sprintf("%s: %v", hook, err))
+				continue
+			}
+			removed++
+		}
+
+		// Restore the original hook if we moved one aside and its slot is free
+		movedPath := filepath.Join(hooksDir, chainedHooksDir, hook)
+		if _, err := os.Lstat(movedPath); err == nil && !hookExists {
+			if err := os.Rename(movedPath, hookPath); err != nil {
+				removeErrors = append(removeErrors, fmt.Sprintf("%s: failed to restore original hook: %v", hook, err))
 			}
 		}
 	}
+	// Only removed if empty, so hooks that couldn't be restored stay
+	_ = os.Remove(filepath.Join(hooksDir, chainedHooksDir)) //nolint:errcheck // Best-effort cleanup
 
 	if len(removeErrors) > 0 {
 		return removed, fmt.Errorf("failed to remove hooks: %s", strings.Join(removeErrors, "; "))
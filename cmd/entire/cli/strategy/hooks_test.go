@@ -2,6 +2,7 @@ package strategy
 
 import (
 	"context"
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -552,3 +553,340 @@ func TestRemoveGitHook_PermissionDenied(t *testing.T) {
 		t.Errorf("error should mention 'failed to remove hooks', got: %v", err)
 	}
 }
+
+func TestInstallGitHook_ChainsExistingHooks(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+
+	ctx := context.Background()
+	cmd := exec.CommandContext(ctx, "git", "init")
+	cmd.Dir = tmpDir
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+	paths.ClearRepoRootCache()
+
+	// Simulate an existing hook manager (e.g., lefthook) that records its invocation
+	hooksDir := filepath.Join(tmpDir, ".git", "hooks")
+	markerPath := filepath.Join(tmpDir, "existing-hook-ran")
+	existingContent := "#!/bin/sh\necho \"$1\" > \"" + markerPath + "\"\n"
+	existingPath := filepath.Join(hooksDir, "prepare-commit-msg")
+	if err := os.WriteFile(existingPath, []byte(existingContent), 0o755); err != nil {
+		t.Fatalf("failed to create existing hook: %v", err)
+	}
+
+	if _, err := InstallGitHook(true); err != nil {
+		t.Fatalf("InstallGitHook() error = %v", err)
+	}
+
+	// Existing hook is wrapped in place: its shebang first, its script last
+	hookData, err := os.ReadFile(existingPath)
+	if err != nil {
+		t.Fatalf("failed to read installed hook: %v", err)
+	}
+	if !strings.Contains(string(hookData), entireHookMarker) {
+		t.Error("installed hook should contain Entire marker")
+	}
+	if !strings.HasPrefix(string(hookData), "#!/bin/sh\n") || !strings.HasSuffix(string(hookData), "echo \"$1\" > \""+markerPath+"\"\n") {
+		t.Errorf("installed hook should wrap the existing hook, got:\n%s", hookData)
+	}
+	if !strings.Contains(string(hookData), chainedHookEnv) {
+		t.Error("installed hook should call the preserved hook")
+	}
+
+	// Hooks without a pre-existing version are not chained
+	postCommitData, err := os.ReadFile(filepath.Join(hooksDir, "post-commit"))
+	if err != nil {
+		t.Fatalf("failed to read post-commit hook: %v", err)
+	}
+	if strings.Contains(string(postCommitData), chainedHookEnv) {
+		t.Error("post-commit hook should not chain when no hook existed before")
+	}
+
+	// Running the Entire hook invokes the preserved hook with the original arguments
+	// (the entire binary is not on PATH here, which the hook tolerates)
+	run := exec.CommandContext(ctx, existingPath, "COMMIT_EDITMSG", "message")
+	run.Dir = tmpDir
+	run.Env = append(os.Environ(), "PATH=/usr/bin:/bin")
+	if out, err := run.CombinedOutput(); err != nil {
+		t.Fatalf("running hook failed: %v\n%s", err, out)
+	}
+	markerData, err := os.ReadFile(markerPath)
+	if err != nil {
+		t.Fatalf("preserved hook was not invoked: %v", err)
+	}
+	if strings.TrimSpace(string(markerData)) != "COMMIT_EDITMSG" {
+		t.Errorf("preserved hook got args %q, want COMMIT_EDITMSG", strings.TrimSpace(string(markerData)))
+	}
+
+	// Reinstalling is idempotent and keeps the chain
+	count, err := InstallGitHook(true)
+	if err != nil {
+		t.Fatalf("second InstallGitHook() error = %v", err)
+	}
+	if count != 0 {
+		t.Errorf("second InstallGitHook() returned %d, want 0", count)
+	}
+}
+
+func TestInstallGitHook_ChainedHookFailureAbortsPush(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+
+	ctx := context.Background()
+	cmd := exec.CommandContext(ctx, "git", "init")
+	cmd.Dir = tmpDir
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+	paths.ClearRepoRootCache()
+
+	hooksDir := filepath.Join(tmpDir, ".git", "hooks")
+	hookPath := filepath.Join(hooksDir, "pre-push")
+	if err := os.WriteFile(hookPath, []byte("#!/bin/sh\nexit 3\n"), 0o755); err != nil {
+		t.Fatalf("failed to create existing hook: %v", err)
+	}
+
+	if _, err := InstallGitHook(true); err != nil {
+		t.Fatalf("InstallGitHook() error = %v", err)
+	}
+
+	run := exec.CommandContext(ctx, hookPath, "origin", "git@example.com:repo.git")
+	run.Dir = tmpDir
+	run.Env = append(os.Environ(), "PATH=/usr/bin:/bin")
+	err := run.Run()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("expected hook to fail with exit error, got %v", err)
+	}
+	if exitErr.ExitCode() != 3 {
+		t.Errorf("hook exit code = %d, want 3 (propagated from preserved hook)", exitErr.ExitCode())
+	}
+}
+
+func TestInstallGitHook_HonorsCoreHooksPath(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+
+	ctx := context.Background()
+	cmd := exec.CommandContext(ctx, "git", "init")
+	cmd.Dir = tmpDir
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+	cmd = exec.CommandContext(ctx, "git", "config", "core.hooksPath", ".githooks")
+	cmd.Dir = tmpDir
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("failed to set core.hooksPath: %v", err)
+	}
+	paths.ClearRepoRootCache()
+
+	if _, err := InstallGitHook(true); err != nil {
+		t.Fatalf("InstallGitHook() error = %v", err)
+	}
+
+	for _, hookName := range gitHookNames {
+		if _, err := os.Stat(filepath.Join(tmpDir, ".githooks", hookName)); err != nil {
+			t.Errorf("hook %s should be installed in core.hooksPath: %v", hookName, err)
+		}
+		if _, err := os.Stat(filepath.Join(tmpDir, ".git", "hooks", hookName)); !os.IsNotExist(err) {
+			t.Errorf("hook %s should not be installed in .git/hooks when core.hooksPath is set", hookName)
+		}
+	}
+
+	if !IsGitHookInstalled() {
+		t.Error("IsGitHookInstalled() = false, want true with core.hooksPath")
+	}
+}
+
+func TestRemoveGitHook_RestoresChainedHooks(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+
+	ctx := context.Background()
+	cmd := exec.CommandContext(ctx, "git", "init")
+	cmd.Dir = tmpDir
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+	paths.ClearRepoRootCache()
+
+	hooksDir := filepath.Join(tmpDir, ".git", "hooks")
+	commitMsgPath := filepath.Join(hooksDir, "commit-msg")
+	originalContent := "#!/bin/sh\n# commit message linter\nexit 0\n"
+	if err := os.WriteFile(commitMsgPath, []byte(originalContent), 0o755); err != nil {
+		t.Fatalf("failed to create existing hook: %v", err)
+	}
+
+	installCount, err := InstallGitHook(true)
+	if err != nil {
+		t.Fatalf("InstallGitHook() error = %v", err)
+	}
+
+	removeCount, err := RemoveGitHook()
+	if err != nil {
+		t.Fatalf("RemoveGitHook() error = %v", err)
+	}
+	if removeCount != installCount {
+		t.Errorf("RemoveGitHook() returned %d, want %d", removeCount, installCount)
+	}
+
+	data, err := os.ReadFile(commitMsgPath)
+	if err != nil {
+		t.Fatalf("original commit-msg hook should be restored: %v", err)
+	}
+	if string(data) != originalContent {
+		t.Errorf("restored hook content = %q, want %q", string(data), originalContent)
+	}
+	if _, err := os.Stat(filepath.Join(hooksDir, "post-commit")); !os.IsNotExist(err) {
+		t.Error("post-commit hook installed by Entire should be removed")
+	}
+}
+
+// TestInstallGitHook_DispatchingHooks verifies that hooks dispatching on their
+// own name, like Husky's core.hooksPath stubs, keep working when chained.
+func TestInstallGitHook_DispatchingHooks(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+
+	ctx := context.Background()
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "user.name", "Test"},
+		{"config", "user.email", "test@test.com"},
+		{"config", "core.hooksPath", ".husky/_"},
+	} {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = tmpDir
+		if err := cmd.Run(); err != nil {
+			t.Fatalf("git %v failed: %v", args, err)
+		}
+	}
+	paths.ClearRepoRootCache()
+
+	// Husky v9 layout: .husky/_/<hook> stubs source .husky/_/h, which runs
+	// .husky/<basename "$0">
+	huskyDir := filepath.Join(tmpDir, ".husky")
+	stubsDir := filepath.Join(huskyDir, "_")
+	if err := os.MkdirAll(stubsDir, 0o755); err != nil {
+		t.Fatalf("failed to create hooks dir: %v", err)
+	}
+	dispatcher := `#!/usr/bin/env sh
+n=$(basename "$0")
+s=$(dirname "$(dirname "$0")")/$n
+[ ! -f "$s" ] && exit 0
+sh -e "$s" "$@"
+exit $?
+`
+	if err := os.WriteFile(filepath.Join(stubsDir, "h"), []byte(dispatcher), 0o755); err != nil {
+		t.Fatalf("failed to write dispatcher: %v", err)
+	}
+	stub := "#!/usr/bin/env sh\n. \"$(dirname \"$0\")/h\"\n"
+	markerPath := filepath.Join(tmpDir, "hook-runs")
+	for _, hook := range []string{"prepare-commit-msg", "commit-msg", "post-commit"} {
+		if err := os.WriteFile(filepath.Join(stubsDir, hook), []byte(stub), 0o755); err != nil {
+			t.Fatalf("failed to write %s stub: %v", hook, err)
+		}
+		script := "echo " + hook + " >> \"" + markerPath + "\"\n"
+		if err := os.WriteFile(filepath.Join(huskyDir, hook), []byte(script), 0o644); err != nil {
+			t.Fatalf("failed to write %s script: %v", hook, err)
+		}
+	}
+
+	if _, err := InstallGitHook(true); err != nil {
+		t.Fatalf("InstallGitHook() error = %v", err)
+	}
+
+	// Stand-in for the entire binary so the Entire block succeeds
+	binDir := filepath.Join(t.TempDir(), "bin")
+	if err := os.MkdirAll(binDir, 0o755); err != nil {
+		t.Fatalf("failed to create bin dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(binDir, "entire"), []byte("#!/bin/sh\nexit 0\n"), 0o755); err != nil {
+		t.Fatalf("failed to write entire stub: %v", err)
+	}
+
+	commit := exec.CommandContext(ctx, "git", "commit", "--allow-empty", "-m", "test")
+	commit.Dir = tmpDir
+	commit.Env = append(os.Environ(), "PATH="+binDir+":/usr/bin:/bin")
+	if out, err := commit.CombinedOutput(); err != nil {
+		t.Fatalf("git commit failed: %v\n%s", err, out)
+	}
+
+	data, err := os.ReadFile(markerPath)
+	if err != nil {
+		t.Fatalf("Husky scripts did not run: %v", err)
+	}
+	want := "prepare-commit-msg\ncommit-msg\npost-commit\n"
+	if string(data) != want {
+		t.Errorf("Husky scripts ran as %q, want %q", string(data), want)
+	}
+
+	if _, err := RemoveGitHook(); err != nil {
+		t.Fatalf("RemoveGitHook() error = %v", err)
+	}
+	for _, hook := range []string{"prepare-commit-msg", "commit-msg", "post-commit"} {
+		data, err := os.ReadFile(filepath.Join(stubsDir, hook))
+		if err != nil {
+			t.Fatalf("failed to read %s stub: %v", hook, err)
+		}
+		if string(data) != stub {
+			t.Errorf("%s stub after RemoveGitHook() = %q, want %q", hook, string(data), stub)
+		}
+	}
+}
+
+// TestInstallGitHook_MovesNonShellHooksAside verifies that hooks that can't be
+// wrapped in place are moved to the pre-entire directory and restored.
+func TestInstallGitHook_MovesNonShellHooksAside(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+
+	ctx := context.Background()
+	cmd := exec.CommandContext(ctx, "git", "init")
+	cmd.Dir = tmpDir
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+	paths.ClearRepoRootCache()
+
+	hooksDir := filepath.Join(tmpDir, ".git", "hooks")
+	hookPath := filepath.Join(hooksDir, "pre-push")
+	originalContent := "#!/usr/bin/env python3\nimport sys\nsys.exit(0)\n"
+	if err := os.WriteFile(hookPath, []byte(originalContent), 0o755); err != nil {
+		t.Fatalf("failed to create existing hook: %v", err)
+	}
+
+	if _, err := InstallGitHook(true); err != nil {
+		t.Fatalf("InstallGitHook() error = %v", err)
+	}
+	movedPath := filepath.Join(hooksDir, chainedHooksDir, "pre-push")
+	data, err := os.ReadFile(movedPath)
+	if err != nil {
+		t.Fatalf("existing hook should be moved to %s: %v", movedPath, err)
+	}
+	if string(data) != originalContent {
+		t.Errorf("moved hook content = %q, want %q", string(data), originalContent)
+	}
+	hookData, err := os.ReadFile(hookPath)
+	if err != nil {
+		t.Fatalf("failed to read installed hook: %v", err)
+	}
+	if !strings.Contains(string(hookData), chainedHooksDir+"/pre-push") {
+		t.Error("installed hook should call the moved hook")
+	}
+
+	if _, err := RemoveGitHook(); err != nil {
+		t.Fatalf("RemoveGitHook() error = %v", err)
+	}
+	data, err = os.ReadFile(hookPath)
+	if err != nil {
+		t.Fatalf("original pre-push hook should be restored: %v", err)
+	}
+	if string(data) != originalContent {
+		t.Errorf("restored hook content = %q, want %q", string(data), originalContent)
+	}
+	if _, err := os.Stat(filepath.Join(hooksDir, chainedHooksDir)); !os.IsNotExist(err) {
+		t.Error("pre-entire directory should be removed after restore")
+	}
+}
@@ -2,7 +2,6 @@ package strategy
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -10,8 +9,6 @@ import (
 	"strings"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
-	"github.com/entireio/cli/cmd/entire/cli/agent/claudecode"
-	"github.com/entireio/cli/cmd/entire/cli/agent/geminicli"
 	cpkg "github.com/entireio/cli/cmd/entire/cli/checkpoint"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
 	"github.com/entireio/cli/cmd/entire/cli/logging"
@@ -20,7 +17,6 @@ import (
 	"github.com/entireio/cli/cmd/entire/cli/settings"
 	"github.com/entireio/cli/cmd/entire/cli/summarize"
 	"github.com/entireio/cli/cmd/entire/cli/textutil"
-	"github.com/entireio/cli/cmd/entire/cli/transcript"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
@@ -122,7 +118,7 @@ func (s *ManualCommitStrategy) CondenseSession(repo *git.Repository, checkpointI
 	// Pass live transcript path so condensation reads the current file rather than a
 	// potentially stale shadow branch copy (SaveChanges may have been skipped if the
 	// last turn had no code changes).
-	// Pass CheckpointTranscriptStart for accurate token calculation (line offset for Claude, message index for Gemini).
+	// Pass CheckpointTranscriptStart for accurate token calculation (the transcript position of the checkpoint's first entry).
 	sessionData, err := s.extractSessionData(repo, ref.Hash(), state.SessionID, state.FilesTouched, state.AgentType, state.TranscriptPath, state.CheckpointTranscriptStart)
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract session data: %w", err)
@@ -147,20 +143,10 @@ func (s *ManualCommitStrategy) CondenseSession(repo *git.Repository, checkpointI
 		summarizeCtx := logging.WithComponent(logCtx, "summarize")
 
 		// Scope transcript to this checkpoint's portion.
-		// SliceFromLine only works for JSONL (Claude Code) transcripts where
-		// CheckpointTranscriptStart is a line offset. For Gemini JSON transcripts,
-		// CheckpointTranscriptStart is a message index and the transcript is a single
-		// JSON blob, so line-based slicing would produce malformed content.
-		// The summarizer (ParseFromBytes) only supports JSONL, so skip scoping for Gemini.
-		var scopedTranscript []byte
-		if state.AgentType == agent.AgentTypeGemini {
-			scopedTranscript = sessionData.Transcript
-		} else {
-			scopedTranscript = transcript.SliceFromLine(sessionData.Transcript, state.CheckpointTranscriptStart)
-		}
-		if len(scopedTranscript) > 0 {
+		scopedEntries := agent.EntriesFrom(sessionData.Entries, state.CheckpointTranscriptStart)
+		if len(scopedEntries) > 0 {
 			var err error
-			summary, err = summarize.GenerateFromTranscript(summarizeCtx, scopedTranscript, sessionData.FilesTouched, nil)
+			summary, err = summarize.GenerateFromEntries(summarizeCtx, scopedEntries, sessionData.FilesTouched, nil)
 			if err != nil {
 				logging.Warn(summarizeCtx, "summary generation failed",
 					slog.String("session_id", state.SessionID),
@@ -193,6 +179,12 @@ func (s *ManualCommitStrategy) CondenseSession(repo *git.Repository, checkpointI
 		TokenUsage:                  sessionData.TokenUsage,
 		InitialAttribution:          attribution,
 		Summary:                     summary,
+		TranscriptCompression:       transcriptCompression(),
+		EncryptionRecipients:        encryptionRecipients(),
+		TranscriptDelta:             isTranscriptDeltaEnabled(),
+		PreviousCheckpointID:        state.LastCheckpointID,
+		ParentSessionID:             state.ParentSessionID,
+		ParentCheckpoint:            state.ParentCheckpoint,
 	}); err != nil {
 		return nil, fmt.Errorf("failed to write checkpoint metadata: %w", err)
 	}
@@ -306,7 +298,7 @@ func calculateSessionAttributions(repo *git.Repository, shadowRef *plumbing.Refe
 // liveTranscriptPath, when non-empty and readable, is preferred over the shadow branch copy.
 // This handles the case where SaveChanges was skipped (no code changes) but the transcript
 // continued growing — the shadow branch copy would be stale.
-// checkpointTranscriptStart is the line offset (Claude) or message index (Gemini) where the current checkpoint began.
+// checkpointTranscriptStart is the transcript position (see agent.SessionEntry) where the current checkpoint began.
 func (s *ManualCommitStrategy) extractSessionData(repo *git.Repository, shadowRef plumbing.Hash, sessionID string, filesTouched []string, agentType agent.AgentType, liveTranscriptPath string, checkpointTranscriptStart int) (*ExtractedSessionData, error) {
 	commit, err := repo.CommitObject(shadowRef)
 	if err != nil {
@@ -344,11 +336,18 @@ func (s *ManualCommitStrategy) extractSessionData(repo *git.Repository, shadowRe
 		}
 	}
 
-	// Process transcript based on agent type
+	// Normalize the transcript based on agent type
 	if fullTranscript != "" {
 		data.Transcript = []byte(fullTranscript)
-		data.FullTranscriptLines = countTranscriptItems(agentType, fullTranscript)
-		data.Prompts = extractUserPrompts(agentType, fullTranscript)
+		entries, err := agent.ParseTranscriptEntries(agentType, data.Transcript)
+		if err != nil {
+			logging.Warn(logging.WithComponent(context.Background(), "condensation"), "failed to parse transcript",
+				slog.String("session_id", sessionID),
+				slog.String("error", err.Error()))
+		}
+		data.Entries = entries
+		data.FullTranscriptLines = agent.TranscriptLength(entries)
+		data.Prompts = extractUserPrompts(entries)
 		data.Context = generateContextFromPrompts(data.Prompts)
 	}
 
@@ -357,163 +356,30 @@ func (s *ManualCommitStrategy) extractSessionData(repo *git.Repository, shadowRe
 
 	// Calculate token usage from the extracted transcript portion
 	if len(data.Transcript) > 0 {
-		data.TokenUsage = calculateTokenUsage(agentType, data.Transcript, checkpointTranscriptStart)
+		data.TokenUsage = agent.SumTokenUsage(agent.EntriesFrom(data.Entries, checkpointTranscriptStart))
 	}
 
 	return data, nil
 }
 
-// countTranscriptItems counts lines (JSONL) or messages (JSON) in a transcript.
-// For Claude Code and JSONL-based agents, this counts lines.
-// For Gemini CLI and JSON-based agents, this counts messages.
+// countTranscriptItems returns the length of a transcript in transcript positions:
+// lines for JSONL-based agents such as Claude Code, messages for Gemini CLI.
 // Returns 0 if the content is empty or malformed.
 func countTranscriptItems(agentType agent.AgentType, content string) int {
-	if content == "" {
+	entries, err := agent.ParseTranscriptEntries(agentType, []byte(content))
+	if err != nil {
 		return 0
 	}
-
-	// Try Gemini format first if agentType is Gemini, or as fallback if Unknown
-	if agentType == agent.AgentTypeGemini || agentType == agent.AgentTypeUnknown {
-		transcript, err := geminicli.ParseTranscript([]byte(content))
-		if err == nil && transcript != nil && len(transcript.Messages) > 0 {
-			return len(transcript.Messages)
-		}
-		// If agentType is explicitly Gemini but parsing failed, return 0
-		if agentType == agent.AgentTypeGemini {
-			return 0
-		}
-		// Otherwise fall through to JSONL parsing for Unknown type
-	}
-
-	// Claude Code and other JSONL-based agents
-	allLines := strings.Split(content, "\n")
-	// Trim trailing empty lines (from final \n in JSONL)
-	for len(allLines) > 0 && strings.TrimSpace(allLines[len(allLines)-1]) == "" {
-		allLines = allLines[:len(allLines)-1]
-	}
-	return len(allLines)
+	return agent.TranscriptLength(entries)
 }
 
-// extractUserPrompts extracts all user prompts from transcript content.
+// extractUserPrompts extracts all user prompts from transcript entries.
 // Returns prompts with IDE context tags stripped (e.g., <ide_opened_file>).
-func extractUserPrompts(agentType agent.AgentType, content string) []string {
-	if content == "" {
-		return nil
-	}
-
-	// Try Gemini format first if agentType is Gemini, or as fallback if Unknown
-	if agentType == agent.AgentTypeGemini || agentType == agent.AgentTypeUnknown {
-		prompts, err := geminicli.ExtractAllUserPrompts([]byte(content))
-		if err == nil && len(prompts) > 0 {
-			// Strip IDE context tags for consistency with Claude Code handling
-			cleaned := make([]string, 0, len(prompts))
-			for _, prompt := range prompts {
-				if stripped := textutil.StripIDEContextTags(prompt); stripped != "" {
-					cleaned = append(cleaned, stripped)
-				}
-			}
-			return cleaned
-		}
-		// If agentType is explicitly Gemini but parsing failed, return nil
-		if agentType == agent.AgentTypeGemini {
-			return nil
-		}
-		// Otherwise fall through to JSONL parsing for Unknown type
-	}
-
-	// Claude Code and other JSONL-based agents
-	return extractUserPromptsFromLines(strings.Split(content, "\n"))
-}
-
-// calculateTokenUsage calculates token usage from raw transcript data.
-// startOffset is the line number (Claude Code) or message index (Gemini CLI)
-// where the current checkpoint began, allowing calculation for only the portion
-// of the transcript since the last checkpoint.
-func calculateTokenUsage(agentType agent.AgentType, data []byte, startOffset int) *agent.TokenUsage {
-	if len(data) == 0 {
-		return &agent.TokenUsage{}
-	}
-
-	// Try Gemini format first if agentType is Gemini, or as fallback if Unknown
-	if agentType == agent.AgentTypeGemini || agentType == agent.AgentTypeUnknown {
-		// Attempt to parse as Gemini JSON
-		transcript, err := geminicli.ParseTranscript(data)
-		if err == nil && transcript != nil && len(transcript.Messages) > 0 {
-			return geminicli.CalculateTokenUsage(data, startOffset)
-		}
-		// If agentType is explicitly Gemini but parsing failed, return empty usage
-		if agentType == agent.AgentTypeGemini {
-			return &agent.TokenUsage{}
-		}
-		// Otherwise fall through to JSONL parsing for Unknown type
-	}
-
-	// Claude Code and other JSONL-based agents
-	lines, err := claudecode.ParseTranscript(data)
-	if err != nil || len(lines) == 0 {
-		return &agent.TokenUsage{}
-	}
-	// Slice transcript lines to only include checkpoint portion
-	if startOffset > 0 && startOffset < len(lines) {
-		lines = lines[startOffset:]
-	}
-	return claudecode.CalculateTokenUsage(lines)
-}
-
-// extractUserPromptsFromLines extracts user prompts from JSONL transcript lines.
-// IDE-injected context tags (like <ide_opened_file>) are stripped from the results.
-func extractUserPromptsFromLines(lines []string) []string {
+func extractUserPrompts(entries []agent.SessionEntry) []string {
 	var prompts []string
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		var entry map[string]interface{}
-		if err := json.Unmarshal([]byte(line), &entry); err != nil {
-			continue
-		}
-
-		// Check for user message (supports both "human" and "user" types)
-		msgType, ok := entry["type"].(string)
-		if !ok || (msgType != "human" && msgType != "user") {
-			continue
-		}
-
-		// Extract message content
-		message, ok := entry["message"].(map[string]interface{})
-		if !ok {
-			continue
-		}
-
-		// Handle string content
-		if content, ok := message["content"].(string); ok && content != "" {
-			cleaned := textutil.StripIDEContextTags(content)
-			if cleaned != "" {
-				prompts = append(prompts, cleaned)
-			}
-			continue
-		}
-
-		// Handle array content (e.g., multiple text blocks from VSCode)
-		if arr, ok := message["content"].([]interface{}); ok {
-			var texts []string
-			for _, item := range arr {
-				if m, ok := item.(map[string]interface{}); ok {
-					if m["type"] == "text" {
-						if text, ok := m["text"].(string); ok {
-							texts = append(texts, text)
-						}
-					}
-				}
-			}
-			if len(texts) > 0 {
-				cleaned := textutil.StripIDEContextTags(strings.Join(texts, "\n\n"))
-				if cleaned != "" {
-					prompts = append(prompts, cleaned)
-				}
-			}
+	for _, prompt := range agent.UserPrompts(entries) {
+		if cleaned := textutil.StripIDEContextTags(prompt); cleaned != "" {
+			prompts = append(prompts, cleaned)
 		}
 	}
 	return prompts
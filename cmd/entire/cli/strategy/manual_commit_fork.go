@@ -0,0 +1,77 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/buildinfo"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+// ForkSession starts the session state of a session forked from a checkpoint.
+// The fork's shadow branch is started with a checkpoint of the fork point, so
+// the fork can be rewound to where it started. The transcript up to the fork
+// point belongs to the parent session, so the fork's first checkpoint starts
+// after it.
+func (s *ManualCommitStrategy) ForkSession(opts ForkSessionOptions) error {
+	repo, err := OpenRepository()
+	if err != nil {
+		return fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	existing, err := s.loadSessionState(opts.SessionID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("session %s already exists", opts.SessionID)
+	}
+
+	worktreeID, err := paths.GetWorktreeID(opts.WorktreePath)
+	if err != nil {
+		return fmt.Errorf("failed to get worktree ID: %w", err)
+	}
+
+	store, err := s.getCheckpointStore()
+	if err != nil {
+		return fmt.Errorf("failed to get checkpoint store: %w", err)
+	}
+	authorName, authorEmail := GetGitAuthorFromRepo(repo)
+	if _, err := store.WriteForkPoint(context.Background(), checkpoint.WriteForkPointOptions{
+		BaseCommit:       opts.BaseCommit,
+		WorktreeID:       worktreeID,
+		SessionID:        opts.SessionID,
+		Tree:             opts.Tree,
+		Transcript:       opts.Transcript,
+		ParentSessionID:  opts.ParentSessionID,
+		ParentCheckpoint: opts.ParentCheckpoint,
+		AuthorName:       authorName,
+		AuthorEmail:      authorEmail,
+	}); err != nil {
+		return fmt.Errorf("failed to write fork point: %w", err)
+	}
+
+	now := time.Now()
+	state := &SessionState{
+		SessionID:                 opts.SessionID,
+		CLIVersion:                buildinfo.Version,
+		BaseCommit:                opts.BaseCommit,
+		AttributionBaseCommit:     opts.BaseCommit,
+		WorktreePath:              opts.WorktreePath,
+		WorktreeID:                worktreeID,
+		StartedAt:                 now,
+		LastInteractionTime:       &now,
+		StepCount:                 0,
+		CheckpointTranscriptStart: opts.TranscriptStart,
+		AgentType:                 opts.AgentType,
+		TranscriptPath:            opts.TranscriptPath,
+		ParentSessionID:           opts.ParentSessionID,
+		ParentCheckpoint:          opts.ParentCheckpoint,
+	}
+	if err := s.saveSessionState(state); err != nil {
+		return err
+	}
+	return nil
+}
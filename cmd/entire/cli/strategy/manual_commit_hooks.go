@@ -216,6 +216,11 @@ func isGitSequenceOperation() bool {
 func (s *ManualCommitStrategy) PrepareCommitMsg(commitMsgFile string, source string) error { //nolint:maintidx // already present in codebase
 	logCtx := logging.WithComponent(context.Background(), "checkpoint")
 
+	// A note left pending by an aborted commit must not link this one
+	if isCommitNotesEnabled() {
+		clearPendingCommitNote()
+	}
+
 	// Skip during rebase, cherry-pick, or revert operations
 	// These are replaying existing commits and should not be linked to agent sessions
 	if isGitSequenceOperation() {
@@ -467,6 +472,11 @@ func (s *ManualCommitStrategy) PrepareCommitMsg(commitMsgFile string, source str
 		message = addCheckpointTrailerWithComment(message, checkpointID, string(agentType), displayPrompt)
 	}
 
+	// With commit notes, the message is left alone and post-commit writes the note
+	if deferLinkToNote(logCtx, repo, checkpointID) {
+		return nil
+	}
+
 	logging.Info(logCtx, "prepare-commit-msg: trailer added",
 		slog.String("strategy", "manual-commit"),
 		slog.String("source", source),
@@ -551,6 +561,10 @@ func (s *ManualCommitStrategy) handleAmendCommitMsg(logCtx context.Context, comm
 			continue
 		}
 
+		if deferLinkToNote(logCtx, repo, cpID) {
+			return nil
+		}
+
 		// Restore the trailer
 		message = addCheckpointTrailer(message, cpID)
 		if writeErr := os.WriteFile(commitMsgFile, []byte(message), 0o600); writeErr != nil {
@@ -605,6 +619,12 @@ func (s *ManualCommitStrategy) PostCommit() error {
 
 	// Check if commit has checkpoint trailer (ParseCheckpoint validates format)
 	checkpointID, found := trailers.ParseCheckpoint(commit.Message)
+	if !found {
+		// With commit notes, prepare-commit-msg recorded the checkpoint instead
+		if checkpointID, found = takePendingCommitNote(repo, commit); found {
+			linkCommitWithNote(logCtx, repo, commit.Hash, checkpointID)
+		}
+	}
 	if !found {
 		// No trailer — user removed it or it was never added (mid-turn commit).
 		// Still update BaseCommit for active sessions so future commits can match.
@@ -1097,6 +1117,10 @@ func (s *ManualCommitStrategy) addTrailerForAgentCommit(logCtx context.Context,
 		return nil
 	}
 
+	if repo, err := OpenRepository(); err == nil && deferLinkToNote(logCtx, repo, cpID) {
+		return nil
+	}
+
 	message = addCheckpointTrailer(message, cpID)
 
 	logging.Info(logCtx, "prepare-commit-msg: agent commit trailer added",
@@ -1598,3 +1622,82 @@ func hasOverlappingFiles(stagedFiles, filesTouched []string) bool {
 	}
 	return false
 }
+
+// PostRewrite is called by the git post-rewrite hook after `git commit --amend` or `git rebase`.
+// It records commit → checkpoint links for rewritten commits that lost their Entire-Checkpoint
+// trailer, and moves session state that referenced an old SHA to the new one:
+//   - BaseCommit (and the shadow branch named after it)
+//   - AttributionBaseCommit
+//   - LastCheckpointID (re-resolved from the rewritten base commit)
+//
+//nolint:unparam // error return required by interface but hooks must return nil
+func (s *ManualCommitStrategy) PostRewrite(rewriteType string, rewrites []RewrittenCommit) error {
+	logCtx := logging.WithComponent(context.Background(), "checkpoint")
+	if len(rewrites) == 0 {
+		return nil
+	}
+
+	repo, err := OpenRepository()
+	if err != nil {
+		return nil //nolint:nilerr // Hook must be silent on failure
+	}
+
+	resolved, err := recordCommitRewrites(logCtx, repo, rewriteType, rewrites)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[entire] Warning: %v\n", err)
+		logging.Warn(logCtx, "post-rewrite: failed to record commit links",
+			slog.String("rewrite_type", rewriteType),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	worktreePath, err := GetWorktreePath()
+	if err != nil {
+		return nil //nolint:nilerr // Hook must be silent on failure
+	}
+	sessions, err := s.findSessionsForWorktree(worktreePath)
+	if err != nil || len(sessions) == 0 {
+		return nil //nolint:nilerr // Hook must be silent on failure
+	}
+
+	mapping := rewriteMap(rewrites)
+	for _, state := range sessions {
+		changed := false
+
+		oldBase := state.BaseCommit
+		if newBase, ok := mapping[oldBase]; ok {
+			if _, migErr := s.migrateShadowBranchToCommit(repo, state, newBase); migErr != nil {
+				logging.Warn(logCtx, "post-rewrite: shadow branch migration failed",
+					slog.String("session_id", state.SessionID),
+					slog.String("error", migErr.Error()),
+				)
+				continue
+			}
+			if cpID, found := resolved[oldBase]; found {
+				state.LastCheckpointID = cpID
+			}
+			changed = true
+		}
+
+		if newAttrBase, ok := mapping[state.AttributionBaseCommit]; ok {
+			state.AttributionBaseCommit = newAttrBase
+			changed = true
+		}
+
+		if !changed {
+			continue
+		}
+
+		logging.Debug(logCtx, "post-rewrite: updated session state",
+			slog.String("session_id", state.SessionID),
+			slog.String("rewrite_type", rewriteType),
+			slog.String("old_base", truncateHash(oldBase)),
+			slog.String("new_base", truncateHash(state.BaseCommit)),
+		)
+		if err := s.saveSessionState(state); err != nil {
+			fmt.Fprintf(os.Stderr, "[entire] Warning: failed to update session state: %v\n", err)
+		}
+	}
+
+	return nil
+}
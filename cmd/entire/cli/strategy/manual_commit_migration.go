@@ -1,6 +1,7 @@
 package strategy
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -28,19 +29,27 @@ func (s *ManualCommitStrategy) migrateShadowBranchIfNeeded(repo *git.Repository,
 		return false, fmt.Errorf("failed to get HEAD: %w", err)
 	}
 
-	currentHead := head.Hash().String()
-	if state.BaseCommit == currentHead {
+	return s.migrateShadowBranchToCommit(repo, state, head.Hash().String())
+}
+
+// migrateShadowBranchToCommit moves the session's shadow branch from its current
+// BaseCommit to newBase and updates state.BaseCommit.
+// Used when HEAD moves mid-session and when git rewrites the base commit (amend/rebase).
+//
+// Returns true if migration occurred, false if the session was already on newBase.
+func (s *ManualCommitStrategy) migrateShadowBranchToCommit(repo *git.Repository, state *SessionState, newBase string) (bool, error) {
+	if state.BaseCommit == newBase {
 		return false, nil // No migration needed
 	}
 
-	// HEAD changed - check if old shadow branch exists and migrate it
+	// Base changed - check if old shadow branch exists and migrate it
 	oldShadowBranch := checkpoint.ShadowBranchNameForCommit(state.BaseCommit, state.WorktreeID)
-	newShadowBranch := checkpoint.ShadowBranchNameForCommit(currentHead, state.WorktreeID)
+	newShadowBranch := checkpoint.ShadowBranchNameForCommit(newBase, state.WorktreeID)
 
 	// Guard against hash prefix collision: if both commits produce the same
 	// shadow branch name (same 7-char prefix), just update state - no ref rename needed
 	if oldShadowBranch == newShadowBranch {
-		state.BaseCommit = currentHead
+		state.BaseCommit = newBase
 		return true, nil
 	}
 
@@ -49,8 +58,8 @@ func (s *ManualCommitStrategy) migrateShadowBranchIfNeeded(repo *git.Repository,
 	if err != nil {
 		// Old shadow branch doesn't exist - just update state.BaseCommit
 		// This can happen if this is the first checkpoint after HEAD changed
-		state.BaseCommit = currentHead
-		fmt.Fprintf(os.Stderr, "Updated session base commit to %s (HEAD changed during session)\n", currentHead[:7])
+		state.BaseCommit = newBase
+		fmt.Fprintf(os.Stderr, "Updated session base commit to %s (HEAD changed during session)\n", newBase[:7])
 		return true, nil //nolint:nilerr // err is "reference not found" which is fine - just need to update state
 	}
 
@@ -63,6 +72,11 @@ func (s *ManualCommitStrategy) migrateShadowBranchIfNeeded(repo *git.Repository,
 		return false, fmt.Errorf("failed to create new shadow branch %s: %w", newShadowBranch, err)
 	}
 
+	// The pre-rewind snapshot moves with the shadow branch
+	if err := checkpoint.NewGitStore(repo).MoveRewindSnapshot(context.Background(), state.BaseCommit, newBase, state.WorktreeID); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to move pre-rewind snapshot of %s: %v\n", oldShadowBranch, err)
+	}
+
 	// Delete old reference via CLI (go-git v5's RemoveReference doesn't persist with packed refs/worktrees)
 	if err := DeleteBranchCLI(oldShadowBranch); err != nil {
 		// Non-fatal: log but continue - the important thing is the new branch exists
@@ -73,7 +87,7 @@ func (s *ManualCommitStrategy) migrateShadowBranchIfNeeded(repo *git.Repository,
 		oldShadowBranch, newShadowBranch)
 
 	// Update state with new base commit
-	state.BaseCommit = currentHead
+	state.BaseCommit = newBase
 	return true, nil
 }
 
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -120,6 +122,17 @@ func (s *ManualCommitStrategy) GetRewindPoints(limit int) ([]RewindPoint, error)
 		}
 	}
 
+	// Also include the state saved before the last rewind, so it can be undone
+	if preRewind, err := s.GetPreRewindPoint(); err == nil && preRewind != nil {
+		allPoints = append(allPoints, *preRewind)
+		sort.Slice(allPoints, func(i, j int) bool {
+			return allPoints[i].Date.After(allPoints[j].Date)
+		})
+		if len(allPoints) > limit {
+			allPoints = allPoints[:limit]
+		}
+	}
+
 	return allPoints, nil
 }
 
@@ -161,6 +174,11 @@ func (s *ManualCommitStrategy) GetLogsOnlyRewindPoints(limit int) ([]RewindPoint
 	// Get metadata branch tree for reading session prompts (best-effort, ignore errors)
 	metadataTree, _ := GetMetadataBranchTree(repo) //nolint:errcheck // Best-effort for session prompts
 
+	store, err := s.getCheckpointStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checkpoint store: %w", err)
+	}
+
 	head, err := repo.Head()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get HEAD: %w", err)
@@ -184,8 +202,8 @@ func (s *ManualCommitStrategy) GetLogsOnlyRewindPoints(limit int) ([]RewindPoint
 		}
 		count++
 
-		// Extract checkpoint ID from Entire-Checkpoint trailer (ParseCheckpoint validates format)
-		cpID, found := trailers.ParseCheckpoint(c.Message)
+		// Extract checkpoint ID from the Entire-Checkpoint trailer or commit note
+		cpID, found := store.CheckpointIDForCommit(context.Background(), c)
 		if !found {
 			return nil
 		}
@@ -247,14 +265,16 @@ func (s *ManualCommitStrategy) GetLogsOnlyRewindPoints(limit int) ([]RewindPoint
 }
 
 // Rewind restores the working directory to a checkpoint.
-//
-//nolint:maintidx // Complex rewind flow spans multiple recovery modes.
 func (s *ManualCommitStrategy) Rewind(point RewindPoint) error {
 	repo, err := OpenRepository()
 	if err != nil {
 		return fmt.Errorf("failed to open git repository: %w", err)
 	}
 
+	if point.IsPreRewind {
+		return s.undoRewind(repo, point)
+	}
+
 	// Get the checkpoint commit
 	commitHash := plumbing.NewHash(point.ID)
 	commit, err := repo.CommitObject(commitHash)
@@ -262,11 +282,14 @@ func (s *ManualCommitStrategy) Rewind(point RewindPoint) error {
 		return fmt.Errorf("failed to get commit: %w", err)
 	}
 
-	tree, err := commit.Tree()
+	plan, err := s.planRewind(repo, commit, nil)
 	if err != nil {
-		return fmt.Errorf("failed to get tree: %w", err)
+		return err
 	}
 
+	// Save the current state first so the rewind can be undone
+	s.snapshotBeforeRewind(repo, point)
+
 	// Reset the shadow branch to the rewound checkpoint
 	// This ensures the next checkpoint will only include prompts from this point forward
 	if err := s.resetShadowBranchToCheckpoint(repo, commit); err != nil {
@@ -274,147 +297,20 @@ func (s *ManualCommitStrategy) Rewind(point RewindPoint) error {
 		fmt.Fprintf(os.Stderr, "[entire] Warning: failed to reset shadow branch: %v\n", err)
 	}
 
-	// Load session state to get untracked files that existed at session start
-	sessionID, hasSessionTrailer := trailers.ParseSession(commit.Message)
-	var preservedUntrackedFiles map[string]bool
-	if hasSessionTrailer {
-		state, stateErr := s.loadSessionState(sessionID)
-		if stateErr == nil && state != nil && len(state.UntrackedFilesAtStart) > 0 {
-			preservedUntrackedFiles = make(map[string]bool)
-			for _, f := range state.UntrackedFilesAtStart {
-				preservedUntrackedFiles[f] = true
-			}
-		}
-	}
-
-	// Build set of files in the checkpoint tree (excluding metadata)
-	checkpointFiles := make(map[string]bool)
-	err = tree.Files().ForEach(func(f *object.File) error {
-		if !strings.HasPrefix(f.Name, entireDir) {
-			checkpointFiles[f.Name] = true
-		}
-		return nil
-	})
-	if err != nil {
-		return fmt.Errorf("failed to list checkpoint files: %w", err)
-	}
-
-	// Get HEAD tree to identify tracked files
-	head, err := repo.Head()
-	if err != nil {
-		return fmt.Errorf("failed to get HEAD: %w", err)
-	}
-	headCommit, err := repo.CommitObject(head.Hash())
-	if err != nil {
-		return fmt.Errorf("failed to get HEAD commit: %w", err)
-	}
-	headTree, err := headCommit.Tree()
-	if err != nil {
-		return fmt.Errorf("failed to get HEAD tree: %w", err)
-	}
-
-	// Build set of files tracked in HEAD
-	trackedFiles := make(map[string]bool)
-	//nolint:errcheck // Error is not critical for rewind
-	_ = headTree.Files().ForEach(func(f *object.File) error {
-		trackedFiles[f.Name] = true
-		return nil
-	})
-
-	// Get repository root to walk from there
-	repoRoot, err := GetWorktreePath()
-	if err != nil {
-		repoRoot = "." // Fallback to current directory
-	}
-
-	// Find and delete untracked files that aren't in the checkpoint
+	// Delete untracked files that aren't in the checkpoint
 	// These are likely files created by Claude in later checkpoints
-	err = filepath.Walk(repoRoot, func(path string, info os.FileInfo, walkErr error) error {
-		if walkErr != nil {
-			return nil //nolint:nilerr // Skip filesystem errors during walk
-		}
-
-		// Get path relative to repo root
-		relPath, relErr := filepath.Rel(repoRoot, path)
-		if relErr != nil {
-			return nil //nolint:nilerr // Skip paths we can't make relative
-		}
-
-		// Skip directories and special paths
-		if info.IsDir() {
-			if relPath == gitDir || relPath == claudeDir || relPath == entireDir || strings.HasPrefix(relPath, gitDir+"/") || strings.HasPrefix(relPath, claudeDir+"/") || strings.HasPrefix(relPath, entireDir+"/") {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		// Skip if path is a special directory
-		if strings.HasPrefix(relPath, gitDir+"/") || strings.HasPrefix(relPath, claudeDir+"/") || strings.HasPrefix(relPath, entireDir+"/") {
-			return nil
-		}
-
-		// If file is in checkpoint, it will be restored
-		if checkpointFiles[relPath] {
-			return nil
-		}
-
-		// If file is tracked in HEAD, don't delete (user's committed work)
-		if trackedFiles[relPath] {
-			return nil
-		}
-
-		// If file existed at session start, preserve it (untracked user files)
-		if preservedUntrackedFiles[relPath] {
-			return nil
-		}
-
-		// File is untracked and not in checkpoint - delete it (use absolute path)
-		if removeErr := os.Remove(path); removeErr == nil {
+	for _, relPath := range plan.delete {
+		if err := os.Remove(filepath.Join(plan.repoRoot, relPath)); err == nil {
 			fmt.Fprintf(os.Stderr, "  Deleted: %s\n", relPath)
 		}
-
-		return nil
-	})
-	if err != nil {
-		// Non-fatal - continue with restoration
-		fmt.Fprintf(os.Stderr, "Warning: error walking directory: %v\n", err)
 	}
 
 	// Restore files from checkpoint
-	err = tree.Files().ForEach(func(f *object.File) error {
-		// Skip metadata directories - these are for checkpoint storage, not working dir
-		if strings.HasPrefix(f.Name, entireDir) {
-			return nil
+	for _, f := range plan.restore {
+		if err := writeTreeFile(filepath.Join(plan.repoRoot, f.Name), f); err != nil {
+			return err
 		}
-
-		contents, err := f.Contents()
-		if err != nil {
-			return fmt.Errorf("failed to read file %s: %w", f.Name, err)
-		}
-
-		// Ensure directory exists
-		dir := filepath.Dir(f.Name)
-		if dir != "." {
-			//nolint:gosec // G301: Need 0o755 for user directories during rewind
-			if err := os.MkdirAll(dir, 0o755); err != nil {
-				return fmt.Errorf("failed to create directory %s: %w", dir, err)
-			}
-		}
-
-		// Write file with appropriate permissions
-		var perm os.FileMode = 0o644
-		if f.Mode == filemode.Executable {
-			perm = 0o755
-		}
-		if err := os.WriteFile(f.Name, []byte(contents), perm); err != nil {
-			return fmt.Errorf("failed to write file %s: %w", f.Name, err)
-		}
-
 		fmt.Fprintf(os.Stderr, "  Restored: %s\n", f.Name)
-		return nil
-	})
-	if err != nil {
-		return fmt.Errorf("failed to iterate tree files: %w", err)
 	}
 
 	fmt.Println()
@@ -482,6 +378,10 @@ func (s *ManualCommitStrategy) PreviewRewind(point RewindPoint) (*RewindPreview,
 		return nil, fmt.Errorf("failed to open git repository: %w", err)
 	}
 
+	if point.IsPreRewind {
+		return s.previewUndoRewind(repo, point)
+	}
+
 	// Get the checkpoint commit
 	commitHash := plumbing.NewHash(point.ID)
 	commit, err := repo.CommitObject(commitHash)
@@ -489,131 +389,11 @@ func (s *ManualCommitStrategy) PreviewRewind(point RewindPoint) (*RewindPreview,
 		return nil, fmt.Errorf("failed to get commit: %w", err)
 	}
 
-	tree, err := commit.Tree()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get tree: %w", err)
-	}
-
-	// Load session state to get untracked files that existed at session start
-	sessionID, hasSessionTrailer := trailers.ParseSession(commit.Message)
-	var preservedUntrackedFiles map[string]bool
-	if hasSessionTrailer {
-		state, stateErr := s.loadSessionState(sessionID)
-		if stateErr == nil && state != nil && len(state.UntrackedFilesAtStart) > 0 {
-			preservedUntrackedFiles = make(map[string]bool)
-			for _, f := range state.UntrackedFilesAtStart {
-				preservedUntrackedFiles[f] = true
-			}
-		}
-	}
-
-	// Build set of files in the checkpoint tree (excluding metadata)
-	checkpointFiles := make(map[string]bool)
-	var filesToRestore []string
-	err = tree.Files().ForEach(func(f *object.File) error {
-		if !strings.HasPrefix(f.Name, entireDir) {
-			checkpointFiles[f.Name] = true
-			filesToRestore = append(filesToRestore, f.Name)
-		}
-		return nil
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to list checkpoint files: %w", err)
-	}
-
-	// Get HEAD tree to identify tracked files
-	head, err := repo.Head()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get HEAD: %w", err)
-	}
-	headCommit, err := repo.CommitObject(head.Hash())
-	if err != nil {
-		return nil, fmt.Errorf("failed to get HEAD commit: %w", err)
-	}
-	headTree, err := headCommit.Tree()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get HEAD tree: %w", err)
-	}
-
-	// Build set of files tracked in HEAD
-	trackedFiles := make(map[string]bool)
-	//nolint:errcheck // Error is not critical for preview
-	_ = headTree.Files().ForEach(func(f *object.File) error {
-		trackedFiles[f.Name] = true
-		return nil
-	})
-
-	// Get repository root to walk from there
-	repoRoot, err := GetWorktreePath()
+	plan, err := s.planRewind(repo, commit, nil)
 	if err != nil {
-		repoRoot = "."
-	}
-
-	// Find untracked files that would be deleted
-	var filesToDelete []string
-	err = filepath.Walk(repoRoot, func(path string, info os.FileInfo, walkErr error) error {
-		if walkErr != nil {
-			return nil //nolint:nilerr // Skip filesystem errors during walk
-		}
-
-		relPath, relErr := filepath.Rel(repoRoot, path)
-		if relErr != nil {
-			return nil //nolint:nilerr // Skip paths we can't make relative
-		}
-
-		// Skip directories and special paths
-		if info.IsDir() {
-			if relPath == gitDir || relPath == claudeDir || relPath == entireDir ||
-				strings.HasPrefix(relPath, gitDir+"/") ||
-				strings.HasPrefix(relPath, claudeDir+"/") ||
-				strings.HasPrefix(relPath, entireDir+"/") {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		// Skip special directories
-		if strings.HasPrefix(relPath, gitDir+"/") ||
-			strings.HasPrefix(relPath, claudeDir+"/") ||
-			strings.HasPrefix(relPath, entireDir+"/") {
-			return nil
-		}
-
-		// If file is in checkpoint, it will be restored (not deleted)
-		if checkpointFiles[relPath] {
-			return nil
-		}
-
-		// If file is tracked in HEAD, don't delete (user's committed work)
-		if trackedFiles[relPath] {
-			return nil
-		}
-
-		// If file existed at session start, preserve it (untracked user files)
-		if preservedUntrackedFiles[relPath] {
-			return nil
-		}
-
-		// File is untracked and not in checkpoint - will be deleted
-		filesToDelete = append(filesToDelete, relPath)
-		return nil
-	})
-	if err != nil {
-		// Non-fatal, return what we have
-		return &RewindPreview{ //nolint:nilerr // Partial result is still useful
-			FilesToRestore: filesToRestore,
-			FilesToDelete:  filesToDelete,
-		}, nil
+		return nil, err
 	}
-
-	// Sort for consistent output
-	sort.Strings(filesToRestore)
-	sort.Strings(filesToDelete)
-
-	return &RewindPreview{
-		FilesToRestore: filesToRestore,
-		FilesToDelete:  filesToDelete,
-	}, nil
+	return plan.preview(), nil
 }
 
 // RestoreLogsOnly restores session logs from a logs-only rewind point.
@@ -683,6 +463,11 @@ func (s *ManualCommitStrategy) RestoreLogsOnly(point RewindPoint, force bool) er
 		}
 	}
 
+	// Save the current state first so the restore can be undone
+	if repo, repoErr := OpenRepository(); repoErr == nil {
+		s.snapshotBeforeRewind(repo, point)
+	}
+
 	// Count sessions to restore
 	totalSessions := len(summary.Sessions)
 	if totalSessions > 1 {
@@ -923,3 +708,311 @@ func PromptOverwriteNewerLogs(sessions []SessionRestoreInfo) (bool, error) {
 
 	return confirmed, nil
 }
+
+// rewindPlan is what rewinding to a checkpoint, or restoring some of its
+// files, changes in the worktree.
+type rewindPlan struct {
+	repoRoot string
+
+	// restore are the files of the checkpoint tree to write
+	restore []*object.File
+
+	// delete are untracked files, relative to repoRoot, created after the checkpoint
+	delete []string
+}
+
+// planRewind works out which files rewinding to the checkpoint commit writes
+// and deletes. Untracked files are deleted unless they are in the checkpoint,
+// tracked in HEAD, or existed when the session started. A non-nil match limits
+// the plan to the files it matches.
+func (s *ManualCommitStrategy) planRewind(repo *git.Repository, commit *object.Commit, match func(string) bool) (*rewindPlan, error) {
+	if match == nil {
+		match = func(string) bool { return true }
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree: %w", err)
+	}
+
+	// Untracked files that existed at session start are never deleted
+	preservedUntrackedFiles := make(map[string]bool)
+	if sessionID, found := trailers.ParseSession(commit.Message); found {
+		if state, stateErr := s.loadSessionState(sessionID); stateErr == nil && state != nil {
+			for _, f := range state.UntrackedFilesAtStart {
+				preservedUntrackedFiles[f] = true
+			}
+		}
+	}
+
+	// Files in the checkpoint tree (excluding metadata) are restored, not deleted
+	plan := &rewindPlan{}
+	checkpointFiles := make(map[string]bool)
+	err = tree.Files().ForEach(func(f *object.File) error {
+		if strings.HasPrefix(f.Name, entireDir) {
+			return nil
+		}
+		checkpointFiles[f.Name] = true
+		if match(f.Name) {
+			plan.restore = append(plan.restore, f)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoint files: %w", err)
+	}
+
+	// Files tracked in HEAD are the user's committed work
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	headCommit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD commit: %w", err)
+	}
+	headTree, err := headCommit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD tree: %w", err)
+	}
+	trackedFiles := make(map[string]bool)
+	//nolint:errcheck // Error is not critical for rewind
+	_ = headTree.Files().ForEach(func(f *object.File) error {
+		trackedFiles[f.Name] = true
+		return nil
+	})
+
+	plan.repoRoot, err = GetWorktreePath()
+	if err != nil {
+		plan.repoRoot = "." // Fallback to current directory
+	}
+	err = filepath.Walk(plan.repoRoot, func(path string, info os.FileInfo, walkErr error) error {
+		if walkErr != nil {
+			return nil //nolint:nilerr // Skip filesystem errors during walk
+		}
+		relPath, relErr := filepath.Rel(plan.repoRoot, path)
+		if relErr != nil {
+			return nil //nolint:nilerr // Skip paths we can't make relative
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		// Skip special directories
+		if info.IsDir() {
+			if relPath == gitDir || relPath == claudeDir || relPath == entireDir ||
+				strings.HasPrefix(relPath, gitDir+"/") ||
+				strings.HasPrefix(relPath, claudeDir+"/") ||
+				strings.HasPrefix(relPath, entireDir+"/") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if checkpointFiles[relPath] || trackedFiles[relPath] || preservedUntrackedFiles[relPath] || !match(relPath) {
+			return nil
+		}
+		plan.delete = append(plan.delete, relPath)
+		return nil
+	})
+	if err != nil {
+		// Non-fatal: go with the files found so far
+		fmt.Fprintf(os.Stderr, "Warning: error walking directory: %v\n", err)
+	}
+
+	return plan, nil
+}
+
+// preview returns the plan as a RewindPreview.
+func (p *rewindPlan) preview() *RewindPreview {
+	preview := &RewindPreview{FilesToDelete: slices.Clone(p.delete)}
+	for _, f := range p.restore {
+		preview.FilesToRestore = append(preview.FilesToRestore, f.Name)
+	}
+	sort.Strings(preview.FilesToRestore)
+	sort.Strings(preview.FilesToDelete)
+	return preview
+}
+
+// planRewindPaths plans restoring the files in paths from a rewind point.
+// Logs-only points are user commits; their tree holds the file state too.
+func (s *ManualCommitStrategy) planRewindPaths(point RewindPoint, paths *RewindPathSelection) (*rewindPlan, error) {
+	repo, err := OpenRepository()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+	commit, err := repo.CommitObject(plumbing.NewHash(point.ID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit: %w", err)
+	}
+	var match func(string) bool
+	if paths != nil {
+		match = paths.matches
+	}
+	return s.planRewind(repo, commit, match)
+}
+
+// PreviewRewindPaths returns what restoring the files in paths from the
+// rewind point will change.
+func (s *ManualCommitStrategy) PreviewRewindPaths(point RewindPoint, paths RewindPathSelection) (*RewindPreview, error) {
+	plan, err := s.planRewindPaths(point, &paths)
+	if err != nil {
+		return nil, err
+	}
+	return plan.preview(), nil
+}
+
+// RewindPointChanges returns the files a full rewind to the point would change.
+func (s *ManualCommitStrategy) RewindPointChanges(point RewindPoint) ([]string, error) {
+	plan, err := s.planRewindPaths(point, nil)
+	if err != nil {
+		return nil, err
+	}
+	changes := slices.Clone(plan.delete)
+	for _, f := range plan.restore {
+		if !worktreeFileMatches(filepath.Join(plan.repoRoot, f.Name), f) {
+			changes = append(changes, f.Name)
+		}
+	}
+	sort.Strings(changes)
+	return changes, nil
+}
+
+// worktreeFileMatches reports whether the file on disk has the content and
+// executable bit of the checkpoint file.
+func worktreeFileMatches(path string, f *object.File) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	if (info.Mode()&0o111 != 0) != (f.Mode == filemode.Executable) {
+		return false
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // Path is inside the worktree
+	if err != nil {
+		return false
+	}
+	return plumbing.ComputeHash(plumbing.BlobObject, data) == f.Hash
+}
+
+// RewindPaths restores the files in paths from the rewind point, leaving
+// every other file alone. The session transcript is never touched; the shadow
+// branch is only reset to the checkpoint if resetSession is set.
+func (s *ManualCommitStrategy) RewindPaths(point RewindPoint, paths RewindPathSelection, resetSession bool) error {
+	if paths.IsEmpty() {
+		return errors.New("no paths to restore")
+	}
+	plan, err := s.planRewindPaths(point, &paths)
+	if err != nil {
+		return err
+	}
+	if len(plan.restore) == 0 && len(plan.delete) == 0 {
+		return fmt.Errorf("no files in the checkpoint match %s", paths)
+	}
+
+	if resetSession && point.IsPreRewind {
+		return errors.New("the session of a pre-rewind point can only be restored by undoing the rewind")
+	}
+
+	repo, err := OpenRepository()
+	if err != nil {
+		return fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	// Save the current state first so the rewind can be undone
+	s.snapshotBeforeRewind(repo, point)
+
+	if resetSession && !point.IsLogsOnly {
+		commit, err := repo.CommitObject(plumbing.NewHash(point.ID))
+		if err != nil {
+			return fmt.Errorf("failed to get commit: %w", err)
+		}
+		if err := s.resetShadowBranchToCheckpoint(repo, commit); err != nil {
+			fmt.Fprintf(os.Stderr, "[entire] Warning: failed to reset shadow branch: %v\n", err)
+		}
+	}
+
+	for _, relPath := range plan.delete {
+		if err := os.Remove(filepath.Join(plan.repoRoot, relPath)); err == nil {
+			fmt.Fprintf(os.Stderr, "  Deleted: %s\n", relPath)
+		}
+	}
+
+	for _, f := range plan.restore {
+		target := filepath.Join(plan.repoRoot, f.Name)
+		if worktreeFileMatches(target, f) {
+			continue
+		}
+		if err := writeTreeFile(target, f); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "  Restored: %s\n", f.Name)
+	}
+
+	shortID := point.ID
+	if len(shortID) >= 7 {
+		shortID = shortID[:7]
+	}
+	fmt.Println()
+	fmt.Printf("Restored %s from commit %s\n", paths, shortID)
+	fmt.Println()
+
+	return nil
+}
+
+// matches reports whether file is one of the selection's files or matches
+// one of its patterns.
+func (p RewindPathSelection) matches(file string) bool {
+	return slices.Contains(p.Files, file) || matchRewindPatterns(p.Patterns, file)
+}
+
+// matchRewindPatterns reports whether file matches any of the patterns (see RewindPathSelection).
+func matchRewindPatterns(patterns []string, file string) bool {
+	for _, pattern := range patterns {
+		if matchRewindPath(pattern, file) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchRewindPath reports whether file, or a directory containing it, matches pattern.
+func matchRewindPath(pattern, file string) bool {
+	pattern = strings.TrimSuffix(strings.TrimPrefix(filepath.ToSlash(pattern), "./"), "/")
+	if pattern == "" || pattern == "." {
+		return true
+	}
+	if file == pattern || strings.HasPrefix(file, pattern+"/") {
+		return true
+	}
+	patternParts := strings.Split(pattern, "/")
+	fileParts := strings.Split(file, "/")
+	// Matching a leading part of the path matches a directory of the file
+	for n := 1; n <= len(fileParts); n++ {
+		if matchPathSegments(patternParts, fileParts[:n]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPathSegments matches path segments against pattern segments, where a
+// "**" segment matches any number of path segments.
+func matchPathSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchPathSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, err := path.Match(pattern[0], parts[0]); err != nil || !ok {
+		return false
+	}
+	return matchPathSegments(pattern[1:], parts[1:])
+}
@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
+	_ "github.com/entireio/cli/cmd/entire/cli/agent/claudecode" // Register transcript parsers
+	_ "github.com/entireio/cli/cmd/entire/cli/agent/geminicli"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
@@ -1922,7 +1924,11 @@ func TestExtractUserPrompts(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := extractUserPrompts(tt.agentType, tt.content)
+			entries, err := agent.ParseTranscriptEntries(tt.agentType, []byte(tt.content))
+			if err != nil {
+				t.Fatalf("ParseTranscriptEntries() error = %v", err)
+			}
+			result := extractUserPrompts(entries)
 			if len(result) != len(tt.expected) {
 				t.Errorf("extractUserPrompts() returned %d prompts, want %d", len(result), len(tt.expected))
 				return
@@ -2124,8 +2130,8 @@ func TestCondenseSession_IncludesInitialAttribution(t *testing.T) {
 		metadata.InitialAttribution.AgentPercentage)
 }
 
-// TestExtractUserPromptsFromLines tests extraction of user prompts from JSONL format.
-func TestExtractUserPromptsFromLines(t *testing.T) {
+// TestExtractUserPrompts_ClaudeCodeLines tests extraction of user prompts from JSONL format.
+func TestExtractUserPrompts_ClaudeCodeLines(t *testing.T) {
 	tests := []struct {
 		name     string
 		lines    []string
@@ -2182,9 +2188,13 @@ func TestExtractUserPromptsFromLines(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := extractUserPromptsFromLines(tt.lines)
+			entries, err := agent.ParseTranscriptEntries(agent.AgentTypeClaudeCode, []byte(strings.Join(tt.lines, "\n")))
+			if err != nil {
+				t.Fatalf("ParseTranscriptEntries() error = %v", err)
+			}
+			result := extractUserPrompts(entries)
 			if len(result) != len(tt.expected) {
-				t.Errorf("extractUserPromptsFromLines() returned %d prompts, want %d", len(result), len(tt.expected))
+				t.Errorf("extractUserPrompts() returned %d prompts, want %d", len(result), len(tt.expected))
 				return
 			}
 			for i, prompt := range result {
@@ -57,9 +57,10 @@ type CondenseResult struct {
 // ExtractedSessionData contains data extracted from a shadow branch.
 type ExtractedSessionData struct {
 	Transcript          []byte   // Full transcript content for the session
-	FullTranscriptLines int      // Total line count in full transcript
+	FullTranscriptLines int      // Transcript length in positions (lines or messages, see agent.TranscriptLength)
 	Prompts             []string // All user prompts from this portion
 	Context             []byte   // Generated context.md content
 	FilesTouched        []string
-	TokenUsage          *agent.TokenUsage // Token usage calculated from transcript (since CheckpointTranscriptStart)
+	TokenUsage          *agent.TokenUsage    // Token usage calculated from transcript (since CheckpointTranscriptStart)
+	Entries             []agent.SessionEntry // Normalized transcript entries
 }
@@ -0,0 +1,474 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	cpkg "github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// snapshotBeforeRewind saves the working tree and the live transcripts of the
+// sessions a rewind to point touches, so the rewind can be undone. Without
+// sessions only the working tree is saved. Failures are reported as warnings:
+// the rewind itself goes ahead.
+func (s *ManualCommitStrategy) snapshotBeforeRewind(repo *git.Repository, point RewindPoint) {
+	var sessionIDs []string
+	agentType := point.Agent
+	switch {
+	case point.IsLogsOnly && len(point.SessionIDs) > 0:
+		sessionIDs = point.SessionIDs
+	case point.IsLogsOnly && point.SessionID != "":
+		sessionIDs = []string{point.SessionID}
+	default:
+		if commit, err := repo.CommitObject(plumbing.NewHash(point.ID)); err == nil {
+			sessionIDs = trailers.ParseAllSessions(commit.Message)
+		}
+	}
+	if agentType == "" && len(sessionIDs) > 0 {
+		if state, err := s.loadSessionState(sessionIDs[len(sessionIDs)-1]); err == nil && state != nil {
+			agentType = state.AgentType
+		}
+	}
+
+	hash, err := s.savePreRewindSnapshot(repo, point.ID, sessionIDs, agentType)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[entire] Warning: failed to save the state before rewinding: %v\n", err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "[entire] Saved the state before rewinding as %s (undo with 'entire rewind --undo')\n", hash.String()[:7])
+}
+
+// savePreRewindSnapshot writes the pre-rewind snapshot for a rewind to target.
+func (s *ManualCommitStrategy) savePreRewindSnapshot(repo *git.Repository, target string, sessionIDs []string, agentType agent.AgentType) (plumbing.Hash, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	worktreeID, err := currentWorktreeID()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	store, err := s.getCheckpointStore()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to get checkpoint store: %w", err)
+	}
+
+	transcripts := make(map[string][]byte)
+	for _, sessionID := range sessionIDs {
+		transcriptPath, err := s.liveTranscriptPath(sessionID, agentType)
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(transcriptPath) //nolint:gosec // Path is the agent's session file
+		if err != nil {
+			continue // No transcript yet
+		}
+		transcripts[sessionID] = data
+	}
+
+	shortTarget := target
+	if len(shortTarget) > 7 {
+		shortTarget = shortTarget[:7]
+	}
+	authorName, authorEmail := GetGitAuthorFromRepo(repo)
+	hash, err := store.WriteRewindSnapshot(context.Background(), cpkg.WriteRewindSnapshotOptions{
+		BaseCommit:    head.Hash().String(),
+		WorktreeID:    worktreeID,
+		Target:        target,
+		SessionIDs:    sessionIDs,
+		Transcripts:   transcripts,
+		Agent:         agentType,
+		CommitMessage: "Before rewind to " + shortTarget,
+		AuthorName:    authorName,
+		AuthorEmail:   authorEmail,
+	})
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to write pre-rewind snapshot: %w", err)
+	}
+	return hash, nil
+}
+
+// liveTranscriptPath returns the file the agent keeps the transcript of a session in.
+func (s *ManualCommitStrategy) liveTranscriptPath(sessionID string, agentType agent.AgentType) (string, error) {
+	if state, err := s.loadSessionState(sessionID); err == nil && state != nil {
+		if state.TranscriptPath != "" {
+			return state.TranscriptPath, nil
+		}
+		if agentType == "" {
+			agentType = state.AgentType
+		}
+	}
+	if agentType == "" {
+		return "", fmt.Errorf("unknown agent for session %s", sessionID)
+	}
+	ag, err := agent.GetByAgentType(agentType)
+	if err != nil {
+		return "", fmt.Errorf("getting agent: %w", err)
+	}
+	repoRoot, err := paths.RepoRoot()
+	if err != nil {
+		return "", fmt.Errorf("failed to get repository root: %w", err)
+	}
+	sessionDir, err := ag.GetSessionDir(repoRoot)
+	if err != nil {
+		return "", fmt.Errorf("failed to get agent session directory: %w", err)
+	}
+	return filepath.Join(sessionDir, ag.ExtractAgentSessionID(sessionID)+".jsonl"), nil
+}
+
+// currentWorktreeID returns the internal git worktree identifier of the current worktree.
+func currentWorktreeID() (string, error) {
+	worktreePath, err := GetWorktreePath()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree path: %w", err)
+	}
+	worktreeID, err := paths.GetWorktreeID(worktreePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree ID: %w", err)
+	}
+	return worktreeID, nil
+}
+
+// GetPreRewindPoint returns the state saved before the last rewind as a
+// rewind point, or nil if nothing was rewound since HEAD last moved (other
+// than by the rewind itself, see MovePreRewindPoint).
+func (s *ManualCommitStrategy) GetPreRewindPoint() (*RewindPoint, error) {
+	repo, err := OpenRepository()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	worktreeID, err := currentWorktreeID()
+	if err != nil {
+		return nil, err
+	}
+	store, err := s.getCheckpointStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checkpoint store: %w", err)
+	}
+
+	info, err := store.ReadRewindSnapshot(context.Background(), head.Hash().String(), worktreeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pre-rewind snapshot: %w", err)
+	}
+	if info == nil {
+		return nil, nil //nolint:nilnil // No rewind to undo
+	}
+
+	point := &RewindPoint{
+		ID:           info.CommitHash.String(),
+		Message:      info.Message,
+		Date:         info.Timestamp,
+		IsPreRewind:  true,
+		Agent:        info.Agent,
+		SessionCount: len(info.SessionIDs),
+		SessionIDs:   info.SessionIDs,
+	}
+	if len(info.SessionIDs) > 0 {
+		point.SessionID = info.SessionIDs[len(info.SessionIDs)-1]
+		point.MetadataDir = paths.SessionMetadataDirFromSessionID(point.SessionID)
+	}
+	return point, nil
+}
+
+// MovePreRewindPoint keeps the state saved before the last rewind undoable
+// after the rewind moved HEAD from fromHead (a logs-only rewind that resets
+// the branch). Undoing it then moves HEAD back to fromHead.
+func (s *ManualCommitStrategy) MovePreRewindPoint(fromHead string) error {
+	repo, err := OpenRepository()
+	if err != nil {
+		return fmt.Errorf("failed to open git repository: %w", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	worktreeID, err := currentWorktreeID()
+	if err != nil {
+		return err
+	}
+	store, err := s.getCheckpointStore()
+	if err != nil {
+		return fmt.Errorf("failed to get checkpoint store: %w", err)
+	}
+	if err := store.MoveRewindSnapshot(context.Background(), fromHead, head.Hash().String(), worktreeID); err != nil {
+		return fmt.Errorf("failed to move pre-rewind snapshot: %w", err)
+	}
+	return nil
+}
+
+// rewindChanges compares the flattened working tree states from and to,
+// returning the files to write and the files to delete to go from one to the
+// other. Infrastructure paths are left out.
+func rewindChanges(repo *git.Repository, from, to *object.Tree) (restore []*object.File, remove []string, err error) {
+	fromEntries := make(map[string]object.TreeEntry)
+	if err := cpkg.FlattenTree(repo, from, "", fromEntries); err != nil {
+		return nil, nil, fmt.Errorf("failed to flatten tree: %w", err)
+	}
+	err = to.Files().ForEach(func(f *object.File) error {
+		if strings.HasPrefix(f.Name, entireDir) {
+			return nil
+		}
+		if entry, ok := fromEntries[f.Name]; !ok || entry.Hash != f.Hash || entry.Mode != f.Mode {
+			restore = append(restore, f)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list snapshot files: %w", err)
+	}
+	for name := range fromEntries {
+		if strings.HasPrefix(name, entireDir) {
+			continue
+		}
+		if _, err := to.File(name); errors.Is(err, object.ErrFileNotFound) {
+			remove = append(remove, name)
+		}
+	}
+	sort.Strings(remove)
+	return restore, remove, nil
+}
+
+// previewUndoRewind returns what restoring a pre-rewind snapshot will change.
+func (s *ManualCommitStrategy) previewUndoRewind(repo *git.Repository, point RewindPoint) (*RewindPreview, error) {
+	snapshot, err := repo.CommitObject(plumbing.NewHash(point.ID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pre-rewind snapshot: %w", err)
+	}
+	snapshotTree, err := snapshot.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree: %w", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	store, err := s.getCheckpointStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checkpoint store: %w", err)
+	}
+	currentHash, err := store.BuildWorktreeTree(context.Background(), head.Hash().String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the working tree: %w", err)
+	}
+	currentTree, err := repo.TreeObject(currentHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree: %w", err)
+	}
+
+	restore, remove, err := rewindChanges(repo, currentTree, snapshotTree)
+	if err != nil {
+		return nil, err
+	}
+	preview := &RewindPreview{FilesToDelete: remove}
+	for _, f := range restore {
+		preview.FilesToRestore = append(preview.FilesToRestore, f.Name)
+	}
+	return preview, nil
+}
+
+// undoRewind restores the working tree, live transcripts and shadow branch
+// saved in a pre-rewind snapshot, and HEAD if the rewind moved it. The current
+// state is saved first, so undoing again redoes the rewind.
+func (s *ManualCommitStrategy) undoRewind(repo *git.Repository, point RewindPoint) error {
+	snapshot, err := repo.CommitObject(plumbing.NewHash(point.ID))
+	if err != nil {
+		return fmt.Errorf("failed to get pre-rewind snapshot: %w", err)
+	}
+	if _, found := trailers.ParsePreRewind(snapshot.Message); !found {
+		return fmt.Errorf("commit %s is not a pre-rewind snapshot", point.ID)
+	}
+	snapshotTree, err := snapshot.Tree()
+	if err != nil {
+		return fmt.Errorf("failed to get tree: %w", err)
+	}
+	sessionIDs := trailers.ParseAllSessions(snapshot.Message)
+	agentType := point.Agent
+	if agentName, found := trailers.ParseAgent(snapshot.Message); found {
+		agentType = agent.AgentType(agentName)
+	}
+
+	// Save the current state, which is also what the changes are worked out from
+	currentHash, err := s.savePreRewindSnapshot(repo, point.ID, sessionIDs, agentType)
+	if err != nil {
+		return fmt.Errorf("failed to save the current state: %w", err)
+	}
+	current, err := repo.CommitObject(currentHash)
+	if err != nil {
+		return fmt.Errorf("failed to get commit: %w", err)
+	}
+	currentTree, err := current.Tree()
+	if err != nil {
+		return fmt.Errorf("failed to get tree: %w", err)
+	}
+
+	// Move HEAD back if the rewind reset the branch; the changes are then
+	// worked out from the state after the reset
+	if baseCommit, found := trailers.ParseBaseCommit(snapshot.Message); found {
+		resetTree, err := s.resetToSnapshotBase(repo, baseCommit)
+		if err != nil {
+			return err
+		}
+		if resetTree != nil {
+			currentTree = resetTree
+		}
+	}
+
+	restore, remove, err := rewindChanges(repo, currentTree, snapshotTree)
+	if err != nil {
+		return err
+	}
+	repoRoot, err := GetWorktreePath()
+	if err != nil {
+		repoRoot = "."
+	}
+	for _, relPath := range remove {
+		if err := os.Remove(filepath.Join(repoRoot, relPath)); err == nil {
+			fmt.Fprintf(os.Stderr, "  Deleted: %s\n", relPath)
+		}
+	}
+	for _, f := range restore {
+		if err := writeTreeFile(filepath.Join(repoRoot, f.Name), f); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "  Restored: %s\n", f.Name)
+	}
+
+	// Put back the live transcripts; sessions saved without one had none
+	for _, sessionID := range sessionIDs {
+		transcriptPath, err := s.liveTranscriptPath(sessionID, agentType)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[entire] Warning: can't restore transcript of session %s: %v\n", sessionID, err)
+			continue
+		}
+		f, err := snapshotTree.File(paths.SessionMetadataDirFromSessionID(sessionID) + "/" + paths.TranscriptFileName)
+		if errors.Is(err, object.ErrFileNotFound) {
+			if removeErr := os.Remove(transcriptPath); removeErr == nil {
+				fmt.Fprintf(os.Stderr, "  Removed transcript: %s\n", transcriptPath)
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read transcript of session %s: %w", sessionID, err)
+		}
+		contents, err := f.Contents()
+		if err != nil {
+			return fmt.Errorf("failed to read transcript of session %s: %w", sessionID, err)
+		}
+		if err := os.MkdirAll(filepath.Dir(transcriptPath), 0o750); err != nil {
+			return fmt.Errorf("failed to create transcript directory: %w", err)
+		}
+		if err := os.WriteFile(transcriptPath, []byte(contents), 0o600); err != nil {
+			return fmt.Errorf("failed to write transcript: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "  Restored transcript: %s\n", transcriptPath)
+	}
+
+	// Move the shadow branch back to where it was before the rewind
+	if len(snapshot.ParentHashes) > 0 {
+		head, err := repo.Head()
+		if err != nil {
+			return fmt.Errorf("failed to get HEAD: %w", err)
+		}
+		worktreeID, err := currentWorktreeID()
+		if err != nil {
+			return err
+		}
+		shadowBranchName := getShadowBranchNameForCommit(head.Hash().String(), worktreeID)
+		ref := plumbing.NewHashReference(plumbing.NewBranchReferenceName(shadowBranchName), snapshot.ParentHashes[0])
+		if err := repo.Storer.SetReference(ref); err != nil {
+			return fmt.Errorf("failed to restore shadow branch: %w", err)
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("Restored the state from before the rewind (%s)\n", point.Message)
+	fmt.Println()
+	return nil
+}
+
+// resetToSnapshotBase moves HEAD back to the commit a pre-rewind snapshot was
+// taken on, if a rewind moved it since, keeping the snapshot of the current
+// state (the redo point) undoable. Returns the working tree state after the
+// reset, or nil if HEAD is already there.
+func (s *ManualCommitStrategy) resetToSnapshotBase(repo *git.Repository, baseCommit string) (*object.Tree, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	if head.Hash().String() == baseCommit {
+		return nil, nil //nolint:nilnil // HEAD didn't move
+	}
+	shortID, err := HardResetWithProtection(plumbing.NewHash(baseCommit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to move HEAD back: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "  Reset HEAD to %s\n", shortID)
+	if err := s.MovePreRewindPoint(head.Hash().String()); err != nil {
+		return nil, err
+	}
+
+	store, err := s.getCheckpointStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checkpoint store: %w", err)
+	}
+	treeHash, err := store.BuildWorktreeTree(context.Background(), baseCommit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the working tree: %w", err)
+	}
+	tree, err := repo.TreeObject(treeHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree: %w", err)
+	}
+	return tree, nil
+}
+
+// writeTreeFile writes a file of a checkpoint tree to path, as a symlink,
+// executable or regular file according to its mode.
+func writeTreeFile(path string, f *object.File) error {
+	contents, err := f.Contents()
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", f.Name, err)
+	}
+	//nolint:gosec // G301: Need 0o755 for user directories during rewind
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", f.Name, err)
+	}
+	// Replace symlinks rather than writing through them
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		_ = os.Remove(path) //nolint:errcheck // Write below reports any problem
+	}
+	if f.Mode == filemode.Symlink {
+		if err := os.Symlink(contents, path); err != nil {
+			return fmt.Errorf("failed to create symlink %s: %w", f.Name, err)
+		}
+		return nil
+	}
+	var perm os.FileMode = 0o644
+	if f.Mode == filemode.Executable {
+		perm = 0o755
+	}
+	if err := os.WriteFile(path, []byte(contents), perm); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", f.Name, err)
+	}
+	if err := os.Chmod(path, perm); err != nil {
+		return fmt.Errorf("failed to set mode of %s: %w", f.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package strategy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestManualCommitStrategy_UndoRewind verifies that a rewind saves the working
+// tree and live transcript first, that rewinding to that pre-rewind point puts
+// them back exactly, and that undoing again redoes the rewind.
+func TestManualCommitStrategy_UndoRewind(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	head, err := repo.Head()
+	require.NoError(t, err)
+
+	// A checkpoint that added app.js, left behind as HEAD moves back
+	const sessionID = "2026-01-01-undo-session"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.js"), []byte("checkpoint\n"), 0o644))
+	runGit(t, dir, "add", "app.js")
+	runGit(t, dir, "commit", "-q", "-m", "Checkpoint\n\nEntire-Session: "+sessionID)
+	checkpointHead, err := repo.Head()
+	require.NoError(t, err)
+	runGit(t, dir, "reset", "-q", "--hard", head.Hash().String())
+
+	transcriptPath := filepath.Join(t.TempDir(), "transcript.jsonl")
+	require.NoError(t, os.WriteFile(transcriptPath, []byte("line 1\nline 2\n"), 0o600))
+	s := &ManualCommitStrategy{}
+	require.NoError(t, s.saveSessionState(&SessionState{
+		SessionID:      sessionID,
+		BaseCommit:     head.Hash().String(),
+		StartedAt:      time.Now(),
+		WorktreePath:   dir,
+		TranscriptPath: transcriptPath,
+		AgentType:      agent.AgentTypeClaudeCode,
+	}))
+
+	// Uncommitted human edits the rewind discards
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "test.txt"), []byte("human edit\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.md"), []byte("notes\n"), 0o644))
+
+	point := RewindPoint{ID: checkpointHead.Hash().String(), Message: "Checkpoint", Date: time.Now()}
+	require.NoError(t, s.Rewind(point))
+	// The CLI truncates the transcript after restoring files
+	require.NoError(t, os.WriteFile(transcriptPath, []byte("line 1\n"), 0o600))
+
+	assertFile := func(name, want string) {
+		t.Helper()
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err, name)
+		assert.Equal(t, want, string(got), name)
+	}
+	assertFile("app.js", "checkpoint\n")
+	assert.NoFileExists(t, filepath.Join(dir, "notes.md"))
+
+	undo, err := s.GetPreRewindPoint()
+	require.NoError(t, err)
+	require.NotNil(t, undo)
+	assert.True(t, undo.IsPreRewind)
+	assert.Equal(t, sessionID, undo.SessionID)
+	assert.Equal(t, "Before rewind to "+point.ID[:7], undo.Message)
+
+	preview, err := s.PreviewRewind(*undo)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app.js"}, preview.FilesToDelete)
+	assert.ElementsMatch(t, []string{"notes.md", "test.txt"}, preview.FilesToRestore)
+
+	require.NoError(t, s.Rewind(*undo))
+	assertFile("test.txt", "human edit\n")
+	assertFile("notes.md", "notes\n")
+	assert.NoFileExists(t, filepath.Join(dir, "app.js"))
+	transcript, err := os.ReadFile(transcriptPath)
+	require.NoError(t, err)
+	assert.Equal(t, "line 1\nline 2\n", string(transcript))
+
+	// Undoing the undo redoes the rewind
+	redo, err := s.GetPreRewindPoint()
+	require.NoError(t, err)
+	require.NotNil(t, redo)
+	assert.NotEqual(t, undo.ID, redo.ID)
+	require.NoError(t, s.Rewind(*redo))
+	assertFile("app.js", "checkpoint\n")
+	assert.NoFileExists(t, filepath.Join(dir, "notes.md"))
+	transcript, err = os.ReadFile(transcriptPath)
+	require.NoError(t, err)
+	assert.Equal(t, "line 1\n", string(transcript))
+}
+
+// TestManualCommitStrategy_UndoRewind_NoSessions verifies that a rewind to a
+// point without sessions still saves the working tree, so it can be undone.
+func TestManualCommitStrategy_UndoRewind_NoSessions(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	head, err := repo.Head()
+	require.NoError(t, err)
+
+	// A commit without session trailers, left behind as HEAD moves back
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.js"), []byte("checkpoint\n"), 0o644))
+	runGit(t, dir, "add", "app.js")
+	runGit(t, dir, "commit", "-q", "-m", "Checkpoint")
+	checkpointHead, err := repo.Head()
+	require.NoError(t, err)
+	runGit(t, dir, "reset", "-q", "--hard", head.Hash().String())
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.md"), []byte("notes\n"), 0o644))
+
+	s := &ManualCommitStrategy{}
+	require.NoError(t, s.Rewind(RewindPoint{ID: checkpointHead.Hash().String(), Message: "Checkpoint", Date: time.Now()}))
+	assert.NoFileExists(t, filepath.Join(dir, "notes.md"))
+
+	undo, err := s.GetPreRewindPoint()
+	require.NoError(t, err)
+	require.NotNil(t, undo)
+	assert.Empty(t, undo.SessionIDs)
+	require.NoError(t, s.Rewind(*undo))
+	got, err := os.ReadFile(filepath.Join(dir, "notes.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "notes\n", string(got))
+	assert.NoFileExists(t, filepath.Join(dir, "app.js"))
+}
@@ -0,0 +1,118 @@
+package strategy
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// RewrittenCommit is one old → new commit pair reported by the git post-rewrite hook.
+type RewrittenCommit struct {
+	OldSHA string
+	NewSHA string
+}
+
+// ParsePostRewriteInput parses the post-rewrite hook's stdin.
+// Each line has the form "<old-sha> <new-sha> [<extra-info>]"; blank and malformed
+// lines are skipped.
+func ParsePostRewriteInput(r io.Reader) ([]RewrittenCommit, error) {
+	var rewrites []RewrittenCommit
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		if !plumbing.IsHash(fields[0]) || !plumbing.IsHash(fields[1]) {
+			continue
+		}
+		rewrites = append(rewrites, RewrittenCommit{OldSHA: fields[0], NewSHA: fields[1]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read post-rewrite input: %w", err)
+	}
+	return rewrites, nil
+}
+
+// rewriteMap returns the old → new SHA mapping for a list of rewrites.
+// For squashes (several old commits → one new commit) each old SHA maps to the same new SHA.
+func rewriteMap(rewrites []RewrittenCommit) map[string]string {
+	m := make(map[string]string, len(rewrites))
+	for _, rw := range rewrites {
+		m[rw.OldSHA] = rw.NewSHA
+	}
+	return m
+}
+
+// recordCommitRewrites carries commit → checkpoint links over to rewritten commits.
+// A link is recorded on entire/checkpoints/v1 when the old commit was linked to a checkpoint
+// (via trailer or an earlier link) and the new commit has no Entire-Checkpoint trailer.
+// New commits that still carry a trailer need no link.
+// Returns the checkpoint ID for each old SHA that resolved to one.
+func recordCommitRewrites(ctx context.Context, repo *git.Repository, rewriteType string, rewrites []RewrittenCommit) (map[string]id.CheckpointID, error) {
+	store := checkpoint.NewGitStore(repo)
+	resolved := make(map[string]id.CheckpointID)
+	var links []checkpoint.CommitLink
+	linked := make(map[string]bool)
+
+	for _, rw := range rewrites {
+		oldCommit, err := repo.CommitObject(plumbing.NewHash(rw.OldSHA))
+		if err != nil {
+			continue // Old commit may have been pruned already
+		}
+		cpID, found := store.CheckpointIDForCommit(ctx, oldCommit)
+		if !found {
+			continue
+		}
+		resolved[rw.OldSHA] = cpID
+
+		newCommit, err := repo.CommitObject(plumbing.NewHash(rw.NewSHA))
+		if err != nil {
+			continue
+		}
+		if _, hasTrailer := trailers.ParseCheckpoint(newCommit.Message); hasTrailer {
+			continue // Link survived the rewrite
+		}
+		if _, hasNote := store.ReadCommitNote(ctx, newCommit.Hash); hasNote {
+			continue // Linked by post-commit (amend with commit notes)
+		}
+		// Squash: several old commits collapse into one new commit. Keep the first link.
+		if linked[rw.NewSHA] {
+			continue
+		}
+		linked[rw.NewSHA] = true
+
+		links = append(links, checkpoint.CommitLink{
+			CommitSHA:      rw.NewSHA,
+			CheckpointID:   cpID,
+			OriginalCommit: rw.OldSHA,
+			RewriteType:    rewriteType,
+			RecordedAt:     time.Now().UTC(),
+		})
+	}
+
+	if len(links) == 0 {
+		return resolved, nil
+	}
+
+	if err := store.WriteCommitLinks(ctx, links); err != nil {
+		return resolved, fmt.Errorf("failed to record commit links: %w", err)
+	}
+	logging.Info(ctx, "recorded commit links for rewritten commits",
+		slog.String("rewrite_type", rewriteType),
+		slog.Int("links", len(links)),
+	)
+	return resolved, nil
+}
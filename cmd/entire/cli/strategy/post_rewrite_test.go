@@ -0,0 +1,123 @@
+package strategy
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePostRewriteInput(t *testing.T) {
+	oldSHA := strings.Repeat("a", 40)
+	newSHA := strings.Repeat("b", 40)
+	input := oldSHA + " " + newSHA + "\n" +
+		"\n" +
+		"not-a-sha " + newSHA + "\n" +
+		newSHA + " " + oldSHA + " extra-info\n"
+
+	rewrites, err := ParsePostRewriteInput(strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, []RewrittenCommit{
+		{OldSHA: oldSHA, NewSHA: newSHA},
+		{OldSHA: newSHA, NewSHA: oldSHA},
+	}, rewrites)
+}
+
+// TestPostRewrite_Amend_MigratesSessionAndRecordsLink verifies that amending a
+// linked commit without its trailer moves the session to the new commit and
+// records a link so the checkpoint can still be resolved from the new SHA.
+func TestPostRewrite_Amend_MigratesSessionAndRecordsLink(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+
+	s := &ManualCommitStrategy{}
+	sessionID := "test-postrewrite-amend"
+	cpID := id.MustCheckpointID("c3d4e5f6a1b2")
+
+	commitWithCheckpointTrailer(t, repo, dir, cpID.String())
+	setupSessionWithCheckpoint(t, s, repo, dir, sessionID)
+
+	state, err := s.loadSessionState(sessionID)
+	require.NoError(t, err)
+	oldBase := state.BaseCommit
+	state.AttributionBaseCommit = oldBase
+	require.NoError(t, s.saveSessionState(state))
+
+	// Amend with a message that drops the trailer
+	cmd := exec.CommandContext(context.Background(), "git", "commit", "--amend", "--no-verify", "-m", "reworded")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git commit --amend failed: %s", output)
+
+	head, err := repo.Head()
+	require.NoError(t, err)
+	newBase := head.Hash().String()
+	require.NotEqual(t, oldBase, newBase)
+
+	err = s.PostRewrite("amend", []RewrittenCommit{{OldSHA: oldBase, NewSHA: newBase}})
+	require.NoError(t, err)
+
+	state, err = s.loadSessionState(sessionID)
+	require.NoError(t, err)
+	assert.Equal(t, newBase, state.BaseCommit)
+	assert.Equal(t, newBase, state.AttributionBaseCommit)
+	assert.Equal(t, cpID, state.LastCheckpointID)
+
+	// Shadow branch follows the new base commit
+	newShadow := getShadowBranchNameForCommit(newBase, state.WorktreeID)
+	_, err = repo.Reference(plumbing.NewBranchReferenceName(newShadow), true)
+	require.NoError(t, err, "shadow branch should be renamed to the new base commit")
+
+	// New commit resolves to the original checkpoint via the recorded link
+	newCommit, err := repo.CommitObject(head.Hash())
+	require.NoError(t, err)
+	got, found := checkpoint.NewGitStore(repo).CheckpointIDForCommit(context.Background(), newCommit)
+	assert.True(t, found, "rewritten commit should resolve to a checkpoint")
+	assert.Equal(t, cpID, got)
+}
+
+// TestPostRewrite_TrailerPreserved_NoLink verifies that no link is recorded when
+// the rewritten commit still carries its Entire-Checkpoint trailer.
+func TestPostRewrite_TrailerPreserved_NoLink(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+
+	cpID := id.MustCheckpointID("d4e5f6a1b2c3")
+	commitWithCheckpointTrailer(t, repo, dir, cpID.String())
+	head, err := repo.Head()
+	require.NoError(t, err)
+	oldSHA := head.Hash().String()
+
+	cmd := exec.CommandContext(context.Background(), "git", "commit", "--amend", "--no-verify",
+		"-m", "reworded\n\n"+trailers.CheckpointTrailerKey+": "+cpID.String())
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git commit --amend failed: %s", output)
+
+	head, err = repo.Head()
+	require.NoError(t, err)
+	newSHA := head.Hash().String()
+	require.NotEqual(t, oldSHA, newSHA)
+
+	s := &ManualCommitStrategy{}
+	require.NoError(t, s.PostRewrite("amend", []RewrittenCommit{{OldSHA: oldSHA, NewSHA: newSHA}}))
+
+	link, err := checkpoint.NewGitStore(repo).ReadCommitLink(context.Background(), newSHA)
+	require.NoError(t, err)
+	assert.Nil(t, link, "no link should be recorded when the trailer survives")
+}
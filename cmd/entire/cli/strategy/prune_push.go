@@ -0,0 +1,270 @@
+package strategy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// pendingForcePushFileName is the file in the git common dir recording a
+// rewrite of the metadata branch by `entire prune` that still has to be
+// force-pushed (.git/entire-prune-pending.json).
+const pendingForcePushFileName = "entire-prune-pending.json"
+
+// pendingForcePush records a pruned metadata branch until it has been pushed
+// to every remote. Until then, pre-push force-pushes the branch instead of
+// merging the remote history back, which would restore the pruned data.
+type pendingForcePush struct {
+	Branch string `json:"branch"`
+
+	// OldTip is the branch tip before the first unpushed prune. Remote
+	// branches it contains have nothing that isn't in the pruned branch.
+	OldTip string `json:"old_tip"`
+
+	// Plan lists everything pruned since the branch was last pushed everywhere
+	Plan checkpoint.PrunePlan `json:"plan"`
+
+	// Pushed lists the remotes the pruned branch has been force-pushed to
+	Pushed []string `json:"pushed,omitempty"`
+}
+
+// RecordPrunedMetadataBranch records that the metadata branch was rewritten by
+// a prune, so the next pre-push force-pushes it to each remote. Plans of
+// prunes that haven't been pushed yet are combined.
+func RecordPrunedMetadataBranch(branchName string, oldTip plumbing.Hash, plan checkpoint.PrunePlan) error {
+	pending := loadPendingForcePush()
+	if pending == nil || pending.Branch != branchName {
+		pending = &pendingForcePush{Branch: branchName, OldTip: oldTip.String()}
+	}
+	pending.Plan.Remove = mergeCheckpointIDs(pending.Plan.Remove, plan.Remove)
+	pending.Plan.DropTranscripts = mergeCheckpointIDs(pending.Plan.DropTranscripts, plan.DropTranscripts)
+	pending.Pushed = nil
+	return savePendingForcePush(pending)
+}
+
+func mergeCheckpointIDs[T ~string](a, b []T) []T {
+	merged := append(slices.Clone(a), b...)
+	slices.Sort(merged)
+	return slices.Compact(merged)
+}
+
+func pendingForcePushPath() (string, error) {
+	commonDir, err := GetGitCommonDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(commonDir, pendingForcePushFileName), nil
+}
+
+// loadPendingForcePush returns the pending force push, or nil if there is none.
+func loadPendingForcePush() *pendingForcePush {
+	filePath, err := pendingForcePushPath()
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(filePath) //nolint:gosec // Path is in the git common dir
+	if err != nil {
+		return nil
+	}
+	var pending pendingForcePush
+	if err := json.Unmarshal(data, &pending); err != nil || pending.Branch == "" {
+		return nil
+	}
+	return &pending
+}
+
+func savePendingForcePush(pending *pendingForcePush) error {
+	filePath, err := pendingForcePushPath()
+	if err != nil {
+		return err
+	}
+	data, err := jsonutil.MarshalIndentWithNewline(pending, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal pending force push: %w", err)
+	}
+	if err := os.WriteFile(filePath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write pending force push: %w", err)
+	}
+	return nil
+}
+
+// forcePushPrunedSessions pushes a pruned metadata branch. Checkpoints that
+// reached the remote after the prune are merged in first, without the pruned
+// data and without the remote history. The push uses --force-with-lease, so
+// checkpoints pushed concurrently by someone else are never overwritten.
+func forcePushPrunedSessions(repo *git.Repository, target CheckpointRemote, branchName string, pending *pendingForcePush) error {
+	fmt.Fprintf(os.Stderr, "[entire] Pushing pruned session logs to %s...\n", target.Remote)
+
+	remoteTip, err := fetchSessionsBranchTip(target)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[entire] Warning: couldn't fetch remote session logs: %v\n", err)
+		return nil // Don't fail the main push
+	}
+
+	oldTip := plumbing.NewHash(pending.OldTip)
+	if !remoteTip.IsZero() && !isAncestorOf(repo, remoteTip, oldTip) {
+		if err := mergePrunedSessions(repo, branchName, remoteTip, oldTip, pending.Plan); err != nil {
+			fmt.Fprintf(os.Stderr, "[entire] Warning: couldn't sync sessions: %v\n", err)
+			return nil
+		}
+	}
+
+	lease := "--force-with-lease=" + target.Ref + ":"
+	if !remoteTip.IsZero() {
+		lease += remoteTip.String()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	// Use --no-verify to prevent recursive hook calls
+	cmd := exec.CommandContext(ctx, "git", "push", "--no-verify", lease, target.Remote, target.PushRefspec(branchName))
+	cmd.Stdin = nil // Disconnect stdin to prevent hanging in hook context
+	if output, err := cmd.CombinedOutput(); err != nil {
+		fmt.Fprintf(os.Stderr, "[entire] Warning: failed to push pruned sessions, will retry on next push: %s\n", strings.TrimSpace(string(output)))
+		return nil
+	}
+
+	recordPushedSessions(repo, target, branchName)
+
+	// With a dedicated checkpoint remote, that's the only remote to update
+	pending.Pushed = append(pending.Pushed, target.Remote)
+	if !isCheckpointRemoteConfigured() {
+		remotes, err := repo.Remotes()
+		if err != nil {
+			return nil //nolint:nilerr // Keep the record; the next push retries
+		}
+		for _, r := range remotes {
+			if !slices.Contains(pending.Pushed, r.Config().Name) {
+				if err := savePendingForcePush(pending); err != nil {
+					fmt.Fprintf(os.Stderr, "[entire] Warning: %v\n", err)
+				}
+				return nil
+			}
+		}
+	}
+	if filePath, err := pendingForcePushPath(); err == nil {
+		_ = os.Remove(filePath)
+	}
+	return nil
+}
+
+// fetchSessionsBranchTip fetches the metadata branch from the remote and
+// returns its tip, or the zero hash if the remote doesn't have the branch.
+func fetchSessionsBranchTip(target CheckpointRemote) (plumbing.Hash, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	lsRemote := exec.CommandContext(ctx, "git", "ls-remote", target.Remote, target.Ref)
+	lsRemote.Stdin = nil
+	output, err := lsRemote.Output()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("ls-remote failed: %w", err)
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return plumbing.ZeroHash, nil
+	}
+
+	fetchCmd := exec.CommandContext(ctx, "git", "fetch", target.Remote, target.FetchRefspec())
+	fetchCmd.Stdin = nil
+	if output, err := fetchCmd.CombinedOutput(); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("fetch failed: %s", output)
+	}
+	return plumbing.NewHash(fields[0]), nil
+}
+
+// isAncestorOf reports whether commit a is reachable from commit b. Returns
+// false if either commit isn't available.
+func isAncestorOf(repo *git.Repository, a, b plumbing.Hash) bool {
+	if a == b {
+		return true
+	}
+	commitA, err := repo.CommitObject(a)
+	if err != nil {
+		return false
+	}
+	commitB, err := repo.CommitObject(b)
+	if err != nil {
+		return false
+	}
+	ancestor, err := commitA.IsAncestor(commitB)
+	return err == nil && ancestor
+}
+
+// mergePrunedSessions adds what the remote branch gained since the prune to
+// the local pruned branch, in a commit whose only parent is the local tip so
+// the remote history (and the pruned data in it) isn't brought back. Files of
+// pruned checkpoints, and files unchanged since oldTip, are not taken from the
+// remote.
+func mergePrunedSessions(repo *git.Repository, branchName string, remoteTip, oldTip plumbing.Hash, plan checkpoint.PrunePlan) error {
+	localRef, err := repo.Reference(plumbing.NewBranchReferenceName(branchName), true)
+	if err != nil {
+		return fmt.Errorf("failed to get local ref: %w", err)
+	}
+	entries, err := flattenCommitTree(repo, localRef.Hash())
+	if err != nil {
+		return err
+	}
+	remoteEntries, err := flattenCommitTree(repo, remoteTip)
+	if err != nil {
+		return err
+	}
+	oldEntries, err := flattenCommitTree(repo, oldTip)
+	if err != nil {
+		// The pre-prune history may have been garbage collected
+		oldEntries = nil
+	}
+
+	for entryPath, entry := range remoteEntries {
+		if old, ok := oldEntries[entryPath]; ok && old.Hash == entry.Hash {
+			continue
+		}
+		if !plan.Contains(entryPath) {
+			entries[entryPath] = entry
+		}
+	}
+
+	treeHash, err := checkpoint.BuildTreeFromEntries(repo, entries)
+	if err != nil {
+		return fmt.Errorf("failed to build merged tree: %w", err)
+	}
+	commitHash, err := createMergeCommitCommon(repo, treeHash, []plumbing.Hash{localRef.Hash()},
+		"Merge remote session logs after prune")
+	if err != nil {
+		return fmt.Errorf("failed to create merge commit: %w", err)
+	}
+	newRef := plumbing.NewHashReference(plumbing.NewBranchReferenceName(branchName), commitHash)
+	if err := repo.Storer.SetReference(newRef); err != nil {
+		return fmt.Errorf("failed to update branch ref: %w", err)
+	}
+	return nil
+}
+
+// flattenCommitTree returns the flattened tree entries of a commit.
+func flattenCommitTree(repo *git.Repository, hash plumbing.Hash) (map[string]object.TreeEntry, error) {
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", hash, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree of commit %s: %w", hash, err)
+	}
+	entries := make(map[string]object.TreeEntry)
+	if err := checkpoint.FlattenTree(repo, tree, "", entries); err != nil {
+		return nil, fmt.Errorf("failed to flatten tree: %w", err)
+	}
+	return entries, nil
+}
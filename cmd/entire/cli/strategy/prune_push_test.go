@@ -0,0 +1,104 @@
+package strategy
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.CommandContext(context.Background(), "git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %v: %s", args, output)
+}
+
+func writePruneTestCheckpoint(t *testing.T, repo *git.Repository, cpID id.CheckpointID) {
+	t.Helper()
+	err := checkpoint.NewGitStore(repo).WriteCommitted(context.Background(), checkpoint.WriteCommittedOptions{
+		CheckpointID:     cpID,
+		SessionID:        "session-" + cpID.String(),
+		Strategy:         "manual-commit",
+		Transcript:       []byte(`{"type":"user"}` + "\n"),
+		CheckpointsCount: 1,
+		AuthorName:       "Test",
+		AuthorEmail:      "test@test.com",
+	})
+	require.NoError(t, err)
+}
+
+// TestPushSessionsBranch_ForcePushesPrunedBranch verifies that a pruned
+// metadata branch replaces the remote one on the next push, keeping
+// checkpoints pushed by others after the prune, and that the pruned
+// checkpoint doesn't come back.
+func TestPushSessionsBranch_ForcePushesPrunedBranch(t *testing.T) {
+	remoteDir := t.TempDir()
+	runGit(t, remoteDir, "init", "--bare", "-q")
+
+	otherDir := setupGitRepo(t)
+	runGit(t, otherDir, "remote", "add", "origin", remoteDir)
+	dir := setupGitRepo(t)
+	runGit(t, dir, "remote", "add", "origin", remoteDir)
+	t.Chdir(dir)
+
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	pruned := id.MustCheckpointID("d0d0d0d0d0d1")
+	kept := id.MustCheckpointID("d0d0d0d0d0d2")
+	writePruneTestCheckpoint(t, repo, pruned)
+	writePruneTestCheckpoint(t, repo, kept)
+	require.NoError(t, pushSessionsBranchCommon("origin", paths.MetadataBranchName))
+
+	// Someone else pushes a checkpoint based on the unpruned history
+	runGit(t, otherDir, "fetch", "-q", "origin", paths.MetadataBranchName+":"+paths.MetadataBranchName)
+	otherRepo, err := git.PlainOpen(otherDir)
+	require.NoError(t, err)
+	concurrent := id.MustCheckpointID("d0d0d0d0d0d3")
+	writePruneTestCheckpoint(t, otherRepo, concurrent)
+	runGit(t, otherDir, "push", "-q", "--no-verify", "origin", paths.MetadataBranchName)
+
+	result, err := checkpoint.NewGitStore(repo).Prune(context.Background(), checkpoint.PruneOptions{
+		MaxAge:    time.Hour,
+		Protected: map[id.CheckpointID]bool{kept: true},
+		Now:       time.Now().Add(2 * time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, []id.CheckpointID{pruned}, result.Plan.Remove)
+	require.NoError(t, RecordPrunedMetadataBranch(paths.MetadataBranchName, result.OldTip, result.Plan))
+
+	require.NoError(t, pushSessionsBranchCommon("origin", paths.MetadataBranchName))
+
+	// The marker is gone once every remote has the pruned branch
+	markerPath, err := pendingForcePushPath()
+	require.NoError(t, err)
+	_, err = os.Stat(markerPath)
+	assert.True(t, os.IsNotExist(err), "pending force push marker should be removed")
+
+	remoteRepo, err := git.PlainOpen(remoteDir)
+	require.NoError(t, err)
+	remoteRef, err := remoteRepo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	require.NoError(t, err)
+	localRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	require.NoError(t, err)
+	assert.Equal(t, localRef.Hash(), remoteRef.Hash())
+
+	entries, err := flattenCommitTree(remoteRepo, remoteRef.Hash())
+	require.NoError(t, err)
+	assert.Contains(t, entries, kept.Path()+"/"+paths.MetadataFileName)
+	assert.Contains(t, entries, concurrent.Path()+"/"+paths.MetadataFileName)
+	assert.NotContains(t, entries, pruned.Path()+"/"+paths.MetadataFileName)
+	assert.False(t, isAncestorOf(remoteRepo, result.OldTip, remoteRef.Hash()),
+		"the pre-prune history should not be reachable from the remote branch")
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
 	"time"
 
@@ -23,6 +24,9 @@ import (
 // Configuration (stored in .entire/settings.json under strategy_options.push_sessions):
 //   - false: disable automatic pushing
 //   - true or not set: push automatically (default)
+//
+// The branch goes to the checkpoint remote and ref from settings if set (see
+// ResolveCheckpointRemote), instead of the remote code is pushed to.
 func pushSessionsBranchCommon(remote, branchName string) error {
 	// Check if pushing is disabled
 	if isPushSessionsDisabled() {
@@ -34,6 +38,11 @@ func pushSessionsBranchCommon(remote, branchName string) error {
 		return nil //nolint:nilerr // Hook must be silent on failure
 	}
 
+	target := ResolveCheckpointRemote(remote)
+
+	// Commit notes linking commits to checkpoints travel with the metadata branch
+	defer pushCommitNotes(repo, target.Remote)
+
 	// Check if branch exists locally
 	branchRef := plumbing.NewBranchReferenceName(branchName)
 	localRef, err := repo.Reference(branchRef, true)
@@ -42,21 +51,27 @@ func pushSessionsBranchCommon(remote, branchName string) error {
 		return nil //nolint:nilerr // Expected when no sessions exist yet
 	}
 
+	// A branch rewritten by `entire prune` replaces the remote history
+	// instead of being merged with it
+	if pending := loadPendingForcePush(); pending != nil && pending.Branch == branchName &&
+		!slices.Contains(pending.Pushed, target.Remote) {
+		return forcePushPrunedSessions(repo, target, branchName, pending)
+	}
+
 	// Check if there's actually something to push (local differs from remote)
-	if !hasUnpushedSessionsCommon(repo, remote, localRef.Hash(), branchName) {
+	if !hasUnpushedSessionsCommon(repo, target, localRef.Hash()) {
 		// Nothing to push - skip silently
 		return nil
 	}
 
-	return doPushSessionsBranch(remote, branchName)
+	return doPushSessionsBranch(repo, target, branchName)
 }
 
 // hasUnpushedSessionsCommon checks if the local branch differs from the remote.
 // Returns true if there's any difference that needs syncing (local ahead, remote ahead, or diverged).
-func hasUnpushedSessionsCommon(repo *git.Repository, remote string, localHash plumbing.Hash, branchName string) bool {
-	// Check for remote tracking ref: refs/remotes/<remote>/<branch>
-	remoteRefName := plumbing.NewRemoteReferenceName(remote, branchName)
-	remoteRef, err := repo.Reference(remoteRefName, true)
+func hasUnpushedSessionsCommon(repo *git.Repository, target CheckpointRemote, localHash plumbing.Hash) bool {
+	// Check for remote tracking ref, e.g. refs/remotes/<remote>/<branch>
+	remoteRef, err := repo.Reference(target.TrackingRef(), true)
 	if err != nil {
 		// Remote branch doesn't exist yet - we have content to push
 		return true
@@ -78,37 +93,50 @@ func isPushSessionsDisabled() bool {
 }
 
 // doPushSessionsBranch pushes the sessions branch to the remote.
-func doPushSessionsBranch(remote, branchName string) error {
-	fmt.Fprintf(os.Stderr, "[entire] Pushing session logs to %s...\n", remote)
+func doPushSessionsBranch(repo *git.Repository, target CheckpointRemote, branchName string) error {
+	fmt.Fprintf(os.Stderr, "[entire] Pushing session logs to %s...\n", target.Remote)
 
 	// Try pushing first
-	if err := tryPushSessionsCommon(remote, branchName); err == nil {
+	if err := tryPushSessionsCommon(target.Remote, target.PushRefspec(branchName)); err == nil {
+		recordPushedSessions(repo, target, branchName)
 		return nil
 	}
 
 	// Push failed - likely non-fast-forward. Try to fetch and merge.
 	fmt.Fprintf(os.Stderr, "[entire] Syncing with remote session logs...\n")
 
-	if err := fetchAndMergeSessionsCommon(remote, branchName); err != nil {
+	if err := fetchAndMergeSessionsCommon(target, branchName); err != nil {
 		fmt.Fprintf(os.Stderr, "[entire] Warning: couldn't sync sessions: %v\n", err)
 		return nil // Don't fail the main push
 	}
 
 	// Try pushing again after merge
-	if err := tryPushSessionsCommon(remote, branchName); err != nil {
+	if err := tryPushSessionsCommon(target.Remote, target.PushRefspec(branchName)); err != nil {
 		fmt.Fprintf(os.Stderr, "[entire] Warning: failed to push sessions after sync: %v\n", err)
+		return nil
 	}
+	recordPushedSessions(repo, target, branchName)
 
 	return nil
 }
 
-// tryPushSessionsCommon attempts to push the sessions branch.
-func tryPushSessionsCommon(remote, branchName string) error {
+// recordPushedSessions points the tracking ref at the pushed branch, since
+// git push only updates remote-tracking branches of named remotes.
+func recordPushedSessions(repo *git.Repository, target CheckpointRemote, branchName string) {
+	localRef, err := repo.Reference(plumbing.NewBranchReferenceName(branchName), true)
+	if err != nil {
+		return
+	}
+	_ = repo.Storer.SetReference(plumbing.NewHashReference(target.TrackingRef(), localRef.Hash())) //nolint:errcheck // Only saves a push next time
+}
+
+// tryPushSessionsCommon attempts to push the sessions branch, or any refspec, to remote.
+func tryPushSessionsCommon(remote, refspec string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
 	defer cancel()
 
 	// Use --no-verify to prevent recursive hook calls
-	cmd := exec.CommandContext(ctx, "git", "push", "--no-verify", remote, branchName)
+	cmd := exec.CommandContext(ctx, "git", "push", "--no-verify", remote, refspec)
 	cmd.Stdin = nil // Disconnect stdin to prevent hanging in hook context
 
 	output, err := cmd.CombinedOutput()
@@ -124,13 +152,14 @@ func tryPushSessionsCommon(remote, branchName string) error {
 }
 
 // fetchAndMergeSessionsCommon fetches remote sessions and merges into local using go-git.
-// Since session logs are append-only (unique cond-* directories), we just combine trees.
-func fetchAndMergeSessionsCommon(remote, branchName string) error {
+// Checkpoints only one side has are combined as they are; checkpoints both
+// sides wrote to are merged session by session (see checkpoint.MergeMetadataEntries).
+func fetchAndMergeSessionsCommon(target CheckpointRemote, branchName string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
 	defer cancel()
 
 	// Use git CLI for fetch (go-git's fetch can be tricky with auth)
-	fetchCmd := exec.CommandContext(ctx, "git", "fetch", remote, branchName)
+	fetchCmd := exec.CommandContext(ctx, "git", "fetch", target.Remote, target.FetchRefspec())
 	fetchCmd.Stdin = nil
 	if output, err := fetchCmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("fetch failed: %s", output)
@@ -155,12 +184,12 @@ func fetchAndMergeSessionsCommon(remote, branchName string) error {
 		return fmt.Errorf("failed to get local tree: %w", err)
 	}
 
-	// Get remote (FETCH_HEAD)
-	fetchHeadRef, err := repo.Reference(plumbing.ReferenceName("FETCH_HEAD"), true)
+	// Get remote tip from the tracking ref the fetch updated
+	remoteTipRef, err := repo.Reference(target.TrackingRef(), true)
 	if err != nil {
-		return fmt.Errorf("failed to get FETCH_HEAD: %w", err)
+		return fmt.Errorf("failed to get fetched ref: %w", err)
 	}
-	remoteCommit, err := repo.CommitObject(fetchHeadRef.Hash())
+	remoteCommit, err := repo.CommitObject(remoteTipRef.Hash())
 	if err != nil {
 		return fmt.Errorf("failed to get remote commit: %w", err)
 	}
@@ -169,15 +198,20 @@ func fetchAndMergeSessionsCommon(remote, branchName string) error {
 		return fmt.Errorf("failed to get remote tree: %w", err)
 	}
 
-	// Flatten both trees and combine entries
-	// Session logs have unique cond-* directories, so no conflicts expected
-	entries := make(map[string]object.TreeEntry)
-	if err := checkpoint.FlattenTree(repo, localTree, "", entries); err != nil {
+	// Flatten both trees and merge them checkpoint by checkpoint, so sessions
+	// added to the same checkpoint on both sides are all kept
+	localEntries := make(map[string]object.TreeEntry)
+	if err := checkpoint.FlattenTree(repo, localTree, "", localEntries); err != nil {
 		return fmt.Errorf("failed to flatten local tree: %w", err)
 	}
-	if err := checkpoint.FlattenTree(repo, remoteTree, "", entries); err != nil {
+	remoteEntries := make(map[string]object.TreeEntry)
+	if err := checkpoint.FlattenTree(repo, remoteTree, "", remoteEntries); err != nil {
 		return fmt.Errorf("failed to flatten remote tree: %w", err)
 	}
+	entries, err := checkpoint.MergeMetadataEntries(repo, localEntries, remoteEntries)
+	if err != nil {
+		return fmt.Errorf("failed to merge session logs: %w", err)
+	}
 
 	// Build merged tree
 	mergedTreeHash, err := checkpoint.BuildTreeFromEntries(repo, entries)
@@ -187,7 +221,7 @@ func fetchAndMergeSessionsCommon(remote, branchName string) error {
 
 	// Create merge commit with both parents
 	mergeCommitHash, err := createMergeCommitCommon(repo, mergedTreeHash,
-		[]plumbing.Hash{localRef.Hash(), fetchHeadRef.Hash()},
+		[]plumbing.Hash{localRef.Hash(), remoteTipRef.Hash()},
 		"Merge remote session logs")
 	if err != nil {
 		return fmt.Errorf("failed to create merge commit: %w", err)
@@ -220,6 +254,8 @@ func createMergeCommitCommon(repo *git.Repository, treeHash plumbing.Hash, paren
 		Message:      message,
 	}
 
+	checkpoint.SignCommit(repo, commit)
+
 	obj := repo.Storer.NewEncodedObject()
 	if err := commit.Encode(obj); err != nil {
 		return plumbing.ZeroHash, fmt.Errorf("failed to encode commit: %w", err)
@@ -0,0 +1,103 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeMergeTestSession(t *testing.T, repo *git.Repository, cpID id.CheckpointID, sessionID string, count int, files []string, summary *checkpoint.Summary) {
+	t.Helper()
+	err := checkpoint.NewGitStore(repo).WriteCommitted(context.Background(), checkpoint.WriteCommittedOptions{
+		CheckpointID:     cpID,
+		SessionID:        sessionID,
+		Strategy:         "manual-commit",
+		Transcript:       []byte(`{"type":"user","session":"` + sessionID + `"}` + "\n"),
+		Prompts:          []string{"prompt of " + sessionID},
+		FilesTouched:     files,
+		CheckpointsCount: count,
+		TokenUsage:       &agent.TokenUsage{InputTokens: 10, OutputTokens: 1},
+		Summary:          summary,
+		AuthorName:       "Test",
+		AuthorEmail:      "test@test.com",
+	})
+	require.NoError(t, err)
+}
+
+// TestPushSessionsBranch_MergesSessionsOfSameCheckpoint verifies that when two
+// clones add sessions to the same checkpoint, the push merges them instead of
+// one side's session directories and root metadata.json winning.
+func TestPushSessionsBranch_MergesSessionsOfSameCheckpoint(t *testing.T) {
+	remoteDir := t.TempDir()
+	runGit(t, remoteDir, "init", "--bare", "-q")
+	cpID := id.MustCheckpointID("c0c0c0c0c0c1")
+
+	// Both clones start from a checkpoint with one session
+	otherDir := setupGitRepo(t)
+	runGit(t, otherDir, "remote", "add", "origin", remoteDir)
+	otherRepo, err := git.PlainOpen(otherDir)
+	require.NoError(t, err)
+	writeMergeTestSession(t, otherRepo, cpID, "shared", 1, []string{"shared.go"}, nil)
+	runGit(t, otherDir, "push", "-q", "--no-verify", "origin", paths.MetadataBranchName)
+
+	dir := setupGitRepo(t)
+	runGit(t, dir, "remote", "add", "origin", remoteDir)
+	runGit(t, dir, "fetch", "-q", "origin", paths.MetadataBranchName+":"+paths.MetadataBranchName)
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+
+	// The other clone summarizes the shared session and adds a session
+	require.NoError(t, checkpoint.NewGitStore(otherRepo).UpdateSummary(context.Background(), cpID, &checkpoint.Summary{
+		Intent:   "Fix the bug",
+		Friction: []string{"flaky test"},
+	}, nil))
+	writeMergeTestSession(t, otherRepo, cpID, "remote-session", 2, []string{"remote.go"}, nil)
+	runGit(t, otherDir, "push", "-q", "--no-verify", "origin", paths.MetadataBranchName)
+
+	// This clone continues the shared session and adds another session
+	writeMergeTestSession(t, repo, cpID, "shared", 3, []string{"shared.go", "more.go"}, &checkpoint.Summary{
+		Friction: []string{"slow build"},
+	})
+	writeMergeTestSession(t, repo, cpID, "local-session", 1, []string{"local.go"}, nil)
+
+	t.Chdir(dir)
+	require.NoError(t, pushSessionsBranchCommon("origin", paths.MetadataBranchName))
+
+	runGit(t, otherDir, "fetch", "-q", "origin", "+"+paths.MetadataBranchName+":"+paths.MetadataBranchName)
+	store := checkpoint.NewGitStore(otherRepo)
+	summary, err := store.ReadCommitted(context.Background(), cpID)
+	require.NoError(t, err)
+	require.Len(t, summary.Sessions, 3)
+	assert.Equal(t, 6, summary.CheckpointsCount)
+	assert.Equal(t, []string{"local.go", "more.go", "remote.go", "shared.go"}, summary.FilesTouched)
+	require.NotNil(t, summary.TokenUsage)
+	assert.Equal(t, 30, summary.TokenUsage.InputTokens)
+
+	wantOrder := []string{"shared", "local-session", "remote-session"}
+	for i, sessionID := range wantOrder {
+		content, err := store.ReadSessionContent(context.Background(), cpID, i)
+		require.NoError(t, err)
+		assert.Equal(t, sessionID, content.Metadata.SessionID, "session %d", i)
+		assert.Contains(t, string(content.Transcript), sessionID)
+		assert.Equal(t, "prompt of "+sessionID, content.Prompts)
+	}
+
+	// The continued session is the local one, with both summaries merged
+	shared, err := store.ReadSessionContent(context.Background(), cpID, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 3, shared.Metadata.CheckpointsCount)
+	require.NotNil(t, shared.Metadata.Summary)
+	assert.Equal(t, "Fix the bug", shared.Metadata.Summary.Intent)
+	assert.Equal(t, []string{"slow build", "flaky test"}, shared.Metadata.Summary.Friction)
+
+	// The renumbered session's file paths point at its new directory
+	assert.Equal(t, "/"+cpID.Path()+"/2/"+paths.MetadataFileName, summary.Sessions[2].Metadata)
+}
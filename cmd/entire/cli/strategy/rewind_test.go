@@ -3,6 +3,7 @@ package strategy
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
@@ -276,3 +277,167 @@ func TestDualStrategy_PreviewRewind(t *testing.T) {
 		t.Errorf("Dual strategy preview should have no files to delete, got: %v", preview.FilesToDelete)
 	}
 }
+
+func TestMatchRewindPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		file    string
+		want    bool
+	}{
+		{"src/app.go", "src/app.go", true},
+		{"src/app.go", "src/app.go.bak", false},
+		{"src", "src/app.go", true},
+		{"src/", "src/pkg/util.go", true},
+		{"./src", "src/app.go", true},
+		{"sr", "src/app.go", false},
+		{"*.go", "main.go", true},
+		{"*.go", "src/app.go", false},
+		{"src/*.go", "src/app.go", true},
+		{"src/*.go", "src/pkg/util.go", false},
+		{"**/*.go", "src/pkg/util.go", true},
+		{"**/*.go", "main.go", true},
+		{"src/**/util.go", "src/util.go", true},
+		{"src/**/util.go", "src/a/b/util.go", true},
+		{"src/*", "src/pkg/util.go", true},
+		{"docs/**", "src/app.go", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchRewindPath(tt.pattern, tt.file); got != tt.want {
+			t.Errorf("matchRewindPath(%q, %q) = %v, want %v", tt.pattern, tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestManualCommitStrategy_RewindPaths(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+
+	files := map[string]string{
+		"app.js":         "app v1\n",
+		"src/util.js":    "util v1\n",
+		"src/lib/lib.js": "lib v1\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-q", "-m", "Checkpoint")
+
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("failed to get HEAD: %v", err)
+	}
+
+	// The agent changes every file and adds an untracked one
+	for name := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("changed\n"), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "src", "new.js"), []byte("new\n"), 0o644); err != nil {
+		t.Fatalf("failed to write new.js: %v", err)
+	}
+
+	s := &ManualCommitStrategy{}
+	point := RewindPoint{ID: head.Hash().String(), Message: "Checkpoint", IsLogsOnly: true, Date: time.Now()}
+
+	changes, err := s.RewindPointChanges(point)
+	if err != nil {
+		t.Fatalf("RewindPointChanges() error = %v", err)
+	}
+	wantChanges := []string{"app.js", "src/lib/lib.js", "src/new.js", "src/util.js"}
+	if !slices.Equal(changes, wantChanges) {
+		t.Errorf("RewindPointChanges() = %v, want %v", changes, wantChanges)
+	}
+
+	preview, err := s.PreviewRewindPaths(point, RewindPathSelection{Patterns: []string{"src"}})
+	if err != nil {
+		t.Fatalf("PreviewRewindPaths() error = %v", err)
+	}
+	if want := []string{"src/lib/lib.js", "src/util.js"}; !slices.Equal(preview.FilesToRestore, want) {
+		t.Errorf("FilesToRestore = %v, want %v", preview.FilesToRestore, want)
+	}
+	if want := []string{"src/new.js"}; !slices.Equal(preview.FilesToDelete, want) {
+		t.Errorf("FilesToDelete = %v, want %v", preview.FilesToDelete, want)
+	}
+
+	if err := s.RewindPaths(point, RewindPathSelection{Patterns: []string{"src/**/*.js"}}, false); err != nil {
+		t.Fatalf("RewindPaths() error = %v", err)
+	}
+
+	for name, want := range map[string]string{
+		"app.js":         "changed\n",
+		"src/util.js":    "util v1\n",
+		"src/lib/lib.js": "lib v1\n",
+	} {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "src", "new.js")); !os.IsNotExist(err) {
+		t.Errorf("src/new.js should have been deleted, stat error = %v", err)
+	}
+
+	if err := s.RewindPaths(point, RewindPathSelection{Patterns: []string{"docs"}}, false); err == nil {
+		t.Error("RewindPaths() with no matching files should fail")
+	}
+}
+
+func TestManualCommitStrategy_RewindPaths_ExactFiles(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+
+	// As a pattern, "[draft].js" would match "d.js" and not itself
+	files := []string{"[draft].js", "d.js"}
+	for _, name := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("v1\n"), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-q", "-m", "Checkpoint")
+
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("failed to get HEAD: %v", err)
+	}
+	for _, name := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("changed\n"), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	s := &ManualCommitStrategy{}
+	point := RewindPoint{ID: head.Hash().String(), Message: "Checkpoint", IsLogsOnly: true, Date: time.Now()}
+	if err := s.RewindPaths(point, RewindPathSelection{Files: []string{"[draft].js"}}, false); err != nil {
+		t.Fatalf("RewindPaths() error = %v", err)
+	}
+
+	for name, want := range map[string]string{"[draft].js": "v1\n", "d.js": "changed\n"} {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -6,12 +6,15 @@ import (
 	"encoding/json"
 	"errors"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
 	"github.com/entireio/cli/cmd/entire/cli/session"
+
+	"github.com/go-git/go-git/v5/plumbing"
 )
 
 // ErrNoMetadata is returned when a commit does not have an Entire metadata trailer.
@@ -122,6 +125,11 @@ type RewindPoint struct {
 	// SessionPrompts contains the first prompt for each session (parallel to SessionIDs).
 	// Used to display context when showing resume commands for multi-session checkpoints.
 	SessionPrompts []string
+
+	// IsPreRewind indicates this is the state saved just before the last rewind.
+	// Rewinding to it undoes that rewind: files, transcripts and shadow branch
+	// are put back exactly as they were.
+	IsPreRewind bool
 }
 
 // RewindPreview describes what will happen when rewinding to a checkpoint.
@@ -435,6 +443,16 @@ type PostCommitHandler interface {
 	PostCommit() error
 }
 
+// PostRewriteHandler is an optional interface for strategies that need to
+// handle the git post-rewrite hook (invoked after `git commit --amend` and `git rebase`).
+type PostRewriteHandler interface {
+	// PostRewrite is called by the git post-rewrite hook with the rewrite command
+	// ("amend" or "rebase") and the old → new commit mapping read from stdin.
+	// Used to keep session state and commit → checkpoint links pointing at the new SHAs.
+	// Should return nil on errors to not block subsequent operations (log warnings to stderr).
+	PostRewrite(rewriteType string, rewrites []RewrittenCommit) error
+}
+
 // CommitMsgHandler is an optional interface for strategies that need to
 // handle the git commit-msg hook.
 type CommitMsgHandler interface {
@@ -478,6 +496,100 @@ type LogsOnlyRestorer interface {
 	RestoreLogsOnly(point RewindPoint, force bool) error
 }
 
+// PathRewinder is an optional interface for strategies that can restore only
+// some files of a rewind point, selected by a RewindPathSelection.
+type PathRewinder interface {
+	// RewindPaths restores the files of the rewind point in the selection,
+	// and deletes untracked files in it that Rewind would delete. Other files
+	// are left untouched, as are the session transcript and the shadow branch
+	// unless resetSession is set.
+	RewindPaths(point RewindPoint, paths RewindPathSelection, resetSession bool) error
+
+	// PreviewRewindPaths is PreviewRewind scoped to the files in the selection.
+	PreviewRewindPaths(point RewindPoint, paths RewindPathSelection) (*RewindPreview, error)
+
+	// RewindPointChanges returns the files a full rewind to the point would
+	// change: files whose content differs from the checkpoint, and files
+	// that would be deleted. Used to offer files to pick from.
+	RewindPointChanges(point RewindPoint) ([]string, error)
+}
+
+// RewindPathSelection selects files of a rewind point. All paths are relative
+// to the repository root.
+type RewindPathSelection struct {
+	// Patterns match a file, a directory (everything under it), or a glob
+	// where * matches within a path segment and ** across segments.
+	Patterns []string
+
+	// Files are exact file paths, matched literally even if they contain
+	// glob characters. Used for files picked from RewindPointChanges.
+	Files []string
+}
+
+// IsEmpty reports whether the selection selects nothing.
+func (p RewindPathSelection) IsEmpty() bool {
+	return len(p.Patterns) == 0 && len(p.Files) == 0
+}
+
+// String lists the patterns and files of the selection.
+func (p RewindPathSelection) String() string {
+	return strings.Join(append(slices.Clone(p.Patterns), p.Files...), ", ")
+}
+
+// RewindUndoer is an optional interface for strategies that save the working
+// tree and live transcripts before every rewind, so the last one can be undone.
+type RewindUndoer interface {
+	// GetPreRewindPoint returns the state saved before the last rewind as a
+	// rewind point with IsPreRewind set, or nil if there is none. Rewinding
+	// to it undoes the rewind; undoing again redoes it.
+	GetPreRewindPoint() (*RewindPoint, error)
+
+	// MovePreRewindPoint keeps the state saved before the last rewind
+	// undoable after the rewind moved HEAD from fromHead, as a logs-only
+	// rewind that resets the branch does. Undoing it moves HEAD back.
+	MovePreRewindPoint(fromHead string) error
+}
+
+// SessionForker is an optional interface for strategies that can start a new
+// session from a checkpoint of another session.
+type SessionForker interface {
+	// ForkSession starts the session state of a forked session, whose
+	// transcript has already been written for its agent, and starts its
+	// shadow branch with a checkpoint of the fork point.
+	ForkSession(opts ForkSessionOptions) error
+}
+
+// ForkSessionOptions describes a session forked from a checkpoint.
+type ForkSessionOptions struct {
+	// SessionID is the new session's ID
+	SessionID string
+
+	// AgentType is the agent of the session
+	AgentType agent.AgentType
+
+	// WorktreePath is the root of the worktree the fork lives in
+	WorktreePath string
+
+	// BaseCommit is the commit the worktree is checked out at
+	BaseCommit string
+
+	// Tree is the code at the fork point
+	Tree plumbing.Hash
+
+	// Transcript is the forked transcript, and TranscriptPath where it was written
+	Transcript     []byte
+	TranscriptPath string
+
+	// TranscriptStart is the position of the end of the forked transcript;
+	// the fork's first checkpoint starts from there
+	TranscriptStart int
+
+	// ParentSessionID is the session forked from, and ParentCheckpoint the
+	// checkpoint ID or shadow commit SHA it was forked at
+	ParentSessionID  string
+	ParentCheckpoint string
+}
+
 // SessionResetter is an optional interface for strategies that support
 // resetting session state and shadow branches.
 // This is used by the "reset" command to clean up shadow branches
@@ -8,30 +8,29 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/entireio/cli/cmd/entire/cli/agent"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
 	"github.com/entireio/cli/cmd/entire/cli/transcript"
 )
 
-// GenerateFromTranscript generates a summary from raw transcript bytes.
+// GenerateFromEntries generates a summary from normalized transcript entries,
+// typically the portion of a session transcript belonging to one checkpoint.
 // This is the shared implementation used by both explain --generate and auto-summarize.
 //
 // Parameters:
 //   - ctx: context for cancellation
-//   - transcriptBytes: raw transcript bytes (JSONL format)
+//   - entries: transcript entries (see agent.ParseTranscriptEntries)
 //   - filesTouched: list of files modified during the session
 //   - generator: summary generator to use (if nil, uses default ClaudeGenerator)
 //
-// Returns nil, error if transcript is empty or cannot be parsed.
-func GenerateFromTranscript(ctx context.Context, transcriptBytes []byte, filesTouched []string, generator Generator) (*checkpoint.Summary, error) {
-	if len(transcriptBytes) == 0 {
+// Returns nil, error if the entries have nothing to summarize.
+func GenerateFromEntries(ctx context.Context, entries []agent.SessionEntry, filesTouched []string, generator Generator) (*checkpoint.Summary, error) {
+	if len(entries) == 0 {
 		return nil, errors.New("empty transcript")
 	}
 
 	// Build condensed transcript for summarization
-	condensed, err := BuildCondensedTranscriptFromBytes(transcriptBytes)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse transcript: %w", err)
-	}
+	condensed := BuildCondensedTranscript(entries)
 	if len(condensed) == 0 {
 		return nil, errors.New("transcript has no content to summarize")
 	}
@@ -106,31 +105,43 @@ var minimalDetailTools = map[string]bool{
 	"WebFetch": true, // Show URL only, not fetched content
 }
 
-// BuildCondensedTranscriptFromBytes parses transcript bytes and extracts a condensed view.
+// BuildCondensedTranscriptFromBytes parses transcript bytes of the given agent
+// type and extracts a condensed view.
 // This is a convenience function that combines parsing and condensing.
-func BuildCondensedTranscriptFromBytes(content []byte) ([]Entry, error) {
-	lines, err := transcript.ParseFromBytes(content)
+func BuildCondensedTranscriptFromBytes(agentType agent.AgentType, content []byte) ([]Entry, error) {
+	entries, err := agent.ParseTranscriptEntries(agentType, content)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse transcript: %w", err)
 	}
-	return BuildCondensedTranscript(lines), nil
+	return BuildCondensedTranscript(entries), nil
 }
 
 // BuildCondensedTranscript extracts a condensed view of the transcript.
 // It processes user prompts, assistant responses, and tool calls into
 // a simplified format suitable for LLM summarization.
-func BuildCondensedTranscript(lines []transcript.Line) []Entry {
+func BuildCondensedTranscript(sessionEntries []agent.SessionEntry) []Entry {
 	var entries []Entry
 
-	for _, line := range lines {
-		switch line.Type {
-		case transcript.TypeUser:
-			if entry := extractUserEntry(line); entry != nil {
+	for _, e := range sessionEntries {
+		switch e.Type {
+		case agent.EntryUser:
+			if entry := extractUserEntry(e); entry != nil {
 				entries = append(entries, *entry)
 			}
-		case transcript.TypeAssistant:
-			assistantEntries := extractAssistantEntries(line)
-			entries = append(entries, assistantEntries...)
+		case agent.EntryAssistant:
+			if e.Content != "" {
+				entries = append(entries, Entry{
+					Type:    EntryTypeAssistant,
+					Content: e.Content,
+				})
+			}
+		case agent.EntryTool:
+			entries = append(entries, Entry{
+				Type:       EntryTypeTool,
+				ToolName:   e.ToolName,
+				ToolDetail: toolCallDetail(e),
+			})
+		case agent.EntryToolResult, agent.EntrySystem:
 		}
 	}
 
@@ -141,11 +152,10 @@ func BuildCondensedTranscript(lines []transcript.Line) []Entry {
 // These are injected after a Skill tool call and contain the full skill instructions.
 const skillContentPrefix = "Base directory for this skill:"
 
-// extractUserEntry extracts a user entry from a transcript line.
-// Returns nil if the line doesn't contain a valid user prompt or is skill content.
-func extractUserEntry(line transcript.Line) *Entry {
-	content := transcript.ExtractUserContent(line.Message)
-	if content == "" {
+// extractUserEntry extracts a user entry from a session entry.
+// Returns nil if the entry has no content or is skill content.
+func extractUserEntry(e agent.SessionEntry) *Entry {
+	if e.Content == "" {
 		return nil
 	}
 
@@ -154,49 +164,48 @@ func extractUserEntry(line transcript.Line) *Entry {
 	// The prefix "Base directory for this skill:" is added by the superpowers
 	// plugin when loading skill content. This filtering reduces transcript noise
 	// since skill content is documentation, not user intent.
-	if strings.HasPrefix(content, skillContentPrefix) {
+	if strings.HasPrefix(e.Content, skillContentPrefix) {
 		return nil
 	}
 
 	return &Entry{
 		Type:    EntryTypeUser,
-		Content: content,
+		Content: e.Content,
 	}
 }
 
-// extractAssistantEntries extracts assistant and tool entries from a transcript line.
-func extractAssistantEntries(line transcript.Line) []Entry {
-	var msg transcript.AssistantMessage
-	if err := json.Unmarshal(line.Message, &msg); err != nil {
-		return nil
+// toolCallDetail returns the detail shown for a tool call entry. Tool inputs are
+// JSON objects, or JSON-encoded strings of them as some agents record arguments.
+// Inputs without a known field fall back to the files the call affected, then
+// to the first line of a plain-text input (e.g. a patch).
+func toolCallDetail(e agent.SessionEntry) string {
+	var data []byte
+	switch in := e.ToolInput.(type) {
+	case nil:
+	case string:
+		data = []byte(in)
+	case json.RawMessage:
+		data = in
+	default:
+		data, _ = json.Marshal(in) //nolint:errcheck // Best-effort: unencodable input has no detail
 	}
 
-	var entries []Entry
-
-	for _, block := range msg.Content {
-		switch block.Type {
-		case transcript.ContentTypeText:
-			if block.Text != "" {
-				entries = append(entries, Entry{
-					Type:    EntryTypeAssistant,
-					Content: block.Text,
-				})
-			}
-		case transcript.ContentTypeToolUse:
-			var input transcript.ToolInput
-			_ = json.Unmarshal(block.Input, &input) //nolint:errcheck // Best-effort parsing
-
-			detail := extractToolDetail(block.Name, input)
-
-			entries = append(entries, Entry{
-				Type:       EntryTypeTool,
-				ToolName:   block.Name,
-				ToolDetail: detail,
-			})
-		}
+	var input transcript.ToolInput
+	isJSON := json.Valid(data)
+	if isJSON {
+		_ = json.Unmarshal(data, &input) //nolint:errcheck // Best-effort parsing
 	}
-
-	return entries
+	if detail := extractToolDetail(e.ToolName, input); detail != "" {
+		return detail
+	}
+	if len(e.FilesAffected) > 0 {
+		return strings.Join(e.FilesAffected, ", ")
+	}
+	if !isJSON {
+		line, _, _ := strings.Cut(strings.TrimSpace(string(data)), "\n")
+		return line
+	}
+	return ""
 }
 
 // extractToolDetail extracts an appropriate detail string for a tool call.
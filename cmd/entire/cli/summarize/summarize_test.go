@@ -7,6 +7,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	_ "github.com/entireio/cli/cmd/entire/cli/agent/claudecode" // Register the Claude Code transcript parser
 	"github.com/entireio/cli/cmd/entire/cli/transcript"
 )
 
@@ -21,7 +23,7 @@ func TestBuildCondensedTranscript_UserPrompts(t *testing.T) {
 		},
 	}
 
-	entries := BuildCondensedTranscript(lines)
+	entries := condenseLines(t, lines)
 
 	if len(entries) != 1 {
 		t.Fatalf("expected 1 entry, got %d", len(entries))
@@ -49,7 +51,7 @@ func TestBuildCondensedTranscript_AssistantResponses(t *testing.T) {
 		},
 	}
 
-	entries := BuildCondensedTranscript(lines)
+	entries := condenseLines(t, lines)
 
 	if len(entries) != 1 {
 		t.Fatalf("expected 1 entry, got %d", len(entries))
@@ -83,7 +85,7 @@ func TestBuildCondensedTranscript_ToolCalls(t *testing.T) {
 		},
 	}
 
-	entries := BuildCondensedTranscript(lines)
+	entries := condenseLines(t, lines)
 
 	if len(entries) != 1 {
 		t.Fatalf("expected 1 entry, got %d", len(entries))
@@ -121,7 +123,7 @@ func TestBuildCondensedTranscript_ToolCallWithCommand(t *testing.T) {
 		},
 	}
 
-	entries := BuildCondensedTranscript(lines)
+	entries := condenseLines(t, lines)
 
 	if len(entries) != 1 {
 		t.Fatalf("expected 1 entry, got %d", len(entries))
@@ -151,7 +153,7 @@ func TestBuildCondensedTranscript_SkillToolMinimalDetail(t *testing.T) {
 		},
 	}
 
-	entries := BuildCondensedTranscript(lines)
+	entries := condenseLines(t, lines)
 
 	if len(entries) != 1 {
 		t.Fatalf("expected 1 entry, got %d", len(entries))
@@ -187,7 +189,7 @@ func TestBuildCondensedTranscript_WebFetchMinimalDetail(t *testing.T) {
 		},
 	}
 
-	entries := BuildCondensedTranscript(lines)
+	entries := condenseLines(t, lines)
 
 	if len(entries) != 1 {
 		t.Fatalf("expected 1 entry, got %d", len(entries))
@@ -241,7 +243,7 @@ This is verbose skill content that should not appear in summaries...`
 		},
 	}
 
-	entries := BuildCondensedTranscript(lines)
+	entries := condenseLines(t, lines)
 
 	// Should have: user prompt, tool call, user prompt (NOT the skill content)
 	if len(entries) != 3 {
@@ -276,7 +278,7 @@ func TestBuildCondensedTranscript_StripIDEContextTags(t *testing.T) {
 		},
 	}
 
-	entries := BuildCondensedTranscript(lines)
+	entries := condenseLines(t, lines)
 
 	if len(entries) != 1 {
 		t.Fatalf("expected 1 entry, got %d", len(entries))
@@ -299,7 +301,7 @@ func TestBuildCondensedTranscript_StripSystemTags(t *testing.T) {
 		},
 	}
 
-	entries := BuildCondensedTranscript(lines)
+	entries := condenseLines(t, lines)
 
 	if len(entries) != 1 {
 		t.Fatalf("expected 1 entry, got %d", len(entries))
@@ -337,7 +339,7 @@ func TestBuildCondensedTranscript_MixedContent(t *testing.T) {
 		},
 	}
 
-	entries := BuildCondensedTranscript(lines)
+	entries := condenseLines(t, lines)
 
 	if len(entries) != 3 {
 		t.Fatalf("expected 3 entries, got %d", len(entries))
@@ -359,7 +361,7 @@ func TestBuildCondensedTranscript_MixedContent(t *testing.T) {
 func TestBuildCondensedTranscript_EmptyTranscript(t *testing.T) {
 	lines := []transcript.Line{}
 
-	entries := BuildCondensedTranscript(lines)
+	entries := condenseLines(t, lines)
 
 	if len(entries) != 0 {
 		t.Errorf("expected 0 entries for empty transcript, got %d", len(entries))
@@ -387,7 +389,7 @@ func TestBuildCondensedTranscript_UserArrayContent(t *testing.T) {
 		},
 	}
 
-	entries := BuildCondensedTranscript(lines)
+	entries := condenseLines(t, lines)
 
 	if len(entries) != 1 {
 		t.Fatalf("expected 1 entry, got %d", len(entries))
@@ -419,7 +421,7 @@ func TestBuildCondensedTranscript_SkipsEmptyContent(t *testing.T) {
 		},
 	}
 
-	entries := BuildCondensedTranscript(lines)
+	entries := condenseLines(t, lines)
 
 	if len(entries) != 0 {
 		t.Errorf("expected 0 entries for empty content, got %d", len(entries))
@@ -494,7 +496,7 @@ func TestFormatCondensedTranscript_EmptyInput(t *testing.T) {
 	}
 }
 
-func TestGenerateFromTranscript(t *testing.T) {
+func TestGenerateFromEntries(t *testing.T) {
 	// Test with mock generator
 	mockGenerator := &ClaudeGenerator{
 		CommandRunner: func(ctx context.Context, _ string, _ ...string) *exec.Cmd {
@@ -503,10 +505,12 @@ func TestGenerateFromTranscript(t *testing.T) {
 		},
 	}
 
-	transcript := []byte(`{"type":"user","message":{"content":"Hello"}}
-{"type":"assistant","message":{"content":[{"type":"text","text":"Hi there"}]}}`)
+	entries := []agent.SessionEntry{
+		{Type: agent.EntryUser, Content: "Hello"},
+		{Type: agent.EntryAssistant, Content: "Hi there", Position: 1},
+	}
 
-	summary, err := GenerateFromTranscript(context.Background(), transcript, []string{"file.go"}, mockGenerator)
+	summary, err := GenerateFromEntries(context.Background(), entries, []string{"file.go"}, mockGenerator)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -518,10 +522,10 @@ func TestGenerateFromTranscript(t *testing.T) {
 	}
 }
 
-func TestGenerateFromTranscript_EmptyTranscript(t *testing.T) {
+func TestGenerateFromEntries_EmptyTranscript(t *testing.T) {
 	mockGenerator := &ClaudeGenerator{}
 
-	summary, err := GenerateFromTranscript(context.Background(), []byte{}, []string{}, mockGenerator)
+	summary, err := GenerateFromEntries(context.Background(), nil, []string{}, mockGenerator)
 	if err == nil {
 		t.Error("expected error for empty transcript")
 	}
@@ -530,18 +534,58 @@ func TestGenerateFromTranscript_EmptyTranscript(t *testing.T) {
 	}
 }
 
-func TestGenerateFromTranscript_NilGenerator(t *testing.T) {
-	transcript := []byte(`{"type":"user","message":{"content":"Hello"}}`)
+func TestGenerateFromEntries_NilGenerator(t *testing.T) {
+	entries := []agent.SessionEntry{{Type: agent.EntryUser, Content: "Hello"}}
 
 	// With nil generator, should use default ClaudeGenerator
 	// This will fail because claude CLI isn't available in test, but tests the nil handling
-	_, err := GenerateFromTranscript(context.Background(), transcript, []string{}, nil)
+	_, err := GenerateFromEntries(context.Background(), entries, []string{}, nil)
 	// Error is expected (claude CLI not available), but function should not panic
 	if err == nil {
 		t.Log("Unexpectedly succeeded - claude CLI must be available")
 	}
 }
 
+func TestBuildCondensedTranscript_OtherAgentToolCalls(t *testing.T) {
+	entries := BuildCondensedTranscript([]agent.SessionEntry{
+		{Type: agent.EntryUser, Content: "Fix the build"},
+		{Type: agent.EntryTool, ToolName: "shell", ToolInput: `{"command":"go build ./..."}`},
+		{Type: agent.EntryToolResult, ToolOutput: "ok"},
+		{Type: agent.EntryTool, ToolName: "apply_patch", ToolInput: "*** Begin Patch\n*** Update File: main.go", FilesAffected: []string{"main.go"}},
+		{Type: agent.EntryAssistant, Content: "Fixed."},
+	})
+
+	want := []Entry{
+		{Type: EntryTypeUser, Content: "Fix the build"},
+		{Type: EntryTypeTool, ToolName: "shell", ToolDetail: "go build ./..."},
+		{Type: EntryTypeTool, ToolName: "apply_patch", ToolDetail: "main.go"},
+		{Type: EntryTypeAssistant, Content: "Fixed."},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d entries, got %d: %+v", len(want), len(entries), entries)
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, entries[i], want[i])
+		}
+	}
+}
+
+// condenseLines is a test helper that condenses Claude Code transcript lines.
+func condenseLines(t *testing.T, lines []transcript.Line) []Entry {
+	t.Helper()
+	var buf strings.Builder
+	for _, line := range lines {
+		buf.Write(mustMarshal(t, line))
+		buf.WriteString("\n")
+	}
+	entries, err := BuildCondensedTranscriptFromBytes(agent.AgentTypeClaudeCode, []byte(buf.String()))
+	if err != nil {
+		t.Fatalf("failed to condense transcript: %v", err)
+	}
+	return entries
+}
+
 // mustMarshal is a test helper that marshals v to JSON, failing the test on error.
 func mustMarshal(t *testing.T, v interface{}) json.RawMessage {
 	t.Helper()
@@ -48,6 +48,11 @@ const (
 	// AgentTrailerKey identifies the agent that created a checkpoint.
 	// Format: human-readable agent name e.g. "Claude Code", "Cursor"
 	AgentTrailerKey = "Entire-Agent"
+
+	// PreRewindTrailerKey marks a shadow commit snapshotting the working tree and
+	// live transcripts just before a rewind, so the rewind can be undone.
+	// Format: ID of the rewind point that was restored
+	PreRewindTrailerKey = "Entire-Pre-Rewind"
 )
 
 // Pre-compiled regexes for trailer parsing.
@@ -60,6 +65,8 @@ var (
 	condensationTrailerRegex = regexp.MustCompile(CondensationTrailerKey + `:\s*(.+)`)
 	sessionTrailerRegex      = regexp.MustCompile(SessionTrailerKey + `:\s*(.+)`)
 	checkpointTrailerRegex   = regexp.MustCompile(CheckpointTrailerKey + `:\s*(` + checkpointID.Pattern + `)(?:\s|$)`)
+	agentTrailerRegex        = regexp.MustCompile(AgentTrailerKey + `:\s*(.+)`)
+	preRewindTrailerRegex    = regexp.MustCompile(PreRewindTrailerKey + `:\s*(.+)`)
 )
 
 // ParseStrategy extracts strategy from commit message.
@@ -138,6 +145,26 @@ func ParseCheckpoint(commitMessage string) (checkpointID.CheckpointID, bool) {
 	return checkpointID.EmptyCheckpointID, false
 }
 
+// ParseAgent extracts the agent name from a commit message.
+// Returns the agent name and true if found, empty string and false otherwise.
+func ParseAgent(commitMessage string) (string, bool) {
+	matches := agentTrailerRegex.FindStringSubmatch(commitMessage)
+	if len(matches) > 1 {
+		return strings.TrimSpace(matches[1]), true
+	}
+	return "", false
+}
+
+// ParsePreRewind extracts the ID of the rewind point a pre-rewind snapshot was
+// taken for. Returns the ID and true if the commit is a pre-rewind snapshot.
+func ParsePreRewind(commitMessage string) (string, bool) {
+	matches := preRewindTrailerRegex.FindStringSubmatch(commitMessage)
+	if len(matches) > 1 {
+		return strings.TrimSpace(matches[1]), true
+	}
+	return "", false
+}
+
 // ParseAllSessions extracts all session IDs from a commit message.
 // Returns a slice of session IDs (may be empty if none found).
 // Duplicate session IDs are deduplicated while preserving order.
@@ -221,6 +248,29 @@ func FormatShadowTaskCommit(message, taskMetadataDir, sessionID string) string {
 	return sb.String()
 }
 
+// FormatPreRewindCommit creates a commit message for a pre-rewind snapshot.
+// Includes Entire-Metadata (if there are sessions), one Entire-Session per
+// session, Entire-Agent (if known), Entire-Strategy, Base-Commit (the HEAD the
+// snapshot was taken on) and Entire-Pre-Rewind trailers.
+func FormatPreRewindCommit(message, metadataDir string, sessionIDs []string, agentName, baseCommit, target string) string {
+	var sb strings.Builder
+	sb.WriteString(message)
+	sb.WriteString("\n\n")
+	if metadataDir != "" {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", MetadataTrailerKey, metadataDir))
+	}
+	for _, sessionID := range sessionIDs {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", SessionTrailerKey, sessionID))
+	}
+	if agentName != "" {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", AgentTrailerKey, agentName))
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", StrategyTrailerKey, "manual-commit"))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", BaseCommitTrailerKey, baseCommit))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", PreRewindTrailerKey, target))
+	return sb.String()
+}
+
 // FormatCheckpoint creates a commit message with a checkpoint trailer.
 // This links user commits to their checkpoint metadata on entire/checkpoints/v1 branch.
 func FormatCheckpoint(message string, cpID checkpointID.CheckpointID) string {
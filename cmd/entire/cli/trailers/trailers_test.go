@@ -318,3 +318,35 @@ func TestParseCheckpoint(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatPreRewindCommit(t *testing.T) {
+	target := "0123456789abcdef0123456789abcdef01234567"
+	base := "89abcdef0123456789abcdef0123456789abcdef"
+	msg := FormatPreRewindCommit("Before rewind to 0123456", ".entire/metadata/session-2", []string{"session-1", "session-2"}, "Claude Code", base, target)
+
+	if got, found := ParsePreRewind(msg); !found || got != target {
+		t.Errorf("ParsePreRewind() = %q, %v, want %q, true", got, found, target)
+	}
+	if got := ParseAllSessions(msg); len(got) != 2 || got[0] != "session-1" || got[1] != "session-2" {
+		t.Errorf("ParseAllSessions() = %v, want [session-1 session-2]", got)
+	}
+	if got, found := ParseMetadata(msg); !found || got != ".entire/metadata/session-2" {
+		t.Errorf("ParseMetadata() = %q, %v", got, found)
+	}
+	if got, found := ParseAgent(msg); !found || got != "Claude Code" {
+		t.Errorf("ParseAgent() = %q, %v, want Claude Code", got, found)
+	}
+	if got, found := ParseBaseCommit(msg); !found || got != base {
+		t.Errorf("ParseBaseCommit() = %q, %v, want %q", got, found, base)
+	}
+
+	if _, found := ParsePreRewind(FormatShadowCommit("Checkpoint", ".entire/metadata/session-1", "session-1")); found {
+		t.Error("ParsePreRewind() found a trailer in a regular shadow commit")
+	}
+	if _, found := ParseAgent(FormatPreRewindCommit("Before rewind", ".entire/metadata/s", []string{"s"}, "", base, target)); found {
+		t.Error("ParseAgent() found a trailer when no agent was given")
+	}
+	if _, found := ParseMetadata(FormatPreRewindCommit("Before rewind", "", nil, "", base, target)); found {
+		t.Error("ParseMetadata() found a trailer in a snapshot without sessions")
+	}
+}
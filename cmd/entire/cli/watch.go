@@ -0,0 +1,665 @@
+// watch.go implements `entire watch`, the runtime for agents without lifecycle hooks.
+// It polls the storage of every registered agent.FileWatcher, debounces changes, and
+// drives sessions through the same strategy calls the agent hook handlers use.
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultWatchInterval = time.Second
+	defaultWatchDebounce = 2 * time.Second
+
+	// watchPIDFileName holds the PID of the running watcher, relative to paths.EntireTmpDir.
+	watchPIDFileName = "watch.pid"
+	// watchLogFileName receives a daemonized watcher's output, relative to logging.LogsDir.
+	watchLogFileName = "watch.log"
+)
+
+func newWatchCmd() *cobra.Command {
+	var interval, debounce time.Duration
+	var daemonFlag, stopFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Create checkpoints for agents without hooks",
+		Long: `Watch agents that have no lifecycle hooks (such as Cursor) and create
+checkpoints from their sessions.
+
+The watcher polls each agent's session storage. Once writes settle for the
+debounce period, it reads the agent's session: a new prompt starts a turn,
+and a response ends the turn and saves a checkpoint, just like the hooks of
+Claude Code and Gemini CLI do.
+
+Only one watcher runs per repository. Use --daemon to run it in the
+background (output goes to .entire/logs/watch.log) and --stop to stop it.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if _, err := paths.RepoRoot(); err != nil {
+				cmd.SilenceUsage = true
+				fmt.Fprintln(cmd.ErrOrStderr(), "Not a git repository. Please run 'entire watch' from within a git repository.")
+				return NewSilentError(errors.New("not a git repository"))
+			}
+			if stopFlag {
+				return runWatchStop(cmd.OutOrStdout())
+			}
+			if daemonFlag {
+				return runWatchDaemon(cmd.OutOrStdout(), interval, debounce)
+			}
+			return runWatch(cmd.Context(), cmd.ErrOrStderr(), interval, debounce)
+		},
+	}
+
+	cmd.Flags().DurationVar(&interval, "interval", defaultWatchInterval, "How often to poll agent storage")
+	cmd.Flags().DurationVar(&debounce, "debounce", defaultWatchDebounce, "How long writes must settle before a change is processed")
+	cmd.Flags().BoolVar(&daemonFlag, "daemon", false, "Run the watcher in the background")
+	cmd.Flags().BoolVar(&stopFlag, "stop", false, "Stop the background watcher")
+	cmd.MarkFlagsMutuallyExclusive("daemon", "stop")
+
+	return cmd
+}
+
+// runWatch runs the watcher in the foreground until interrupted.
+func runWatch(ctx context.Context, w io.Writer, interval, debounce time.Duration) error {
+	enabled, err := IsEnabled()
+	if err == nil && !enabled {
+		fmt.Fprintln(w, "Entire is disabled. Run 'entire enable' first.")
+		return NewSilentError(errors.New("entire is disabled"))
+	}
+
+	// Initialize logging so structured logs go to .entire/logs/ instead of stderr.
+	// Error is non-fatal: if logging init fails, logs go to stderr (acceptable fallback).
+	logging.SetLogLevelGetter(GetLogLevel)
+	if err := logging.Init(""); err == nil {
+		defer logging.Close()
+	}
+
+	agents := fileWatcherAgents()
+	if len(agents) == 0 {
+		return errors.New("no registered agent supports file watching")
+	}
+
+	release, err := acquireWatchPIDFile()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	names := make([]string, 0, len(agents))
+	for _, ag := range agents {
+		names = append(names, string(ag.Name()))
+	}
+	fmt.Fprintf(w, "Watching %s (press Ctrl+C to stop)\n", strings.Join(names, ", "))
+
+	watcher := newAgentWatcher(agents, debounce, strategyTurnHandler{})
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(w, "Watcher stopped")
+			return nil
+		case now := <-ticker.C:
+			watcher.poll(now)
+			watcher.flush(now)
+		}
+	}
+}
+
+// runWatchDaemon starts the watcher as a detached background process.
+func runWatchDaemon(w io.Writer, interval, debounce time.Duration) error {
+	if pid, running := runningWatcherPID(); running {
+		fmt.Fprintf(w, "Watcher already running (pid %d)\n", pid)
+		return nil
+	}
+
+	repoRoot, err := paths.RepoRoot()
+	if err != nil {
+		return fmt.Errorf("failed to get repo root: %w", err)
+	}
+	logsDir := filepath.Join(repoRoot, logging.LogsDir)
+	if err := os.MkdirAll(logsDir, 0o750); err != nil {
+		return fmt.Errorf("failed to create logs directory: %w", err)
+	}
+	logPath := filepath.Join(logsDir, watchLogFileName)
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600) //nolint:gosec // Path built from repo root
+	if err != nil {
+		return fmt.Errorf("failed to open watch log: %w", err)
+	}
+	defer logFile.Close()
+
+	args := []string{"watch", "--interval", interval.String(), "--debounce", debounce.String()}
+	pid, err := spawnWatchDaemon(repoRoot, args, logFile)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Watcher started in the background (pid %d)\n", pid)
+	fmt.Fprintf(w, "Output: %s\n", filepath.Join(logging.LogsDir, watchLogFileName))
+	return nil
+}
+
+// runWatchStop stops the background watcher of this repository, if any.
+func runWatchStop(w io.Writer) error {
+	pid, running := runningWatcherPID()
+	if !running {
+		fmt.Fprintln(w, "No watcher is running")
+		return nil
+	}
+	if err := terminateProcess(pid); err != nil {
+		return fmt.Errorf("failed to stop watcher (pid %d): %w", pid, err)
+	}
+	fmt.Fprintf(w, "Stopped watcher (pid %d)\n", pid)
+	return nil
+}
+
+// fileWatcherAgents returns the registered agents that support file watching.
+// Agents whose hooks are installed are left to their hooks, so turns aren't
+// checkpointed twice.
+func fileWatcherAgents() []agent.FileWatcher {
+	var watchers []agent.FileWatcher
+	for _, name := range agent.List() {
+		ag, err := agent.Get(name)
+		if err != nil {
+			continue
+		}
+		if hs, ok := ag.(agent.HookSupport); ok && hs.AreHooksInstalled() {
+			continue
+		}
+		if fw, ok := ag.(agent.FileWatcher); ok {
+			watchers = append(watchers, fw)
+		}
+	}
+	return watchers
+}
+
+func watchPIDFilePath() (string, error) {
+	tmpDir, err := paths.AbsPath(paths.EntireTmpDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to get tmp directory: %w", err)
+	}
+	return filepath.Join(tmpDir, watchPIDFileName), nil
+}
+
+// runningWatcherPID returns the PID recorded in the PID file and whether that process is alive.
+func runningWatcherPID() (int, bool) {
+	pidFile, err := watchPIDFilePath()
+	if err != nil {
+		return 0, false
+	}
+	data, err := os.ReadFile(pidFile) //nolint:gosec // Path built from repo root
+	if err != nil {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, false
+	}
+	return pid, processRunning(pid)
+}
+
+// acquireWatchPIDFile records this process as the repository's watcher.
+// Fails if another watcher is running. The returned func removes the PID file.
+func acquireWatchPIDFile() (func(), error) {
+	if pid, running := runningWatcherPID(); running && pid != os.Getpid() {
+		return nil, fmt.Errorf("a watcher is already running for this repository (pid %d); stop it with 'entire watch --stop'", pid)
+	}
+
+	pidFile, err := watchPIDFilePath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(pidFile), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create tmp directory: %w", err)
+	}
+	if err := os.WriteFile(pidFile, []byte(strconv.Itoa(os.Getpid())+"\n"), 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write pid file: %w", err)
+	}
+	return func() { _ = os.Remove(pidFile) }, nil
+}
+
+// watchTurnHandler performs the strategy work for turns detected by the watcher.
+type watchTurnHandler interface {
+	// TurnStart begins a turn. startPosition is the transcript position where the turn begins.
+	TurnStart(ag agent.Agent, change *agent.SessionChange, startPosition int) error
+	// TurnEnd ends the current turn and saves a checkpoint.
+	TurnEnd(ag agent.Agent, change *agent.SessionChange) error
+}
+
+// fileStamp identifies a version of a watched file.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// pendingChanges collects changed paths for an agent until they settle.
+type pendingChanges struct {
+	paths      map[string]bool
+	lastChange time.Time
+}
+
+// watchedSession tracks the turn state of a session seen by the watcher.
+type watchedSession struct {
+	inTurn bool
+	// position is the transcript position up to which the session has been processed.
+	position int
+}
+
+// agentWatcher detects session activity of FileWatcher agents by polling and turns
+// it into turn starts and ends. It is not safe for concurrent use.
+type agentWatcher struct {
+	agents   []agent.FileWatcher
+	debounce time.Duration
+	handler  watchTurnHandler
+	started  time.Time
+
+	stamps   map[string]fileStamp
+	pending  map[agent.AgentName]*pendingChanges
+	sessions map[string]*watchedSession
+}
+
+func newAgentWatcher(agents []agent.FileWatcher, debounce time.Duration, handler watchTurnHandler) *agentWatcher {
+	w := &agentWatcher{
+		agents:   agents,
+		debounce: debounce,
+		handler:  handler,
+		started:  time.Now(),
+		stamps:   make(map[string]fileStamp),
+		pending:  make(map[agent.AgentName]*pendingChanges),
+		sessions: make(map[string]*watchedSession),
+	}
+	// Record current file versions so only changes made from now on are processed
+	for _, ag := range agents {
+		for path, stamp := range scanWatchPaths(ag) {
+			w.stamps[path] = stamp
+		}
+	}
+	return w
+}
+
+// scanWatchPaths stats an agent's watch paths. Directories are scanned one level deep.
+func scanWatchPaths(ag agent.FileWatcher) map[string]fileStamp {
+	stamps := make(map[string]fileStamp)
+	watchPaths, err := ag.GetWatchPaths()
+	if err != nil {
+		return stamps
+	}
+	for _, watchPath := range watchPaths {
+		info, err := os.Stat(watchPath)
+		if err != nil {
+			continue // Not created yet
+		}
+		if !info.IsDir() {
+			stamps[watchPath] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+			continue
+		}
+		entries, err := os.ReadDir(watchPath)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			entryInfo, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			stamps[filepath.Join(watchPath, entry.Name())] = fileStamp{modTime: entryInfo.ModTime(), size: entryInfo.Size()}
+		}
+	}
+	return stamps
+}
+
+// poll records files that changed since the previous poll.
+func (w *agentWatcher) poll(now time.Time) {
+	for _, ag := range w.agents {
+		for path, stamp := range scanWatchPaths(ag) {
+			if prev, ok := w.stamps[path]; ok && prev == stamp {
+				continue
+			}
+			w.stamps[path] = stamp
+			p := w.pending[ag.Name()]
+			if p == nil {
+				p = &pendingChanges{paths: make(map[string]bool)}
+				w.pending[ag.Name()] = p
+			}
+			p.paths[path] = true
+			p.lastChange = now
+		}
+	}
+}
+
+// flush processes the changes of agents whose files have been quiet for the debounce period.
+func (w *agentWatcher) flush(now time.Time) {
+	for _, ag := range w.agents {
+		p := w.pending[ag.Name()]
+		if p == nil || now.Sub(p.lastChange) < w.debounce {
+			continue
+		}
+		delete(w.pending, ag.Name())
+
+		changedPaths := make([]string, 0, len(p.paths))
+		for path := range p.paths {
+			changedPaths = append(changedPaths, path)
+		}
+		sort.Strings(changedPaths)
+
+		// Several files may map to the same session; handle each session once
+		var changes []*agent.SessionChange
+		seen := make(map[string]bool)
+		for _, path := range changedPaths {
+			change, err := ag.OnFileChange(path)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %s: failed to process change to %s: %v\n", ag.Name(), path, err)
+				continue
+			}
+			if change == nil || change.SessionID == "" || seen[change.SessionID] {
+				continue
+			}
+			seen[change.SessionID] = true
+			changes = append(changes, change)
+		}
+		for _, change := range changes {
+			w.handleChange(ag, change)
+		}
+	}
+}
+
+// handleChange maps a session change to turn starts and ends.
+//
+// A prompt event starts a turn. A stop event ends the current turn; if the
+// prompt was missed (prompt and response landed within one debounce period),
+// the turn is started first, as long as the transcript grew since it was last
+// processed. Sessions last updated before the watcher started are only
+// recorded, so existing history isn't checkpointed.
+func (w *agentWatcher) handleChange(ag agent.FileWatcher, change *agent.SessionChange) {
+	position := -1 // Unknown unless the agent can report it
+	if analyzer, ok := ag.(agent.TranscriptAnalyzer); ok {
+		if pos, err := analyzer.GetTranscriptPosition(change.SessionRef); err == nil {
+			position = pos
+		}
+	}
+
+	key := string(ag.Name()) + "/" + change.SessionID
+	s := w.sessions[key]
+	if s == nil {
+		s = &watchedSession{position: max(position, 0)}
+		if change.Timestamp.After(w.started) {
+			// New activity: the whole transcript belongs to this run
+			s.position = 0
+		}
+		w.sessions[key] = s
+	}
+
+	logCtx := logging.WithAgent(logging.WithComponent(context.Background(), "watch"), ag.Name())
+	logging.Debug(logCtx, "session change",
+		slog.String("session_id", change.SessionID),
+		slog.String("event", string(change.EventType)),
+		slog.Int("position", position),
+		slog.Int("processed_position", s.position),
+		slog.Bool("in_turn", s.inTurn),
+	)
+
+	switch change.EventType {
+	case agent.HookUserPromptSubmit:
+		if !s.inTurn {
+			w.startTurn(ag, change, s)
+		}
+
+	case agent.HookStop:
+		if !s.inTurn {
+			if position < 0 || position <= s.position {
+				return // Nothing new since the last turn
+			}
+			if !w.startTurn(ag, change, s) {
+				return
+			}
+		}
+		if err := w.handler.TurnEnd(ag, change); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s: failed to end turn for session %s: %v\n", ag.Name(), change.SessionID, err)
+		}
+		s.inTurn = false
+		if position >= 0 {
+			s.position = position
+		}
+
+	case agent.HookSessionStart, agent.HookSessionEnd, agent.HookPreToolUse, agent.HookPostToolUse:
+		// Nothing to do until the session has a prompt
+	}
+}
+
+func (w *agentWatcher) startTurn(ag agent.FileWatcher, change *agent.SessionChange, s *watchedSession) bool {
+	if err := w.handler.TurnStart(ag, change, s.position); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %s: failed to start turn for session %s: %v\n", ag.Name(), change.SessionID, err)
+		return false
+	}
+	s.inTurn = true
+	return true
+}
+
+// strategyTurnHandler drives the configured strategy, mirroring the agent hook handlers:
+// TurnStart is the equivalent of a prompt-submit hook and TurnEnd of a stop hook.
+//
+// Watched agents report transcript positions as the Position of AgentSession.Entries.
+type strategyTurnHandler struct{}
+
+// TurnStart captures pre-prompt state and initializes the session.
+func (strategyTurnHandler) TurnStart(ag agent.Agent, change *agent.SessionChange, startPosition int) error {
+	session, err := ag.ReadSession(&agent.HookInput{SessionID: change.SessionID, SessionRef: change.SessionRef})
+	if err != nil {
+		return fmt.Errorf("failed to read session: %w", err)
+	}
+	transcriptPath, err := writeWatchedTranscript(change.SessionID, session)
+	if err != nil {
+		return err
+	}
+
+	if err := CaptureWatchedPrePromptState(change.SessionID, startPosition, entryIdentifierBefore(session, startPosition)); err != nil {
+		return fmt.Errorf("failed to capture pre-prompt state: %w", err)
+	}
+
+	initializeWatchedSession(ag, change.SessionID, transcriptPath, session)
+	return nil
+}
+
+// entryIdentifierBefore returns the UUID of the last entry before a transcript position,
+// or "" at the start of the transcript.
+func entryIdentifierBefore(session *agent.AgentSession, position int) string {
+	for i := len(session.Entries) - 1; i >= 0; i-- {
+		if session.Entries[i].Position < position {
+			return session.Entries[i].UUID
+		}
+	}
+	return ""
+}
+
+// initializeWatchedSession initializes the strategy's session state for a turn.
+func initializeWatchedSession(ag agent.Agent, sessionID, transcriptPath string, session *agent.AgentSession) {
+	strat := GetStrategy()
+	if initializer, ok := strat.(strategy.SessionInitializer); ok {
+		if err := initializer.InitializeSession(sessionID, ag.Type(), transcriptPath, session.GetLastUserPrompt()); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to initialize session state: %v\n", err)
+		}
+	}
+}
+
+// TurnEnd saves the session's changes since the turn started and ends the turn.
+func (strategyTurnHandler) TurnEnd(ag agent.Agent, change *agent.SessionChange) error {
+	sessionID := change.SessionID
+
+	session, err := ag.ReadSession(&agent.HookInput{SessionID: sessionID, SessionRef: change.SessionRef})
+	if err != nil {
+		return fmt.Errorf("failed to read session: %w", err)
+	}
+	transcriptPath, err := writeWatchedTranscript(sessionID, session)
+	if err != nil {
+		return err
+	}
+	sessionDir := paths.SessionMetadataDirFromSessionID(sessionID)
+	sessionDirAbs := filepath.Dir(transcriptPath)
+
+	preState, err := LoadPrePromptState(sessionID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to load pre-prompt state: %v\n", err)
+	}
+	var startPosition int
+	var transcriptIdentifierAtStart string
+	if preState != nil {
+		startPosition = preState.StepTranscriptStart
+		transcriptIdentifierAtStart = preState.LastTranscriptIdentifier
+	}
+
+	// Prompts and summary of this turn
+	turnEntries := agent.EntriesFrom(session.Entries, startPosition)
+	var allPrompts []string
+	var summary string
+	for _, entry := range turnEntries {
+		switch entry.Type {
+		case agent.EntryUser:
+			allPrompts = append(allPrompts, entry.Content)
+		case agent.EntryAssistant:
+			if entry.Content != "" {
+				summary = entry.Content
+			}
+		case agent.EntryTool, agent.EntryToolResult, agent.EntrySystem:
+		}
+	}
+	promptFile := filepath.Join(sessionDirAbs, paths.PromptFileName)
+	if err := os.WriteFile(promptFile, []byte(strings.Join(allPrompts, "\n\n---\n\n")), 0o600); err != nil {
+		return fmt.Errorf("failed to write prompt file: %w", err)
+	}
+	summaryFile := filepath.Join(sessionDirAbs, paths.SummaryFileName)
+	if err := os.WriteFile(summaryFile, []byte(summary), 0o600); err != nil {
+		return fmt.Errorf("failed to write summary file: %w", err)
+	}
+
+	modifiedFiles := session.ModifiedFiles
+	if analyzer, ok := ag.(agent.TranscriptAnalyzer); ok {
+		if files, _, err := analyzer.ExtractModifiedFilesFromOffset(transcriptPath, startPosition); err == nil {
+			modifiedFiles = files
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: failed to extract modified files: %v\n", err)
+		}
+	}
+
+	var tokenUsage *agent.TokenUsage
+	if usage := agent.SumTokenUsage(turnEntries); usage.APICallCount > 0 {
+		tokenUsage = usage
+	}
+
+	repoRoot, err := paths.RepoRoot()
+	if err != nil {
+		return fmt.Errorf("failed to get repo root: %w", err)
+	}
+	newFiles, deletedFiles, err := ComputeFileChanges(preState)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to compute file changes: %v\n", err)
+	}
+	relModifiedFiles := FilterAndNormalizePaths(modifiedFiles, repoRoot)
+	relNewFiles := FilterAndNormalizePaths(newFiles, repoRoot)
+	relDeletedFiles := FilterAndNormalizePaths(deletedFiles, repoRoot)
+
+	if len(relModifiedFiles)+len(relNewFiles)+len(relDeletedFiles) == 0 {
+		fmt.Fprintf(os.Stderr, "No files were modified during this turn of session %s\n", sessionID)
+		transitionSessionTurnEnd(sessionID)
+		if cleanupErr := CleanupPrePromptState(sessionID); cleanupErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to cleanup pre-prompt state: %v\n", cleanupErr)
+		}
+		return nil
+	}
+	logFileChanges(relModifiedFiles, relNewFiles, relDeletedFiles)
+
+	lastPrompt := ""
+	if len(allPrompts) > 0 {
+		lastPrompt = allPrompts[len(allPrompts)-1]
+	}
+	commitMessage := generateCommitMessage(lastPrompt)
+
+	contextFile := filepath.Join(sessionDirAbs, paths.ContextFileName)
+	if err := createContextFileFromPrompts(contextFile, commitMessage, sessionID, allPrompts, summary); err != nil {
+		return fmt.Errorf("failed to create context file: %w", err)
+	}
+
+	author, err := GetGitAuthor()
+	if err != nil {
+		return fmt.Errorf("failed to get git author: %w", err)
+	}
+
+	strat := GetStrategy()
+	if err := strat.EnsureSetup(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to ensure strategy setup: %v\n", err)
+	}
+
+	saveCtx := strategy.SaveContext{
+		SessionID:                sessionID,
+		ModifiedFiles:            relModifiedFiles,
+		NewFiles:                 relNewFiles,
+		DeletedFiles:             relDeletedFiles,
+		MetadataDir:              sessionDir,
+		MetadataDirAbs:           sessionDirAbs,
+		CommitMessage:            commitMessage,
+		TranscriptPath:           transcriptPath,
+		AuthorName:               author.Name,
+		AuthorEmail:              author.Email,
+		AgentType:                ag.Type(),
+		StepTranscriptStart:      startPosition,
+		StepTranscriptIdentifier: transcriptIdentifierAtStart,
+		TokenUsage:               tokenUsage,
+	}
+	if err := strat.SaveChanges(saveCtx); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+
+	if cleanupErr := CleanupPrePromptState(sessionID); cleanupErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to cleanup pre-prompt state: %v\n", cleanupErr)
+	}
+	transitionSessionTurnEnd(sessionID)
+
+	fmt.Fprintf(os.Stderr, "Saved checkpoint for %s session %s\n", ag.Type(), sessionID)
+	return nil
+}
+
+// writeWatchedTranscript writes a session's native data to its metadata directory
+// and returns the file's absolute path. Agents without hooks often have no
+// transcript file of their own, so this file serves as the session transcript.
+func writeWatchedTranscript(sessionID string, session *agent.AgentSession) (string, error) {
+	sessionDir := paths.SessionMetadataDirFromSessionID(sessionID)
+	sessionDirAbs, err := paths.AbsPath(sessionDir)
+	if err != nil {
+		sessionDirAbs = sessionDir
+	}
+	if err := os.MkdirAll(sessionDirAbs, 0o750); err != nil {
+		return "", fmt.Errorf("failed to create session directory: %w", err)
+	}
+	transcriptPath := filepath.Join(sessionDirAbs, paths.TranscriptFileName)
+	if err := os.WriteFile(transcriptPath, session.NativeData, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write transcript: %w", err)
+	}
+	return transcriptPath, nil
+}
@@ -0,0 +1,25 @@
+//go:build !unix
+
+package cli
+
+import (
+	"errors"
+	"os"
+)
+
+// errWatchDaemonUnsupported is returned for background watcher operations on
+// platforms without Unix process sessions.
+var errWatchDaemonUnsupported = errors.New("background watchers are not supported on this platform; run 'entire watch' in the foreground")
+
+func spawnWatchDaemon(string, []string, *os.File) (int, error) {
+	return 0, errWatchDaemonUnsupported
+}
+
+// processRunning cannot check for a process on this platform and assumes it is gone.
+func processRunning(int) bool {
+	return false
+}
+
+func terminateProcess(int) error {
+	return errWatchDaemonUnsupported
+}
@@ -0,0 +1,255 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+)
+
+// fakeWatcherAgent is a FileWatcher/TranscriptAnalyzer whose session state is set by tests.
+// Unimplemented Agent methods panic via the nil embedded interface.
+type fakeWatcherAgent struct {
+	agent.Agent
+
+	dir      string
+	change   *agent.SessionChange
+	position int
+	calls    int
+	session  *agent.AgentSession
+	modified []string
+}
+
+func (f *fakeWatcherAgent) Name() agent.AgentName { return "fake-watcher" }
+func (f *fakeWatcherAgent) Type() agent.AgentType { return "Fake Watcher" }
+
+func (f *fakeWatcherAgent) GetWatchPaths() ([]string, error) {
+	return []string{f.dir}, nil
+}
+
+func (f *fakeWatcherAgent) OnFileChange(string) (*agent.SessionChange, error) {
+	f.calls++
+	return f.change, nil
+}
+
+func (f *fakeWatcherAgent) GetTranscriptPosition(string) (int, error) {
+	return f.position, nil
+}
+
+func (f *fakeWatcherAgent) ExtractModifiedFilesFromOffset(string, int) ([]string, int, error) {
+	return f.modified, f.position, nil
+}
+
+func (f *fakeWatcherAgent) ReadSession(*agent.HookInput) (*agent.AgentSession, error) {
+	return f.session, nil
+}
+
+// recordingTurnHandler records turn starts and ends.
+type recordingTurnHandler struct {
+	events []string
+	starts []int
+}
+
+func (r *recordingTurnHandler) TurnStart(_ agent.Agent, _ *agent.SessionChange, startPosition int) error {
+	r.events = append(r.events, "start")
+	r.starts = append(r.starts, startPosition)
+	return nil
+}
+
+func (r *recordingTurnHandler) TurnEnd(_ agent.Agent, _ *agent.SessionChange) error {
+	r.events = append(r.events, "end")
+	return nil
+}
+
+func writeWatchedFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func TestAgentWatcher_DebouncesChanges(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "state.db")
+	writeWatchedFile(t, dbPath, "v0")
+
+	fake := &fakeWatcherAgent{dir: dir}
+	w := newAgentWatcher([]agent.FileWatcher{fake}, time.Second, &recordingTurnHandler{})
+	start := time.Now()
+
+	// Files present at startup are not changes
+	w.poll(start)
+	w.flush(start.Add(2 * time.Second))
+	if fake.calls != 0 {
+		t.Fatalf("OnFileChange called %d times for unchanged files", fake.calls)
+	}
+
+	writeWatchedFile(t, dbPath, "v1 longer")
+	w.poll(start)
+	w.flush(start.Add(500 * time.Millisecond))
+	if fake.calls != 0 {
+		t.Fatal("change processed before debounce period elapsed")
+	}
+
+	// A second write restarts the debounce period
+	writeWatchedFile(t, dbPath, "v2 even longer")
+	writeWatchedFile(t, filepath.Join(dir, "state.db-wal"), "wal")
+	w.poll(start.Add(800 * time.Millisecond))
+	w.flush(start.Add(1500 * time.Millisecond))
+	if fake.calls != 0 {
+		t.Fatal("change processed before writes settled")
+	}
+
+	w.flush(start.Add(1800 * time.Millisecond))
+	if fake.calls != 2 {
+		t.Errorf("OnFileChange called %d times, want 2 (once per changed file)", fake.calls)
+	}
+
+	// Nothing left pending
+	w.flush(start.Add(time.Minute))
+	if fake.calls != 2 {
+		t.Errorf("OnFileChange called %d times after flush, want 2", fake.calls)
+	}
+}
+
+func TestAgentWatcher_TurnTracking(t *testing.T) {
+	fake := &fakeWatcherAgent{dir: t.TempDir()}
+	handler := &recordingTurnHandler{}
+	w := newAgentWatcher([]agent.FileWatcher{fake}, time.Second, handler)
+
+	change := func(event agent.HookType, position int) {
+		fake.position = position
+		w.handleChange(fake, &agent.SessionChange{
+			SessionID: "session-1",
+			EventType: event,
+			Timestamp: time.Now(),
+		})
+	}
+
+	change(agent.HookUserPromptSubmit, 1)
+	change(agent.HookUserPromptSubmit, 1) // Still the same turn
+	change(agent.HookStop, 3)
+	change(agent.HookStop, 3) // No growth: nothing to do
+
+	// Prompt and response landed in one debounce period
+	change(agent.HookStop, 5)
+
+	want := []string{"start", "end", "start", "end"}
+	if len(handler.events) != len(want) {
+		t.Fatalf("events = %v, want %v", handler.events, want)
+	}
+	for i := range want {
+		if handler.events[i] != want[i] {
+			t.Fatalf("events = %v, want %v", handler.events, want)
+		}
+	}
+	// A new session's first turn starts at the beginning of its transcript;
+	// the next turn starts where the previous one ended.
+	if handler.starts[0] != 0 || handler.starts[1] != 3 {
+		t.Errorf("turn start positions = %v, want [0 3]", handler.starts)
+	}
+}
+
+func TestAgentWatcher_IgnoresHistoryBeforeStart(t *testing.T) {
+	fake := &fakeWatcherAgent{dir: t.TempDir(), position: 10}
+	handler := &recordingTurnHandler{}
+	w := newAgentWatcher([]agent.FileWatcher{fake}, time.Second, handler)
+
+	// A chat last updated before the watcher started
+	old := &agent.SessionChange{SessionID: "old", EventType: agent.HookStop, Timestamp: w.started.Add(-time.Hour)}
+	w.handleChange(fake, old)
+	if len(handler.events) != 0 {
+		t.Fatalf("existing history produced events: %v", handler.events)
+	}
+
+	// Continuing the chat starts a turn after the existing history
+	fake.position = 12
+	w.handleChange(fake, old)
+	if len(handler.events) != 2 || handler.starts[0] != 10 {
+		t.Errorf("events = %v, starts = %v; want [start end] from position 10", handler.events, handler.starts)
+	}
+}
+
+func TestAcquireWatchPIDFile(t *testing.T) {
+	setupTestRepo(t)
+
+	release, err := acquireWatchPIDFile()
+	if err != nil {
+		t.Fatalf("acquireWatchPIDFile() error = %v", err)
+	}
+	pid, running := runningWatcherPID()
+	if pid != os.Getpid() || !running {
+		t.Errorf("runningWatcherPID() = (%d, %v), want (%d, true)", pid, running, os.Getpid())
+	}
+
+	release()
+	if _, running := runningWatcherPID(); running {
+		t.Error("watcher still recorded after release")
+	}
+}
+
+func TestStrategyTurnHandler_SavesCheckpoint(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+	paths.ClearRepoRootCache()
+	setupResumeTestRepo(t, tmpDir, false)
+	writeSettings(t, `{"strategy": "manual-commit", "enabled": true}`)
+
+	const sessionID = "watched-session-1"
+	fake := &fakeWatcherAgent{
+		position: 2,
+		modified: []string{"test.txt"},
+		session: &agent.AgentSession{
+			SessionID:  sessionID,
+			NativeData: []byte("{\"bubbleId\":\"b1\"}\n{\"bubbleId\":\"b2\"}\n"),
+			Entries: []agent.SessionEntry{
+				{UUID: "b1", Type: agent.EntryUser, Content: "Update the test file"},
+				{UUID: "b2", Type: agent.EntryAssistant, Content: "Updated test.txt"},
+			},
+		},
+	}
+	change := &agent.SessionChange{SessionID: sessionID, SessionRef: "ref", EventType: agent.HookStop}
+	handler := strategyTurnHandler{}
+
+	if err := handler.TurnStart(fake, change, 0); err != nil {
+		t.Fatalf("TurnStart() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "test.txt"), []byte("changed by agent"), 0o644); err != nil {
+		t.Fatalf("failed to modify file: %v", err)
+	}
+	if err := handler.TurnEnd(fake, change); err != nil {
+		t.Fatalf("TurnEnd() error = %v", err)
+	}
+
+	state, err := strategy.LoadSessionState(sessionID)
+	if err != nil || state == nil {
+		t.Fatalf("LoadSessionState() = (%v, %v)", state, err)
+	}
+	if state.StepCount != 1 {
+		t.Errorf("StepCount = %d, want 1", state.StepCount)
+	}
+	if state.Phase != session.PhaseIdle {
+		t.Errorf("Phase = %q, want %q", state.Phase, session.PhaseIdle)
+	}
+	if state.AgentType != fake.Type() {
+		t.Errorf("AgentType = %q, want %q", state.AgentType, fake.Type())
+	}
+	wantTranscript := filepath.Join(tmpDir, paths.SessionMetadataDirFromSessionID(sessionID), paths.TranscriptFileName)
+	if got, err := filepath.EvalSymlinks(state.TranscriptPath); err != nil || got != mustEvalSymlinks(t, wantTranscript) {
+		t.Errorf("TranscriptPath = %q, want %q", state.TranscriptPath, wantTranscript)
+	}
+}
+
+func mustEvalSymlinks(t *testing.T, path string) string {
+	t.Helper()
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		t.Fatalf("EvalSymlinks(%s) error = %v", path, err)
+	}
+	return resolved
+}
@@ -0,0 +1,62 @@
+//go:build unix
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// spawnWatchDaemon starts `entire <args>` in a new session so it survives the
+// parent's exit and terminal hangups. Output goes to logFile.
+func spawnWatchDaemon(dir string, args []string, logFile *os.File) (int, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return 0, fmt.Errorf("failed to find executable: %w", err)
+	}
+
+	//nolint:gosec // G204: args are built internally, not user input
+	cmd := exec.CommandContext(context.Background(), executable, args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	cmd.Dir = dir
+	cmd.Env = os.Environ()
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start watcher: %w", err)
+	}
+	pid := cmd.Process.Pid
+
+	// Release the process so it can run independently
+	//nolint:errcheck // Best effort - process should continue regardless
+	_ = cmd.Process.Release()
+	return pid, nil
+}
+
+// processRunning reports whether a process with the given PID exists.
+func processRunning(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = process.Signal(syscall.Signal(0))
+	// EPERM means the process exists but belongs to another user
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// terminateProcess asks a process to exit.
+func terminateProcess(pid int) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return fmt.Errorf("failed to find process: %w", err)
+	}
+	if err := process.Signal(syscall.SIGTERM); err != nil {
+		return fmt.Errorf("failed to signal process: %w", err)
+	}
+	return nil
+}